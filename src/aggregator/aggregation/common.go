@@ -23,6 +23,7 @@ package aggregation
 import (
 	"math"
 
+	"github.com/m3db/m3/src/aggregator/aggregation/hll"
 	"github.com/m3db/m3/src/metrics/aggregation"
 )
 
@@ -43,3 +44,19 @@ func isExpensive(aggTypes aggregation.Types) bool {
 	}
 	return false
 }
+
+// addDistinct adds the value to the distinct count sketch, allocating the sketch
+// on first use so aggregations without distinct counts do not pay for it.
+func addDistinct(sketch **hll.Sketch, v uint64) {
+	if *sketch == nil {
+		*sketch = hll.MustNew(hll.DefaultPrecision)
+	}
+	(*sketch).AddUint64(v)
+}
+
+func distinctCount(sketch *hll.Sketch) int64 {
+	if sketch == nil {
+		return 0
+	}
+	return int64(sketch.Estimate())
+}
@@ -23,6 +23,7 @@ package aggregation
 import (
 	"math"
 
+	"github.com/m3db/m3/src/aggregator/aggregation/hll"
 	"github.com/m3db/m3/src/metrics/aggregation"
 )
 
@@ -35,6 +36,8 @@ type Counter struct {
 	count int64
 	max   int64
 	min   int64
+
+	distinct *hll.Sketch
 }
 
 // NewCounter creates a new counter.
@@ -63,6 +66,25 @@ func (c *Counter) Update(value int64) {
 	}
 }
 
+// AddDistinct adds the hash of a distinct value to the distinct count sketch.
+func (c *Counter) AddDistinct(hash uint64) {
+	addDistinct(&c.distinct, hash)
+}
+
+// Merge merges previously checkpointed values into the counter. The distinct
+// count sketch is not part of a checkpoint and is left untouched.
+func (c *Counter) Merge(sum, sumSq, count, min, max int64) {
+	c.sum += sum
+	c.sumSq += sumSq
+	c.count += count
+	if c.max < max {
+		c.max = max
+	}
+	if c.min > min {
+		c.min = min
+	}
+}
+
 // Count returns the number of values received.
 func (c *Counter) Count() int64 { return c.count }
 
@@ -91,6 +113,9 @@ func (c *Counter) Min() int64 { return c.min }
 // Max returns the maximum counter value.
 func (c *Counter) Max() int64 { return c.max }
 
+// DistinctCount returns the estimated number of distinct values added.
+func (c *Counter) DistinctCount() int64 { return distinctCount(c.distinct) }
+
 // ValueOf returns the value for the aggregation type.
 func (c *Counter) ValueOf(aggType aggregation.Type) float64 {
 	switch aggType {
@@ -108,6 +133,8 @@ func (c *Counter) ValueOf(aggType aggregation.Type) float64 {
 		return float64(c.SumSq())
 	case aggregation.Stdev:
 		return c.Stdev()
+	case aggregation.DistinctCount:
+		return float64(c.DistinctCount())
 	default:
 		return 0
 	}
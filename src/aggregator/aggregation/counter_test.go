@@ -43,6 +43,7 @@ func TestCounterDefaultAggregationType(t *testing.T) {
 func TestCounterCustomAggregationType(t *testing.T) {
 	opts := NewOptions()
 	opts.HasExpensiveAggregations = true
+	opts.HasDistinctCount = true
 
 	c := NewCounter(opts)
 	require.True(t, c.HasExpensiveAggregations)
@@ -68,9 +69,47 @@ func TestCounterCustomAggregationType(t *testing.T) {
 			require.Equal(t, float64(338350), v)
 		case aggregation.Stdev:
 			require.InDelta(t, 29.01149, v, 0.001)
+		case aggregation.DistinctCount:
+			// Counter values are not added to the distinct count sketch.
+			require.Equal(t, float64(0), v)
 		default:
 			require.Equal(t, float64(0), v)
 			require.False(t, aggType.IsValidForCounter())
 		}
 	}
 }
+
+func TestCounterMerge(t *testing.T) {
+	opts := NewOptions()
+	opts.HasExpensiveAggregations = true
+
+	c := NewCounter(opts)
+	c.Update(100)
+	c.Merge(10, 58, 3, 1, 7)
+	require.Equal(t, int64(110), c.Sum())
+	require.Equal(t, int64(10058), c.SumSq())
+	require.Equal(t, int64(4), c.Count())
+	require.Equal(t, int64(1), c.Min())
+	require.Equal(t, int64(100), c.Max())
+
+	c = NewCounter(opts)
+	c.Merge(10, 58, 3, 1, 7)
+	c.Update(-2)
+	require.Equal(t, int64(8), c.Sum())
+	require.Equal(t, int64(62), c.SumSq())
+	require.Equal(t, int64(4), c.Count())
+	require.Equal(t, int64(-2), c.Min())
+	require.Equal(t, int64(7), c.Max())
+}
+
+func TestCounterAddDistinct(t *testing.T) {
+	opts := NewOptions()
+	opts.HasDistinctCount = true
+
+	c := NewCounter(opts)
+	for i := 0; i < 100; i++ {
+		c.AddDistinct(uint64(i % 50))
+	}
+	require.Equal(t, int64(0), c.Count())
+	require.InDelta(t, 50.0, c.ValueOf(aggregation.DistinctCount), 1.0)
+}
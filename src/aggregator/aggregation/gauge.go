@@ -23,6 +23,7 @@ package aggregation
 import (
 	"math"
 
+	"github.com/m3db/m3/src/aggregator/aggregation/hll"
 	"github.com/m3db/m3/src/metrics/aggregation"
 )
 
@@ -40,6 +41,8 @@ type Gauge struct {
 	count int64
 	max   float64
 	min   float64
+
+	distinct *hll.Sketch
 }
 
 // NewGauge creates a new gauge.
@@ -67,6 +70,10 @@ func (g *Gauge) Update(value float64) {
 	if g.HasExpensiveAggregations {
 		g.sumSq += value * value
 	}
+
+	if g.HasDistinctCount {
+		addDistinct(&g.distinct, math.Float64bits(value))
+	}
 }
 
 // Last returns the last value received.
@@ -100,6 +107,9 @@ func (g *Gauge) Min() float64 { return g.min }
 // Max returns the maximum gauge value.
 func (g *Gauge) Max() float64 { return g.max }
 
+// DistinctCount returns the estimated number of distinct gauge values.
+func (g *Gauge) DistinctCount() int64 { return distinctCount(g.distinct) }
+
 // ValueOf returns the value for the aggregation type.
 func (g *Gauge) ValueOf(aggType aggregation.Type) float64 {
 	switch aggType {
@@ -119,6 +129,8 @@ func (g *Gauge) ValueOf(aggType aggregation.Type) float64 {
 		return g.SumSq()
 	case aggregation.Stdev:
 		return g.Stdev()
+	case aggregation.DistinctCount:
+		return float64(g.DistinctCount())
 	default:
 		return 0
 	}
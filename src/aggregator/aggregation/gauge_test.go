@@ -44,6 +44,7 @@ func TestGaugeDefaultAggregationType(t *testing.T) {
 func TestGaugeCustomAggregationType(t *testing.T) {
 	opts := NewOptions()
 	opts.HasExpensiveAggregations = true
+	opts.HasDistinctCount = true
 
 	g := NewGauge(opts)
 	require.True(t, g.HasExpensiveAggregations)
@@ -72,6 +73,8 @@ func TestGaugeCustomAggregationType(t *testing.T) {
 			require.Equal(t, float64(338350), v)
 		case aggregation.Stdev:
 			require.InDelta(t, 29.01149, v, 0.001)
+		case aggregation.DistinctCount:
+			require.InDelta(t, 100.0, v, 2.0)
 		default:
 			require.Equal(t, float64(0), v)
 			require.False(t, aggType.IsValidForGauge())
@@ -0,0 +1,192 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregation
+
+import (
+	"math"
+
+	"github.com/m3db/m3/src/metrics/aggregation"
+	"github.com/m3db/m3/src/metrics/metric/histogram"
+)
+
+// Histogram aggregates pre-bucketed histogram values. Histogram APIs are not thread-safe.
+type Histogram struct {
+	Options
+
+	schema    histogram.Schema // Bucket schema adopted from the first update.
+	hasSchema bool             // Whether the bucket schema has been set.
+	counts    []uint64         // Number of values in each bucket.
+	count     int64            // Number of values received.
+	sum       float64          // Sum of the values.
+}
+
+// NewHistogram creates a new histogram.
+func NewHistogram(opts Options) Histogram {
+	return Histogram{Options: opts}
+}
+
+// Update merges the bucket counts and the sum of a histogram into the aggregation.
+// The schema of the first update is adopted by the aggregation, and the counts of
+// histograms with a different schema are re-bucketed by their bucket upper bounds.
+func (h *Histogram) Update(schema histogram.Schema, counts []uint64, sum float64) {
+	if !h.hasSchema {
+		h.setSchema(schema)
+	}
+	h.sum += sum
+
+	if h.schema.Equal(schema) {
+		for i := 0; i < len(counts) && i < len(h.counts); i++ {
+			h.counts[i] += counts[i]
+			h.count += int64(counts[i])
+		}
+		return
+	}
+
+	for i, c := range counts {
+		if c == 0 {
+			continue
+		}
+		idx := h.schema.BucketIndex(schema.UpperBound(i))
+		h.counts[idx] += c
+		h.count += int64(c)
+	}
+}
+
+// Add adds a single value to the histogram.
+func (h *Histogram) Add(value float64) {
+	h.count++
+	h.sum += value
+
+	// NB: values received before any schema is known only contribute
+	// to the count and the sum as there are no buckets to place them in.
+	if h.hasSchema {
+		h.counts[h.schema.BucketIndex(value)]++
+	}
+}
+
+// Quantile returns the value at a given quantile, linearly interpolated
+// within the bucket containing the quantile.
+func (h *Histogram) Quantile(q float64) float64 {
+	var total uint64
+	for _, c := range h.counts {
+		total += c
+	}
+	if total == 0 {
+		return 0.0
+	}
+
+	var (
+		rank       = q * float64(total)
+		cumulative float64
+		overflow   = len(h.counts) - 1
+	)
+	for i, c := range h.counts {
+		if c == 0 {
+			continue
+		}
+		if cumulative+float64(c) < rank && i < overflow {
+			cumulative += float64(c)
+			continue
+		}
+		lower, upper := h.schema.LowerBound(i), h.schema.UpperBound(i)
+		if i == overflow {
+			// The overflow bucket has no upper bound, use the largest finite bound.
+			return lower
+		}
+		if math.IsInf(lower, -1) {
+			if upper <= 0 {
+				return upper
+			}
+			lower = 0
+		}
+		fraction := (rank - cumulative) / float64(c)
+		if fraction < 0 {
+			fraction = 0
+		}
+		return lower + (upper-lower)*fraction
+	}
+	return 0.0
+}
+
+// Count returns the number of values received.
+func (h *Histogram) Count() int64 { return h.count }
+
+// Sum returns the sum of the values.
+func (h *Histogram) Sum() float64 { return h.sum }
+
+// Mean returns the mean value.
+func (h *Histogram) Mean() float64 {
+	if h.count == 0 {
+		return 0.0
+	}
+	return h.sum / float64(h.count)
+}
+
+// Min returns the approximate minimum value, bounded by the bucket boundaries.
+func (h *Histogram) Min() float64 { return h.Quantile(0) }
+
+// Max returns the approximate maximum value, bounded by the bucket boundaries.
+func (h *Histogram) Max() float64 { return h.Quantile(1) }
+
+// Rate returns the number of values received per second.
+func (h *Histogram) Rate() float64 {
+	if h.Resolution <= 0 {
+		return 0.0
+	}
+	return float64(h.count) / h.Resolution.Seconds()
+}
+
+// ValueOf returns the value for the aggregation type.
+func (h *Histogram) ValueOf(aggType aggregation.Type) float64 {
+	if q, ok := aggType.Quantile(); ok {
+		return h.Quantile(q)
+	}
+
+	switch aggType {
+	case aggregation.Min:
+		return h.Min()
+	case aggregation.Max:
+		return h.Max()
+	case aggregation.Mean:
+		return h.Mean()
+	case aggregation.Count:
+		return float64(h.Count())
+	case aggregation.Sum:
+		return h.Sum()
+	case aggregation.Rate:
+		return h.Rate()
+	}
+	return 0
+}
+
+// Close closes the histogram.
+func (h *Histogram) Close() {}
+
+func (h *Histogram) setSchema(schema histogram.Schema) {
+	// NB: the bounds are copied since the schema may reference
+	// buffers owned and reused by the decoder.
+	h.schema = schema
+	if len(schema.Bounds) > 0 {
+		h.schema.Bounds = append([]float64(nil), schema.Bounds...)
+	}
+	h.counts = make([]uint64, schema.Len())
+	h.hasSchema = true
+}
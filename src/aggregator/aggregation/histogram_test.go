@@ -0,0 +1,127 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/metrics/aggregation"
+	"github.com/m3db/m3/src/metrics/metric/histogram"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogramEmpty(t *testing.T) {
+	h := NewHistogram(NewOptions())
+	require.Equal(t, int64(0), h.Count())
+	require.Equal(t, 0.0, h.Sum())
+	require.Equal(t, 0.0, h.Mean())
+	require.Equal(t, 0.0, h.Quantile(0.5))
+	require.Equal(t, 0.0, h.Rate())
+}
+
+func TestHistogramUpdateSameSchema(t *testing.T) {
+	opts := NewOptions()
+	opts.Resolution = 10 * time.Second
+	h := NewHistogram(opts)
+
+	schema := histogram.NewFixedSchema([]float64{10, 20, 30})
+	h.Update(schema, []uint64{10, 20, 10, 0}, 700)
+	h.Update(schema, []uint64{0, 20, 20, 0}, 1000)
+
+	require.Equal(t, int64(80), h.Count())
+	require.Equal(t, 1700.0, h.Sum())
+	require.Equal(t, 21.25, h.Mean())
+	require.Equal(t, 8.0, h.Rate())
+
+	// 10 values in (-Inf, 10], 40 values in (10, 20], 30 values in (20, 30].
+	require.Equal(t, 0.0, h.Min())
+	require.Equal(t, 20.0, h.Quantile(0.625))
+	require.Equal(t, 15.0, h.Quantile(0.375))
+	require.InDelta(t, 29.7333, h.Quantile(0.99), 1e-3)
+	require.Equal(t, 30.0, h.Max())
+}
+
+func TestHistogramUpdateDifferentSchema(t *testing.T) {
+	h := NewHistogram(NewOptions())
+	h.Update(histogram.NewFixedSchema([]float64{1, 10, 100}), []uint64{1, 2, 3, 4}, 1234)
+
+	// Buckets with upper bounds 0.5, 1, 2, 4 and overflow are re-bucketed into
+	// (-Inf, 1], (-Inf, 1], (1, 10], (1, 10] and the overflow bucket.
+	h.Update(histogram.NewExponentialSchema(0.5, 2, 4), []uint64{1, 1, 1, 1, 1}, 10)
+
+	require.Equal(t, int64(15), h.Count())
+	require.Equal(t, 1244.0, h.Sum())
+	require.Equal(t, []uint64{3, 4, 3, 5}, h.counts)
+	require.Equal(t, 100.0, h.Quantile(1))
+}
+
+func TestHistogramUpdateCopiesBounds(t *testing.T) {
+	h := NewHistogram(NewOptions())
+	bounds := []float64{1, 10, 100}
+	h.Update(histogram.NewFixedSchema(bounds), []uint64{1, 0, 0, 0}, 1)
+	bounds[0] = 1000
+	require.Equal(t, []float64{1, 10, 100}, h.schema.Bounds)
+}
+
+func TestHistogramAdd(t *testing.T) {
+	h := NewHistogram(NewOptions())
+	h.Update(histogram.NewFixedSchema([]float64{1, 10, 100}), []uint64{0, 0, 0, 0}, 0)
+	for _, v := range []float64{0.5, 5, 50, 500} {
+		h.Add(v)
+	}
+	require.Equal(t, int64(4), h.Count())
+	require.Equal(t, 555.5, h.Sum())
+	require.Equal(t, []uint64{1, 1, 1, 1}, h.counts)
+}
+
+func TestHistogramValueOf(t *testing.T) {
+	opts := NewOptions()
+	opts.Resolution = time.Second
+	h := NewHistogram(opts)
+	h.Update(histogram.NewExponentialSchema(1, 2, 4), []uint64{2, 2, 2, 2, 0}, 20)
+
+	for aggType := range aggregation.ValidTypes {
+		v := h.ValueOf(aggType)
+		if q, ok := aggType.Quantile(); ok {
+			require.Equal(t, h.Quantile(q), v)
+			continue
+		}
+		switch aggType {
+		case aggregation.Min:
+			require.Equal(t, 0.0, v)
+		case aggregation.Max:
+			require.Equal(t, 8.0, v)
+		case aggregation.Mean:
+			require.Equal(t, 2.5, v)
+		case aggregation.Count:
+			require.Equal(t, 8.0, v)
+		case aggregation.Sum:
+			require.Equal(t, 20.0, v)
+		case aggregation.Rate:
+			require.Equal(t, 8.0, v)
+		default:
+			require.Equal(t, 0.0, v)
+			require.False(t, aggType.IsValidForHistogram())
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package hll implements the HyperLogLog algorithm for estimating the number of
+// distinct values in a stream using a small, fixed amount of memory, based on
+// "HyperLogLog: the analysis of a near-optimal cardinality estimation algorithm"
+// by Flajolet et al. Sketches with the same precision can be merged without loss
+// of accuracy, which allows distinct counts to be combined across shards.
+package hll
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+const (
+	// MinPrecision is the minimum supported precision.
+	MinPrecision uint8 = 4
+
+	// MaxPrecision is the maximum supported precision.
+	MaxPrecision uint8 = 16
+
+	// DefaultPrecision is the default precision, which uses 4KB of registers
+	// for a standard error of roughly 1.6%.
+	DefaultPrecision uint8 = 12
+)
+
+var (
+	errPrecisionMismatch = errors.New("cannot merge sketches with different precisions")
+)
+
+// Sketch is a HyperLogLog sketch.
+type Sketch struct {
+	precision uint8
+	registers []uint8
+}
+
+// New creates a new sketch with 2^precision registers.
+func New(precision uint8) (*Sketch, error) {
+	if precision < MinPrecision || precision > MaxPrecision {
+		return nil, fmt.Errorf("invalid precision %d, must be between %d and %d",
+			precision, MinPrecision, MaxPrecision)
+	}
+	return &Sketch{
+		precision: precision,
+		registers: make([]uint8, 1<<precision),
+	}, nil
+}
+
+// MustNew creates a new sketch, panicking if the precision is invalid.
+func MustNew(precision uint8) *Sketch {
+	s, err := New(precision)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Precision returns the precision of the sketch.
+func (s *Sketch) Precision() uint8 { return s.precision }
+
+// Add adds a hashed value to the sketch. The hash is expected to be uniformly
+// distributed across all 64 bits.
+func (s *Sketch) Add(hash uint64) {
+	idx := hash >> (64 - s.precision)
+	// NB: the remaining bits are shifted to the top and a sentinel bit is set so
+	// the rank is bounded by the number of bits left after taking the index.
+	w := hash<<s.precision | 1<<(s.precision-1)
+	rank := uint8(bits.LeadingZeros64(w)) + 1
+	if rank > s.registers[idx] {
+		s.registers[idx] = rank
+	}
+}
+
+// AddUint64 hashes the value and adds it to the sketch.
+func (s *Sketch) AddUint64(v uint64) { s.Add(Hash(v)) }
+
+// Merge merges the other sketch into the current sketch.
+func (s *Sketch) Merge(other *Sketch) error {
+	if s.precision != other.precision {
+		return errPrecisionMismatch
+	}
+	for i, r := range other.registers {
+		if r > s.registers[i] {
+			s.registers[i] = r
+		}
+	}
+	return nil
+}
+
+// Estimate returns the estimated number of distinct values added to the sketch.
+func (s *Sketch) Estimate() uint64 {
+	var (
+		m     = float64(len(s.registers))
+		sum   float64
+		zeros int
+	)
+	for _, r := range s.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	estimate := alpha(m) * m * m / sum
+	// NB: the raw estimate is heavily biased for small cardinalities, in which
+	// case linear counting based on the number of empty registers is used instead.
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// Reset resets the sketch.
+func (s *Sketch) Reset() {
+	for i := range s.registers {
+		s.registers[i] = 0
+	}
+}
+
+// Hash mixes the bits of a value so that values with few differing bits, such as
+// small integers, are spread uniformly across the sketch registers.
+func Hash(v uint64) uint64 {
+	v ^= v >> 33
+	v *= 0xff51afd7ed558ccd
+	v ^= v >> 33
+	v *= 0xc4ceb9fe1a85ec53
+	v ^= v >> 33
+	return v
+}
+
+func alpha(m float64) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	default:
+		return 0.7213 / (1 + 1.079/m)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package hll
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInvalidPrecision(t *testing.T) {
+	_, err := New(MinPrecision - 1)
+	require.Error(t, err)
+	_, err = New(MaxPrecision + 1)
+	require.Error(t, err)
+	require.Panics(t, func() { MustNew(0) })
+}
+
+func TestSketchEmpty(t *testing.T) {
+	s := MustNew(DefaultPrecision)
+	require.Equal(t, DefaultPrecision, s.Precision())
+	require.Equal(t, uint64(0), s.Estimate())
+}
+
+func TestSketchEstimate(t *testing.T) {
+	inputs := []uint64{10, 1000, 100000}
+	for _, n := range inputs {
+		s := MustNew(DefaultPrecision)
+		for i := uint64(0); i < n; i++ {
+			// Duplicate values must not affect the estimate.
+			s.AddUint64(i)
+			s.AddUint64(i)
+		}
+		require.InEpsilon(t, float64(n), float64(s.Estimate()), 0.05)
+	}
+}
+
+func TestSketchMerge(t *testing.T) {
+	s1 := MustNew(DefaultPrecision)
+	s2 := MustNew(DefaultPrecision)
+	for i := uint64(0); i < 20000; i++ {
+		s1.AddUint64(i)
+	}
+	for i := uint64(10000); i < 30000; i++ {
+		s2.AddUint64(i)
+	}
+	require.NoError(t, s1.Merge(s2))
+	require.InEpsilon(t, 30000.0, float64(s1.Estimate()), 0.05)
+
+	require.Error(t, s1.Merge(MustNew(DefaultPrecision+1)))
+}
+
+func TestSketchReset(t *testing.T) {
+	s := MustNew(MinPrecision)
+	for i := uint64(0); i < 100; i++ {
+		s.AddUint64(i)
+	}
+	require.NotEqual(t, uint64(0), s.Estimate())
+	s.Reset()
+	require.Equal(t, uint64(0), s.Estimate())
+}
@@ -20,7 +20,11 @@
 
 package aggregation
 
-import "github.com/m3db/m3/src/metrics/aggregation"
+import (
+	"time"
+
+	"github.com/m3db/m3/src/metrics/aggregation"
+)
 
 var (
 	defaultOptions Options
@@ -31,6 +35,14 @@ type Options struct {
 	// HasExpensiveAggregations means expensive (multiplication／division)
 	// aggregation types are enabled.
 	HasExpensiveAggregations bool
+
+	// HasDistinctCount means the distinct count aggregation type is enabled,
+	// which requires maintaining a HyperLogLog sketch of the values received.
+	HasDistinctCount bool
+
+	// Resolution is the resolution of the storage policy the aggregation is
+	// computed for, used to derive per-second rates.
+	Resolution time.Duration
 }
 
 // NewOptions creates a new aggregation options.
@@ -41,4 +53,5 @@ func NewOptions() Options {
 // ResetSetData resets the aggregation options.
 func (o *Options) ResetSetData(aggTypes aggregation.Types) {
 	o.HasExpensiveAggregations = isExpensive(aggTypes)
+	o.HasDistinctCount = aggTypes.Contains(aggregation.DistinctCount)
 }
@@ -21,6 +21,9 @@
 package aggregation
 
 import (
+	"math"
+
+	"github.com/m3db/m3/src/aggregator/aggregation/hll"
 	"github.com/m3db/m3/src/aggregator/aggregation/quantile/cm"
 	"github.com/m3db/m3/src/metrics/aggregation"
 )
@@ -33,6 +36,8 @@ type Timer struct {
 	sum    float64   // Sum of the values.
 	sumSq  float64   // Sum of squared values.
 	stream cm.Stream // Stream of values received.
+
+	distinct *hll.Sketch // Sketch of distinct values received.
 }
 
 // NewTimer creates a new timer
@@ -54,6 +59,10 @@ func (t *Timer) Add(value float64) {
 	if t.HasExpensiveAggregations {
 		t.sumSq += value * value
 	}
+
+	if t.HasDistinctCount {
+		addDistinct(&t.distinct, math.Float64bits(value))
+	}
 }
 
 // AddBatch adds a batch of timer values.
@@ -103,6 +112,9 @@ func (t *Timer) Stdev() float64 {
 	return stdev(t.count, t.sumSq, t.sum)
 }
 
+// DistinctCount returns the estimated number of distinct timer values.
+func (t *Timer) DistinctCount() int64 { return distinctCount(t.distinct) }
+
 // ValueOf returns the value for the aggregation type.
 func (t *Timer) ValueOf(aggType aggregation.Type) float64 {
 	if q, ok := aggType.Quantile(); ok {
@@ -124,6 +136,8 @@ func (t *Timer) ValueOf(aggType aggregation.Type) float64 {
 		return t.SumSq()
 	case aggregation.Stdev:
 		return t.Stdev()
+	case aggregation.DistinctCount:
+		return float64(t.DistinctCount())
 	}
 	return 0
 }
@@ -165,7 +165,25 @@ func TestTimerAggregationsNotExpensive(t *testing.T) {
 
 	// Expensive calculations are not performed.
 	require.Equal(t, 0.0, timer.SumSq())
+	require.Equal(t, int64(0), timer.DistinctCount())
 
 	// Closing the timer a second time should be a no op.
 	timer.Close()
 }
+
+func TestTimerDistinctCount(t *testing.T) {
+	opts := NewOptions()
+	opts.ResetSetData(aggregation.Types{aggregation.DistinctCount})
+
+	timer := NewTimer(testQuantiles, cm.NewOptions(), opts)
+	require.True(t, timer.HasDistinctCount)
+
+	// Each value is added twice but only counted once.
+	for i := 1; i <= 100; i++ {
+		timer.Add(float64(i))
+		timer.Add(float64(i))
+	}
+	require.Equal(t, int64(200), timer.Count())
+	require.InDelta(t, 100.0, timer.ValueOf(aggregation.DistinctCount), 2.0)
+	timer.Close()
+}
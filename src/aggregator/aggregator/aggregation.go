@@ -34,9 +34,18 @@ func newCounterAggregation(c aggregation.Counter) counterAggregation {
 	return counterAggregation{Counter: c}
 }
 
-func (c *counterAggregation) Add(value float64)                    { c.Counter.Update(int64(value)) }
 func (c *counterAggregation) AddUnion(mu unaggregated.MetricUnion) { c.Counter.Update(mu.CounterVal) }
 
+// NB: values forwarded to distinct count aggregations are the hashes of the
+// distinct tag values of the source metrics rather than counter values.
+func (c *counterAggregation) Add(value float64) {
+	if c.HasDistinctCount {
+		c.Counter.AddDistinct(uint64(value))
+		return
+	}
+	c.Counter.Update(int64(value))
+}
+
 // timerAggregation is a timer aggregation.
 type timerAggregation struct {
 	aggregation.Timer
@@ -54,3 +63,18 @@ type gaugeAggregation struct {
 func newGaugeAggregation(g aggregation.Gauge) gaugeAggregation   { return gaugeAggregation{Gauge: g} }
 func (g *gaugeAggregation) Add(value float64)                    { g.Gauge.Update(value) }
 func (g *gaugeAggregation) AddUnion(mu unaggregated.MetricUnion) { g.Gauge.Update(mu.GaugeVal) }
+
+// histogramAggregation is a histogram aggregation.
+type histogramAggregation struct {
+	aggregation.Histogram
+}
+
+func newHistogramAggregation(h aggregation.Histogram) histogramAggregation {
+	return histogramAggregation{Histogram: h}
+}
+
+func (h *histogramAggregation) Add(value float64) { h.Histogram.Add(value) }
+
+func (h *histogramAggregation) AddUnion(mu unaggregated.MetricUnion) {
+	h.Histogram.Update(mu.HistogramSchema, mu.HistogramCounts, mu.HistogramSum)
+}
@@ -40,6 +40,7 @@ import (
 	"github.com/m3db/m3/src/metrics/metric/id"
 	"github.com/m3db/m3/src/metrics/metric/unaggregated"
 	"github.com/m3db/m3x/clock"
+	xerrors "github.com/m3db/m3x/errors"
 	"github.com/m3db/m3x/instrument"
 
 	"github.com/uber-go/tally"
@@ -58,6 +59,25 @@ var (
 	errShardNotOwned                 = errors.New("aggregator shard is not owned")
 )
 
+// IsRetryableError returns true if the error returned when adding a metric is
+// transient, i.e. the same metric may be added successfully if it is retried
+// later, for example once the aggregator is open or owns the metric's shard.
+func IsRetryableError(err error) bool {
+	switch err {
+	case errAggregatorNotOpenOrClosed,
+		errActivePlacementChanged,
+		errShardNotOwned,
+		errAggregatorShardClosed,
+		errAggregatorShardNotWriteable,
+		errMetricMapClosed,
+		errWriteNewMetricRateLimitExceeded,
+		errEntryClosed,
+		errWriteValueRateLimitExceeded:
+		return true
+	}
+	return xerrors.IsRetryableError(err)
+}
+
 // Aggregator aggregates different types of metrics.
 type Aggregator interface {
 	// Open opens the aggregator.
@@ -95,6 +115,7 @@ type aggregator struct {
 	placementManager  PlacementManager
 	flushTimesManager FlushTimesManager
 	flushTimesChecker flushTimesChecker
+	checkpointManager CheckpointManager
 	electionManager   ElectionManager
 	flushManager      FlushManager
 	flushHandler      handler.Handler
@@ -128,6 +149,7 @@ func NewAggregator(opts Options) Aggregator {
 		placementManager:  opts.PlacementManager(),
 		flushTimesManager: opts.FlushTimesManager(),
 		flushTimesChecker: newFlushTimesChecker(scope.SubScope("tick.shard-check")),
+		checkpointManager: opts.CheckpointManager(),
 		electionManager:   opts.ElectionManager(),
 		flushManager:      opts.FlushManager(),
 		flushHandler:      opts.FlushHandler(),
@@ -406,6 +428,11 @@ func (agg *aggregator) openShardSetWithLock(shardSetID uint32) error {
 	if err := agg.flushTimesManager.Open(shardSetID); err != nil {
 		return err
 	}
+	if agg.checkpointManager != nil {
+		if err := agg.checkpointManager.Open(shardSetID); err != nil {
+			return err
+		}
+	}
 	if err := agg.electionManager.Open(shardSetID); err != nil {
 		return err
 	}
@@ -426,6 +453,14 @@ func (agg *aggregator) closeShardSetWithLock() error {
 	if err := agg.electionManager.Reset(); err != nil {
 		return err
 	}
+	if agg.checkpointManager != nil {
+		if err := agg.checkpointManager.Close(); err != nil {
+			return err
+		}
+		if err := agg.checkpointManager.Reset(); err != nil {
+			return err
+		}
+	}
 	if err := agg.flushTimesManager.Close(); err != nil {
 		return err
 	}
@@ -495,6 +530,9 @@ func (agg *aggregator) checkMetricType(mu unaggregated.MetricUnion) error {
 	case metric.GaugeType:
 		agg.metrics.gauges.Inc(1)
 		return nil
+	case metric.HistogramType:
+		agg.metrics.histograms.Inc(1)
+		return nil
 	default:
 		return errInvalidMetricType
 	}
@@ -890,6 +928,7 @@ type aggregatorMetrics struct {
 	timers       tally.Counter
 	timerBatches tally.Counter
 	gauges       tally.Counter
+	histograms   tally.Counter
 	forwarded    tally.Counter
 	timed        tally.Counter
 	addUntimed   aggregatorAddUntimedMetrics
@@ -918,6 +957,7 @@ func newAggregatorMetrics(
 		timers:       scope.Counter("timers"),
 		timerBatches: scope.Counter("timer-batches"),
 		gauges:       scope.Counter("gauges"),
+		histograms:   scope.Counter("histograms"),
 		forwarded:    scope.Counter("forwarded"),
 		timed:        scope.Counter("timed"),
 		addUntimed:   newAggregatorAddUntimedMetrics(addUntimedScope, samplingRate),
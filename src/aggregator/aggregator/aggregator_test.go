@@ -44,6 +44,7 @@ import (
 	"github.com/m3db/m3/src/metrics/pipeline"
 	"github.com/m3db/m3/src/metrics/pipeline/applied"
 	"github.com/m3db/m3/src/metrics/policy"
+	xerrors "github.com/m3db/m3x/errors"
 	xtime "github.com/m3db/m3x/time"
 
 	"github.com/golang/mock/gomock"
@@ -128,6 +129,27 @@ var (
 	}
 )
 
+func TestIsRetryableError(t *testing.T) {
+	for _, err := range []error{
+		errAggregatorNotOpenOrClosed,
+		errShardNotOwned,
+		errAggregatorShardNotWriteable,
+		errWriteNewMetricRateLimitExceeded,
+		errWriteValueRateLimitExceeded,
+		xerrors.NewRetryableError(errors.New("foo")),
+	} {
+		require.True(t, IsRetryableError(err), err.Error())
+	}
+	for _, err := range []error{
+		errInvalidMetricType,
+		errTooFarInThePast,
+		errArrivedTooLate,
+		errors.New("foo"),
+	} {
+		require.False(t, IsRetryableError(err), err.Error())
+	}
+}
+
 func TestAggregatorOpenAlreadyOpen(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
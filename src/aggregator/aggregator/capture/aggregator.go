@@ -43,6 +43,7 @@ type aggregator struct {
 	countersWithMetadatas        []unaggregated.CounterWithMetadatas
 	batchTimersWithMetadatas     []unaggregated.BatchTimerWithMetadatas
 	gaugesWithMetadatas          []unaggregated.GaugeWithMetadatas
+	histogramsWithMetadatas      []unaggregated.HistogramWithMetadatas
 	forwardedMetricsWithMetadata []aggregated.ForwardedMetricWithMetadata
 	timedMetricsWithMetadata     []aggregated.TimedMetricWithMetadata
 }
@@ -84,6 +85,12 @@ func (agg *aggregator) AddUntimed(
 			StagedMetadatas: sm,
 		}
 		agg.gaugesWithMetadatas = append(agg.gaugesWithMetadatas, gp)
+	case metric.HistogramType:
+		hp := unaggregated.HistogramWithMetadatas{
+			Histogram:       mu.Histogram(),
+			StagedMetadatas: sm,
+		}
+		agg.histogramsWithMetadatas = append(agg.histogramsWithMetadatas, hp)
 	default:
 		return fmt.Errorf("unrecognized metric type %v", mu.Type)
 	}
@@ -149,12 +156,14 @@ func (agg *aggregator) Snapshot() SnapshotResult {
 		CountersWithMetadatas:        agg.countersWithMetadatas,
 		BatchTimersWithMetadatas:     agg.batchTimersWithMetadatas,
 		GaugesWithMetadatas:          agg.gaugesWithMetadatas,
+		HistogramsWithMetadatas:      agg.histogramsWithMetadatas,
 		ForwardedMetricsWithMetadata: agg.forwardedMetricsWithMetadata,
 		TimedMetricWithMetadata:      agg.timedMetricsWithMetadata,
 	}
 	agg.countersWithMetadatas = nil
 	agg.batchTimersWithMetadatas = nil
 	agg.gaugesWithMetadatas = nil
+	agg.histogramsWithMetadatas = nil
 	agg.forwardedMetricsWithMetadata = nil
 	agg.timedMetricsWithMetadata = nil
 	agg.numMetricsAdded = 0
@@ -178,6 +187,18 @@ func cloneUntimedMetric(m unaggregated.MetricUnion) unaggregated.MetricUnion {
 		copy(clonedTimerVal, m.BatchTimerVal)
 		mu.BatchTimerVal = clonedTimerVal
 	}
+
+	// Clone histogram bounds and counts.
+	if m.Type == metric.HistogramType {
+		if m.HistogramSchema.Bounds != nil {
+			clonedBounds := make([]float64, len(m.HistogramSchema.Bounds))
+			copy(clonedBounds, m.HistogramSchema.Bounds)
+			mu.HistogramSchema.Bounds = clonedBounds
+		}
+		clonedCounts := make([]uint64, len(m.HistogramCounts))
+		copy(clonedCounts, m.HistogramCounts)
+		mu.HistogramCounts = clonedCounts
+	}
 	return mu
 }
 
@@ -29,6 +29,7 @@ import (
 	"github.com/m3db/m3/src/metrics/metadata"
 	"github.com/m3db/m3/src/metrics/metric"
 	"github.com/m3db/m3/src/metrics/metric/aggregated"
+	"github.com/m3db/m3/src/metrics/metric/histogram"
 	"github.com/m3db/m3/src/metrics/metric/id"
 	"github.com/m3db/m3/src/metrics/metric/unaggregated"
 	"github.com/m3db/m3/src/metrics/pipeline"
@@ -55,6 +56,13 @@ var (
 		ID:       id.RawID("testCounter"),
 		GaugeVal: 123.456,
 	}
+	testHistogram = unaggregated.MetricUnion{
+		Type:            metric.HistogramType,
+		ID:              id.RawID("testHistogram"),
+		HistogramSchema: histogram.NewFixedSchema([]float64{1, 10, 100}),
+		HistogramCounts: []uint64{3, 0, 12, 1},
+		HistogramSum:    567.8,
+	}
 	testTimed = aggregated.Metric{
 		Type:      metric.CounterType,
 		ID:        []byte("testForwarded"),
@@ -102,7 +110,7 @@ func TestAggregator(t *testing.T) {
 
 	// Add valid untimed metrics with policies.
 	var expected SnapshotResult
-	for _, mu := range []unaggregated.MetricUnion{testCounter, testBatchTimer, testGauge} {
+	for _, mu := range []unaggregated.MetricUnion{testCounter, testBatchTimer, testGauge, testHistogram} {
 		switch mu.Type {
 		case metric.CounterType:
 			expected.CountersWithMetadatas = append(
@@ -125,6 +133,13 @@ func TestAggregator(t *testing.T) {
 					Gauge:           mu.Gauge(),
 					StagedMetadatas: metadatas,
 				})
+		case metric.HistogramType:
+			expected.HistogramsWithMetadatas = append(
+				expected.HistogramsWithMetadatas,
+				unaggregated.HistogramWithMetadatas{
+					Histogram:       mu.Histogram(),
+					StagedMetadatas: metadatas,
+				})
 		default:
 			require.Fail(t, fmt.Sprintf("unknown metric type %v", mu.Type))
 		}
@@ -141,7 +156,7 @@ func TestAggregator(t *testing.T) {
 	)
 	require.NoError(t, agg.AddTimed(testTimed, testTimedMetadata))
 
-	require.Equal(t, 4, agg.NumMetricsAdded())
+	require.Equal(t, 5, agg.NumMetricsAdded())
 
 	// Add valid forwarded metrics with metadata.
 	expected.ForwardedMetricsWithMetadata = append(
@@ -153,7 +168,7 @@ func TestAggregator(t *testing.T) {
 	)
 	require.NoError(t, agg.AddForwarded(testForwarded, testForwardMetadata))
 
-	require.Equal(t, 5, agg.NumMetricsAdded())
+	require.Equal(t, 6, agg.NumMetricsAdded())
 
 	res := agg.Snapshot()
 	require.Equal(t, expected, res)
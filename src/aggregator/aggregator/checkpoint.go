@@ -0,0 +1,199 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregator
+
+import (
+	"encoding/binary"
+
+	checkpointpb "github.com/m3db/m3/src/aggregator/generated/proto/checkpoint"
+	"github.com/m3db/m3/src/aggregator/hash"
+)
+
+// checkpointingMetricList is a flushing metric list whose open aggregation windows
+// can be checkpointed by the leader and restored by the followers.
+type checkpointingMetricList interface {
+	flushingMetricList
+
+	// Checkpoint appends the open aggregation windows of the list to the checkpoint
+	// until it contains the given maximum number of windows, returning false if
+	// any window was left out.
+	Checkpoint(cp *checkpointpb.ShardCheckpoint, maxWindows int) bool
+
+	// Restore merges the checkpointed aggregation windows that started before
+	// the given time and have not been flushed yet.
+	Restore(checkpoints counterCheckpointsByKey, beforeNanos int64)
+}
+
+// counterCheckpointsByKey contains checkpointed counter windows keyed by the
+// checkpoint key of the element they belong to.
+type counterCheckpointsByKey map[hash.Hash128][]*checkpointpb.CounterWindowCheckpoint
+
+func newCounterCheckpointsByKey(cp *checkpointpb.ShardCheckpoint) counterCheckpointsByKey {
+	checkpoints := make(counterCheckpointsByKey, len(cp.Counters))
+	for _, window := range cp.Counters {
+		key := hash.Hash128{window.KeyHi, window.KeyLo}
+		checkpoints[key] = append(checkpoints[key], window)
+	}
+	return checkpoints
+}
+
+// Checkpoint appends the open counter windows of the list to the checkpoint
+// until it contains the given maximum number of windows, returning false if
+// any window was left out.
+func (l *baseMetricList) Checkpoint(cp *checkpointpb.ShardCheckpoint, maxWindows int) bool {
+	var (
+		numWindows = len(cp.Counters)
+		complete   = true
+	)
+	l.RLock()
+	for e := l.aggregations.Front(); e != nil && complete; e = e.Next() {
+		if elem, ok := e.Value.(*CounterElem); ok {
+			complete = elem.checkpoint(cp, maxWindows)
+		}
+	}
+	l.RUnlock()
+	l.metrics.checkpointWindows.Inc(int64(len(cp.Counters) - numWindows))
+	return complete
+}
+
+// Restore merges the checkpointed counter windows that started before the
+// given time and have not been flushed yet. Checkpointed windows of elements
+// that do not exist in the list are skipped since the element metadata is not
+// part of the checkpoint.
+func (l *baseMetricList) Restore(checkpoints counterCheckpointsByKey, beforeNanos int64) {
+	var (
+		lastFlushedNanos = l.LastFlushedNanos()
+		numRestored      int
+	)
+	l.RLock()
+	for e := l.aggregations.Front(); e != nil; e = e.Next() {
+		elem, ok := e.Value.(*CounterElem)
+		if !ok {
+			continue
+		}
+		windows, exists := checkpoints[elem.checkpointKey()]
+		if !exists {
+			continue
+		}
+		numRestored += elem.restore(windows, beforeNanos, lastFlushedNanos)
+	}
+	l.RUnlock()
+	l.metrics.restoreWindows.Inc(int64(numRestored))
+}
+
+// checkpointKey returns the key identifying the element across aggregator
+// instances, which is derived from the metric id, the storage policy, the
+// aggregation types and the pipeline of the element.
+func (e *elemBase) checkpointKey() hash.Hash128 {
+	var (
+		sp       = e.sp.String()
+		aggTypes = e.aggTypes.String()
+		buf      = make([]byte, 0, binary.MaxVarintLen64+len(e.id)+len(sp)+len(aggTypes))
+	)
+	// NB: the id is prefixed with its length so ids that are prefixes of each
+	// other followed by certain storage policies do not produce the same key.
+	buf = appendUvarint(buf, uint64(len(e.id)))
+	buf = append(buf, e.id...)
+	buf = append(buf, sp...)
+	buf = append(buf, aggTypes...)
+	if e.parsedPipeline.HasRollup {
+		buf = append(buf, e.parsedPipeline.Transformations.String()...)
+		buf = append(buf, e.parsedPipeline.Rollup.String()...)
+		buf = append(buf, e.parsedPipeline.Remainder.String()...)
+	}
+	return hash.Murmur3Hash128(buf)
+}
+
+// checkpoint appends the open aggregation windows of the element to the checkpoint
+// until it contains the given maximum number of windows, returning false if any
+// window was left out.
+func (e *CounterElem) checkpoint(cp *checkpointpb.ShardCheckpoint, maxWindows int) bool {
+	e.RLock()
+	defer e.RUnlock()
+
+	if e.closed {
+		return true
+	}
+	key := e.checkpointKey()
+	for _, value := range e.values {
+		if len(cp.Counters) >= maxWindows {
+			return false
+		}
+		lockedAgg := value.lockedAgg
+		lockedAgg.Lock()
+		if lockedAgg.closed || lockedAgg.aggregation.Count() == 0 {
+			lockedAgg.Unlock()
+			continue
+		}
+		agg := &lockedAgg.aggregation
+		cp.Counters = append(cp.Counters, &checkpointpb.CounterWindowCheckpoint{
+			KeyHi:        key[0],
+			KeyLo:        key[1],
+			StartAtNanos: value.startAtNanos,
+			Sum:          agg.Sum(),
+			SumSq:        agg.SumSq(),
+			Count:        agg.Count(),
+			Min:          agg.Min(),
+			Max:          agg.Max(),
+		})
+		lockedAgg.Unlock()
+	}
+	return true
+}
+
+// restore merges the checkpointed windows that started before the given time and
+// ended after the last flushed time into the aggregation windows of the element,
+// returning the number of windows restored. The checkpoint must have been taken
+// before the element started receiving values so no value is counted twice.
+func (e *CounterElem) restore(
+	windows []*checkpointpb.CounterWindowCheckpoint,
+	beforeNanos int64,
+	lastFlushedNanos int64,
+) int {
+	var (
+		resolution  = e.sp.Resolution().Window.Nanoseconds()
+		numRestored int
+	)
+	for _, window := range windows {
+		if window.StartAtNanos >= beforeNanos || window.StartAtNanos+resolution <= lastFlushedNanos {
+			continue
+		}
+		lockedAgg, err := e.findOrCreate(window.StartAtNanos, createAggregationOptions{})
+		if err != nil {
+			return numRestored
+		}
+		lockedAgg.Lock()
+		if lockedAgg.closed {
+			lockedAgg.Unlock()
+			continue
+		}
+		lockedAgg.aggregation.Merge(window.Sum, window.SumSq, window.Count, window.Min, window.Max)
+		lockedAgg.Unlock()
+		numRestored++
+	}
+	return numRestored
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
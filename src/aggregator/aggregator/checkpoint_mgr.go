@@ -0,0 +1,255 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregator
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	checkpointpb "github.com/m3db/m3/src/aggregator/generated/proto/checkpoint"
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3x/clock"
+	"github.com/m3db/m3x/instrument"
+	"github.com/m3db/m3x/log"
+	"github.com/m3db/m3x/retry"
+	"github.com/m3db/m3x/watch"
+
+	"github.com/uber-go/tally"
+)
+
+// CheckpointManager manages the checkpoints of open aggregation windows stored
+// in kv so followers can restore them when taking over leadership. Checkpoints
+// are stored under a separate key for each shard to keep the size of each kv
+// value bounded.
+type CheckpointManager interface {
+	// Reset resets the checkpoint manager.
+	Reset() error
+
+	// Open opens the checkpoint manager.
+	Open(shardSetID uint32) error
+
+	// Get returns the latest checkpoint of a shard, or nil if the shard has
+	// not been checkpointed.
+	Get(shard uint32) (*checkpointpb.ShardCheckpoint, error)
+
+	// StoreAsync stores the checkpoints of the shards asynchronously.
+	StoreAsync(value *checkpointpb.ShardSetCheckpoint) error
+
+	// Close closes the checkpoint manager.
+	Close() error
+}
+
+type checkpointManagerState int
+
+const (
+	checkpointManagerNotOpen checkpointManagerState = iota
+	checkpointManagerOpen
+	checkpointManagerClosed
+)
+
+var (
+	errCheckpointManagerNotOpenOrClosed     = errors.New("checkpoint manager not open or closed")
+	errCheckpointManagerOpen                = errors.New("checkpoint manager open")
+	errCheckpointManagerAlreadyOpenOrClosed = errors.New("checkpoint manager already open or closed")
+)
+
+type checkpointManagerMetrics struct {
+	checkpointUnmarshalErrors tally.Counter
+	checkpointPersist         instrument.MethodMetrics
+}
+
+func newCheckpointManagerMetrics(scope tally.Scope) checkpointManagerMetrics {
+	return checkpointManagerMetrics{
+		checkpointUnmarshalErrors: scope.Counter("checkpoint-unmarshal-errors"),
+		checkpointPersist:         instrument.NewMethodMetrics(scope, "checkpoint-persist", 1.0),
+	}
+}
+
+type checkpointManager struct {
+	sync.RWMutex
+	sync.WaitGroup
+
+	nowFn                    clock.NowFn
+	logger                   log.Logger
+	checkpointKeyFmt         string
+	checkpointStore          kv.Store
+	checkpointPersistRetrier retry.Retrier
+
+	state            checkpointManagerState
+	doneCh           chan struct{}
+	shardSetID       uint32
+	persistWatchable watch.Watchable
+	metrics          checkpointManagerMetrics
+}
+
+// NewCheckpointManager creates a new checkpoint manager.
+func NewCheckpointManager(opts CheckpointManagerOptions) CheckpointManager {
+	instrumentOpts := opts.InstrumentOptions()
+	mgr := &checkpointManager{
+		nowFn:                    opts.ClockOptions().NowFn(),
+		logger:                   instrumentOpts.Logger(),
+		checkpointKeyFmt:         opts.CheckpointKeyFmt(),
+		checkpointStore:          opts.CheckpointStore(),
+		checkpointPersistRetrier: opts.CheckpointPersistRetrier(),
+		metrics:                  newCheckpointManagerMetrics(instrumentOpts.MetricsScope()),
+	}
+	mgr.Lock()
+	mgr.resetWithLock()
+	mgr.Unlock()
+	return mgr
+}
+
+func (mgr *checkpointManager) Reset() error {
+	mgr.Lock()
+	defer mgr.Unlock()
+
+	switch mgr.state {
+	case checkpointManagerNotOpen:
+		return nil
+	case checkpointManagerOpen:
+		return errCheckpointManagerOpen
+	default:
+		mgr.resetWithLock()
+		return nil
+	}
+}
+
+func (mgr *checkpointManager) Open(shardSetID uint32) error {
+	mgr.Lock()
+	defer mgr.Unlock()
+
+	if mgr.state != checkpointManagerNotOpen {
+		return errCheckpointManagerAlreadyOpenOrClosed
+	}
+	_, persistWatch, err := mgr.persistWatchable.Watch()
+	if err != nil {
+		return err
+	}
+	mgr.shardSetID = shardSetID
+	mgr.state = checkpointManagerOpen
+
+	mgr.Add(1)
+	go mgr.persistCheckpoints(persistWatch)
+
+	return nil
+}
+
+func (mgr *checkpointManager) Get(shard uint32) (*checkpointpb.ShardCheckpoint, error) {
+	mgr.RLock()
+	if mgr.state != checkpointManagerOpen {
+		mgr.RUnlock()
+		return nil, errCheckpointManagerNotOpenOrClosed
+	}
+	checkpointKey := mgr.checkpointKey(shard)
+	mgr.RUnlock()
+
+	value, err := mgr.checkpointStore.Get(checkpointKey)
+	if err == kv.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var proto checkpointpb.ShardCheckpoint
+	if err := value.Unmarshal(&proto); err != nil {
+		mgr.metrics.checkpointUnmarshalErrors.Inc(1)
+		return nil, err
+	}
+	return &proto, nil
+}
+
+func (mgr *checkpointManager) StoreAsync(value *checkpointpb.ShardSetCheckpoint) error {
+	mgr.RLock()
+	defer mgr.RUnlock()
+
+	if mgr.state != checkpointManagerOpen {
+		return errCheckpointManagerNotOpenOrClosed
+	}
+	mgr.persistWatchable.Update(value)
+	return nil
+}
+
+func (mgr *checkpointManager) Close() error {
+	mgr.Lock()
+	if mgr.state != checkpointManagerOpen {
+		mgr.Unlock()
+		return errCheckpointManagerNotOpenOrClosed
+	}
+	close(mgr.doneCh)
+	mgr.state = checkpointManagerClosed
+	mgr.Unlock()
+
+	mgr.Wait()
+	mgr.persistWatchable.Close()
+	return nil
+}
+
+func (mgr *checkpointManager) resetWithLock() {
+	mgr.state = checkpointManagerNotOpen
+	mgr.doneCh = make(chan struct{})
+	mgr.shardSetID = 0
+	mgr.persistWatchable = watch.NewWatchable()
+}
+
+func (mgr *checkpointManager) checkpointKey(shard uint32) string {
+	return fmt.Sprintf(mgr.checkpointKeyFmt, mgr.shardSetID, shard)
+}
+
+func (mgr *checkpointManager) persistCheckpoints(persistWatch watch.Watch) {
+	defer mgr.Done()
+
+	for {
+		select {
+		case <-mgr.doneCh:
+			return
+		case <-persistWatch.C():
+			checkpoint := persistWatch.Get().(*checkpointpb.ShardSetCheckpoint)
+			for shard, cp := range checkpoint.ByShard {
+				mgr.persistShardCheckpoint(shard, cp)
+			}
+		}
+	}
+}
+
+func (mgr *checkpointManager) persistShardCheckpoint(
+	shard uint32,
+	cp *checkpointpb.ShardCheckpoint,
+) {
+	var (
+		checkpointKey = mgr.checkpointKey(shard)
+		persistStart  = mgr.nowFn()
+	)
+	persistErr := mgr.checkpointPersistRetrier.Attempt(func() error {
+		_, err := mgr.checkpointStore.Set(checkpointKey, cp)
+		return err
+	})
+	duration := mgr.nowFn().Sub(persistStart)
+	if persistErr == nil {
+		mgr.metrics.checkpointPersist.ReportSuccess(duration)
+		return
+	}
+	mgr.metrics.checkpointPersist.ReportError(duration)
+	mgr.logger.WithFields(
+		log.NewField("checkpointKey", checkpointKey),
+		log.NewErrField(persistErr),
+	).Error("checkpoint persist error")
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregator
+
+import (
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3x/clock"
+	"github.com/m3db/m3x/instrument"
+	"github.com/m3db/m3x/retry"
+)
+
+const (
+	defaultCheckpointKeyFormat = "/shardset/%d/shard/%d/checkpoint"
+)
+
+// CheckpointManagerOptions provide a set of options for checkpoint manager.
+type CheckpointManagerOptions interface {
+	// SetClockOptions sets the clock options.
+	SetClockOptions(value clock.Options) CheckpointManagerOptions
+
+	// ClockOptions returns the clock options.
+	ClockOptions() clock.Options
+
+	// SetInstrumentOptions sets the instrument options.
+	SetInstrumentOptions(value instrument.Options) CheckpointManagerOptions
+
+	// InstrumentOptions returns the instrument options.
+	InstrumentOptions() instrument.Options
+
+	// SetCheckpointKeyFmt sets the checkpoint key format, which takes the shard
+	// set id and the shard id.
+	SetCheckpointKeyFmt(value string) CheckpointManagerOptions
+
+	// CheckpointKeyFmt returns the checkpoint key format.
+	CheckpointKeyFmt() string
+
+	// SetCheckpointStore sets the checkpoint store.
+	SetCheckpointStore(value kv.Store) CheckpointManagerOptions
+
+	// CheckpointStore returns the checkpoint store.
+	CheckpointStore() kv.Store
+
+	// SetCheckpointPersistRetrier sets the retrier for persisting checkpoints.
+	SetCheckpointPersistRetrier(value retry.Retrier) CheckpointManagerOptions
+
+	// CheckpointPersistRetrier returns the retrier for persisting checkpoints.
+	CheckpointPersistRetrier() retry.Retrier
+}
+
+type checkpointManagerOptions struct {
+	clockOpts                clock.Options
+	instrumentOpts           instrument.Options
+	checkpointKeyFmt         string
+	checkpointStore          kv.Store
+	checkpointPersistRetrier retry.Retrier
+}
+
+// NewCheckpointManagerOptions create a new set of checkpoint manager options.
+func NewCheckpointManagerOptions() CheckpointManagerOptions {
+	return &checkpointManagerOptions{
+		clockOpts:                clock.NewOptions(),
+		instrumentOpts:           instrument.NewOptions(),
+		checkpointKeyFmt:         defaultCheckpointKeyFormat,
+		checkpointPersistRetrier: retry.NewRetrier(retry.NewOptions()),
+	}
+}
+
+func (o *checkpointManagerOptions) SetClockOptions(value clock.Options) CheckpointManagerOptions {
+	opts := *o
+	opts.clockOpts = value
+	return &opts
+}
+
+func (o *checkpointManagerOptions) ClockOptions() clock.Options {
+	return o.clockOpts
+}
+
+func (o *checkpointManagerOptions) SetInstrumentOptions(value instrument.Options) CheckpointManagerOptions {
+	opts := *o
+	opts.instrumentOpts = value
+	return &opts
+}
+
+func (o *checkpointManagerOptions) InstrumentOptions() instrument.Options {
+	return o.instrumentOpts
+}
+
+func (o *checkpointManagerOptions) SetCheckpointKeyFmt(value string) CheckpointManagerOptions {
+	opts := *o
+	opts.checkpointKeyFmt = value
+	return &opts
+}
+
+func (o *checkpointManagerOptions) CheckpointKeyFmt() string {
+	return o.checkpointKeyFmt
+}
+
+func (o *checkpointManagerOptions) SetCheckpointStore(value kv.Store) CheckpointManagerOptions {
+	opts := *o
+	opts.checkpointStore = value
+	return &opts
+}
+
+func (o *checkpointManagerOptions) CheckpointStore() kv.Store {
+	return o.checkpointStore
+}
+
+func (o *checkpointManagerOptions) SetCheckpointPersistRetrier(value retry.Retrier) CheckpointManagerOptions {
+	opts := *o
+	opts.checkpointPersistRetrier = value
+	return &opts
+}
+
+func (o *checkpointManagerOptions) CheckpointPersistRetrier() retry.Retrier {
+	return o.checkpointPersistRetrier
+}
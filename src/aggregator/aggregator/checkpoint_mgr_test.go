@@ -0,0 +1,139 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregator
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	checkpointpb "github.com/m3db/m3/src/aggregator/generated/proto/checkpoint"
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/kv/mem"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCheckpointKeyFmt = "test/%d/shard/%d/checkpoint"
+)
+
+var (
+	testCheckpointProto = &checkpointpb.ShardSetCheckpoint{
+		ByShard: map[uint32]*checkpointpb.ShardCheckpoint{
+			0: &checkpointpb.ShardCheckpoint{
+				CheckpointedAtNanos: 1000,
+				Counters: []*checkpointpb.CounterWindowCheckpoint{
+					{KeyHi: 1, KeyLo: 2, StartAtNanos: 500, Sum: 10, Count: 2, Min: 4, Max: 6},
+				},
+			},
+			1: &checkpointpb.ShardCheckpoint{
+				CheckpointedAtNanos: 2000,
+			},
+		},
+	}
+)
+
+func TestCheckpointManagerReset(t *testing.T) {
+	mgr, _ := testCheckpointManager()
+
+	// Reseting an unopened manager is a no op.
+	require.NoError(t, mgr.Reset())
+	require.NoError(t, mgr.Open(testShardSetID))
+	require.NoError(t, mgr.Close())
+
+	// Opening a closed manager causes an error.
+	require.Error(t, mgr.Open(testShardSetID))
+
+	// Reseting the manager allows the manager to be reopened.
+	require.NoError(t, mgr.Reset())
+	require.NoError(t, mgr.Open(testShardSetID))
+	require.NoError(t, mgr.Close())
+
+	// Resetting an open manager causes an error.
+	mgr.state = checkpointManagerOpen
+	require.Equal(t, errCheckpointManagerOpen, mgr.Reset())
+}
+
+func TestCheckpointManagerGetClosed(t *testing.T) {
+	mgr, _ := testCheckpointManager()
+	_, err := mgr.Get(0)
+	require.Equal(t, errCheckpointManagerNotOpenOrClosed, err)
+}
+
+func TestCheckpointManagerGetNotFound(t *testing.T) {
+	mgr, _ := testCheckpointManager()
+	require.NoError(t, mgr.Open(testShardSetID))
+	defer mgr.Close()
+
+	res, err := mgr.Get(0)
+	require.NoError(t, err)
+	require.Nil(t, res)
+}
+
+func TestCheckpointManagerGetSuccess(t *testing.T) {
+	mgr, store := testCheckpointManager()
+	require.NoError(t, mgr.Open(testShardSetID))
+	defer mgr.Close()
+
+	key := fmt.Sprintf(testCheckpointKeyFmt, testShardSetID, 0)
+	_, err := store.Set(key, testCheckpointProto.ByShard[0])
+	require.NoError(t, err)
+
+	res, err := mgr.Get(0)
+	require.NoError(t, err)
+	require.Equal(t, testCheckpointProto.ByShard[0], res)
+}
+
+func TestCheckpointManagerStoreAsyncClosed(t *testing.T) {
+	mgr, _ := testCheckpointManager()
+	require.Equal(t, errCheckpointManagerNotOpenOrClosed, mgr.StoreAsync(testCheckpointProto))
+}
+
+func TestCheckpointManagerStoreAsyncSuccess(t *testing.T) {
+	mgr, store := testCheckpointManager()
+	require.NoError(t, mgr.Open(testShardSetID))
+	defer mgr.Close()
+
+	// Store the checkpoint and wait for the change of each shard to propagate.
+	require.NoError(t, mgr.StoreAsync(testCheckpointProto))
+	for shard, expected := range testCheckpointProto.ByShard {
+		key := fmt.Sprintf(testCheckpointKeyFmt, testShardSetID, shard)
+		for {
+			value, err := store.Get(key)
+			if value != nil && err == nil {
+				var res checkpointpb.ShardCheckpoint
+				require.NoError(t, value.Unmarshal(&res))
+				require.Equal(t, *expected, res)
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func testCheckpointManager() (*checkpointManager, kv.Store) {
+	store := mem.NewStore()
+	opts := NewCheckpointManagerOptions().
+		SetCheckpointKeyFmt(testCheckpointKeyFmt).
+		SetCheckpointStore(store)
+	return NewCheckpointManager(opts).(*checkpointManager), store
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregator
+
+import (
+	"testing"
+	"time"
+
+	checkpointpb "github.com/m3db/m3/src/aggregator/generated/proto/checkpoint"
+	maggregation "github.com/m3db/m3/src/metrics/aggregation"
+	"github.com/m3db/m3/src/metrics/pipeline/applied"
+	"github.com/m3db/m3/src/metrics/policy"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElemCheckpointKey(t *testing.T) {
+	e1 := MustNewCounterElem(testCounterID, testStoragePolicy, maggregation.DefaultTypes, applied.DefaultPipeline, 0, NoPrefixNoSuffix, NewOptions())
+	e2 := MustNewCounterElem(testCounterID, testStoragePolicy, maggregation.DefaultTypes, applied.DefaultPipeline, 0, NoPrefixNoSuffix, NewOptions())
+	require.Equal(t, e1.checkpointKey(), e2.checkpointKey())
+
+	sp := policy.NewStoragePolicy(time.Minute, xtime.Minute, 6*time.Hour)
+	e3 := MustNewCounterElem(testCounterID, sp, maggregation.DefaultTypes, applied.DefaultPipeline, 0, NoPrefixNoSuffix, NewOptions())
+	require.NotEqual(t, e1.checkpointKey(), e3.checkpointKey())
+
+	e4 := MustNewCounterElem(testCounterID, testStoragePolicy, testAggregationTypes, applied.DefaultPipeline, 0, NoPrefixNoSuffix, NewOptions())
+	require.NotEqual(t, e1.checkpointKey(), e4.checkpointKey())
+
+	e5 := MustNewCounterElem(testCounterID, testStoragePolicy, maggregation.DefaultTypes, testPipeline, 0, NoPrefixNoSuffix, NewOptions())
+	require.NotEqual(t, e1.checkpointKey(), e5.checkpointKey())
+}
+
+func TestCounterElemCheckpointAndRestore(t *testing.T) {
+	var (
+		resolution = testStoragePolicy.Resolution().Window
+		start      = time.Unix(1200, 0)
+		opts       = NewOptions()
+	)
+	leader := MustNewCounterElem(testCounterID, testStoragePolicy, maggregation.DefaultTypes, applied.DefaultPipeline, 0, NoPrefixNoSuffix, opts)
+	require.NoError(t, leader.AddValue(start, 1))
+	require.NoError(t, leader.AddValue(start, 2))
+	require.NoError(t, leader.AddValue(start.Add(resolution), 5))
+
+	var cp checkpointpb.ShardCheckpoint
+	require.True(t, leader.checkpoint(&cp, 10))
+	key := leader.checkpointKey()
+	expected := []*checkpointpb.CounterWindowCheckpoint{
+		{KeyHi: key[0], KeyLo: key[1], StartAtNanos: start.UnixNano(), Sum: 3, Count: 2, Min: 1, Max: 2},
+		{KeyHi: key[0], KeyLo: key[1], StartAtNanos: start.Add(resolution).UnixNano(), Sum: 5, Count: 1, Min: 5, Max: 5},
+	}
+	require.Equal(t, expected, cp.Counters)
+
+	// Windows beyond the maximum number of windows are left out.
+	var truncated checkpointpb.ShardCheckpoint
+	require.False(t, leader.checkpoint(&truncated, 1))
+	require.Equal(t, expected[:1], truncated.Counters)
+
+	// The follower only received the values after it was opened, which are
+	// merged with the checkpointed values.
+	openedAtNanos := start.Add(resolution).Add(time.Second).UnixNano()
+	follower := MustNewCounterElem(testCounterID, testStoragePolicy, maggregation.DefaultTypes, applied.DefaultPipeline, 0, NoPrefixNoSuffix, opts)
+	require.NoError(t, follower.AddValue(start.Add(resolution), 4))
+	require.NoError(t, follower.AddValue(start.Add(2*resolution), 7))
+	require.Equal(t, 2, follower.restore(cp.Counters, openedAtNanos, 0))
+	require.Equal(t, 3, len(follower.values))
+	for i, expectedSum := range []int64{3, 9, 7} {
+		require.Equal(t, expectedSum, follower.values[i].lockedAgg.aggregation.Sum())
+	}
+	require.Equal(t, int64(2), follower.values[1].lockedAgg.aggregation.Count())
+	require.Equal(t, int64(4), follower.values[1].lockedAgg.aggregation.Min())
+	require.Equal(t, int64(5), follower.values[1].lockedAgg.aggregation.Max())
+
+	// Windows that have been flushed or started after the follower was opened
+	// are not restored.
+	follower = MustNewCounterElem(testCounterID, testStoragePolicy, maggregation.DefaultTypes, applied.DefaultPipeline, 0, NoPrefixNoSuffix, opts)
+	require.Equal(t, 0, follower.restore(cp.Counters, start.Add(resolution).UnixNano(), start.Add(resolution).UnixNano()))
+	require.Equal(t, 0, len(follower.values))
+}
+
+func TestBaseMetricListCheckpointAndRestore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		resolution = testStoragePolicy.Resolution().Window
+		start      = time.Unix(1200, 0)
+		opts       = testOptions(ctrl)
+		listID     = standardMetricListID{resolution: resolution}
+	)
+	leaderList, err := newStandardMetricList(testShard, listID, opts)
+	require.NoError(t, err)
+	leaderCounter := MustNewCounterElem(testCounterID, testStoragePolicy, maggregation.DefaultTypes, applied.DefaultPipeline, 0, NoPrefixNoSuffix, opts)
+	leaderGauge := MustNewGaugeElem(testGaugeID, testStoragePolicy, maggregation.DefaultTypes, applied.DefaultPipeline, 0, NoPrefixNoSuffix, opts)
+	require.NoError(t, leaderCounter.AddValue(start, 10))
+	require.NoError(t, leaderGauge.AddValue(start, 20))
+	_, err = leaderList.PushBack(leaderCounter)
+	require.NoError(t, err)
+	_, err = leaderList.PushBack(leaderGauge)
+	require.NoError(t, err)
+
+	// Only counter windows are checkpointed.
+	cp := &checkpointpb.ShardCheckpoint{}
+	require.True(t, leaderList.Checkpoint(cp, 10))
+	require.Equal(t, 1, len(cp.Counters))
+
+	followerList, err := newStandardMetricList(testShard, listID, opts)
+	require.NoError(t, err)
+	followerCounter := MustNewCounterElem(testCounterID, testStoragePolicy, maggregation.DefaultTypes, applied.DefaultPipeline, 0, NoPrefixNoSuffix, opts)
+	_, err = followerList.PushBack(followerCounter)
+	require.NoError(t, err)
+
+	followerList.Restore(newCounterCheckpointsByKey(cp), start.Add(time.Second).UnixNano())
+	require.Equal(t, 1, len(followerCounter.values))
+	require.Equal(t, int64(10), followerCounter.values[0].lockedAgg.aggregation.Sum())
+}
+
+// testCheckpointingList is a flushing metric list that records checkpoint
+// and restore calls.
+type testCheckpointingList struct {
+	*MockflushingMetricList
+
+	windows      []*checkpointpb.CounterWindowCheckpoint
+	restored     counterCheckpointsByKey
+	restoredFrom int64
+}
+
+func (l *testCheckpointingList) Checkpoint(cp *checkpointpb.ShardCheckpoint, maxWindows int) bool {
+	for _, window := range l.windows {
+		if len(cp.Counters) >= maxWindows {
+			return false
+		}
+		cp.Counters = append(cp.Counters, window)
+	}
+	return true
+}
+
+func (l *testCheckpointingList) Restore(checkpoints counterCheckpointsByKey, beforeNanos int64) {
+	l.restored = checkpoints
+	l.restoredFrom = beforeNanos
+}
+
+func newTestCheckpointingList(
+	ctrl *gomock.Controller,
+	shard uint32,
+	windows []*checkpointpb.CounterWindowCheckpoint,
+) *testCheckpointingList {
+	flusher := NewMockflushingMetricList(ctrl)
+	flusher.EXPECT().Shard().Return(shard).AnyTimes()
+	return &testCheckpointingList{MockflushingMetricList: flusher, windows: windows}
+}
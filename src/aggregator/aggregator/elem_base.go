@@ -28,6 +28,7 @@ import (
 	"time"
 
 	raggregation "github.com/m3db/m3/src/aggregator/aggregation"
+	"github.com/m3db/m3/src/aggregator/hash"
 	maggregation "github.com/m3db/m3/src/metrics/aggregation"
 	"github.com/m3db/m3/src/metrics/metric"
 	"github.com/m3db/m3/src/metrics/metric/id"
@@ -52,6 +53,11 @@ const (
 	// compute first-order derivatives. This applies to the most common usecases
 	// without imposing significant bookkeeping overhead.
 	maxSupportedTransformationDerivativeOrder = 1
+
+	// Number of low bits dropped from the distinct tag value hash forwarded for
+	// distinct count rollups so the remaining bits are exactly representable as
+	// a float64.
+	distinctTagValueHashShift = 12
 )
 
 var (
@@ -59,6 +65,8 @@ var (
 	errElemClosed                = errors.New("element is closed")
 	errAggregationClosed         = errors.New("aggregation is closed")
 	errDuplicateForwardingSource = errors.New("duplicate forwarding source")
+	errNoDistinctTagValue        = errors.New("no distinct tag value for distinct count rollup")
+	distinctCountAggregationID   = maggregation.MustCompressTypes(maggregation.DistinctCount)
 )
 
 // isEarlierThanFn determines whether the timestamps of the metrics in a given
@@ -160,6 +168,7 @@ type elemBase struct {
 	aggTypes                        maggregation.Types
 	aggOpts                         raggregation.Options
 	parsedPipeline                  parsedPipeline
+	distinctTagValueHash            float64
 	numForwardedTimes               int
 	idPrefixSuffixType              IDPrefixSuffixType
 	writeForwardedMetricFn          writeForwardedMetricFn
@@ -199,7 +208,12 @@ func (e *elemBase) resetSetData(
 	e.aggTypes = aggTypes
 	e.useDefaultAggregation = useDefaultAggregation
 	e.aggOpts.ResetSetData(aggTypes)
+	e.aggOpts.Resolution = sp.Resolution().Window
 	e.parsedPipeline = parsed
+	e.distinctTagValueHash = 0
+	if parsed.HasDistinctCountRollup {
+		e.distinctTagValueHash = float64(hash.Murmur3Hash128(parsed.Rollup.DistinctTagValue)[0] >> distinctTagValueHashShift)
+	}
 	e.numForwardedTimes = numForwardedTimes
 	e.tombstoned = false
 	e.closed = false
@@ -373,6 +387,39 @@ func (e *gaugeElemBase) ResetSetData(
 
 func (e *gaugeElemBase) Close() {}
 
+type histogramElemBase struct{}
+
+func (e histogramElemBase) Type() metric.Type { return metric.HistogramType }
+
+func (e histogramElemBase) FullPrefix(opts Options) []byte { return opts.FullHistogramPrefix() }
+
+func (e histogramElemBase) DefaultAggregationTypes(aggTypesOpts maggregation.TypesOptions) maggregation.Types {
+	return aggTypesOpts.DefaultHistogramAggregationTypes()
+}
+
+func (e histogramElemBase) TypeStringFor(aggTypesOpts maggregation.TypesOptions, aggType maggregation.Type) []byte {
+	return aggTypesOpts.TypeStringForHistogram(aggType)
+}
+
+func (e histogramElemBase) ElemPool(opts Options) HistogramElemPool { return opts.HistogramElemPool() }
+
+func (e histogramElemBase) NewAggregation(_ Options, aggOpts raggregation.Options) histogramAggregation {
+	return newHistogramAggregation(raggregation.NewHistogram(aggOpts))
+}
+
+func (e *histogramElemBase) ResetSetData(
+	_ maggregation.TypesOptions,
+	aggTypes maggregation.Types,
+	_ bool,
+) error {
+	if !aggTypes.IsValidForHistogram() {
+		return fmt.Errorf("invalid aggregation types %s for histogram", aggTypes.String())
+	}
+	return nil
+}
+
+func (e *histogramElemBase) Close() {}
+
 // nolint: maligned
 type parsedPipeline struct {
 	// Whether the source pipeline contains derivative transformations at its head.
@@ -392,6 +439,11 @@ type parsedPipeline struct {
 	// source pipeline if applicable.
 	Rollup applied.RollupOp
 
+	// Whether the rollup operation computes the distinct count of the distinct tag values
+	// of its sources, in which case the hash of the distinct tag value is forwarded instead
+	// of the aggregated values.
+	HasDistinctCountRollup bool
+
 	// The remainder of the source pipeline after stripping the transformation
 	// and rollup operations from the head of the source pipeline.
 	Remainder applied.Pipeline
@@ -405,6 +457,8 @@ type parsedPipeline struct {
 //   rollup operation. Additionally, the transformation derivative order computed from
 //   the list of transformations must be no more than the maximum transformation derivative
 //   order that is supported.
+// * Additionally, a rollup operation computing the distinct count of its sources must
+//   not contain other aggregation types and must carry the distinct tag value of the source.
 func newParsedPipeline(pipeline applied.Pipeline) (parsedPipeline, error) {
 	if pipeline.IsEmpty() {
 		return parsedPipeline{}, nil
@@ -427,8 +481,9 @@ func newParsedPipeline(pipeline applied.Pipeline) (parsedPipeline, error) {
 			// We only care about the transformation operations at the head of the pipeline
 			// before the first rollup operation since those are going to be processed locally.
 			transformOp := pipelineOp.Transformation
-			// A binary transformation is a transformation that computes first-order derivatives.
-			if transformOp.Type.IsBinaryTransform() {
+			// Binary and window transformations are transformations that compute first-order
+			// derivatives.
+			if transformOp.Type.IsBinaryTransform() || transformOp.Type.IsWindowTransform() {
 				transformationDerivativeOrder++
 			}
 		}
@@ -445,11 +500,23 @@ func newParsedPipeline(pipeline applied.Pipeline) (parsedPipeline, error) {
 	if transformationDerivativeOrder > maxSupportedTransformationDerivativeOrder {
 		return parsedPipeline{}, fmt.Errorf("pipeline %v transformation derivative order is %d higher than supported %d", pipeline, transformationDerivativeOrder, maxSupportedTransformationDerivativeOrder)
 	}
+	// Distinct count rollups estimate the number of distinct tag values across their
+	// sources rather than aggregating the source values, so they cannot be combined with
+	// other aggregation types that require the source values to be forwarded.
+	rollupOp := pipeline.At(firstRollupOpIdx).Rollup
+	hasDistinctCountRollup := rollupOp.AggregationID.Contains(maggregation.DistinctCount)
+	if hasDistinctCountRollup && !rollupOp.AggregationID.Equal(distinctCountAggregationID) {
+		return parsedPipeline{}, fmt.Errorf("pipeline %v rollup aggregation %v cannot combine distinct count with other aggregation types", pipeline, rollupOp.AggregationID)
+	}
+	if hasDistinctCountRollup && len(rollupOp.DistinctTagValue) == 0 {
+		return parsedPipeline{}, fmt.Errorf("pipeline %v: %v", pipeline, errNoDistinctTagValue)
+	}
 	return parsedPipeline{
 		HasDerivativeTransform: transformationDerivativeOrder > 0,
 		Transformations:        pipeline.SubPipeline(0, firstRollupOpIdx),
 		HasRollup:              true,
-		Rollup:                 pipeline.At(firstRollupOpIdx).Rollup,
+		Rollup:                 rollupOp,
+		HasDistinctCountRollup: hasDistinctCountRollup,
 		Remainder:              pipeline.SubPipeline(firstRollupOpIdx+1, numSteps),
 	}, nil
 }
@@ -23,10 +23,12 @@ package aggregator
 import (
 	"strings"
 	"testing"
+	"time"
 
 	raggregation "github.com/m3db/m3/src/aggregator/aggregation"
 	maggregation "github.com/m3db/m3/src/metrics/aggregation"
 	"github.com/m3db/m3/src/metrics/metric"
+	"github.com/m3db/m3/src/metrics/metric/histogram"
 	"github.com/m3db/m3/src/metrics/metric/id"
 	"github.com/m3db/m3/src/metrics/metric/unaggregated"
 	"github.com/m3db/m3/src/metrics/pipeline"
@@ -82,6 +84,41 @@ func TestElemBaseResetSetData(t *testing.T) {
 	require.False(t, e.tombstoned)
 	require.False(t, e.closed)
 	require.Equal(t, WithPrefixWithSuffix, e.idPrefixSuffixType)
+	require.Equal(t, 0.0, e.distinctTagValueHash)
+}
+
+func TestElemBaseResetSetDataDistinctCountRollup(t *testing.T) {
+	newPipeline := func(distinctTagValue string) applied.Pipeline {
+		return applied.NewPipeline([]applied.OpUnion{
+			{
+				Type: pipeline.RollupOpType,
+				Rollup: applied.RollupOp{
+					ID:               []byte("foo"),
+					AggregationID:    maggregation.MustCompressTypes(maggregation.DistinctCount),
+					DistinctTagValue: []byte(distinctTagValue),
+				},
+			},
+		})
+	}
+	e := &elemBase{}
+	require.NoError(t, e.resetSetData(testCounterID, testStoragePolicy, testAggregationTypes, false, newPipeline("user1"), 0, WithPrefixWithSuffix))
+	require.True(t, e.parsedPipeline.HasDistinctCountRollup)
+	require.NotEqual(t, 0.0, e.distinctTagValueHash)
+	require.Equal(t, e.distinctTagValueHash, float64(int64(e.distinctTagValueHash)))
+
+	// The hash of the same tag value is stable across source ids.
+	e2 := &elemBase{}
+	require.NoError(t, e2.resetSetData([]byte("otherID"), testStoragePolicy, testAggregationTypes, false, newPipeline("user1"), 0, WithPrefixWithSuffix))
+	require.Equal(t, e.distinctTagValueHash, e2.distinctTagValueHash)
+
+	// Different tag values hash differently for the same source id.
+	e3 := &elemBase{}
+	require.NoError(t, e3.resetSetData(testCounterID, testStoragePolicy, testAggregationTypes, false, newPipeline("user2"), 0, WithPrefixWithSuffix))
+	require.NotEqual(t, e.distinctTagValueHash, e3.distinctTagValueHash)
+
+	// A distinct count rollup without a distinct tag value is invalid.
+	e4 := &elemBase{}
+	require.Error(t, e4.resetSetData(testCounterID, testStoragePolicy, testAggregationTypes, false, newPipeline(""), 0, WithPrefixWithSuffix))
 }
 
 func TestElemBaseResetSetDataInvalidPipeline(t *testing.T) {
@@ -300,6 +337,49 @@ func TestGaugeElemBaseResetSetDataInvalidTypes(t *testing.T) {
 	require.True(t, strings.Contains(err.Error(), "invalid aggregation types P99 for gauge"))
 }
 
+func TestHistogramElemBase(t *testing.T) {
+	opts := NewOptions()
+	aggTypesOpts := opts.AggregationTypesOptions()
+	e := histogramElemBase{}
+	require.Equal(t, []byte("stats.histograms."), e.FullPrefix(opts))
+	require.Equal(t, aggTypesOpts.DefaultHistogramAggregationTypes(), e.DefaultAggregationTypes(aggTypesOpts))
+	require.Equal(t, []byte(".p99"), e.TypeStringFor(aggTypesOpts, maggregation.P99))
+	require.Equal(t, []byte(".rate"), e.TypeStringFor(aggTypesOpts, maggregation.Rate))
+	require.True(t, opts.HistogramElemPool() == e.ElemPool(opts))
+}
+
+func TestHistogramElemBaseNewLockedAggregation(t *testing.T) {
+	e := histogramElemBase{}
+	la := e.NewAggregation(nil, raggregation.Options{})
+	schema := histogram.NewFixedSchema([]float64{10, 20})
+	la.AddUnion(unaggregated.MetricUnion{
+		Type:            metric.HistogramType,
+		HistogramSchema: schema,
+		HistogramCounts: []uint64{1, 2, 0},
+		HistogramSum:    40.0,
+	})
+	la.AddUnion(unaggregated.MetricUnion{
+		Type:            metric.HistogramType,
+		HistogramSchema: schema,
+		HistogramCounts: []uint64{3, 0, 1},
+		HistogramSum:    60.0,
+	})
+	require.Equal(t, 7.0, la.ValueOf(maggregation.Count))
+	require.Equal(t, 100.0, la.ValueOf(maggregation.Sum))
+}
+
+func TestHistogramElemBaseResetSetData(t *testing.T) {
+	e := histogramElemBase{}
+	require.NoError(t, e.ResetSetData(nil, maggregation.Types{maggregation.P99, maggregation.Rate}, false))
+}
+
+func TestHistogramElemBaseResetSetDataInvalidTypes(t *testing.T) {
+	e := histogramElemBase{}
+	err := e.ResetSetData(nil, maggregation.Types{maggregation.Last}, false)
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "invalid aggregation types Last for histogram"))
+}
+
 func TestParsedPipelineEmptyPipeline(t *testing.T) {
 	p := applied.Pipeline{}
 	pp, err := newParsedPipeline(p)
@@ -568,3 +648,75 @@ func TestParsePipelineTransformationDerivativeOrderTooHigh(t *testing.T) {
 	require.Error(t, err)
 	require.True(t, strings.Contains(err.Error(), "transformation derivative order is 2 higher than supported 1"))
 }
+
+func TestParsePipelineResetAwareTransformations(t *testing.T) {
+	rollupOp := applied.OpUnion{
+		Type: pipeline.RollupOpType,
+		Rollup: applied.RollupOp{
+			ID:            []byte("foo"),
+			AggregationID: maggregation.MustCompressTypes(maggregation.Count),
+		},
+	}
+	p := applied.NewPipeline([]applied.OpUnion{
+		{
+			Type:           pipeline.TransformationOpType,
+			Transformation: pipeline.TransformationOp{Type: transformation.Increase},
+		},
+		{
+			Type:           pipeline.TransformationOpType,
+			Transformation: pipeline.TransformationOp{Type: transformation.Multiply, Constant: 2},
+		},
+		rollupOp,
+	})
+	parsed, err := newParsedPipeline(p)
+	require.NoError(t, err)
+	require.True(t, parsed.HasDerivativeTransform)
+	require.Equal(t, p.SubPipeline(0, 2), parsed.Transformations)
+
+	// Increase and rate both compute first-order derivatives.
+	p = applied.NewPipeline([]applied.OpUnion{
+		{
+			Type:           pipeline.TransformationOpType,
+			Transformation: pipeline.TransformationOp{Type: transformation.Increase},
+		},
+		{
+			Type:           pipeline.TransformationOpType,
+			Transformation: pipeline.TransformationOp{Type: transformation.Rate, Window: time.Minute},
+		},
+		rollupOp,
+	})
+	_, err = newParsedPipeline(p)
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "transformation derivative order is 2 higher than supported 1"))
+}
+
+func TestParsePipelineDistinctCountRollup(t *testing.T) {
+	p := applied.NewPipeline([]applied.OpUnion{
+		{
+			Type: pipeline.RollupOpType,
+			Rollup: applied.RollupOp{
+				ID:            []byte("foo"),
+				AggregationID: maggregation.MustCompressTypes(maggregation.DistinctCount),
+			},
+		},
+	})
+	parsed, err := newParsedPipeline(p)
+	require.NoError(t, err)
+	require.True(t, parsed.HasRollup)
+	require.True(t, parsed.HasDistinctCountRollup)
+}
+
+func TestParsePipelineDistinctCountRollupWithOtherTypes(t *testing.T) {
+	p := applied.NewPipeline([]applied.OpUnion{
+		{
+			Type: pipeline.RollupOpType,
+			Rollup: applied.RollupOp{
+				ID:            []byte("foo"),
+				AggregationID: maggregation.MustCompressTypes(maggregation.DistinctCount, maggregation.Sum),
+			},
+		},
+	})
+	_, err := newParsedPipeline(p)
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "cannot combine distinct count with other aggregation types"))
+}
@@ -93,3 +93,24 @@ func TestGaugeElemPool(t *testing.T) {
 	require.Equal(t, testGaugeID, element.id)
 	require.Equal(t, testStoragePolicy, element.sp)
 }
+
+func TestHistogramElemPool(t *testing.T) {
+	p := NewHistogramElemPool(pool.NewObjectPoolOptions().SetSize(1))
+	p.Init(func() *HistogramElem {
+		return MustNewHistogramElem(nil, policy.EmptyStoragePolicy, aggregation.DefaultTypes, applied.DefaultPipeline, 0, NoPrefixNoSuffix, NewOptions())
+	})
+
+	// Retrieve an element from the pool.
+	element := p.Get()
+	require.NoError(t, element.ResetSetData(testHistogramID, testStoragePolicy, aggregation.DefaultTypes, applied.DefaultPipeline, 0, NoPrefixNoSuffix))
+	require.Equal(t, testHistogramID, element.id)
+	require.Equal(t, testStoragePolicy, element.sp)
+
+	// Put the element back to pool.
+	p.Put(element)
+
+	// Retrieve the element and assert it's the same element.
+	element = p.Get()
+	require.Equal(t, testHistogramID, element.id)
+	require.Equal(t, testStoragePolicy, element.sp)
+}
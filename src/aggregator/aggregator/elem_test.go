@@ -29,6 +29,7 @@ import (
 	"github.com/m3db/m3/src/aggregator/aggregation/quantile/cm"
 	maggregation "github.com/m3db/m3/src/metrics/aggregation"
 	"github.com/m3db/m3/src/metrics/metric"
+	"github.com/m3db/m3/src/metrics/metric/histogram"
 	"github.com/m3db/m3/src/metrics/metric/id"
 	"github.com/m3db/m3/src/metrics/metric/unaggregated"
 	"github.com/m3db/m3/src/metrics/pipeline"
@@ -46,6 +47,7 @@ var (
 	testCounterID                 = id.RawID("testCounter")
 	testBatchTimerID              = id.RawID("testBatchTimer")
 	testGaugeID                   = id.RawID("testGauge")
+	testHistogramID               = id.RawID("testHistogram")
 	testStoragePolicy             = policy.NewStoragePolicy(10*time.Second, xtime.Second, 6*time.Hour)
 	testAggregationTypes          = maggregation.Types{maggregation.Mean, maggregation.Sum}
 	testAggregationTypesExpensive = maggregation.Types{maggregation.SumSq}
@@ -65,6 +67,13 @@ var (
 		ID:       testGaugeID,
 		GaugeVal: 123.456,
 	}
+	testHistogram = unaggregated.MetricUnion{
+		Type:            metric.HistogramType,
+		ID:              testHistogramID,
+		HistogramSchema: histogram.NewFixedSchema([]float64{1, 10, 100}),
+		HistogramCounts: []uint64{3, 0, 12, 1},
+		HistogramSum:    567.8,
+	}
 	testPipeline = applied.NewPipeline([]applied.OpUnion{
 		{
 			Type:           pipeline.TransformationOpType,
@@ -584,6 +593,70 @@ func TestCounterElemConsumeCustomAggregationCustomPipeline(t *testing.T) {
 	require.Equal(t, 0, len(e.values))
 }
 
+func TestCounterElemConsumeDistinctCountRollup(t *testing.T) {
+	alignedstartAtNanos := []int64{
+		time.Unix(210, 0).UnixNano(),
+		time.Unix(220, 0).UnixNano(),
+		time.Unix(230, 0).UnixNano(),
+	}
+	counterVals := []int64{123, 456}
+	distinctCountPipeline := applied.NewPipeline([]applied.OpUnion{
+		{
+			Type: pipeline.RollupOpType,
+			Rollup: applied.RollupOp{
+				ID:               []byte("foo.bar"),
+				AggregationID:    maggregation.MustCompressTypes(maggregation.DistinctCount),
+				DistinctTagValue: []byte("user1"),
+			},
+		},
+	})
+	e := testCounterElem(alignedstartAtNanos[:2], counterVals, maggregation.Types{maggregation.Sum}, distinctCountPipeline, NewOptions())
+	require.NotEqual(t, 0.0, e.distinctTagValueHash)
+
+	// The distinct tag value hash is forwarded in place of the aggregated values.
+	aggKey := aggregationKey{
+		aggregationID:     maggregation.MustCompressTypes(maggregation.DistinctCount),
+		storagePolicy:     testStoragePolicy,
+		pipeline:          applied.NewPipeline([]applied.OpUnion{}),
+		numForwardedTimes: testNumForwardedTimes + 1,
+	}
+	expectedForwardedRes := []testForwardedMetricWithMetadata{
+		{
+			aggregationKey: aggKey,
+			timeNanos:      time.Unix(220, 0).UnixNano(),
+			value:          e.distinctTagValueHash,
+		},
+		{
+			aggregationKey: aggKey,
+			timeNanos:      time.Unix(230, 0).UnixNano(),
+			value:          e.distinctTagValueHash,
+		},
+	}
+	localFn, localRes := testFlushLocalMetricFn()
+	forwardFn, forwardRes := testFlushForwardedMetricFn()
+	onForwardedFlushedFn, _ := testOnForwardedFlushedFn()
+	require.False(t, e.Consume(alignedstartAtNanos[2], isStandardMetricEarlierThan, standardMetricTimestampNanos, localFn, forwardFn, onForwardedFlushedFn))
+	verifyForwardedMetrics(t, expectedForwardedRes, *forwardRes)
+	require.Equal(t, 0, len(*localRes))
+	require.Equal(t, 0, len(e.values))
+	require.Equal(t, time.Unix(230, 0).UnixNano(), e.lastConsumedAtNanos)
+}
+
+func TestCounterElemAddUniqueDistinctCount(t *testing.T) {
+	aggTypes := maggregation.Types{maggregation.DistinctCount}
+	e, err := NewCounterElem(testCounterID, testStoragePolicy, aggTypes, applied.DefaultPipeline, testNumForwardedTimes, NoPrefixNoSuffix, NewOptions())
+	require.NoError(t, err)
+
+	// Each source forwards the hash of its distinct tag value, which may be seen
+	// multiple times across sources but is only counted once.
+	for source := uint32(0); source < 100; source++ {
+		distinctTagValueHash := float64(source % 50)
+		require.NoError(t, e.AddUnique(testTimestamps[0], []float64{distinctTagValueHash}, source))
+	}
+	require.Equal(t, 1, len(e.values))
+	require.InDelta(t, 50.0, e.values[0].lockedAgg.aggregation.ValueOf(maggregation.DistinctCount), 1.0)
+}
+
 func TestCounterElemClose(t *testing.T) {
 	e := testCounterElem(testAlignedStarts[:len(testAlignedStarts)-1], testCounterVals, maggregation.DefaultTypes, applied.DefaultPipeline, NewOptions())
 	require.False(t, e.closed)
@@ -1625,6 +1698,161 @@ func TestGaugeElemConsumeCustomAggregationCustomPipeline(t *testing.T) {
 	require.Equal(t, 0, len(e.values))
 }
 
+func TestGaugeElemConsumeResetAwarePipeline(t *testing.T) {
+	alignedstartAtNanos := []int64{
+		time.Unix(210, 0).UnixNano(),
+		time.Unix(220, 0).UnixNano(),
+		time.Unix(230, 0).UnixNano(),
+		time.Unix(240, 0).UnixNano(),
+	}
+	// The gauge values are the cumulative values of a counter that is reset
+	// between the second and the third value.
+	gaugeVals := []float64{10.0, 15.0, 5.0}
+	aggregationTypes := maggregation.Types{maggregation.Last}
+	isEarlierThanFn := isStandardMetricEarlierThan
+	timestampNanosFn := standardMetricTimestampNanos
+	opts := NewOptions().SetDiscardNaNAggregatedValues(false)
+	rollupOp := applied.OpUnion{
+		Type: pipeline.RollupOpType,
+		Rollup: applied.RollupOp{
+			ID:            []byte("foo.bar"),
+			AggregationID: maggregation.MustCompressTypes(maggregation.Sum),
+		},
+	}
+	p := applied.NewPipeline([]applied.OpUnion{
+		{
+			Type:           pipeline.TransformationOpType,
+			Transformation: pipeline.TransformationOp{Type: transformation.Increase},
+		},
+		{
+			Type:           pipeline.TransformationOpType,
+			Transformation: pipeline.TransformationOp{Type: transformation.Multiply, Constant: 2},
+		},
+		{
+			Type:           pipeline.TransformationOpType,
+			Transformation: pipeline.TransformationOp{Type: transformation.Add, Constant: 1},
+		},
+		rollupOp,
+	})
+	e := testGaugeElem(alignedstartAtNanos[:3], gaugeVals, aggregationTypes, p, opts)
+
+	aggKey := aggregationKey{
+		aggregationID:     maggregation.MustCompressTypes(maggregation.Sum),
+		storagePolicy:     testStoragePolicy,
+		pipeline:          applied.NewPipeline([]applied.OpUnion{}),
+		numForwardedTimes: testNumForwardedTimes + 1,
+	}
+	expectedForwardedRes := []testForwardedMetricWithMetadata{
+		{
+			aggregationKey: aggKey,
+			timeNanos:      time.Unix(220, 0).UnixNano(),
+			value:          nan,
+		},
+		{
+			aggregationKey: aggKey,
+			timeNanos:      time.Unix(230, 0).UnixNano(),
+			value:          11.0,
+		},
+		{
+			aggregationKey: aggKey,
+			timeNanos:      time.Unix(240, 0).UnixNano(),
+			value:          11.0,
+		},
+	}
+	localFn, localRes := testFlushLocalMetricFn()
+	forwardFn, forwardRes := testFlushForwardedMetricFn()
+	onForwardedFlushedFn, _ := testOnForwardedFlushedFn()
+	require.False(t, e.Consume(alignedstartAtNanos[3], isEarlierThanFn, timestampNanosFn, localFn, forwardFn, onForwardedFlushedFn))
+	verifyForwardedMetrics(t, expectedForwardedRes, *forwardRes)
+	require.Equal(t, 0, len(*localRes))
+	require.Equal(t, 0, len(e.values))
+	require.Equal(t, time.Unix(240, 0).UnixNano(), e.lastConsumedAtNanos)
+	require.Equal(t, []float64{5.0}, e.lastConsumedValues)
+}
+
+func TestGaugeElemConsumeWindowTransformationPipeline(t *testing.T) {
+	alignedstartAtNanos := []int64{
+		time.Unix(210, 0).UnixNano(),
+		time.Unix(220, 0).UnixNano(),
+		time.Unix(230, 0).UnixNano(),
+		time.Unix(240, 0).UnixNano(),
+		time.Unix(250, 0).UnixNano(),
+		time.Unix(260, 0).UnixNano(),
+	}
+	// The gauge values are the cumulative values of a counter that is reset
+	// between the second and the third value.
+	gaugeVals := []float64{10.0, 15.0, 5.0, 9.0, 13.0}
+	aggregationTypes := maggregation.Types{maggregation.Last}
+	isEarlierThanFn := isStandardMetricEarlierThan
+	timestampNanosFn := standardMetricTimestampNanos
+	opts := NewOptions().SetDiscardNaNAggregatedValues(false)
+	rollupOp := applied.OpUnion{
+		Type: pipeline.RollupOpType,
+		Rollup: applied.RollupOp{
+			ID:            []byte("foo.bar"),
+			AggregationID: maggregation.MustCompressTypes(maggregation.Sum),
+		},
+	}
+	p := applied.NewPipeline([]applied.OpUnion{
+		{
+			Type:           pipeline.TransformationOpType,
+			Transformation: pipeline.TransformationOp{Type: transformation.Rate, Window: 20 * time.Second},
+		},
+		rollupOp,
+	})
+	e := testGaugeElem(alignedstartAtNanos[:5], gaugeVals, aggregationTypes, p, opts)
+
+	aggKey := aggregationKey{
+		aggregationID:     maggregation.MustCompressTypes(maggregation.Sum),
+		storagePolicy:     testStoragePolicy,
+		pipeline:          applied.NewPipeline([]applied.OpUnion{}),
+		numForwardedTimes: testNumForwardedTimes + 1,
+	}
+	// The rate is computed over the values consumed within the 20 second
+	// window before each value, taking the counter reset into account.
+	expectedForwardedRes := []testForwardedMetricWithMetadata{
+		{
+			aggregationKey: aggKey,
+			timeNanos:      time.Unix(220, 0).UnixNano(),
+			value:          nan,
+		},
+		{
+			aggregationKey: aggKey,
+			timeNanos:      time.Unix(230, 0).UnixNano(),
+			value:          0.5,
+		},
+		{
+			aggregationKey: aggKey,
+			timeNanos:      time.Unix(240, 0).UnixNano(),
+			value:          0.5,
+		},
+		{
+			aggregationKey: aggKey,
+			timeNanos:      time.Unix(250, 0).UnixNano(),
+			value:          0.45,
+		},
+		{
+			aggregationKey: aggKey,
+			timeNanos:      time.Unix(260, 0).UnixNano(),
+			value:          0.4,
+		},
+	}
+	localFn, localRes := testFlushLocalMetricFn()
+	forwardFn, forwardRes := testFlushForwardedMetricFn()
+	onForwardedFlushedFn, _ := testOnForwardedFlushedFn()
+	require.False(t, e.Consume(alignedstartAtNanos[5], isEarlierThanFn, timestampNanosFn, localFn, forwardFn, onForwardedFlushedFn))
+	verifyForwardedMetrics(t, expectedForwardedRes, *forwardRes)
+	require.Equal(t, 0, len(*localRes))
+	require.Equal(t, 0, len(e.values))
+	require.Equal(t, [][]transformation.Datapoint{
+		{
+			{TimeNanos: time.Unix(240, 0).UnixNano(), Value: 5.0},
+			{TimeNanos: time.Unix(250, 0).UnixNano(), Value: 9.0},
+			{TimeNanos: time.Unix(260, 0).UnixNano(), Value: 13.0},
+		},
+	}, e.consumedWindowValues)
+}
+
 func TestGaugeElemClose(t *testing.T) {
 	e := testGaugeElem(testAlignedStarts[:len(testAlignedStarts)-1], testGaugeVals, maggregation.DefaultTypes, applied.DefaultPipeline, NewOptions())
 	require.False(t, e.closed)
@@ -1644,6 +1872,7 @@ func TestGaugeElemClose(t *testing.T) {
 	require.Equal(t, 0, len(e.values))
 	require.Equal(t, 0, len(e.toConsume))
 	require.Equal(t, 0, len(e.lastConsumedValues))
+	require.Equal(t, 0, len(e.consumedWindowValues))
 	require.NotNil(t, e.values)
 }
 
@@ -1833,6 +2062,93 @@ func testTimerElem(
 	return e
 }
 
+func TestHistogramResetSetData(t *testing.T) {
+	opts := NewOptions()
+	he, err := NewHistogramElem(nil, policy.EmptyStoragePolicy, maggregation.DefaultTypes, applied.DefaultPipeline, testNumForwardedTimes, NoPrefixNoSuffix, opts)
+	require.NoError(t, err)
+	require.Equal(t, opts.AggregationTypesOptions().DefaultHistogramAggregationTypes(), he.aggTypes)
+	require.True(t, he.useDefaultAggregation)
+
+	// Reset element with custom aggregation types.
+	aggTypes := maggregation.Types{maggregation.Count, maggregation.Rate}
+	err = he.ResetSetData(testHistogramID, testStoragePolicy, aggTypes, applied.DefaultPipeline, 0, NoPrefixNoSuffix)
+	require.NoError(t, err)
+	require.Equal(t, testHistogramID, he.id)
+	require.Equal(t, testStoragePolicy, he.sp)
+	require.Equal(t, aggTypes, he.aggTypes)
+	require.False(t, he.useDefaultAggregation)
+	require.Equal(t, testStoragePolicy.Resolution().Window, he.aggOpts.Resolution)
+
+	// Resetting with aggregation types that are invalid for histograms results in an error.
+	err = he.ResetSetData(testHistogramID, testStoragePolicy, maggregation.Types{maggregation.Last}, applied.DefaultPipeline, 0, NoPrefixNoSuffix)
+	require.Error(t, err)
+}
+
+func TestHistogramElemAddUnion(t *testing.T) {
+	e, err := NewHistogramElem(testHistogramID, testStoragePolicy, maggregation.DefaultTypes, applied.DefaultPipeline, testNumForwardedTimes, NoPrefixNoSuffix, NewOptions())
+	require.NoError(t, err)
+
+	// Add a histogram metric.
+	require.NoError(t, e.AddUnion(testTimestamps[0], testHistogram))
+	require.Equal(t, 1, len(e.values))
+	require.Equal(t, testAlignedStarts[0], e.values[0].startAtNanos)
+	require.Equal(t, int64(16), e.values[0].lockedAgg.aggregation.Count())
+	require.Equal(t, testHistogram.HistogramSum, e.values[0].lockedAgg.aggregation.Sum())
+
+	// Add the histogram metric at slightly different time
+	// but still within the same aggregation interval.
+	require.NoError(t, e.AddUnion(testTimestamps[1], testHistogram))
+	require.Equal(t, 1, len(e.values))
+	require.Equal(t, int64(32), e.values[0].lockedAgg.aggregation.Count())
+	require.Equal(t, 2*testHistogram.HistogramSum, e.values[0].lockedAgg.aggregation.Sum())
+	require.Equal(t, 3.2, e.values[0].lockedAgg.aggregation.ValueOf(maggregation.Rate))
+
+	// Add the histogram metric in the next aggregation interval.
+	require.NoError(t, e.AddUnion(testTimestamps[2], testHistogram))
+	require.Equal(t, 2, len(e.values))
+	for i := 0; i < len(e.values); i++ {
+		require.Equal(t, testAlignedStarts[i], e.values[i].startAtNanos)
+	}
+	require.Equal(t, int64(16), e.values[1].lockedAgg.aggregation.Count())
+
+	// Adding the histogram metric to a closed element results in an error.
+	e.closed = true
+	require.Equal(t, errElemClosed, e.AddUnion(testTimestamps[2], testHistogram))
+}
+
+func TestHistogramElemConsumeCustomAggregationDefaultPipeline(t *testing.T) {
+	isEarlierThanFn := isStandardMetricEarlierThan
+	timestampNanosFn := standardMetricTimestampNanos
+	aggTypes := maggregation.Types{maggregation.Count, maggregation.Sum, maggregation.Rate, maggregation.P50}
+	e, err := NewHistogramElem(testHistogramID, testStoragePolicy, aggTypes, applied.DefaultPipeline, testNumForwardedTimes, WithPrefixWithSuffix, NewOptions())
+	require.NoError(t, err)
+	require.NoError(t, e.AddUnion(testTimestamps[0], testHistogram))
+
+	localFn, localRes := testFlushLocalMetricFn()
+	forwardFn, forwardRes := testFlushForwardedMetricFn()
+	onForwardedFlushedFn, onForwardedFlushedRes := testOnForwardedFlushedFn()
+	require.False(t, e.Consume(testAlignedStarts[1], isEarlierThanFn, timestampNanosFn, localFn, forwardFn, onForwardedFlushedFn))
+
+	// 3 values in (-Inf, 1], 12 values in (10, 100] and 1 value in the overflow
+	// bucket, the median lies at 5/12 of the third bucket.
+	expectedValues := []float64{16, 567.8, 1.6, 10 + 90*5.0/12}
+	var expected []testLocalMetricWithMetadata
+	for i, aggType := range aggTypes {
+		expected = append(expected, testLocalMetricWithMetadata{
+			idPrefix:  []byte("stats.histograms."),
+			id:        testHistogramID,
+			idSuffix:  testOpts.AggregationTypesOptions().TypeStringForHistogram(aggType),
+			timeNanos: testAlignedStarts[1],
+			value:     expectedValues[i],
+			sp:        testStoragePolicy,
+		})
+	}
+	require.Equal(t, expected, *localRes)
+	require.Equal(t, 0, len(*forwardRes))
+	require.Equal(t, 0, len(*onForwardedFlushedRes))
+	require.Equal(t, 0, len(e.values))
+}
+
 func testGaugeElem(
 	alignedstartAtNanos []int64,
 	gaugeVals []float64,
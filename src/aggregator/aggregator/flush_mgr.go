@@ -95,6 +95,14 @@ type roleBasedFlushManager interface {
 	Close()
 }
 
+// checkpointRestorer restores the open aggregation windows checkpointed by the
+// previous leader when taking over leadership.
+type checkpointRestorer interface {
+	// PrepareRestore prepares for restoring the checkpoints, returning nil if
+	// there is nothing to restore.
+	PrepareRestore(buckets []*flushBucket) flushTask
+}
+
 var (
 	errFlushManagerAlreadyOpenOrClosed = errors.New("flush manager is already open or closed")
 	errFlushManagerNotOpenOrClosed     = errors.New("flush manager is not open or closed")
@@ -331,10 +339,20 @@ func (mgr *flushManager) flush() {
 		// If the election state has changed, we need to switch the flush manager.
 		newElectionState := mgr.checkElectionState()
 		if electionState != newElectionState {
+			var restoreTask flushTask
 			mgr.Lock()
+			if r, ok := mgr.followerMgr.(checkpointRestorer); ok && newElectionState == LeaderState {
+				restoreTask = r.PrepareRestore(mgr.buckets)
+			}
 			mgr.electionState = newElectionState
 			mgr.flushManagerWithLock().Init(mgr.buckets)
 			mgr.Unlock()
+
+			// NB: the checkpoints are restored before the new leader flushes any
+			// of the windows they were taken from.
+			if restoreTask != nil {
+				restoreTask.Run()
+			}
 		}
 
 		mgr.RLock()
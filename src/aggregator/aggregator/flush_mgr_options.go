@@ -36,6 +36,11 @@ const (
 	defaultFlushTimesPersistEvery = 10 * time.Second
 	defaultMaxBufferSize          = 5 * time.Minute
 	defaultForcedFlushWindowSize  = 10 * time.Second
+
+	// NB: a checkpointed counter window takes up to 60 bytes when encoded, which
+	// keeps the checkpoint of a single shard well below the default etcd request
+	// size limit of 1.5MB.
+	defaultMaxCheckpointWindowsPerShard = 16384
 )
 
 var (
@@ -107,6 +112,29 @@ type FlushManagerOptions interface {
 	// FlushTimesPersistEvery returns how frequently the flush times are stored in kv.
 	FlushTimesPersistEvery() time.Duration
 
+	// SetCheckpointManager sets the checkpoint manager. If nil, open aggregation
+	// windows are not checkpointed.
+	SetCheckpointManager(value CheckpointManager) FlushManagerOptions
+
+	// CheckpointManager returns the checkpoint manager.
+	CheckpointManager() CheckpointManager
+
+	// SetCheckpointEvery sets how frequently the leader checkpoints the open aggregation
+	// windows in kv. A non-positive value disables checkpointing.
+	SetCheckpointEvery(value time.Duration) FlushManagerOptions
+
+	// CheckpointEvery returns how frequently the leader checkpoints the open aggregation
+	// windows in kv.
+	CheckpointEvery() time.Duration
+
+	// SetMaxCheckpointWindowsPerShard sets the maximum number of open aggregation
+	// windows checkpointed for a single shard.
+	SetMaxCheckpointWindowsPerShard(value int) FlushManagerOptions
+
+	// MaxCheckpointWindowsPerShard returns the maximum number of open aggregation
+	// windows checkpointed for a single shard.
+	MaxCheckpointWindowsPerShard() int
+
 	// SetMaxBufferSize sets the maximum duration data are buffered for without getting
 	// flushed or discarded to handle transient KV issues or for backing out of active
 	// topology changes.
@@ -125,18 +153,21 @@ type FlushManagerOptions interface {
 }
 
 type flushManagerOptions struct {
-	clockOpts              clock.Options
-	instrumentOpts         instrument.Options
-	checkEvery             time.Duration
-	jitterEnabled          bool
-	maxJitterFn            FlushJitterFn
-	workerPool             sync.WorkerPool
-	placementManager       PlacementManager
-	electionManager        ElectionManager
-	flushTimesManager      FlushTimesManager
-	flushTimesPersistEvery time.Duration
-	maxBufferSize          time.Duration
-	forcedFlushWindowSize  time.Duration
+	clockOpts                    clock.Options
+	instrumentOpts               instrument.Options
+	checkEvery                   time.Duration
+	jitterEnabled                bool
+	maxJitterFn                  FlushJitterFn
+	workerPool                   sync.WorkerPool
+	placementManager             PlacementManager
+	electionManager              ElectionManager
+	flushTimesManager            FlushTimesManager
+	flushTimesPersistEvery       time.Duration
+	checkpointManager            CheckpointManager
+	checkpointEvery              time.Duration
+	maxCheckpointWindowsPerShard int
+	maxBufferSize                time.Duration
+	forcedFlushWindowSize        time.Duration
 }
 
 // NewFlushManagerOptions create a new set of flush manager options.
@@ -144,14 +175,15 @@ func NewFlushManagerOptions() FlushManagerOptions {
 	workerPool := sync.NewWorkerPool(defaultWorkerPoolSize)
 	workerPool.Init()
 	return &flushManagerOptions{
-		clockOpts:              clock.NewOptions(),
-		instrumentOpts:         instrument.NewOptions(),
-		checkEvery:             defaultCheckEvery,
-		jitterEnabled:          defaultJitterEnabled,
-		workerPool:             workerPool,
-		flushTimesPersistEvery: defaultFlushTimesPersistEvery,
-		maxBufferSize:          defaultMaxBufferSize,
-		forcedFlushWindowSize:  defaultForcedFlushWindowSize,
+		clockOpts:                    clock.NewOptions(),
+		instrumentOpts:               instrument.NewOptions(),
+		checkEvery:                   defaultCheckEvery,
+		jitterEnabled:                defaultJitterEnabled,
+		workerPool:                   workerPool,
+		flushTimesPersistEvery:       defaultFlushTimesPersistEvery,
+		maxCheckpointWindowsPerShard: defaultMaxCheckpointWindowsPerShard,
+		maxBufferSize:                defaultMaxBufferSize,
+		forcedFlushWindowSize:        defaultForcedFlushWindowSize,
 	}
 }
 
@@ -255,6 +287,36 @@ func (o *flushManagerOptions) FlushTimesPersistEvery() time.Duration {
 	return o.flushTimesPersistEvery
 }
 
+func (o *flushManagerOptions) SetCheckpointManager(value CheckpointManager) FlushManagerOptions {
+	opts := *o
+	opts.checkpointManager = value
+	return &opts
+}
+
+func (o *flushManagerOptions) CheckpointManager() CheckpointManager {
+	return o.checkpointManager
+}
+
+func (o *flushManagerOptions) SetCheckpointEvery(value time.Duration) FlushManagerOptions {
+	opts := *o
+	opts.checkpointEvery = value
+	return &opts
+}
+
+func (o *flushManagerOptions) CheckpointEvery() time.Duration {
+	return o.checkpointEvery
+}
+
+func (o *flushManagerOptions) SetMaxCheckpointWindowsPerShard(value int) FlushManagerOptions {
+	opts := *o
+	opts.maxCheckpointWindowsPerShard = value
+	return &opts
+}
+
+func (o *flushManagerOptions) MaxCheckpointWindowsPerShard() int {
+	return o.maxCheckpointWindowsPerShard
+}
+
 func (o *flushManagerOptions) SetMaxBufferSize(value time.Duration) FlushManagerOptions {
 	opts := *o
 	opts.maxBufferSize = value
@@ -24,6 +24,7 @@ import (
 	"sync"
 	"time"
 
+	checkpointpb "github.com/m3db/m3/src/aggregator/generated/proto/checkpoint"
 	schema "github.com/m3db/m3/src/aggregator/generated/proto/flush"
 	"github.com/m3db/m3x/clock"
 	"github.com/m3db/m3x/log"
@@ -70,13 +71,18 @@ func newForwardedFlusherMetrics(scope tally.Scope) forwardedFollowerFlusherMetri
 }
 
 type followerFlushManagerMetrics struct {
-	watchCreateErrors tally.Counter
-	kvUpdateFlush     tally.Counter
-	forcedFlush       tally.Counter
-	notCampaigning    tally.Counter
-	standard          standardFollowerFlusherMetrics
-	forwarded         forwardedFollowerFlusherMetrics
-	timed             standardFollowerFlusherMetrics
+	watchCreateErrors     tally.Counter
+	kvUpdateFlush         tally.Counter
+	forcedFlush           tally.Counter
+	notCampaigning        tally.Counter
+	checkpointFetchErrors tally.Counter
+	checkpointStale       tally.Counter
+	checkpointRestore     tally.Counter
+	checkpointNoShard     tally.Counter
+	checkpointRestoreDur  tally.Timer
+	standard              standardFollowerFlusherMetrics
+	forwarded             forwardedFollowerFlusherMetrics
+	timed                 standardFollowerFlusherMetrics
 }
 
 func newFollowerFlushManagerMetrics(scope tally.Scope) followerFlushManagerMetrics {
@@ -84,13 +90,18 @@ func newFollowerFlushManagerMetrics(scope tally.Scope) followerFlushManagerMetri
 	forwardedScope := scope.Tagged(map[string]string{"flusher-type": "forwarded"})
 	timedScope := scope.Tagged(map[string]string{"flusher-type": "timed"})
 	return followerFlushManagerMetrics{
-		watchCreateErrors: scope.Counter("watch-create-errors"),
-		kvUpdateFlush:     scope.Counter("kv-update-flush"),
-		forcedFlush:       scope.Counter("forced-flush"),
-		notCampaigning:    scope.Counter("not-campaigning"),
-		standard:          newStandardFlusherMetrics(standardScope),
-		forwarded:         newForwardedFlusherMetrics(forwardedScope),
-		timed:             newStandardFlusherMetrics(timedScope),
+		watchCreateErrors:     scope.Counter("watch-create-errors"),
+		kvUpdateFlush:         scope.Counter("kv-update-flush"),
+		forcedFlush:           scope.Counter("forced-flush"),
+		notCampaigning:        scope.Counter("not-campaigning"),
+		checkpointFetchErrors: scope.Counter("checkpoint-fetch-errors"),
+		checkpointStale:       scope.Counter("checkpoint-stale"),
+		checkpointRestore:     scope.Counter("checkpoint-restore"),
+		checkpointNoShard:     scope.Counter("checkpoint-shard-not-found"),
+		checkpointRestoreDur:  scope.Timer("checkpoint-restore-duration"),
+		standard:              newStandardFlusherMetrics(standardScope),
+		forwarded:             newForwardedFlusherMetrics(forwardedScope),
+		timed:                 newStandardFlusherMetrics(timedScope),
 	}
 }
 
@@ -104,6 +115,8 @@ type followerFlushManager struct {
 	placementManager      PlacementManager
 	electionManager       ElectionManager
 	flushTimesManager     FlushTimesManager
+	checkpointManager     CheckpointManager
+	checkpointEvery       time.Duration
 	maxBufferSize         time.Duration
 	forcedFlushWindowSize time.Duration
 	logger                log.Logger
@@ -113,10 +126,14 @@ type followerFlushManager struct {
 	received        *schema.ShardSetFlushTimes
 	processed       *schema.ShardSetFlushTimes
 	flushTimesState flushTimesState
+	fetchedShards   map[uint32]struct{}
+	checkpoints     map[uint32]*checkpointpb.ShardCheckpoint
 	flushMode       followerFlushMode
 	lastFlushed     time.Time
 	openedAt        time.Time
 	flushTask       *followerFlushTask
+	fetchTask       *followerCheckpointFetchTask
+	restoreTask     *followerRestoreTask
 	sleepFn         sleepFn
 	metrics         followerFlushManagerMetrics
 }
@@ -135,6 +152,8 @@ func newFollowerFlushManager(
 		placementManager:      opts.PlacementManager(),
 		electionManager:       opts.ElectionManager(),
 		flushTimesManager:     opts.FlushTimesManager(),
+		checkpointManager:     opts.CheckpointManager(),
+		checkpointEvery:       opts.CheckpointEvery(),
 		maxBufferSize:         opts.MaxBufferSize(),
 		forcedFlushWindowSize: opts.ForcedFlushWindowSize(),
 		logger:                instrumentOpts.Logger(),
@@ -142,11 +161,18 @@ func newFollowerFlushManager(
 		doneCh:                doneCh,
 		flushTimesState:       flushTimesUninitialized,
 		flushMode:             unknownFollowerFlush,
+		fetchedShards:         make(map[uint32]struct{}),
+		checkpoints:           make(map[uint32]*checkpointpb.ShardCheckpoint),
 		lastFlushed:           nowFn(),
 		sleepFn:               time.Sleep,
 		metrics:               newFollowerFlushManagerMetrics(scope),
 	}
 	mgr.flushTask = &followerFlushTask{mgr: mgr}
+	mgr.fetchTask = &followerCheckpointFetchTask{
+		mgr:     mgr,
+		pending: make(map[uint32]struct{}),
+	}
+	mgr.restoreTask = &followerRestoreTask{mgr: mgr}
 	return mgr
 }
 
@@ -198,6 +224,9 @@ func (mgr *followerFlushManager) Prepare(buckets []*flushBucket) (flushTask, tim
 	}
 
 	if !needsFlush {
+		if mgr.prepareCheckpointFetchWithLock(buckets, now) {
+			return mgr.fetchTask, mgr.checkEvery
+		}
 		return nil, mgr.checkEvery
 	}
 	mgr.lastFlushed = now
@@ -205,6 +234,71 @@ func (mgr *followerFlushManager) Prepare(buckets []*flushBucket) (flushTask, tim
 	return mgr.flushTask, 0
 }
 
+// PrepareRestore prepares for merging the checkpoints fetched by the follower
+// when it takes over leadership, returning nil if there is nothing to restore.
+// The checkpoints are only restored once.
+func (mgr *followerFlushManager) PrepareRestore(buckets []*flushBucket) flushTask {
+	mgr.Lock()
+	defer mgr.Unlock()
+
+	if len(mgr.checkpoints) == 0 {
+		return nil
+	}
+	mgr.restoreTask.checkpoints = mgr.checkpoints
+	mgr.restoreTask.lists = checkpointingListsFrom(buckets, mgr.restoreTask.lists[:0])
+	mgr.checkpoints = make(map[uint32]*checkpointpb.ShardCheckpoint)
+	return mgr.restoreTask
+}
+
+// prepareCheckpointFetchWithLock prepares for fetching the checkpoints of the
+// shards whose checkpoints have not been fetched yet, returning true if there
+// are any.
+//
+// NB: a checkpoint can only be merged into the windows of the follower if it was
+// taken before the follower was opened, otherwise the values received by both
+// would be counted twice. Once the leader has had the time to take a checkpoint
+// after the follower was opened, the checkpoints are therefore no longer fetched.
+func (mgr *followerFlushManager) prepareCheckpointFetchWithLock(
+	buckets []*flushBucket,
+	now time.Time,
+) bool {
+	if mgr.checkpointManager == nil || mgr.checkpointEvery <= 0 {
+		return false
+	}
+	if now.Sub(mgr.openedAt) >= mgr.checkpointEvery {
+		return false
+	}
+	task := mgr.fetchTask
+	for shard := range task.pending {
+		delete(task.pending, shard)
+	}
+	task.shards = task.shards[:0]
+	task.lists = checkpointingListsFrom(buckets, task.lists[:0])
+	for _, l := range task.lists {
+		shard := l.Shard()
+		if _, fetched := mgr.fetchedShards[shard]; fetched {
+			continue
+		}
+		if _, pending := task.pending[shard]; pending {
+			continue
+		}
+		task.pending[shard] = struct{}{}
+		task.shards = append(task.shards, shard)
+	}
+	return len(task.shards) > 0
+}
+
+// isCheckpointUsableWithLock returns true if the checkpoint was taken before the
+// follower was opened. Checkpoints taken more than the max buffer size before
+// only contain windows that have been flushed or discarded since.
+func (mgr *followerFlushManager) isCheckpointUsableWithLock(
+	cp *checkpointpb.ShardCheckpoint,
+) bool {
+	openedAtNanos := mgr.openedAt.UnixNano()
+	return cp.CheckpointedAtNanos < openedAtNanos &&
+		cp.CheckpointedAtNanos >= openedAtNanos-mgr.maxBufferSize.Nanoseconds()
+}
+
 // NB(xichen): The follower flush manager flushes data based on the flush times
 // stored in kv and does not need to take extra actions when a new bucket is added.
 func (mgr *followerFlushManager) OnBucketAdded(int, *flushBucket) {}
@@ -507,6 +601,72 @@ func (t *followerFlushTask) Run() {
 	}
 }
 
+// followerCheckpointFetchTask fetches the latest checkpoints of the shards owned
+// by the follower.
+type followerCheckpointFetchTask struct {
+	mgr     *followerFlushManager
+	lists   []checkpointingMetricList
+	pending map[uint32]struct{}
+	shards  []uint32
+}
+
+func (t *followerCheckpointFetchTask) Run() {
+	mgr := t.mgr
+	for _, shard := range t.shards {
+		cp, err := mgr.checkpointManager.Get(shard)
+		if err != nil {
+			// NB: the checkpoint is fetched again on the next check.
+			mgr.metrics.checkpointFetchErrors.Inc(1)
+			mgr.logger.Errorf("unable to fetch checkpoint for shard %d: %v", shard, err)
+			continue
+		}
+		mgr.Lock()
+		mgr.fetchedShards[shard] = struct{}{}
+		switch {
+		case cp == nil:
+		case mgr.isCheckpointUsableWithLock(cp):
+			mgr.checkpoints[shard] = cp
+		default:
+			mgr.metrics.checkpointStale.Inc(1)
+		}
+		mgr.Unlock()
+	}
+}
+
+// followerRestoreTask merges the open aggregation windows checkpointed by the
+// previous leader into the windows of the follower taking over leadership, so
+// it has complete aggregations for the windows containing the time it was
+// opened at.
+type followerRestoreTask struct {
+	mgr         *followerFlushManager
+	checkpoints map[uint32]*checkpointpb.ShardCheckpoint
+	lists       []checkpointingMetricList
+}
+
+func (t *followerRestoreTask) Run() {
+	var (
+		mgr           = t.mgr
+		start         = mgr.nowFn()
+		openedAtNanos = mgr.openedAt.UnixNano()
+		byShard       = make(map[uint32]counterCheckpointsByKey, len(t.checkpoints))
+	)
+	for shard, cp := range t.checkpoints {
+		byShard[shard] = newCounterCheckpointsByKey(cp)
+	}
+	for _, l := range t.lists {
+		checkpoints, exists := byShard[l.Shard()]
+		if !exists {
+			mgr.metrics.checkpointNoShard.Inc(1)
+			continue
+		}
+		l.Restore(checkpoints, openedAtNanos)
+	}
+	t.checkpoints = nil
+	t.lists = t.lists[:0]
+	mgr.metrics.checkpointRestore.Inc(1)
+	mgr.metrics.checkpointRestoreDur.Record(mgr.nowFn().Sub(start))
+}
+
 type flushTimesState int
 
 const (
@@ -21,9 +21,11 @@
 package aggregator
 
 import (
+	"errors"
 	"testing"
 	"time"
 
+	checkpointpb "github.com/m3db/m3/src/aggregator/generated/proto/checkpoint"
 	"github.com/m3db/m3/src/cluster/kv/mem"
 	"github.com/m3db/m3x/watch"
 
@@ -388,6 +390,132 @@ func TestFollowerFlushManagerPrepareMaxBufferSizeExceeded(t *testing.T) {
 	require.Equal(t, time.Second, dur)
 }
 
+func TestFollowerFlushManagerPrepareCheckpointFetch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		now      = time.Unix(1234, 0)
+		nowFn    = func() time.Time { return now }
+		openedAt = now.Add(-time.Second)
+		doneCh   = make(chan struct{})
+		window   = &checkpointpb.CounterWindowCheckpoint{KeyHi: 1, KeyLo: 2, StartAtNanos: 1000, Sum: 5}
+		cp0      = &checkpointpb.ShardCheckpoint{
+			CheckpointedAtNanos: openedAt.Add(-time.Second).UnixNano(),
+			Counters:            []*checkpointpb.CounterWindowCheckpoint{window},
+		}
+		// Checkpoints taken after the follower was opened are not restored.
+		cp1 = &checkpointpb.ShardCheckpoint{
+			CheckpointedAtNanos: openedAt.Add(time.Millisecond).UnixNano(),
+			Counters:            []*checkpointpb.CounterWindowCheckpoint{window},
+		}
+	)
+	checkpointManager := NewMockCheckpointManager(ctrl)
+	gomock.InOrder(
+		checkpointManager.EXPECT().Get(uint32(0)).Return(cp0, nil),
+		checkpointManager.EXPECT().Get(uint32(1)).Return(cp1, nil),
+		checkpointManager.EXPECT().Get(uint32(2)).Return(nil, errors.New("error")),
+		checkpointManager.EXPECT().Get(uint32(2)).Return(nil, nil),
+	)
+	opts := NewFlushManagerOptions().
+		SetMaxBufferSize(time.Minute).
+		SetCheckEvery(time.Second).
+		SetCheckpointManager(checkpointManager).
+		SetCheckpointEvery(10 * time.Second)
+	mgr := newFollowerFlushManager(doneCh, opts).(*followerFlushManager)
+	mgr.nowFn = nowFn
+	mgr.openedAt = openedAt
+	mgr.flushTimesState = flushTimesProcessed
+	mgr.lastFlushed = now
+
+	buckets := []*flushBucket{
+		&flushBucket{
+			bucketID: standardMetricListID{resolution: time.Second}.toMetricListID(),
+			interval: time.Second,
+			flushers: []flushingMetricList{
+				newTestCheckpointingList(ctrl, 0, nil),
+				newTestCheckpointingList(ctrl, 1, nil),
+				newTestCheckpointingList(ctrl, 2, nil),
+			},
+		},
+		&flushBucket{
+			bucketID: standardMetricListID{resolution: time.Minute}.toMetricListID(),
+			interval: time.Minute,
+			flushers: []flushingMetricList{
+				newTestCheckpointingList(ctrl, 0, nil),
+			},
+		},
+	}
+
+	task, dur := mgr.Prepare(buckets)
+	require.Equal(t, mgr.fetchTask, task)
+	require.Equal(t, time.Second, dur)
+	require.Equal(t, []uint32{0, 1, 2}, mgr.fetchTask.shards)
+	task.Run()
+	require.Equal(t, map[uint32]*checkpointpb.ShardCheckpoint{0: cp0}, mgr.checkpoints)
+
+	// Checkpoints that could not be fetched are fetched again.
+	task, _ = mgr.Prepare(buckets)
+	require.Equal(t, mgr.fetchTask, task)
+	require.Equal(t, []uint32{2}, mgr.fetchTask.shards)
+	task.Run()
+
+	task, _ = mgr.Prepare(buckets)
+	require.Nil(t, task)
+
+	// Checkpoints are no longer fetched once the leader has taken a checkpoint
+	// after the follower was opened.
+	delete(mgr.fetchedShards, 2)
+	now = openedAt.Add(10 * time.Second)
+	task, _ = mgr.Prepare(buckets)
+	require.Nil(t, task)
+}
+
+func TestFollowerFlushManagerPrepareRestore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		openedAt = time.Unix(1234, 0)
+		doneCh   = make(chan struct{})
+		window   = &checkpointpb.CounterWindowCheckpoint{KeyHi: 1, KeyLo: 2, StartAtNanos: 1000, Sum: 5}
+	)
+	mgr := newFollowerFlushManager(doneCh, NewFlushManagerOptions()).(*followerFlushManager)
+	mgr.openedAt = openedAt
+
+	list1 := newTestCheckpointingList(ctrl, 0, nil)
+	list2 := newTestCheckpointingList(ctrl, 1, nil)
+	buckets := []*flushBucket{
+		&flushBucket{
+			bucketID: standardMetricListID{resolution: time.Second}.toMetricListID(),
+			interval: time.Second,
+			flushers: []flushingMetricList{list1, list2},
+		},
+	}
+
+	// Nothing to restore.
+	require.Nil(t, mgr.PrepareRestore(buckets))
+
+	mgr.checkpoints[0] = &checkpointpb.ShardCheckpoint{
+		CheckpointedAtNanos: openedAt.Add(-time.Second).UnixNano(),
+		Counters:            []*checkpointpb.CounterWindowCheckpoint{window},
+	}
+	task := mgr.PrepareRestore(buckets)
+	require.Equal(t, mgr.restoreTask, task)
+	require.Equal(t, 0, len(mgr.checkpoints))
+
+	task.Run()
+	expected := counterCheckpointsByKey{
+		{1, 2}: []*checkpointpb.CounterWindowCheckpoint{window},
+	}
+	require.Equal(t, expected, list1.restored)
+	require.Equal(t, openedAt.UnixNano(), list1.restoredFrom)
+	require.Nil(t, list2.restored)
+
+	// The checkpoints are only restored once.
+	require.Nil(t, mgr.PrepareRestore(buckets))
+}
+
 func TestFollowerFlushManagerWatchFlushTimes(t *testing.T) {
 	// Set up a flush times manager watching in-memory kv store.
 	store := mem.NewStore()
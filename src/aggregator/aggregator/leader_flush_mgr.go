@@ -24,6 +24,7 @@ import (
 	"sync"
 	"time"
 
+	checkpointpb "github.com/m3db/m3/src/aggregator/generated/proto/checkpoint"
 	schema "github.com/m3db/m3/src/aggregator/generated/proto/flush"
 	"github.com/m3db/m3x/clock"
 	"github.com/m3db/m3x/log"
@@ -47,10 +48,13 @@ func newLeaderFlusherMetrics(scope tally.Scope) leaderFlusherMetrics {
 }
 
 type leaderFlushManagerMetrics struct {
-	queueSize tally.Gauge
-	standard  leaderFlusherMetrics
-	forwarded leaderFlusherMetrics
-	timed     leaderFlusherMetrics
+	queueSize           tally.Gauge
+	checkpointDuration  tally.Timer
+	checkpointErrors    tally.Counter
+	checkpointTruncated tally.Counter
+	standard            leaderFlusherMetrics
+	forwarded           leaderFlusherMetrics
+	timed               leaderFlusherMetrics
 }
 
 func newLeaderFlushManagerMetrics(scope tally.Scope) leaderFlushManagerMetrics {
@@ -58,10 +62,13 @@ func newLeaderFlushManagerMetrics(scope tally.Scope) leaderFlushManagerMetrics {
 	forwardedScope := scope.Tagged(map[string]string{"flusher-type": "forwarded"})
 	timedScope := scope.Tagged(map[string]string{"flusher-type": "timed"})
 	return leaderFlushManagerMetrics{
-		queueSize: scope.Gauge("queue-size"),
-		standard:  newLeaderFlusherMetrics(standardScope),
-		forwarded: newLeaderFlusherMetrics(forwardedScope),
-		timed:     newLeaderFlusherMetrics(timedScope),
+		queueSize:           scope.Gauge("queue-size"),
+		checkpointDuration:  scope.Timer("checkpoint-duration"),
+		checkpointErrors:    scope.Counter("checkpoint-errors"),
+		checkpointTruncated: scope.Counter("checkpoint-truncated"),
+		standard:            newLeaderFlusherMetrics(standardScope),
+		forwarded:           newLeaderFlusherMetrics(forwardedScope),
+		timed:               newLeaderFlusherMetrics(timedScope),
 	}
 }
 
@@ -76,17 +83,22 @@ type leaderFlushManager struct {
 	placementManager       PlacementManager
 	flushTimesManager      FlushTimesManager
 	flushTimesPersistEvery time.Duration
+	checkpointManager      CheckpointManager
+	checkpointEvery        time.Duration
+	maxCheckpointWindows   int
 	maxBufferSize          time.Duration
 	logger                 log.Logger
 	scope                  tally.Scope
 
-	doneCh              <-chan struct{}
-	flushTimes          flushMetadataHeap
-	flushedByShard      map[uint32]*schema.ShardFlushTimes
-	lastPersistAtNanos  int64
-	flushedSincePersist bool
-	flushTask           *leaderFlushTask
-	metrics             leaderFlushManagerMetrics
+	doneCh                <-chan struct{}
+	flushTimes            flushMetadataHeap
+	flushedByShard        map[uint32]*schema.ShardFlushTimes
+	lastPersistAtNanos    int64
+	flushedSincePersist   bool
+	lastCheckpointAtNanos int64
+	flushTask             *leaderFlushTask
+	checkpointTask        *leaderCheckpointTask
+	metrics               leaderFlushManagerMetrics
 }
 
 func newLeaderFlushManager(
@@ -103,18 +115,26 @@ func newLeaderFlushManager(
 		placementManager:       opts.PlacementManager(),
 		flushTimesManager:      opts.FlushTimesManager(),
 		flushTimesPersistEvery: opts.FlushTimesPersistEvery(),
+		checkpointManager:      opts.CheckpointManager(),
+		checkpointEvery:        opts.CheckpointEvery(),
+		maxCheckpointWindows:   opts.MaxCheckpointWindowsPerShard(),
 		maxBufferSize:          opts.MaxBufferSize(),
 		logger:                 instrumentOpts.Logger(),
 		scope:                  scope,
 		doneCh:                 doneCh,
 		flushedByShard:         make(map[uint32]*schema.ShardFlushTimes, defaultInitialFlushCapacity),
 		lastPersistAtNanos:     nowFn().UnixNano(),
+		lastCheckpointAtNanos:  nowFn().UnixNano(),
 		metrics:                newLeaderFlushManagerMetrics(scope),
 	}
 	mgr.flushTask = &leaderFlushTask{
 		mgr:      mgr,
 		flushers: make([]flushingMetricList, 0, defaultInitialFlushCapacity),
 	}
+	mgr.checkpointTask = &leaderCheckpointTask{
+		mgr:   mgr,
+		lists: make([]checkpointingMetricList, 0, defaultInitialFlushCapacity),
+	}
 	return mgr
 }
 
@@ -178,10 +198,18 @@ func (mgr *leaderFlushManager) Prepare(buckets []*flushBucket) (flushTask, time.
 		mgr.flushTimesManager.StoreAsync(flushTimes)
 	}
 
-	if !shouldFlush {
-		return nil, waitFor
+	// NB: flushes take precedence over checkpoints, and since the flush goroutine
+	// prepares again immediately after a flush, a pending checkpoint is only delayed
+	// until all due flushes have completed.
+	if shouldFlush {
+		return mgr.flushTask, waitFor
+	}
+	if mgr.shouldCheckpointWithLock(nowNanos) {
+		mgr.lastCheckpointAtNanos = nowNanos
+		mgr.checkpointTask.lists = checkpointingListsFrom(buckets, mgr.checkpointTask.lists[:0])
+		return mgr.checkpointTask, waitFor
 	}
-	return mgr.flushTask, waitFor
+	return nil, waitFor
 }
 
 // NB(xichen): if the current instance is a leader, we need to update the flush
@@ -336,6 +364,13 @@ func (mgr *leaderFlushManager) updateForwardedFlushTimesWithLock(
 	mgr.metrics.forwarded.updateFlushTimes.Inc(int64(len(flushers)))
 }
 
+func (mgr *leaderFlushManager) shouldCheckpointWithLock(nowNanos int64) bool {
+	if mgr.checkpointManager == nil || mgr.checkpointEvery <= 0 {
+		return false
+	}
+	return time.Duration(nowNanos-mgr.lastCheckpointAtNanos) >= mgr.checkpointEvery
+}
+
 func (mgr *leaderFlushManager) nowNanos() int64 { return mgr.nowFn().UnixNano() }
 
 func newShardFlushTimes() *schema.ShardFlushTimes {
@@ -443,6 +478,61 @@ func (t *leaderFlushTask) Run() {
 	t.duration.Record(mgr.nowFn().Sub(start))
 }
 
+type leaderCheckpointTask struct {
+	mgr   *leaderFlushManager
+	lists []checkpointingMetricList
+}
+
+func (t *leaderCheckpointTask) Run() {
+	var (
+		mgr      = t.mgr
+		start    = mgr.nowFn()
+		nowNanos = start.UnixNano()
+		byShard  = make(map[uint32]*checkpointpb.ShardCheckpoint, defaultInitialFlushCapacity)
+	)
+	for _, l := range t.lists {
+		shard := l.Shard()
+		cp, exists := byShard[shard]
+		if !exists {
+			cp = &checkpointpb.ShardCheckpoint{CheckpointedAtNanos: nowNanos}
+			byShard[shard] = cp
+		}
+		if !l.Checkpoint(cp, mgr.maxCheckpointWindows) {
+			mgr.metrics.checkpointTruncated.Inc(1)
+		}
+	}
+	if err := mgr.checkpointManager.StoreAsync(&checkpointpb.ShardSetCheckpoint{ByShard: byShard}); err != nil {
+		mgr.metrics.checkpointErrors.Inc(1)
+		mgr.logger.Errorf("unable to store checkpoint: %v", err)
+	}
+	mgr.metrics.checkpointDuration.Record(mgr.nowFn().Sub(start))
+}
+
+// checkpointingListsFrom appends the standard metric lists in the buckets
+// that support checkpointing to the given slice.
+//
+// NB: forwarded metric lists are not checkpointed. Each forwarded window keeps
+// track of the sources it has received values from to drop duplicates, and since
+// the sources are not part of a checkpoint, merging a checkpointed window could
+// count values the follower also received from the same source twice. Forwarded
+// (rollup) counters are therefore still subject to data loss on failover.
+func checkpointingListsFrom(
+	buckets []*flushBucket,
+	lists []checkpointingMetricList,
+) []checkpointingMetricList {
+	for _, bucket := range buckets {
+		if bucket.bucketID.listType != standardMetricListType {
+			continue
+		}
+		for _, flusher := range bucket.flushers {
+			if l, ok := flusher.(checkpointingMetricList); ok {
+				lists = append(lists, l)
+			}
+		}
+	}
+	return lists
+}
+
 // flushMetadata contains metadata information for a flush.
 type flushMetadata struct {
 	timeNanos int64
@@ -25,6 +25,7 @@ import (
 	"testing"
 	"time"
 
+	checkpointpb "github.com/m3db/m3/src/aggregator/generated/proto/checkpoint"
 	schema "github.com/m3db/m3/src/aggregator/generated/proto/flush"
 	"github.com/m3db/m3/src/cluster/shard"
 
@@ -411,6 +412,100 @@ func TestLeaderFlushManagerPrepareWithFlushAndPersist(t *testing.T) {
 	validateFlushMetadataHeap(t, expectedFlushTimes, mgr.flushTimes)
 }
 
+func TestLeaderFlushManagerPrepareWithCheckpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		stored *checkpointpb.ShardSetCheckpoint
+		now    = time.Unix(120, 0)
+		nowFn  = func() time.Time { return now }
+		doneCh = make(chan struct{})
+	)
+
+	checkpointManager := NewMockCheckpointManager(ctrl)
+	checkpointManager.EXPECT().
+		StoreAsync(gomock.Any()).
+		DoAndReturn(func(value *checkpointpb.ShardSetCheckpoint) error {
+			stored = value
+			return nil
+		})
+
+	opts := NewFlushManagerOptions().
+		SetJitterEnabled(false).
+		SetCheckpointManager(checkpointManager).
+		SetCheckpointEvery(10 * time.Second)
+	mgr := newLeaderFlushManager(doneCh, opts).(*leaderFlushManager)
+	mgr.nowFn = nowFn
+	mgr.flushTimesManager = NewMockFlushTimesManager(ctrl)
+
+	var (
+		window1 = &checkpointpb.CounterWindowCheckpoint{KeyHi: 1, StartAtNanos: 90000000000, Sum: 1}
+		window2 = &checkpointpb.CounterWindowCheckpoint{KeyHi: 2, StartAtNanos: 90000000000, Sum: 2}
+		window3 = &checkpointpb.CounterWindowCheckpoint{KeyHi: 3, StartAtNanos: 60000000000, Sum: 3}
+		window4 = &checkpointpb.CounterWindowCheckpoint{KeyHi: 4, StartAtNanos: 90000000000, Sum: 4}
+	)
+	buckets := []*flushBucket{
+		&flushBucket{
+			bucketID: standardMetricListID{resolution: 10 * time.Second}.toMetricListID(),
+			interval: 10 * time.Second,
+			offset:   500 * time.Millisecond,
+			flushers: []flushingMetricList{
+				newTestCheckpointingList(ctrl, 0, []*checkpointpb.CounterWindowCheckpoint{window1}),
+				newTestCheckpointingList(ctrl, 1, []*checkpointpb.CounterWindowCheckpoint{window2}),
+			},
+		},
+		&flushBucket{
+			bucketID: standardMetricListID{resolution: time.Minute}.toMetricListID(),
+			interval: time.Minute,
+			offset:   time.Second,
+			flushers: []flushingMetricList{
+				newTestCheckpointingList(ctrl, 0, []*checkpointpb.CounterWindowCheckpoint{window3}),
+			},
+		},
+		// Forwarded metric lists are not checkpointed.
+		&flushBucket{
+			bucketID: forwardedMetricListID{resolution: 10 * time.Second, numForwardedTimes: 1}.toMetricListID(),
+			interval: 10 * time.Second,
+			offset:   500 * time.Millisecond,
+			flushers: []flushingMetricList{
+				newTestCheckpointingList(ctrl, 0, []*checkpointpb.CounterWindowCheckpoint{window4}),
+			},
+		},
+	}
+	mgr.Init(buckets)
+	mgr.lastPersistAtNanos = now.UnixNano()
+
+	// No checkpoint is due yet.
+	mgr.lastCheckpointAtNanos = now.Add(-5 * time.Second).UnixNano()
+	task, dur := mgr.Prepare(buckets)
+	require.Nil(t, task)
+	require.Equal(t, 500*time.Millisecond, dur)
+
+	// The checkpoint is due.
+	mgr.lastCheckpointAtNanos = now.Add(-10 * time.Second).UnixNano()
+	task, dur = mgr.Prepare(buckets)
+	require.Equal(t, mgr.checkpointTask, task)
+	require.Equal(t, 500*time.Millisecond, dur)
+	require.Equal(t, now.UnixNano(), mgr.lastCheckpointAtNanos)
+	require.Equal(t, 3, len(mgr.checkpointTask.lists))
+
+	task.Run()
+	expected := &checkpointpb.ShardSetCheckpoint{
+		ByShard: map[uint32]*checkpointpb.ShardCheckpoint{
+			0: &checkpointpb.ShardCheckpoint{
+				CheckpointedAtNanos: now.UnixNano(),
+				Counters:            []*checkpointpb.CounterWindowCheckpoint{window1, window3},
+			},
+			1: &checkpointpb.ShardCheckpoint{
+				CheckpointedAtNanos: now.UnixNano(),
+				Counters:            []*checkpointpb.CounterWindowCheckpoint{window2},
+			},
+		},
+	}
+	require.Equal(t, expected, stored)
+}
+
 func TestLeaderFlushManagerOnBucketAdded(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -116,6 +116,8 @@ type baseMetricListMetrics struct {
 	flushBeforeStale            tally.Counter
 	flushBeforeDuration         tally.Timer
 	discardBefore               tally.Counter
+	checkpointWindows           tally.Counter
+	restoreWindows              tally.Counter
 }
 
 func newMetricListMetrics(scope tally.Scope) baseMetricListMetrics {
@@ -139,6 +141,8 @@ func newMetricListMetrics(scope tally.Scope) baseMetricListMetrics {
 		flushBeforeStale:            flushBeforeScope.Counter("stale"),
 		flushBeforeDuration:         flushBeforeScope.Timer("duration"),
 		discardBefore:               scope.Counter("discard-before"),
+		checkpointWindows:           scope.Counter("checkpoint-windows"),
+		restoreWindows:              scope.Counter("restore-windows"),
 	}
 }
 
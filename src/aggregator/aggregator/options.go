@@ -42,6 +42,7 @@ var (
 	defaultCounterPrefix              = []byte("counts.")
 	defaultTimerPrefix                = []byte("timers.")
 	defaultGaugePrefix                = []byte("gauges.")
+	defaultHistogramPrefix            = []byte("histograms.")
 	defaultEntryTTL                   = 24 * time.Hour
 	defaultEntryCheckInterval         = time.Hour
 	defaultEntryCheckBatchPercent     = 0.01
@@ -108,6 +109,12 @@ type Options interface {
 	// GaugePrefix returns the prefix for gauges.
 	GaugePrefix() []byte
 
+	// SetHistogramPrefix sets the prefix for histograms.
+	SetHistogramPrefix(value []byte) Options
+
+	// HistogramPrefix returns the prefix for histograms.
+	HistogramPrefix() []byte
+
 	// SetTimeLock sets the time lock.
 	SetTimeLock(value *sync.RWMutex) Options
 
@@ -180,6 +187,13 @@ type Options interface {
 	// FlushTimesManager returns the flush times manager.
 	FlushTimesManager() FlushTimesManager
 
+	// SetCheckpointManager sets the checkpoint manager. If nil, open aggregation
+	// windows are not checkpointed.
+	SetCheckpointManager(value CheckpointManager) Options
+
+	// CheckpointManager returns the checkpoint manager.
+	CheckpointManager() CheckpointManager
+
 	// SetElectionManager sets the election manager.
 	SetElectionManager(value ElectionManager) Options
 
@@ -290,6 +304,12 @@ type Options interface {
 	// GaugeElemPool returns the gauge element pool.
 	GaugeElemPool() GaugeElemPool
 
+	// SetHistogramElemPool sets the histogram element pool.
+	SetHistogramElemPool(value HistogramElemPool) Options
+
+	// HistogramElemPool returns the histogram element pool.
+	HistogramElemPool() HistogramElemPool
+
 	/// Read-only derived options.
 
 	// FullCounterPrefix returns the full prefix for counters.
@@ -300,6 +320,9 @@ type Options interface {
 
 	// FullGaugePrefix returns the full prefix for gauges.
 	FullGaugePrefix() []byte
+
+	// FullHistogramPrefix returns the full prefix for histograms.
+	FullHistogramPrefix() []byte
 }
 
 type options struct {
@@ -309,6 +332,7 @@ type options struct {
 	counterPrefix                    []byte
 	timerPrefix                      []byte
 	gaugePrefix                      []byte
+	histogramPrefix                  []byte
 	timeLock                         *sync.RWMutex
 	clockOpts                        clock.Options
 	instrumentOpts                   instrument.Options
@@ -327,6 +351,7 @@ type options struct {
 	maxTimerBatchSizePerWrite        int
 	defaultStoragePolicies           []policy.StoragePolicy
 	flushTimesManager                FlushTimesManager
+	checkpointManager                CheckpointManager
 	electionManager                  ElectionManager
 	resignTimeout                    time.Duration
 	maxAllowedForwardingDelayFn      MaxAllowedForwardingDelayFn
@@ -338,12 +363,14 @@ type options struct {
 	counterElemPool                  CounterElemPool
 	timerElemPool                    TimerElemPool
 	gaugeElemPool                    GaugeElemPool
+	histogramElemPool                HistogramElemPool
 
 	// Derived options.
-	fullCounterPrefix []byte
-	fullTimerPrefix   []byte
-	fullGaugePrefix   []byte
-	timerQuantiles    []float64
+	fullCounterPrefix   []byte
+	fullTimerPrefix     []byte
+	fullGaugePrefix     []byte
+	fullHistogramPrefix []byte
+	timerQuantiles      []float64
 }
 
 // NewOptions create a new set of options.
@@ -351,13 +378,15 @@ func NewOptions() Options {
 	aggTypesOptions := aggregation.NewTypesOptions().
 		SetCounterTypeStringTransformFn(aggregation.EmptyTransform).
 		SetTimerTypeStringTransformFn(aggregation.SuffixTransform).
-		SetGaugeTypeStringTransformFn(aggregation.EmptyTransform)
+		SetGaugeTypeStringTransformFn(aggregation.EmptyTransform).
+		SetHistogramTypeStringTransformFn(aggregation.SuffixTransform)
 	o := &options{
 		aggTypesOptions:    aggTypesOptions,
 		metricPrefix:       defaultMetricPrefix,
 		counterPrefix:      defaultCounterPrefix,
 		timerPrefix:        defaultTimerPrefix,
 		gaugePrefix:        defaultGaugePrefix,
+		histogramPrefix:    defaultHistogramPrefix,
 		timeLock:           &sync.RWMutex{},
 		clockOpts:          clock.NewOptions(),
 		instrumentOpts:     instrument.NewOptions(),
@@ -432,6 +461,17 @@ func (o *options) GaugePrefix() []byte {
 	return o.gaugePrefix
 }
 
+func (o *options) SetHistogramPrefix(value []byte) Options {
+	opts := *o
+	opts.histogramPrefix = value
+	opts.computeFullHistogramPrefix()
+	return &opts
+}
+
+func (o *options) HistogramPrefix() []byte {
+	return o.histogramPrefix
+}
+
 func (o *options) SetTimeLock(value *sync.RWMutex) Options {
 	opts := *o
 	opts.timeLock = value
@@ -552,6 +592,16 @@ func (o *options) FlushTimesManager() FlushTimesManager {
 	return o.flushTimesManager
 }
 
+func (o *options) SetCheckpointManager(value CheckpointManager) Options {
+	opts := *o
+	opts.checkpointManager = value
+	return &opts
+}
+
+func (o *options) CheckpointManager() CheckpointManager {
+	return o.checkpointManager
+}
+
 func (o *options) SetElectionManager(value ElectionManager) Options {
 	opts := *o
 	opts.electionManager = value
@@ -732,6 +782,16 @@ func (o *options) GaugeElemPool() GaugeElemPool {
 	return o.gaugeElemPool
 }
 
+func (o *options) SetHistogramElemPool(value HistogramElemPool) Options {
+	opts := *o
+	opts.histogramElemPool = value
+	return &opts
+}
+
+func (o *options) HistogramElemPool() HistogramElemPool {
+	return o.histogramElemPool
+}
+
 func (o *options) FullCounterPrefix() []byte {
 	return o.fullCounterPrefix
 }
@@ -744,6 +804,10 @@ func (o *options) FullGaugePrefix() []byte {
 	return o.fullGaugePrefix
 }
 
+func (o *options) FullHistogramPrefix() []byte {
+	return o.fullHistogramPrefix
+}
+
 func (o *options) TimerQuantiles() []float64 {
 	return o.timerQuantiles
 }
@@ -769,6 +833,11 @@ func (o *options) initPools() {
 	o.gaugeElemPool.Init(func() *GaugeElem {
 		return MustNewGaugeElem(nil, policy.EmptyStoragePolicy, aggregation.DefaultTypes, applied.DefaultPipeline, 0, WithPrefixWithSuffix, o)
 	})
+
+	o.histogramElemPool = NewHistogramElemPool(nil)
+	o.histogramElemPool.Init(func() *HistogramElem {
+		return MustNewHistogramElem(nil, policy.EmptyStoragePolicy, aggregation.DefaultTypes, applied.DefaultPipeline, 0, WithPrefixWithSuffix, o)
+	})
 }
 
 func (o *options) computeAllDerived() {
@@ -779,6 +848,7 @@ func (o *options) computeFullPrefixes() {
 	o.computeFullCounterPrefix()
 	o.computeFullTimerPrefix()
 	o.computeFullGaugePrefix()
+	o.computeFullHistogramPrefix()
 }
 
 func (o *options) computeFullCounterPrefix() {
@@ -802,6 +872,13 @@ func (o *options) computeFullGaugePrefix() {
 	o.fullGaugePrefix = fullGaugePrefix
 }
 
+func (o *options) computeFullHistogramPrefix() {
+	fullHistogramPrefix := make([]byte, len(o.metricPrefix)+len(o.histogramPrefix))
+	n := copy(fullHistogramPrefix, o.metricPrefix)
+	copy(fullHistogramPrefix[n:], o.histogramPrefix)
+	o.fullHistogramPrefix = fullHistogramPrefix
+}
+
 func defaultMaxAllowedForwardingDelayFn(
 	resolution time.Duration,
 	numForwardedTimes int,
@@ -56,6 +56,7 @@ func TestOptionsValidateDefault(t *testing.T) {
 	require.Equal(t, defaultCounterPrefix, o.CounterPrefix())
 	require.Equal(t, defaultTimerPrefix, o.TimerPrefix())
 	require.Equal(t, defaultGaugePrefix, o.GaugePrefix())
+	require.Equal(t, defaultHistogramPrefix, o.HistogramPrefix())
 	require.Equal(t, defaultEntryTTL, o.EntryTTL())
 	require.Equal(t, defaultEntryCheckInterval, o.EntryCheckInterval())
 	require.Equal(t, defaultEntryCheckBatchPercent, o.EntryCheckBatchPercent())
@@ -67,11 +68,13 @@ func TestOptionsValidateDefault(t *testing.T) {
 	require.NotNil(t, o.CounterElemPool())
 	require.NotNil(t, o.TimerElemPool())
 	require.NotNil(t, o.GaugeElemPool())
+	require.NotNil(t, o.HistogramElemPool())
 
 	// Validate derived options.
 	validateDerivedPrefix(t, o.FullCounterPrefix(), o.MetricPrefix(), o.CounterPrefix())
 	validateDerivedPrefix(t, o.FullTimerPrefix(), o.MetricPrefix(), o.TimerPrefix())
 	validateDerivedPrefix(t, o.FullGaugePrefix(), o.MetricPrefix(), o.GaugePrefix())
+	validateDerivedPrefix(t, o.FullHistogramPrefix(), o.MetricPrefix(), o.HistogramPrefix())
 }
 
 func TestOptionsSetMetricPrefix(t *testing.T) {
@@ -81,6 +84,7 @@ func TestOptionsSetMetricPrefix(t *testing.T) {
 	validateDerivedPrefix(t, o.FullCounterPrefix(), o.MetricPrefix(), o.CounterPrefix())
 	validateDerivedPrefix(t, o.FullTimerPrefix(), o.MetricPrefix(), o.TimerPrefix())
 	validateDerivedPrefix(t, o.FullGaugePrefix(), o.MetricPrefix(), o.GaugePrefix())
+	validateDerivedPrefix(t, o.FullHistogramPrefix(), o.MetricPrefix(), o.HistogramPrefix())
 }
 
 func TestOptionsSetCounterPrefix(t *testing.T) {
@@ -104,6 +108,13 @@ func TestOptionsSetGaugePrefix(t *testing.T) {
 	validateDerivedPrefix(t, o.FullGaugePrefix(), o.MetricPrefix(), o.GaugePrefix())
 }
 
+func TestOptionsSetHistogramPrefix(t *testing.T) {
+	newPrefix := []byte("testHistogramPrefix")
+	o := NewOptions().SetHistogramPrefix(newPrefix)
+	require.Equal(t, newPrefix, o.HistogramPrefix())
+	validateDerivedPrefix(t, o.FullHistogramPrefix(), o.MetricPrefix(), o.HistogramPrefix())
+}
+
 func TestSetClockOptions(t *testing.T) {
 	value := clock.NewOptions()
 	o := NewOptions().SetClockOptions(value)
@@ -225,3 +236,9 @@ func TestSetGaugeElemPool(t *testing.T) {
 	o := NewOptions().SetGaugeElemPool(value)
 	require.Equal(t, value, o.GaugeElemPool())
 }
+
+func TestSetHistogramElemPool(t *testing.T) {
+	value := NewHistogramElemPool(nil)
+	o := NewOptions().SetHistogramElemPool(value)
+	require.Equal(t, value, o.HistogramElemPool())
+}
@@ -63,10 +63,11 @@ type TimerElem struct {
 	elemBase
 	timerElemBase
 
-	values              []timedTimer // metric aggregations sorted by time in ascending order
-	toConsume           []timedTimer // small buffer to avoid memory allocations during consumption
-	lastConsumedAtNanos int64        // last consumed at in Unix nanoseconds
-	lastConsumedValues  []float64    // last consumed values
+	values               []timedTimer                 // metric aggregations sorted by time in ascending order
+	toConsume            []timedTimer                 // small buffer to avoid memory allocations during consumption
+	lastConsumedAtNanos  int64                        // last consumed at in Unix nanoseconds
+	lastConsumedValues   []float64                    // last consumed values
+	consumedWindowValues [][]transformation.Datapoint // values consumed within the window of window transformations
 }
 
 // NewTimerElem creates a new element for the given metric type.
@@ -138,6 +139,13 @@ func (e *TimerElem) ResetSetData(
 	for i := 0; i < len(e.lastConsumedValues); i++ {
 		e.lastConsumedValues[i] = nan
 	}
+	if cap(e.consumedWindowValues) < numAggTypes {
+		e.consumedWindowValues = make([][]transformation.Datapoint, numAggTypes)
+	}
+	e.consumedWindowValues = e.consumedWindowValues[:numAggTypes]
+	for i := 0; i < len(e.consumedWindowValues); i++ {
+		e.consumedWindowValues[i] = e.consumedWindowValues[i][:0]
+	}
 	return nil
 }
 
@@ -299,6 +307,7 @@ func (e *TimerElem) Close() {
 	e.values = e.values[:0]
 	e.toConsume = e.toConsume[:0]
 	e.lastConsumedValues = e.lastConsumedValues[:0]
+	e.consumedWindowValues = e.consumedWindowValues[:0]
 	e.timerElemBase.Close()
 	aggTypesPool := e.aggTypesOpts.TypesPool()
 	pool := e.ElemPool(e.opts)
@@ -410,14 +419,46 @@ func (e *TimerElem) processValueWithAggregationLock(
 		transformations  = e.parsedPipeline.Transformations
 		discardNaNValues = e.opts.DiscardNaNAggregatedValues()
 	)
+	if e.parsedPipeline.HasDistinctCountRollup {
+		// NB: distinct count rollups estimate the number of distinct tag values
+		// contributing to the rollup (e.g. unique users per endpoint), so the hash
+		// of the distinct tag value is forwarded once per window in place of the
+		// aggregated values.
+		forwardedAggregationKey, _ := e.ForwardedAggregationKey()
+		flushForwardedFn(e.writeForwardedMetricFn, forwardedAggregationKey, timeNanos, e.distinctTagValueHash)
+		e.lastConsumedAtNanos = timeNanos
+		return
+	}
 	for aggTypeIdx, aggType := range e.aggTypes {
 		value := lockedAgg.aggregation.ValueOf(aggType)
 		for i := 0; i < transformations.Len(); i++ {
-			transformType := transformations.At(i).Transformation.Type
+			transformOp := transformations.At(i).Transformation
+			transformType := transformOp.Type
 			if transformType.IsUnaryTransform() {
 				fn := transformType.MustUnaryTransform()
 				res := fn(transformation.Datapoint{TimeNanos: timeNanos, Value: value})
 				value = res.Value
+			} else if transformType.IsConstantTransform() {
+				fn := transformType.MustConstantTransform()
+				res := fn(transformation.Datapoint{TimeNanos: timeNanos, Value: value}, transformOp.Constant)
+				value = res.Value
+			} else if transformType.IsWindowTransform() {
+				fn := transformType.MustWindowTransform()
+				// NB: we only keep the values consumed within the window, which starts
+				// inclusively at the window duration before the current time. Similar to
+				// binary transformations, we only support one window transformation per
+				// pipeline so we only need to keep the values of one window.
+				prev := e.consumedWindowValues[aggTypeIdx]
+				windowStartNanos := timeNanos - int64(transformOp.Window)
+				expired := 0
+				for expired < len(prev) && prev[expired].TimeNanos < windowStartNanos {
+					expired++
+				}
+				prev = prev[:copy(prev, prev[expired:])]
+				curr := transformation.Datapoint{TimeNanos: timeNanos, Value: value}
+				res := fn(prev, curr)
+				e.consumedWindowValues[aggTypeIdx] = append(prev, curr)
+				value = res.Value
 			} else {
 				fn := transformType.MustBinaryTransform()
 				prev := transformation.Datapoint{TimeNanos: e.lastConsumedAtNanos, Value: e.lastConsumedValues[aggTypeIdx]}
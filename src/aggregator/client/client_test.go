@@ -33,6 +33,7 @@ import (
 	"github.com/m3db/m3/src/metrics/metadata"
 	"github.com/m3db/m3/src/metrics/metric"
 	"github.com/m3db/m3/src/metrics/metric/aggregated"
+	"github.com/m3db/m3/src/metrics/metric/histogram"
 	"github.com/m3db/m3/src/metrics/metric/unaggregated"
 	"github.com/m3db/m3/src/metrics/pipeline"
 	"github.com/m3db/m3/src/metrics/pipeline/applied"
@@ -64,6 +65,13 @@ var (
 		ID:       []byte("foo"),
 		GaugeVal: 123.456,
 	}
+	testHistogram = unaggregated.MetricUnion{
+		Type:            metric.HistogramType,
+		ID:              []byte("foo"),
+		HistogramSchema: histogram.NewFixedSchema([]float64{1, 10, 100}),
+		HistogramCounts: []uint64{3, 0, 12, 1},
+		HistogramSum:    567.8,
+	}
 	testTimed = aggregated.Metric{
 		Type:      metric.CounterType,
 		ID:        []byte("testForwarded"),
@@ -225,7 +233,7 @@ func TestClientInitSuccess(t *testing.T) {
 func TestClientWriteUntimedMetricClosed(t *testing.T) {
 	c := NewClient(testOptions()).(*client)
 	c.state = clientUninitialized
-	for _, input := range []unaggregated.MetricUnion{testCounter, testBatchTimer, testGauge} {
+	for _, input := range []unaggregated.MetricUnion{testCounter, testBatchTimer, testGauge, testHistogram} {
 		var err error
 		switch input.Type {
 		case metric.CounterType:
@@ -234,6 +242,8 @@ func TestClientWriteUntimedMetricClosed(t *testing.T) {
 			err = c.WriteUntimedBatchTimer(input.BatchTimer(), testStagedMetadatas)
 		case metric.GaugeType:
 			err = c.WriteUntimedGauge(input.Gauge(), testStagedMetadatas)
+		case metric.HistogramType:
+			err = c.WriteUntimedHistogram(input.Histogram(), testStagedMetadatas)
 		}
 		require.Equal(t, errClientIsUninitializedOrClosed, err)
 	}
@@ -250,7 +260,7 @@ func TestClientWriteUntimedMetricActiveStagedPlacementError(t *testing.T) {
 	c.state = clientInitialized
 	c.placementWatcher = watcher
 
-	for _, input := range []unaggregated.MetricUnion{testCounter, testBatchTimer, testGauge} {
+	for _, input := range []unaggregated.MetricUnion{testCounter, testBatchTimer, testGauge, testHistogram} {
 		var err error
 		switch input.Type {
 		case metric.CounterType:
@@ -259,6 +269,8 @@ func TestClientWriteUntimedMetricActiveStagedPlacementError(t *testing.T) {
 			err = c.WriteUntimedBatchTimer(input.BatchTimer(), testStagedMetadatas)
 		case metric.GaugeType:
 			err = c.WriteUntimedGauge(input.Gauge(), testStagedMetadatas)
+		case metric.HistogramType:
+			err = c.WriteUntimedHistogram(input.Histogram(), testStagedMetadatas)
 		}
 		require.Equal(t, errActiveStagedPlacementError, err)
 	}
@@ -277,7 +289,7 @@ func TestClientWriteUntimedMetricActivePlacementError(t *testing.T) {
 	c.state = clientInitialized
 	c.placementWatcher = watcher
 
-	for _, input := range []unaggregated.MetricUnion{testCounter, testBatchTimer, testGauge} {
+	for _, input := range []unaggregated.MetricUnion{testCounter, testBatchTimer, testGauge, testHistogram} {
 		var err error
 		switch input.Type {
 		case metric.CounterType:
@@ -286,6 +298,8 @@ func TestClientWriteUntimedMetricActivePlacementError(t *testing.T) {
 			err = c.WriteUntimedBatchTimer(input.BatchTimer(), testStagedMetadatas)
 		case metric.GaugeType:
 			err = c.WriteUntimedGauge(input.Gauge(), testStagedMetadatas)
+		case metric.HistogramType:
+			err = c.WriteUntimedHistogram(input.Histogram(), testStagedMetadatas)
 		}
 		require.Equal(t, errActivePlacementError, err)
 	}
@@ -328,7 +342,7 @@ func TestClientWriteUntimedMetricSuccess(t *testing.T) {
 		testPlacementInstances[0],
 		testPlacementInstances[2],
 	}
-	for _, input := range []unaggregated.MetricUnion{testCounter, testBatchTimer, testGauge} {
+	for _, input := range []unaggregated.MetricUnion{testCounter, testBatchTimer, testGauge, testHistogram} {
 		// Reset states in each iteration.
 		instancesRes = instancesRes[:0]
 		shardRes = 0
@@ -342,6 +356,8 @@ func TestClientWriteUntimedMetricSuccess(t *testing.T) {
 			err = c.WriteUntimedBatchTimer(input.BatchTimer(), testStagedMetadatas)
 		case metric.GaugeType:
 			err = c.WriteUntimedGauge(input.Gauge(), testStagedMetadatas)
+		case metric.HistogramType:
+			err = c.WriteUntimedHistogram(input.Histogram(), testStagedMetadatas)
 		}
 
 		require.NoError(t, err)
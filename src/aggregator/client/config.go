@@ -21,6 +21,7 @@
 package client
 
 import (
+	"errors"
 	"time"
 
 	"github.com/m3db/m3/src/aggregator/sharding"
@@ -28,6 +29,7 @@ import (
 	"github.com/m3db/m3/src/cluster/kv"
 	"github.com/m3db/m3/src/cluster/placement"
 	"github.com/m3db/m3/src/metrics/encoding/protobuf"
+	producerconfig "github.com/m3db/m3/src/msg/producer/config"
 	"github.com/m3db/m3x/clock"
 	"github.com/m3db/m3x/instrument"
 	"github.com/m3db/m3x/pool"
@@ -36,6 +38,10 @@ import (
 	"github.com/uber-go/tally"
 )
 
+var (
+	errNoM3MsgConfiguration = errors.New("no m3msg configuration for the m3msg client")
+)
+
 // Configuration contains client configuration.
 type Configuration struct {
 	PlacementKV                kv.OverrideConfiguration       `yaml:"placementKV" validate:"nonzero"`
@@ -49,6 +55,8 @@ type Configuration struct {
 	QueueSize                  int                            `yaml:"queueSize"`
 	QueueDropType              *DropType                      `yaml:"queueDropType"`
 	Connection                 ConnectionConfiguration        `yaml:"connection"`
+	Type                       AggregatorClientType           `yaml:"type"`
+	M3Msg                      *M3MsgConfiguration            `yaml:"m3msg"`
 }
 
 // NewAdminClient creates a new admin client.
@@ -74,6 +82,9 @@ func (c *Configuration) NewClient(
 	if err != nil {
 		return nil, err
 	}
+	if c.Type == M3MsgAggregatorClient {
+		return NewM3MsgClient(opts)
+	}
 	return NewClient(opts), nil
 }
 
@@ -136,9 +147,37 @@ func (c *Configuration) newClientOptions(
 	if c.QueueDropType != nil {
 		opts = opts.SetQueueDropType(*c.QueueDropType)
 	}
+	if c.Type == M3MsgAggregatorClient {
+		if c.M3Msg == nil {
+			return nil, errNoM3MsgConfiguration
+		}
+		iOpts := instrumentOpts.SetMetricsScope(scope.SubScope("m3msg"))
+		m3msgOpts, err := c.M3Msg.NewM3MsgOptions(kvClient, iOpts)
+		if err != nil {
+			return nil, err
+		}
+		opts = opts.SetM3MsgOptions(m3msgOpts)
+	}
 	return opts, nil
 }
 
+// M3MsgConfiguration contains the configuration for the m3msg client.
+type M3MsgConfiguration struct {
+	Producer producerconfig.ProducerConfiguration `yaml:"producer"`
+}
+
+// NewM3MsgOptions creates a new set of m3msg client options.
+func (c *M3MsgConfiguration) NewM3MsgOptions(
+	kvClient m3clusterclient.Client,
+	instrumentOpts instrument.Options,
+) (M3MsgOptions, error) {
+	p, err := c.Producer.NewProducer(kvClient, instrumentOpts)
+	if err != nil {
+		return nil, err
+	}
+	return NewM3MsgOptions().SetProducer(p), nil
+}
+
 // ConnectionConfiguration contains the connection configuration.
 type ConnectionConfiguration struct {
 	ConnectionTimeout            time.Duration        `yaml:"connectionTimeout"`
@@ -118,6 +118,36 @@ func TestConfigUnmarshal(t *testing.T) {
 	require.Equal(t, 2, cfg.Connection.WriteRetries.MaxRetries)
 	require.Equal(t, true, *cfg.Connection.WriteRetries.Jitter)
 	require.Nil(t, cfg.Connection.WriteRetries.Forever)
+	require.Equal(t, LegacyAggregatorClient, cfg.Type)
+	require.Nil(t, cfg.M3Msg)
+}
+
+func TestConfigUnmarshalAggregatorClientType(t *testing.T) {
+	var cfg Configuration
+	require.NoError(t, yaml.Unmarshal([]byte("type: m3msg\nm3msg:\n  producer: {}\n"), &cfg))
+	require.Equal(t, M3MsgAggregatorClient, cfg.Type)
+	require.NotNil(t, cfg.M3Msg)
+
+	cfg = Configuration{}
+	require.NoError(t, yaml.Unmarshal([]byte("type: legacy"), &cfg))
+	require.Equal(t, LegacyAggregatorClient, cfg.Type)
+
+	cfg = Configuration{}
+	require.Error(t, yaml.Unmarshal([]byte("type: foo"), &cfg))
+}
+
+func TestNewClientOptionsM3MsgClientWithoutM3MsgConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var cfg Configuration
+	require.NoError(t, yaml.Unmarshal([]byte(testClientConfig), &cfg))
+	cfg.Type = M3MsgAggregatorClient
+
+	kvClient := m3clusterclient.NewMockClient(ctrl)
+	kvClient.EXPECT().Store(gomock.Any()).Return(mem.NewStore(), nil)
+	_, err := cfg.newClientOptions(kvClient, clock.NewOptions(), instrument.NewOptions())
+	require.Equal(t, errNoM3MsgConfiguration, err)
 }
 
 func TestNewClientOptions(t *testing.T) {
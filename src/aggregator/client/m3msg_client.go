@@ -0,0 +1,309 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/m3db/m3/src/aggregator/sharding"
+	"github.com/m3db/m3/src/metrics/encoding"
+	"github.com/m3db/m3/src/metrics/encoding/protobuf"
+	"github.com/m3db/m3/src/metrics/metadata"
+	"github.com/m3db/m3/src/metrics/metric/aggregated"
+	"github.com/m3db/m3/src/metrics/metric/id"
+	"github.com/m3db/m3/src/metrics/metric/unaggregated"
+	"github.com/m3db/m3/src/msg/producer"
+	"github.com/m3db/m3x/clock"
+)
+
+var (
+	errNoShardsInTopic = errors.New("m3msg topic has no shards")
+)
+
+// m3msgClient publishes metrics to the aggregators through an m3msg producer.
+// Metrics are sharded with the same shard function as the legacy client, and
+// the producer routes each message to the aggregator instances owning its shard
+// in the consumer service placement, retrying until the message is acked.
+type m3msgClient struct {
+	sync.RWMutex
+
+	opts              Options
+	nowFn             clock.NowFn
+	producer          producer.Producer
+	shardFn           sharding.ShardFn
+	maxTimerBatchSize int
+	encoder           *lockedEncoder
+	numShards         uint32
+	state             clientState
+	metrics           clientMetrics
+}
+
+// NewM3MsgClient creates a new client that publishes metrics through the
+// m3msg producer set in the m3msg options.
+func NewM3MsgClient(opts Options) (AdminClient, error) {
+	m3msgOpts := opts.M3MsgOptions()
+	if err := m3msgOpts.Validate(); err != nil {
+		return nil, err
+	}
+	instrumentOpts := opts.InstrumentOptions()
+	return &m3msgClient{
+		opts:              opts,
+		nowFn:             opts.ClockOptions().NowFn(),
+		producer:          m3msgOpts.Producer(),
+		shardFn:           opts.ShardFn(),
+		maxTimerBatchSize: opts.MaxTimerBatchSize(),
+		encoder:           newLockedEncoder(opts.EncoderOptions()),
+		metrics:           newClientMetrics(instrumentOpts.MetricsScope(), instrumentOpts.MetricsSamplingRate()),
+	}, nil
+}
+
+func (c *m3msgClient) Init() error {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.state != clientUninitialized {
+		return errClientIsInitializedOrClosed
+	}
+	if err := c.producer.Init(); err != nil {
+		return err
+	}
+	// NB: the number of shards is only known once the producer has retrieved
+	// the topic during initialization.
+	numShards := c.producer.NumShards()
+	if numShards == 0 {
+		c.producer.Close(producer.DropEverything)
+		return errNoShardsInTopic
+	}
+	c.numShards = numShards
+	c.state = clientInitialized
+	return nil
+}
+
+func (c *m3msgClient) WriteUntimedCounter(
+	counter unaggregated.Counter,
+	metadatas metadata.StagedMetadatas,
+) error {
+	callStart := c.nowFn()
+	msg := encoding.UnaggregatedMessageUnion{
+		Type: encoding.CounterWithMetadatasType,
+		CounterWithMetadatas: unaggregated.CounterWithMetadatas{
+			Counter:         counter,
+			StagedMetadatas: metadatas,
+		},
+	}
+	err := c.write(counter.ID, msg)
+	c.metrics.writeUntimedCounter.ReportSuccessOrError(err, c.nowFn().Sub(callStart))
+	return err
+}
+
+func (c *m3msgClient) WriteUntimedBatchTimer(
+	batchTimer unaggregated.BatchTimer,
+	metadatas metadata.StagedMetadatas,
+) error {
+	callStart := c.nowFn()
+	err := c.writeBatchTimer(batchTimer, metadatas)
+	c.metrics.writeUntimedBatchTimer.ReportSuccessOrError(err, c.nowFn().Sub(callStart))
+	return err
+}
+
+func (c *m3msgClient) writeBatchTimer(
+	batchTimer unaggregated.BatchTimer,
+	metadatas metadata.StagedMetadatas,
+) error {
+	// If there is no limit on the timer batch size, write the full batch.
+	numTimerValues := len(batchTimer.Values)
+	if c.maxTimerBatchSize == 0 || numTimerValues <= c.maxTimerBatchSize {
+		return c.write(batchTimer.ID, newBatchTimerMessage(batchTimer, metadatas))
+	}
+
+	// Otherwise, honor maximum timer batch size and publish each batch separately.
+	for start := 0; start < numTimerValues; start += c.maxTimerBatchSize {
+		end := start + c.maxTimerBatchSize
+		if end > numTimerValues {
+			end = numTimerValues
+		}
+		singleBatchTimer := unaggregated.BatchTimer{
+			ID:     batchTimer.ID,
+			Values: batchTimer.Values[start:end],
+		}
+		if err := c.write(batchTimer.ID, newBatchTimerMessage(singleBatchTimer, metadatas)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *m3msgClient) WriteUntimedGauge(
+	gauge unaggregated.Gauge,
+	metadatas metadata.StagedMetadatas,
+) error {
+	callStart := c.nowFn()
+	msg := encoding.UnaggregatedMessageUnion{
+		Type: encoding.GaugeWithMetadatasType,
+		GaugeWithMetadatas: unaggregated.GaugeWithMetadatas{
+			Gauge:           gauge,
+			StagedMetadatas: metadatas,
+		},
+	}
+	err := c.write(gauge.ID, msg)
+	c.metrics.writeUntimedGauge.ReportSuccessOrError(err, c.nowFn().Sub(callStart))
+	return err
+}
+
+func (c *m3msgClient) WriteUntimedHistogram(
+	histogram unaggregated.Histogram,
+	metadatas metadata.StagedMetadatas,
+) error {
+	callStart := c.nowFn()
+	msg := encoding.UnaggregatedMessageUnion{
+		Type: encoding.HistogramWithMetadatasType,
+		HistogramWithMetadatas: unaggregated.HistogramWithMetadatas{
+			Histogram:       histogram,
+			StagedMetadatas: metadatas,
+		},
+	}
+	err := c.write(histogram.ID, msg)
+	c.metrics.writeUntimedHistogram.ReportSuccessOrError(err, c.nowFn().Sub(callStart))
+	return err
+}
+
+func (c *m3msgClient) WriteTimed(
+	metric aggregated.Metric,
+	metadata metadata.TimedMetadata,
+) error {
+	callStart := c.nowFn()
+	msg := encoding.UnaggregatedMessageUnion{
+		Type: encoding.TimedMetricWithMetadataType,
+		TimedMetricWithMetadata: aggregated.TimedMetricWithMetadata{
+			Metric:        metric,
+			TimedMetadata: metadata,
+		},
+	}
+	err := c.write(metric.ID, msg)
+	c.metrics.writeForwarded.ReportSuccessOrError(err, c.nowFn().Sub(callStart))
+	return err
+}
+
+func (c *m3msgClient) WriteForwarded(
+	metric aggregated.ForwardedMetric,
+	metadata metadata.ForwardMetadata,
+) error {
+	callStart := c.nowFn()
+	msg := encoding.UnaggregatedMessageUnion{
+		Type: encoding.ForwardedMetricWithMetadataType,
+		ForwardedMetricWithMetadata: aggregated.ForwardedMetricWithMetadata{
+			ForwardedMetric: metric,
+			ForwardMetadata: metadata,
+		},
+	}
+	err := c.write(metric.ID, msg)
+	c.metrics.writeForwarded.ReportSuccessOrError(err, c.nowFn().Sub(callStart))
+	return err
+}
+
+// Flush is a no-op because every write is handed to the producer right away
+// and the producer takes care of buffering and retrying until acked.
+func (c *m3msgClient) Flush() error {
+	callStart := c.nowFn()
+	c.RLock()
+	defer c.RUnlock()
+
+	if c.state != clientInitialized {
+		return errClientIsUninitializedOrClosed
+	}
+	c.metrics.flush.ReportSuccessOrError(nil, c.nowFn().Sub(callStart))
+	return nil
+}
+
+func (c *m3msgClient) Close() error {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.state != clientInitialized {
+		return errClientIsUninitializedOrClosed
+	}
+	c.state = clientClosed
+	c.producer.Close(producer.WaitForConsumption)
+	return nil
+}
+
+func (c *m3msgClient) write(metricID id.RawID, msg encoding.UnaggregatedMessageUnion) error {
+	c.RLock()
+	defer c.RUnlock()
+
+	if c.state != clientInitialized {
+		return errClientIsUninitializedOrClosed
+	}
+	shard := c.shardFn(metricID, c.numShards)
+
+	c.encoder.Lock()
+	if err := c.encoder.EncodeMessage(msg); err != nil {
+		// Rewind buffer and clear out the encoder error.
+		c.encoder.Truncate(0)
+		c.encoder.Unlock()
+		return err
+	}
+	buf := c.encoder.Relinquish()
+	c.encoder.Unlock()
+
+	return c.producer.Produce(newM3MsgMessage(shard, buf))
+}
+
+func newBatchTimerMessage(
+	batchTimer unaggregated.BatchTimer,
+	metadatas metadata.StagedMetadatas,
+) encoding.UnaggregatedMessageUnion {
+	return encoding.UnaggregatedMessageUnion{
+		Type: encoding.BatchTimerWithMetadatasType,
+		BatchTimerWithMetadatas: unaggregated.BatchTimerWithMetadatas{
+			BatchTimer:      batchTimer,
+			StagedMetadatas: metadatas,
+		},
+	}
+}
+
+type m3msgMessage struct {
+	shard uint32
+	data  protobuf.Buffer
+}
+
+func newM3MsgMessage(shard uint32, data protobuf.Buffer) producer.Message {
+	return &m3msgMessage{shard: shard, data: data}
+}
+
+func (m *m3msgMessage) Shard() uint32 {
+	return m.shard
+}
+
+func (m *m3msgMessage) Bytes() []byte {
+	return m.data.Bytes()
+}
+
+func (m *m3msgMessage) Size() int {
+	// NB: use the capacity of the underlying buffer so the producer accounts
+	// for the memory actually held rather than the bytes encoded.
+	return cap(m.data.Bytes())
+}
+
+func (m *m3msgMessage) Finalize(producer.FinalizeReason) {
+	m.data.Close()
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/m3db/m3/src/metrics/encoding"
+	"github.com/m3db/m3/src/metrics/encoding/protobuf"
+	"github.com/m3db/m3/src/msg/producer"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewM3MsgClientNoProducer(t *testing.T) {
+	_, err := NewM3MsgClient(testOptions())
+	require.Equal(t, errNoM3MsgProducer, err)
+}
+
+func TestM3MsgClientInitUninitializedOrClosed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	p := producer.NewMockProducer(ctrl)
+	c := testM3MsgClient(t, p)
+	require.Equal(t, errClientIsUninitializedOrClosed, c.WriteUntimedCounter(testCounter.Counter(), testStagedMetadatas))
+	require.Equal(t, errClientIsUninitializedOrClosed, c.Flush())
+	require.Equal(t, errClientIsUninitializedOrClosed, c.Close())
+
+	p.EXPECT().Init().Return(nil)
+	p.EXPECT().NumShards().Return(uint32(4))
+	require.NoError(t, c.Init())
+	require.Equal(t, errClientIsInitializedOrClosed, c.Init())
+
+	p.EXPECT().Close(producer.WaitForConsumption)
+	require.NoError(t, c.Close())
+	require.Equal(t, errClientIsInitializedOrClosed, c.Init())
+	require.Equal(t, errClientIsUninitializedOrClosed, c.WriteUntimedCounter(testCounter.Counter(), testStagedMetadatas))
+}
+
+func TestM3MsgClientInitNoShards(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	p := producer.NewMockProducer(ctrl)
+	p.EXPECT().Init().Return(nil)
+	p.EXPECT().NumShards().Return(uint32(0))
+	p.EXPECT().Close(producer.DropEverything)
+	c := testM3MsgClient(t, p)
+	require.Equal(t, errNoShardsInTopic, c.Init())
+}
+
+func TestM3MsgClientWrite(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var produced []producer.Message
+	p := producer.NewMockProducer(ctrl)
+	p.EXPECT().Init().Return(nil)
+	p.EXPECT().NumShards().Return(uint32(4))
+	p.EXPECT().Produce(gomock.Any()).Do(func(m producer.Message) error {
+		produced = append(produced, m)
+		return nil
+	}).Return(nil).Times(5)
+
+	c := testM3MsgClient(t, p)
+	require.NoError(t, c.Init())
+	require.NoError(t, c.WriteUntimedCounter(testCounter.Counter(), testStagedMetadatas))
+	require.NoError(t, c.WriteUntimedGauge(testGauge.Gauge(), testStagedMetadatas))
+	require.NoError(t, c.WriteUntimedHistogram(testHistogram.Histogram(), testStagedMetadatas))
+	require.NoError(t, c.WriteTimed(testTimed, testTimedMetadata))
+	require.NoError(t, c.WriteForwarded(testForwarded, testForwardMetadata))
+	require.NoError(t, c.Flush())
+
+	require.Equal(t, 5, len(produced))
+	var decoded []encoding.UnaggregatedMessageUnion
+	for _, m := range produced {
+		require.Equal(t, uint32(1), m.Shard())
+		require.Equal(t, cap(m.Bytes()), m.Size())
+		res := decodeM3MsgMessage(t, m)
+		require.Equal(t, 1, len(res))
+		decoded = append(decoded, res[0])
+	}
+
+	require.Equal(t, encoding.CounterWithMetadatasType, decoded[0].Type)
+	require.Equal(t, testCounter.Counter(), decoded[0].CounterWithMetadatas.Counter)
+	require.Equal(t, testStagedMetadatas, decoded[0].CounterWithMetadatas.StagedMetadatas)
+	require.Equal(t, encoding.GaugeWithMetadatasType, decoded[1].Type)
+	require.Equal(t, testGauge.Gauge(), decoded[1].GaugeWithMetadatas.Gauge)
+	require.Equal(t, testStagedMetadatas, decoded[1].GaugeWithMetadatas.StagedMetadatas)
+	require.Equal(t, encoding.HistogramWithMetadatasType, decoded[2].Type)
+	require.Equal(t, testHistogram.ID, decoded[2].HistogramWithMetadatas.Histogram.ID)
+	require.Equal(t, testHistogram.HistogramCounts, decoded[2].HistogramWithMetadatas.Histogram.Counts)
+	require.Equal(t, testStagedMetadatas, decoded[2].HistogramWithMetadatas.StagedMetadatas)
+	require.Equal(t, encoding.TimedMetricWithMetadataType, decoded[3].Type)
+	require.Equal(t, testTimed, decoded[3].TimedMetricWithMetadata.Metric)
+	require.Equal(t, testTimedMetadata, decoded[3].TimedMetricWithMetadata.TimedMetadata)
+	require.Equal(t, encoding.ForwardedMetricWithMetadataType, decoded[4].Type)
+	require.Equal(t, testForwarded, decoded[4].ForwardedMetricWithMetadata.ForwardedMetric)
+	require.Equal(t, testForwardMetadata, decoded[4].ForwardedMetricWithMetadata.ForwardMetadata)
+
+	for _, m := range produced {
+		m.Finalize(producer.Consumed)
+	}
+}
+
+func TestM3MsgClientWriteBatchTimerWithMaxBatchSize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var produced []producer.Message
+	p := producer.NewMockProducer(ctrl)
+	p.EXPECT().Init().Return(nil)
+	p.EXPECT().NumShards().Return(uint32(4))
+	p.EXPECT().Produce(gomock.Any()).Do(func(m producer.Message) error {
+		produced = append(produced, m)
+		return nil
+	}).Return(nil).Times(2)
+
+	opts := testOptions().
+		SetMaxTimerBatchSize(2).
+		SetM3MsgOptions(NewM3MsgOptions().SetProducer(p))
+	c, err := NewM3MsgClient(opts)
+	require.NoError(t, err)
+	require.NoError(t, c.Init())
+	require.NoError(t, c.WriteUntimedBatchTimer(testBatchTimer.BatchTimer(), testStagedMetadatas))
+
+	require.Equal(t, 2, len(produced))
+	var values []float64
+	for _, m := range produced {
+		decoded := decodeM3MsgMessage(t, m)
+		require.Equal(t, 1, len(decoded))
+		require.Equal(t, encoding.BatchTimerWithMetadatasType, decoded[0].Type)
+		require.Equal(t, testBatchTimer.ID, decoded[0].BatchTimerWithMetadatas.BatchTimer.ID)
+		require.Equal(t, testStagedMetadatas, decoded[0].BatchTimerWithMetadatas.StagedMetadatas)
+		values = append(values, decoded[0].BatchTimerWithMetadatas.BatchTimer.Values...)
+	}
+	require.Equal(t, testBatchTimer.BatchTimerVal, values)
+}
+
+func testM3MsgClient(t *testing.T, p producer.Producer) AdminClient {
+	opts := testOptions().SetM3MsgOptions(NewM3MsgOptions().SetProducer(p))
+	c, err := NewM3MsgClient(opts)
+	require.NoError(t, err)
+	return c
+}
+
+func decodeM3MsgMessage(t *testing.T, m producer.Message) []encoding.UnaggregatedMessageUnion {
+	it := protobuf.NewUnaggregatedIterator(bytes.NewReader(m.Bytes()), protobuf.NewUnaggregatedOptions())
+	defer it.Close()
+
+	var res []encoding.UnaggregatedMessageUnion
+	for it.Next() {
+		res = append(res, it.Current())
+	}
+	require.Equal(t, io.EOF, it.Err())
+	return res
+}
@@ -21,11 +21,15 @@
 package client
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/m3db/m3/src/aggregator/sharding"
 	"github.com/m3db/m3/src/cluster/placement"
 	"github.com/m3db/m3/src/metrics/encoding/protobuf"
+	"github.com/m3db/m3/src/msg/producer"
 	"github.com/m3db/m3x/clock"
 	"github.com/m3db/m3x/instrument"
 )
@@ -50,8 +54,106 @@ const (
 
 	// By default the oldest metrics in the queue are dropped when it is full.
 	defaultDropType = DropOldest
+
+	defaultAggregatorClientType = LegacyAggregatorClient
+)
+
+var (
+	errNoM3MsgProducer = errors.New("no m3msg producer set")
+)
+
+// AggregatorClientType determines how the client delivers metrics to the aggregators.
+type AggregatorClientType int
+
+const (
+	// LegacyAggregatorClient writes metrics to the aggregator instances directly
+	// over raw TCP connections.
+	LegacyAggregatorClient AggregatorClientType = iota
+
+	// M3MsgAggregatorClient publishes metrics to the aggregators through an
+	// m3msg producer with acked delivery.
+	M3MsgAggregatorClient
+)
+
+var (
+	validAggregatorClientTypes = []AggregatorClientType{
+		LegacyAggregatorClient,
+		M3MsgAggregatorClient,
+	}
 )
 
+func (t AggregatorClientType) String() string {
+	switch t {
+	case LegacyAggregatorClient:
+		return "legacy"
+	case M3MsgAggregatorClient:
+		return "m3msg"
+	default:
+		return "unknown"
+	}
+}
+
+// UnmarshalYAML unmarshals an AggregatorClientType into a valid type from string.
+func (t *AggregatorClientType) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	if str == "" {
+		*t = defaultAggregatorClientType
+		return nil
+	}
+	strs := make([]string, 0, len(validAggregatorClientTypes))
+	for _, valid := range validAggregatorClientTypes {
+		if str == valid.String() {
+			*t = valid
+			return nil
+		}
+		strs = append(strs, "'"+valid.String()+"'")
+	}
+	return fmt.Errorf(
+		"invalid AggregatorClientType '%s' valid types are: %s", str, strings.Join(strs, ", "),
+	)
+}
+
+// M3MsgOptions is a set of options for the m3msg client.
+type M3MsgOptions interface {
+	// Validate validates the m3msg client options.
+	Validate() error
+
+	// SetProducer sets the producer.
+	SetProducer(value producer.Producer) M3MsgOptions
+
+	// Producer returns the producer.
+	Producer() producer.Producer
+}
+
+type m3msgOptions struct {
+	producer producer.Producer
+}
+
+// NewM3MsgOptions creates a new set of m3msg client options.
+func NewM3MsgOptions() M3MsgOptions {
+	return &m3msgOptions{}
+}
+
+func (o *m3msgOptions) Validate() error {
+	if o.producer == nil {
+		return errNoM3MsgProducer
+	}
+	return nil
+}
+
+func (o *m3msgOptions) SetProducer(value producer.Producer) M3MsgOptions {
+	opts := *o
+	opts.producer = value
+	return &opts
+}
+
+func (o *m3msgOptions) Producer() producer.Producer {
+	return o.producer
+}
+
 // Options provide a set of client options.
 type Options interface {
 	// SetClockOptions sets the clock options.
@@ -127,6 +229,12 @@ type Options interface {
 	// QueueDropType returns sets the strategy for which metrics should metrics should be dropped
 	// when the queue is full.
 	QueueDropType() DropType
+
+	// SetM3MsgOptions sets the options used by the m3msg client.
+	SetM3MsgOptions(value M3MsgOptions) Options
+
+	// M3MsgOptions returns the options used by the m3msg client.
+	M3MsgOptions() M3MsgOptions
 }
 
 type options struct {
@@ -142,6 +250,7 @@ type options struct {
 	maxTimerBatchSize          int
 	instanceQueueSize          int
 	dropType                   DropType
+	m3msgOpts                  M3MsgOptions
 }
 
 // NewOptions creates a new set of client options.
@@ -159,6 +268,7 @@ func NewOptions() Options {
 		maxTimerBatchSize:          defaultMaxTimerBatchSize,
 		instanceQueueSize:          defaultInstanceQueueSize,
 		dropType:                   defaultDropType,
+		m3msgOpts:                  NewM3MsgOptions(),
 	}
 }
 
@@ -281,3 +391,13 @@ func (o *options) SetQueueDropType(value DropType) Options {
 func (o *options) QueueDropType() DropType {
 	return o.dropType
 }
+
+func (o *options) SetM3MsgOptions(value M3MsgOptions) Options {
+	opts := *o
+	opts.m3msgOpts = value
+	return &opts
+}
+
+func (o *options) M3MsgOptions() M3MsgOptions {
+	return o.m3msgOpts
+}
@@ -100,6 +100,11 @@ func (c *client) writeUntimedMetricWithPoliciesList(
 			Gauge:        mu.Gauge(),
 			PoliciesList: pl,
 		})
+	case metric.HistogramType:
+		err = c.msgpackEncoder.EncodeHistogramWithPoliciesList(unaggregated.HistogramWithPoliciesList{
+			Histogram:    mu.Histogram(),
+			PoliciesList: pl,
+		})
 	default:
 		err = fmt.Errorf("unrecognized metric type %v", mu.Type)
 	}
@@ -153,6 +158,13 @@ func (c *client) writeUntimedMetricWithMetadatas(
 				Gauge:           mu.Gauge(),
 				StagedMetadatas: sm,
 			}}
+	case metric.HistogramType:
+		msg = encoding.UnaggregatedMessageUnion{
+			Type: encoding.HistogramWithMetadatasType,
+			HistogramWithMetadatas: unaggregated.HistogramWithMetadatas{
+				Histogram:       mu.Histogram(),
+				StagedMetadatas: sm,
+			}}
 	default:
 		return fmt.Errorf("unrecognized metric type %v", mu.Type)
 	}
@@ -247,6 +247,8 @@ func (ts *testServerSetup) startServer() error {
 		if err := serve.Serve(
 			ts.rawTCPAddr,
 			ts.rawTCPServerOpts,
+			"",
+			nil,
 			ts.httpAddr,
 			ts.httpServerOpts,
 			ts.aggregator,
@@ -0,0 +1,210 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package m3msg
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/m3db/m3/src/aggregator/aggregator"
+	"github.com/m3db/m3/src/aggregator/rate"
+	"github.com/m3db/m3/src/metrics/encoding"
+	"github.com/m3db/m3/src/metrics/encoding/protobuf"
+	"github.com/m3db/m3/src/msg/consumer"
+	"github.com/m3db/m3x/log"
+	xserver "github.com/m3db/m3x/server"
+
+	"github.com/uber-go/tally"
+)
+
+// NewServer creates a new m3msg server that consumes unaggregated metrics
+// published by the aggregator clients.
+func NewServer(address string, aggregator aggregator.Aggregator, opts Options) xserver.Server {
+	iOpts := opts.InstrumentOptions()
+	handlerScope := iOpts.MetricsScope().Tagged(map[string]string{"handler": "m3msg"})
+	processor := NewMessageProcessor(aggregator, opts.SetInstrumentOptions(iOpts.SetMetricsScope(handlerScope)))
+	handler := consumer.NewMessageHandler(processor, opts.ConsumerOptions())
+	return xserver.NewServer(address, handler, opts.ServerOptions())
+}
+
+type messageProcessorMetrics struct {
+	messagesProcessed        tally.Counter
+	messagesNotAcked         tally.Counter
+	unknownMessageTypeErrors tally.Counter
+	addUntimedErrors         tally.Counter
+	addTimedErrors           tally.Counter
+	addForwardedErrors       tally.Counter
+	decodeErrors             tally.Counter
+	errLogRateLimited        tally.Counter
+}
+
+func newMessageProcessorMetrics(scope tally.Scope) messageProcessorMetrics {
+	return messageProcessorMetrics{
+		messagesProcessed:        scope.Counter("messages-processed"),
+		messagesNotAcked:         scope.Counter("messages-not-acked"),
+		unknownMessageTypeErrors: scope.Counter("unknown-message-type-errors"),
+		addUntimedErrors:         scope.Counter("add-untimed-errors"),
+		addTimedErrors:           scope.Counter("add-timed-errors"),
+		addForwardedErrors:       scope.Counter("add-forwarded-errors"),
+		decodeErrors:             scope.Counter("decode-errors"),
+		errLogRateLimited:        scope.Counter("error-log-rate-limited"),
+	}
+}
+
+type messageProcessor struct {
+	aggregator     aggregator.Aggregator
+	log            log.Logger
+	protobufItOpts protobuf.UnaggregatedOptions
+
+	errLogRateLimiter *rate.Limiter
+	metrics           messageProcessorMetrics
+}
+
+// NewMessageProcessor creates a new m3msg message processor that adds the
+// metrics decoded from each message to the aggregator and acks the message
+// unless it needs to be redelivered.
+func NewMessageProcessor(aggregator aggregator.Aggregator, opts Options) consumer.MessageProcessor {
+	nowFn := opts.ClockOptions().NowFn()
+	iOpts := opts.InstrumentOptions()
+	var limiter *rate.Limiter
+	if rateLimit := opts.ErrorLogLimitPerSecond(); rateLimit != 0 {
+		limiter = rate.NewLimiter(rateLimit, nowFn)
+	}
+	return &messageProcessor{
+		aggregator:        aggregator,
+		log:               iOpts.Logger(),
+		protobufItOpts:    opts.ProtobufUnaggregatedIteratorOptions(),
+		errLogRateLimiter: limiter,
+		metrics:           newMessageProcessorMetrics(iOpts.MetricsScope()),
+	}
+}
+
+func (p *messageProcessor) Process(msg consumer.Message) {
+	var (
+		it        = protobuf.NewUnaggregatedIterator(bytes.NewReader(msg.Bytes()), p.protobufItOpts)
+		retryable bool
+	)
+	for it.Next() {
+		if err := p.add(it.Current()); err != nil && aggregator.IsRetryableError(err) {
+			retryable = true
+		}
+	}
+	if err := it.Err(); err != nil && err != io.EOF {
+		p.metrics.decodeErrors.Inc(1)
+		if p.shouldLogError() {
+			p.log.WithFields(log.NewErrField(err)).Error("decode error")
+		}
+	}
+	it.Close()
+
+	// NB: the message is not acked if a metric could not be added due to a
+	// transient error, e.g. the aggregator is not open yet or does not own the
+	// shard yet, so that the producer redelivers it later. The clients publish
+	// one metric per message so redelivery does not double count other metrics.
+	// Messages with decoding or other non-retryable errors are acked since
+	// redelivering them would fail the same way.
+	if retryable {
+		p.metrics.messagesNotAcked.Inc(1)
+		return
+	}
+	msg.Ack()
+	p.metrics.messagesProcessed.Inc(1)
+}
+
+func (p *messageProcessor) add(current encoding.UnaggregatedMessageUnion) error {
+	var err error
+	switch current.Type {
+	case encoding.CounterWithMetadatasType:
+		untimedMetric := current.CounterWithMetadatas.Counter.ToUnion()
+		stagedMetadatas := current.CounterWithMetadatas.StagedMetadatas
+		if err = p.aggregator.AddUntimed(untimedMetric, stagedMetadatas); err != nil {
+			p.metrics.addUntimedErrors.Inc(1)
+			p.logAddError(err, "error adding untimed metric", untimedMetric.ID.String())
+		}
+	case encoding.BatchTimerWithMetadatasType:
+		untimedMetric := current.BatchTimerWithMetadatas.BatchTimer.ToUnion()
+		stagedMetadatas := current.BatchTimerWithMetadatas.StagedMetadatas
+		if err = p.aggregator.AddUntimed(untimedMetric, stagedMetadatas); err != nil {
+			p.metrics.addUntimedErrors.Inc(1)
+			p.logAddError(err, "error adding untimed metric", untimedMetric.ID.String())
+		}
+	case encoding.GaugeWithMetadatasType:
+		untimedMetric := current.GaugeWithMetadatas.Gauge.ToUnion()
+		stagedMetadatas := current.GaugeWithMetadatas.StagedMetadatas
+		if err = p.aggregator.AddUntimed(untimedMetric, stagedMetadatas); err != nil {
+			p.metrics.addUntimedErrors.Inc(1)
+			p.logAddError(err, "error adding untimed metric", untimedMetric.ID.String())
+		}
+	case encoding.HistogramWithMetadatasType:
+		untimedMetric := current.HistogramWithMetadatas.Histogram.ToUnion()
+		stagedMetadatas := current.HistogramWithMetadatas.StagedMetadatas
+		if err = p.aggregator.AddUntimed(untimedMetric, stagedMetadatas); err != nil {
+			p.metrics.addUntimedErrors.Inc(1)
+			p.logAddError(err, "error adding untimed metric", untimedMetric.ID.String())
+		}
+	case encoding.ForwardedMetricWithMetadataType:
+		forwardedMetric := current.ForwardedMetricWithMetadata.ForwardedMetric
+		forwardMetadata := current.ForwardedMetricWithMetadata.ForwardMetadata
+		if err = p.aggregator.AddForwarded(forwardedMetric, forwardMetadata); err != nil {
+			p.metrics.addForwardedErrors.Inc(1)
+			p.logAddError(err, "error adding forwarded metric", forwardedMetric.ID.String(),
+				log.NewField("timestamp", time.Unix(0, forwardedMetric.TimeNanos).String()))
+		}
+	case encoding.TimedMetricWithMetadataType:
+		timedMetric := current.TimedMetricWithMetadata.Metric
+		timedMetadata := current.TimedMetricWithMetadata.TimedMetadata
+		if err = p.aggregator.AddTimed(timedMetric, timedMetadata); err != nil {
+			p.metrics.addTimedErrors.Inc(1)
+			p.logAddError(err, "error adding timed metric", timedMetric.ID.String(),
+				log.NewField("timestamp", time.Unix(0, timedMetric.TimeNanos).String()))
+		}
+	default:
+		p.metrics.unknownMessageTypeErrors.Inc(1)
+		if p.shouldLogError() {
+			p.log.WithFields(log.NewField("type", current.Type)).Error("unexpected message type")
+		}
+	}
+	return err
+}
+
+func (p *messageProcessor) logAddError(err error, msg string, id string, fields ...log.Field) {
+	if !p.shouldLogError() {
+		return
+	}
+	fields = append(fields, log.NewField("id", id), log.NewErrField(err))
+	p.log.WithFields(fields...).Error(msg)
+}
+
+// shouldLogError rate limits the error logs because the error rate may scale
+// with the incoming metrics rate and consume lots of cpu cycles.
+func (p *messageProcessor) shouldLogError() bool {
+	if p.errLogRateLimiter != nil && !p.errLogRateLimiter.IsAllowed(1) {
+		p.metrics.errLogRateLimited.Inc(1)
+		return false
+	}
+	return true
+}
+
+func (p *messageProcessor) Close() {
+	// NB: do not close the aggregator here because it's shared between
+	// the servers, and it will be closed on exit signal.
+}
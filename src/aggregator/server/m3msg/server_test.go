@@ -0,0 +1,170 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package m3msg
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/aggregator/aggregator/capture"
+	"github.com/m3db/m3/src/metrics/aggregation"
+	"github.com/m3db/m3/src/metrics/encoding"
+	"github.com/m3db/m3/src/metrics/encoding/protobuf"
+	"github.com/m3db/m3/src/metrics/metadata"
+	"github.com/m3db/m3/src/metrics/metric"
+	"github.com/m3db/m3/src/metrics/metric/aggregated"
+	"github.com/m3db/m3/src/metrics/metric/unaggregated"
+	"github.com/m3db/m3/src/metrics/policy"
+	"github.com/m3db/m3/src/msg/consumer"
+	xerrors "github.com/m3db/m3x/errors"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testCounterWithMetadatas = unaggregated.CounterWithMetadatas{
+		Counter: unaggregated.Counter{
+			ID:    []byte("testCounter"),
+			Value: 123,
+		},
+		StagedMetadatas: metadata.DefaultStagedMetadatas,
+	}
+	testGaugeWithMetadatas = unaggregated.GaugeWithMetadatas{
+		Gauge: unaggregated.Gauge{
+			ID:    []byte("testGauge"),
+			Value: 456.780,
+		},
+		StagedMetadatas: metadata.DefaultStagedMetadatas,
+	}
+	testTimedMetricWithMetadata = aggregated.TimedMetricWithMetadata{
+		Metric: aggregated.Metric{
+			Type:      metric.CounterType,
+			ID:        []byte("testTimed"),
+			TimeNanos: 12345,
+			Value:     -13,
+		},
+		TimedMetadata: metadata.TimedMetadata{
+			AggregationID: aggregation.DefaultID,
+			StoragePolicy: policy.NewStoragePolicy(time.Minute, xtime.Minute, 12*time.Hour),
+		},
+	}
+	testCmpOpts = []cmp.Option{
+		cmpopts.EquateEmpty(),
+		cmp.AllowUnexported(policy.StoragePolicy{}),
+	}
+)
+
+func TestMessageProcessorProcess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	encoder := protobuf.NewUnaggregatedEncoder(protobuf.NewUnaggregatedOptions())
+	require.NoError(t, encoder.EncodeMessage(encoding.UnaggregatedMessageUnion{
+		Type:                 encoding.CounterWithMetadatasType,
+		CounterWithMetadatas: testCounterWithMetadatas,
+	}))
+	require.NoError(t, encoder.EncodeMessage(encoding.UnaggregatedMessageUnion{
+		Type:               encoding.GaugeWithMetadatasType,
+		GaugeWithMetadatas: testGaugeWithMetadatas,
+	}))
+	require.NoError(t, encoder.EncodeMessage(encoding.UnaggregatedMessageUnion{
+		Type:                    encoding.TimedMetricWithMetadataType,
+		TimedMetricWithMetadata: testTimedMetricWithMetadata,
+	}))
+	buf := encoder.Relinquish()
+
+	msg := consumer.NewMockMessage(ctrl)
+	msg.EXPECT().Bytes().Return(buf.Bytes())
+	msg.EXPECT().Ack()
+
+	agg := capture.NewAggregator()
+	p := NewMessageProcessor(agg, NewOptions())
+	p.Process(msg)
+	p.Close()
+
+	expected := capture.SnapshotResult{
+		CountersWithMetadatas:   []unaggregated.CounterWithMetadatas{testCounterWithMetadatas},
+		GaugesWithMetadatas:     []unaggregated.GaugeWithMetadatas{testGaugeWithMetadatas},
+		TimedMetricWithMetadata: []aggregated.TimedMetricWithMetadata{testTimedMetricWithMetadata},
+	}
+	snapshot := agg.Snapshot()
+	require.True(t, cmp.Equal(expected, snapshot, testCmpOpts...), expected, snapshot)
+}
+
+func TestMessageProcessorProcessDecodeErrorAcksMessage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	msg := consumer.NewMockMessage(ctrl)
+	msg.EXPECT().Bytes().Return([]byte{0x04, 0xff, 0xff, 0xff})
+	msg.EXPECT().Ack()
+
+	agg := capture.NewAggregator()
+	p := NewMessageProcessor(agg, NewOptions())
+	p.Process(msg)
+	require.Equal(t, 0, agg.NumMetricsAdded())
+}
+
+func TestMessageProcessorProcessAddErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	encoder := protobuf.NewUnaggregatedEncoder(protobuf.NewUnaggregatedOptions())
+	require.NoError(t, encoder.EncodeMessage(encoding.UnaggregatedMessageUnion{
+		Type:                 encoding.CounterWithMetadatasType,
+		CounterWithMetadatas: testCounterWithMetadatas,
+	}))
+	data := encoder.Relinquish().Bytes()
+
+	// Messages failing with a retryable error are not acked so they get redelivered.
+	msg := consumer.NewMockMessage(ctrl)
+	msg.EXPECT().Bytes().Return(data)
+	agg := &errAggregator{
+		Aggregator: capture.NewAggregator(),
+		err:        xerrors.NewRetryableError(errors.New("shard not owned")),
+	}
+	NewMessageProcessor(agg, NewOptions()).Process(msg)
+
+	// Messages failing with a non-retryable error are acked.
+	msg = consumer.NewMockMessage(ctrl)
+	msg.EXPECT().Bytes().Return(data)
+	msg.EXPECT().Ack()
+	agg.err = errors.New("too far in the past")
+	NewMessageProcessor(agg, NewOptions()).Process(msg)
+}
+
+type errAggregator struct {
+	capture.Aggregator
+
+	err error
+}
+
+func (agg *errAggregator) AddUntimed(
+	unaggregated.MetricUnion,
+	metadata.StagedMetadatas,
+) error {
+	return agg.err
+}
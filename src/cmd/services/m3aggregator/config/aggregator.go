@@ -105,6 +105,9 @@ type AggregatorConfiguration struct {
 	// Flush times manager.
 	FlushTimesManager flushTimesManagerConfiguration `yaml:"flushTimesManager"`
 
+	// Checkpoint manager, if not configured open aggregation windows are not checkpointed.
+	CheckpointManager *checkpointManagerConfiguration `yaml:"checkpointManager"`
+
 	// Election manager.
 	ElectionManager electionManagerConfiguration `yaml:"electionManager"`
 
@@ -248,6 +251,17 @@ func (c *AggregatorConfiguration) NewAggregatorOptions(
 	}
 	opts = opts.SetFlushTimesManager(flushTimesManager)
 
+	// Set checkpoint manager.
+	var checkpointManager aggregator.CheckpointManager
+	if c.CheckpointManager != nil {
+		iOpts = instrumentOpts.SetMetricsScope(scope.SubScope("checkpoint-manager"))
+		checkpointManager, err = c.CheckpointManager.NewCheckpointManager(client, iOpts)
+		if err != nil {
+			return nil, err
+		}
+		opts = opts.SetCheckpointManager(checkpointManager)
+	}
+
 	// Set election manager.
 	iOpts = instrumentOpts.SetMetricsScope(scope.SubScope("election-manager"))
 	placementNamespace := c.PlacementManager.KVConfig.Namespace
@@ -270,6 +284,7 @@ func (c *AggregatorConfiguration) NewAggregatorOptions(
 		placementManager,
 		electionManager,
 		flushTimesManager,
+		checkpointManager,
 		iOpts,
 	)
 	if err != nil {
@@ -531,6 +546,39 @@ func (c flushTimesManagerConfiguration) NewFlushTimesManager(
 	return aggregator.NewFlushTimesManager(flushTimesManagerOpts), nil
 }
 
+type checkpointManagerConfiguration struct {
+	// KV Configuration.
+	KVConfig kv.OverrideConfiguration `yaml:"kvConfig"`
+
+	// Checkpoint key format, which takes the shard set id and the shard id.
+	CheckpointKeyFmt string `yaml:"checkpointKeyFmt" validate:"nonzero"`
+
+	// Retrier for persisting checkpoints.
+	CheckpointPersistRetrier retry.Configuration `yaml:"checkpointPersistRetrier"`
+}
+
+func (c checkpointManagerConfiguration) NewCheckpointManager(
+	client client.Client,
+	instrumentOpts instrument.Options,
+) (aggregator.CheckpointManager, error) {
+	kvOpts, err := c.KVConfig.NewOverrideOptions()
+	if err != nil {
+		return nil, err
+	}
+	store, err := client.Store(kvOpts)
+	if err != nil {
+		return nil, err
+	}
+	scope := instrumentOpts.MetricsScope()
+	retrier := c.CheckpointPersistRetrier.NewRetrier(scope.SubScope("checkpoint-persist"))
+	checkpointManagerOpts := aggregator.NewCheckpointManagerOptions().
+		SetInstrumentOptions(instrumentOpts).
+		SetCheckpointKeyFmt(c.CheckpointKeyFmt).
+		SetCheckpointStore(store).
+		SetCheckpointPersistRetrier(retrier)
+	return aggregator.NewCheckpointManager(checkpointManagerOpts), nil
+}
+
 type electionManagerConfiguration struct {
 	Election                   electionConfiguration  `yaml:"election"`
 	ServiceID                  serviceIDConfiguration `yaml:"serviceID"`
@@ -657,6 +705,12 @@ type flushManagerConfiguration struct {
 	// How frequently the flush times are persisted.
 	FlushTimesPersistEvery time.Duration `yaml:"flushTimesPersistEvery"`
 
+	// How frequently the open aggregation windows are checkpointed.
+	CheckpointEvery time.Duration `yaml:"checkpointEvery"`
+
+	// Maximum number of open aggregation windows checkpointed per shard.
+	MaxCheckpointWindowsPerShard int `yaml:"maxCheckpointWindowsPerShard"`
+
 	// Maximum buffer size.
 	MaxBufferSize time.Duration `yaml:"maxBufferSize"`
 
@@ -668,13 +722,15 @@ func (c flushManagerConfiguration) NewFlushManagerOptions(
 	placementManager aggregator.PlacementManager,
 	electionManager aggregator.ElectionManager,
 	flushTimesManager aggregator.FlushTimesManager,
+	checkpointManager aggregator.CheckpointManager,
 	instrumentOpts instrument.Options,
 ) (aggregator.FlushManagerOptions, error) {
 	opts := aggregator.NewFlushManagerOptions().
 		SetInstrumentOptions(instrumentOpts).
 		SetPlacementManager(placementManager).
 		SetElectionManager(electionManager).
-		SetFlushTimesManager(flushTimesManager)
+		SetFlushTimesManager(flushTimesManager).
+		SetCheckpointManager(checkpointManager)
 	if c.CheckEvery != 0 {
 		opts = opts.SetCheckEvery(c.CheckEvery)
 	}
@@ -697,6 +753,12 @@ func (c flushManagerConfiguration) NewFlushManagerOptions(
 	if c.FlushTimesPersistEvery != 0 {
 		opts = opts.SetFlushTimesPersistEvery(c.FlushTimesPersistEvery)
 	}
+	if c.CheckpointEvery != 0 {
+		opts = opts.SetCheckpointEvery(c.CheckpointEvery)
+	}
+	if c.MaxCheckpointWindowsPerShard != 0 {
+		opts = opts.SetMaxCheckpointWindowsPerShard(c.MaxCheckpointWindowsPerShard)
+	}
 	if c.MaxBufferSize != 0 {
 		opts = opts.SetMaxBufferSize(c.MaxBufferSize)
 	}
@@ -24,9 +24,11 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/aggregator/server/http"
+	"github.com/m3db/m3/src/aggregator/server/m3msg"
 	"github.com/m3db/m3/src/aggregator/server/rawtcp"
 	"github.com/m3db/m3/src/metrics/encoding/msgpack"
 	"github.com/m3db/m3/src/metrics/encoding/protobuf"
+	"github.com/m3db/m3/src/msg/consumer"
 	"github.com/m3db/m3x/instrument"
 	"github.com/m3db/m3x/pool"
 	"github.com/m3db/m3x/retry"
@@ -95,6 +97,63 @@ func (c *RawTCPServerConfiguration) NewServerOptions(
 	return opts
 }
 
+// M3MsgServerConfiguration contains m3msg server configuration.
+type M3MsgServerConfiguration struct {
+	// M3msg server listening address.
+	ListenAddress string `yaml:"listenAddress" validate:"nonzero"`
+
+	// Error log limit per second.
+	ErrorLogLimitPerSecond *int64 `yaml:"errorLogLimitPerSecond"`
+
+	// Whether keep alives are enabled on connections.
+	KeepAliveEnabled *bool `yaml:"keepAliveEnabled"`
+
+	// KeepAlive period.
+	KeepAlivePeriod *time.Duration `yaml:"keepAlivePeriod"`
+
+	// Retry mechanism configuration.
+	Retry retry.Configuration `yaml:"retry"`
+
+	// M3msg consumer configuration.
+	Consumer consumer.Configuration `yaml:"consumer"`
+
+	// Protobuf iterator configuration.
+	ProtobufIterator protobufUnaggregatedIteratorConfiguration `yaml:"protobufIterator"`
+}
+
+// NewServerOptions create a new set of m3msg server options.
+func (c *M3MsgServerConfiguration) NewServerOptions(
+	instrumentOpts instrument.Options,
+) m3msg.Options {
+	opts := m3msg.NewOptions().SetInstrumentOptions(instrumentOpts)
+
+	// Set server options.
+	serverOpts := xserver.NewOptions().
+		SetInstrumentOptions(instrumentOpts).
+		SetRetryOptions(c.Retry.NewOptions(instrumentOpts.MetricsScope()))
+	if c.KeepAliveEnabled != nil {
+		serverOpts = serverOpts.SetTCPConnectionKeepAlive(*c.KeepAliveEnabled)
+	}
+	if c.KeepAlivePeriod != nil {
+		serverOpts = serverOpts.SetTCPConnectionKeepAlivePeriod(*c.KeepAlivePeriod)
+	}
+	opts = opts.SetServerOptions(serverOpts)
+
+	// Set consumer options.
+	scope := instrumentOpts.MetricsScope()
+	consumerOpts := c.Consumer.NewOptions(instrumentOpts.SetMetricsScope(scope.SubScope("consumer")))
+	opts = opts.SetConsumerOptions(consumerOpts)
+
+	// Set protobuf iterator options.
+	protobufItOpts := c.ProtobufIterator.NewOptions(instrumentOpts)
+	opts = opts.SetProtobufUnaggregatedIteratorOptions(protobufItOpts)
+
+	if c.ErrorLogLimitPerSecond != nil {
+		opts = opts.SetErrorLogLimitPerSecond(*c.ErrorLogLimitPerSecond)
+	}
+	return opts
+}
+
 // msgpackUnaggregatedIteratorConfiguration contains configuration for msgpack unaggregated iterator.
 type msgpackUnaggregatedIteratorConfiguration struct {
 	// Whether to ignore encoded data streams whose version is higher than the current known version.
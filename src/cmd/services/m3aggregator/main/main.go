@@ -29,6 +29,7 @@ import (
 	"time"
 
 	m3aggregator "github.com/m3db/m3/src/aggregator/aggregator"
+	m3msgserver "github.com/m3db/m3/src/aggregator/server/m3msg"
 	"github.com/m3db/m3/src/cmd/services/m3aggregator/config"
 	"github.com/m3db/m3/src/cmd/services/m3aggregator/serve"
 	xconfig "github.com/m3db/m3x/config"
@@ -80,6 +81,18 @@ func main() {
 	iOpts := instrumentOpts.SetMetricsScope(rawTCPServerScope)
 	rawTCPServerOpts := cfg.RawTCP.NewServerOptions(iOpts)
 
+	// Create the m3msg server options if the m3msg server is configured.
+	var (
+		m3msgAddr       string
+		m3msgServerOpts m3msgserver.Options
+	)
+	if cfg.M3Msg != nil {
+		m3msgAddr = cfg.M3Msg.ListenAddress
+		m3msgServerScope := scope.SubScope("m3msg-server").Tagged(map[string]string{"server": "m3msg"})
+		iOpts = instrumentOpts.SetMetricsScope(m3msgServerScope)
+		m3msgServerOpts = cfg.M3Msg.NewServerOptions(iOpts)
+	}
+
 	// Create the http server options.
 	httpAddr := cfg.HTTP.ListenAddress
 	httpServerOpts := cfg.HTTP.NewServerOptions()
@@ -115,6 +128,8 @@ func main() {
 		if err := serve.Serve(
 			rawTCPAddr,
 			rawTCPServerOpts,
+			m3msgAddr,
+			m3msgServerOpts,
 			httpAddr,
 			httpServerOpts,
 			aggregator,
@@ -25,6 +25,7 @@ import (
 
 	"github.com/m3db/m3/src/aggregator/aggregator"
 	httpserver "github.com/m3db/m3/src/aggregator/server/http"
+	m3msgserver "github.com/m3db/m3/src/aggregator/server/m3msg"
 	rawtcpserver "github.com/m3db/m3/src/aggregator/server/rawtcp"
 )
 
@@ -32,6 +33,8 @@ import (
 func Serve(
 	rawTCPAddr string,
 	rawTCPServerOpts rawtcpserver.Options,
+	m3msgAddr string,
+	m3msgServerOpts m3msgserver.Options,
 	httpAddr string,
 	httpServerOpts httpserver.Options,
 	aggregator aggregator.Aggregator,
@@ -47,6 +50,16 @@ func Serve(
 	defer rawTCPServer.Close()
 	log.Infof("raw TCP server: listening on %s", rawTCPAddr)
 
+	// The m3msg server is optional and only started if an address is configured.
+	if m3msgAddr != "" {
+		m3msgServer := m3msgserver.NewServer(m3msgAddr, aggregator, m3msgServerOpts)
+		if err := m3msgServer.ListenAndServe(); err != nil {
+			return fmt.Errorf("could not start m3msg server at %s: %v", m3msgAddr, err)
+		}
+		defer m3msgServer.Close()
+		log.Infof("m3msg server: listening on %s", m3msgAddr)
+	}
+
 	httpServer := httpserver.NewServer(httpAddr, aggregator, httpServerOpts)
 	if err := httpServer.ListenAndServe(); err != nil {
 		return fmt.Errorf("could not start http server at %s: %v", httpAddr, err)
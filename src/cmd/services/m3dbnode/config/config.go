@@ -32,6 +32,7 @@ import (
 	coordinatorcfg "github.com/m3db/m3/src/cmd/services/m3query/config"
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/environment"
+	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
 	"github.com/m3db/m3x/config/hostid"
 	"github.com/m3db/m3x/instrument"
 	xlog "github.com/m3db/m3x/log"
@@ -63,6 +64,9 @@ type DBConfiguration struct {
 	// Index configuration.
 	Index IndexConfiguration `yaml:"index"`
 
+	// Limits configuration.
+	Limits *LimitsConfiguration `yaml:"limits"`
+
 	// Logging configuration.
 	Logging xlog.Configuration `yaml:"logging"`
 
@@ -195,6 +199,15 @@ type CommitLogPolicy struct {
 
 	// The commit log block size.
 	BlockSize time.Duration `yaml:"blockSize" validate:"nonzero"`
+
+	// The commit log write strategy, defaults to write_behind. The group_commit
+	// strategy batches concurrent writes and acknowledges each write only once
+	// the batch containing it has been fsync'd to disk.
+	Strategy *commitlog.Strategy `yaml:"strategy"`
+
+	// The maximum number of writes acknowledged by a single fsync when using
+	// the group_commit strategy.
+	GroupCommitMaxBatchSize *int `yaml:"groupCommitMaxBatchSize"`
 }
 
 // CalculationType is a type of configuration parameter.
@@ -235,6 +248,14 @@ type RepairPolicy struct {
 
 	// The repair check interval.
 	CheckInterval time.Duration `yaml:"checkInterval" validate:"nonzero"`
+
+	// Whether repairs only record differences between replicas rather than
+	// fetching and persisting the differing blocks.
+	DryRun bool `yaml:"dryRun"`
+
+	// The max number of differing blocks to fetch from peers per second per
+	// shard, zero means unlimited.
+	MaxBlocksPerSecond *int `yaml:"maxBlocksPerSecond" validate:"min=0"`
 }
 
 // HashingConfiguration is the configuration for hashing.
@@ -315,6 +315,7 @@ func TestConfiguration(t *testing.T) {
 	expected := `db:
   index:
     maxQueryIDsConcurrency: 0
+  limits: null
   logging:
     file: /var/log/m3dbnode.log
     level: info
@@ -400,6 +401,8 @@ func TestConfiguration(t *testing.T) {
     mmap: null
     force_index_summaries_mmap_memory: true
     force_bloom_filter_mmap_memory: true
+    tier: null
+    compaction: null
   commitlog:
     flushMaxBytes: 524288
     flushEvery: 1s
@@ -408,6 +411,8 @@ func TestConfiguration(t *testing.T) {
       size: 2097152
     queueChannel: null
     blockSize: 10m0s
+    strategy: null
+    groupCommitMaxBatchSize: null
   repair:
     enabled: false
     interval: 2h0m0s
@@ -415,6 +420,8 @@ func TestConfiguration(t *testing.T) {
     jitter: 1h0m0s
     throttle: 2m0s
     checkInterval: 1m0s
+    dryRun: false
+    maxBlocksPerSecond: null
   pooling:
     blockAllocSize: 16
     type: simple
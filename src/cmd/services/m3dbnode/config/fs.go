@@ -23,6 +23,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 )
 
 const (
@@ -83,6 +84,36 @@ type FilesystemConfiguration struct {
 	// ForceBloomFilterMmapMemory forces the mmap that stores the index lookup bytes
 	// to be an anonymous region in memory as opposed to a file-based mmap.
 	ForceBloomFilterMmapMemory bool `yaml:"force_bloom_filter_mmap_memory"`
+
+	// Tier is the optional secondary storage tier that flushed filesets are
+	// migrated to once they are older than the tier migration age.
+	Tier *FilesystemTierConfiguration `yaml:"tier"`
+
+	// Compaction is the optional configuration for compacting flushed
+	// filesets of consecutive blocks into a single larger fileset.
+	Compaction *FilesystemCompactionConfiguration `yaml:"compaction"`
+}
+
+// FilesystemTierConfiguration is the secondary filesystem tier configuration.
+type FilesystemTierConfiguration struct {
+	// FilePathPrefix is the file path prefix of the directory that holds
+	// migrated filesets, typically a mount of cheaper storage.
+	FilePathPrefix string `yaml:"filePathPrefix" validate:"nonzero"`
+
+	// MigrationAge is the age past the end of a block at which its
+	// flushed filesets are migrated to the tier.
+	MigrationAge time.Duration `yaml:"migrationAge" validate:"nonzero"`
+}
+
+// FilesystemCompactionConfiguration is the fileset compaction configuration.
+type FilesystemCompactionConfiguration struct {
+	// Blocks is the number of consecutive blocks compacted into a
+	// single fileset.
+	Blocks int `yaml:"blocks" validate:"min=2"`
+
+	// Age is the age past the end of the compacted blocks at which
+	// their filesets are compacted.
+	Age time.Duration `yaml:"age" validate:"nonzero"`
 }
 
 // MmapConfiguration is the mmap configuration.
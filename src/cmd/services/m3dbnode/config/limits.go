@@ -0,0 +1,96 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/storage/limits"
+)
+
+// LimitsConfiguration contains configuration for the limits imposed on
+// queries to protect the node from expensive requests.
+type LimitsConfiguration struct {
+	// MaxDocsMatchedPerQuery is the maximum number of index documents a single
+	// query may match before it is aborted, zero disables the limit.
+	MaxDocsMatchedPerQuery int `yaml:"maxDocsMatchedPerQuery" validate:"min=0"`
+
+	// MaxSeriesFetchedPerQuery is the maximum number of series a single query
+	// may read data for before it is aborted, zero disables the limit.
+	MaxSeriesFetchedPerQuery int `yaml:"maxSeriesFetchedPerQuery" validate:"min=0"`
+
+	// MaxBytesReadPerQuery is the maximum number of bytes a single query may
+	// read from blocks before it is aborted, zero disables the limit.
+	MaxBytesReadPerQuery int64 `yaml:"maxBytesReadPerQuery" validate:"min=0"`
+
+	// MaxDatapointsReadPerQuery is the maximum number of datapoints a single
+	// query may read from blocks before it is aborted, zero disables the limit.
+	// NB: Enabling the limit requires the data read to be decoded to count
+	// its datapoints.
+	MaxDatapointsReadPerQuery int64 `yaml:"maxDatapointsReadPerQuery" validate:"min=0"`
+
+	// MaxRecentlyMatchedDocs limits the number of index documents matched by
+	// all queries to a namespace within a rolling lookback window.
+	MaxRecentlyMatchedDocs *LookbackLimitConfiguration `yaml:"maxRecentlyMatchedDocs"`
+
+	// MaxRecentlyReadBytes limits the number of bytes read from blocks by all
+	// queries to a namespace within a rolling lookback window.
+	MaxRecentlyReadBytes *LookbackLimitConfiguration `yaml:"maxRecentlyReadBytes"`
+}
+
+// LookbackLimitConfiguration is the configuration for a limit that is
+// enforced over a rolling lookback window.
+type LookbackLimitConfiguration struct {
+	// Value is the limit value, zero disables the limit.
+	Value int64 `yaml:"value" validate:"min=0"`
+
+	// Lookback is the period over which the limit is enforced.
+	Lookback time.Duration `yaml:"lookback" validate:"min=0"`
+}
+
+// NewOptions returns the limits options for the configuration.
+func (c LimitsConfiguration) NewOptions() limits.Options {
+	opts := limits.NewOptions().
+		SetMaxDocsMatchedPerQuery(c.MaxDocsMatchedPerQuery).
+		SetMaxSeriesFetchedPerQuery(c.MaxSeriesFetchedPerQuery).
+		SetMaxBytesReadPerQuery(c.MaxBytesReadPerQuery).
+		SetMaxDatapointsReadPerQuery(c.MaxDatapointsReadPerQuery)
+	if v := c.MaxRecentlyMatchedDocs; v != nil {
+		opts = opts.SetDocsLimitOptions(v.options(opts.DocsLimitOptions()))
+	}
+	if v := c.MaxRecentlyReadBytes; v != nil {
+		opts = opts.SetBytesReadLimitOptions(v.options(opts.BytesReadLimitOptions()))
+	}
+	return opts
+}
+
+func (c LookbackLimitConfiguration) options(
+	defaults limits.LookbackLimitOptions,
+) limits.LookbackLimitOptions {
+	opts := limits.LookbackLimitOptions{
+		Limit:    c.Value,
+		Lookback: defaults.Lookback,
+	}
+	if c.Lookback > 0 {
+		opts.Lookback = c.Lookback
+	}
+	return opts
+}
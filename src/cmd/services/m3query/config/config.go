@@ -29,6 +29,7 @@ import (
 	ingestm3msg "github.com/m3db/m3/src/cmd/services/m3coordinator/ingest/m3msg"
 	"github.com/m3db/m3/src/cmd/services/m3coordinator/server/m3msg"
 	"github.com/m3db/m3/src/metrics/aggregation"
+	"github.com/m3db/m3/src/query/cache"
 	"github.com/m3db/m3/src/query/graphite/graphite"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/storage"
@@ -54,6 +55,8 @@ var (
 	// 5m is the default lookback in Prometheus
 	defaultLookbackDuration = 5 * time.Minute
 
+	defaultServerSideDownsampleStepRatio = 10
+
 	defaultCarbonIngesterWriteTimeout    = 15 * time.Second
 	defaultCarbonIngesterAggregationType = aggregation.Mean
 
@@ -116,6 +119,19 @@ type Configuration struct {
 
 	// LookbackDuration determines the lookback duration for queries
 	LookbackDuration *time.Duration `yaml:"lookbackDuration"`
+
+	// ResultsCache is the configuration for caching range query results,
+	// results are not cached if not set.
+	ResultsCache *cache.Configuration `yaml:"resultsCache"`
+
+	// Stitching is the configuration for stitching results from the
+	// unaggregated and aggregated namespaces together by resolution,
+	// results are not stitched if not set.
+	Stitching *m3.StitchingConfiguration `yaml:"stitching"`
+
+	// ServerSideDownsample is the configuration for downsampling the series
+	// fetched by queries on the database nodes.
+	ServerSideDownsample ServerSideDownsampleConfiguration `yaml:"serverSideDownsample"`
 }
 
 // Filter is a query filter type.
@@ -144,6 +160,40 @@ type LimitsConfiguration struct {
 	MaxComputedDatapoints int64 `yaml:"maxComputedDatapoints"`
 }
 
+// ServerSideDownsampleConfiguration is the configuration for downsampling
+// the series fetched by queries on the database nodes, which is only done
+// for the series of plain selectors, or of range selectors consumed by
+// min, max, sum or last_over_time, since any other function must see the
+// raw datapoints of a series.
+type ServerSideDownsampleConfiguration struct {
+	// Enabled downsamples the series fetched by queries when that does not
+	// change their result, series are always fetched raw if not enabled.
+	Enabled bool `yaml:"enabled"`
+
+	// StepRatio is how many times smaller than the step of a query, and the
+	// range of any range selector, the resolution series are downsampled to
+	// is, defaults to 10.
+	StepRatio int `yaml:"stepRatio"`
+}
+
+// StepRatioOrDefault returns how many times smaller than the step of a query
+// the resolution series are downsampled to is, zero if not enabled.
+func (c ServerSideDownsampleConfiguration) StepRatioOrDefault() (int, error) {
+	if !c.Enabled {
+		return 0, nil
+	}
+
+	if c.StepRatio < 0 {
+		return 0, errors.New("serverSideDownsample stepRatio must be > 0")
+	}
+
+	if c.StepRatio == 0 {
+		return defaultServerSideDownsampleStepRatio, nil
+	}
+
+	return c.StepRatio, nil
+}
+
 // IngestConfiguration is the configuration for ingestion server.
 type IngestConfiguration struct {
 	// Ingester is the configuration for storage based ingester.
@@ -115,3 +115,25 @@ func TestTagOptionsConfig(t *testing.T) {
 	assert.Equal(t, []byte("abcdefg"), opts.MetricName())
 	assert.Equal(t, models.TypePrependMeta, opts.IDSchemeType())
 }
+
+func TestServerSideDownsampleConfig(t *testing.T) {
+	var cfg ServerSideDownsampleConfiguration
+	require.NoError(t, yaml.Unmarshal([]byte(""), &cfg))
+	ratio, err := cfg.StepRatioOrDefault()
+	require.NoError(t, err)
+	assert.Equal(t, 0, ratio)
+
+	require.NoError(t, yaml.Unmarshal([]byte("enabled: true"), &cfg))
+	ratio, err = cfg.StepRatioOrDefault()
+	require.NoError(t, err)
+	assert.Equal(t, defaultServerSideDownsampleStepRatio, ratio)
+
+	require.NoError(t, yaml.Unmarshal([]byte("enabled: true\nstepRatio: 20"), &cfg))
+	ratio, err = cfg.StepRatioOrDefault()
+	require.NoError(t, err)
+	assert.Equal(t, 20, ratio)
+
+	require.NoError(t, yaml.Unmarshal([]byte("enabled: true\nstepRatio: -1"), &cfg))
+	_, err = cfg.StepRatioOrDefault()
+	assert.Error(t, err)
+}
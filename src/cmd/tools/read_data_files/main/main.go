@@ -30,6 +30,7 @@ import (
 
 	"github.com/m3db/m3/src/cmd/tools"
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/dod"
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
@@ -101,6 +102,13 @@ func main() {
 		log.Fatalf("unable to open reader: %v", err)
 	}
 
+	codecs := encoding.NewCodecRegistry(encodingOpts, nil,
+		m3tsz.NewCodec(), m3tsz.NewXORCodec(), dod.NewCodec())
+	dataCodec, err := codecs.Codec(reader.CodecID())
+	if err != nil {
+		log.Fatalf("unable to decode fileset: %v", err)
+	}
+
 	for {
 		id, _, data, _, err := reader.Read()
 		if err == io.EOF {
@@ -115,7 +123,7 @@ func main() {
 		}
 
 		data.IncRef()
-		iter := m3tsz.NewReaderIterator(bytes.NewReader(data.Bytes()), true, encodingOpts)
+		iter := dataCodec.NewReaderIterator(bytes.NewReader(data.Bytes()), encodingOpts)
 		for iter.Next() {
 			dp, _, _ := iter.Current()
 			// Use fmt package so it goes to stdout instead of stderr
@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/dod"
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
 	"github.com/m3db/m3/src/dbnode/environment"
 	"github.com/m3db/m3/src/dbnode/topology"
@@ -199,6 +200,8 @@ func (c Configuration) NewAdminClient(
 		intOptimized := m3tsz.DefaultIntOptimizationEnabled
 		return m3tsz.NewReaderIterator(r, intOptimized, encodingOpts)
 	})
+	v = v.SetCodecRegistry(encoding.NewCodecRegistry(encodingOpts, nil,
+		m3tsz.NewCodec(), m3tsz.NewXORCodec(), dod.NewCodec()))
 
 	// Apply programtic custom options last
 	opts := v.(AdminOptions)
@@ -54,6 +54,18 @@ func IsBadRequestError(err error) bool {
 	return false
 }
 
+// IsResourceExhaustedError determines if the error is a resource exhausted
+// error, returned when a query exceeds the limits imposed by a node.
+func IsResourceExhaustedError(err error) bool {
+	for err != nil {
+		if e, ok := err.(*rpc.Error); ok && tterrors.IsResourceExhaustedError(e) {
+			return true
+		}
+		err = xerrors.InnerError(err)
+	}
+	return false
+}
+
 // IsConsistencyResultError determines if the error is a consistency result error.
 func IsConsistencyResultError(err error) bool {
 	_, ok := err.(consistencyResultErr)
@@ -117,14 +129,15 @@ func newConsistencyResultError(
 	errs []error,
 ) consistencyResultError {
 	// NB(r): if any errors are bad request errors, encapsulate that error
-	// to ensure the error itself is wholly classified as a bad request error
+	// to ensure the error itself is wholly classified as a bad request error,
+	// the same applies to resource exhausted errors
 	var topLevelErr error
 	for i := 0; i < len(errs); i++ {
 		if topLevelErr == nil {
 			topLevelErr = errs[i]
 			continue
 		}
-		if IsBadRequestError(errs[i]) {
+		if IsBadRequestError(errs[i]) || IsResourceExhaustedError(errs[i]) {
 			topLevelErr = errs[i]
 			break
 		}
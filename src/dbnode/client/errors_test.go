@@ -54,3 +54,17 @@ func TestConsistencyResultError(t *testing.T) {
 	assert.Equal(t, 1, NumSuccess(err))
 	assert.Equal(t, 2, NumError(err))
 }
+
+func TestConsistencyResultErrorPrefersResourceExhausted(t *testing.T) {
+	limitErr := &rpc.Error{
+		Type: rpc.ErrorType_RESOURCE_EXHAUSTED,
+	}
+
+	err := newConsistencyResultError(topology.ReadConsistencyLevelMajority,
+		3, 3, []error{fmt.Errorf("an error"), limitErr})
+
+	assert.Equal(t, limitErr, xerrors.InnerError(err))
+	assert.True(t, IsResourceExhaustedError(err))
+	assert.False(t, IsBadRequestError(err))
+	assert.False(t, IsResourceExhaustedError(fmt.Errorf("an error")))
+}
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	xerrors "github.com/m3db/m3x/errors"
 	"github.com/m3db/m3x/ident"
 	"github.com/m3db/m3x/pool"
@@ -43,10 +44,12 @@ type fetchAttempt struct {
 }
 
 type fetchAttemptArgs struct {
-	namespace ident.ID
-	ids       ident.Iterator
-	start     time.Time
-	end       time.Time
+	namespace                 ident.ID
+	ids                       ident.Iterator
+	start                     time.Time
+	end                       time.Time
+	downsampleResolutionNanos *int64
+	downsampleAggregationType rpc.DownsampleAggregationType
 }
 
 func (f *fetchAttempt) reset() {
@@ -56,11 +59,12 @@ func (f *fetchAttempt) reset() {
 
 func (f *fetchAttempt) perform() error {
 	result, err := f.session.fetchIDsAttempt(f.args.namespace,
-		f.args.ids, f.args.start, f.args.end)
+		f.args.ids, f.args.start, f.args.end,
+		f.args.downsampleResolutionNanos, f.args.downsampleAggregationType)
 	f.result = result
 
-	if IsBadRequestError(err) {
-		// Do not retry bad request errors
+	if IsBadRequestError(err) || IsResourceExhaustedError(err) {
+		// Do not retry bad request or resource exhausted errors
 		err = xerrors.NewNonRetryableError(err)
 	}
 
@@ -38,6 +38,8 @@ func (f *fetchBatchOp) reset() {
 	f.request.RangeStart = 0
 	f.request.RangeEnd = 0
 	f.request.NameSpace = nil
+	f.request.DownsampleResolutionNanos = nil
+	f.request.DownsampleAggregationType = rpc.DownsampleAggregationType_LAST
 	for i := range f.request.Ids {
 		f.request.Ids[i] = nil
 	}
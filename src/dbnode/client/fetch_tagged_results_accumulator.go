@@ -28,6 +28,7 @@ import (
 
 	"github.com/m3db/m3/src/cluster/shard"
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/topology"
 	xerrors "github.com/m3db/m3x/errors"
@@ -157,9 +158,18 @@ func (accum *fetchTaggedResultAccumulator) Add(
 	// all shards, so we need to fail
 	if accum.numHostsPending == 0 && accum.numShardsPending != 0 {
 		doneAccumulating := true
-		return doneAccumulating, fmt.Errorf(
+		err := fmt.Errorf(
 			"unable to satisfy consistency requirements for %d shards [ err = %s ]",
 			accum.numShardsPending, accum.errors.Error())
+		for _, hostErr := range accum.errors {
+			if IsResourceExhaustedError(hostErr) {
+				// NB: Retain the resource exhausted error so callers can classify
+				// the failure, retrying would only exceed the limit again.
+				err = xerrors.NewRenamedError(hostErr, err)
+				return doneAccumulating, xerrors.NewNonRetryableError(err)
+			}
+		}
+		return doneAccumulating, err
 	}
 
 	doneAccumulating := false
@@ -215,13 +225,27 @@ func (accum *fetchTaggedResultAccumulator) Reset(
 func (accum *fetchTaggedResultAccumulator) sliceResponsesAsSeriesIter(
 	pools fetchTaggedPools,
 	elems fetchTaggedIDResults,
-) encoding.SeriesIterator {
+) (encoding.SeriesIterator, error) {
+	// NB: the replicas of a series are all encoded with the codec of the
+	// namespace, which hosts only report when it is not the default.
+	codecID := codec.DefaultID
+	for _, elem := range elems {
+		if id := codecIDFromSegments(elem.Segments); id != codec.DefaultID {
+			codecID = id
+			break
+		}
+	}
+	multiIterPool, err := pools.MultiReaderIteratorForCodec(codecID)
+	if err != nil {
+		return nil, err
+	}
+
 	numElems := len(elems)
 	iters := pools.MultiReaderIteratorArray().Get(numElems)[:numElems]
 	for idx, elem := range elems {
 		slicesIter := pools.ReaderSliceOfSlicesIterator().Get()
 		slicesIter.Reset(elem.Segments)
-		multiIter := pools.MultiReaderIterator().Get()
+		multiIter := multiIterPool.Get()
 		multiIter.ResetSliceOfSlices(slicesIter)
 		iters[idx] = multiIter
 	}
@@ -245,9 +269,10 @@ func (accum *fetchTaggedResultAccumulator) sliceResponsesAsSeriesIter(
 		StartInclusive: accum.startTime,
 		EndExclusive:   accum.endTime,
 		Replicas:       iters,
+		CodecID:        codecID,
 	})
 
-	return seriesIter
+	return seriesIter, nil
 }
 
 func (accum *fetchTaggedResultAccumulator) AsEncodingSeriesIterators(
@@ -267,13 +292,22 @@ func (accum *fetchTaggedResultAccumulator) AsEncodingSeriesIterators(
 	result.Reset(numElements)
 	count := 0
 	moreElems := false
+	var err error
 	accum.responses.forEachID(func(elems fetchTaggedIDResults, hasMore bool) bool {
-		seriesIter := accum.sliceResponsesAsSeriesIter(pools, elems)
+		var seriesIter encoding.SeriesIterator
+		seriesIter, err = accum.sliceResponsesAsSeriesIter(pools, elems)
+		if err != nil {
+			return false
+		}
 		result.SetAt(count, seriesIter)
 		count++
 		moreElems = hasMore
 		return count < limit
 	})
+	if err != nil {
+		result.Close()
+		return nil, false, err
+	}
 
 	exhaustive := accum.exhaustive && count <= limit && !moreElems
 	return result, exhaustive, nil
@@ -29,6 +29,9 @@ import (
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/topology"
 	tu "github.com/m3db/m3/src/dbnode/topology/testutil"
+	xerrors "github.com/m3db/m3x/errors"
+
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -85,6 +88,38 @@ func TestFetchTaggedResultsAccumulatorAnyResponseShouldTerminateConsistencyLevel
 	}.run()
 }
 
+func TestFetchTaggedResultsAccumulatorResourceExhaustedIsNotRetryable(t *testing.T) {
+	// rf=3, 30 shards total; three identical hosts
+	topoMap := tu.MustNewTopologyMap(3, map[string][]shard.Shard{
+		"testhost0": tu.ShardsRange(0, 29, shard.Available),
+		"testhost1": tu.ShardsRange(0, 29, shard.Available),
+		"testhost2": tu.ShardsRange(0, 29, shard.Available),
+	})
+
+	limitErr := &rpc.Error{
+		Type:    rpc.ErrorType_RESOURCE_EXHAUSTED,
+		Message: "query aborted due to limit",
+	}
+	accum := newFetchTaggedResultAccumulator()
+	accum.Clear()
+	accum.Reset(time.Time{}, time.Time{}, topoMap, topoMap.MajorityReplicas(),
+		topology.ReadConsistencyLevelMajority)
+
+	var (
+		done bool
+		err  error
+	)
+	for i, hostErr := range []error{errTestFetchTagged, limitErr, errTestFetchTagged} {
+		done, err = accum.Add(fetchTaggedResultAccumulatorOpts{
+			host: host(t, topoMap, fmt.Sprintf("testhost%d", i)),
+		}, hostErr)
+	}
+	require.True(t, done)
+	require.Error(t, err)
+	require.True(t, IsResourceExhaustedError(err))
+	require.True(t, xerrors.IsNonRetryableError(err))
+}
+
 func TestFetchTaggedResultsAccumulatorShardAvailabilityIsEnforced(t *testing.T) {
 	// rf=3, 30 shards total; three identical hosts
 	topoMap := tu.MustNewTopologyMap(3, map[string][]shard.Shard{
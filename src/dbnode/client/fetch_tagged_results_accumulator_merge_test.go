@@ -27,6 +27,8 @@ import (
 
 	"github.com/m3db/m3/src/cluster/shard"
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
+	"github.com/m3db/m3/src/dbnode/encoding/dod"
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/convert"
 	"github.com/m3db/m3/src/dbnode/topology"
@@ -290,6 +292,63 @@ func TestFetchTaggedResultsAccumulatorSeriesItersDatapointsNSplit(t *testing.T)
 	sg0.assertMatchesEncodingIters(t, iters)
 }
 
+func TestFetchTaggedResultsAccumulatorSeriesItersDatapointsCodec(t *testing.T) {
+	// rf=3, 3 identical hosts, with same shards
+	topoMap := testutil.MustNewTopologyMap(3, map[string][]shard.Shard{
+		"testhost0": testutil.ShardsRange(0, 29, shard.Available),
+		"testhost1": testutil.ShardsRange(0, 29, shard.Available),
+		"testhost2": testutil.ShardsRange(0, 29, shard.Available),
+	})
+
+	var (
+		sg0       = newTestSerieses(1, 5)
+		startTime = time.Now().Add(-time.Hour).Truncate(time.Hour)
+		endTime   = time.Now().Truncate(time.Hour)
+		numPoints = 100
+	)
+	sg0.addDatapoints(numPoints, startTime, endTime)
+
+	// Encode the responses with a codec other than the default, the hosts
+	// report it on the segments returned for the namespace.
+	th := newTestFetchTaggedHelper(t)
+	codecPools, err := encoding.NewCodecRegistry(nil, nil, dod.NewCodec()).
+		Pools(codec.DeltaOfDelta)
+	require.NoError(t, err)
+	th.encPool = codecPools.EncoderPool
+
+	response := sg0.toRPCResult(th, startTime, true)
+	codecID := int32(codec.DeltaOfDelta)
+	for _, elem := range response.Elements {
+		for _, segments := range elem.Segments {
+			segments.CodecID = &codecID
+		}
+	}
+
+	workflow := testFetchTaggedWorkflow{
+		t:         t,
+		topoMap:   topoMap,
+		level:     topology.ReadConsistencyLevelOne,
+		startTime: startTime,
+		endTime:   endTime,
+		steps: []testFetchTaggedWorklowStep{
+			testFetchTaggedWorklowStep{
+				hostname:     "testhost0",
+				response:     response,
+				expectedDone: true,
+			},
+		},
+	}
+	accum := workflow.run()
+
+	iters, exhaust, err := accum.AsEncodingSeriesIterators(10, th.pools)
+	require.NoError(t, err)
+	require.True(t, exhaust)
+	for _, iter := range iters.Iters() {
+		require.Equal(t, codec.DeltaOfDelta, iter.CodecID())
+	}
+	sg0.assertMatchesEncodingIters(t, iters)
+}
+
 type testFetchTaggedWorkflow struct {
 	t         *testing.T
 	topoMap   topology.Map
@@ -30,6 +30,8 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
+	"github.com/m3db/m3/src/dbnode/encoding/dod"
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/x/xpool"
@@ -272,6 +274,9 @@ func initTestFetchTaggedPools() *testFetchTaggedPools {
 		return m3tsz.NewReaderIterator(r, m3tsz.DefaultIntOptimizationEnabled, encoding.NewOptions())
 	})
 
+	pools.codecRegistry = encoding.NewCodecRegistry(nil, opts,
+		m3tsz.NewCodec(), m3tsz.NewXORCodec(), dod.NewCodec())
+
 	pools.seriesIter = encoding.NewSeriesIteratorPool(opts)
 	pools.seriesIter.Init()
 
@@ -302,6 +307,7 @@ var _ fetchTaggedPools = testFetchTaggedPools{}
 type testFetchTaggedPools struct {
 	readerSlices             *readerSliceOfSlicesIteratorPool
 	multiReader              encoding.MultiReaderIteratorPool
+	codecRegistry            encoding.CodecRegistry
 	seriesIter               encoding.SeriesIteratorPool
 	mutableSeriesIter        encoding.MutableSeriesIteratorsPool
 	multiReaderIteratorArray encoding.MultiReaderIteratorArrayPool
@@ -318,6 +324,19 @@ func (p testFetchTaggedPools) MultiReaderIterator() encoding.MultiReaderIterator
 	return p.multiReader
 }
 
+func (p testFetchTaggedPools) MultiReaderIteratorForCodec(
+	id codec.ID,
+) (encoding.MultiReaderIteratorPool, error) {
+	if id == codec.DefaultID {
+		return p.multiReader, nil
+	}
+	pools, err := p.codecRegistry.Pools(id)
+	if err != nil {
+		return nil, err
+	}
+	return pools.MultiReaderIteratorPool, nil
+}
+
 func (p testFetchTaggedPools) SeriesIterator() encoding.SeriesIteratorPool {
 	return p.seriesIter
 }
@@ -35,6 +35,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/digest"
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/convert"
 	"github.com/m3db/m3/src/dbnode/runtime"
@@ -241,8 +242,9 @@ func newSession(opts Options) (clientSession, error) {
 		writeRetrier:         opts.WriteRetrier(),
 		fetchRetrier:         opts.FetchRetrier(),
 		pools: sessionPools{
-			context: opts.ContextPool(),
-			id:      opts.IdentifierPool(),
+			context:       opts.ContextPool(),
+			id:            opts.IdentifierPool(),
+			codecRegistry: opts.CodecRegistry(),
 		},
 		metrics: newSessionMetrics(scope),
 	}
@@ -1059,9 +1061,20 @@ func (s *session) Fetch(
 	namespace ident.ID,
 	id ident.ID,
 	startInclusive, endExclusive time.Time,
+) (encoding.SeriesIterator, error) {
+	return s.FetchWithOptions(namespace, id, startInclusive, endExclusive,
+		FetchOptions{})
+}
+
+func (s *session) FetchWithOptions(
+	namespace ident.ID,
+	id ident.ID,
+	startInclusive, endExclusive time.Time,
+	opts FetchOptions,
 ) (encoding.SeriesIterator, error) {
 	tsIDs := ident.NewIDsIterator(id)
-	results, err := s.FetchIDs(namespace, tsIDs, startInclusive, endExclusive)
+	results, err := s.FetchIDsWithOptions(namespace, tsIDs,
+		startInclusive, endExclusive, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -1079,10 +1092,30 @@ func (s *session) FetchIDs(
 	ids ident.Iterator,
 	startInclusive, endExclusive time.Time,
 ) (encoding.SeriesIterators, error) {
+	return s.FetchIDsWithOptions(namespace, ids, startInclusive, endExclusive,
+		FetchOptions{})
+}
+
+func (s *session) FetchIDsWithOptions(
+	namespace ident.ID,
+	ids ident.Iterator,
+	startInclusive, endExclusive time.Time,
+	opts FetchOptions,
+) (encoding.SeriesIterators, error) {
+	// NB: Convert the downsample options once up front rather than on
+	// every attempt since retrying would not make them any more valid.
+	resolutionNanos, aggregationType, err := convert.ToRPCDownsampleOptions(
+		opts.Downsample)
+	if err != nil {
+		return nil, err
+	}
+
 	f := s.pools.fetchAttempt.Get()
 	f.args.namespace, f.args.ids = namespace, ids
 	f.args.start, f.args.end = startInclusive, endExclusive
-	err := s.fetchRetrier.Attempt(f.attemptFn)
+	f.args.downsampleResolutionNanos = resolutionNanos
+	f.args.downsampleAggregationType = aggregationType
+	err = s.fetchRetrier.Attempt(f.attemptFn)
 	result := f.result
 	s.pools.fetchAttempt.Put(f)
 	return result, err
@@ -1180,6 +1213,121 @@ func (s *session) fetchTaggedIDsAttempt(
 	return iter, exhaustive, err
 }
 
+func (s *session) Aggregate(
+	ns ident.ID, q index.Query, opts index.AggregationOptions,
+) (index.AggregateResults, bool, error) {
+	var (
+		results    index.AggregateResults
+		exhaustive bool
+	)
+	err := s.fetchRetrier.Attempt(func() error {
+		var err error
+		results, exhaustive, err = s.aggregateAttempt(ns, q, opts)
+		return err
+	})
+	return results, exhaustive, err
+}
+
+// aggregateAttempt enqueues the aggregate to every host and merges the
+// responses, it succeeds as long as every shard is owned by at least one
+// host that responded successfully.
+func (s *session) aggregateAttempt(
+	ns ident.ID, q index.Query, opts index.AggregationOptions,
+) (index.AggregateResults, bool, error) {
+	req, err := convert.ToRPCAggregateQueryRawRequest(ns, q, opts)
+	if err != nil {
+		return nil, false, xerrors.NewNonRetryableError(err)
+	}
+
+	var (
+		wg         sync.WaitGroup
+		enqueueErr xerrors.MultiError
+		resultLock sync.Mutex
+		resultErr  xerrors.MultiError
+		responded  = make(map[uint32]struct{})
+		results    = index.NewAggregateResults(ns, opts.Type)
+		exhaustive = true
+	)
+
+	s.state.RLock()
+	if s.state.status != statusOpen {
+		s.state.RUnlock()
+		return nil, false, errSessionStatusNotOpen
+	}
+
+	topoMap := s.state.topoMap
+	for _, hq := range s.state.queues {
+		host := hq.Host()
+		op := &aggregateOp{request: req}
+		op.completionFn = func(result interface{}, err error) {
+			resultLock.Lock()
+			defer func() {
+				resultLock.Unlock()
+				wg.Done()
+			}()
+
+			if err != nil {
+				resultErr = resultErr.Add(err)
+				return
+			}
+
+			if hostShardSet, ok := topoMap.LookupHostShardSet(host.ID()); ok {
+				for _, shard := range hostShardSet.ShardSet().AllIDs() {
+					responded[shard] = struct{}{}
+				}
+			}
+
+			res := result.(*rpc.AggregateQueryRawResult_)
+			if !res.Exhaustive {
+				exhaustive = false
+			}
+			for _, elem := range res.Results {
+				if opts.LimitExceeded(results.Size()) {
+					exhaustive = false
+					return
+				}
+				if opts.Type == index.AggregateTagNames || len(elem.TagValues) == 0 {
+					results.AddField(elem.TagName)
+					continue
+				}
+				for _, value := range elem.TagValues {
+					if opts.LimitExceeded(results.Size()) {
+						exhaustive = false
+						return
+					}
+					results.AddFieldAndTerm(elem.TagName, value.TagValue)
+				}
+			}
+		}
+
+		wg.Add(1)
+		if err := hq.Enqueue(op); err != nil {
+			wg.Done()
+			enqueueErr = enqueueErr.Add(err)
+		}
+	}
+	s.state.RUnlock()
+
+	if err := enqueueErr.FinalError(); err != nil {
+		s.log.Errorf("failed to enqueue request: %v", err)
+		return nil, false, err
+	}
+
+	wg.Wait()
+
+	for _, shard := range topoMap.ShardSet().AllIDs() {
+		if _, ok := responded[shard]; ok {
+			continue
+		}
+		if err := resultErr.FinalError(); err != nil {
+			return nil, false, err
+		}
+		return nil, false, fmt.Errorf("no response for shard %d", shard)
+	}
+
+	return results, exhaustive, nil
+}
+
 // NB(prateek): the returned fetchState, if valid, still holds the lock. Its ownership
 // is transferred to the calling function, and is expected to manage the lifecycle of
 // of the object (including releasing the lock/decRef'ing it).
@@ -1244,6 +1392,8 @@ func (s *session) fetchIDsAttempt(
 	inputNamespace ident.ID,
 	inputIDs ident.Iterator,
 	startInclusive, endExclusive time.Time,
+	downsampleResolutionNanos *int64,
+	downsampleAggregationType rpc.DownsampleAggregationType,
 ) (encoding.SeriesIterators, error) {
 	var (
 		wg                     sync.WaitGroup
@@ -1327,6 +1477,7 @@ func (s *session) fetchIDsAttempt(
 			idAccessors      int32 = 1
 			resultsLock      sync.RWMutex
 			results          []encoding.MultiReaderIterator
+			codecID          codec.ID
 			enqueued         int32
 			pending          int32
 			success          int32
@@ -1361,6 +1512,7 @@ func (s *session) fetchIDsAttempt(
 			} else {
 				resultsLock.RLock()
 				successIters := results[:success]
+				successCodecID := codecID
 				resultsLock.RUnlock()
 				iter := s.pools.seriesIterator.Get()
 				// NB(prateek): we need to allocate a copy of ident.ID to allow the seriesIterator
@@ -1375,6 +1527,7 @@ func (s *session) fetchIDsAttempt(
 					StartInclusive: startInclusive,
 					EndExclusive:   endExclusive,
 					Replicas:       successIters,
+					CodecID:        successCodecID,
 				})
 				iters.SetAt(idx, iter)
 			}
@@ -1390,7 +1543,19 @@ func (s *session) fetchIDsAttempt(
 			wg.Done()
 		}
 		completionFn := func(result interface{}, err error) {
-			var snapshotSuccess int32
+			var (
+				snapshotSuccess int32
+				segments        []*rpc.Segments
+				segmentsCodecID codec.ID
+				multiIterPool   encoding.MultiReaderIteratorPool
+			)
+			if err == nil {
+				// NB: the server reports the codec the namespace is encoded
+				// with so the segments must be decoded with the same codec.
+				segments = result.([]*rpc.Segments)
+				segmentsCodecID = codecIDFromSegments(segments)
+				multiIterPool, err = s.pools.MultiReaderIteratorForCodec(segmentsCodecID)
+			}
 			if err != nil {
 				atomic.AddInt32(&errs, 1)
 				// NB(r): reuse the error lock here as we do not want to create
@@ -1403,12 +1568,16 @@ func (s *session) fetchIDsAttempt(
 				resultErrLock.Unlock()
 			} else {
 				slicesIter := s.pools.readerSliceOfSlicesIterator.Get()
-				slicesIter.Reset(result.([]*rpc.Segments))
-				multiIter := s.pools.multiReaderIterator.Get()
+				slicesIter.Reset(segments)
+				multiIter := multiIterPool.Get()
 				multiIter.ResetSliceOfSlices(slicesIter)
 				// Results is pre-allocated after creating fetch ops for this ID below
 				resultsLock.Lock()
 				results[success] = multiIter
+				if segmentsCodecID != codec.DefaultID {
+					// NB: replicas without data do not report a codec.
+					codecID = segmentsCodecID
+				}
 				success++
 				snapshotSuccess = success
 				resultsLock.Unlock()
@@ -1461,6 +1630,8 @@ func (s *session) fetchIDsAttempt(
 				f.request.RangeStart = rangeStart
 				f.request.RangeEnd = rangeEnd
 				f.request.RangeTimeType = rpc.TimeType_UNIX_NANOSECONDS
+				f.request.DownsampleResolutionNanos = downsampleResolutionNanos
+				f.request.DownsampleAggregationType = downsampleAggregationType
 			}
 
 			// Append IDWithNamespace to this request
@@ -1648,6 +1819,79 @@ func (s *session) Truncate(namespace ident.ID) (int64, error) {
 	return truncated, resultErr.FinalError()
 }
 
+func (s *session) DeleteSeries(namespace ident.ID, ids ident.Iterator) (int64, error) {
+	d := &deleteSeriesOp{}
+	d.request.NameSpace = namespace.Bytes()
+	for ids.Next() {
+		// Copy the ID as the iterator may reuse the underlying bytes.
+		id := ids.Current().Bytes()
+		d.request.Ids = append(d.request.Ids, append([]byte(nil), id...))
+	}
+	if err := ids.Err(); err != nil {
+		return 0, err
+	}
+
+	return s.deleteFromAllHosts(d, &d.completionFn)
+}
+
+func (s *session) DeleteTagged(namespace ident.ID, q index.Query) (int64, error) {
+	req, err := convert.ToRPCDeleteTaggedRequest(namespace, q)
+	if err != nil {
+		return 0, xerrors.NewNonRetryableError(err)
+	}
+
+	d := &deleteTaggedOp{request: req}
+	return s.deleteFromAllHosts(d, &d.completionFn)
+}
+
+// deleteFromAllHosts enqueues the delete op to every host, as each host only
+// deletes the series belonging to shards it owns, and counts the distinct
+// series deleted by any of them since each series is deleted by every
+// replica of its shard.
+func (s *session) deleteFromAllHosts(d op, completionFnPtr *completionFn) (int64, error) {
+	var (
+		wg         sync.WaitGroup
+		enqueueErr xerrors.MultiError
+		resultLock sync.Mutex
+		resultErr  xerrors.MultiError
+		deleted    = make(map[string]struct{})
+	)
+
+	*completionFnPtr = func(result interface{}, err error) {
+		resultLock.Lock()
+		if err != nil {
+			resultErr = resultErr.Add(err)
+		} else {
+			res := result.(*rpc.DeleteSeriesResult_)
+			for _, id := range res.Ids {
+				deleted[string(id)] = struct{}{}
+			}
+		}
+		resultLock.Unlock()
+		wg.Done()
+	}
+
+	s.state.RLock()
+	for idx := range s.state.queues {
+		wg.Add(1)
+		if err := s.state.queues[idx].Enqueue(d); err != nil {
+			wg.Done()
+			enqueueErr = enqueueErr.Add(err)
+		}
+	}
+	s.state.RUnlock()
+
+	if err := enqueueErr.FinalError(); err != nil {
+		s.log.Errorf("failed to enqueue request: %v", err)
+		return 0, err
+	}
+
+	// Wait for the series to be deleted on all replicas
+	wg.Wait()
+
+	return int64(len(deleted)), resultErr.FinalError()
+}
+
 // NB(r): Excluding maligned struct check here as we can
 // live with a few extra bytes since this struct is only
 // ever passed by stack, its much more readable not optimized
@@ -1766,6 +2010,7 @@ func (s *session) FetchBootstrapBlocksFromPeers(
 ) (result.ShardResult, error) {
 	var (
 		result = newBulkBlocksResult(s.opts, opts,
+			nsMetadata.Options().RetentionOptions().BlockSize(),
 			s.pools.tagDecoder, s.pools.id)
 		doneCh   = make(chan struct{})
 		progress = s.newPeerMetadataStreamingProgressMetrics(shard,
@@ -1836,7 +2081,8 @@ func (s *session) FetchBlocksFromPeers(
 		complete = int64(0)
 		doneCh   = make(chan error, 1)
 		outputCh = make(chan peerBlocksDatapoint, 4096)
-		result   = newStreamBlocksResult(s.opts, opts, outputCh,
+		result   = newStreamBlocksResult(s.opts, opts,
+			nsMetadata.Options().RetentionOptions().BlockSize(), outputCh,
 			s.pools.tagDecoder.Get(), s.pools.id)
 		onDone = func(err error) {
 			atomic.StoreInt64(&complete, 1)
@@ -2949,6 +3195,7 @@ type blocksResult interface {
 }
 
 type baseBlocksResult struct {
+	blockSize               time.Duration
 	blockOpts               block.Options
 	blockAllocSize          int
 	contextPool             context.Pool
@@ -2959,9 +3206,11 @@ type baseBlocksResult struct {
 func newBaseBlocksResult(
 	opts Options,
 	resultOpts result.Options,
+	blockSize time.Duration,
 ) baseBlocksResult {
 	blockOpts := resultOpts.DatabaseBlockOptions()
 	return baseBlocksResult{
+		blockSize:               blockSize,
 		blockOpts:               blockOpts,
 		blockAllocSize:          blockOpts.DatabaseBlockAllocSize(),
 		contextPool:             opts.ContextPool(),
@@ -3070,6 +3319,84 @@ func (b *baseBlocksResult) newDatabaseBlock(block *rpc.Block) (block.DatabaseBlo
 	return result, nil
 }
 
+// newDatabaseBlocks returns the blocks for a block from a peer, peers return
+// blocks held by compacted filesets as a single block spanning several
+// namespace blocks which is split into namespace sized blocks.
+func (b *baseBlocksResult) newDatabaseBlocks(block *rpc.Block) ([]block.DatabaseBlock, error) {
+	result, err := b.newDatabaseBlock(block)
+	if err != nil {
+		return nil, err
+	}
+	if b.blockSize <= 0 || result.BlockSize() <= b.blockSize {
+		return []block.DatabaseBlock{result}, nil
+	}
+
+	blocks, err := b.splitDatabaseBlock(result)
+	result.Close()
+	return blocks, err
+}
+
+func (b *baseBlocksResult) splitDatabaseBlock(
+	compacted block.DatabaseBlock,
+) ([]block.DatabaseBlock, error) {
+	ctx := b.contextPool.Get()
+	defer ctx.Close()
+
+	reader, err := compacted.Stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if reader.IsEmpty() {
+		return nil, nil
+	}
+
+	iter := b.multiReaderIteratorPool.Get()
+	iter.Reset([]xio.SegmentReader{reader.SegmentReader},
+		reader.Start, reader.BlockSize)
+	defer iter.Close()
+
+	var (
+		blocks       []block.DatabaseBlock
+		encoder      encoding.Encoder
+		encoderStart time.Time
+	)
+	closeBlocks := func() {
+		if encoder != nil {
+			encoder.Close()
+		}
+		for _, bl := range blocks {
+			bl.Close()
+		}
+	}
+	for iter.Next() {
+		dp, unit, annotation := iter.Current()
+		if start := dp.Timestamp.Truncate(b.blockSize); encoder == nil || !start.Equal(encoderStart) {
+			if encoder != nil {
+				bl := b.blockOpts.DatabaseBlockPool().Get()
+				bl.Reset(encoderStart, b.blockSize, encoder.Discard())
+				blocks = append(blocks, bl)
+			}
+			encoder = b.encoderPool.Get()
+			encoder.Reset(start, b.blockAllocSize)
+			encoderStart = start
+		}
+		if err := encoder.Encode(dp, unit, annotation); err != nil {
+			closeBlocks()
+			return nil, err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		closeBlocks()
+		return nil, err
+	}
+	if encoder != nil {
+		bl := b.blockOpts.DatabaseBlockPool().Get()
+		bl.Reset(encoderStart, b.blockSize, encoder.Discard())
+		blocks = append(blocks, bl)
+	}
+	return blocks, nil
+}
+
 // Ensure streamBlocksResult implements blocksResult
 var _ blocksResult = (*streamBlocksResult)(nil)
 
@@ -3083,12 +3410,13 @@ type streamBlocksResult struct {
 func newStreamBlocksResult(
 	opts Options,
 	resultOpts result.Options,
+	blockSize time.Duration,
 	outputCh chan<- peerBlocksDatapoint,
 	tagDecoder serialize.TagDecoder,
 	idPool ident.Pool,
 ) *streamBlocksResult {
 	return &streamBlocksResult{
-		baseBlocksResult: newBaseBlocksResult(opts, resultOpts),
+		baseBlocksResult: newBaseBlocksResult(opts, resultOpts, blockSize),
 		outputCh:         outputCh,
 		tagDecoder:       tagDecoder,
 		idPool:           idPool,
@@ -3108,7 +3436,7 @@ func (s *streamBlocksResult) addBlockFromPeer(
 	peer topology.Host,
 	block *rpc.Block,
 ) error {
-	result, err := s.newDatabaseBlock(block)
+	results, err := s.newDatabaseBlocks(block)
 	if err != nil {
 		return err
 	}
@@ -3117,11 +3445,13 @@ func (s *streamBlocksResult) addBlockFromPeer(
 	if err != nil {
 		return err
 	}
-	s.outputCh <- peerBlocksDatapoint{
-		id:    id,
-		tags:  tags,
-		peer:  peer,
-		block: result,
+	for _, result := range results {
+		s.outputCh <- peerBlocksDatapoint{
+			id:    id,
+			tags:  tags,
+			peer:  peer,
+			block: result,
+		}
 	}
 	return nil
 }
@@ -3182,11 +3512,12 @@ type bulkBlocksResult struct {
 func newBulkBlocksResult(
 	opts Options,
 	resultOpts result.Options,
+	blockSize time.Duration,
 	tagDecoderPool serialize.TagDecoderPool,
 	idPool ident.Pool,
 ) *bulkBlocksResult {
 	return &bulkBlocksResult{
-		baseBlocksResult: newBaseBlocksResult(opts, resultOpts),
+		baseBlocksResult: newBaseBlocksResult(opts, resultOpts, blockSize),
 		result:           result.NewShardResult(4096, resultOpts),
 		tagDecoderPool:   tagDecoderPool,
 		idPool:           idPool,
@@ -3199,8 +3530,7 @@ func (r *bulkBlocksResult) addBlockFromPeer(
 	peer topology.Host,
 	block *rpc.Block,
 ) error {
-	start := time.Unix(0, block.Start)
-	result, err := r.newDatabaseBlock(block)
+	results, err := r.newDatabaseBlocks(block)
 	if err != nil {
 		return err
 	}
@@ -3209,6 +3539,27 @@ func (r *bulkBlocksResult) addBlockFromPeer(
 		tags                ident.Tags
 		attemptedDecodeTags bool
 	)
+	for _, result := range results {
+		tags, attemptedDecodeTags, err = r.addBlock(id, encodedTags, tags,
+			attemptedDecodeTags, result)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *bulkBlocksResult) addBlock(
+	id ident.ID,
+	encodedTags checked.Bytes,
+	tags ident.Tags,
+	attemptedDecodeTags bool,
+	result block.DatabaseBlock,
+) (ident.Tags, bool, error) {
+	var (
+		start = result.StartTime()
+		err   error
+	)
 	for {
 		r.Lock()
 		currBlock, exists := r.result.BlockAt(id, start)
@@ -3227,7 +3578,7 @@ func (r *bulkBlocksResult) addBlockFromPeer(
 				tagDecoder, r.idPool)
 			tagDecoder.Close()
 			if err != nil {
-				return err
+				return nil, false, err
 			}
 			continue
 		}
@@ -3242,7 +3593,7 @@ func (r *bulkBlocksResult) addBlockFromPeer(
 		tmpCtx := r.contextPool.Get()
 		currReader, err := currBlock.Stream(tmpCtx)
 		if err != nil {
-			return err
+			return nil, false, err
 		}
 
 		// If there are no data in the current block, there is no
@@ -3253,10 +3604,10 @@ func (r *bulkBlocksResult) addBlockFromPeer(
 
 		resultReader, err := result.Stream(tmpCtx)
 		if err != nil {
-			return err
+			return nil, false, err
 		}
 		if resultReader.IsEmpty() {
-			return nil
+			return tags, attemptedDecodeTags, nil
 		}
 
 		readers := []xio.SegmentReader{currReader.SegmentReader, resultReader.SegmentReader}
@@ -3265,7 +3616,7 @@ func (r *bulkBlocksResult) addBlockFromPeer(
 		encoder, err := r.mergeReaders(start, blockSize, readers)
 
 		if err != nil {
-			return err
+			return nil, false, err
 		}
 
 		result.Close()
@@ -3276,7 +3627,7 @@ func (r *bulkBlocksResult) addBlockFromPeer(
 		tmpCtx.Close()
 	}
 
-	return nil
+	return tags, attemptedDecodeTags, nil
 }
 
 type enqueueCh struct {
@@ -0,0 +1,100 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/m3ninx/idx"
+	"github.com/m3db/m3x/ident"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionAggregate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSessionTestOptions()
+	s, err := newSession(opts)
+	assert.NoError(t, err)
+	session := s.(*session)
+
+	q, err := idx.NewRegexpQuery([]byte("foo"), []byte("b.*"))
+	require.NoError(t, err)
+	query, err := idx.Marshal(q)
+	require.NoError(t, err)
+
+	mockHostQueues(ctrl, session, sessionTestReplicas, []testEnqueueFn{
+		func(idx int, op op) {
+			agg, ok := op.(*aggregateOp)
+			assert.True(t, ok)
+			assert.Equal(t, []byte("metrics"), agg.request.NameSpace)
+			assert.Equal(t, query, agg.request.Query)
+			assert.Equal(t, [][]byte{[]byte("foo")}, agg.request.TagNameFilter)
+
+			if idx == 0 {
+				// A single failed replica is tolerated.
+				agg.completionFn(nil, fmt.Errorf("random err"))
+				return
+			}
+
+			value := fmt.Sprintf("bar%d", idx)
+			agg.completionFn(&rpc.AggregateQueryRawResult_{
+				Results: []*rpc.AggregateQueryRawResultTagNameElement{
+					{
+						TagName: []byte("foo"),
+						TagValues: []*rpc.AggregateQueryRawResultTagValueElement{
+							{TagValue: []byte("baz")},
+							{TagValue: []byte(value)},
+						},
+					},
+				},
+				Exhaustive: true,
+			}, nil)
+		},
+	})
+
+	assert.NoError(t, session.Open())
+
+	results, exhaustive, err := s.Aggregate(ident.StringID("metrics"),
+		index.Query{Query: q}, index.AggregationOptions{
+			FieldFilter: index.AggregateFieldFilter{[]byte("foo")},
+		})
+	require.NoError(t, err)
+	assert.True(t, exhaustive)
+
+	var expectedTerms [][]byte
+	for i := 1; i < sessionTestReplicas; i++ {
+		expectedTerms = append(expectedTerms, []byte(fmt.Sprintf("bar%d", i)))
+	}
+	expectedTerms = append(expectedTerms, []byte("baz"))
+	assert.Equal(t, []index.AggregateField{
+		{Name: []byte("foo"), Terms: expectedTerms},
+	}, results.Fields())
+
+	assert.NoError(t, session.Close())
+}
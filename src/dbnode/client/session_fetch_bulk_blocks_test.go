@@ -1357,7 +1357,7 @@ func TestStreamBlocksBatchFromPeerVerifiesBlockErr(t *testing.T) {
 	// Attempt stream blocks
 	bopts := result.NewOptions()
 	m := session.newPeerMetadataStreamingProgressMetrics(0, resultTypeRaw)
-	r := newBulkBlocksResult(opts, bopts, session.pools.tagDecoder, session.pools.id)
+	r := newBulkBlocksResult(opts, bopts, blockSize, session.pools.tagDecoder, session.pools.id)
 	session.streamBlocksBatchFromPeer(testsNsMetadata(t), 0, peer, batch, bopts, r, enqueueCh, retrier, m)
 
 	// Assert result
@@ -1510,7 +1510,7 @@ func TestStreamBlocksBatchFromPeerVerifiesBlockChecksum(t *testing.T) {
 	// Attempt stream blocks
 	bopts := result.NewOptions()
 	m := session.newPeerMetadataStreamingProgressMetrics(0, resultTypeRaw)
-	r := newBulkBlocksResult(opts, bopts, session.pools.tagDecoder, session.pools.id)
+	r := newBulkBlocksResult(opts, bopts, blockSize, session.pools.tagDecoder, session.pools.id)
 	session.streamBlocksBatchFromPeer(testsNsMetadata(t), 0, peer, batch, bopts, r, enqueueCh, retrier, m)
 
 	// Assert enqueueChannel contents (bad bar block)
@@ -1552,7 +1552,7 @@ func TestBlocksResultAddBlockFromPeerReadMerged(t *testing.T) {
 		}},
 	}
 
-	r := newBulkBlocksResult(opts, bopts,
+	r := newBulkBlocksResult(opts, bopts, blockSize,
 		testTagDecodingPool, testIDPool)
 	r.addBlockFromPeer(fooID, fooTags, testHost, bl)
 
@@ -1639,7 +1639,7 @@ func TestBlocksResultAddBlockFromPeerReadUnmerged(t *testing.T) {
 		bl.Segments.Unmerged = append(bl.Segments.Unmerged, seg)
 	}
 
-	r := newBulkBlocksResult(opts, bopts, testTagDecodingPool, testIDPool)
+	r := newBulkBlocksResult(opts, bopts, blockSize, testTagDecodingPool, testIDPool)
 	r.addBlockFromPeer(fooID, fooTags, testHost, bl)
 
 	series := r.result.AllSeries()
@@ -1680,10 +1680,89 @@ func TestBlocksResultAddBlockFromPeerReadUnmerged(t *testing.T) {
 
 // TODO: add test TestBlocksResultAddBlockFromPeerMergeExistingResult
 
+func TestBlocksResultAddBlockFromPeerSplitsCompactedBlock(t *testing.T) {
+	eops := encoding.NewOptions()
+	intopt := true
+
+	encoderPool := encoding.NewEncoderPool(nil)
+	encoderPool.Init(func() encoding.Encoder {
+		return m3tsz.NewEncoder(time.Time{}, nil, intopt, eops)
+	})
+
+	opts := newSessionTestAdminOptions()
+	bopts := result.NewOptions()
+	bopts = bopts.SetDatabaseBlockOptions(bopts.DatabaseBlockOptions().
+		SetEncoderPool(encoderPool).
+		SetMultiReaderIteratorPool(newSessionTestMultiReaderIteratorPool()))
+
+	start := time.Now().Truncate(blockSize)
+	vals := []testValue{
+		{1.0, start, xtime.Second, nil},
+		{2.0, start.Add(time.Minute), xtime.Second, nil},
+		{3.0, start.Add(2 * blockSize), xtime.Second, nil},
+	}
+
+	encoder := encoderPool.Get()
+	encoder.Reset(start, 0)
+	for _, val := range vals {
+		dp := ts.Datapoint{Timestamp: val.t, Value: val.value}
+		require.NoError(t, encoder.Encode(dp, val.unit, val.annotation))
+	}
+	seg := encoder.Discard()
+
+	compactedBlockSize := int64(3 * blockSize)
+	bl := &rpc.Block{
+		Start: start.UnixNano(),
+		Segments: &rpc.Segments{Merged: &rpc.Segment{
+			Head:      seg.Head.Bytes(),
+			Tail:      seg.Tail.Bytes(),
+			BlockSize: &compactedBlockSize,
+		}},
+	}
+
+	r := newBulkBlocksResult(opts, bopts, blockSize, testTagDecodingPool, testIDPool)
+	require.NoError(t, r.addBlockFromPeer(fooID, fooTags, testHost, bl))
+
+	sl, ok := r.result.AllSeries().Get(fooID)
+	require.True(t, ok)
+	require.Equal(t, 2, sl.Blocks.Len())
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	for _, expected := range []struct {
+		start time.Time
+		vals  []testValue
+	}{
+		{start: start, vals: vals[:2]},
+		{start: start.Add(2 * blockSize), vals: vals[2:]},
+	} {
+		result, ok := sl.Blocks.BlockAt(expected.start)
+		require.True(t, ok)
+		assert.Equal(t, blockSize, result.BlockSize())
+
+		stream, err := result.Stream(ctx)
+		require.NoError(t, err)
+
+		iter := m3tsz.NewReaderIterator(stream, intopt, eops)
+		asserted := 0
+		for iter.Next() {
+			dp, _, _ := iter.Current()
+			require.True(t, asserted < len(expected.vals))
+			assert.True(t, expected.vals[asserted].t.Equal(dp.Timestamp))
+			assert.Equal(t, expected.vals[asserted].value, dp.Value)
+			asserted++
+		}
+		assert.NoError(t, iter.Err())
+		assert.Equal(t, len(expected.vals), asserted)
+		iter.Close()
+	}
+}
+
 func TestBlocksResultAddBlockFromPeerErrorOnNoSegments(t *testing.T) {
 	opts := newSessionTestAdminOptions()
 	bopts := result.NewOptions()
-	r := newBulkBlocksResult(opts, bopts, testTagDecodingPool, testIDPool)
+	r := newBulkBlocksResult(opts, bopts, blockSize, testTagDecodingPool, testIDPool)
 
 	bl := &rpc.Block{Start: time.Now().UnixNano()}
 	err := r.addBlockFromPeer(fooID, fooTags, testHost, bl)
@@ -1694,7 +1773,7 @@ func TestBlocksResultAddBlockFromPeerErrorOnNoSegments(t *testing.T) {
 func TestBlocksResultAddBlockFromPeerErrorOnNoSegmentsData(t *testing.T) {
 	opts := newSessionTestAdminOptions()
 	bopts := result.NewOptions()
-	r := newBulkBlocksResult(opts, bopts, testTagDecodingPool, testIDPool)
+	r := newBulkBlocksResult(opts, bopts, blockSize, testTagDecodingPool, testIDPool)
 
 	bl := &rpc.Block{Start: time.Now().UnixNano(), Segments: &rpc.Segments{}}
 	err := r.addBlockFromPeer(fooID, fooTags, testHost, bl)
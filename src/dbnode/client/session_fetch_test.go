@@ -30,6 +30,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/downsample"
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/topology"
@@ -152,6 +153,81 @@ func TestSessionFetchIDs(t *testing.T) {
 	assert.NoError(t, session.Close())
 }
 
+func TestSessionFetchIDsWithOptionsDownsample(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSessionTestOptions().SetFetchBatchSize(2)
+	s, err := newSession(opts)
+	assert.NoError(t, err)
+	session := s.(*session)
+
+	start := time.Now().Truncate(time.Hour)
+	end := start.Add(2 * time.Hour)
+
+	fetches := testFetches([]testFetch{
+		{"foo", []testValue{
+			{1.0, start.Add(1 * time.Minute), xtime.Second, nil},
+		}},
+		{"bar", []testValue{
+			{2.0, start.Add(1 * time.Minute), xtime.Second, nil},
+		}},
+		{"baz", []testValue{
+			{3.0, start.Add(1 * time.Minute), xtime.Second, nil},
+		}},
+	})
+
+	fetchBatchOps, enqueueWg := prepareTestFetchEnqueues(t, ctrl, session, fetches)
+
+	go func() {
+		// Fulfill fetch ops once enqueued
+		enqueueWg.Wait()
+		fulfillTszFetchBatchOps(t, fetches, *fetchBatchOps, 0)
+	}()
+
+	assert.NoError(t, session.Open())
+
+	results, err := session.FetchIDsWithOptions(ident.StringID(testNamespaceName),
+		fetches.IDsIter(), start, end, FetchOptions{
+			Downsample: downsample.Options{
+				Resolution:  time.Minute,
+				Aggregation: downsample.Max,
+			},
+		})
+	assert.NoError(t, err)
+	assertFetchResults(t, start, end, fetches, results)
+
+	// Every batch must request the values downsampled.
+	require.True(t, len(*fetchBatchOps) > 0)
+	for _, op := range *fetchBatchOps {
+		require.NotNil(t, op.request.DownsampleResolutionNanos)
+		assert.Equal(t, int64(time.Minute), *op.request.DownsampleResolutionNanos)
+		assert.Equal(t, rpc.DownsampleAggregationType_MAX,
+			op.request.DownsampleAggregationType)
+	}
+
+	assert.NoError(t, session.Close())
+}
+
+func TestSessionFetchIDsWithOptionsInvalidDownsample(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSessionTestOptions()
+	s, err := newSession(opts)
+	assert.NoError(t, err)
+
+	_, err = s.FetchIDsWithOptions(ident.StringID(testNamespaceName),
+		ident.NewStringIDsSliceIterator([]string{"foo"}),
+		time.Now().Add(-time.Hour), time.Now(), FetchOptions{
+			Downsample: downsample.Options{
+				Resolution:  time.Minute,
+				Aggregation: downsample.AggregationType(-1),
+			},
+		})
+	assert.Error(t, err)
+}
+
 func TestSessionFetchIDsWithRetries(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
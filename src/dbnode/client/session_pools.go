@@ -22,6 +22,8 @@ package client
 
 import (
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/x/xpool"
 	"github.com/m3db/m3/src/x/serialize"
 	"github.com/m3db/m3x/context"
@@ -42,6 +44,7 @@ type sessionPools struct {
 	tagDecoder                  serialize.TagDecoderPool
 	readerSliceOfSlicesIterator *readerSliceOfSlicesIteratorPool
 	multiReaderIterator         encoding.MultiReaderIteratorPool
+	codecRegistry               encoding.CodecRegistry
 	seriesIterator              encoding.SeriesIteratorPool
 	seriesIterators             encoding.MutableSeriesIteratorsPool
 	writeAttempt                *writeAttemptPool
@@ -82,6 +85,19 @@ func (s sessionPools) MultiReaderIterator() encoding.MultiReaderIteratorPool {
 	return s.multiReaderIterator
 }
 
+func (s sessionPools) MultiReaderIteratorForCodec(
+	id codec.ID,
+) (encoding.MultiReaderIteratorPool, error) {
+	if id == codec.DefaultID {
+		return s.multiReaderIterator, nil
+	}
+	pools, err := s.codecRegistry.Pools(id)
+	if err != nil {
+		return nil, err
+	}
+	return pools.MultiReaderIteratorPool, nil
+}
+
 func (s sessionPools) CheckedBytesWrapper() xpool.CheckedBytesWrapperPool {
 	return s.checkedBytesWrapper
 }
@@ -89,3 +105,14 @@ func (s sessionPools) CheckedBytesWrapper() xpool.CheckedBytesWrapperPool {
 func (s sessionPools) MutableSeriesIterators() encoding.MutableSeriesIteratorsPool {
 	return s.seriesIterators
 }
+
+// codecIDFromSegments returns the codec the segments returned by a host are
+// encoded with, hosts only report the codec when it is not the default.
+func codecIDFromSegments(segments []*rpc.Segments) codec.ID {
+	for _, segs := range segments {
+		if segs != nil && segs.IsSetCodecID() {
+			return codec.ID(segs.GetCodecID())
+		}
+	}
+	return codec.DefaultID
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/downsample"
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage/block"
@@ -72,12 +73,30 @@ type Session interface {
 	// FetchIDs values from the database for a set of IDs
 	FetchIDs(namespace ident.ID, ids ident.Iterator, startInclusive, endExclusive time.Time) (encoding.SeriesIterators, error)
 
+	// FetchWithOptions values from the database for an ID with the given fetch options
+	FetchWithOptions(namespace, id ident.ID, startInclusive, endExclusive time.Time, opts FetchOptions) (encoding.SeriesIterator, error)
+
+	// FetchIDsWithOptions values from the database for a set of IDs with the given fetch options
+	FetchIDsWithOptions(namespace ident.ID, ids ident.Iterator, startInclusive, endExclusive time.Time, opts FetchOptions) (encoding.SeriesIterators, error)
+
 	// FetchTagged resolves the provided query to known IDs, and fetches the data for them.
 	FetchTagged(namespace ident.ID, q index.Query, opts index.QueryOptions) (results encoding.SeriesIterators, exhaustive bool, err error)
 
 	// FetchTaggedIDs resolves the provided query to known IDs.
 	FetchTaggedIDs(namespace ident.ID, q index.Query, opts index.QueryOptions) (iter TaggedIDsIterator, exhaustive bool, err error)
 
+	// Aggregate resolves the provided query to the distinct tag names and,
+	// depending on the aggregation type, tag values of the matching series.
+	Aggregate(namespace ident.ID, q index.Query, opts index.AggregationOptions) (results index.AggregateResults, exhaustive bool, err error)
+
+	// DeleteSeries deletes the series with the given IDs, returning the number
+	// of series deleted summed across all replicas.
+	DeleteSeries(namespace ident.ID, ids ident.Iterator) (int64, error)
+
+	// DeleteTagged deletes the series matching the provided query, returning
+	// the number of series deleted summed across all replicas.
+	DeleteTagged(namespace ident.ID, q index.Query) (int64, error)
+
 	// ShardID returns the given shard for an ID for callers
 	// to easily discern what shard is failing when operations
 	// for given IDs begin failing
@@ -90,6 +109,13 @@ type Session interface {
 	Close() error
 }
 
+// FetchOptions are the options for fetching values by ID.
+type FetchOptions struct {
+	// Downsample, if enabled, downsamples the values to the resolution
+	// using the aggregation on the nodes before they are returned.
+	Downsample downsample.Options
+}
+
 // TaggedIDsIterator iterates over a collection of IDs with associated tags and namespace.
 type TaggedIDsIterator interface {
 	// Next returns whether there are more items in the collection.
@@ -300,6 +326,12 @@ type Options interface {
 	// TruncateRequestTimeout returns the truncateRequestTimeout
 	TruncateRequestTimeout() time.Duration
 
+	// SetDeleteRequestTimeout sets the deleteRequestTimeout
+	SetDeleteRequestTimeout(value time.Duration) Options
+
+	// DeleteRequestTimeout returns the deleteRequestTimeout
+	DeleteRequestTimeout() time.Duration
+
 	// SetBackgroundConnectInterval sets the backgroundConnectInterval
 	SetBackgroundConnectInterval(value time.Duration) Options
 
@@ -473,6 +505,14 @@ type Options interface {
 
 	// ReaderIteratorAllocate returns the readerIteratorAllocate
 	ReaderIteratorAllocate() encoding.ReaderIteratorAllocate
+
+	// SetCodecRegistry sets the registry of codecs used to decode data
+	// fetched from namespaces that are not encoded with the default codec
+	SetCodecRegistry(value encoding.CodecRegistry) Options
+
+	// CodecRegistry returns the registry of codecs used to decode data
+	// fetched from namespaces that are not encoded with the default codec
+	CodecRegistry() encoding.CodecRegistry
 }
 
 // AdminOptions is a set of administration client options
@@ -0,0 +1,108 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package codec contains the identifiers of the compression codecs that
+// series data can be encoded with.
+package codec
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	errCodecUnspecified = errors.New("codec unspecified")
+)
+
+// ID identifies a compression codec, it is persisted alongside encoded data
+// so it must never be reassigned.
+type ID uint8
+
+const (
+	// M3TSZ is the m3tsz codec that encodes values as ints where possible
+	// and otherwise falls back to XOR encoded floats.
+	M3TSZ ID = iota
+	// XOR is the Gorilla-style codec that encodes every value as an XOR
+	// encoded float.
+	XOR
+	// DeltaOfDelta is a codec that encodes integer values as the delta of
+	// their deltas, it is optimised for monotonic counters.
+	DeltaOfDelta
+
+	// DefaultID is the default codec.
+	DefaultID = M3TSZ
+)
+
+// ValidIDs returns the valid codec IDs.
+func ValidIDs() []ID {
+	return []ID{M3TSZ, XOR, DeltaOfDelta}
+}
+
+func (id ID) String() string {
+	switch id {
+	case M3TSZ:
+		return "m3tsz"
+	case XOR:
+		return "xor"
+	case DeltaOfDelta:
+		return "deltaofdelta"
+	}
+	return "unknown"
+}
+
+// Validate validates a codec ID.
+func (id ID) Validate() error {
+	for _, valid := range ValidIDs() {
+		if valid == id {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid codec '%d' valid codecs are: %v",
+		uint8(id), ValidIDs())
+}
+
+// ParseID parses a codec ID from a string.
+func ParseID(str string) (ID, error) {
+	var r ID
+	if str == "" {
+		return r, errCodecUnspecified
+	}
+	for _, valid := range ValidIDs() {
+		if str == valid.String() {
+			return valid, nil
+		}
+	}
+	return r, fmt.Errorf("invalid codec '%s' valid codecs are: %v",
+		str, ValidIDs())
+}
+
+// UnmarshalYAML unmarshals a codec ID into a valid type from string.
+func (id *ID) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	r, err := ParseID(str)
+	if err != nil {
+		return err
+	}
+	*id = r
+	return nil
+}
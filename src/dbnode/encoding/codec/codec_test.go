@@ -0,0 +1,54 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package codec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestParseID(t *testing.T) {
+	for _, id := range ValidIDs() {
+		parsed, err := ParseID(id.String())
+		require.NoError(t, err)
+		assert.Equal(t, id, parsed)
+		assert.NoError(t, id.Validate())
+	}
+
+	_, err := ParseID("")
+	assert.Error(t, err)
+	_, err = ParseID("gzip")
+	assert.Error(t, err)
+	assert.Error(t, ID(255).Validate())
+}
+
+func TestIDUnmarshalYAML(t *testing.T) {
+	var cfg struct {
+		Codec ID `yaml:"codec"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte("codec: deltaofdelta\n"), &cfg))
+	assert.Equal(t, DeltaOfDelta, cfg.Codec)
+
+	assert.Error(t, yaml.Unmarshal([]byte("codec: gzip\n"), &cfg))
+}
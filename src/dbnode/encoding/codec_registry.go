@@ -0,0 +1,114 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encoding
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
+	"github.com/m3db/m3x/pool"
+)
+
+type codecRegistry struct {
+	sync.Mutex
+
+	opts     Options
+	poolOpts pool.ObjectPoolOptions
+	codecs   map[codec.ID]Codec
+	pools    map[codec.ID]CodecPools
+}
+
+// NewCodecRegistry returns a new codec registry for a set of codecs, the
+// encoding options are used to construct the encoders and reader iterators
+// for each codec and the pool options are used to construct their pools.
+func NewCodecRegistry(
+	opts Options,
+	poolOpts pool.ObjectPoolOptions,
+	codecs ...Codec,
+) CodecRegistry {
+	if opts == nil {
+		opts = NewOptions()
+	}
+	if poolOpts == nil {
+		poolOpts = pool.NewObjectPoolOptions()
+	}
+	r := &codecRegistry{
+		opts:     opts,
+		poolOpts: poolOpts,
+		codecs:   make(map[codec.ID]Codec, len(codecs)),
+		pools:    make(map[codec.ID]CodecPools, len(codecs)),
+	}
+	for _, c := range codecs {
+		r.codecs[c.ID()] = c
+	}
+	return r
+}
+
+func (r *codecRegistry) Codec(id codec.ID) (Codec, error) {
+	c, ok := r.codecs[id]
+	if !ok {
+		return nil, fmt.Errorf("codec %v is not registered", id)
+	}
+	return c, nil
+}
+
+func (r *codecRegistry) Pools(id codec.ID) (CodecPools, error) {
+	c, err := r.Codec(id)
+	if err != nil {
+		return CodecPools{}, err
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	if pools, ok := r.pools[id]; ok {
+		return pools, nil
+	}
+
+	var (
+		encoderPool             = NewEncoderPool(r.poolOpts)
+		readerIteratorPool      = NewReaderIteratorPool(r.poolOpts)
+		multiReaderIteratorPool = NewMultiReaderIteratorPool(r.poolOpts)
+		encodingOpts            = r.opts.
+					SetEncoderPool(encoderPool).
+					SetReaderIteratorPool(readerIteratorPool)
+	)
+	encoderPool.Init(func() Encoder {
+		return c.NewEncoder(time.Time{}, nil, encodingOpts)
+	})
+	readerIteratorPool.Init(func(r io.Reader) ReaderIterator {
+		return c.NewReaderIterator(r, encodingOpts)
+	})
+	multiReaderIteratorPool.Init(func(r io.Reader) ReaderIterator {
+		return c.NewReaderIterator(r, encodingOpts)
+	})
+
+	pools := CodecPools{
+		EncoderPool:             encoderPool,
+		ReaderIteratorPool:      readerIteratorPool,
+		MultiReaderIteratorPool: multiReaderIteratorPool,
+	}
+	r.pools[id] = pools
+	return pools, nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encoding
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
+	"github.com/m3db/m3x/pool"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodecRegistryCodec(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	xorCodec := NewMockCodec(ctrl)
+	xorCodec.EXPECT().ID().Return(codec.XOR).AnyTimes()
+
+	registry := NewCodecRegistry(nil, nil, xorCodec)
+
+	c, err := registry.Codec(codec.XOR)
+	require.NoError(t, err)
+	require.Equal(t, xorCodec, c)
+
+	_, err = registry.Codec(codec.DeltaOfDelta)
+	require.Error(t, err)
+	_, err = registry.Pools(codec.DeltaOfDelta)
+	require.Error(t, err)
+}
+
+func TestCodecRegistryPoolsAllocatedOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dodCodec := NewMockCodec(ctrl)
+	dodCodec.EXPECT().ID().Return(codec.DeltaOfDelta).AnyTimes()
+	dodCodec.EXPECT().NewEncoder(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(NewMockEncoder(ctrl)).Times(1)
+	dodCodec.EXPECT().NewReaderIterator(gomock.Any(), gomock.Any()).
+		Return(NewMockReaderIterator(ctrl)).Times(2)
+
+	poolOpts := pool.NewObjectPoolOptions().SetSize(1)
+	registry := NewCodecRegistry(NewOptions(), poolOpts, dodCodec)
+
+	pools, err := registry.Pools(codec.DeltaOfDelta)
+	require.NoError(t, err)
+	require.NotNil(t, pools.EncoderPool)
+	require.NotNil(t, pools.ReaderIteratorPool)
+	require.NotNil(t, pools.MultiReaderIteratorPool)
+
+	// Requesting the pools again returns the same pools without allocating.
+	again, err := registry.Pools(codec.DeltaOfDelta)
+	require.NoError(t, err)
+	require.Equal(t, pools, again)
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package dod implements a codec optimised for counters which encodes
+// integer values as the delta of their deltas, using the same timestamp
+// and marker encoding as m3tsz. Values that are not integers are encoded
+// as XOR encoded floats until the next integer value.
+package dod
+
+import (
+	"math"
+
+	"github.com/m3db/m3/src/dbnode/encoding"
+)
+
+const (
+	opcodeIntMode   = 0x0
+	opcodeFloatMode = 0x1
+
+	// Opcodes for the delta of delta of integer values, the largest
+	// bucket is followed by an opcode to switch to float mode.
+	opcodeZeroDoD          = 0x0
+	opcodeFloatModeSwitch  = 0x1f
+	numFloatModeSwitchBits = 5
+
+	opcodeZeroValueXOR        = 0x0
+	opcodeContainedValueXOR   = 0x2
+	opcodeUncontainedValueXOR = 0x3
+
+	// maxExactInt is the largest integer that can be represented exactly
+	// as a float64, bounding values to this ensures deltas of deltas can
+	// never overflow an int64.
+	maxExactInt = float64(1 << 53)
+)
+
+// dodBucket is a range of deltas of deltas encoded with the same
+// number of value bits.
+type dodBucket struct {
+	opcode        uint64
+	numOpcodeBits int
+	numValueBits  int
+	min           int64
+	max           int64
+}
+
+var dodBuckets = newDoDBuckets([]int{8, 16, 32, 64})
+
+func newDoDBuckets(numValueBits []int) []dodBucket {
+	buckets := make([]dodBucket, 0, len(numValueBits))
+	opcode := uint64(0x2)
+	for i, n := range numValueBits {
+		b := dodBucket{
+			opcode:        opcode,
+			numOpcodeBits: i + 2,
+			numValueBits:  n,
+			min:           math.MinInt64,
+			max:           math.MaxInt64,
+		}
+		if n < 64 {
+			b.min = -(1 << uint(n-1))
+			b.max = (1 << uint(n-1)) - 1
+		}
+		buckets = append(buckets, b)
+		opcode = (opcode | 0x1) << 1
+	}
+	return buckets
+}
+
+// isInt returns whether a value can be encoded as an integer without
+// losing precision.
+func isInt(v float64) bool {
+	if math.IsNaN(v) || math.IsInf(v, 0) || math.Abs(v) > maxExactInt {
+		return false
+	}
+	if v == 0 && math.Signbit(v) {
+		// Negative zero would be decoded as positive zero.
+		return false
+	}
+	return v == math.Trunc(v)
+}
+
+func writeXOR(os encoding.OStream, prevXOR, curXOR uint64) {
+	if curXOR == 0 {
+		os.WriteBits(opcodeZeroValueXOR, 1)
+		return
+	}
+
+	prevLeading, prevTrailing := encoding.LeadingAndTrailingZeros(prevXOR)
+	curLeading, curTrailing := encoding.LeadingAndTrailingZeros(curXOR)
+	if curLeading >= prevLeading && curTrailing >= prevTrailing {
+		os.WriteBits(opcodeContainedValueXOR, 2)
+		os.WriteBits(curXOR>>uint(prevTrailing), 64-prevLeading-prevTrailing)
+		return
+	}
+	os.WriteBits(opcodeUncontainedValueXOR, 2)
+	os.WriteBits(uint64(curLeading), 6)
+	numMeaningfulBits := 64 - curLeading - curTrailing
+	// numMeaningfulBits is at least 1, so we can subtract 1 from it and encode it in 6 bits
+	os.WriteBits(uint64(numMeaningfulBits-1), 6)
+	os.WriteBits(curXOR>>uint(curTrailing), numMeaningfulBits)
+}
@@ -0,0 +1,424 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dod
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/dbnode/x/xio"
+	"github.com/m3db/m3x/checked"
+	xtime "github.com/m3db/m3x/time"
+)
+
+var (
+	errEncoderClosed       = errors.New("encoder is closed")
+	errNoEncodedDatapoints = errors.New("encoder has no encoded datapoints")
+)
+
+type encoder struct {
+	os   encoding.OStream
+	opts encoding.Options
+
+	// internal bookkeeping
+	t  time.Time     // current time
+	dt time.Duration // current time delta
+
+	ant ts.Annotation // current annotation
+	tu  xtime.Unit    // current time unit
+
+	isFloat    bool   // whether values are currently encoded as floats
+	intVal     int64  // current int value
+	intDelta   int64  // current int value delta
+	vb         uint64 // current value as float bits
+	xor        uint64 // current float XOR
+	numEncoded uint32 // number of datapoints written
+
+	closed bool
+}
+
+// NewEncoder creates a new encoder.
+func NewEncoder(
+	start time.Time,
+	bytes checked.Bytes,
+	opts encoding.Options,
+) encoding.Encoder {
+	if opts == nil {
+		opts = encoding.NewOptions()
+	}
+	// NB(r): only perform an initial allocation if there is no pool that
+	// will be used for this encoder.  If a pool is being used alloc when the
+	// `Reset` method is called.
+	initAllocIfEmpty := opts.EncoderPool() == nil
+	return &encoder{
+		os:   encoding.NewOStream(bytes, initAllocIfEmpty, opts.BytesPool()),
+		opts: opts,
+		t:    start,
+		tu:   initialTimeUnit(start, opts.DefaultTimeUnit()),
+	}
+}
+
+func initialTimeUnit(start time.Time, tu xtime.Unit) xtime.Unit {
+	tv, err := tu.Value()
+	if err != nil {
+		return xtime.None
+	}
+	// If we want to use tu as the time unit for start, start must
+	// be a multiple of tu.
+	startInNano := xtime.ToNormalizedTime(start, time.Nanosecond)
+	tvInNano := xtime.ToNormalizedDuration(tv, time.Nanosecond)
+	if startInNano%tvInNano == 0 {
+		return tu
+	}
+	return xtime.None
+}
+
+// Encode encodes the timestamp and the value of a datapoint.
+func (enc *encoder) Encode(dp ts.Datapoint, tu xtime.Unit, ant ts.Annotation) error {
+	if enc.closed {
+		return errEncoderClosed
+	}
+
+	var err error
+	if enc.numEncoded == 0 {
+		err = enc.writeFirstTime(dp.Timestamp, ant, tu)
+	} else {
+		err = enc.writeNextTime(dp.Timestamp, ant, tu)
+	}
+	if err != nil {
+		return err
+	}
+
+	if enc.numEncoded == 0 {
+		enc.writeFirstValue(dp.Value)
+	} else {
+		enc.writeNextValue(dp.Value)
+	}
+	enc.numEncoded++
+	return nil
+}
+
+// shouldWriteAnnotation determines whether we should write ant as an annotation.
+// Returns true if ant is not empty and differs from the existing annotation, false otherwise.
+func (enc *encoder) shouldWriteAnnotation(ant ts.Annotation) bool {
+	numAnnotationBytes := len(ant)
+	if numAnnotationBytes == 0 {
+		return false
+	}
+	if numAnnotationBytes != len(enc.ant) {
+		return true
+	}
+	for i := 0; i < numAnnotationBytes; i++ {
+		if enc.ant[i] != ant[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func (enc *encoder) writeAnnotation(ant ts.Annotation) {
+	if !enc.shouldWriteAnnotation(ant) {
+		return
+	}
+	scheme := enc.opts.MarkerEncodingScheme()
+	encoding.WriteSpecialMarker(enc.os, scheme, scheme.Annotation())
+
+	var buf [binary.MaxVarintLen32]byte
+	// NB: we subtract 1 for possible varint encoding savings
+	annotationLength := binary.PutVarint(buf[:], int64(len(ant)-1))
+	enc.os.WriteBytes(buf[:annotationLength])
+	enc.os.WriteBytes(ant)
+	enc.ant = ant
+}
+
+// writeTimeUnit encodes the time unit and returns true if the time unit has
+// changed, and false otherwise.
+func (enc *encoder) writeTimeUnit(tu xtime.Unit) bool {
+	if !tu.IsValid() || tu == enc.tu {
+		return false
+	}
+	scheme := enc.opts.MarkerEncodingScheme()
+	encoding.WriteSpecialMarker(enc.os, scheme, scheme.TimeUnit())
+	enc.os.WriteByte(byte(tu))
+	enc.tu = tu
+	return true
+}
+
+func (enc *encoder) writeFirstTime(t time.Time, ant ts.Annotation, tu xtime.Unit) error {
+	// NB: always write the first time in nanoseconds because we don't know
+	// if the start time is going to be a multiple of the time unit provided.
+	nt := xtime.ToNormalizedTime(enc.t, time.Nanosecond)
+	enc.os.WriteBits(uint64(nt), 64)
+	return enc.writeNextTime(t, ant, tu)
+}
+
+func (enc *encoder) writeNextTime(t time.Time, ant ts.Annotation, tu xtime.Unit) error {
+	enc.writeAnnotation(ant)
+	tuChanged := enc.writeTimeUnit(tu)
+
+	dt := t.Sub(enc.t)
+	enc.t = t
+	if tuChanged {
+		// NB: if the time unit has changed always normalize the delta of delta
+		// to nanoseconds and reset the time delta, the same as m3tsz.
+		enc.os.WriteBits(uint64(int64(dt-enc.dt)), 64)
+		enc.dt = 0
+		return nil
+	}
+	err := enc.writeTimeDeltaOfDelta(enc.dt, dt, tu)
+	enc.dt = dt
+	return err
+}
+
+func (enc *encoder) writeTimeDeltaOfDelta(prevDelta, curDelta time.Duration, tu xtime.Unit) error {
+	u, err := tu.Value()
+	if err != nil {
+		return err
+	}
+	deltaOfDelta := xtime.ToNormalizedDuration(curDelta-prevDelta, u)
+	tes, exists := enc.opts.TimeEncodingSchemes()[tu]
+	if !exists {
+		return fmt.Errorf("time encoding scheme for time unit %v doesn't exist", tu)
+	}
+
+	if deltaOfDelta == 0 {
+		zeroBucket := tes.ZeroBucket()
+		enc.os.WriteBits(zeroBucket.Opcode(), zeroBucket.NumOpcodeBits())
+		return nil
+	}
+	buckets := tes.Buckets()
+	for i := 0; i < len(buckets); i++ {
+		if deltaOfDelta >= buckets[i].Min() && deltaOfDelta <= buckets[i].Max() {
+			enc.os.WriteBits(buckets[i].Opcode(), buckets[i].NumOpcodeBits())
+			enc.os.WriteBits(uint64(deltaOfDelta), buckets[i].NumValueBits())
+			return nil
+		}
+	}
+	defaultBucket := tes.DefaultBucket()
+	enc.os.WriteBits(defaultBucket.Opcode(), defaultBucket.NumOpcodeBits())
+	enc.os.WriteBits(uint64(deltaOfDelta), defaultBucket.NumValueBits())
+	return nil
+}
+
+func (enc *encoder) writeFirstValue(v float64) {
+	if !isInt(v) {
+		enc.os.WriteBit(opcodeFloatMode)
+		enc.writeFullFloatVal(v)
+		return
+	}
+	enc.os.WriteBit(opcodeIntMode)
+	enc.writeIntVal(int64(v))
+}
+
+func (enc *encoder) writeNextValue(v float64) {
+	if enc.isFloat {
+		if isInt(v) {
+			// Switch back to int mode, the int value and delta are reset
+			// so the first int value is written in full.
+			enc.os.WriteBit(opcodeIntMode)
+			enc.intVal = 0
+			enc.intDelta = 0
+			enc.isFloat = false
+			enc.writeIntVal(int64(v))
+			return
+		}
+		enc.os.WriteBit(opcodeFloatMode)
+		enc.writeFloatXOR(math.Float64bits(v))
+		return
+	}
+
+	if !isInt(v) {
+		enc.os.WriteBits(opcodeFloatModeSwitch, numFloatModeSwitchBits)
+		enc.writeFullFloatVal(v)
+		return
+	}
+	enc.writeIntVal(int64(v))
+}
+
+// writeIntVal writes the delta of the delta of an int value.
+func (enc *encoder) writeIntVal(v int64) {
+	delta := v - enc.intVal
+	deltaOfDelta := delta - enc.intDelta
+	enc.intVal = v
+	enc.intDelta = delta
+
+	if deltaOfDelta == 0 {
+		enc.os.WriteBit(opcodeZeroDoD)
+		return
+	}
+	for _, b := range dodBuckets {
+		if deltaOfDelta >= b.min && deltaOfDelta <= b.max {
+			enc.os.WriteBits(b.opcode, b.numOpcodeBits)
+			enc.os.WriteBits(uint64(deltaOfDelta), b.numValueBits)
+			return
+		}
+	}
+}
+
+func (enc *encoder) writeFullFloatVal(v float64) {
+	val := math.Float64bits(v)
+	enc.vb = val
+	enc.xor = val
+	enc.isFloat = true
+	enc.os.WriteBits(val, 64)
+}
+
+func (enc *encoder) writeFloatXOR(val uint64) {
+	xor := enc.vb ^ val
+	writeXOR(enc.os, enc.xor, xor)
+	enc.xor = xor
+	enc.vb = val
+}
+
+func (enc *encoder) newBuffer(capacity int) checked.Bytes {
+	if bytesPool := enc.opts.BytesPool(); bytesPool != nil {
+		return bytesPool.Get(capacity)
+	}
+	return checked.NewBytes(make([]byte, 0, capacity), nil)
+}
+
+func (enc *encoder) Reset(start time.Time, capacity int) {
+	enc.reset(start, enc.newBuffer(capacity))
+}
+
+func (enc *encoder) reset(start time.Time, bytes checked.Bytes) {
+	enc.os.Reset(bytes)
+	enc.t = start
+	enc.dt = 0
+	enc.ant = nil
+	enc.tu = initialTimeUnit(start, enc.opts.DefaultTimeUnit())
+	enc.isFloat = false
+	enc.intVal = 0
+	enc.intDelta = 0
+	enc.vb = 0
+	enc.xor = 0
+	enc.numEncoded = 0
+	enc.closed = false
+}
+
+func (enc *encoder) Stream() xio.SegmentReader {
+	segment := enc.segment(false)
+	if segment.Len() == 0 {
+		return nil
+	}
+	if readerPool := enc.opts.SegmentReaderPool(); readerPool != nil {
+		reader := readerPool.Get()
+		reader.Reset(segment)
+		return reader
+	}
+	return xio.NewSegmentReader(segment)
+}
+
+func (enc *encoder) NumEncoded() int {
+	return int(enc.numEncoded)
+}
+
+func (enc *encoder) LastEncoded() (ts.Datapoint, error) {
+	if enc.numEncoded == 0 {
+		return ts.Datapoint{}, errNoEncodedDatapoints
+	}
+
+	result := ts.Datapoint{Timestamp: enc.t}
+	if enc.isFloat {
+		result.Value = math.Float64frombits(enc.vb)
+	} else {
+		result.Value = float64(enc.intVal)
+	}
+	return result, nil
+}
+
+func (enc *encoder) Len() int {
+	return enc.os.Len()
+}
+
+func (enc *encoder) Close() {
+	if enc.closed {
+		return
+	}
+
+	enc.closed = true
+
+	// Ensure to free ref to ostream bytes
+	enc.os.Reset(nil)
+
+	if pool := enc.opts.EncoderPool(); pool != nil {
+		pool.Put(enc)
+	}
+}
+
+func (enc *encoder) Discard() ts.Segment {
+	segment := enc.segment(true)
+
+	// Close the encoder no longer needed
+	enc.Close()
+
+	return segment
+}
+
+func (enc *encoder) DiscardReset(start time.Time, capacity int) ts.Segment {
+	segment := enc.segment(true)
+	enc.Reset(start, capacity)
+	return segment
+}
+
+// segment returns the encoded data with the last byte replaced by a tail
+// containing the end of stream marker, if byRef is true then the encoded
+// bytes are taken from the encoder rather than copied.
+func (enc *encoder) segment(byRef bool) ts.Segment {
+	length := enc.os.Len()
+	if length == 0 {
+		return ts.Segment{}
+	}
+
+	var head checked.Bytes
+	buffer, pos := enc.os.Rawbytes()
+	lastByte := buffer.Bytes()[length-1]
+	if byRef {
+		// Take ref from the ostream
+		head = enc.os.Discard()
+
+		// Resize to crop out last byte
+		head.IncRef()
+		defer head.DecRef()
+
+		head.Resize(length - 1)
+	} else {
+		// Copy into new buffer
+		head = enc.newBuffer(length - 1)
+
+		head.IncRef()
+		defer head.DecRef()
+
+		// Copy up to last byte
+		head.AppendAll(buffer.Bytes()[:length-1])
+	}
+
+	// Take a shared ref to a known good tail
+	scheme := enc.opts.MarkerEncodingScheme()
+	tail := scheme.Tail(lastByte, pos)
+
+	return ts.NewSegment(head, tail, ts.FinalizeHead)
+}
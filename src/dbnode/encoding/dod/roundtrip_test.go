@@ -0,0 +1,167 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dod
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/ts"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+var testStartTime = time.Unix(1427162400, 0)
+
+func TestCounterRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := 0; i < 100; i++ {
+		var (
+			value float64
+			dps   []ts.Datapoint
+		)
+		for j := 0; j < 1000; j++ {
+			value += float64(r.Intn(100))
+			dps = append(dps, ts.Datapoint{
+				Timestamp: testStartTime.Add(time.Duration(j*10) * time.Second),
+				Value:     value,
+			})
+		}
+		validateRoundTrip(t, dps)
+	}
+}
+
+func TestMixedRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := 0; i < 100; i++ {
+		var dps []ts.Datapoint
+		curr := testStartTime
+		for j := 0; j < 1000; j++ {
+			var value float64
+			switch r.Intn(4) {
+			case 0:
+				value = r.Float64() * 1000
+			case 1:
+				value = -1 * float64(r.Int63n(1<<40))
+			default:
+				value = float64(r.Intn(1000))
+			}
+			curr = curr.Add(time.Duration(1+r.Intn(1200)) * time.Second)
+			dps = append(dps, ts.Datapoint{Timestamp: curr, Value: value})
+		}
+		validateRoundTrip(t, dps)
+	}
+}
+
+func TestEdgeValuesRoundTrip(t *testing.T) {
+	vals := []float64{
+		0, maxExactInt, -maxExactInt, 0, maxExactInt + 2, math.Copysign(0, -1),
+		math.MaxFloat64, 1, math.Inf(1), 2, math.Inf(-1), 3, 12.5, 12.5, 4,
+	}
+	dps := make([]ts.Datapoint, 0, len(vals))
+	for i, v := range vals {
+		dps = append(dps, ts.Datapoint{
+			Timestamp: testStartTime.Add(time.Duration(i) * time.Second),
+			Value:     v,
+		})
+	}
+	validateRoundTrip(t, dps)
+}
+
+func TestNaNRoundTrip(t *testing.T) {
+	encoder := NewEncoder(testStartTime, nil, nil)
+	require.NoError(t, encoder.Encode(ts.Datapoint{Timestamp: testStartTime, Value: 1}, xtime.Second, nil))
+	require.NoError(t, encoder.Encode(ts.Datapoint{Timestamp: testStartTime.Add(time.Second), Value: math.NaN()}, xtime.Second, nil))
+	require.NoError(t, encoder.Encode(ts.Datapoint{Timestamp: testStartTime.Add(2 * time.Second), Value: 2}, xtime.Second, nil))
+
+	it := NewReaderIterator(encoder.Stream(), nil)
+	defer it.Close()
+
+	var values []float64
+	for it.Next() {
+		dp, _, _ := it.Current()
+		values = append(values, dp.Value)
+	}
+	require.NoError(t, it.Err())
+	require.Len(t, values, 3)
+	require.Equal(t, 1.0, values[0])
+	require.True(t, math.IsNaN(values[1]))
+	require.Equal(t, 2.0, values[2])
+}
+
+func TestLastEncoded(t *testing.T) {
+	encoder := NewEncoder(testStartTime, nil, nil)
+	_, err := encoder.LastEncoded()
+	require.Error(t, err)
+
+	for i, v := range []float64{10, 12.5, 20} {
+		dp := ts.Datapoint{Timestamp: testStartTime.Add(time.Duration(i) * time.Second), Value: v}
+		require.NoError(t, encoder.Encode(dp, xtime.Second, nil))
+		last, err := encoder.LastEncoded()
+		require.NoError(t, err)
+		require.Equal(t, dp, last)
+	}
+}
+
+func validateRoundTrip(t *testing.T, input []ts.Datapoint) {
+	encoder := NewEncoder(testStartTime, nil, nil)
+	for j, v := range input {
+		var err error
+		if j == 0 {
+			err = encoder.Encode(v, xtime.Millisecond, proto.EncodeVarint(10))
+		} else if j == 10 {
+			err = encoder.Encode(v, xtime.Microsecond, proto.EncodeVarint(60))
+		} else {
+			err = encoder.Encode(v, xtime.Second, nil)
+		}
+		require.NoError(t, err)
+	}
+
+	it := NewReaderIterator(encoder.Stream(), nil)
+	defer it.Close()
+
+	var decompressed []ts.Datapoint
+	j := 0
+	for it.Next() {
+		v, _, a := it.Current()
+		if j == 0 {
+			s, _ := proto.DecodeVarint(a)
+			require.Equal(t, uint64(10), s)
+		} else if j == 10 {
+			s, _ := proto.DecodeVarint(a)
+			require.Equal(t, uint64(60), s)
+		} else {
+			require.Nil(t, a)
+		}
+		decompressed = append(decompressed, v)
+		j++
+	}
+	require.NoError(t, it.Err())
+	require.Equal(t, len(input), len(decompressed))
+	for i := 0; i < len(input); i++ {
+		require.Equal(t, input[i].Timestamp, decompressed[i].Timestamp)
+		require.Equal(t, math.Float64bits(input[i].Value), math.Float64bits(decompressed[i].Value))
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package downsample provides iterators that downsample decoded series to a
+// lower resolution, this allows the data read for long range queries to be
+// reduced before it is sent over the network.
+package downsample
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/ts"
+	xtime "github.com/m3db/m3x/time"
+)
+
+// AggregationType is the aggregation applied to the datapoints of each
+// downsampled window.
+type AggregationType int
+
+const (
+	// Last returns the last value of each window.
+	Last AggregationType = iota
+	// Min returns the smallest value of each window.
+	Min
+	// Max returns the largest value of each window.
+	Max
+	// Sum returns the sum of the values of each window.
+	Sum
+	// Avg returns the mean of the values of each window.
+	Avg
+)
+
+var (
+	errResolutionNegative = errors.New("downsample resolution is negative")
+
+	validAggregationTypes = []AggregationType{
+		Last,
+		Min,
+		Max,
+		Sum,
+		Avg,
+	}
+)
+
+// Validate returns an error if the aggregation type is not valid.
+func (t AggregationType) Validate() error {
+	for _, valid := range validAggregationTypes {
+		if t == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid downsample aggregation type: %d", int(t))
+}
+
+func (t AggregationType) String() string {
+	switch t {
+	case Last:
+		return "last"
+	case Min:
+		return "min"
+	case Max:
+		return "max"
+	case Sum:
+		return "sum"
+	case Avg:
+		return "avg"
+	}
+	return "unknown"
+}
+
+// Options describes how to downsample a series.
+type Options struct {
+	// Resolution is the width of the windows datapoints are downsampled
+	// into, downsampling is disabled when zero.
+	Resolution time.Duration
+	// Aggregation is the aggregation applied to the datapoints of a window.
+	Aggregation AggregationType
+}
+
+// Enabled returns whether the options downsample series at all.
+func (o Options) Enabled() bool {
+	return o.Resolution > 0
+}
+
+// Validate returns an error if the options are not valid.
+func (o Options) Validate() error {
+	if o.Resolution < 0 {
+		return errResolutionNegative
+	}
+	return o.Aggregation.Validate()
+}
+
+type iterator struct {
+	iter  encoding.Iterator
+	start time.Time
+	end   time.Time
+	opts  Options
+
+	curr     ts.Datapoint
+	currUnit xtime.Unit
+
+	next     ts.Datapoint
+	nextUnit xtime.Unit
+	hasNext  bool
+
+	done bool
+	err  error
+}
+
+// NewIterator returns an iterator that downsamples the datapoints of iter
+// between start inclusive and end exclusive into windows of the resolution
+// of the options, aligned to start. Each window yields a single datapoint
+// timestamped at the last datapoint of the window, datapoints outside the
+// range are dropped and annotations are not retained. Closing the returned
+// iterator closes iter.
+func NewIterator(
+	iter encoding.Iterator,
+	start, end time.Time,
+	opts Options,
+) encoding.Iterator {
+	return &iterator{
+		iter:  iter,
+		start: start,
+		end:   end,
+		opts:  opts,
+	}
+}
+
+func (it *iterator) Next() bool {
+	if it.done || it.err != nil {
+		return false
+	}
+	if !it.hasNext && !it.advance() {
+		return false
+	}
+
+	var (
+		windowStart = it.windowStart(it.next.Timestamp)
+		windowEnd   = windowStart.Add(it.opts.Resolution)
+		window      = newWindow(it.opts.Aggregation)
+		timestamp   = it.next.Timestamp
+	)
+	window.add(it.next.Value)
+	it.currUnit = it.nextUnit
+	it.hasNext = false
+	for it.advance() {
+		if !it.next.Timestamp.Before(windowEnd) {
+			break
+		}
+		window.add(it.next.Value)
+		timestamp = it.next.Timestamp
+		it.currUnit = it.nextUnit
+		it.hasNext = false
+	}
+	if it.err != nil {
+		return false
+	}
+
+	// NB: the window is timestamped at its last datapoint rather than its
+	// start so that a value is never returned before all of the datapoints
+	// it aggregates were written, this also means the timestamp is always a
+	// multiple of the unit of the datapoint.
+	it.curr = ts.Datapoint{
+		Timestamp: timestamp,
+		Value:     window.value(),
+	}
+	return true
+}
+
+// advance moves the underlying iterator to the next datapoint in range and
+// holds it as the next datapoint.
+func (it *iterator) advance() bool {
+	for it.iter.Next() {
+		dp, unit, _ := it.iter.Current()
+		if dp.Timestamp.Before(it.start) {
+			continue
+		}
+		if !dp.Timestamp.Before(it.end) {
+			break
+		}
+		it.next, it.nextUnit, it.hasNext = dp, unit, true
+		return true
+	}
+	it.err = it.iter.Err()
+	it.done = true
+	it.hasNext = false
+	return false
+}
+
+func (it *iterator) windowStart(t time.Time) time.Time {
+	elapsed := t.Sub(it.start)
+	return it.start.Add(elapsed - elapsed%it.opts.Resolution)
+}
+
+func (it *iterator) Current() (ts.Datapoint, xtime.Unit, ts.Annotation) {
+	return it.curr, it.currUnit, nil
+}
+
+func (it *iterator) Err() error {
+	return it.err
+}
+
+func (it *iterator) Close() {
+	it.iter.Close()
+}
+
+type window struct {
+	aggregation AggregationType
+	last        float64
+	min         float64
+	max         float64
+	sum         float64
+	count       int
+}
+
+func newWindow(aggregation AggregationType) window {
+	return window{
+		aggregation: aggregation,
+		min:         math.NaN(),
+		max:         math.NaN(),
+	}
+}
+
+func (w *window) add(v float64) {
+	w.last = v
+	// NB: NaN values are only kept by the last aggregation, this matches
+	// how the other aggregations treat missing values.
+	if math.IsNaN(v) {
+		return
+	}
+	if w.count == 0 || v < w.min {
+		w.min = v
+	}
+	if w.count == 0 || v > w.max {
+		w.max = v
+	}
+	w.sum += v
+	w.count++
+}
+
+func (w *window) value() float64 {
+	switch w.aggregation {
+	case Min:
+		return w.min
+	case Max:
+		return w.max
+	case Sum:
+		if w.count == 0 {
+			return math.NaN()
+		}
+		return w.sum
+	case Avg:
+		if w.count == 0 {
+			return math.NaN()
+		}
+		return w.sum / float64(w.count)
+	}
+	return w.last
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package downsample
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/ts"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sliceIterator struct {
+	dps    []ts.Datapoint
+	idx    int
+	closed bool
+}
+
+func newSliceIterator(dps []ts.Datapoint) *sliceIterator {
+	return &sliceIterator{dps: dps, idx: -1}
+}
+
+func (it *sliceIterator) Next() bool {
+	it.idx++
+	return it.idx < len(it.dps)
+}
+
+func (it *sliceIterator) Current() (ts.Datapoint, xtime.Unit, ts.Annotation) {
+	return it.dps[it.idx], xtime.Second, ts.Annotation("annotation")
+}
+
+func (it *sliceIterator) Err() error { return nil }
+func (it *sliceIterator) Close()     { it.closed = true }
+
+func TestOptionsValidate(t *testing.T) {
+	assert.NoError(t, Options{}.Validate())
+	assert.NoError(t, Options{Resolution: time.Minute, Aggregation: Avg}.Validate())
+	assert.Error(t, Options{Resolution: -time.Minute}.Validate())
+	assert.Error(t, Options{Resolution: time.Minute, Aggregation: AggregationType(99)}.Validate())
+	assert.False(t, Options{}.Enabled())
+	assert.True(t, Options{Resolution: time.Minute}.Enabled())
+}
+
+func TestIteratorAggregations(t *testing.T) {
+	start := time.Unix(1000*60, 0)
+	dps := []ts.Datapoint{
+		{Timestamp: start.Add(-time.Second), Value: 100},
+		{Timestamp: start, Value: 1},
+		{Timestamp: start.Add(10 * time.Second), Value: 3},
+		{Timestamp: start.Add(20 * time.Second), Value: 2},
+		{Timestamp: start.Add(70 * time.Second), Value: 4},
+		{Timestamp: start.Add(80 * time.Second), Value: math.NaN()},
+		{Timestamp: start.Add(200 * time.Second), Value: 5},
+		{Timestamp: start.Add(240 * time.Second), Value: 100},
+	}
+	end := start.Add(240 * time.Second)
+
+	tests := []struct {
+		aggregation AggregationType
+		expected    []float64
+	}{
+		{aggregation: Last, expected: []float64{2, math.NaN(), 5}},
+		{aggregation: Min, expected: []float64{1, 4, 5}},
+		{aggregation: Max, expected: []float64{3, 4, 5}},
+		{aggregation: Sum, expected: []float64{6, 4, 5}},
+		{aggregation: Avg, expected: []float64{2, 4, 5}},
+	}
+	expectedTimes := []time.Time{
+		start.Add(20 * time.Second),
+		start.Add(80 * time.Second),
+		start.Add(200 * time.Second),
+	}
+
+	for _, test := range tests {
+		t.Run(test.aggregation.String(), func(t *testing.T) {
+			source := newSliceIterator(dps)
+			iter := NewIterator(source, start, end, Options{
+				Resolution:  time.Minute,
+				Aggregation: test.aggregation,
+			})
+
+			var i int
+			for iter.Next() {
+				require.True(t, i < len(test.expected))
+				dp, unit, annotation := iter.Current()
+				assert.True(t, expectedTimes[i].Equal(dp.Timestamp))
+				if math.IsNaN(test.expected[i]) {
+					assert.True(t, math.IsNaN(dp.Value))
+				} else {
+					assert.Equal(t, test.expected[i], dp.Value)
+				}
+				assert.Equal(t, xtime.Second, unit)
+				assert.Nil(t, annotation)
+				i++
+			}
+			require.NoError(t, iter.Err())
+			assert.Equal(t, len(test.expected), i)
+
+			iter.Close()
+			assert.True(t, source.closed)
+		})
+	}
+}
+
+func TestIteratorUnalignedStartKeepsUnit(t *testing.T) {
+	start := time.Unix(60, int64(500*time.Millisecond))
+	dps := []ts.Datapoint{
+		{Timestamp: time.Unix(61, 0), Value: 1},
+		{Timestamp: time.Unix(62, 0), Value: 2},
+	}
+	iter := NewIterator(newSliceIterator(dps), start, start.Add(time.Hour), Options{
+		Resolution:  time.Minute,
+		Aggregation: Sum,
+	})
+	defer iter.Close()
+
+	require.True(t, iter.Next())
+	dp, unit, _ := iter.Current()
+	assert.True(t, time.Unix(62, 0).Equal(dp.Timestamp))
+	assert.Equal(t, 3.0, dp.Value)
+	assert.Equal(t, xtime.Second, unit)
+	assert.False(t, iter.Next())
+	require.NoError(t, iter.Err())
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package m3tsz
+
+import (
+	"io"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
+	"github.com/m3db/m3x/checked"
+)
+
+type m3tszCodec struct {
+	id           codec.ID
+	intOptimized bool
+}
+
+// NewCodec returns the m3tsz codec.
+func NewCodec() encoding.Codec {
+	return m3tszCodec{id: codec.M3TSZ, intOptimized: DefaultIntOptimizationEnabled}
+}
+
+// NewXORCodec returns a Gorilla-style codec which shares the m3tsz timestamp
+// encoding but encodes every value as an XOR encoded float rather than
+// attempting to encode values as ints.
+func NewXORCodec() encoding.Codec {
+	return m3tszCodec{id: codec.XOR, intOptimized: false}
+}
+
+func (c m3tszCodec) ID() codec.ID {
+	return c.id
+}
+
+func (c m3tszCodec) NewEncoder(
+	start time.Time,
+	bytes checked.Bytes,
+	opts encoding.Options,
+) encoding.Encoder {
+	return NewEncoder(start, bytes, c.intOptimized, opts)
+}
+
+func (c m3tszCodec) NewReaderIterator(
+	reader io.Reader,
+	opts encoding.Options,
+) encoding.ReaderIterator {
+	return NewReaderIterator(reader, c.intOptimized, opts)
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package m3tsz
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
+	"github.com/m3db/m3/src/dbnode/ts"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodecs(t *testing.T) {
+	require.Equal(t, codec.M3TSZ, NewCodec().ID())
+	require.Equal(t, codec.XOR, NewXORCodec().ID())
+
+	opts := encoding.NewOptions()
+	for _, test := range []struct {
+		codec        encoding.Codec
+		intOptimized bool
+	}{
+		{codec: NewCodec(), intOptimized: true},
+		{codec: NewXORCodec(), intOptimized: false},
+	} {
+		encoder := test.codec.NewEncoder(testStartTime, nil, opts)
+		dp := ts.Datapoint{Timestamp: testStartTime.Add(time.Second), Value: 42}
+		require.NoError(t, encoder.Encode(dp, xtime.Second, nil))
+
+		// The stream must be readable by an iterator with the same int optimization.
+		it := NewReaderIterator(encoder.Stream(), test.intOptimized, opts)
+		require.True(t, it.Next())
+		curr, _, _ := it.Current()
+		require.Equal(t, dp, curr)
+		require.False(t, it.Next())
+		require.NoError(t, it.Err())
+		it.Close()
+	}
+}
@@ -23,6 +23,7 @@ package encoding
 import (
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3x/ident"
 	xtime "github.com/m3db/m3x/time"
@@ -36,6 +37,7 @@ type seriesIterator struct {
 	end              time.Time
 	iters            iterators
 	multiReaderIters []MultiReaderIterator
+	codecID          codec.ID
 	err              error
 	firstNext        bool
 	closed           bool
@@ -124,6 +126,10 @@ func (it *seriesIterator) Replicas() []MultiReaderIterator {
 	return it.multiReaderIters
 }
 
+func (it *seriesIterator) CodecID() codec.ID {
+	return it.codecID
+}
+
 func (it *seriesIterator) Reset(opts SeriesIteratorOptions) {
 	it.id = opts.ID
 	it.nsID = opts.Namespace
@@ -132,6 +138,7 @@ func (it *seriesIterator) Reset(opts SeriesIteratorOptions) {
 		it.tags = ident.EmptyTagIterator
 	}
 	it.multiReaderIters = it.multiReaderIters[:0]
+	it.codecID = opts.CodecID
 	it.err = nil
 	it.firstNext = true
 	it.closed = false
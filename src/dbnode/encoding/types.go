@@ -24,6 +24,7 @@ import (
 	"io"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/dbnode/x/xpool"
@@ -190,6 +191,9 @@ type SeriesIterator interface {
 
 	// Replicas exposes the underlying MultiReaderIterator slice for this SeriesIterator
 	Replicas() []MultiReaderIterator
+
+	// CodecID returns the ID of the codec that the replicas are encoded with
+	CodecID() codec.ID
 }
 
 // SeriesIteratorOptions is a set of options for using a series iterator.
@@ -201,6 +205,7 @@ type SeriesIteratorOptions struct {
 	StartInclusive                time.Time
 	EndExclusive                  time.Time
 	IterateEqualTimestampStrategy IterateEqualTimestampStrategy
+	CodecID                       codec.ID
 }
 
 // SeriesIterators is a collection of SeriesIterator that can close all iterators
@@ -238,6 +243,35 @@ type Decoder interface {
 // NewDecoderFn creates a new decoder
 type NewDecoderFn func() Decoder
 
+// Codec encodes and decodes series data with a compression codec.
+type Codec interface {
+	// ID returns the identifier of the codec.
+	ID() codec.ID
+
+	// NewEncoder returns a new encoder for the codec.
+	NewEncoder(start time.Time, bytes checked.Bytes, opts Options) Encoder
+
+	// NewReaderIterator returns a new reader iterator for the codec.
+	NewReaderIterator(reader io.Reader, opts Options) ReaderIterator
+}
+
+// CodecPools are the pools used to encode and decode series data with a codec.
+type CodecPools struct {
+	EncoderPool             EncoderPool
+	ReaderIteratorPool      ReaderIteratorPool
+	MultiReaderIteratorPool MultiReaderIteratorPool
+}
+
+// CodecRegistry is a registry of the codecs that series data can be encoded with.
+type CodecRegistry interface {
+	// Codec returns the codec registered for an ID.
+	Codec(id codec.ID) (Codec, error)
+
+	// Pools returns the pools for the codec registered for an ID, the pools
+	// are allocated the first time they are requested for a codec.
+	Pools(id codec.ID) (CodecPools, error)
+}
+
 // EncoderAllocate allocates an encoder for a pool.
 type EncoderAllocate func() Encoder
 
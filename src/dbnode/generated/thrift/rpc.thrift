@@ -29,7 +29,21 @@ enum TimeType {
 
 enum ErrorType {
 	INTERNAL_ERROR,
-	BAD_REQUEST
+	BAD_REQUEST,
+	RESOURCE_EXHAUSTED
+}
+
+enum DownsampleAggregationType {
+	LAST,
+	MIN,
+	MAX,
+	SUM,
+	AVG
+}
+
+enum AggregateQueryType {
+	AGGREGATE_BY_TAG_NAME_VALUE,
+	AGGREGATE_BY_TAG_NAME
 }
 
 exception Error {
@@ -46,6 +60,7 @@ service Node {
 	QueryResult query(1: QueryRequest req) throws (1: Error err)
 	FetchResult fetch(1: FetchRequest req) throws (1: Error err)
 	FetchTaggedResult fetchTagged(1: FetchTaggedRequest req) throws (1: Error err)
+	AggregateQueryRawResult aggregateRaw(1: AggregateQueryRawRequest req) throws (1: Error err)
 	void write(1: WriteRequest req) throws (1: Error err)
 	void writeTagged(1: WriteTaggedRequest req) throws (1: Error err)
 
@@ -58,6 +73,8 @@ service Node {
 	void writeTaggedBatchRaw(1: WriteTaggedBatchRawRequest req) throws (1: WriteBatchRawErrors err)
 	void repair() throws (1: Error err)
 	TruncateResult truncate(1: TruncateRequest req) throws (1: Error err)
+	DeleteSeriesResult deleteSeries(1: DeleteSeriesRequest req) throws (1: Error err)
+	DeleteSeriesResult deleteTagged(1: DeleteTaggedRequest req) throws (1: Error err)
 
 	// Management endpoints
 	NodeHealthResult health() throws (1: Error err)
@@ -79,6 +96,8 @@ struct FetchRequest {
 	4: required string id
 	5: optional TimeType rangeType = TimeType.UNIX_SECONDS
 	6: optional TimeType resultTimeType = TimeType.UNIX_SECONDS
+	7: optional i64 downsampleResolutionNanos
+	8: optional DownsampleAggregationType downsampleAggregationType = DownsampleAggregationType.LAST
 }
 
 struct FetchResult {
@@ -111,6 +130,8 @@ struct FetchBatchRawRequest {
 	3: required binary nameSpace
 	4: required list<binary> ids
 	5: optional TimeType rangeTimeType = TimeType.UNIX_SECONDS
+	6: optional i64 downsampleResolutionNanos
+	7: optional DownsampleAggregationType downsampleAggregationType = DownsampleAggregationType.LAST
 }
 
 struct FetchBatchRawResult {
@@ -125,6 +146,7 @@ struct FetchRawResult {
 struct Segments {
 	1: optional Segment merged
 	2: optional list<Segment> unmerged
+	3: optional i32 codecID
 }
 
 struct Segment {
@@ -142,6 +164,8 @@ struct FetchTaggedRequest {
 	5: required bool fetchData
 	6: optional i64 limit
 	7: optional TimeType rangeTimeType = TimeType.UNIX_SECONDS
+	8: optional i64 downsampleResolutionNanos
+	9: optional DownsampleAggregationType downsampleAggregationType = DownsampleAggregationType.LAST
 }
 
 struct FetchTaggedResult {
@@ -157,6 +181,31 @@ struct FetchTaggedIDResult {
 	5: optional Error err
 }
 
+struct AggregateQueryRawRequest {
+	1: required binary query
+	2: required i64 rangeStart
+	3: required i64 rangeEnd
+	4: required binary nameSpace
+	5: optional i64 limit
+	6: optional list<binary> tagNameFilter
+	7: optional AggregateQueryType aggregateQueryType = AggregateQueryType.AGGREGATE_BY_TAG_NAME_VALUE
+	8: optional TimeType rangeType = TimeType.UNIX_SECONDS
+}
+
+struct AggregateQueryRawResult {
+	1: required list<AggregateQueryRawResultTagNameElement> results
+	2: required bool exhaustive
+}
+
+struct AggregateQueryRawResultTagNameElement {
+	1: required binary tagName
+	2: optional list<AggregateQueryRawResultTagValueElement> tagValues
+}
+
+struct AggregateQueryRawResultTagValueElement {
+	1: required binary tagValue
+}
+
 struct FetchBlocksRawRequest {
 	1: required binary nameSpace
 	2: required i32 shard
@@ -251,6 +300,21 @@ struct TruncateResult {
 	1: required i64 numSeries
 }
 
+struct DeleteSeriesRequest {
+	1: required binary nameSpace
+	2: required list<binary> ids
+}
+
+struct DeleteTaggedRequest {
+	1: required binary nameSpace
+	2: required binary query
+}
+
+struct DeleteSeriesResult {
+	1: required i64 numSeries
+	2: optional list<binary> ids
+}
+
 struct NodeHealthResult {
 	1: required bool ok
 	2: required string status
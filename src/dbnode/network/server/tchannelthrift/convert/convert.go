@@ -26,9 +26,11 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/digest"
+	"github.com/m3db/m3/src/dbnode/encoding/downsample"
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	tterrors "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/errors"
 	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/dbnode/x/xpool"
 	"github.com/m3db/m3/src/m3ninx/generated/proto/querypb"
@@ -44,6 +46,11 @@ var (
 	errUnknownUnit      = errors.New("unknown unit")
 	errNilTaggedRequest = errors.New("nil write tagged request")
 
+	errUnknownAggregateQueryType = errors.New("unknown aggregate query type")
+
+	errUnknownDownsampleAggregationType = errors.New("unknown downsample aggregation type")
+	errInvalidDownsampleResolution      = errors.New("downsample resolution must be positive")
+
 	timeZero time.Time
 )
 
@@ -112,6 +119,66 @@ func ToTimeType(unit xtime.Unit) (rpc.TimeType, error) {
 	return 0, errUnknownUnit
 }
 
+// FromRPCDownsampleOptions converts the downsample fields of a fetch request
+// into downsample options, downsampling is disabled if no resolution is set.
+func FromRPCDownsampleOptions(
+	resolutionNanos *int64,
+	aggregationType rpc.DownsampleAggregationType,
+) (downsample.Options, error) {
+	if resolutionNanos == nil {
+		return downsample.Options{}, nil
+	}
+	if *resolutionNanos <= 0 {
+		return downsample.Options{}, errInvalidDownsampleResolution
+	}
+	var aggregation downsample.AggregationType
+	switch aggregationType {
+	case rpc.DownsampleAggregationType_LAST:
+		aggregation = downsample.Last
+	case rpc.DownsampleAggregationType_MIN:
+		aggregation = downsample.Min
+	case rpc.DownsampleAggregationType_MAX:
+		aggregation = downsample.Max
+	case rpc.DownsampleAggregationType_SUM:
+		aggregation = downsample.Sum
+	case rpc.DownsampleAggregationType_AVG:
+		aggregation = downsample.Avg
+	default:
+		return downsample.Options{}, errUnknownDownsampleAggregationType
+	}
+	return downsample.Options{
+		Resolution:  time.Duration(*resolutionNanos),
+		Aggregation: aggregation,
+	}, nil
+}
+
+// ToRPCDownsampleOptions converts downsample options into the downsample
+// fields of a fetch request, the resolution is nil if downsampling is disabled.
+func ToRPCDownsampleOptions(
+	opts downsample.Options,
+) (*int64, rpc.DownsampleAggregationType, error) {
+	if !opts.Enabled() {
+		return nil, rpc.DownsampleAggregationType_LAST, nil
+	}
+	var aggregationType rpc.DownsampleAggregationType
+	switch opts.Aggregation {
+	case downsample.Last:
+		aggregationType = rpc.DownsampleAggregationType_LAST
+	case downsample.Min:
+		aggregationType = rpc.DownsampleAggregationType_MIN
+	case downsample.Max:
+		aggregationType = rpc.DownsampleAggregationType_MAX
+	case downsample.Sum:
+		aggregationType = rpc.DownsampleAggregationType_SUM
+	case downsample.Avg:
+		aggregationType = rpc.DownsampleAggregationType_AVG
+	default:
+		return nil, 0, errUnknownDownsampleAggregationType
+	}
+	resolutionNanos := int64(opts.Resolution)
+	return &resolutionNanos, aggregationType, nil
+}
+
 // ToSegmentsResult is the result of a convert to segments call,
 // if the segments were merged then checksum is ptr to the checksum
 // otherwise it is nil.
@@ -187,6 +254,9 @@ func ToRPCError(err error) *rpc.Error {
 	if err == nil {
 		return nil
 	}
+	if limits.IsQueryLimitExceededError(err) {
+		return tterrors.NewResourceExhaustedError(err)
+	}
 	if xerrors.IsInvalidParams(err) {
 		return tterrors.NewBadRequestError(err)
 	}
@@ -224,6 +294,13 @@ func FromRPCFetchTaggedRequest(
 		opts.Limit = int(*l)
 	}
 
+	downsampleOpts, err := FromRPCDownsampleOptions(req.DownsampleResolutionNanos,
+		req.DownsampleAggregationType)
+	if err != nil {
+		return nil, index.Query{}, index.QueryOptions{}, false, err
+	}
+	opts.Downsample = downsampleOpts
+
 	q, err := idx.Unmarshal(req.Query)
 	if err != nil {
 		return nil, index.Query{}, index.QueryOptions{}, false, err
@@ -274,6 +351,156 @@ func ToRPCFetchTaggedRequest(
 		request.Limit = &l
 	}
 
+	resolutionNanos, aggregationType, err := ToRPCDownsampleOptions(opts.Downsample)
+	if err != nil {
+		return rpc.FetchTaggedRequest{}, err
+	}
+	request.DownsampleResolutionNanos = resolutionNanos
+	request.DownsampleAggregationType = aggregationType
+
+	return request, nil
+}
+
+// FromRPCDeleteTaggedRequest converts the rpc request type for DeleteTaggedRequest into corresponding Go API types.
+func FromRPCDeleteTaggedRequest(
+	req *rpc.DeleteTaggedRequest, pools FetchTaggedConversionPools,
+) (ident.ID, index.Query, error) {
+	q, err := idx.Unmarshal(req.Query)
+	if err != nil {
+		return nil, index.Query{}, err
+	}
+
+	var ns ident.ID
+	if pools != nil {
+		nsBytes := pools.CheckedBytesWrapper().Get(req.NameSpace)
+		ns = pools.ID().BinaryID(nsBytes)
+	} else {
+		ns = ident.StringID(string(req.NameSpace))
+	}
+	return ns, index.Query{Query: q}, nil
+}
+
+// ToRPCDeleteTaggedRequest converts the Go `client/` types into rpc request type for DeleteTaggedRequest.
+func ToRPCDeleteTaggedRequest(
+	ns ident.ID,
+	q index.Query,
+) (rpc.DeleteTaggedRequest, error) {
+	query, err := idx.Marshal(q.Query)
+	if err != nil {
+		return rpc.DeleteTaggedRequest{}, err
+	}
+
+	return rpc.DeleteTaggedRequest{
+		NameSpace: ns.Bytes(),
+		Query:     query,
+	}, nil
+}
+
+// FromRPCAggregateQueryRawRequest converts the rpc request type for AggregateQueryRawRequest into corresponding Go API types.
+// An empty query matches all series.
+func FromRPCAggregateQueryRawRequest(
+	req *rpc.AggregateQueryRawRequest, pools FetchTaggedConversionPools,
+) (ident.ID, index.Query, index.AggregationOptions, error) {
+	start, rangeStartErr := ToTime(req.RangeStart, req.RangeType)
+	if rangeStartErr != nil {
+		return nil, index.Query{}, index.AggregationOptions{}, rangeStartErr
+	}
+
+	end, rangeEndErr := ToTime(req.RangeEnd, req.RangeType)
+	if rangeEndErr != nil {
+		return nil, index.Query{}, index.AggregationOptions{}, rangeEndErr
+	}
+
+	opts := index.AggregationOptions{
+		QueryOptions: index.QueryOptions{
+			StartInclusive: start,
+			EndExclusive:   end,
+		},
+		FieldFilter: index.AggregateFieldFilter(req.TagNameFilter),
+	}
+	if l := req.Limit; l != nil {
+		opts.Limit = int(*l)
+	}
+
+	switch req.AggregateQueryType {
+	case rpc.AggregateQueryType_AGGREGATE_BY_TAG_NAME_VALUE:
+		opts.Type = index.AggregateTagNamesAndValues
+	case rpc.AggregateQueryType_AGGREGATE_BY_TAG_NAME:
+		opts.Type = index.AggregateTagNames
+	default:
+		return nil, index.Query{}, index.AggregationOptions{}, errUnknownAggregateQueryType
+	}
+
+	var q idx.Query
+	if len(req.Query) > 0 {
+		var err error
+		q, err = idx.Unmarshal(req.Query)
+		if err != nil {
+			return nil, index.Query{}, index.AggregationOptions{}, err
+		}
+	}
+
+	var ns ident.ID
+	if pools != nil {
+		nsBytes := pools.CheckedBytesWrapper().Get(req.NameSpace)
+		ns = pools.ID().BinaryID(nsBytes)
+	} else {
+		ns = ident.StringID(string(req.NameSpace))
+	}
+	return ns, index.Query{Query: q}, opts, nil
+}
+
+// ToRPCAggregateQueryRawRequest converts the Go `client/` types into rpc request type for AggregateQueryRawRequest.
+func ToRPCAggregateQueryRawRequest(
+	ns ident.ID,
+	q index.Query,
+	opts index.AggregationOptions,
+) (rpc.AggregateQueryRawRequest, error) {
+	rangeStart, tsErr := ToValue(opts.StartInclusive, fetchTaggedTimeType)
+	if tsErr != nil {
+		return rpc.AggregateQueryRawRequest{}, tsErr
+	}
+
+	rangeEnd, tsErr := ToValue(opts.EndExclusive, fetchTaggedTimeType)
+	if tsErr != nil {
+		return rpc.AggregateQueryRawRequest{}, tsErr
+	}
+
+	var query []byte
+	if q.SearchQuery() != nil {
+		var queryErr error
+		query, queryErr = idx.Marshal(q.Query)
+		if queryErr != nil {
+			return rpc.AggregateQueryRawRequest{}, queryErr
+		}
+	}
+
+	request := rpc.AggregateQueryRawRequest{
+		NameSpace:  ns.Bytes(),
+		RangeStart: rangeStart,
+		RangeEnd:   rangeEnd,
+		RangeType:  fetchTaggedTimeType,
+		Query:      query,
+	}
+
+	if opts.Limit > 0 {
+		l := int64(opts.Limit)
+		request.Limit = &l
+	}
+
+	if len(opts.FieldFilter) > 0 {
+		request.TagNameFilter = [][]byte(opts.FieldFilter)
+	}
+
+	switch opts.Type {
+	case index.AggregateTagNamesAndValues:
+		request.AggregateQueryType = rpc.AggregateQueryType_AGGREGATE_BY_TAG_NAME_VALUE
+	case index.AggregateTagNames:
+		request.AggregateQueryType = rpc.AggregateQueryType_AGGREGATE_BY_TAG_NAME
+	default:
+		return rpc.AggregateQueryRawRequest{}, errUnknownAggregateQueryType
+	}
+
 	return request, nil
 }
 
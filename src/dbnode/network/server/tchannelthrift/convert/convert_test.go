@@ -21,15 +21,19 @@
 package convert_test
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/encoding/downsample"
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/convert"
 	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/dbnode/x/xpool"
 	"github.com/m3db/m3/src/m3ninx/idx"
+	xerrors "github.com/m3db/m3x/errors"
 	"github.com/m3db/m3x/ident"
 	"github.com/m3db/m3x/pool"
 
@@ -150,6 +154,161 @@ func TestConvertFetchTaggedRequest(t *testing.T) {
 	}
 }
 
+func TestConvertDeleteTaggedRequest(t *testing.T) {
+	ns := ident.StringID("abc")
+	for _, pools := range []struct {
+		name string
+		pool convert.FetchTaggedConversionPools
+	}{
+		{"nil pools", nil},
+		{"valid pools", newTestPools()},
+	} {
+		t.Run(pools.name, func(t *testing.T) {
+			q, rpcQ := conjunctionQueryATestCase(t)
+
+			req, err := convert.ToRPCDeleteTaggedRequest(ns, index.Query{Query: q})
+			require.NoError(t, err)
+			assert.Equal(t, ns.Bytes(), req.NameSpace)
+			assert.Equal(t, rpcQ, req.Query)
+
+			id, observedQuery, err := convert.FromRPCDeleteTaggedRequest(&req, pools.pool)
+			require.NoError(t, err)
+			require.Equal(t, ns.String(), id.String())
+			require.True(t, index.NewQueryMatcher(index.Query{Query: q}).Matches(observedQuery))
+		})
+	}
+}
+
+func TestConvertFetchTaggedRequestDownsample(t *testing.T) {
+	ns := ident.StringID("abc")
+	q, _ := termQueryTestCase(t)
+	opts := index.QueryOptions{
+		StartInclusive: time.Now().Add(-900 * time.Hour),
+		EndExclusive:   time.Now(),
+		Downsample: downsample.Options{
+			Resolution:  time.Hour,
+			Aggregation: downsample.Avg,
+		},
+	}
+
+	req, err := convert.ToRPCFetchTaggedRequest(ns, index.Query{Query: q}, opts, true)
+	require.NoError(t, err)
+	require.NotNil(t, req.DownsampleResolutionNanos)
+	assert.Equal(t, int64(time.Hour), *req.DownsampleResolutionNanos)
+	assert.Equal(t, rpc.DownsampleAggregationType_AVG, req.DownsampleAggregationType)
+
+	_, _, observedOpts, _, err := convert.FromRPCFetchTaggedRequest(&req, nil)
+	require.NoError(t, err)
+	assert.Equal(t, opts.Downsample, observedOpts.Downsample)
+}
+
+func TestConvertDownsampleOptions(t *testing.T) {
+	opts, err := convert.FromRPCDownsampleOptions(nil, rpc.DownsampleAggregationType_MAX)
+	require.NoError(t, err)
+	assert.False(t, opts.Enabled())
+
+	resolution := int64(time.Minute)
+	opts, err = convert.FromRPCDownsampleOptions(&resolution, rpc.DownsampleAggregationType_MAX)
+	require.NoError(t, err)
+	assert.Equal(t, downsample.Options{Resolution: time.Minute, Aggregation: downsample.Max}, opts)
+
+	resolution = 0
+	_, err = convert.FromRPCDownsampleOptions(&resolution, rpc.DownsampleAggregationType_MAX)
+	assert.Error(t, err)
+
+	resolution = int64(time.Minute)
+	_, err = convert.FromRPCDownsampleOptions(&resolution, rpc.DownsampleAggregationType(42))
+	assert.Error(t, err)
+
+	resolutionNanos, _, err := convert.ToRPCDownsampleOptions(downsample.Options{})
+	require.NoError(t, err)
+	assert.Nil(t, resolutionNanos)
+}
+
+func TestConvertAggregateQueryRawRequest(t *testing.T) {
+	ns := ident.StringID("abc")
+	opts := index.AggregationOptions{
+		QueryOptions: index.QueryOptions{
+			StartInclusive: time.Unix(0, 1000),
+			EndExclusive:   time.Unix(0, 2000),
+			Limit:          10,
+		},
+		FieldFilter: index.AggregateFieldFilter{[]byte("foo")},
+		Type:        index.AggregateTagNames,
+	}
+	for _, pools := range []struct {
+		name string
+		pool convert.FetchTaggedConversionPools
+	}{
+		{"nil pools", nil},
+		{"valid pools", newTestPools()},
+	} {
+		t.Run(pools.name, func(t *testing.T) {
+			q, rpcQ := conjunctionQueryATestCase(t)
+
+			req, err := convert.ToRPCAggregateQueryRawRequest(ns, index.Query{Query: q}, opts)
+			require.NoError(t, err)
+			assert.Equal(t, ns.Bytes(), req.NameSpace)
+			assert.Equal(t, rpcQ, req.Query)
+			assert.Equal(t, int64(10), req.GetLimit())
+			assert.Equal(t, rpc.AggregateQueryType_AGGREGATE_BY_TAG_NAME, req.AggregateQueryType)
+			assert.Equal(t, rpc.TimeType_UNIX_NANOSECONDS, req.RangeType)
+
+			id, observedQuery, observedOpts, err := convert.FromRPCAggregateQueryRawRequest(&req, pools.pool)
+			require.NoError(t, err)
+			require.Equal(t, ns.String(), id.String())
+			require.True(t, index.NewQueryMatcher(index.Query{Query: q}).Matches(observedQuery))
+			require.Equal(t, opts, observedOpts)
+		})
+	}
+}
+
+func TestConvertAggregateQueryRawRequestMatchAll(t *testing.T) {
+	ns := ident.StringID("abc")
+	opts := index.AggregationOptions{
+		QueryOptions: index.QueryOptions{
+			StartInclusive: time.Unix(10, 0),
+			EndExclusive:   time.Unix(20, 0),
+		},
+	}
+
+	req, err := convert.ToRPCAggregateQueryRawRequest(ns, index.Query{}, opts)
+	require.NoError(t, err)
+	require.Nil(t, req.Query)
+	require.False(t, req.IsSetLimit())
+	require.False(t, req.IsSetTagNameFilter())
+
+	_, observedQuery, observedOpts, err := convert.FromRPCAggregateQueryRawRequest(&req, nil)
+	require.NoError(t, err)
+	require.Nil(t, observedQuery.SearchQuery())
+	require.Equal(t, opts, observedOpts)
+
+	// Seconds are the default range type.
+	req = rpc.AggregateQueryRawRequest{
+		NameSpace:  ns.Bytes(),
+		RangeStart: 10,
+		RangeEnd:   20,
+	}
+	_, _, observedOpts, err = convert.FromRPCAggregateQueryRawRequest(&req, nil)
+	require.NoError(t, err)
+	require.Equal(t, opts, observedOpts)
+}
+
+func TestToRPCError(t *testing.T) {
+	require.Nil(t, convert.ToRPCError(nil))
+
+	limitErr := limits.NewQueryLimitExceededError("test", 2, 1)
+	rpcErr := convert.ToRPCError(limitErr)
+	require.Equal(t, rpc.ErrorType_RESOURCE_EXHAUSTED, rpcErr.Type)
+	require.Equal(t, limitErr.Error(), rpcErr.Message)
+
+	rpcErr = convert.ToRPCError(xerrors.NewInvalidParamsError(errors.New("bad")))
+	require.Equal(t, rpc.ErrorType_BAD_REQUEST, rpcErr.Type)
+
+	rpcErr = convert.ToRPCError(errors.New("internal"))
+	require.Equal(t, rpc.ErrorType_INTERNAL_ERROR, rpcErr.Type)
+}
+
 type testPools struct {
 	id      ident.Pool
 	wrapper xpool.CheckedBytesWrapperPool
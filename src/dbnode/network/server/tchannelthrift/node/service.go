@@ -21,13 +21,18 @@
 package node
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/clock"
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
+	"github.com/m3db/m3/src/dbnode/encoding/downsample"
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/convert"
@@ -35,6 +40,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/storage"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/dbnode/x/xpool"
@@ -85,12 +91,15 @@ var (
 type serviceMetrics struct {
 	fetch               instrument.MethodMetrics
 	fetchTagged         instrument.MethodMetrics
+	aggregate           instrument.MethodMetrics
 	write               instrument.MethodMetrics
 	writeTagged         instrument.MethodMetrics
 	fetchBlocks         instrument.MethodMetrics
 	fetchBlocksMetadata instrument.MethodMetrics
 	repair              instrument.MethodMetrics
 	truncate            instrument.MethodMetrics
+	deleteSeries        instrument.MethodMetrics
+	deleteTagged        instrument.MethodMetrics
 	fetchBatchRaw       instrument.BatchMethodMetrics
 	writeBatchRaw       instrument.BatchMethodMetrics
 	writeTaggedBatchRaw instrument.BatchMethodMetrics
@@ -101,12 +110,15 @@ func newServiceMetrics(scope tally.Scope, samplingRate float64) serviceMetrics {
 	return serviceMetrics{
 		fetch:               instrument.NewMethodMetrics(scope, "fetch", samplingRate),
 		fetchTagged:         instrument.NewMethodMetrics(scope, "fetchTagged", samplingRate),
+		aggregate:           instrument.NewMethodMetrics(scope, "aggregate", samplingRate),
 		write:               instrument.NewMethodMetrics(scope, "write", samplingRate),
 		writeTagged:         instrument.NewMethodMetrics(scope, "writeTagged", samplingRate),
 		fetchBlocks:         instrument.NewMethodMetrics(scope, "fetchBlocks", samplingRate),
 		fetchBlocksMetadata: instrument.NewMethodMetrics(scope, "fetchBlocksMetadata", samplingRate),
 		repair:              instrument.NewMethodMetrics(scope, "repair", samplingRate),
 		truncate:            instrument.NewMethodMetrics(scope, "truncate", samplingRate),
+		deleteSeries:        instrument.NewMethodMetrics(scope, "deleteSeries", samplingRate),
+		deleteTagged:        instrument.NewMethodMetrics(scope, "deleteTagged", samplingRate),
 		fetchBatchRaw:       instrument.NewBatchMethodMetrics(scope, "fetchBatchRaw", samplingRate),
 		writeBatchRaw:       instrument.NewBatchMethodMetrics(scope, "writeBatchRaw", samplingRate),
 		writeTaggedBatchRaw: instrument.NewBatchMethodMetrics(scope, "writeTaggedBatchRaw", samplingRate),
@@ -293,6 +305,11 @@ func (s *service) Query(tctx thrift.Context, req *rpc.QueryRequest) (*rpc.QueryR
 	if req.NoData != nil && *req.NoData {
 		fetchData = false
 	}
+	if fetchData {
+		if err := s.checkSeriesFetchedLimit(queryResult.Results.Size()); err != nil {
+			return nil, convert.ToRPCError(err)
+		}
+	}
 	for _, entry := range queryResult.Results.Map().Iter() {
 		elem := &rpc.QueryResultElement{
 			ID:   entry.Key().String(),
@@ -310,7 +327,7 @@ func (s *service) Query(tctx thrift.Context, req *rpc.QueryRequest) (*rpc.QueryR
 		}
 		tsID := entry.Key()
 		datapoints, err := s.readDatapoints(ctx, nsID, tsID, start, end,
-			req.ResultTimeType)
+			downsample.Options{}, req.ResultTimeType)
 		if err != nil {
 			return nil, convert.ToRPCError(err)
 		}
@@ -337,12 +354,19 @@ func (s *service) Fetch(tctx thrift.Context, req *rpc.FetchRequest) (*rpc.FetchR
 		return nil, tterrors.NewBadRequestError(xerrors.FirstError(rangeStartErr, rangeEndErr))
 	}
 
+	downsampleOpts, err := convert.FromRPCDownsampleOptions(
+		req.DownsampleResolutionNanos, req.DownsampleAggregationType)
+	if err != nil {
+		s.metrics.fetch.ReportError(s.nowFn().Sub(callStart))
+		return nil, tterrors.NewBadRequestError(err)
+	}
+
 	tsID := s.pools.id.GetStringID(ctx, req.ID)
 	nsID := s.pools.id.GetStringID(ctx, req.NameSpace)
 
 	// Make datapoints an initialized empty array for JSON serialization as empty array than null
 	datapoints, err := s.readDatapoints(ctx, nsID, tsID, start, end,
-		req.ResultTimeType)
+		downsampleOpts, req.ResultTimeType)
 	if err != nil {
 		s.metrics.fetch.ReportError(s.nowFn().Sub(callStart))
 		return nil, convert.ToRPCError(err)
@@ -356,6 +380,7 @@ func (s *service) readDatapoints(
 	ctx context.Context,
 	nsID, tsID ident.ID,
 	start, end time.Time,
+	downsampleOpts downsample.Options,
 	timeType rpc.TimeType,
 ) ([]*rpc.Datapoint, error) {
 	encoded, err := s.db.ReadEncoded(ctx, nsID, tsID, start, end)
@@ -363,15 +388,25 @@ func (s *service) readDatapoints(
 		return nil, err
 	}
 
+	multiItPool, err := s.multiReaderIteratorPool(nsID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Make datapoints an initialized empty array for JSON serialization as empty array than null
 	datapoints := make([]*rpc.Datapoint, 0)
 
-	multiIt := s.db.Options().MultiReaderIteratorPool().Get()
+	multiIt := multiItPool.Get()
 	multiIt.ResetSliceOfSlices(xio.NewReaderSliceOfSlicesFromBlockReadersIterator(encoded))
-	defer multiIt.Close()
 
-	for multiIt.Next() {
-		dp, _, annotation := multiIt.Current()
+	var iter encoding.Iterator = multiIt
+	if downsampleOpts.Enabled() {
+		iter = downsample.NewIterator(multiIt, start, end, downsampleOpts)
+	}
+	defer iter.Close()
+
+	for iter.Next() {
+		dp, _, annotation := iter.Current()
 
 		timestamp, timestampErr := convert.ToValue(dp.Timestamp, timeType)
 		if timestampErr != nil {
@@ -386,13 +421,84 @@ func (s *service) readDatapoints(
 		datapoints = append(datapoints, datapoint)
 	}
 
-	if err := multiIt.Err(); err != nil {
+	if err := iter.Err(); err != nil {
 		return nil, err
 	}
 
 	return datapoints, nil
 }
 
+// multiReaderIteratorPool returns the pool of iterators that decode the
+// data of a namespace with the codec the namespace is encoded with.
+func (s *service) multiReaderIteratorPool(
+	nsID ident.ID,
+) (encoding.MultiReaderIteratorPool, error) {
+	opts := s.db.Options()
+	ns, ok := s.db.Namespace(nsID)
+	if !ok || ns.Options().CodecID() == codec.DefaultID {
+		return opts.MultiReaderIteratorPool(), nil
+	}
+	blockOpts, err := opts.DatabaseBlockOptions().OptionsForCodec(ns.Options().CodecID())
+	if err != nil {
+		return nil, err
+	}
+	return blockOpts.MultiReaderIteratorPool(), nil
+}
+
+// readerIteratorPool returns the pool of iterators that decode single
+// streams of a namespace with the codec the namespace is encoded with.
+func (s *service) readerIteratorPool(
+	nsID ident.ID,
+) (encoding.ReaderIteratorPool, error) {
+	opts := s.db.Options()
+	ns, ok := s.db.Namespace(nsID)
+	if !ok || ns.Options().CodecID() == codec.DefaultID {
+		return opts.ReaderIteratorPool(), nil
+	}
+	blockOpts, err := opts.DatabaseBlockOptions().OptionsForCodec(ns.Options().CodecID())
+	if err != nil {
+		return nil, err
+	}
+	return blockOpts.ReaderIteratorPool(), nil
+}
+
+// codecID returns the codec the data of a namespace is encoded with.
+func (s *service) codecID(nsID ident.ID) codec.ID {
+	ns, ok := s.db.Namespace(nsID)
+	if !ok {
+		return codec.DefaultID
+	}
+	return ns.Options().CodecID()
+}
+
+// setCodecID marks the segments as encoded with the given codec so that
+// clients can decode them, the field is left unset for the default codec
+// so that responses remain readable by clients that predate it.
+func setCodecID(segments *rpc.Segments, id codec.ID) {
+	if id == codec.DefaultID {
+		return
+	}
+	codecID := int32(id)
+	segments.CodecID = &codecID
+}
+
+// encoderPool returns the pool of encoders that encode data with the
+// codec a namespace is encoded with.
+func (s *service) encoderPool(
+	nsID ident.ID,
+) (encoding.EncoderPool, error) {
+	opts := s.db.Options()
+	ns, ok := s.db.Namespace(nsID)
+	if !ok || ns.Options().CodecID() == codec.DefaultID {
+		return opts.EncoderPool(), nil
+	}
+	blockOpts, err := opts.DatabaseBlockOptions().OptionsForCodec(ns.Options().CodecID())
+	if err != nil {
+		return nil, err
+	}
+	return blockOpts.EncoderPool(), nil
+}
+
 func (s *service) FetchTagged(tctx thrift.Context, req *rpc.FetchTaggedRequest) (*rpc.FetchTaggedResult_, error) {
 	if s.isOverloaded() {
 		s.metrics.overloadRejected.Inc(1)
@@ -410,15 +516,34 @@ func (s *service) FetchTagged(tctx thrift.Context, req *rpc.FetchTaggedRequest)
 	queryResult, err := s.db.QueryIDs(ctx, ns, query, opts)
 	if err != nil {
 		s.metrics.fetchTagged.ReportError(s.nowFn().Sub(callStart))
-		return nil, tterrors.NewInternalError(err)
+		return nil, convert.ToRPCError(err)
 	}
 
 	response := &rpc.FetchTaggedResult_{
 		Exhaustive: queryResult.Exhaustive,
 	}
 	results := queryResult.Results
-	nsID := results.Namespace()
-	tagsIter := ident.NewTagsIterator(ident.Tags{})
+	if fetchData {
+		if err := s.checkSeriesFetchedLimit(results.Size()); err != nil {
+			s.metrics.fetchTagged.ReportError(s.nowFn().Sub(callStart))
+			return nil, convert.ToRPCError(err)
+		}
+	}
+
+	var (
+		nsID       = results.Namespace()
+		codecID    codec.ID
+		readLimits *readLimits
+		tagsIter   = ident.NewTagsIterator(ident.Tags{})
+	)
+	if fetchData {
+		codecID = s.codecID(nsID)
+		readLimits, err = s.newReadLimits(nsID)
+		if err != nil {
+			s.metrics.fetchTagged.ReportError(s.nowFn().Sub(callStart))
+			return nil, tterrors.NewInternalError(err)
+		}
+	}
 	for _, entry := range results.Map().Iter() {
 		tsID := entry.Key()
 		tags := entry.Value()
@@ -440,7 +565,23 @@ func (s *service) FetchTagged(tctx thrift.Context, req *rpc.FetchTaggedRequest)
 		if !fetchData {
 			continue
 		}
-		segments, rpcErr := s.readEncoded(ctx, nsID, tsID, opts.StartInclusive, opts.EndExclusive)
+		var (
+			segments []*rpc.Segments
+			rpcErr   *rpc.Error
+		)
+		if opts.Downsample.Enabled() {
+			segments, rpcErr = s.readDownsampled(ctx, nsID, tsID,
+				opts.StartInclusive, opts.EndExclusive, opts.Downsample, readLimits)
+		} else {
+			segments, rpcErr = s.readEncoded(ctx, nsID, tsID,
+				opts.StartInclusive, opts.EndExclusive, codecID, readLimits)
+		}
+		if tterrors.IsResourceExhaustedError(rpcErr) {
+			// Fail the whole request rather than the single element since
+			// reading any further series would also exceed the limit.
+			s.metrics.fetchTagged.ReportError(s.nowFn().Sub(callStart))
+			return nil, rpcErr
+		}
 		if rpcErr != nil {
 			elem.Err = rpcErr
 			continue
@@ -452,6 +593,50 @@ func (s *service) FetchTagged(tctx thrift.Context, req *rpc.FetchTaggedRequest)
 	return response, nil
 }
 
+func (s *service) AggregateRaw(tctx thrift.Context, req *rpc.AggregateQueryRawRequest) (*rpc.AggregateQueryRawResult_, error) {
+	if s.isOverloaded() {
+		s.metrics.overloadRejected.Inc(1)
+		return nil, tterrors.NewInternalError(errServerIsOverloaded)
+	}
+
+	callStart := s.nowFn()
+	ctx := tchannelthrift.Context(tctx)
+	ns, query, opts, err := convert.FromRPCAggregateQueryRawRequest(req, s.pools)
+	if err != nil {
+		s.metrics.aggregate.ReportError(s.nowFn().Sub(callStart))
+		return nil, tterrors.NewBadRequestError(err)
+	}
+
+	queryResult, err := s.db.AggregateQuery(ctx, ns, query, opts)
+	if err != nil {
+		s.metrics.aggregate.ReportError(s.nowFn().Sub(callStart))
+		return nil, tterrors.NewInternalError(err)
+	}
+
+	fields := queryResult.Results.Fields()
+	response := &rpc.AggregateQueryRawResult_{
+		Results:    make([]*rpc.AggregateQueryRawResultTagNameElement, 0, len(fields)),
+		Exhaustive: queryResult.Exhaustive,
+	}
+	for _, field := range fields {
+		elem := &rpc.AggregateQueryRawResultTagNameElement{
+			TagName: field.Name,
+		}
+		if opts.Type == index.AggregateTagNamesAndValues {
+			elem.TagValues = make([]*rpc.AggregateQueryRawResultTagValueElement, 0, len(field.Terms))
+			for _, term := range field.Terms {
+				elem.TagValues = append(elem.TagValues, &rpc.AggregateQueryRawResultTagValueElement{
+					TagValue: term,
+				})
+			}
+		}
+		response.Results = append(response.Results, elem)
+	}
+
+	s.metrics.aggregate.ReportSuccess(s.nowFn().Sub(callStart))
+	return response, nil
+}
+
 func (s *service) encodeTags(
 	enc serialize.TagEncoder,
 	tags ident.TagIterator,
@@ -494,7 +679,22 @@ func (s *service) FetchBatchRaw(tctx thrift.Context, req *rpc.FetchBatchRawReque
 		return nil, tterrors.NewBadRequestError(xerrors.FirstError(rangeStartErr, rangeEndErr))
 	}
 
+	downsampleOpts, err := convert.FromRPCDownsampleOptions(
+		req.DownsampleResolutionNanos, req.DownsampleAggregationType)
+	if err != nil {
+		s.metrics.fetchBatchRaw.ReportNonRetryableErrors(len(req.Ids))
+		s.metrics.fetchBatchRaw.ReportLatency(s.nowFn().Sub(callStart))
+		return nil, tterrors.NewBadRequestError(err)
+	}
+
 	nsID := s.newID(ctx, req.NameSpace)
+	codecID := s.codecID(nsID)
+	readLimits, err := s.newReadLimits(nsID)
+	if err != nil {
+		s.metrics.fetchBatchRaw.ReportNonRetryableErrors(len(req.Ids))
+		s.metrics.fetchBatchRaw.ReportLatency(s.nowFn().Sub(callStart))
+		return nil, tterrors.NewInternalError(err)
+	}
 
 	result := rpc.NewFetchBatchRawResult_()
 
@@ -509,7 +709,24 @@ func (s *service) FetchBatchRaw(tctx thrift.Context, req *rpc.FetchBatchRawReque
 		result.Elements = append(result.Elements, rawResult)
 
 		tsID := s.newID(ctx, req.Ids[i])
-		segments, rpcErr := s.readEncoded(ctx, nsID, tsID, start, end)
+		var (
+			segments []*rpc.Segments
+			rpcErr   *rpc.Error
+		)
+		if downsampleOpts.Enabled() {
+			segments, rpcErr = s.readDownsampled(ctx, nsID, tsID, start, end,
+				downsampleOpts, readLimits)
+		} else {
+			segments, rpcErr = s.readEncoded(ctx, nsID, tsID, start, end,
+				codecID, readLimits)
+		}
+		if tterrors.IsResourceExhaustedError(rpcErr) {
+			// Fail the whole request rather than the single element since
+			// reading any further series would also exceed the limit.
+			s.metrics.fetchBatchRaw.ReportNonRetryableErrors(len(req.Ids))
+			s.metrics.fetchBatchRaw.ReportLatency(s.nowFn().Sub(callStart))
+			return nil, rpcErr
+		}
 		if rpcErr != nil {
 			rawResult.Err = rpcErr
 			if tterrors.IsBadRequestError(rawResult.Err) {
@@ -590,6 +807,7 @@ func (s *service) FetchBlocksRaw(tctx thrift.Context, req *rpc.FetchBlocksRawReq
 					// No data for block, skip this block
 					continue
 				}
+				setCodecID(converted.Segments, nsMetadata.Options().CodecID())
 				block.Segments = converted.Segments
 				block.Checksum = converted.Checksum
 			}
@@ -1008,6 +1226,66 @@ func (s *service) Truncate(tctx thrift.Context, req *rpc.TruncateRequest) (r *rp
 	return res, nil
 }
 
+func (s *service) DeleteSeries(tctx thrift.Context, req *rpc.DeleteSeriesRequest) (*rpc.DeleteSeriesResult_, error) {
+	callStart := s.nowFn()
+	ctx := tchannelthrift.Context(tctx)
+
+	ids := make([]ident.ID, 0, len(req.Ids))
+	for _, id := range req.Ids {
+		ids = append(ids, s.newID(ctx, id))
+	}
+
+	deleted, err := s.db.DeleteSeries(ctx, s.newID(ctx, req.NameSpace), ids)
+	if err != nil {
+		s.metrics.deleteSeries.ReportError(s.nowFn().Sub(callStart))
+		return nil, convert.ToRPCError(err)
+	}
+
+	res := newDeleteSeriesResult(deleted)
+
+	s.metrics.deleteSeries.ReportSuccess(s.nowFn().Sub(callStart))
+
+	return res, nil
+}
+
+func (s *service) DeleteTagged(tctx thrift.Context, req *rpc.DeleteTaggedRequest) (*rpc.DeleteSeriesResult_, error) {
+	callStart := s.nowFn()
+	ctx := tchannelthrift.Context(tctx)
+
+	ns, query, err := convert.FromRPCDeleteTaggedRequest(req, s.pools)
+	if err != nil {
+		s.metrics.deleteTagged.ReportError(s.nowFn().Sub(callStart))
+		return nil, tterrors.NewBadRequestError(err)
+	}
+
+	deleted, err := s.db.DeleteTagged(ctx, ns, query)
+	if err != nil {
+		s.metrics.deleteTagged.ReportError(s.nowFn().Sub(callStart))
+		return nil, convert.ToRPCError(err)
+	}
+
+	res := newDeleteSeriesResult(deleted)
+
+	s.metrics.deleteTagged.ReportSuccess(s.nowFn().Sub(callStart))
+
+	return res, nil
+}
+
+// newDeleteSeriesResult returns the result of a delete with the IDs of the
+// series deleted, which callers use to count the series deleted across the
+// replicas of each shard.
+func newDeleteSeriesResult(deleted []ident.ID) *rpc.DeleteSeriesResult_ {
+	res := rpc.NewDeleteSeriesResult_()
+	res.NumSeries = int64(len(deleted))
+	res.Ids = make([][]byte, 0, len(deleted))
+	for _, id := range deleted {
+		// Copy the IDs as they may be returned to the pool once the
+		// request context is closed.
+		res.Ids = append(res.Ids, append([]byte(nil), id.Bytes()...))
+	}
+	return res
+}
+
 func (s *service) GetPersistRateLimit(
 	ctx thrift.Context,
 ) (*rpc.NodePersistRateLimitResult_, error) {
@@ -1158,6 +1436,8 @@ func (s *service) readEncoded(
 	ctx context.Context,
 	nsID, tsID ident.ID,
 	start, end time.Time,
+	codecID codec.ID,
+	readLimits *readLimits,
 ) ([]*rpc.Segments, *rpc.Error) {
 	encoded, err := s.db.ReadEncoded(ctx, nsID, tsID, start, end)
 	if err != nil {
@@ -1179,12 +1459,229 @@ func (s *service) readEncoded(
 		if converted.Segments == nil {
 			continue
 		}
+		// NB: The limits are checked as each block is read so that a query
+		// is aborted before it reads the rest of a large series.
+		if err := readLimits.incSegmentsRead(converted.Segments); err != nil {
+			return nil, convert.ToRPCError(err)
+		}
+		setCodecID(converted.Segments, codecID)
 		segments = append(segments, converted.Segments)
 	}
 
 	return segments, nil
 }
 
+// readDownsampled reads the data of a series and downsamples it as it is
+// decoded, returning the downsampled datapoints re-encoded as a single
+// merged segment spanning the requested range.
+func (s *service) readDownsampled(
+	ctx context.Context,
+	nsID, tsID ident.ID,
+	start, end time.Time,
+	opts downsample.Options,
+	readLimits *readLimits,
+) ([]*rpc.Segments, *rpc.Error) {
+	encoded, err := s.db.ReadEncoded(ctx, nsID, tsID, start, end)
+	if err != nil {
+		return nil, convert.ToRPCError(err)
+	}
+	// NB: The blocks are decoded as soon as they are read when downsampling
+	// so waiting on their segments here does not delay the response.
+	for _, readers := range encoded {
+		for _, reader := range readers {
+			seg, err := reader.Segment()
+			if err != nil {
+				return nil, convert.ToRPCError(err)
+			}
+			if err := readLimits.incBytesRead(seg.Len()); err != nil {
+				return nil, convert.ToRPCError(err)
+			}
+		}
+	}
+
+	multiItPool, err := s.multiReaderIteratorPool(nsID)
+	if err != nil {
+		return nil, convert.ToRPCError(err)
+	}
+	encoderPool, err := s.encoderPool(nsID)
+	if err != nil {
+		return nil, convert.ToRPCError(err)
+	}
+
+	multiIt := multiItPool.Get()
+	multiIt.ResetSliceOfSlices(xio.NewReaderSliceOfSlicesFromBlockReadersIterator(encoded))
+	iter := downsample.NewIterator(readLimits.iterator(multiIt), start, end, opts)
+	defer iter.Close()
+
+	encoder := encoderPool.Get()
+	encoder.Reset(start, 0)
+	for iter.Next() {
+		dp, unit, annotation := iter.Current()
+		if err := encoder.Encode(dp, unit, annotation); err != nil {
+			encoder.Close()
+			return nil, convert.ToRPCError(err)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		encoder.Close()
+		return nil, convert.ToRPCError(err)
+	}
+	if encoder.NumEncoded() == 0 {
+		encoder.Close()
+		return nil, nil
+	}
+
+	reader := xio.NewSegmentReader(encoder.Discard())
+	ctx.RegisterFinalizer(reader)
+
+	converted, err := convert.ToSegments([]xio.BlockReader{{
+		SegmentReader: reader,
+		Start:         start,
+		BlockSize:     end.Sub(start),
+	}})
+	if err != nil {
+		return nil, convert.ToRPCError(err)
+	}
+	if converted.Segments == nil {
+		return nil, nil
+	}
+	setCodecID(converted.Segments, s.codecID(nsID))
+	return []*rpc.Segments{converted.Segments}, nil
+}
+
+// checkSeriesFetchedLimit returns an error if reading data for the given
+// number of series would exceed the per query series fetched limit.
+func (s *service) checkSeriesFetchedLimit(numSeries int) error {
+	max := s.db.Options().LimitsOptions().MaxSeriesFetchedPerQuery()
+	if max > 0 && numSeries > max {
+		return limits.NewQueryLimitExceededError("series-fetched-per-query",
+			int64(numSeries), int64(max))
+	}
+	return nil
+}
+
+// readLimits tracks the data read by a single request against the per query
+// read limits and the lookback bytes read limit of the namespace.
+type readLimits struct {
+	maxBytesRead       int64
+	maxDatapointsRead  int64
+	bytesReadLimit     limits.LookbackLimit
+	readerIteratorPool encoding.ReaderIteratorPool
+
+	bytesRead      int64
+	datapointsRead int64
+}
+
+func (s *service) newReadLimits(nsID ident.ID) (*readLimits, error) {
+	opts := s.db.Options().LimitsOptions()
+	l := &readLimits{
+		maxBytesRead:      opts.MaxBytesReadPerQuery(),
+		maxDatapointsRead: opts.MaxDatapointsReadPerQuery(),
+	}
+	if ns, ok := s.db.Namespace(nsID); ok {
+		l.bytesReadLimit = ns.QueryLimits().BytesReadLimit()
+	}
+	if l.maxDatapointsRead > 0 {
+		// NB: Counting the datapoints read requires decoding the blocks.
+		pool, err := s.readerIteratorPool(nsID)
+		if err != nil {
+			return nil, err
+		}
+		l.readerIteratorPool = pool
+	}
+	return l, nil
+}
+
+// incSegmentsRead accounts the bytes and datapoints of the segments read
+// from a block, returning an error if any of the limits are exceeded.
+func (l *readLimits) incSegmentsRead(segments *rpc.Segments) error {
+	if segments.Merged != nil {
+		if err := l.incSegmentRead(segments.Merged); err != nil {
+			return err
+		}
+	}
+	for _, seg := range segments.Unmerged {
+		if err := l.incSegmentRead(seg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *readLimits) incSegmentRead(seg *rpc.Segment) error {
+	if err := l.incBytesRead(len(seg.Head) + len(seg.Tail)); err != nil {
+		return err
+	}
+	if l.maxDatapointsRead <= 0 {
+		return nil
+	}
+
+	readerIter := l.readerIteratorPool.Get()
+	readerIter.Reset(io.MultiReader(bytes.NewReader(seg.Head), bytes.NewReader(seg.Tail)))
+	iter := l.iterator(readerIter)
+	for iter.Next() {
+	}
+	err := iter.Err()
+	iter.Close()
+	return err
+}
+
+func (l *readLimits) incBytesRead(n int) error {
+	l.bytesRead += int64(n)
+	if l.maxBytesRead > 0 && l.bytesRead > l.maxBytesRead {
+		return limits.NewQueryLimitExceededError("bytes-read-per-query",
+			l.bytesRead, l.maxBytesRead)
+	}
+	if l.bytesReadLimit != nil {
+		return l.bytesReadLimit.Inc(n)
+	}
+	return nil
+}
+
+func (l *readLimits) incDatapointsRead(n int) error {
+	l.datapointsRead += int64(n)
+	if l.maxDatapointsRead > 0 && l.datapointsRead > l.maxDatapointsRead {
+		return limits.NewQueryLimitExceededError("datapoints-read-per-query",
+			l.datapointsRead, l.maxDatapointsRead)
+	}
+	return nil
+}
+
+// iterator returns an iterator that accounts each datapoint read from iter
+// against the datapoints read limit, stopping with an error once the limit
+// is exceeded.
+func (l *readLimits) iterator(iter encoding.Iterator) encoding.Iterator {
+	if l.maxDatapointsRead <= 0 {
+		return iter
+	}
+	return &limitedIterator{Iterator: iter, limits: l}
+}
+
+type limitedIterator struct {
+	encoding.Iterator
+
+	limits *readLimits
+	err    error
+}
+
+func (it *limitedIterator) Next() bool {
+	if it.err != nil || !it.Iterator.Next() {
+		return false
+	}
+	if err := it.limits.incDatapointsRead(1); err != nil {
+		it.err = err
+		return false
+	}
+	return true
+}
+
+func (it *limitedIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.Iterator.Err()
+}
+
 func (s *service) newTagsDecoder(ctx context.Context, encodedTags []byte) (serialize.TagDecoder, error) {
 	checkedBytes := s.pools.checkedBytesWrapper.Get(encodedTags)
 	dec := s.pools.tagDecoder.Get()
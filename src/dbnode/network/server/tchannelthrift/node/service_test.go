@@ -28,6 +28,8 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/digest"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
+	"github.com/m3db/m3/src/dbnode/encoding/downsample"
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/convert"
@@ -36,12 +38,14 @@ import (
 	"github.com/m3db/m3/src/dbnode/storage"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/m3ninx/idx"
 	"github.com/m3db/m3/src/x/serialize"
 	"github.com/m3db/m3x/checked"
+	"github.com/m3db/m3x/context"
 	"github.com/m3db/m3x/ident"
 	xtime "github.com/m3db/m3x/time"
 
@@ -127,6 +131,7 @@ func TestServiceQuery(t *testing.T) {
 
 	mockDB := storage.NewMockDatabase(ctrl)
 	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().Namespace(gomock.Any()).Return(nil, false).AnyTimes()
 	mockDB.EXPECT().IsOverloaded().Return(false)
 
 	service := NewService(mockDB, testTChannelThriftOptions).(*service)
@@ -301,6 +306,7 @@ func TestServiceFetch(t *testing.T) {
 
 	mockDB := storage.NewMockDatabase(ctrl)
 	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().Namespace(gomock.Any()).Return(nil, false).AnyTimes()
 	mockDB.EXPECT().IsOverloaded().Return(false)
 
 	service := NewService(mockDB, testTChannelThriftOptions).(*service)
@@ -361,6 +367,94 @@ func TestServiceFetch(t *testing.T) {
 	}
 }
 
+func TestServiceFetchDownsampled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().Namespace(gomock.Any()).Return(nil, false).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false)
+
+	service := NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Minute)
+	end := start.Add(2 * time.Hour)
+
+	enc := testStorageOpts.EncoderPool().Get()
+	enc.Reset(start, 0)
+	for i := 0; i < 180; i++ {
+		dp := ts.Datapoint{
+			Timestamp: start.Add(time.Duration(i) * 10 * time.Second),
+			Value:     float64(i),
+		}
+		require.NoError(t, enc.Encode(dp, xtime.Second, nil))
+	}
+
+	nsID := "metrics"
+	mockDB.EXPECT().
+		ReadEncoded(ctx, ident.NewIDMatcher(nsID), ident.NewIDMatcher("foo"), start, end).
+		Return([][]xio.BlockReader{{
+			xio.BlockReader{
+				SegmentReader: enc.Stream(),
+			},
+		}}, nil)
+
+	resolution := int64(10 * time.Minute)
+	r, err := service.Fetch(tctx, &rpc.FetchRequest{
+		RangeStart:                start.Unix(),
+		RangeEnd:                  end.Unix(),
+		RangeType:                 rpc.TimeType_UNIX_SECONDS,
+		NameSpace:                 nsID,
+		ID:                        "foo",
+		ResultTimeType:            rpc.TimeType_UNIX_SECONDS,
+		DownsampleResolutionNanos: &resolution,
+		DownsampleAggregationType: rpc.DownsampleAggregationType_MAX,
+	})
+	require.NoError(t, err)
+
+	// 180 datapoints every 10s downsampled into three 10m windows, each
+	// timestamped at its last datapoint.
+	require.Equal(t, 3, len(r.Datapoints))
+	for i, dp := range r.Datapoints {
+		windowLast := start.Add(time.Duration(i)*10*time.Minute + 59*10*time.Second)
+		assert.Equal(t, windowLast.Unix(), dp.Timestamp)
+		assert.Equal(t, float64((i+1)*60-1), dp.Value)
+	}
+}
+
+func TestServiceFetchDownsampledInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false)
+
+	service := NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	start := time.Now().Add(-2 * time.Hour)
+	resolution := int64(-1)
+	_, err := service.Fetch(tctx, &rpc.FetchRequest{
+		RangeStart:                start.Unix(),
+		RangeEnd:                  start.Add(time.Hour).Unix(),
+		RangeType:                 rpc.TimeType_UNIX_SECONDS,
+		NameSpace:                 "metrics",
+		ID:                        "foo",
+		DownsampleResolutionNanos: &resolution,
+	})
+	require.Error(t, err)
+	assert.True(t, tterrors.IsBadRequestError(err.(*rpc.Error)))
+}
+
 func TestServiceFetchIsOverloaded(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -400,6 +494,7 @@ func TestServiceFetchBatchRaw(t *testing.T) {
 
 	mockDB := storage.NewMockDatabase(ctrl)
 	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().Namespace(gomock.Any()).Return(nil, false).AnyTimes()
 	mockDB.EXPECT().IsOverloaded().Return(false)
 
 	service := NewService(mockDB, testTChannelThriftOptions).(*service)
@@ -474,6 +569,7 @@ func TestServiceFetchBatchRaw(t *testing.T) {
 		seg := elem.Segments[0]
 		require.NotNil(t, seg)
 		require.NotNil(t, seg.Merged)
+		assert.False(t, seg.IsSetCodecID())
 
 		var expectHead, expectTail []byte
 		expectSegment, err := streams[string(id)].Segment()
@@ -491,6 +587,164 @@ func TestServiceFetchBatchRaw(t *testing.T) {
 	}
 }
 
+func TestServiceFetchBatchRawCodecID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	nsID := "metrics"
+	queryLimits, err := limits.NewQueryLimits(limits.NewOptions())
+	require.NoError(t, err)
+	mockNs := storage.NewMockNamespace(ctrl)
+	mockNs.EXPECT().Options().
+		Return(testNamespaceOptions.SetCodecID(codec.DeltaOfDelta)).AnyTimes()
+	mockNs.EXPECT().QueryLimits().Return(queryLimits).AnyTimes()
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Namespace(ident.NewIDMatcher(nsID)).Return(mockNs, true).AnyTimes()
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false)
+
+	service := NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	end := start.Add(2 * time.Hour)
+
+	enc := testStorageOpts.EncoderPool().Get()
+	enc.Reset(start, 0)
+	dp := ts.Datapoint{Timestamp: start.Add(10 * time.Second), Value: 1.0}
+	require.NoError(t, enc.Encode(dp, xtime.Second, nil))
+
+	mockDB.EXPECT().
+		ReadEncoded(ctx, ident.NewIDMatcher(nsID), ident.NewIDMatcher("foo"), start, end).
+		Return([][]xio.BlockReader{
+			[]xio.BlockReader{
+				xio.BlockReader{
+					SegmentReader: enc.Stream(),
+				},
+			},
+		}, nil)
+
+	r, err := service.FetchBatchRaw(tctx, &rpc.FetchBatchRawRequest{
+		RangeStart:    start.Unix(),
+		RangeEnd:      end.Unix(),
+		RangeTimeType: rpc.TimeType_UNIX_SECONDS,
+		NameSpace:     []byte(nsID),
+		Ids:           [][]byte{[]byte("foo")},
+	})
+	require.NoError(t, err)
+
+	// The segments must report the codec of the namespace so that
+	// clients decode them with it rather than the default codec.
+	require.Equal(t, 1, len(r.Elements))
+	require.Nil(t, r.Elements[0].Err)
+	require.Equal(t, 1, len(r.Elements[0].Segments))
+	seg := r.Elements[0].Segments[0]
+	require.True(t, seg.IsSetCodecID())
+	assert.Equal(t, int32(codec.DeltaOfDelta), seg.GetCodecID())
+}
+
+func TestServiceFetchBatchRawDownsampled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().Namespace(gomock.Any()).Return(nil, false).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false)
+
+	service := NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Hour)
+	end := start.Add(time.Hour)
+	nsID := "metrics"
+
+	enc := testStorageOpts.EncoderPool().Get()
+	enc.Reset(start, 0)
+	for i := 0; i < 60; i++ {
+		dp := ts.Datapoint{
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Value:     float64(i),
+		}
+		require.NoError(t, enc.Encode(dp, xtime.Second, nil))
+	}
+	mockDB.EXPECT().
+		ReadEncoded(ctx, ident.NewIDMatcher(nsID), ident.NewIDMatcher("foo"), start, end).
+		Return([][]xio.BlockReader{
+			[]xio.BlockReader{
+				xio.BlockReader{
+					SegmentReader: enc.Stream(),
+					Start:         start,
+					BlockSize:     time.Hour,
+				},
+			},
+		}, nil)
+
+	resolution := int64(30 * time.Minute)
+	r, err := service.FetchBatchRaw(tctx, &rpc.FetchBatchRawRequest{
+		RangeStart:                start.Unix(),
+		RangeEnd:                  end.Unix(),
+		RangeTimeType:             rpc.TimeType_UNIX_SECONDS,
+		NameSpace:                 []byte(nsID),
+		Ids:                       [][]byte{[]byte("foo")},
+		DownsampleResolutionNanos: &resolution,
+		DownsampleAggregationType: rpc.DownsampleAggregationType_MAX,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 1, len(r.Elements))
+	require.Nil(t, r.Elements[0].Err)
+	require.Equal(t, 1, len(r.Elements[0].Segments))
+	seg := r.Elements[0].Segments[0]
+	require.NotNil(t, seg.Merged)
+
+	iter := testStorageOpts.ReaderIteratorPool().Get()
+	iter.Reset(bytes.NewReader(append(seg.Merged.Head, seg.Merged.Tail...)))
+	defer iter.Close()
+
+	var values []float64
+	for iter.Next() {
+		dp, _, _ := iter.Current()
+		values = append(values, dp.Value)
+	}
+	require.NoError(t, iter.Err())
+	assert.Equal(t, []float64{29, 59}, values)
+}
+
+func TestServiceFetchBatchRawDownsampledInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false)
+
+	service := NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	start := time.Now().Add(-2 * time.Hour)
+	resolution := int64(-1)
+	_, err := service.FetchBatchRaw(tctx, &rpc.FetchBatchRawRequest{
+		RangeStart:                start.Unix(),
+		RangeEnd:                  start.Add(time.Hour).Unix(),
+		RangeTimeType:             rpc.TimeType_UNIX_SECONDS,
+		NameSpace:                 []byte("metrics"),
+		Ids:                       [][]byte{[]byte("foo")},
+		DownsampleResolutionNanos: &resolution,
+	})
+	require.Error(t, err)
+	assert.True(t, tterrors.IsBadRequestError(err.(*rpc.Error)))
+}
+
 func TestServiceFetchBatchRawIsOverloaded(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -871,6 +1125,7 @@ func TestServiceFetchTagged(t *testing.T) {
 
 	mockDB := storage.NewMockDatabase(ctrl)
 	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().Namespace(gomock.Any()).Return(nil, false).AnyTimes()
 	mockDB.EXPECT().IsOverloaded().Return(false)
 
 	service := NewService(mockDB, testTChannelThriftOptions).(*service)
@@ -996,6 +1251,236 @@ func TestServiceFetchTagged(t *testing.T) {
 	}
 }
 
+func TestServiceFetchTaggedDownsampled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().Namespace(gomock.Any()).Return(nil, false).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false)
+
+	service := NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Minute)
+	end := start.Add(2 * time.Hour)
+	nsID := "metrics"
+
+	// Spread the datapoints across two blocks to ensure both are merged
+	// into the single downsampled segment.
+	var blocks []xio.BlockReader
+	for b := 0; b < 2; b++ {
+		blockStart := start.Add(time.Duration(b) * time.Hour)
+		enc := testStorageOpts.EncoderPool().Get()
+		enc.Reset(blockStart, 0)
+		for i := 0; i < 60; i++ {
+			dp := ts.Datapoint{
+				Timestamp: blockStart.Add(time.Duration(i) * time.Minute),
+				Value:     1,
+			}
+			require.NoError(t, enc.Encode(dp, xtime.Second, nil))
+		}
+		blocks = append(blocks, xio.BlockReader{
+			SegmentReader: enc.Stream(),
+			Start:         blockStart,
+			BlockSize:     time.Hour,
+		})
+	}
+	mockDB.EXPECT().
+		ReadEncoded(ctx, ident.NewIDMatcher(nsID), ident.NewIDMatcher("foo"), start, end).
+		Return([][]xio.BlockReader{blocks[:1], blocks[1:]}, nil)
+
+	req, err := idx.NewTermQuery([]byte("foo"), []byte("bar"))
+	require.NoError(t, err)
+	qry := index.Query{Query: req}
+
+	resMap := index.NewResults(testIndexOptions)
+	resMap.Reset(ident.StringID(nsID))
+	resMap.Map().Set(ident.StringID("foo"), ident.NewTags(
+		ident.StringTag("foo", "bar"),
+	))
+
+	downsampleOpts := downsample.Options{
+		Resolution:  30 * time.Minute,
+		Aggregation: downsample.Sum,
+	}
+	mockDB.EXPECT().QueryIDs(
+		ctx,
+		ident.NewIDMatcher(nsID),
+		index.NewQueryMatcher(qry),
+		index.QueryOptions{
+			StartInclusive: start,
+			EndExclusive:   end,
+			Downsample:     downsampleOpts,
+		}).Return(index.QueryResults{Results: resMap, Exhaustive: true}, nil)
+
+	startNanos, err := convert.ToValue(start, rpc.TimeType_UNIX_NANOSECONDS)
+	require.NoError(t, err)
+	endNanos, err := convert.ToValue(end, rpc.TimeType_UNIX_NANOSECONDS)
+	require.NoError(t, err)
+	data, err := idx.Marshal(req)
+	require.NoError(t, err)
+	resolution := int64(downsampleOpts.Resolution)
+	r, err := service.FetchTagged(tctx, &rpc.FetchTaggedRequest{
+		NameSpace:                 []byte(nsID),
+		Query:                     data,
+		RangeStart:                startNanos,
+		RangeEnd:                  endNanos,
+		FetchData:                 true,
+		DownsampleResolutionNanos: &resolution,
+		DownsampleAggregationType: rpc.DownsampleAggregationType_SUM,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 1, len(r.Elements))
+	elem := r.Elements[0]
+	assert.Nil(t, elem.Err)
+	require.Equal(t, 1, len(elem.Segments))
+	seg := elem.Segments[0]
+	require.NotNil(t, seg.Merged)
+	require.NotNil(t, seg.Merged.StartTime)
+	assert.Equal(t, startNanos, *seg.Merged.StartTime)
+
+	iter := testStorageOpts.ReaderIteratorPool().Get()
+	iter.Reset(bytes.NewReader(append(seg.Merged.Head, seg.Merged.Tail...)))
+	defer iter.Close()
+
+	var i int
+	for iter.Next() {
+		dp, _, _ := iter.Current()
+		windowLast := start.Add(time.Duration(i)*30*time.Minute + 29*time.Minute)
+		assert.True(t, windowLast.Equal(dp.Timestamp))
+		assert.Equal(t, 30.0, dp.Value)
+		i++
+	}
+	require.NoError(t, iter.Err())
+	assert.Equal(t, 4, i)
+}
+
+func TestServiceFetchTaggedQueryLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storageOpts := testStorageOpts.SetLimitsOptions(
+		testStorageOpts.LimitsOptions().
+			SetMaxSeriesFetchedPerQuery(1).
+			SetMaxBytesReadPerQuery(1))
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(storageOpts).AnyTimes()
+	mockDB.EXPECT().Namespace(gomock.Any()).Return(nil, false).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false).AnyTimes()
+
+	service := NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	end := start.Add(2 * time.Hour)
+	startNanos, err := convert.ToValue(start, rpc.TimeType_UNIX_NANOSECONDS)
+	require.NoError(t, err)
+	endNanos, err := convert.ToValue(end, rpc.TimeType_UNIX_NANOSECONDS)
+	require.NoError(t, err)
+
+	nsID := "metrics"
+	req, err := idx.NewRegexpQuery([]byte("foo"), []byte("b.*"))
+	require.NoError(t, err)
+	data, err := idx.Marshal(req)
+	require.NoError(t, err)
+	fetchReq := &rpc.FetchTaggedRequest{
+		NameSpace:  []byte(nsID),
+		Query:      data,
+		RangeStart: startNanos,
+		RangeEnd:   endNanos,
+		FetchData:  true,
+	}
+
+	requireResourceExhausted := func(err error) {
+		require.Error(t, err)
+		rpcErr, ok := err.(*rpc.Error)
+		require.True(t, ok)
+		require.Equal(t, rpc.ErrorType_RESOURCE_EXHAUSTED, rpcErr.Type)
+	}
+
+	// Limit errors from the index surface as resource exhausted errors.
+	mockDB.EXPECT().QueryIDs(ctx, ident.NewIDMatcher(nsID), gomock.Any(), gomock.Any()).
+		Return(index.QueryResults{}, limits.NewQueryLimitExceededError("test", 2, 1))
+	_, err = service.FetchTagged(tctx, fetchReq)
+	requireResourceExhausted(err)
+
+	// Fetching data for more series than the limit is rejected.
+	resMap := index.NewResults(testIndexOptions)
+	resMap.Reset(ident.StringID(nsID))
+	resMap.Map().Set(ident.StringID("foo"), ident.Tags{})
+	resMap.Map().Set(ident.StringID("bar"), ident.Tags{})
+	mockDB.EXPECT().QueryIDs(ctx, ident.NewIDMatcher(nsID), gomock.Any(), gomock.Any()).
+		Return(index.QueryResults{Results: resMap, Exhaustive: true}, nil)
+	_, err = service.FetchTagged(tctx, fetchReq)
+	requireResourceExhausted(err)
+
+	// Reading more bytes than the limit is rejected.
+	resMap = index.NewResults(testIndexOptions)
+	resMap.Reset(ident.StringID(nsID))
+	resMap.Map().Set(ident.StringID("foo"), ident.Tags{})
+	mockDB.EXPECT().QueryIDs(ctx, ident.NewIDMatcher(nsID), gomock.Any(), gomock.Any()).
+		Return(index.QueryResults{Results: resMap, Exhaustive: true}, nil)
+
+	enc := testStorageOpts.EncoderPool().Get()
+	enc.Reset(start, 0)
+	require.NoError(t, enc.Encode(ts.Datapoint{
+		Timestamp: start.Add(time.Second),
+		Value:     1.0,
+	}, xtime.Second, nil))
+	mockDB.EXPECT().
+		ReadEncoded(ctx, ident.NewIDMatcher(nsID), ident.NewIDMatcher("foo"), start, end).
+		Return([][]xio.BlockReader{{
+			xio.BlockReader{
+				SegmentReader: enc.Stream(),
+			},
+		}}, nil)
+	_, err = service.FetchTagged(tctx, fetchReq)
+	requireResourceExhausted(err)
+
+	// Reading more datapoints than the limit is rejected.
+	storageOpts = testStorageOpts.SetLimitsOptions(
+		testStorageOpts.LimitsOptions().SetMaxDatapointsReadPerQuery(1))
+	mockDB = storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(storageOpts).AnyTimes()
+	mockDB.EXPECT().Namespace(gomock.Any()).Return(nil, false).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false).AnyTimes()
+	service = NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	resMap = index.NewResults(testIndexOptions)
+	resMap.Reset(ident.StringID(nsID))
+	resMap.Map().Set(ident.StringID("foo"), ident.Tags{})
+	mockDB.EXPECT().QueryIDs(ctx, ident.NewIDMatcher(nsID), gomock.Any(), gomock.Any()).
+		Return(index.QueryResults{Results: resMap, Exhaustive: true}, nil)
+
+	enc = testStorageOpts.EncoderPool().Get()
+	enc.Reset(start, 0)
+	for i := 1; i <= 2; i++ {
+		require.NoError(t, enc.Encode(ts.Datapoint{
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+			Value:     float64(i),
+		}, xtime.Second, nil))
+	}
+	mockDB.EXPECT().
+		ReadEncoded(ctx, ident.NewIDMatcher(nsID), ident.NewIDMatcher("foo"), start, end).
+		Return([][]xio.BlockReader{{
+			xio.BlockReader{
+				SegmentReader: enc.Stream(),
+			},
+		}}, nil)
+	_, err = service.FetchTagged(tctx, fetchReq)
+	requireResourceExhausted(err)
+}
+
 func TestServiceFetchTaggedIsOverloaded(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1418,6 +1903,147 @@ func TestServiceTruncate(t *testing.T) {
 	assert.Equal(t, truncated, r.NumSeries)
 }
 
+func TestServiceDeleteSeries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+
+	service := NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	nsID := "metrics"
+
+	mockDB.EXPECT().
+		DeleteSeries(gomock.Any(), ident.NewIDMatcher(nsID), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ ident.ID, ids []ident.ID) ([]ident.ID, error) {
+			require.Equal(t, 2, len(ids))
+			assert.Equal(t, "foo", ids[0].String())
+			assert.Equal(t, "bar", ids[1].String())
+			return ids, nil
+		})
+
+	r, err := service.DeleteSeries(tctx, &rpc.DeleteSeriesRequest{
+		NameSpace: []byte(nsID),
+		Ids:       [][]byte{[]byte("foo"), []byte("bar")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), r.NumSeries)
+	assert.Equal(t, [][]byte{[]byte("foo"), []byte("bar")}, r.Ids)
+}
+
+func TestServiceDeleteTagged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+
+	service := NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	nsID := "metrics"
+
+	req, err := idx.NewRegexpQuery([]byte("foo"), []byte("b.*"))
+	require.NoError(t, err)
+	qry := index.Query{Query: req}
+
+	mockDB.EXPECT().
+		DeleteTagged(gomock.Any(), ident.NewIDMatcher(nsID), index.NewQueryMatcher(qry)).
+		Return([]ident.ID{ident.StringID("bar"), ident.StringID("baz")}, nil)
+
+	data, err := idx.Marshal(req)
+	require.NoError(t, err)
+	r, err := service.DeleteTagged(tctx, &rpc.DeleteTaggedRequest{
+		NameSpace: []byte(nsID),
+		Query:     data,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), r.NumSeries)
+	assert.Equal(t, [][]byte{[]byte("bar"), []byte("baz")}, r.Ids)
+
+	_, err = service.DeleteTagged(tctx, &rpc.DeleteTaggedRequest{
+		NameSpace: []byte(nsID),
+		Query:     []byte("invalid"),
+	})
+	require.Error(t, err)
+}
+
+func TestServiceAggregateRaw(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+
+	service := NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	end := start.Add(2 * time.Hour)
+	nsID := "metrics"
+
+	req, err := idx.NewRegexpQuery([]byte("foo"), []byte("b.*"))
+	require.NoError(t, err)
+	qry := index.Query{Query: req}
+
+	results := index.NewAggregateResults(ident.StringID(nsID), index.AggregateTagNamesAndValues)
+	results.AddFieldAndTerm([]byte("foo"), []byte("bar"))
+	results.AddFieldAndTerm([]byte("foo"), []byte("baz"))
+	results.AddFieldAndTerm([]byte("qux"), []byte("quz"))
+
+	var limit int64 = 10
+	mockDB.EXPECT().
+		AggregateQuery(gomock.Any(), ident.NewIDMatcher(nsID), index.NewQueryMatcher(qry),
+			index.AggregationOptions{
+				QueryOptions: index.QueryOptions{
+					StartInclusive: start,
+					EndExclusive:   end,
+					Limit:          10,
+				},
+				FieldFilter: index.AggregateFieldFilter{[]byte("foo"), []byte("qux")},
+				Type:        index.AggregateTagNamesAndValues,
+			}).
+		Return(index.AggregateQueryResult{Results: results, Exhaustive: true}, nil)
+
+	data, err := idx.Marshal(req)
+	require.NoError(t, err)
+	r, err := service.AggregateRaw(tctx, &rpc.AggregateQueryRawRequest{
+		NameSpace:     []byte(nsID),
+		Query:         data,
+		RangeStart:    start.Unix(),
+		RangeEnd:      end.Unix(),
+		Limit:         &limit,
+		TagNameFilter: [][]byte{[]byte("foo"), []byte("qux")},
+	})
+	require.NoError(t, err)
+	require.True(t, r.Exhaustive)
+	require.Equal(t, 2, len(r.Results))
+	assert.Equal(t, "foo", string(r.Results[0].TagName))
+	require.Equal(t, 2, len(r.Results[0].TagValues))
+	assert.Equal(t, "bar", string(r.Results[0].TagValues[0].TagValue))
+	assert.Equal(t, "baz", string(r.Results[0].TagValues[1].TagValue))
+	assert.Equal(t, "qux", string(r.Results[1].TagName))
+	require.Equal(t, 1, len(r.Results[1].TagValues))
+	assert.Equal(t, "quz", string(r.Results[1].TagValues[0].TagValue))
+
+	_, err = service.AggregateRaw(tctx, &rpc.AggregateQueryRawRequest{
+		NameSpace: []byte(nsID),
+		Query:     []byte("invalid"),
+	})
+	require.Error(t, err)
+}
+
 func TestServiceSetPersistRateLimit(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -72,6 +72,8 @@ func (c *cloner) Clone(src FileSetID, dest FileSetID, destBlocksize time.Duratio
 	}
 	writerOpts := fs.DataWriterOpenOptions{
 		BlockSize: destBlocksize,
+		// The data is copied as is so it stays encoded with the same codec.
+		CodecID: reader.CodecID(),
 		Identifier: fs.FileSetFileIdentifier{
 			Namespace:  ident.StringID(dest.Namespace),
 			Shard:      dest.Shard,
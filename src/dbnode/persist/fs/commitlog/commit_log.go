@@ -91,6 +91,13 @@ type commitLog struct {
 	pendingFlushFns []callbackFn
 	maxQueueSize    int64
 
+	// Only accessed by the goroutine draining the writes, acks of writes
+	// awaiting the fsync of their batch when using group commit.
+	groupCommitEnabled       bool
+	groupCommitMaxBatchSize  int
+	pendingGroupCommitFns    []callbackFn
+	pendingGroupCommitWrites int
+
 	opts  Options
 	nowFn clock.NowFn
 	log   xlog.Logger
@@ -145,6 +152,10 @@ type commitLogMetrics struct {
 	closeErrors      tally.Counter
 	flushErrors      tally.Counter
 	flushDone        tally.Counter
+
+	groupCommitBatchSize    tally.Histogram
+	groupCommitFsyncLatency tally.Timer
+	groupCommitErrors       tally.Counter
 }
 
 type eventType int
@@ -235,12 +246,20 @@ func NewCommitLog(opts Options) (CommitLog, error) {
 			closeErrors:      scope.Counter("writes.close-errors"),
 			flushErrors:      scope.Counter("writes.flush-errors"),
 			flushDone:        scope.Counter("writes.flush-done"),
+			groupCommitBatchSize: scope.Histogram("group-commit.batch-size",
+				tally.MustMakeExponentialValueBuckets(1, 2, 16)),
+			groupCommitFsyncLatency: scope.Timer("group-commit.fsync-latency"),
+			groupCommitErrors:       scope.Counter("group-commit.errors"),
 		},
 	}
 
 	switch opts.Strategy() {
 	case StrategyWriteWait:
 		commitLog.writeFn = commitLog.writeWait
+	case StrategyGroupCommit:
+		commitLog.groupCommitEnabled = true
+		commitLog.groupCommitMaxBatchSize = opts.GroupCommitMaxBatchSize()
+		commitLog.writeFn = commitLog.writeWait
 	default:
 		commitLog.writeFn = commitLog.writeBehind
 	}
@@ -403,111 +422,175 @@ func (l *commitLog) write() {
 	// by turning non-batched writes into a batch of size one while avoiding
 	// any allocations.
 	var singleBatch = make([]ts.BatchWrite, 1)
-	var batch []ts.BatchWrite
 
 	for write := range l.writes {
-		if write.eventType == flushEventType {
-			l.writerState.writer.Flush(false)
-			continue
-		}
+		l.handleWrite(write, singleBatch)
 
-		if write.eventType == activeLogsEventType {
-			write.callbackFn(callbackResult{
-				eventType: write.eventType,
-				err:       nil,
-				activeLogs: activeLogsCallbackResult{
-					file: l.writerState.activeFile,
-				},
-			})
-			continue
+		// Fsync and acknowledge the pending group commit batch once there
+		// are no more queued writes to join it or the batch is full.
+		if len(l.pendingGroupCommitFns) > 0 &&
+			(len(l.writes) == 0 || l.pendingGroupCommitWrites >= l.groupCommitMaxBatchSize) {
+			l.groupCommit()
 		}
+	}
 
-		// For writes requiring acks add to pending acks
-		if write.eventType == writeEventType && write.callbackFn != nil {
-			l.pendingFlushFns = append(l.pendingFlushFns, write.callbackFn)
+	writer := l.writerState.writer
+	l.writerState.writer = nil
+
+	l.closeErr <- writer.Close()
+}
+
+func (l *commitLog) handleWrite(write commitLogWrite, singleBatch []ts.BatchWrite) {
+	if write.eventType == flushEventType {
+		l.writerState.writer.Flush(false)
+		return
+	}
+
+	if write.eventType == activeLogsEventType {
+		write.callbackFn(callbackResult{
+			eventType: write.eventType,
+			err:       nil,
+			activeLogs: activeLogsCallbackResult{
+				file: l.writerState.activeFile,
+			},
+		})
+		return
+	}
+
+	var (
+		now                         = l.nowFn()
+		isWriteForNextCommitLogFile = !now.Before(l.writerState.writerExpireAt)
+		isRotateLogsEvent           = write.eventType == rotateLogsEventType
+		shouldRotate                = isRotateLogsEvent || isWriteForNextCommitLogFile
+	)
+
+	if shouldRotate {
+		// Fsync and acknowledge the pending group commit batch before rotating
+		// so that its writes are acknowledged with the result of the file they
+		// were written to, rather than that of the next file.
+		if len(l.pendingGroupCommitFns) > 0 {
+			l.groupCommit()
 		}
 
-		var (
-			now                         = l.nowFn()
-			isWriteForNextCommitLogFile = !now.Before(l.writerState.writerExpireAt)
-			isRotateLogsEvent           = write.eventType == rotateLogsEventType
-			shouldRotate                = isRotateLogsEvent || isWriteForNextCommitLogFile
-		)
-
-		if shouldRotate {
-			file, err := l.openWriter(now)
-			if err != nil {
-				l.metrics.errors.Inc(1)
-				l.metrics.openErrors.Inc(1)
-				l.log.Errorf("failed to open commit log: %v", err)
-
-				if l.commitLogFailFn != nil {
-					l.commitLogFailFn(err)
-				}
-			}
+		file, err := l.openWriter(now)
+		if err != nil {
+			l.metrics.errors.Inc(1)
+			l.metrics.openErrors.Inc(1)
+			l.log.Errorf("failed to open commit log: %v", err)
 
-			if isRotateLogsEvent {
-				write.callbackFn(callbackResult{
-					eventType: write.eventType,
-					err:       err,
-					rotateLogs: rotateLogsResult{
-						file: file,
-					},
-				})
+			if l.commitLogFailFn != nil {
+				l.commitLogFailFn(err)
 			}
+		}
 
-			if err != nil || isRotateLogsEvent {
-				continue
-			}
+		if isRotateLogsEvent {
+			write.callbackFn(callbackResult{
+				eventType: write.eventType,
+				err:       err,
+				rotateLogs: rotateLogsResult{
+					file: file,
+				},
+			})
+		} else if err != nil && write.callbackFn != nil {
+			write.callbackFn(callbackResult{
+				eventType: flushEventType,
+				err:       err,
+			})
 		}
 
-		var (
-			numWritesSuccess int64
-			numDequeued      int
-		)
+		if err != nil || isRotateLogsEvent {
+			return
+		}
+	}
+
+	var (
+		batch            []ts.BatchWrite
+		numWritesSuccess int64
+		numDequeued      int
+		writeErr         error
+	)
 
-		if write.write.writeBatch == nil {
-			singleBatch[0].Write = write.write.write
-			batch = singleBatch
-		} else {
-			batch = write.write.writeBatch.Iter()
+	if write.write.writeBatch == nil {
+		singleBatch[0].Write = write.write.write
+		batch = singleBatch
+	} else {
+		batch = write.write.writeBatch.Iter()
+	}
+	numDequeued = len(batch)
+
+	for _, writeBatch := range batch {
+		if writeBatch.Err != nil {
+			// This entry was not written successfully to the in-memory datastructures so
+			// we should not persist it to the commitlog. This is important to maintain
+			// consistency and the integrity of M3DB's business logic, but also because if
+			// the write does not succeed to the in-memory datastructures then we don't have
+			// access to long-lived identifiers like the seriesID (which is pooled) so
+			// attempting to write would cause pooling / lifecycle issues as well.
+			continue
 		}
-		numDequeued = len(batch)
-
-		for _, writeBatch := range batch {
-			if writeBatch.Err != nil {
-				// This entry was not written successfully to the in-memory datastructures so
-				// we should not persist it to the commitlog. This is important to maintain
-				// consistency and the integrity of M3DB's business logic, but also because if
-				// the write does not succeed to the in-memory datastructures then we don't have
-				// access to long-lived identifiers like the seriesID (which is pooled) so
-				// attempting to write would cause pooling / lifecycle issues as well.
-				continue
-			}
 
-			write := writeBatch.Write
-			err := l.writerState.writer.Write(write.Series,
-				write.Datapoint, write.Unit, write.Annotation)
-			if err != nil {
-				l.handleWriteErr(err)
-				continue
-			}
-			numWritesSuccess++
+		write := writeBatch.Write
+		err := l.writerState.writer.Write(write.Series,
+			write.Datapoint, write.Unit, write.Annotation)
+		if err != nil {
+			l.handleWriteErr(err)
+			writeErr = err
+			continue
 		}
+		numWritesSuccess++
+	}
 
-		// Return the write batch to the pool.
-		if write.write.writeBatch != nil {
-			write.write.writeBatch.Finalize()
+	// For writes requiring acks add to pending acks once written, with group
+	// commit the acks are held until the batch the write joins has been
+	// fsync'd. Writes that failed to be written are failed immediately
+	// rather than being acknowledged by the next flush or fsync.
+	if write.callbackFn != nil {
+		switch {
+		case writeErr != nil:
+			write.callbackFn(callbackResult{
+				eventType: flushEventType,
+				err:       writeErr,
+			})
+		case l.groupCommitEnabled:
+			l.pendingGroupCommitFns = append(l.pendingGroupCommitFns, write.callbackFn)
+			l.pendingGroupCommitWrites += numDequeued
+		default:
+			l.pendingFlushFns = append(l.pendingFlushFns, write.callbackFn)
 		}
+	}
 
-		atomic.AddInt64(&l.numWritesInQueue, int64(-numDequeued))
-		l.metrics.success.Inc(numWritesSuccess)
+	// Return the write batch to the pool.
+	if write.write.writeBatch != nil {
+		write.write.writeBatch.Finalize()
 	}
 
-	writer := l.writerState.writer
-	l.writerState.writer = nil
+	atomic.AddInt64(&l.numWritesInQueue, int64(-numDequeued))
+	l.metrics.success.Inc(numWritesSuccess)
+}
 
-	l.closeErr <- writer.Close()
+// groupCommit fsyncs the commit log once for the pending group commit batch
+// and acknowledges each write in the batch with the result of the fsync.
+func (l *commitLog) groupCommit() {
+	start := l.nowFn()
+	err := l.writerState.writer.Flush(true)
+	l.metrics.groupCommitFsyncLatency.Record(l.nowFn().Sub(start))
+	l.metrics.groupCommitBatchSize.RecordValue(float64(l.pendingGroupCommitWrites))
+
+	if err != nil {
+		l.metrics.errors.Inc(1)
+		l.metrics.groupCommitErrors.Inc(1)
+		l.log.Errorf("failed to fsync commit log group commit: %v", err)
+	}
+
+	for i := range l.pendingGroupCommitFns {
+		l.pendingGroupCommitFns[i](callbackResult{
+			eventType: flushEventType,
+			err:       err,
+		})
+		l.pendingGroupCommitFns[i] = nil
+	}
+	l.pendingGroupCommitFns = l.pendingGroupCommitFns[:0]
+	l.pendingGroupCommitWrites = 0
 }
 
 func (l *commitLog) onFlush(err error) {
@@ -32,6 +32,7 @@ import (
 	"time"
 
 	"github.com/m3db/bitset"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3x/context"
@@ -133,6 +134,7 @@ func (w testWrite) assert(
 	require.Equal(t, w.series.UniqueIndex, series.UniqueIndex)
 	require.True(t, w.series.ID.Equal(series.ID), fmt.Sprintf("write ID '%s' does not match actual ID '%s'", w.series.ID.String(), series.ID.String()))
 	require.Equal(t, w.series.Shard, series.Shard)
+	require.Equal(t, w.series.CodecID, series.CodecID)
 
 	// ident.Tags.Equal will compare length
 	require.True(t, w.series.Tags.Equal(series.Tags))
@@ -369,6 +371,30 @@ func TestCommitLogWrite(t *testing.T) {
 	assertCommitLogWritesByIterating(t, commitLog, writes)
 }
 
+func TestCommitLogWriteCodecID(t *testing.T) {
+	opts, scope := newTestOptions(t, overrides{
+		strategy: StrategyWriteWait,
+	})
+	defer cleanup(t, opts)
+
+	commitLog := newTestCommitLog(t, opts)
+
+	series := testSeries(0, "foo.bar", ident.NewTags(ident.StringTag("name1", "val1")), 127)
+	series.CodecID = codec.DeltaOfDelta
+	writes := []testWrite{
+		{series, time.Now(), 123.456, xtime.Second, nil, nil},
+	}
+
+	// Call write sync
+	writeCommitLogs(t, scope, commitLog, writes).Wait()
+
+	// Close the commit log and consequently flush
+	require.NoError(t, commitLog.Close())
+
+	// Assert the codec is read back from the series metadata
+	assertCommitLogWritesByIterating(t, commitLog, writes)
+}
+
 func TestReadCommitLogMissingMetadata(t *testing.T) {
 	readConc := 4
 	// Make sure we're not leaking goroutines
@@ -561,6 +587,222 @@ func TestCommitLogWriteBehind(t *testing.T) {
 	assertCommitLogWritesByIterating(t, commitLog, writes)
 }
 
+func TestCommitLogWriteGroupCommit(t *testing.T) {
+	opts, scope := newTestOptions(t, overrides{
+		strategy: StrategyGroupCommit,
+	})
+	defer cleanup(t, opts)
+
+	commitLog := newTestCommitLog(t, opts)
+
+	writes := []testWrite{
+		{testSeries(0, "foo.bar", testTags1, 127), time.Now(), 123.456, xtime.Millisecond, nil, nil},
+		{testSeries(1, "foo.baz", testTags2, 150), time.Now(), 456.789, xtime.Millisecond, nil, nil},
+		{testSeries(2, "foo.qux", testTags3, 291), time.Now(), 789.123, xtime.Millisecond, nil, nil},
+	}
+
+	// Writes are acknowledged once fsync'd without waiting for a flush
+	writeCommitLogs(t, scope, commitLog, writes).Wait()
+
+	snapshot := scope.Snapshot()
+	fsyncs, ok := snapshot.Timers()[tally.KeyForPrefixedStringMap(
+		"commitlog.group-commit.fsync-latency", nil)]
+	require.True(t, ok)
+	require.True(t, len(fsyncs.Values()) > 0)
+
+	batchSizes, ok := snapshot.Histograms()[tally.KeyForPrefixedStringMap(
+		"commitlog.group-commit.batch-size", nil)]
+	require.True(t, ok)
+	var numBatches int64
+	for _, count := range batchSizes.Values() {
+		numBatches += count
+	}
+	require.Equal(t, int64(len(fsyncs.Values())), numBatches)
+
+	require.NoError(t, commitLog.Close())
+
+	// Assert writes occurred by reading the commit log
+	assertCommitLogWritesByIterating(t, commitLog, writes)
+}
+
+func TestCommitLogGroupCommitAcksFsyncError(t *testing.T) {
+	opts, scope := newTestOptions(t, overrides{
+		strategy: StrategyGroupCommit,
+	})
+	defer cleanup(t, opts)
+
+	commitLogI, err := NewCommitLog(opts)
+	require.NoError(t, err)
+	commitLog := commitLogI.(*commitLog)
+	writer := newMockCommitLogWriter()
+
+	var (
+		failSync int64
+		syncs    int64
+	)
+	writer.flushFn = func(sync bool) error {
+		if !sync {
+			return nil
+		}
+		atomic.AddInt64(&syncs, 1)
+		if atomic.LoadInt64(&failSync) == 1 {
+			return fmt.Errorf("fsync failed")
+		}
+		return nil
+	}
+
+	commitLog.newCommitLogWriterFn = func(
+		_ flushFn,
+		_ Options,
+	) commitLogWriter {
+		return writer
+	}
+
+	require.NoError(t, commitLog.Open())
+	atomic.StoreInt64(&failSync, 1)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	series := testSeries(0, "foo.bar", testTags1, 127)
+	datapoint := ts.Datapoint{Timestamp: time.Now(), Value: 123.456}
+	err = commitLog.Write(ctx, series, datapoint, xtime.Millisecond, nil)
+	require.Error(t, err)
+	require.Equal(t, "fsync failed", err.Error())
+
+	// One sync on open and one for the group commit
+	require.Equal(t, int64(2), atomic.LoadInt64(&syncs))
+
+	groupCommitErrors, ok := snapshotCounterValue(scope, "commitlog.group-commit.errors")
+	require.True(t, ok)
+	require.Equal(t, int64(1), groupCommitErrors.Value())
+
+	atomic.StoreInt64(&failSync, 0)
+	require.NoError(t, commitLog.Close())
+}
+
+func TestCommitLogGroupCommitAcksBeforeRotate(t *testing.T) {
+	opts, _ := newTestOptions(t, overrides{
+		strategy: StrategyGroupCommit,
+	})
+	defer cleanup(t, opts)
+
+	commitLogI, err := NewCommitLog(opts)
+	require.NoError(t, err)
+	commitLog := commitLogI.(*commitLog)
+	writer := newMockCommitLogWriter()
+
+	// Fail the fsync of the first file only.
+	var opens int
+	writer.openFn = func(start time.Time, duration time.Duration) (File, error) {
+		opens++
+		return File{}, nil
+	}
+	writer.flushFn = func(sync bool) error {
+		if sync && opens == 1 {
+			return fmt.Errorf("fsync failed")
+		}
+		return nil
+	}
+
+	commitLog.newCommitLogWriterFn = func(
+		_ flushFn,
+		_ Options,
+	) commitLogWriter {
+		return writer
+	}
+
+	// Drive the writes directly rather than opening the commit log so that
+	// the group commit batch is still pending when the writer expires.
+	_, err = commitLog.openWriter(time.Now())
+	require.NoError(t, err)
+
+	var results []error
+	newWrite := func(id uint64) commitLogWrite {
+		return commitLogWrite{
+			eventType: writeEventType,
+			write: writeOrWriteBatch{
+				write: ts.Write{
+					Series:    testSeries(id, "foo.bar", testTags1, 127),
+					Datapoint: ts.Datapoint{Timestamp: time.Now(), Value: 123.456},
+					Unit:      xtime.Millisecond,
+				},
+			},
+			callbackFn: func(r callbackResult) {
+				results = append(results, r.err)
+			},
+		}
+	}
+
+	singleBatch := make([]ts.BatchWrite, 1)
+	commitLog.handleWrite(newWrite(0), singleBatch)
+	require.Equal(t, 0, len(results))
+
+	// Expire the writer so that the next write rotates the commit log, the
+	// pending write must be acknowledged with the fsync of the first file.
+	commitLog.writerState.writerExpireAt = timeZero
+	commitLog.handleWrite(newWrite(1), singleBatch)
+	require.Equal(t, 2, opens)
+	require.Equal(t, 1, len(results))
+	require.Error(t, results[0])
+
+	commitLog.groupCommit()
+	require.Equal(t, 2, len(results))
+	require.NoError(t, results[1])
+}
+
+func TestCommitLogFailsAckOnWriteError(t *testing.T) {
+	for _, strategy := range []Strategy{StrategyWriteWait, StrategyGroupCommit} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			opts, _ := newTestOptions(t, overrides{
+				strategy: strategy,
+			})
+			defer cleanup(t, opts)
+
+			commitLogI, err := NewCommitLog(opts)
+			require.NoError(t, err)
+			commitLog := commitLogI.(*commitLog)
+			writer := newMockCommitLogWriter()
+			writer.writeFn = func(ts.Series, ts.Datapoint, xtime.Unit, ts.Annotation) error {
+				return fmt.Errorf("an error")
+			}
+
+			commitLog.newCommitLogWriterFn = func(
+				_ flushFn,
+				_ Options,
+			) commitLogWriter {
+				return writer
+			}
+
+			_, err = commitLog.openWriter(time.Now())
+			require.NoError(t, err)
+
+			var results []error
+			write := commitLogWrite{
+				eventType: writeEventType,
+				write: writeOrWriteBatch{
+					write: ts.Write{
+						Series:    testSeries(0, "foo.bar", testTags1, 127),
+						Datapoint: ts.Datapoint{Timestamp: time.Now(), Value: 123.456},
+						Unit:      xtime.Millisecond,
+					},
+				},
+				callbackFn: func(r callbackResult) {
+					results = append(results, r.err)
+				},
+			}
+
+			// The write must be failed rather than left pending for the
+			// next flush or fsync to acknowledge.
+			commitLog.handleWrite(write, make([]ts.BatchWrite, 1))
+			require.Equal(t, 1, len(results))
+			require.Error(t, results[0])
+			require.Equal(t, 0, len(commitLog.pendingFlushFns))
+			require.Equal(t, 0, len(commitLog.pendingGroupCommitFns))
+		})
+	}
+}
+
 func TestCommitLogWriteErrorOnClosed(t *testing.T) {
 	opts, _ := newTestOptions(t, overrides{})
 	defer cleanup(t, opts)
@@ -39,6 +39,38 @@ type File struct {
 	Index    int64
 }
 
+// SnapshotIdentifier returns the identifier that is recorded in a snapshot
+// metadata file to mark this file as the first commit log file whose writes
+// may not be captured by the snapshot.
+func (f File) SnapshotIdentifier() []byte {
+	return []byte(f.FilePath)
+}
+
+// IsBefore returns whether the commit log file was rotated out before the
+// other commit log file was opened.
+func (f File) IsBefore(other File) bool {
+	if f.Start.Equal(other.Start) {
+		return f.Index < other.Index
+	}
+	return f.Start.Before(other.Start)
+}
+
+// FileFromSnapshotIdentifier returns the commit log file recorded in a
+// snapshot metadata file, the duration of the returned file is unknown.
+func FileFromSnapshotIdentifier(id []byte) (File, error) {
+	filePath := string(id)
+	start, index, err := fs.TimeAndIndexFromCommitlogFilename(filePath)
+	if err != nil {
+		return File{}, err
+	}
+
+	return File{
+		FilePath: filePath,
+		Start:    start,
+		Index:    int64(index),
+	}, nil
+}
+
 // ReadLogInfo reads the commit log info out of a commitlog file
 func ReadLogInfo(filePath string, opts Options) (time.Time, time.Duration, int64, error) {
 	var fd *os.File
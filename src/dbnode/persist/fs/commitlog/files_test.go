@@ -21,8 +21,10 @@
 package commitlog
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
+	"path"
 	"strings"
 	"sync"
 	"testing"
@@ -72,6 +74,38 @@ func TestFiles(t *testing.T) {
 	}
 }
 
+func TestFileSnapshotIdentifierRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "commitlogs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		start      = time.Now().Truncate(10 * time.Minute)
+		filePath   = path.Join(fs.CommitLogsDirPath(dir), fmt.Sprintf("commitlog-%d-2.db", start.UnixNano()))
+		file       = File{FilePath: filePath, Start: start, Duration: 10 * time.Minute, Index: 2}
+		nextIndex  = File{Start: start, Index: 3}
+		nextStart  = File{Start: start.Add(10 * time.Minute), Index: 0}
+		prevStart  = File{Start: start.Add(-10 * time.Minute), Index: 5}
+		sameFileID = file.SnapshotIdentifier()
+	)
+
+	parsed, err := FileFromSnapshotIdentifier(sameFileID)
+	require.NoError(t, err)
+	require.Equal(t, filePath, parsed.FilePath)
+	require.True(t, start.Equal(parsed.Start))
+	require.Equal(t, int64(2), parsed.Index)
+
+	require.False(t, file.IsBefore(parsed))
+	require.False(t, parsed.IsBefore(file))
+	require.True(t, file.IsBefore(nextIndex))
+	require.True(t, file.IsBefore(nextStart))
+	require.True(t, prevStart.IsBefore(file))
+	require.False(t, nextIndex.IsBefore(file))
+
+	_, err = FileFromSnapshotIdentifier([]byte("not-a-commitlog"))
+	require.Error(t, err)
+}
+
 // createTestCommitLogFiles creates at least the specified number of commit log files
 // on disk with the appropriate block size. Commit log files will be valid and contain
 // readable metadata.
@@ -37,6 +37,10 @@ const (
 	// defaultStrategy is the default commit log write strategy
 	defaultStrategy = StrategyWriteBehind
 
+	// defaultGroupCommitMaxBatchSize is the default maximum number of writes
+	// acknowledged by a single fsync when using the group commit strategy
+	defaultGroupCommitMaxBatchSize = 1024
+
 	// defaultFlushInterval is the default commit log flush interval
 	defaultFlushInterval = time.Second
 
@@ -67,6 +71,7 @@ var (
 	errBlockSizePositive        = errors.New("block size must be a positive duration")
 	errReadConcurrencyPositive  = errors.New("read concurrency must be a positive integer")
 	errBacklogQueueChannelSize  = errors.New("read concurrency must be a positive integer")
+	errGroupCommitMaxBatchSize  = errors.New("group commit max batch size must be a positive integer")
 )
 
 type options struct {
@@ -75,6 +80,7 @@ type options struct {
 	blockSize               time.Duration
 	fsOpts                  fs.Options
 	strategy                Strategy
+	groupCommitMaxBatchSize int
 	flushSize               int
 	flushInterval           time.Duration
 	backlogQueueSize        int
@@ -92,6 +98,7 @@ func NewOptions() Options {
 		blockSize:               defaultBlockSize,
 		fsOpts:                  fs.NewOptions(),
 		strategy:                defaultStrategy,
+		groupCommitMaxBatchSize: defaultGroupCommitMaxBatchSize,
 		flushSize:               defaultFlushSize,
 		flushInterval:           defaultFlushInterval,
 		backlogQueueSize:        defaultBacklogQueueSize,
@@ -119,6 +126,14 @@ func (o *options) Validate() error {
 		return errReadConcurrencyPositive
 	}
 
+	if err := ValidateStrategy(o.Strategy()); err != nil {
+		return err
+	}
+
+	if o.GroupCommitMaxBatchSize() <= 0 {
+		return errGroupCommitMaxBatchSize
+	}
+
 	if float64(o.BacklogQueueSize())/float64(o.BacklogQueueChannelSize()) > MaximumQueueSizeQueueChannelSizeRatio {
 		return fmt.Errorf(
 			"BacklogQueueSize / BacklogQueueChannelSize ratio must be at least: %f, but was: %f",
@@ -178,6 +193,16 @@ func (o *options) Strategy() Strategy {
 	return o.strategy
 }
 
+func (o *options) SetGroupCommitMaxBatchSize(value int) Options {
+	opts := *o
+	opts.groupCommitMaxBatchSize = value
+	return &opts
+}
+
+func (o *options) GroupCommitMaxBatchSize() int {
+	return o.groupCommitMaxBatchSize
+}
+
 func (o *options) SetFlushSize(value int) Options {
 	opts := *o
 	opts.flushSize = value
@@ -450,6 +450,7 @@ func (r *reader) decodeAndHandleMetadata(
 		Namespace:   ident.BinaryID(namespace),
 		Shard:       decoded.Shard,
 		Tags:        tags,
+		CodecID:     decoded.CodecID,
 	}
 
 	metadataLookup[entry.Index] = seriesMetadata{
@@ -0,0 +1,88 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package commitlog
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	errStrategyUnspecified = errors.New("commit log strategy unspecified")
+)
+
+// ValidStrategies returns the valid commit log strategies.
+func ValidStrategies() []Strategy {
+	return []Strategy{StrategyWriteWait, StrategyWriteBehind, StrategyGroupCommit}
+}
+
+func (s Strategy) String() string {
+	switch s {
+	case StrategyWriteWait:
+		return "write_wait"
+	case StrategyWriteBehind:
+		return "write_behind"
+	case StrategyGroupCommit:
+		return "group_commit"
+	}
+	return "unknown"
+}
+
+// ValidateStrategy validates a commit log strategy.
+func ValidateStrategy(v Strategy) error {
+	for _, valid := range ValidStrategies() {
+		if valid == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid commit log Strategy '%d' valid types are: %v",
+		int(v), ValidStrategies())
+}
+
+// ParseStrategy parses a Strategy from a string.
+func ParseStrategy(str string) (Strategy, error) {
+	var r Strategy
+	if str == "" {
+		return r, errStrategyUnspecified
+	}
+	for _, valid := range ValidStrategies() {
+		if str == valid.String() {
+			r = valid
+			return r, nil
+		}
+	}
+	return r, fmt.Errorf("invalid commit log Strategy '%s' valid types are: %v",
+		str, ValidStrategies())
+}
+
+// UnmarshalYAML unmarshals a Strategy into a valid type from string.
+func (s *Strategy) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	r, err := ParseStrategy(str)
+	if err != nil {
+		return err
+	}
+	*s = r
+	return nil
+}
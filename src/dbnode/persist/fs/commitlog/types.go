@@ -46,6 +46,11 @@ const (
 	// for the buffered commit log chunk that contains a write to flush
 	// before acknowledging a write
 	StrategyWriteBehind
+
+	// StrategyGroupCommit describes the strategy that batches the writes
+	// of concurrent writers, fsyncs the commit log once per batch and
+	// acknowledges each write only after the fsync of its batch completes
+	StrategyGroupCommit
 )
 
 // CommitLog provides a synchronized commit log
@@ -142,6 +147,14 @@ type Options interface {
 	// Strategy returns the strategy.
 	Strategy() Strategy
 
+	// SetGroupCommitMaxBatchSize sets the maximum number of writes that are
+	// acknowledged by a single fsync when using the group commit strategy.
+	SetGroupCommitMaxBatchSize(value int) Options
+
+	// GroupCommitMaxBatchSize returns the maximum number of writes that are
+	// acknowledged by a single fsync when using the group commit strategy.
+	GroupCommitMaxBatchSize() int
+
 	// SetFlushInterval sets the flush interval.
 	SetFlushInterval(value time.Duration) Options
 
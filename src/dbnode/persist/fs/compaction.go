@@ -0,0 +1,217 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/digest"
+	"github.com/m3db/m3/src/dbnode/persist/fs/msgpack"
+	"github.com/m3db/m3/src/dbnode/persist/schema"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+)
+
+// DataFileSetRange describes the complete flushed data fileset holding the
+// data for a block, compacted filesets span several adjacent blocks.
+type DataFileSetRange struct {
+	FilePathPrefix string
+	BlockStart     time.Time
+	BlockSize      time.Duration
+}
+
+// Range returns the time range covered by the fileset.
+func (r DataFileSetRange) Range() xtime.Range {
+	return xtime.Range{Start: r.BlockStart, End: r.BlockStart.Add(r.BlockSize)}
+}
+
+// CompactedBlockSize returns the block size of compacted filesets given the
+// namespace block size, zero if fileset compaction is disabled.
+func CompactedBlockSize(opts Options, blockSize time.Duration) time.Duration {
+	n := opts.FileSetCompactionBlocks()
+	if n < 2 {
+		return 0
+	}
+	return time.Duration(n) * blockSize
+}
+
+// CoveringDataFileSet returns the complete flushed data fileset holding the
+// data for the given namespace, shard, and block start, resolving compacted
+// filesets that span the block as well as filesets held by the fileset tier.
+// If no fileset holds the block then a range for the block in the primary
+// file path prefix is returned alongside false.
+func CoveringDataFileSet(
+	opts Options,
+	namespace ident.ID,
+	shard uint32,
+	blockStart time.Time,
+	blockSize time.Duration,
+) (DataFileSetRange, bool, error) {
+	notFound := DataFileSetRange{
+		FilePathPrefix: opts.FilePathPrefix(),
+		BlockStart:     blockStart,
+		BlockSize:      blockSize,
+	}
+
+	compactedBlockSize := CompactedBlockSize(opts, blockSize)
+	if compactedBlockSize == 0 {
+		filePathPrefix, exists, err := DataFileSetPathPrefix(opts, namespace,
+			shard, blockStart)
+		if err != nil || !exists {
+			return notFound, false, err
+		}
+		return DataFileSetRange{
+			FilePathPrefix: filePathPrefix,
+			BlockStart:     blockStart,
+			BlockSize:      blockSize,
+		}, true, nil
+	}
+
+	// Compacted filesets always start at a multiple of the compacted block
+	// size so only the fileset starting the compacted range the block falls
+	// in can span it. It is checked first since the filesets it replaced may
+	// not have been deleted yet.
+	candidates := []time.Time{blockStart.Truncate(compactedBlockSize)}
+	if !candidates[0].Equal(blockStart) {
+		candidates = append(candidates, blockStart)
+	}
+	for _, start := range candidates {
+		filePathPrefix, exists, err := DataFileSetPathPrefix(opts, namespace,
+			shard, start)
+		if err != nil {
+			return notFound, false, err
+		}
+		if !exists {
+			continue
+		}
+
+		info, err := ReadDataInfoFile(opts, filePathPrefix, namespace, shard, start)
+		if err != nil {
+			return notFound, false, err
+		}
+
+		result := DataFileSetRange{
+			FilePathPrefix: filePathPrefix,
+			BlockStart:     start,
+			BlockSize:      time.Duration(info.BlockSize),
+		}
+		if blockStart.Before(result.Range().End) {
+			return result, true, nil
+		}
+	}
+	return notFound, false, nil
+}
+
+// ReadDataInfoFile reads and validates the info file of the complete flushed
+// data fileset for the given namespace, shard, and block start.
+func ReadDataInfoFile(
+	opts Options,
+	filePathPrefix string,
+	namespace ident.ID,
+	shard uint32,
+	blockStart time.Time,
+) (schema.IndexInfo, error) {
+	var (
+		shardDir           = ShardDataDirPath(filePathPrefix, namespace, shard)
+		checkpointFilePath = filesetPathFromTime(shardDir, blockStart, checkpointFileSuffix)
+		digestFilePath     = filesetPathFromTime(shardDir, blockStart, digestFileSuffix)
+		infoFilePath       = filesetPathFromTime(shardDir, blockStart, infoFileSuffix)
+		readerBufferSize   = opts.InfoReaderBufferSize()
+	)
+
+	checkpointFd, err := os.Open(checkpointFilePath)
+	if err != nil {
+		return schema.IndexInfo{}, err
+	}
+
+	// Read digest of digests from the checkpoint file
+	digestBuf := digest.NewBuffer()
+	expectedDigestOfDigest, err := digestBuf.ReadDigestFromFile(checkpointFd)
+	closeErr := checkpointFd.Close()
+	if err != nil {
+		return schema.IndexInfo{}, err
+	}
+	if closeErr != nil {
+		return schema.IndexInfo{}, closeErr
+	}
+
+	// Read and validate the digest file
+	digestData, err := readAndValidate(
+		digestFilePath, readerBufferSize, expectedDigestOfDigest)
+	if err != nil {
+		return schema.IndexInfo{}, err
+	}
+
+	// Read and validate the info file
+	expectedInfoDigest := digest.ToBuffer(digestData).ReadDigest()
+	infoData, err := readAndValidate(
+		infoFilePath, readerBufferSize, expectedInfoDigest)
+	if err != nil {
+		return schema.IndexInfo{}, err
+	}
+
+	decoder := msgpack.NewDecoder(opts.DecodingOptions())
+	decoder.Reset(msgpack.NewDecoderStream(infoData))
+	info, err := decoder.DecodeIndexInfo()
+	if err != nil {
+		return schema.IndexInfo{}, fmt.Errorf(
+			"error decoding info file for blockStart %d: %v", blockStart.Unix(), err)
+	}
+	return info, nil
+}
+
+// DeleteCompactedDataFileSets deletes the flushed data filesets for the given
+// namespace and shard that are spanned by a compacted fileset, from both the
+// primary file path prefix and the fileset tier.
+func DeleteCompactedDataFileSets(
+	opts Options,
+	namespace ident.ID,
+	shard uint32,
+	compacted DataFileSetRange,
+	blockSize time.Duration,
+) error {
+	filePathPrefixes := []string{opts.FilePathPrefix()}
+	if tier := opts.FileSetTier(); tier != nil {
+		filePathPrefixes = append(filePathPrefixes, tier.FilePathPrefix())
+	}
+
+	end := compacted.BlockStart.Add(compacted.BlockSize)
+	for _, filePathPrefix := range filePathPrefixes {
+		for t := compacted.BlockStart; t.Before(end); t = t.Add(blockSize) {
+			if filePathPrefix == compacted.FilePathPrefix && t.Equal(compacted.BlockStart) {
+				continue
+			}
+			fileset, ok, err := FileSetAt(filePathPrefix, namespace, shard, t)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			if err := DeleteFiles(fileset.AbsoluteFilepaths); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/persist"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestCompactedData(
+	t *testing.T,
+	filePathPrefix string,
+	shard uint32,
+	blockStart time.Time,
+	blockSize time.Duration,
+) {
+	w := newTestWriter(t, filePathPrefix)
+	err := w.Open(DataWriterOpenOptions{
+		Identifier: FileSetFileIdentifier{
+			Namespace:  testNs1ID,
+			Shard:      shard,
+			BlockStart: blockStart,
+		},
+		BlockSize:   blockSize,
+		FileSetType: persist.FileSetFlushType,
+	})
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+}
+
+func TestCoveringDataFileSetCompactionDisabled(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	blockStart := testWriterStart.Truncate(testBlockSize)
+	w := newTestWriter(t, dir)
+	writeTestData(t, w, 0, blockStart, nil, persist.FileSetFlushType)
+
+	opts := testDefaultOpts.SetFilePathPrefix(dir)
+	fileSet, ok, err := CoveringDataFileSet(opts, testNs1ID, 0, blockStart, testBlockSize)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, DataFileSetRange{
+		FilePathPrefix: dir,
+		BlockStart:     blockStart,
+		BlockSize:      testBlockSize,
+	}, fileSet)
+
+	next := blockStart.Add(testBlockSize)
+	fileSet, ok, err = CoveringDataFileSet(opts, testNs1ID, 0, next, testBlockSize)
+	require.NoError(t, err)
+	require.False(t, ok)
+	assert.True(t, next.Equal(fileSet.BlockStart))
+}
+
+func TestCoveringDataFileSetResolvesCompacted(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	var (
+		compactedBlockSize = 3 * testBlockSize
+		compactedStart     = testWriterStart.Truncate(compactedBlockSize)
+	)
+	opts := testDefaultOpts.
+		SetFilePathPrefix(dir).
+		SetFileSetCompactionBlocks(3).
+		SetFileSetCompactionAge(testBlockSize)
+	require.NoError(t, opts.Validate())
+	writeTestCompactedData(t, dir, 0, compactedStart, compactedBlockSize)
+
+	// A fileset left over from before compacting is superseded by the
+	// compacted fileset.
+	w := newTestWriter(t, dir)
+	writeTestData(t, w, 0, compactedStart.Add(testBlockSize), nil, persist.FileSetFlushType)
+
+	for i := 0; i < 3; i++ {
+		blockStart := compactedStart.Add(time.Duration(i) * testBlockSize)
+		fileSet, ok, err := CoveringDataFileSet(opts, testNs1ID, 0, blockStart, testBlockSize)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.True(t, compactedStart.Equal(fileSet.BlockStart))
+		assert.Equal(t, compactedBlockSize, fileSet.BlockSize)
+	}
+
+	// Blocks after the compacted range are not covered.
+	next := compactedStart.Add(compactedBlockSize)
+	_, ok, err := CoveringDataFileSet(opts, testNs1ID, 0, next, testBlockSize)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestDeleteCompactedDataFileSets(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	var (
+		filePathPrefix     = filepath.Join(dir, "primary")
+		tierPathPrefix     = filepath.Join(dir, "tier")
+		compactedBlockSize = 3 * testBlockSize
+		compactedStart     = testWriterStart.Truncate(compactedBlockSize)
+	)
+	opts := testDefaultOpts.
+		SetFilePathPrefix(filePathPrefix).
+		SetFileSetTier(NewLocalDirectoryTier(tierPathPrefix, testDefaultOpts)).
+		SetFileSetTierMigrationAge(testBlockSize)
+	for i := 0; i < 3; i++ {
+		blockStart := compactedStart.Add(time.Duration(i) * testBlockSize)
+		w := newTestWriter(t, tierPathPrefix)
+		writeTestData(t, w, 0, blockStart, nil, persist.FileSetFlushType)
+		if i > 0 {
+			w = newTestWriter(t, filePathPrefix)
+			writeTestData(t, w, 0, blockStart, nil, persist.FileSetFlushType)
+		}
+	}
+	writeTestCompactedData(t, filePathPrefix, 0, compactedStart, compactedBlockSize)
+
+	compacted := DataFileSetRange{
+		FilePathPrefix: filePathPrefix,
+		BlockStart:     compactedStart,
+		BlockSize:      compactedBlockSize,
+	}
+	require.NoError(t, DeleteCompactedDataFileSets(opts, testNs1ID, 0,
+		compacted, testBlockSize))
+
+	for i := 0; i < 3; i++ {
+		blockStart := compactedStart.Add(time.Duration(i) * testBlockSize)
+		exists, err := DataFileSetExistsAt(tierPathPrefix, testNs1ID, 0, blockStart)
+		require.NoError(t, err)
+		assert.False(t, exists)
+		exists, err = DataFileSetExistsAt(filePathPrefix, testNs1ID, 0, blockStart)
+		require.NoError(t, err)
+		assert.Equal(t, i == 0, exists)
+	}
+}
+
+func TestOptionsValidateFileSetCompactionAge(t *testing.T) {
+	opts := testDefaultOpts.SetFileSetCompactionBlocks(2)
+	assert.Equal(t, errCompactionAge, opts.Validate())
+}
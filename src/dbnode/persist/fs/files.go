@@ -51,6 +51,8 @@ const (
 	dataDirName       = "data"
 	indexDirName      = "index"
 	snapshotDirName   = "snapshots"
+	stagingDirName    = "staging"
+	tombstonesDirName = "tombstones"
 	commitLogsDirName = "commitlogs"
 
 	commitLogComponentPosition    = 2
@@ -1106,6 +1108,12 @@ func DataDirPath(prefix string) string {
 	return path.Join(prefix, dataDirName)
 }
 
+// StagingDirPath returns the file path prefix that data filesets replacing
+// existing filesets are written to before they are committed.
+func StagingDirPath(prefix string) string {
+	return path.Join(prefix, stagingDirName)
+}
+
 // SnapshotDirPath returns the path to the snapshot directory belong to a db
 func SnapshotDirPath(prefix string) string {
 	return path.Join(prefix, snapshotDirName)
@@ -1148,6 +1156,11 @@ func ShardSnapshotsDirPath(prefix string, namespace ident.ID, shard uint32) stri
 	return path.Join(namespacePath, strconv.Itoa(int(shard)))
 }
 
+// ShardTombstonesDirPath returns the path to the tombstones directory for a given shard.
+func ShardTombstonesDirPath(prefix string, namespace ident.ID, shard uint32) string {
+	return path.Join(prefix, tombstonesDirName, namespace.String(), strconv.Itoa(int(shard)))
+}
+
 // CommitLogsDirPath returns the path to commit logs.
 func CommitLogsDirPath(prefix string) string {
 	return path.Join(prefix, commitLogsDirName)
@@ -1202,6 +1215,10 @@ func NextSnapshotMetadataFileIndex(opts Options) (int64, error) {
 		return 0, err
 	}
 
+	if len(snapshotMetadataFiles) == 0 {
+		return 0, nil
+	}
+
 	lastSnapshotMetadataFile := snapshotMetadataFiles[len(snapshotMetadataFiles)-1]
 	return lastSnapshotMetadataFile.ID.Index + 1, nil
 }
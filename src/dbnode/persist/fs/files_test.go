@@ -682,6 +682,11 @@ func TestNextSnapshotMetadataFileIndex(t *testing.T) {
 	require.Empty(t, errorsWithpaths)
 	require.Empty(t, metadataFiles)
 
+	// The first snapshot metadata file should have index zero.
+	nextIdx, err := NextSnapshotMetadataFileIndex(opts)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), nextIdx)
+
 	writer := NewSnapshotMetadataWriter(opts)
 	// Write out a bunch of metadata files along with their corresponding checkpoints.
 	for i := 0; i < numMetadataFiles; i++ {
@@ -700,7 +705,7 @@ func TestNextSnapshotMetadataFileIndex(t *testing.T) {
 		require.NoError(t, err)
 	}
 
-	nextIdx, err := NextSnapshotMetadataFileIndex(opts)
+	nextIdx, err = NextSnapshotMetadataFileIndex(opts)
 	require.NoError(t, err)
 	// Snapshot metadata file indices are zero-based so if we wrote out
 	// numMetadataFiles, then the last index should be numMetadataFiles-1
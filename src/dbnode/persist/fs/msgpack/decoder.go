@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/schema"
 
@@ -236,6 +237,16 @@ func (dec *Decoder) decodeIndexInfo() schema.IndexInfo {
 		opts.override = true
 		opts.numExpectedMinFields = 8
 		opts.numExpectedCurrFields = 8
+	} else if dec.legacy.decodeLegacyIndexInfoVersion == legacyEncodingIndexVersionV3 {
+		// V3 had 9 fields.
+		opts.override = true
+		opts.numExpectedMinFields = 9
+		opts.numExpectedCurrFields = 9
+	} else if dec.legacy.decodeLegacyIndexInfoVersion == legacyEncodingIndexVersionV4 {
+		// V4 had 10 fields.
+		opts.override = true
+		opts.numExpectedMinFields = 10
+		opts.numExpectedCurrFields = 10
 	}
 
 	numFieldsToSkip, actual, ok := dec.checkNumFieldsFor(indexInfoType, opts)
@@ -270,6 +281,24 @@ func (dec *Decoder) decodeIndexInfo() schema.IndexInfo {
 	// Decode fields added in V3.
 	indexInfo.SnapshotID, _, _ = dec.decodeBytes()
 
+	// At this point if its a V3 file we've decoded all the available fields.
+	if dec.legacy.decodeLegacyIndexInfoVersion == legacyEncodingIndexVersionV3 || actual < 10 {
+		dec.skip(numFieldsToSkip)
+		return indexInfo
+	}
+
+	// Decode fields added in V4.
+	indexInfo.CodecID = codec.ID(dec.decodeVarint())
+
+	// At this point if its a V4 file we've decoded all the available fields.
+	if dec.legacy.decodeLegacyIndexInfoVersion == legacyEncodingIndexVersionV4 || actual < 11 {
+		dec.skip(numFieldsToSkip)
+		return indexInfo
+	}
+
+	// Decode fields added in V5.
+	indexInfo.ExpiredSeriesRetentionPeriod = dec.decodeVarint()
+
 	dec.skip(numFieldsToSkip)
 	return indexInfo
 }
@@ -399,7 +428,7 @@ func (dec *Decoder) decodeLogEntry() schema.LogEntry {
 }
 
 func (dec *Decoder) decodeLogMetadata() schema.LogMetadata {
-	numFieldsToSkip, _, ok := dec.checkNumFieldsFor(logMetadataType, checkNumFieldsOptions{})
+	numFieldsToSkip, actual, ok := dec.checkNumFieldsFor(logMetadataType, checkNumFieldsOptions{})
 	if !ok {
 		return emptyLogMetadata
 	}
@@ -408,6 +437,10 @@ func (dec *Decoder) decodeLogMetadata() schema.LogMetadata {
 	logMetadata.Namespace, _, _ = dec.decodeBytes()
 	logMetadata.Shard = uint32(dec.decodeVarUint())
 	logMetadata.EncodedTags, _, _ = dec.decodeBytes()
+	// Metadata written before the codec ID was added declared 3 fields.
+	if actual >= 4 {
+		logMetadata.CodecID = codec.ID(dec.decodeVarUint())
+	}
 	dec.skip(numFieldsToSkip)
 	if dec.err != nil {
 		return emptyLogMetadata
@@ -58,6 +58,8 @@ type legacyEncodingIndexInfoVersion int
 const (
 	// List in reverse order to ensure default value is current version.
 	legacyEncodingIndexVersionCurrent legacyEncodingIndexInfoVersion = iota
+	legacyEncodingIndexVersionV4
+	legacyEncodingIndexVersionV3
 	legacyEncodingIndexVersionV2
 	legacyEncodingIndexVersionV1
 )
@@ -123,8 +125,12 @@ func (enc *Encoder) EncodeIndexInfo(info schema.IndexInfo) error {
 		enc.encodeIndexInfoV1(info)
 	} else if enc.legacy.encodeLegacyIndexInfoVersion == legacyEncodingIndexVersionV2 {
 		enc.encodeIndexInfoV2(info)
-	} else {
+	} else if enc.legacy.encodeLegacyIndexInfoVersion == legacyEncodingIndexVersionV3 {
 		enc.encodeIndexInfoV3(info)
+	} else if enc.legacy.encodeLegacyIndexInfoVersion == legacyEncodingIndexVersionV4 {
+		enc.encodeIndexInfoV4(info)
+	} else {
+		enc.encodeIndexInfoV5(info)
 	}
 	return enc.err
 }
@@ -211,7 +217,40 @@ func (enc *Encoder) encodeIndexInfoV2(info schema.IndexInfo) {
 	enc.encodeVarintFn(int64(info.FileType))
 }
 
+// We only keep this method around for the sake of testing
+// backwards-compatbility.
 func (enc *Encoder) encodeIndexInfoV3(info schema.IndexInfo) {
+	// Manually encode num fields for testing purposes.
+	enc.encodeArrayLenFn(9) // V3 had 9 fields.
+	enc.encodeVarintFn(info.BlockStart)
+	enc.encodeVarintFn(info.BlockSize)
+	enc.encodeVarintFn(info.Entries)
+	enc.encodeVarintFn(info.MajorVersion)
+	enc.encodeIndexSummariesInfo(info.Summaries)
+	enc.encodeIndexBloomFilterInfo(info.BloomFilter)
+	enc.encodeVarintFn(info.SnapshotTime)
+	enc.encodeVarintFn(int64(info.FileType))
+	enc.encodeBytesFn(info.SnapshotID)
+}
+
+// We only keep this method around for the sake of testing
+// backwards-compatbility.
+func (enc *Encoder) encodeIndexInfoV4(info schema.IndexInfo) {
+	// Manually encode num fields for testing purposes.
+	enc.encodeArrayLenFn(10) // V4 had 10 fields.
+	enc.encodeVarintFn(info.BlockStart)
+	enc.encodeVarintFn(info.BlockSize)
+	enc.encodeVarintFn(info.Entries)
+	enc.encodeVarintFn(info.MajorVersion)
+	enc.encodeIndexSummariesInfo(info.Summaries)
+	enc.encodeIndexBloomFilterInfo(info.BloomFilter)
+	enc.encodeVarintFn(info.SnapshotTime)
+	enc.encodeVarintFn(int64(info.FileType))
+	enc.encodeBytesFn(info.SnapshotID)
+	enc.encodeVarintFn(int64(info.CodecID))
+}
+
+func (enc *Encoder) encodeIndexInfoV5(info schema.IndexInfo) {
 	enc.encodeNumObjectFieldsForFn(indexInfoType)
 	enc.encodeVarintFn(info.BlockStart)
 	enc.encodeVarintFn(info.BlockSize)
@@ -222,6 +261,8 @@ func (enc *Encoder) encodeIndexInfoV3(info schema.IndexInfo) {
 	enc.encodeVarintFn(info.SnapshotTime)
 	enc.encodeVarintFn(int64(info.FileType))
 	enc.encodeBytesFn(info.SnapshotID)
+	enc.encodeVarintFn(int64(info.CodecID))
+	enc.encodeVarintFn(info.ExpiredSeriesRetentionPeriod)
 }
 
 func (enc *Encoder) encodeIndexSummariesInfo(info schema.IndexSummariesInfo) {
@@ -290,6 +331,10 @@ func (enc *Encoder) encodeLogMetadata(metadata schema.LogMetadata) {
 	enc.encodeBytesFn(metadata.Namespace)
 	enc.encodeVarUintFn(uint64(metadata.Shard))
 	enc.encodeBytesFn(metadata.EncodedTags)
+	// NB: the log metadata has always encoded one more field (the encoded
+	// tags) than it declared, so declaring 4 fields now that the codec ID is
+	// encoded as the fifth field means older binaries will skip over it.
+	enc.encodeVarUintFn(uint64(metadata.CodecID))
 }
 
 func (enc *Encoder) encodeRootObject(version int, objType objectType) {
@@ -79,6 +79,7 @@ func EncodeLogMetadataFast(b []byte, entry schema.LogMetadata) ([]byte, error) {
 	b = encodeBytes(b, entry.Namespace)
 	b = encodeVarUint64(b, uint64(entry.Shard))
 	b = encodeBytes(b, entry.EncodedTags)
+	b = encodeVarUint64(b, uint64(entry.CodecID))
 
 	return b, nil
 }
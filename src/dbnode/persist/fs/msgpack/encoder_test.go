@@ -81,6 +81,8 @@ func testExpectedResultForIndexInfo(t *testing.T, indexInfo schema.IndexInfo) []
 		indexInfo.SnapshotTime,
 		int64(indexInfo.FileType),
 		indexInfo.SnapshotID,
+		int64(indexInfo.CodecID),
+		indexInfo.ExpiredSeriesRetentionPeriod,
 	}
 }
 
@@ -145,6 +147,7 @@ func testExpectedResultForLogMetadata(t *testing.T, logMetadata schema.LogMetada
 		logMetadata.Namespace,
 		uint64(logMetadata.Shard),
 		logMetadata.EncodedTags,
+		uint64(logMetadata.CodecID),
 	}
 }
 
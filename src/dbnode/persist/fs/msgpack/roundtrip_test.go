@@ -24,6 +24,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/schema"
 
@@ -43,9 +44,11 @@ var (
 			NumElementsM: 2075674,
 			NumHashesK:   7,
 		},
-		SnapshotTime: time.Now().UnixNano(),
-		FileType:     persist.FileSetSnapshotType,
-		SnapshotID:   []byte("some_bytes"),
+		SnapshotTime:                 time.Now().UnixNano(),
+		FileType:                     persist.FileSetSnapshotType,
+		SnapshotID:                   []byte("some_bytes"),
+		CodecID:                      codec.DeltaOfDelta,
+		ExpiredSeriesRetentionPeriod: int64(24 * time.Hour),
 	}
 
 	testIndexEntry = schema.IndexEntry{
@@ -84,6 +87,7 @@ var (
 		Namespace:   []byte("testNamespace"),
 		Shard:       123,
 		EncodedTags: []byte("testLogMetadataTags"),
+		CodecID:     codec.XOR,
 	}
 )
 
@@ -116,14 +120,20 @@ func TestIndexInfoRoundTripBackwardsCompatibilityV1(t *testing.T) {
 		currSnapshotTime = testIndexInfo.SnapshotTime
 		currFileType     = testIndexInfo.FileType
 		currSnapshotID   = testIndexInfo.SnapshotID
+		currCodecID      = testIndexInfo.CodecID
+		currExpired      = testIndexInfo.ExpiredSeriesRetentionPeriod
 	)
 	testIndexInfo.SnapshotTime = 0
 	testIndexInfo.FileType = 0
 	testIndexInfo.SnapshotID = nil
+	testIndexInfo.CodecID = 0
+	testIndexInfo.ExpiredSeriesRetentionPeriod = 0
 	defer func() {
 		testIndexInfo.SnapshotTime = currSnapshotTime
 		testIndexInfo.FileType = currFileType
 		testIndexInfo.SnapshotID = currSnapshotID
+		testIndexInfo.CodecID = currCodecID
+		testIndexInfo.ExpiredSeriesRetentionPeriod = currExpired
 	}()
 
 	enc.EncodeIndexInfo(testIndexInfo)
@@ -148,6 +158,8 @@ func TestIndexInfoRoundTripForwardsCompatibilityV2(t *testing.T) {
 		currSnapshotTime = testIndexInfo.SnapshotTime
 		currFileType     = testIndexInfo.FileType
 		currSnapshotID   = testIndexInfo.SnapshotID
+		currCodecID      = testIndexInfo.CodecID
+		currExpired      = testIndexInfo.ExpiredSeriesRetentionPeriod
 	)
 
 	enc.EncodeIndexInfo(testIndexInfo)
@@ -157,10 +169,14 @@ func TestIndexInfoRoundTripForwardsCompatibilityV2(t *testing.T) {
 	testIndexInfo.SnapshotTime = 0
 	testIndexInfo.FileType = 0
 	testIndexInfo.SnapshotID = nil
+	testIndexInfo.CodecID = 0
+	testIndexInfo.ExpiredSeriesRetentionPeriod = 0
 	defer func() {
 		testIndexInfo.SnapshotTime = currSnapshotTime
 		testIndexInfo.FileType = currFileType
 		testIndexInfo.SnapshotID = currSnapshotID
+		testIndexInfo.CodecID = currCodecID
+		testIndexInfo.ExpiredSeriesRetentionPeriod = currExpired
 	}()
 
 	dec.Reset(NewDecoderStream(enc.Bytes()))
@@ -185,14 +201,20 @@ func TestIndexInfoRoundTripBackwardsCompatibilityV2(t *testing.T) {
 		currSnapshotTime = testIndexInfo.SnapshotTime
 		currFileType     = testIndexInfo.FileType
 		currSnapshotID   = testIndexInfo.SnapshotID
+		currCodecID      = testIndexInfo.CodecID
+		currExpired      = testIndexInfo.ExpiredSeriesRetentionPeriod
 	)
 	testIndexInfo.SnapshotTime = 0
 	testIndexInfo.FileType = 0
 	testIndexInfo.SnapshotID = nil
+	testIndexInfo.CodecID = 0
+	testIndexInfo.ExpiredSeriesRetentionPeriod = 0
 	defer func() {
 		testIndexInfo.SnapshotTime = currSnapshotTime
 		testIndexInfo.FileType = currFileType
 		testIndexInfo.SnapshotID = currSnapshotID
+		testIndexInfo.CodecID = currCodecID
+		testIndexInfo.ExpiredSeriesRetentionPeriod = currExpired
 	}()
 
 	enc.EncodeIndexInfo(testIndexInfo)
@@ -213,15 +235,139 @@ func TestIndexInfoRoundTripForwardsCompatibilityV3(t *testing.T) {
 	// Set the default values on the fields that did not exist in V2
 	// and then restore them at the end of the test - This is required
 	// because the old decoder won't read the new fields.
-	currSnapshotID := testIndexInfo.SnapshotID
+	var (
+		currSnapshotID = testIndexInfo.SnapshotID
+		currCodecID    = testIndexInfo.CodecID
+		currExpired    = testIndexInfo.ExpiredSeriesRetentionPeriod
+	)
 
 	enc.EncodeIndexInfo(testIndexInfo)
 
 	// Make sure to zero them before we compare, but after we have
 	// encoded the data.
 	testIndexInfo.SnapshotID = nil
+	testIndexInfo.CodecID = 0
+	testIndexInfo.ExpiredSeriesRetentionPeriod = 0
 	defer func() {
 		testIndexInfo.SnapshotID = currSnapshotID
+		testIndexInfo.CodecID = currCodecID
+		testIndexInfo.ExpiredSeriesRetentionPeriod = currExpired
+	}()
+
+	dec.Reset(NewDecoderStream(enc.Bytes()))
+	res, err := dec.DecodeIndexInfo()
+	require.NoError(t, err)
+	require.Equal(t, testIndexInfo, res)
+}
+
+// Make sure the V4 decoding code can handle the V3 file format.
+func TestIndexInfoRoundTripBackwardsCompatibilityV3(t *testing.T) {
+	var (
+		opts = legacyEncodingOptions{encodeLegacyIndexInfoVersion: legacyEncodingIndexVersionV3}
+		enc  = newEncoder(opts)
+		dec  = newDecoder(opts, nil)
+	)
+
+	// Set the default values on the fields that did not exist in V3,
+	// and then restore them at the end of the test - This is required
+	// because the new decoder won't try and read the new fields from
+	// the old file format.
+	var (
+		currCodecID = testIndexInfo.CodecID
+		currExpired = testIndexInfo.ExpiredSeriesRetentionPeriod
+	)
+	testIndexInfo.CodecID = 0
+	testIndexInfo.ExpiredSeriesRetentionPeriod = 0
+	defer func() {
+		testIndexInfo.CodecID = currCodecID
+		testIndexInfo.ExpiredSeriesRetentionPeriod = currExpired
+	}()
+
+	enc.EncodeIndexInfo(testIndexInfo)
+	dec.Reset(NewDecoderStream(enc.Bytes()))
+	res, err := dec.DecodeIndexInfo()
+	require.NoError(t, err)
+	require.Equal(t, testIndexInfo, res)
+}
+
+// Make sure the V3 decoder code can handle the V4 file format.
+func TestIndexInfoRoundTripForwardsCompatibilityV4(t *testing.T) {
+	var (
+		opts = legacyEncodingOptions{decodeLegacyIndexInfoVersion: legacyEncodingIndexVersionV3}
+		enc  = newEncoder(opts)
+		dec  = newDecoder(opts, nil)
+	)
+
+	// Set the default values on the fields that did not exist in V3
+	// and then restore them at the end of the test - This is required
+	// because the old decoder won't read the new fields.
+	var (
+		currCodecID = testIndexInfo.CodecID
+		currExpired = testIndexInfo.ExpiredSeriesRetentionPeriod
+	)
+
+	enc.EncodeIndexInfo(testIndexInfo)
+
+	// Make sure to zero them before we compare, but after we have
+	// encoded the data.
+	testIndexInfo.CodecID = 0
+	testIndexInfo.ExpiredSeriesRetentionPeriod = 0
+	defer func() {
+		testIndexInfo.CodecID = currCodecID
+		testIndexInfo.ExpiredSeriesRetentionPeriod = currExpired
+	}()
+
+	dec.Reset(NewDecoderStream(enc.Bytes()))
+	res, err := dec.DecodeIndexInfo()
+	require.NoError(t, err)
+	require.Equal(t, testIndexInfo, res)
+}
+
+// Make sure the V5 decoding code can handle the V4 file format.
+func TestIndexInfoRoundTripBackwardsCompatibilityV4(t *testing.T) {
+	var (
+		opts = legacyEncodingOptions{encodeLegacyIndexInfoVersion: legacyEncodingIndexVersionV4}
+		enc  = newEncoder(opts)
+		dec  = newDecoder(opts, nil)
+	)
+
+	// Set the default values on the fields that did not exist in V4,
+	// and then restore them at the end of the test - This is required
+	// because the new decoder won't try and read the new fields from
+	// the old file format.
+	currExpired := testIndexInfo.ExpiredSeriesRetentionPeriod
+	testIndexInfo.ExpiredSeriesRetentionPeriod = 0
+	defer func() {
+		testIndexInfo.ExpiredSeriesRetentionPeriod = currExpired
+	}()
+
+	enc.EncodeIndexInfo(testIndexInfo)
+	dec.Reset(NewDecoderStream(enc.Bytes()))
+	res, err := dec.DecodeIndexInfo()
+	require.NoError(t, err)
+	require.Equal(t, testIndexInfo, res)
+}
+
+// Make sure the V4 decoder code can handle the V5 file format.
+func TestIndexInfoRoundTripForwardsCompatibilityV5(t *testing.T) {
+	var (
+		opts = legacyEncodingOptions{decodeLegacyIndexInfoVersion: legacyEncodingIndexVersionV4}
+		enc  = newEncoder(opts)
+		dec  = newDecoder(opts, nil)
+	)
+
+	// Set the default values on the fields that did not exist in V4
+	// and then restore them at the end of the test - This is required
+	// because the old decoder won't read the new fields.
+	currExpired := testIndexInfo.ExpiredSeriesRetentionPeriod
+
+	enc.EncodeIndexInfo(testIndexInfo)
+
+	// Make sure to zero them before we compare, but after we have
+	// encoded the data.
+	testIndexInfo.ExpiredSeriesRetentionPeriod = 0
+	defer func() {
+		testIndexInfo.ExpiredSeriesRetentionPeriod = currExpired
 	}()
 
 	dec.Reset(NewDecoderStream(enc.Bytes()))
@@ -360,6 +506,32 @@ func TestLogMetadataRoundtrip(t *testing.T) {
 	require.Equal(t, testLogMetadata, res)
 }
 
+// Make sure the current decoding code can handle log metadata written
+// before the codec ID was added.
+func TestLogMetadataRoundTripBackwardsCompatibility(t *testing.T) {
+	var (
+		enc = NewEncoder()
+		dec = NewDecoder(nil)
+	)
+
+	// Manually encode the log metadata as it was prior to the codec ID.
+	enc.encodeRootObject(logMetadataVersion, logMetadataType)
+	enc.encodeArrayLenFn(3)
+	enc.encodeBytesFn(testLogMetadata.ID)
+	enc.encodeBytesFn(testLogMetadata.Namespace)
+	enc.encodeVarUintFn(uint64(testLogMetadata.Shard))
+	enc.encodeBytesFn(testLogMetadata.EncodedTags)
+	require.NoError(t, enc.err)
+
+	expected := testLogMetadata
+	expected.CodecID = 0
+
+	dec.Reset(NewDecoderStream(enc.Bytes()))
+	res, err := dec.DecodeLogMetadata()
+	require.NoError(t, err)
+	require.Equal(t, expected, res)
+}
+
 func TestMultiTypeRoundtripStress(t *testing.T) {
 	var (
 		enc    = NewEncoder()
@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/persist/fs/msgpack"
@@ -75,6 +76,8 @@ var (
 
 	errTagEncoderPoolNotSet = errors.New("tag encoder pool is not set")
 	errTagDecoderPoolNotSet = errors.New("tag decoder pool is not set")
+	errTierMigrationAge     = errors.New("fileset tier migration age must be positive when a fileset tier is set")
+	errCompactionAge        = errors.New("fileset compaction age must be positive when fileset compaction is enabled")
 )
 
 type options struct {
@@ -98,6 +101,10 @@ type options struct {
 	tagEncoderPool                       serialize.TagEncoderPool
 	tagDecoderPool                       serialize.TagDecoderPool
 	fstOptions                           fst.Options
+	fileSetTier                          FileSetTier
+	fileSetTierMigrationAge              time.Duration
+	fileSetCompactionBlocks              int
+	fileSetCompactionAge                 time.Duration
 }
 
 // NewOptions creates a new set of fs options
@@ -151,6 +158,12 @@ func (o *options) Validate() error {
 	if o.tagDecoderPool == nil {
 		return errTagDecoderPoolNotSet
 	}
+	if o.fileSetTier != nil && o.fileSetTierMigrationAge <= 0 {
+		return errTierMigrationAge
+	}
+	if o.fileSetCompactionBlocks > 1 && o.fileSetCompactionAge <= 0 {
+		return errCompactionAge
+	}
 	return nil
 }
 
@@ -353,3 +366,43 @@ func (o *options) SetFSTOptions(value fst.Options) Options {
 func (o *options) FSTOptions() fst.Options {
 	return o.fstOptions
 }
+
+func (o *options) SetFileSetTier(value FileSetTier) Options {
+	opts := *o
+	opts.fileSetTier = value
+	return &opts
+}
+
+func (o *options) FileSetTier() FileSetTier {
+	return o.fileSetTier
+}
+
+func (o *options) SetFileSetTierMigrationAge(value time.Duration) Options {
+	opts := *o
+	opts.fileSetTierMigrationAge = value
+	return &opts
+}
+
+func (o *options) FileSetTierMigrationAge() time.Duration {
+	return o.fileSetTierMigrationAge
+}
+
+func (o *options) SetFileSetCompactionBlocks(value int) Options {
+	opts := *o
+	opts.fileSetCompactionBlocks = value
+	return &opts
+}
+
+func (o *options) FileSetCompactionBlocks() int {
+	return o.fileSetCompactionBlocks
+}
+
+func (o *options) SetFileSetCompactionAge(value time.Duration) Options {
+	opts := *o
+	opts.fileSetCompactionAge = value
+	return &opts
+}
+
+func (o *options) FileSetCompactionAge() time.Duration {
+	return o.fileSetCompactionAge
+}
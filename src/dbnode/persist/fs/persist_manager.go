@@ -95,6 +95,10 @@ type dataPersistManager struct {
 	// head and the tail of each segment so we don't need to allocate memory
 	// and gc it shortly after.
 	segmentHolder []checked.Bytes
+	// staged is set when the fileset being written replaces an existing
+	// fileset once complete.
+	staged   bool
+	stagedID FileSetFileIdentifier
 }
 
 type indexPersistManager struct {
@@ -248,7 +252,7 @@ func (pm *persistManager) PrepareIndex(opts persist.IndexPrepareOptions) (persis
 		BlockStart:         blockStart,
 		VolumeIndex:        volumeIndex,
 	}
-	blockSize := nsMetadata.Options().IndexOptions().BlockSize()
+	blockSize := nsMetadata.Options().IndexOptions().BlockSizeForBlockStart(blockStart)
 	idxWriterOpts := IndexWriterOpenOptions{
 		BlockSize:   blockSize,
 		FileSetType: opts.FileSetType,
@@ -416,18 +420,22 @@ func (pm *persistManager) PrepareData(opts persist.DataPrepareOptions) (persist.
 		return prepared, errPersistManagerFileSetAlreadyExists
 	}
 
-	if exists && opts.DeleteIfExists {
-		err := DeleteFileSetAt(pm.opts.FilePathPrefix(), nsID, shard, blockStart)
-		if err != nil {
-			return prepared, err
-		}
-	}
+	// NB: an existing fileset is written to the staging directory and only
+	// replaces the existing fileset once complete, so that a failure while
+	// writing it does not lose the data in the existing fileset.
+	staged := exists && opts.DeleteIfExists
 
 	blockSize := nsMetadata.Options().RetentionOptions().BlockSize()
+	if opts.BlockSize > 0 {
+		blockSize = opts.BlockSize
+	}
 	dataWriterOpts := DataWriterOpenOptions{
-		BlockSize: blockSize,
+		BlockSize:                    blockSize,
+		CodecID:                      nsMetadata.Options().CodecID(),
+		ExpiredSeriesRetentionPeriod: opts.ExpiredSeriesRetentionPeriod,
 		Snapshot: DataWriterSnapshotOptions{
 			SnapshotTime: snapshotTime,
+			SnapshotID:   opts.Snapshot.SnapshotID,
 		},
 		FileSetType: opts.FileSetType,
 		Identifier: FileSetFileIdentifier{
@@ -436,13 +444,17 @@ func (pm *persistManager) PrepareData(opts persist.DataPrepareOptions) (persist.
 			BlockStart:  blockStart,
 			VolumeIndex: volumeIndex,
 		},
+		Staged: staged,
 	}
 	if err := pm.dataPM.writer.Open(dataWriterOpts); err != nil {
 		return prepared, err
 	}
+	pm.dataPM.staged = staged
+	pm.dataPM.stagedID = dataWriterOpts.Identifier
 
 	prepared.Persist = pm.persist
 	prepared.Close = pm.closeData
+	prepared.Abort = pm.abortData
 
 	return prepared, nil
 }
@@ -494,7 +506,29 @@ func (pm *persistManager) persist(
 }
 
 func (pm *persistManager) closeData() error {
-	return pm.dataPM.writer.Close()
+	if err := pm.dataPM.writer.Close(); err != nil {
+		return err
+	}
+	if !pm.dataPM.staged {
+		return nil
+	}
+	id := pm.dataPM.stagedID
+	return CommitStagedDataFileSet(pm.opts, id.Namespace, id.Shard, id.BlockStart)
+}
+
+func (pm *persistManager) abortData() error {
+	err := pm.dataPM.writer.Close()
+	if !pm.dataPM.staged {
+		return err
+	}
+
+	// Discard the staged fileset so that the existing fileset is kept.
+	id := pm.dataPM.stagedID
+	if deleteErr := deleteStagedDataFileSet(pm.opts, id.Namespace, id.Shard,
+		id.BlockStart); deleteErr != nil && err == nil {
+		err = deleteErr
+	}
+	return err
 }
 
 // DoneData is called by the databaseFlushManager to finish the data persist process.
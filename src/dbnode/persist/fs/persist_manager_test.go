@@ -23,6 +23,7 @@ package fs
 import (
 	"errors"
 	"io"
+	"io/ioutil"
 	"os"
 	"testing"
 	"time"
@@ -96,15 +97,19 @@ func TestPersistenceManagerPrepareDataFileExistsWithDelete(t *testing.T) {
 			BlockStart: blockStart,
 		},
 		BlockSize: testBlockSize,
+		Staged:    true,
 	}, m3test.IdentTransformer)
 	writer.EXPECT().Open(writerOpts).Return(nil)
 
 	var (
 		shardDir           = createDataShardDir(t, pm.filePathPrefix, testNs1ID, shard)
 		checkpointFilePath = filesetPathFromTime(shardDir, blockStart, checkpointFileSuffix)
-		checkpointFileBuf  = make([]byte, CheckpointFileSizeBytes)
+		dataFilePath       = filesetPathFromTime(shardDir, blockStart, dataFileSuffix)
+		stagingPrefix      = StagingDirPath(pm.filePathPrefix)
+		stagedShardDir     = createDataShardDir(t, stagingPrefix, testNs1ID, shard)
 	)
-	createFile(t, checkpointFilePath, checkpointFileBuf)
+	createFile(t, checkpointFilePath, make([]byte, CheckpointFileSizeBytes))
+	createFile(t, dataFilePath, []byte{1})
 
 	flush, err := pm.StartDataPersist()
 	require.NoError(t, err)
@@ -124,7 +129,84 @@ func TestPersistenceManagerPrepareDataFileExistsWithDelete(t *testing.T) {
 	require.NotNil(t, prepared.Persist)
 	require.NotNil(t, prepared.Close)
 
-	_, err = os.Open(checkpointFilePath)
+	// The existing fileset is kept until the staged fileset is complete.
+	_, err = os.Stat(checkpointFilePath)
+	require.NoError(t, err)
+
+	writer.EXPECT().Close().DoAndReturn(func() error {
+		createFile(t, filesetPathFromTime(stagedShardDir, blockStart, dataFileSuffix), []byte{2})
+		createFile(t, filesetPathFromTime(stagedShardDir, blockStart, checkpointFileSuffix),
+			make([]byte, CheckpointFileSizeBytes))
+		return nil
+	})
+	require.NoError(t, prepared.Close())
+
+	data, err := ioutil.ReadFile(dataFilePath)
+	require.NoError(t, err)
+	require.Equal(t, []byte{2}, data)
+	_, err = os.Stat(checkpointFilePath)
+	require.NoError(t, err)
+
+	_, exists, err := FileSetAt(stagingPrefix, testNs1ID, shard, blockStart)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestPersistenceManagerPrepareDataFileExistsWithDeleteAbort(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pm, writer, _ := testDataPersistManager(t, ctrl)
+	defer os.RemoveAll(pm.filePathPrefix)
+
+	var (
+		shard      = uint32(0)
+		blockStart = time.Unix(1000, 0)
+	)
+
+	writer.EXPECT().Open(gomock.Any()).Return(nil)
+
+	var (
+		shardDir           = createDataShardDir(t, pm.filePathPrefix, testNs1ID, shard)
+		checkpointFilePath = filesetPathFromTime(shardDir, blockStart, checkpointFileSuffix)
+		dataFilePath       = filesetPathFromTime(shardDir, blockStart, dataFileSuffix)
+		stagingPrefix      = StagingDirPath(pm.filePathPrefix)
+		stagedShardDir     = createDataShardDir(t, stagingPrefix, testNs1ID, shard)
+		stagedDataFilePath = filesetPathFromTime(stagedShardDir, blockStart, dataFileSuffix)
+	)
+	createFile(t, checkpointFilePath, make([]byte, CheckpointFileSizeBytes))
+	createFile(t, dataFilePath, []byte{1})
+
+	flush, err := pm.StartDataPersist()
+	require.NoError(t, err)
+
+	defer func() {
+		assert.NoError(t, flush.DoneData())
+	}()
+
+	prepared, err := flush.PrepareData(persist.DataPrepareOptions{
+		NamespaceMetadata: testNs1Metadata(t),
+		Shard:             shard,
+		BlockStart:        blockStart,
+		DeleteIfExists:    true,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, prepared.Abort)
+
+	// Aborting discards the staged fileset, even if it is incomplete, and
+	// keeps the existing fileset.
+	writer.EXPECT().Close().DoAndReturn(func() error {
+		createFile(t, stagedDataFilePath, []byte{2})
+		return nil
+	})
+	require.NoError(t, prepared.Abort())
+
+	data, err := ioutil.ReadFile(dataFilePath)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1}, data)
+	_, err = os.Stat(checkpointFilePath)
+	require.NoError(t, err)
+	_, err = os.Stat(stagedDataFilePath)
 	require.True(t, os.IsNotExist(err))
 }
 
@@ -30,6 +30,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/digest"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs/msgpack"
 	"github.com/m3db/m3/src/dbnode/persist/schema"
@@ -59,6 +60,7 @@ type reader struct {
 
 	start     time.Time
 	blockSize time.Duration
+	codecID   codec.ID
 
 	infoFdWithDigest           digest.FdWithDigestReader
 	bloomFilterWithDigest      digest.FdWithDigestReader
@@ -130,41 +132,88 @@ func (r *reader) Open(opts DataReaderOpenOptions) error {
 		shard         = opts.Identifier.Shard
 		blockStart    = opts.Identifier.BlockStart
 		snapshotIndex = opts.Identifier.VolumeIndex
-		err           error
 	)
 
-	var (
-		shardDir            string
-		checkpointFilepath  string
-		infoFilepath        string
-		digestFilepath      string
-		bloomFilterFilepath string
-		indexFilepath       string
-		dataFilepath        string
-	)
 	switch opts.FileSetType {
 	case persist.FileSetSnapshotType:
-		shardDir = ShardSnapshotsDirPath(r.filePathPrefix, namespace, shard)
-		checkpointFilepath = filesetPathFromTimeAndIndex(shardDir, blockStart, snapshotIndex, checkpointFileSuffix)
-		infoFilepath = filesetPathFromTimeAndIndex(shardDir, blockStart, snapshotIndex, infoFileSuffix)
-		digestFilepath = filesetPathFromTimeAndIndex(shardDir, blockStart, snapshotIndex, digestFileSuffix)
-		bloomFilterFilepath = filesetPathFromTimeAndIndex(shardDir, blockStart, snapshotIndex, bloomFilterFileSuffix)
-		indexFilepath = filesetPathFromTimeAndIndex(shardDir, blockStart, snapshotIndex, indexFileSuffix)
-		dataFilepath = filesetPathFromTimeAndIndex(shardDir, blockStart, snapshotIndex, dataFileSuffix)
+		shardDir := ShardSnapshotsDirPath(r.filePathPrefix, namespace, shard)
+		return r.open(namespace, shard, readerFilePaths{
+			checkpoint:  filesetPathFromTimeAndIndex(shardDir, blockStart, snapshotIndex, checkpointFileSuffix),
+			info:        filesetPathFromTimeAndIndex(shardDir, blockStart, snapshotIndex, infoFileSuffix),
+			digest:      filesetPathFromTimeAndIndex(shardDir, blockStart, snapshotIndex, digestFileSuffix),
+			bloomFilter: filesetPathFromTimeAndIndex(shardDir, blockStart, snapshotIndex, bloomFilterFileSuffix),
+			index:       filesetPathFromTimeAndIndex(shardDir, blockStart, snapshotIndex, indexFileSuffix),
+			data:        filesetPathFromTimeAndIndex(shardDir, blockStart, snapshotIndex, dataFileSuffix),
+		})
 	case persist.FileSetFlushType:
-		shardDir = ShardDataDirPath(r.filePathPrefix, namespace, shard)
-		checkpointFilepath = filesetPathFromTime(shardDir, blockStart, checkpointFileSuffix)
-		infoFilepath = filesetPathFromTime(shardDir, blockStart, infoFileSuffix)
-		digestFilepath = filesetPathFromTime(shardDir, blockStart, digestFileSuffix)
-		bloomFilterFilepath = filesetPathFromTime(shardDir, blockStart, bloomFilterFileSuffix)
-		indexFilepath = filesetPathFromTime(shardDir, blockStart, indexFileSuffix)
-		dataFilepath = filesetPathFromTime(shardDir, blockStart, dataFileSuffix)
+		tier := r.opts.FileSetTier()
+		if tier == nil {
+			return r.open(namespace, shard,
+				newFlushReaderFilePaths(r.filePathPrefix, namespace, shard, blockStart))
+		}
+
+		// The fileset may have been migrated to the fileset tier.
+		filePathPrefix, _, err := DataFileSetPathPrefix(r.opts, namespace, shard, blockStart)
+		if err != nil {
+			return err
+		}
+		err = r.open(namespace, shard,
+			newFlushReaderFilePaths(filePathPrefix, namespace, shard, blockStart))
+		if err == nil || filePathPrefix == tier.FilePathPrefix() {
+			return err
+		}
+
+		// NB: The fileset may have been migrated to the fileset tier after its
+		// file path prefix was resolved, the fileset tier holds a complete copy
+		// of it before any of its files are removed from the primary prefix.
+		migrated, existsErr := DataFileSetExistsAt(tier.FilePathPrefix(), namespace, shard, blockStart)
+		if existsErr != nil || !migrated {
+			return err
+		}
+		if r.bloomFilterFd != nil {
+			r.bloomFilterFd.Close()
+			r.bloomFilterFd = nil
+		}
+		return r.open(namespace, shard,
+			newFlushReaderFilePaths(tier.FilePathPrefix(), namespace, shard, blockStart))
 	default:
 		return fmt.Errorf("unable to open reader with fileset type: %s", opts.FileSetType)
 	}
+}
+
+type readerFilePaths struct {
+	checkpoint  string
+	info        string
+	digest      string
+	bloomFilter string
+	index       string
+	data        string
+}
+
+func newFlushReaderFilePaths(
+	filePathPrefix string,
+	namespace ident.ID,
+	shard uint32,
+	blockStart time.Time,
+) readerFilePaths {
+	shardDir := ShardDataDirPath(filePathPrefix, namespace, shard)
+	return readerFilePaths{
+		checkpoint:  filesetPathFromTime(shardDir, blockStart, checkpointFileSuffix),
+		info:        filesetPathFromTime(shardDir, blockStart, infoFileSuffix),
+		digest:      filesetPathFromTime(shardDir, blockStart, digestFileSuffix),
+		bloomFilter: filesetPathFromTime(shardDir, blockStart, bloomFilterFileSuffix),
+		index:       filesetPathFromTime(shardDir, blockStart, indexFileSuffix),
+		data:        filesetPathFromTime(shardDir, blockStart, dataFileSuffix),
+	}
+}
 
+func (r *reader) open(
+	namespace ident.ID,
+	shard uint32,
+	paths readerFilePaths,
+) error {
 	// If there is no checkpoint file, don't read the data files.
-	digest, err := readCheckpointFile(checkpointFilepath, r.digestBuf)
+	digest, err := readCheckpointFile(paths.checkpoint, r.digestBuf)
 	if err != nil {
 		return err
 	}
@@ -172,9 +221,9 @@ func (r *reader) Open(opts DataReaderOpenOptions) error {
 
 	var infoFd, digestFd *os.File
 	err = openFiles(os.Open, map[string]**os.File{
-		infoFilepath:        &infoFd,
-		digestFilepath:      &digestFd,
-		bloomFilterFilepath: &r.bloomFilterFd,
+		paths.info:        &infoFd,
+		paths.digest:      &digestFd,
+		paths.bloomFilter: &r.bloomFilterFd,
 	})
 	if err != nil {
 		return err
@@ -190,12 +239,12 @@ func (r *reader) Open(opts DataReaderOpenOptions) error {
 	}()
 
 	result, err := mmap.Files(os.Open, map[string]mmap.FileDesc{
-		indexFilepath: mmap.FileDesc{
+		paths.index: mmap.FileDesc{
 			File:    &r.indexFd,
 			Bytes:   &r.indexMmap,
 			Options: mmap.Options{Read: true, HugeTLB: r.hugePagesOpts},
 		},
-		dataFilepath: mmap.FileDesc{
+		paths.data: mmap.FileDesc{
 			File:    &r.dataFd,
 			Bytes:   &r.dataMmap,
 			Options: mmap.Options{Read: true, HugeTLB: r.hugePagesOpts},
@@ -283,6 +332,7 @@ func (r *reader) readInfo(size int) error {
 	}
 	r.start = xtime.FromNanoseconds(info.BlockStart)
 	r.blockSize = time.Duration(info.BlockSize)
+	r.codecID = info.CodecID
 	r.entries = int(info.Entries)
 	r.entriesRead = 0
 	r.metadataRead = 0
@@ -433,6 +483,10 @@ func (r *reader) Range() xtime.Range {
 	return xtime.Range{Start: r.start, End: r.start.Add(r.blockSize)}
 }
 
+func (r *reader) CodecID() codec.ID {
+	return r.codecID
+}
+
 func (r *reader) Entries() int {
 	return r.entries
 }
@@ -31,6 +31,7 @@ import (
 
 	"github.com/m3db/bloom"
 	"github.com/m3db/m3/src/dbnode/digest"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3x/checked"
 	"github.com/m3db/m3x/ident"
@@ -372,6 +373,43 @@ func TestInfoReadWriteSnapshot(t *testing.T) {
 	require.Equal(t, testSnapshotID, snapshotID)
 }
 
+func TestInfoReadWriteCodecID(t *testing.T) {
+	dir := createTempDir(t)
+	filePathPrefix := filepath.Join(dir, "")
+	defer os.RemoveAll(dir)
+
+	w := newTestWriter(t, filePathPrefix)
+	err := w.Open(DataWriterOpenOptions{
+		Identifier: FileSetFileIdentifier{
+			Namespace:  testNs1ID,
+			Shard:      0,
+			BlockStart: testWriterStart,
+		},
+		BlockSize:   testBlockSize,
+		FileSetType: persist.FileSetFlushType,
+		CodecID:     codec.DeltaOfDelta,
+	})
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	readInfoFileResults := ReadInfoFiles(filePathPrefix, testNs1ID, 0, 16, nil)
+	require.Equal(t, 1, len(readInfoFileResults))
+	require.NoError(t, readInfoFileResults[0].Err.Error())
+	require.Equal(t, codec.DeltaOfDelta, readInfoFileResults[0].Info.CodecID)
+
+	r := newTestReader(t, filePathPrefix)
+	err = r.Open(DataReaderOpenOptions{
+		Identifier: FileSetFileIdentifier{
+			Namespace:  testNs1ID,
+			Shard:      0,
+			BlockStart: testWriterStart,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, codec.DeltaOfDelta, r.CodecID())
+	require.NoError(t, r.Close())
+}
+
 func TestReusingReaderWriter(t *testing.T) {
 	dir := createTempDir(t)
 	filePathPrefix := filepath.Join(dir, "")
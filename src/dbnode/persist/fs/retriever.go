@@ -274,12 +274,12 @@ func (r *blockRetriever) fetchBatch(
 	// to ensure all seeks are in ascending order
 	for _, req := range reqs {
 		entry, err := seeker.SeekIndexEntry(req.id)
-		if err != nil && err != errSeekIDNotFound {
+		if err != nil && err != ErrSeekIDNotFound {
 			req.onError(err)
 			continue
 		}
 
-		if err == errSeekIDNotFound {
+		if err == ErrSeekIDNotFound {
 			req.notFound = true
 		}
 		req.indexEntry = entry
@@ -297,7 +297,7 @@ func (r *blockRetriever) fetchBatch(
 		// mismatch error because default offset value for indexEntry is zero.
 		if !req.notFound {
 			data, err = seeker.SeekByIndexEntry(req.indexEntry)
-			if err != nil && err != errSeekIDNotFound {
+			if err != nil && err != ErrSeekIDNotFound {
 				req.onError(err)
 				continue
 			}
@@ -310,8 +310,11 @@ func (r *blockRetriever) fetchBatch(
 			seg = ts.NewSegment(data, nil, ts.FinalizeHead)
 		}
 
-		// We don't need to call onRetrieve.OnRetrieveBlock if the ID was not found
-		callOnRetrieve := req.onRetrieve != nil && !req.notFound
+		// We don't need to call onRetrieve.OnRetrieveBlock if the ID was not
+		// found or if the data is from a compacted fileset spanning several
+		// blocks, which can't be cached as a single block.
+		callOnRetrieve := req.onRetrieve != nil && !req.notFound &&
+			req.blockSize == r.blockSize
 		if callOnRetrieve {
 			// NB(r): Need to also trigger callback with a copy of the data.
 			// This is used by the database to cache the in memory data for
@@ -394,6 +397,15 @@ func (r *blockRetriever) Stream(
 		return xio.EmptyBlockReader, err
 	}
 
+	// Blocks held by a compacted fileset are read as the whole fileset range
+	// so that the seekers for it are shared by all the blocks it spans.
+	blockRange, err := r.seekerMgr.BlockRange(shard, startTime)
+	if err != nil {
+		return xio.EmptyBlockReader, err
+	}
+	req.start = blockRange.Start
+	req.blockSize = blockRange.End.Sub(blockRange.Start)
+
 	// If the ID is not in the seeker's bloom filter, then it's definitely not on
 	// disk and we can return immediately
 	if !bloomFilter.Test(id.Bytes()) {
@@ -428,6 +440,18 @@ func (req *retrieveRequest) toBlock() xio.BlockReader {
 	}
 }
 
+func (r *blockRetriever) InvalidateBlock(shard uint32, blockStart time.Time) {
+	r.RLock()
+	seekerMgr := r.seekerMgr
+	r.RUnlock()
+	if seekerMgr == nil {
+		// Nothing has been opened before Open() is called.
+		return
+	}
+
+	seekerMgr.InvalidateSeekers(shard, blockStart)
+}
+
 func (r *blockRetriever) shardRequests(
 	shard uint32,
 ) (*shardRetrieveRequests, error) {
@@ -39,8 +39,8 @@ import (
 )
 
 var (
-	// errSeekIDNotFound returned when ID cannot be found in the shard
-	errSeekIDNotFound = errors.New("id not found in shard")
+	// ErrSeekIDNotFound returned when ID cannot be found in the shard
+	ErrSeekIDNotFound = errors.New("id not found in shard")
 
 	// errSeekChecksumMismatch returned when data checksum does not match the expected checksum
 	errSeekChecksumMismatch = errors.New("checksum does not match expected checksum")
@@ -416,14 +416,14 @@ func (s *seeker) SeekIndexEntry(id ident.ID) (IndexEntry, error) {
 		// We've scanned far enough through the index file to be sure that the ID
 		// we're looking for doesn't exist (because the index is sorted by ID)
 		if comparison == 1 {
-			return IndexEntry{}, errSeekIDNotFound
+			return IndexEntry{}, ErrSeekIDNotFound
 		}
 	}
 
 	// Similar to the case above where comparison == 1, except in this case we're
 	// sure that the ID we're looking for doesn't exist because we reached the end
 	// of the index file.
-	return IndexEntry{}, errSeekIDNotFound
+	return IndexEntry{}, ErrSeekIDNotFound
 }
 
 func (s *seeker) Range() xtime.Range {
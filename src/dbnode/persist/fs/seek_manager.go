@@ -68,8 +68,7 @@ type seekerManager struct {
 	fetchConcurrency int
 	logger           log.Logger
 
-	bytesPool      pool.CheckedBytesPool
-	filePathPrefix string
+	bytesPool pool.CheckedBytesPool
 
 	status                 seekerManagerStatus
 	seekersByShardIdx      []*seekersByTime
@@ -94,6 +93,7 @@ type seekersAndBloom struct {
 	wg          *sync.WaitGroup
 	seekers     []borrowableSeeker
 	bloomFilter *ManagedConcurrentBloomFilter
+	blockRange  xtime.Range
 }
 
 // borrowableSeeker is just a seeker with an additional field for keeping track of whether or not it has been borrowed.
@@ -107,6 +107,13 @@ type seekersByTime struct {
 	shard    uint32
 	accessed bool
 	seekers  map[xtime.UnixNano]seekersAndBloom
+	// compacted maps the start of blocks held by compacted filesets to the
+	// start of the compacted fileset so that all the blocks it spans share
+	// the same seekers.
+	compacted map[xtime.UnixNano]xtime.UnixNano
+	// invalidated holds the seekers opened for filesets that have since been
+	// replaced, they are closed once all of them have been returned.
+	invalidated []seekersAndBloom
 }
 
 type seekerManagerPendingClose struct {
@@ -122,7 +129,6 @@ func NewSeekerManager(
 ) DataFileSetSeekerManager {
 	m := &seekerManager{
 		bytesPool:           bytesPool,
-		filePathPrefix:      opts.FilePathPrefix(),
 		opts:                opts,
 		fetchConcurrency:    fetchConcurrency,
 		logger:              opts.InstrumentOptions().Logger(),
@@ -173,22 +179,32 @@ func (m *seekerManager) CacheShardIndices(shards []uint32) error {
 }
 
 func (m *seekerManager) ConcurrentIDBloomFilter(shard uint32, start time.Time) (*ManagedConcurrentBloomFilter, error) {
+	seekersAndBloom, err := m.seekersAndBloom(shard, start)
+	return seekersAndBloom.bloomFilter, err
+}
+
+func (m *seekerManager) BlockRange(shard uint32, start time.Time) (xtime.Range, error) {
+	seekersAndBloom, err := m.seekersAndBloom(shard, start)
+	return seekersAndBloom.blockRange, err
+}
+
+func (m *seekerManager) seekersAndBloom(shard uint32, start time.Time) (seekersAndBloom, error) {
 	byTime := m.seekersByTime(shard)
 
 	// Try fast RLock() first
 	byTime.RLock()
-	startNano := xtime.ToUnixNano(start)
+	startNano := byTime.fileSetStartWithRLock(xtime.ToUnixNano(start))
 	seekersAndBloom, ok := byTime.seekers[startNano]
 	byTime.RUnlock()
 
 	if ok && seekersAndBloom.wg == nil {
-		return seekersAndBloom.bloomFilter, nil
+		return seekersAndBloom, nil
 	}
 
 	byTime.Lock()
 	seekersAndBloom, err := m.getOrOpenSeekersWithLock(startNano, byTime)
 	byTime.Unlock()
-	return seekersAndBloom.bloomFilter, err
+	return seekersAndBloom, err
 }
 
 func (m *seekerManager) Borrow(shard uint32, start time.Time) (ConcurrentDataFileSetSeeker, error) {
@@ -232,8 +248,20 @@ func (m *seekerManager) Return(shard uint32, start time.Time, seeker ConcurrentD
 	byTime.Lock()
 	defer byTime.Unlock()
 
-	startNano := xtime.ToUnixNano(start)
+	startNano := byTime.fileSetStartWithRLock(xtime.ToUnixNano(start))
 	seekersAndBloom, ok := byTime.seekers[startNano]
+	if ok && returnBorrowedSeeker(seekersAndBloom.seekers, seeker) {
+		return nil
+	}
+
+	// Seekers borrowed before the fileset they were opened for was replaced
+	// are returned after they have been invalidated.
+	for _, invalidated := range byTime.invalidated {
+		if returnBorrowedSeeker(invalidated.seekers, seeker) {
+			return nil
+		}
+	}
+
 	// Should never happen - This either means that the caller (DataBlockRetriever) is trying to return seekers
 	// that it never requested, OR its trying to return seekers after the openCloseLoop has already
 	// determined that they were all no longer in use and safe to close. Either way it indicates there is
@@ -242,22 +270,47 @@ func (m *seekerManager) Return(shard uint32, start time.Time, seeker ConcurrentD
 		return errSeekersDontExist
 	}
 
-	found := false
-	for i, compareSeeker := range seekersAndBloom.seekers {
-		if seeker == compareSeeker.seeker {
-			found = true
-			compareSeeker.isBorrowed = false
-			seekersAndBloom.seekers[i] = compareSeeker
-			break
-		}
-	}
 	// Should never happen with a well behaved caller. Either they are trying to return a seeker
 	// that we're not managing, or they provided the wrong shard/start.
-	if !found {
-		return errReturnedUnmanagedSeeker
+	return errReturnedUnmanagedSeeker
+}
+
+// InvalidateSeekers invalidates the seekers opened for the fileset holding a
+// given shard and block start once it has been replaced by a rewrite or a
+// compaction, so that the next borrow opens the fileset that replaced it.
+// Seekers which are still borrowed remain usable until they are returned.
+func (m *seekerManager) InvalidateSeekers(shard uint32, start time.Time) {
+	byTime := m.seekersByTime(shard)
+
+	byTime.Lock()
+	m.invalidateSeekersWithLock(xtime.ToUnixNano(start), byTime)
+	byTime.Unlock()
+}
+
+func (m *seekerManager) invalidateSeekersWithLock(start xtime.UnixNano, byTime *seekersByTime) {
+	start = byTime.fileSetStartWithRLock(start)
+	seekers, ok := byTime.seekers[start]
+	if !ok {
+		return
 	}
 
-	return nil
+	if seekers.wg != nil {
+		// Seekers are being opened, possibly for the replaced fileset, so wait
+		// for them to be opened before invalidating them.
+		byTime.Unlock()
+		seekers.wg.Wait()
+		byTime.Lock()
+		m.invalidateSeekersWithLock(start, byTime)
+		return
+	}
+
+	delete(byTime.seekers, start)
+	for blockStart, fileSetStart := range byTime.compacted {
+		if fileSetStart == start {
+			delete(byTime.compacted, blockStart)
+		}
+	}
+	byTime.invalidated = append(byTime.invalidated, seekers)
 }
 
 // getOrOpenSeekersWithLock checks if the seekers are already open / initialized. If they are, then it
@@ -270,6 +323,7 @@ func (m *seekerManager) Return(shard uint32, start time.Time, seeker ConcurrentD
 // open the Seeker (I/O heavy), re-acquire the lock (so that the waiting goroutines don't get it before us),
 // and then notify the waiting goroutines that we've finished.
 func (m *seekerManager) getOrOpenSeekersWithLock(start xtime.UnixNano, byTime *seekersByTime) (seekersAndBloom, error) {
+	start = byTime.fileSetStartWithRLock(start)
 	seekers, ok := byTime.seekers[start]
 	if ok && seekers.wg == nil {
 		// Seekers are already open
@@ -311,6 +365,25 @@ func (m *seekerManager) getOrOpenSeekersWithLock(start xtime.UnixNano, byTime *s
 		return seekersAndBloom{}, err
 	}
 
+	blockRange := seeker.Range()
+	if fileSetStart := xtime.ToUnixNano(blockRange.Start); fileSetStart != start {
+		// The block is held by a compacted fileset, share its seekers between
+		// all the blocks it spans rather than opening it once per block.
+		delete(byTime.seekers, start)
+		if byTime.compacted == nil {
+			byTime.compacted = make(map[xtime.UnixNano]xtime.UnixNano)
+		}
+		byTime.compacted[start] = fileSetStart
+		if _, ok := byTime.seekers[fileSetStart]; ok {
+			// Already opened, or being opened, for another block it spans.
+			if err := seeker.Close(); err != nil {
+				return seekersAndBloom{}, err
+			}
+			return m.getOrOpenSeekersWithLock(fileSetStart, byTime)
+		}
+		start = fileSetStart
+	}
+
 	borrowableSeekers = append(borrowableSeekers, borrowableSeeker{seeker: seeker})
 	// Clone remaining seekers from the original - No need to release the lock, cloning is cheap.
 	for i := 0; i < m.fetchConcurrency-1; i++ {
@@ -331,6 +404,7 @@ func (m *seekerManager) getOrOpenSeekersWithLock(start xtime.UnixNano, byTime *s
 
 	seekers.wg = nil
 	seekers.seekers = borrowableSeekers
+	seekers.blockRange = blockRange
 	// Doesn't matter which seeker we pick to grab the bloom filter from, they all share the same underlying one.
 	// Use index 0 because its guaranteed to be there.
 	seekers.bloomFilter = borrowableSeekers[0].seeker.ConcurrentIDBloomFilter()
@@ -360,7 +434,12 @@ func (m *seekerManager) newOpenSeeker(
 	shard uint32,
 	blockStart time.Time,
 ) (DataFileSetSeeker, error) {
-	exists, err := DataFileSetExistsAt(m.filePathPrefix, m.namespace, shard, blockStart)
+	// Filesets may live in either the primary file path prefix or, once
+	// migrated, the fileset tier and may be compacted filesets spanning
+	// several blocks.
+	blockSize := m.namespaceMetadata.Options().RetentionOptions().BlockSize()
+	fileSet, exists, err := CoveringDataFileSet(m.opts, m.namespace, shard,
+		blockStart, blockSize)
 	if err != nil {
 		return nil, err
 	}
@@ -374,8 +453,32 @@ func (m *seekerManager) newOpenSeeker(
 	m.unreadBuf.Lock()
 	defer m.unreadBuf.Unlock()
 
+	seeker, err := m.openSeekerWithUnreadBufLock(fileSet.FilePathPrefix, shard,
+		fileSet.BlockStart)
+	tier := m.opts.FileSetTier()
+	if err == nil || tier == nil || fileSet.FilePathPrefix == tier.FilePathPrefix() {
+		return seeker, err
+	}
+
+	// NB: The fileset may have been migrated to the fileset tier after its
+	// file path prefix was resolved, the fileset tier holds a complete copy
+	// of it before any of its files are removed from the primary prefix.
+	migrated, existsErr := DataFileSetExistsAt(tier.FilePathPrefix(), m.namespace,
+		shard, fileSet.BlockStart)
+	if existsErr != nil || !migrated {
+		return nil, err
+	}
+	return m.openSeekerWithUnreadBufLock(tier.FilePathPrefix(), shard,
+		fileSet.BlockStart)
+}
+
+func (m *seekerManager) openSeekerWithUnreadBufLock(
+	filePathPrefix string,
+	shard uint32,
+	blockStart time.Time,
+) (DataFileSetSeeker, error) {
 	seekerIface := NewSeeker(
-		m.filePathPrefix,
+		filePathPrefix,
 		m.opts.DataReaderBufferSize(),
 		m.opts.InfoReaderBufferSize(),
 		m.opts.SeekReaderBufferSize(),
@@ -450,16 +553,18 @@ func (m *seekerManager) Close() error {
 	// Actual cleanup of the seekers themselves will be handled by the openCloseLoop.
 	for _, byTime := range m.seekersByShardIdx {
 		byTime.Lock()
+		borrowed := false
 		for _, seekersByTime := range byTime.seekers {
-			for _, seeker := range seekersByTime.seekers {
-				if seeker.isBorrowed {
-					byTime.Unlock()
-					m.Unlock()
-					return errCantCloseSeekerManagerWhileSeekersAreBorrowed
-				}
-			}
+			borrowed = borrowed || !allSeekersReturned(seekersByTime.seekers)
+		}
+		for _, seekersByTime := range byTime.invalidated {
+			borrowed = borrowed || !allSeekersReturned(seekersByTime.seekers)
 		}
 		byTime.Unlock()
+		if borrowed {
+			m.Unlock()
+			return errCantCloseSeekerManagerWhileSeekersAreBorrowed
+		}
 	}
 
 	m.status = seekerManagerClosed
@@ -536,9 +641,12 @@ func (m *seekerManager) openCloseLoop() {
 		m.RLock()
 		for shard, byTime := range m.seekersByShardIdx {
 			byTime.RLock()
-			for blockStartNano := range byTime.seekers {
+			for blockStartNano, seekersAndBloom := range byTime.seekers {
+				// Compacted filesets are only closed once every block they
+				// span is out of retention.
 				blockStart := blockStartNano.ToTime()
-				if blockStart.Before(earliestSeekableBlockStart) {
+				if blockStart.Before(earliestSeekableBlockStart) &&
+					!seekersAndBloom.blockRange.End.After(earliestSeekableBlockStart) {
 					shouldClose = append(shouldClose, seekerManagerPendingClose{
 						shard:      uint32(shard),
 						blockStart: blockStart,
@@ -554,23 +662,39 @@ func (m *seekerManager) openCloseLoop() {
 				blockStartNano := xtime.ToUnixNano(elem.blockStart)
 				byTime.Lock()
 				seekersAndBloom := byTime.seekers[blockStartNano]
-				allSeekersAreReturned := true
-				for _, seeker := range seekersAndBloom.seekers {
-					if seeker.isBorrowed {
-						allSeekersAreReturned = false
-						break
-					}
-				}
 				// Never close seekers unless they've all been returned because
 				// some of them are clones of the original and can't be used once
 				// the parent is closed (because they share underlying resources)
-				if allSeekersAreReturned {
+				if allSeekersReturned(seekersAndBloom.seekers) {
 					closing = append(closing, seekersAndBloom.seekers...)
 					delete(byTime.seekers, blockStartNano)
+					for start, fileSetStart := range byTime.compacted {
+						if fileSetStart == blockStartNano {
+							delete(byTime.compacted, start)
+						}
+					}
 				}
 				byTime.Unlock()
 			}
 		}
+
+		// Close the invalidated seekers which have all been returned.
+		for _, byTime := range m.seekersByShardIdx {
+			byTime.Lock()
+			remaining := byTime.invalidated[:0]
+			for _, invalidated := range byTime.invalidated {
+				if allSeekersReturned(invalidated.seekers) {
+					closing = append(closing, invalidated.seekers...)
+					continue
+				}
+				remaining = append(remaining, invalidated)
+			}
+			for i := len(remaining); i < len(byTime.invalidated); i++ {
+				byTime.invalidated[i] = seekersAndBloom{}
+			}
+			byTime.invalidated = remaining
+			byTime.Unlock()
+		}
 		m.RUnlock()
 
 		// Close after releasing lock so any IO is done out of lock
@@ -592,7 +716,11 @@ func (m *seekerManager) openCloseLoop() {
 	m.Lock()
 	for _, byTime := range m.seekersByShardIdx {
 		byTime.Lock()
+		all := byTime.invalidated
 		for _, seekersByTime := range byTime.seekers {
+			all = append(all, seekersByTime)
+		}
+		for _, seekersByTime := range all {
 			for _, seeker := range seekersByTime.seekers {
 				// We don't need to check if the seeker is borrowed here because we don't allow the
 				// SeekerManager to be closed if any seekers are still outstanding.
@@ -605,6 +733,8 @@ func (m *seekerManager) openCloseLoop() {
 			}
 		}
 		byTime.seekers = nil
+		byTime.compacted = nil
+		byTime.invalidated = nil
 		byTime.Unlock()
 	}
 	m.seekersByShardIdx = nil
@@ -612,3 +742,37 @@ func (m *seekerManager) openCloseLoop() {
 
 	m.openCloseLoopDoneCh <- struct{}{}
 }
+
+// fileSetStartWithRLock returns the start of the fileset holding the block
+// with the given start, which for compacted filesets is earlier than the
+// block start.
+func (s *seekersByTime) fileSetStartWithRLock(blockStart xtime.UnixNano) xtime.UnixNano {
+	if fileSetStart, ok := s.compacted[blockStart]; ok {
+		return fileSetStart
+	}
+	return blockStart
+}
+
+// returnBorrowedSeeker marks a seeker as returned if it is one of the given
+// seekers, returning whether it was.
+func returnBorrowedSeeker(
+	seekers []borrowableSeeker,
+	seeker ConcurrentDataFileSetSeeker,
+) bool {
+	for i := range seekers {
+		if seekers[i].seeker == seeker {
+			seekers[i].isBorrowed = false
+			return true
+		}
+	}
+	return false
+}
+
+func allSeekersReturned(seekers []borrowableSeeker) bool {
+	for _, seeker := range seekers {
+		if seeker.isBorrowed {
+			return false
+		}
+	}
+	return true
+}
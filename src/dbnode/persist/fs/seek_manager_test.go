@@ -83,6 +83,10 @@ func TestSeekerManagerBorrowOpenSeekersLazy(t *testing.T) {
 		mock := NewMockDataFileSetSeeker(ctrl)
 		mock.EXPECT().Open(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 		mock.EXPECT().ConcurrentClone().Return(mock, nil)
+		mock.EXPECT().Range().Return(xtime.Range{
+			Start: blockStart,
+			End:   blockStart.Add(testBlockSize),
+		})
 		for i := 0; i < defaultFetchConcurrency; i++ {
 			mock.EXPECT().Close().Return(nil)
 			mock.EXPECT().ConcurrentIDBloomFilter().Return(nil)
@@ -275,3 +279,73 @@ func TestSeekerManagerOpenCloseLoop(t *testing.T) {
 	// to prevent the test itself from interfering with the goroutine leak test
 	close(cleanupCh)
 }
+
+// TestSeekerManagerInvalidateSeekers tests that invalidated seekers are
+// reopened when next borrowed and closed once they have all been returned.
+func TestSeekerManagerInvalidateSeekers(t *testing.T) {
+	defer leaktest.CheckTimeout(t, 1*time.Minute)()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		shard  = uint32(1)
+		start  = time.Now().Truncate(testBlockSize)
+		opened int
+	)
+	m := NewSeekerManager(nil, testDefaultOpts, defaultFetchConcurrency).(*seekerManager)
+	m.openAnyUnopenSeekersFn = func(*seekersByTime) error {
+		return nil
+	}
+	m.newOpenSeekerFn = func(
+		shard uint32,
+		blockStart time.Time,
+	) (DataFileSetSeeker, error) {
+		opened++
+		seeker := NewMockDataFileSetSeeker(ctrl)
+		seeker.EXPECT().Range().Return(xtime.Range{
+			Start: blockStart,
+			End:   blockStart.Add(testBlockSize),
+		})
+		seeker.EXPECT().ConcurrentIDBloomFilter().Return(nil)
+		seeker.EXPECT().Close().Return(nil)
+		for i := 0; i < defaultFetchConcurrency-1; i++ {
+			clone := NewMockDataFileSetSeeker(ctrl)
+			clone.EXPECT().Close().Return(nil)
+			seeker.EXPECT().ConcurrentClone().Return(clone, nil)
+		}
+		return seeker, nil
+	}
+	m.sleepFn = func(_ time.Duration) {
+		time.Sleep(time.Millisecond)
+	}
+
+	require.NoError(t, m.Open(testNs1Metadata(t)))
+
+	borrowed, err := m.Borrow(shard, start)
+	require.NoError(t, err)
+
+	// The borrowed seeker remains usable until returned while the next
+	// borrow opens the fileset again.
+	m.InvalidateSeekers(shard, start)
+	reopened, err := m.Borrow(shard, start)
+	require.NoError(t, err)
+	require.Equal(t, 2, opened)
+	require.True(t, borrowed != reopened)
+
+	require.NoError(t, m.Return(shard, start, borrowed))
+	require.NoError(t, m.Return(shard, start, reopened))
+
+	for {
+		byTime := m.seekersByTime(shard)
+		byTime.RLock()
+		numInvalidated := len(byTime.invalidated)
+		byTime.RUnlock()
+		if numInvalidated == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	require.NoError(t, m.Close())
+}
@@ -74,7 +74,7 @@ func TestSeekEmptyIndex(t *testing.T) {
 	assert.Equal(t, 0, s.Entries())
 	_, err = s.SeekByID(ident.StringID("foo"))
 	assert.Error(t, err)
-	assert.Equal(t, errSeekIDNotFound, err)
+	assert.Equal(t, ErrSeekIDNotFound, err)
 	assert.NoError(t, s.Close())
 }
 
@@ -158,7 +158,7 @@ func TestSeekBadChecksum(t *testing.T) {
 }
 
 // TestSeek is a basic sanity test that we can seek IDs that have been written,
-// as well as received errSeekIDNotFound for IDs that were not written.
+// as well as received ErrSeekIDNotFound for IDs that were not written.
 func TestSeek(t *testing.T) {
 	dir, err := ioutil.TempDir("", "testdb")
 	if err != nil {
@@ -215,7 +215,7 @@ func TestSeek(t *testing.T) {
 
 	_, err = s.SeekByID(ident.StringID("foo"))
 	assert.Error(t, err)
-	assert.Equal(t, errSeekIDNotFound, err)
+	assert.Equal(t, ErrSeekIDNotFound, err)
 
 	data, err = s.SeekByID(ident.StringID("foo2"))
 	require.NoError(t, err)
@@ -266,17 +266,17 @@ func TestSeekIDNotExists(t *testing.T) {
 	err = s.Open(testNs1ID, 0, testWriterStart)
 	assert.NoError(t, err)
 
-	// Test errSeekIDNotFound when we scan far enough into the index file that
+	// Test ErrSeekIDNotFound when we scan far enough into the index file that
 	// we're sure that the ID we're looking for doesn't exist (because the index
 	// file is sorted). In this particular case, we would know foo21 doesn't exist
 	// once we've scanned all the way to foo30 (which does exist).
 	_, err = s.SeekByID(ident.StringID("foo21"))
-	assert.Equal(t, errSeekIDNotFound, err)
+	assert.Equal(t, ErrSeekIDNotFound, err)
 
-	// Test errSeekIDNotFound when we scan to the end of the index file (foo40
+	// Test ErrSeekIDNotFound when we scan to the end of the index file (foo40
 	// would be located at the end of the index file based on the writes we've made)
 	_, err = s.SeekByID(ident.StringID("foo40"))
-	assert.Equal(t, errSeekIDNotFound, err)
+	assert.Equal(t, ErrSeekIDNotFound, err)
 
 	assert.NoError(t, s.Close())
 }
@@ -0,0 +1,170 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/persist"
+	xerrors "github.com/m3db/m3x/errors"
+	"github.com/m3db/m3x/ident"
+)
+
+// CommitStagedDataFileSet replaces the data fileset for the given namespace,
+// shard, and block start with the complete fileset staged for it. The checkpoint
+// file of the existing fileset is removed before any of its other files are
+// replaced and the staged checkpoint file is moved last, so that at any point
+// either the existing or the staged fileset is complete. If the process stops
+// before the commit finishes, RecoverStagedDataFileSets finishes it.
+func CommitStagedDataFileSet(
+	opts Options,
+	namespace ident.ID,
+	shard uint32,
+	blockStart time.Time,
+) error {
+	stagingPrefix := StagingDirPath(opts.FilePathPrefix())
+	staged, ok, err := FileSetAt(stagingPrefix, namespace, shard, blockStart)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("staged fileset for blockStart: %d does not exist",
+			blockStart.Unix())
+	}
+	return commitStagedDataFileSet(opts, namespace, shard, staged)
+}
+
+// RecoverStagedDataFileSets finishes committing the staged data filesets for the
+// given namespace and shard that were complete when the process stopped, and
+// removes the staged filesets that were not. The existing filesets are only
+// modified once the staged filesets replacing them are complete so neither case
+// loses data. It must not be called while filesets are being written for the
+// shard.
+func RecoverStagedDataFileSets(
+	opts Options,
+	namespace ident.ID,
+	shard uint32,
+) error {
+	staged, err := filesetFiles(filesetFilesSelector{
+		fileSetType:    persist.FileSetFlushType,
+		contentType:    persist.FileSetDataContentType,
+		filePathPrefix: StagingDirPath(opts.FilePathPrefix()),
+		namespace:      namespace,
+		shard:          shard,
+		pattern:        filesetFilePattern,
+	})
+	if err != nil {
+		return err
+	}
+
+	multiErr := xerrors.NewMultiError()
+	for _, fileset := range staged {
+		if fileset.HasCheckpointFile() {
+			err = commitStagedDataFileSet(opts, namespace, shard, fileset)
+		} else {
+			err = DeleteFiles(fileset.AbsoluteFilepaths)
+		}
+		if err != nil {
+			multiErr = multiErr.Add(fmt.Errorf(
+				"failed to recover staged fileset for blockStart %d: %v",
+				fileset.ID.BlockStart.Unix(), err))
+		}
+	}
+	return multiErr.FinalError()
+}
+
+// deleteStagedDataFileSet removes the files staged for the given namespace,
+// shard, and block start whether or not the staged fileset is complete.
+func deleteStagedDataFileSet(
+	opts Options,
+	namespace ident.ID,
+	shard uint32,
+	blockStart time.Time,
+) error {
+	staged, err := filesetFiles(filesetFilesSelector{
+		fileSetType:    persist.FileSetFlushType,
+		contentType:    persist.FileSetDataContentType,
+		filePathPrefix: StagingDirPath(opts.FilePathPrefix()),
+		namespace:      namespace,
+		shard:          shard,
+		pattern:        filesetFileForTime(blockStart, anyLowerCaseCharsPattern),
+	})
+	if err != nil {
+		return err
+	}
+
+	multiErr := xerrors.NewMultiError()
+	for _, fileset := range staged {
+		if err := DeleteFiles(fileset.AbsoluteFilepaths); err != nil {
+			multiErr = multiErr.Add(err)
+		}
+	}
+	return multiErr.FinalError()
+}
+
+func commitStagedDataFileSet(
+	opts Options,
+	namespace ident.ID,
+	shard uint32,
+	staged FileSetFile,
+) error {
+	shardDir := ShardDataDirPath(opts.FilePathPrefix(), namespace, shard)
+	if err := os.MkdirAll(shardDir, opts.NewDirectoryMode()); err != nil {
+		return err
+	}
+
+	checkpointFilePath := filesetPathFromTime(shardDir, staged.ID.BlockStart,
+		checkpointFileSuffix)
+	if err := os.Remove(checkpointFilePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var stagedCheckpointFilePath string
+	for _, src := range staged.AbsoluteFilepaths {
+		if strings.Contains(src, checkpointFileSuffix) {
+			stagedCheckpointFilePath = src
+			continue
+		}
+		if err := os.Rename(src, filepath.Join(shardDir, filepath.Base(src))); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(stagedCheckpointFilePath, checkpointFilePath); err != nil {
+		return err
+	}
+	return syncDir(shardDir)
+}
+
+func syncDir(dirPath string) error {
+	dir, err := os.Open(dirPath)
+	if err != nil {
+		return err
+	}
+	if err := dir.Sync(); err != nil {
+		dir.Close()
+		return err
+	}
+	return dir.Close()
+}
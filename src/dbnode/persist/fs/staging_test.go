@@ -0,0 +1,92 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/persist"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverStagedDataFileSets(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	var (
+		filePathPrefix = filepath.Join(dir, "primary")
+		stagingPrefix  = StagingDirPath(filePathPrefix)
+		shard          = uint32(0)
+		blockStart1    = testWriterStart.Truncate(testBlockSize)
+		blockStart2    = blockStart1.Add(testBlockSize)
+		shardDir       = ShardDataDirPath(filePathPrefix, testNs1ID, shard)
+		stagedShardDir = ShardDataDirPath(stagingPrefix, testNs1ID, shard)
+		existing       = []testEntry{
+			{"foo", nil, []byte{1, 2, 3}},
+			{"bar", nil, []byte{4, 5, 6}},
+		}
+		replacement = []testEntry{
+			{"foo", nil, []byte{1, 2, 3}},
+		}
+	)
+
+	w := newTestWriter(t, filePathPrefix)
+	writeTestData(t, w, shard, blockStart1, existing, persist.FileSetFlushType)
+	writeTestData(t, w, shard, blockStart2, existing, persist.FileSetFlushType)
+
+	w = newTestWriter(t, stagingPrefix)
+	writeTestData(t, w, shard, blockStart1, replacement, persist.FileSetFlushType)
+	writeTestData(t, w, shard, blockStart2, replacement, persist.FileSetFlushType)
+
+	// The commit of the first staged fileset was interrupted after removing the
+	// checkpoint file of the existing fileset, and the second staged fileset was
+	// never completed.
+	require.NoError(t, os.Remove(filesetPathFromTime(shardDir, blockStart1, checkpointFileSuffix)))
+	require.NoError(t, os.Remove(filesetPathFromTime(stagedShardDir, blockStart2, checkpointFileSuffix)))
+	stagedData, err := ioutil.ReadFile(filesetPathFromTime(stagedShardDir, blockStart1, dataFileSuffix))
+	require.NoError(t, err)
+	existingData, err := ioutil.ReadFile(filesetPathFromTime(shardDir, blockStart2, dataFileSuffix))
+	require.NoError(t, err)
+
+	opts := testDefaultOpts.SetFilePathPrefix(filePathPrefix)
+	require.NoError(t, RecoverStagedDataFileSets(opts, testNs1ID, shard))
+
+	for _, blockStart := range []time.Time{blockStart1, blockStart2} {
+		exists, err := DataFileSetExistsAt(filePathPrefix, testNs1ID, shard, blockStart)
+		require.NoError(t, err)
+		require.True(t, exists)
+	}
+	data, err := ioutil.ReadFile(filesetPathFromTime(shardDir, blockStart1, dataFileSuffix))
+	require.NoError(t, err)
+	require.Equal(t, stagedData, data)
+	data, err = ioutil.ReadFile(filesetPathFromTime(shardDir, blockStart2, dataFileSuffix))
+	require.NoError(t, err)
+	require.Equal(t, existingData, data)
+
+	files, err := ioutil.ReadDir(stagedShardDir)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(files))
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/persist"
+	xerrors "github.com/m3db/m3x/errors"
+	"github.com/m3db/m3x/ident"
+)
+
+type localDirectoryTier struct {
+	filePathPrefix   string
+	newFileMode      os.FileMode
+	newDirectoryMode os.FileMode
+}
+
+// NewLocalDirectoryTier returns a fileset tier backed by a local directory,
+// typically a mount of cheaper storage than the primary file path prefix.
+func NewLocalDirectoryTier(filePathPrefix string, opts Options) FileSetTier {
+	return &localDirectoryTier{
+		filePathPrefix:   filePathPrefix,
+		newFileMode:      opts.NewFileMode(),
+		newDirectoryMode: opts.NewDirectoryMode(),
+	}
+}
+
+func (t *localDirectoryTier) FilePathPrefix() string {
+	return t.filePathPrefix
+}
+
+func (t *localDirectoryTier) MoveFileSet(filePathPrefix string, fileset FileSetFile) error {
+	if !fileset.HasCheckpointFile() {
+		return fmt.Errorf("fileset for blockStart: %d is not complete",
+			fileset.ID.BlockStart.Unix())
+	}
+
+	// Copy the checkpoint file last so that the fileset only becomes
+	// visible in the tier once all of its other files are durable.
+	var checkpointFilePath string
+	for _, src := range fileset.AbsoluteFilepaths {
+		if strings.Contains(src, checkpointFileSuffix) {
+			checkpointFilePath = src
+			continue
+		}
+		if err := t.copyFile(filePathPrefix, src); err != nil {
+			return err
+		}
+	}
+	if err := t.copyFile(filePathPrefix, checkpointFilePath); err != nil {
+		return err
+	}
+
+	// Remove the checkpoint file first so that the primary copy stops being
+	// considered complete before any of its other files are removed.
+	if err := os.Remove(checkpointFilePath); err != nil {
+		return err
+	}
+	multiErr := xerrors.NewMultiError()
+	for _, src := range fileset.AbsoluteFilepaths {
+		if src == checkpointFilePath {
+			continue
+		}
+		if err := os.Remove(src); err != nil && !os.IsNotExist(err) {
+			multiErr = multiErr.Add(err)
+		}
+	}
+	return multiErr.FinalError()
+}
+
+func (t *localDirectoryTier) copyFile(filePathPrefix, src string) error {
+	relPath, err := filepath.Rel(filePathPrefix, src)
+	if err != nil {
+		return err
+	}
+
+	dst := filepath.Join(t.filePathPrefix, relPath)
+	if err := os.MkdirAll(filepath.Dir(dst), t.newDirectoryMode); err != nil {
+		return err
+	}
+
+	srcFd, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFd.Close()
+
+	dstFd, err := OpenWritable(dst, t.newFileMode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dstFd, srcFd); err != nil {
+		dstFd.Close()
+		return err
+	}
+	if err := dstFd.Sync(); err != nil {
+		dstFd.Close()
+		return err
+	}
+	return dstFd.Close()
+}
+
+// DataFileSetPathPrefix returns the file path prefix holding the complete data
+// fileset for the given namespace, shard, and block start, checking the primary
+// file path prefix before the fileset tier. If neither holds the fileset then
+// the primary file path prefix is returned alongside false.
+func DataFileSetPathPrefix(
+	opts Options,
+	namespace ident.ID,
+	shard uint32,
+	blockStart time.Time,
+) (string, bool, error) {
+	filePathPrefix := opts.FilePathPrefix()
+	exists, err := DataFileSetExistsAt(filePathPrefix, namespace, shard, blockStart)
+	if err != nil || exists {
+		return filePathPrefix, exists, err
+	}
+
+	tier := opts.FileSetTier()
+	if tier == nil {
+		return filePathPrefix, false, nil
+	}
+
+	exists, err = DataFileSetExistsAt(tier.FilePathPrefix(), namespace, shard, blockStart)
+	if err != nil || !exists {
+		return filePathPrefix, false, err
+	}
+	return tier.FilePathPrefix(), true, nil
+}
+
+// ReadInfoFilesAllTiers reads the data fileset info files for a given shard
+// from both the primary file path prefix and, if configured, the fileset tier.
+// Block starts with filesets in both are only read from the primary prefix.
+func ReadInfoFilesAllTiers(
+	opts Options,
+	namespace ident.ID,
+	shard uint32,
+) []ReadInfoFileResult {
+	results := ReadInfoFiles(opts.FilePathPrefix(), namespace, shard,
+		opts.InfoReaderBufferSize(), opts.DecodingOptions())
+
+	tier := opts.FileSetTier()
+	if tier == nil {
+		return results
+	}
+
+	primaryBlockStarts := make(map[int64]struct{}, len(results))
+	for _, result := range results {
+		if result.Err.Error() == nil {
+			primaryBlockStarts[result.Info.BlockStart] = struct{}{}
+		}
+	}
+
+	tierResults := ReadInfoFiles(tier.FilePathPrefix(), namespace, shard,
+		opts.InfoReaderBufferSize(), opts.DecodingOptions())
+	for _, result := range tierResults {
+		if result.Err.Error() == nil {
+			if _, ok := primaryBlockStarts[result.Info.BlockStart]; ok {
+				continue
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// MigrateDataFileSetsBefore moves the complete data filesets for a given shard
+// with block starts earlier than a given time from the primary file path prefix
+// to the fileset tier, returning the block starts of the filesets moved.
+func MigrateDataFileSetsBefore(
+	opts Options,
+	namespace ident.ID,
+	shard uint32,
+	t time.Time,
+) ([]time.Time, error) {
+	tier := opts.FileSetTier()
+	if tier == nil {
+		return nil, nil
+	}
+
+	filePathPrefix := opts.FilePathPrefix()
+	filesets, err := filesetFiles(filesetFilesSelector{
+		fileSetType:    persist.FileSetFlushType,
+		contentType:    persist.FileSetDataContentType,
+		filePathPrefix: filePathPrefix,
+		namespace:      namespace,
+		shard:          shard,
+		pattern:        filesetFilePattern,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		migrated []time.Time
+		multiErr = xerrors.NewMultiError()
+	)
+	for _, fileset := range filesets {
+		if !fileset.ID.BlockStart.Before(t) {
+			continue
+		}
+
+		// Skip filesets that are still being written out.
+		exists, err := DataFileSetExistsAt(filePathPrefix, namespace, shard,
+			fileset.ID.BlockStart)
+		if err != nil {
+			multiErr = multiErr.Add(err)
+			continue
+		}
+		if !exists {
+			continue
+		}
+
+		if err := tier.MoveFileSet(filePathPrefix, fileset); err != nil {
+			multiErr = multiErr.Add(fmt.Errorf(
+				"failed to move fileset for blockStart %d to tier: %v",
+				fileset.ID.BlockStart.Unix(), err))
+			continue
+		}
+		migrated = append(migrated, fileset.ID.BlockStart)
+	}
+	return migrated, multiErr.FinalError()
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/persist"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateDataFileSetsBeforeMovesToTier(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	var (
+		filePathPrefix = filepath.Join(dir, "primary")
+		tierPathPrefix = filepath.Join(dir, "tier")
+		shard          = uint32(0)
+		oldBlockStart  = testWriterStart.Truncate(testBlockSize)
+		newBlockStart  = oldBlockStart.Add(testBlockSize)
+		entries        = []testEntry{
+			{"foo", nil, []byte{1, 2, 3}},
+			{"bar", map[string]string{"baz": "qux"}, []byte{4, 5, 6}},
+		}
+	)
+
+	w := newTestWriter(t, filePathPrefix)
+	writeTestData(t, w, shard, oldBlockStart, entries, persist.FileSetFlushType)
+	writeTestData(t, w, shard, newBlockStart, entries, persist.FileSetFlushType)
+
+	opts := testDefaultOpts.
+		SetFilePathPrefix(filePathPrefix).
+		SetFileSetTier(NewLocalDirectoryTier(tierPathPrefix, testDefaultOpts)).
+		SetFileSetTierMigrationAge(testBlockSize)
+	require.NoError(t, opts.Validate())
+
+	migrated, err := MigrateDataFileSetsBefore(opts, testNs1ID, shard, newBlockStart)
+	require.NoError(t, err)
+	require.Equal(t, []time.Time{oldBlockStart}, migrated)
+
+	// Only the older fileset should have moved to the tier.
+	exists, err := DataFileSetExistsAt(filePathPrefix, testNs1ID, shard, oldBlockStart)
+	require.NoError(t, err)
+	assert.False(t, exists)
+	exists, err = DataFileSetExistsAt(tierPathPrefix, testNs1ID, shard, oldBlockStart)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = DataFileSetExistsAt(filePathPrefix, testNs1ID, shard, newBlockStart)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	prefix, ok, err := DataFileSetPathPrefix(opts, testNs1ID, shard, oldBlockStart)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, tierPathPrefix, prefix)
+
+	prefix, ok, err = DataFileSetPathPrefix(opts, testNs1ID, shard, newBlockStart)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, filePathPrefix, prefix)
+
+	// Info files are read across both tiers.
+	results := ReadInfoFilesAllTiers(opts, testNs1ID, shard)
+	require.Equal(t, 2, len(results))
+	for _, result := range results {
+		require.NoError(t, result.Err.Error())
+	}
+
+	// The reader transparently reads the migrated fileset from the tier.
+	r, err := NewReader(testBytesPool, opts.
+		SetInfoReaderBufferSize(testReaderBufferSize).
+		SetDataReaderBufferSize(testReaderBufferSize))
+	require.NoError(t, err)
+	readTestData(t, r, shard, oldBlockStart, entries)
+}
+
+func TestMigrateDataFileSetsBeforeNoTier(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	blockStart := testWriterStart.Truncate(testBlockSize)
+	w := newTestWriter(t, dir)
+	writeTestData(t, w, 0, blockStart, nil, persist.FileSetFlushType)
+
+	opts := testDefaultOpts.SetFilePathPrefix(dir)
+	migrated, err := MigrateDataFileSetsBefore(opts, testNs1ID, 0, blockStart.Add(testBlockSize))
+	require.NoError(t, err)
+	assert.Empty(t, migrated)
+
+	exists, err := DataFileSetExistsAt(dir, testNs1ID, 0, blockStart)
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestOptionsValidateFileSetTierMigrationAge(t *testing.T) {
+	opts := testDefaultOpts.SetFileSetTier(NewLocalDirectoryTier(os.TempDir(), testDefaultOpts))
+	assert.Equal(t, errTierMigrationAge, opts.Validate())
+}
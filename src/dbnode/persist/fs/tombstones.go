@@ -0,0 +1,215 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/digest"
+	"github.com/m3db/m3x/ident"
+)
+
+const (
+	tombstonesFileName     = "tombstones.db"
+	tombstonesTempFileName = "tombstones.db.tmp"
+	tombstonesDigestLen    = 4
+)
+
+var (
+	errTombstonesFileTooShort = errors.New("tombstones file too short")
+	errTombstonesFileCorrupt  = errors.New("tombstones file corrupt")
+)
+
+// ShardTombstone is a series deleted from a shard.
+type ShardTombstone struct {
+	// ID is the ID of the deleted series.
+	ID []byte
+	// DeletedAt is the time the series was deleted at, data for the
+	// series at or before this time is deleted.
+	DeletedAt time.Time
+	// BlockStarts are the starts of the flushed blocks that may still
+	// contain data for the series.
+	BlockStarts []time.Time
+}
+
+// WriteShardTombstones replaces the tombstones persisted for the given namespace
+// and shard. The tombstones are written to a temporary file which is synced and
+// then renamed over the existing file so that a failed write leaves the existing
+// tombstones in place.
+func WriteShardTombstones(
+	opts Options,
+	namespace ident.ID,
+	shard uint32,
+	tombstones []ShardTombstone,
+) error {
+	dir := ShardTombstonesDirPath(opts.FilePathPrefix(), namespace, shard)
+	if err := os.MkdirAll(dir, opts.NewDirectoryMode()); err != nil {
+		return err
+	}
+
+	buf := encodeShardTombstones(tombstones)
+	tempFilePath := path.Join(dir, tombstonesTempFileName)
+	fd, err := OpenWritable(tempFilePath, opts.NewFileMode())
+	if err != nil {
+		return err
+	}
+	if _, err := fd.Write(buf); err != nil {
+		fd.Close()
+		return err
+	}
+	if err := fd.Sync(); err != nil {
+		fd.Close()
+		return err
+	}
+	if err := fd.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tempFilePath, path.Join(dir, tombstonesFileName)); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// ReadShardTombstones reads the tombstones persisted for the given namespace and
+// shard, returning no tombstones if none have been persisted.
+func ReadShardTombstones(
+	opts Options,
+	namespace ident.ID,
+	shard uint32,
+) ([]ShardTombstone, error) {
+	dir := ShardTombstonesDirPath(opts.FilePathPrefix(), namespace, shard)
+	buf, err := ioutil.ReadFile(path.Join(dir, tombstonesFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeShardTombstones(buf)
+}
+
+// encodeShardTombstones encodes each tombstone as its ID length, ID, deleted at
+// time and block starts, preceded by the number of tombstones and followed by a
+// digest of the encoded tombstones.
+func encodeShardTombstones(tombstones []ShardTombstone) []byte {
+	var (
+		buf     = make([]byte, 0, 64*len(tombstones)+binary.MaxVarintLen64)
+		scratch [binary.MaxVarintLen64]byte
+	)
+	putUvarint := func(v uint64) {
+		n := binary.PutUvarint(scratch[:], v)
+		buf = append(buf, scratch[:n]...)
+	}
+	putVarint := func(v int64) {
+		n := binary.PutVarint(scratch[:], v)
+		buf = append(buf, scratch[:n]...)
+	}
+
+	putUvarint(uint64(len(tombstones)))
+	for _, tombstone := range tombstones {
+		putUvarint(uint64(len(tombstone.ID)))
+		buf = append(buf, tombstone.ID...)
+		putVarint(tombstone.DeletedAt.UnixNano())
+		putUvarint(uint64(len(tombstone.BlockStarts)))
+		for _, blockStart := range tombstone.BlockStarts {
+			putVarint(blockStart.UnixNano())
+		}
+	}
+
+	var digestBuf [tombstonesDigestLen]byte
+	binary.LittleEndian.PutUint32(digestBuf[:], digest.Checksum(buf))
+	return append(buf, digestBuf[:]...)
+}
+
+func decodeShardTombstones(buf []byte) ([]ShardTombstone, error) {
+	if len(buf) < tombstonesDigestLen {
+		return nil, errTombstonesFileTooShort
+	}
+	var (
+		contentsLen    = len(buf) - tombstonesDigestLen
+		expectedDigest = binary.LittleEndian.Uint32(buf[contentsLen:])
+	)
+	buf = buf[:contentsLen]
+	if digest.Checksum(buf) != expectedDigest {
+		return nil, errTombstonesFileCorrupt
+	}
+
+	var decodeErr error
+	uvarint := func() uint64 {
+		v, n := binary.Uvarint(buf)
+		if n <= 0 {
+			decodeErr = errTombstonesFileCorrupt
+			return 0
+		}
+		buf = buf[n:]
+		return v
+	}
+	varint := func() int64 {
+		v, n := binary.Varint(buf)
+		if n <= 0 {
+			decodeErr = errTombstonesFileCorrupt
+			return 0
+		}
+		buf = buf[n:]
+		return v
+	}
+
+	numTombstones := uvarint()
+	if decodeErr != nil || numTombstones > uint64(len(buf)) {
+		return nil, errTombstonesFileCorrupt
+	}
+	tombstones := make([]ShardTombstone, 0, int(numTombstones))
+	for i := uint64(0); i < numTombstones; i++ {
+		idLen := uvarint()
+		if decodeErr != nil || idLen > uint64(len(buf)) {
+			return nil, errTombstonesFileCorrupt
+		}
+		id := append([]byte(nil), buf[:idLen]...)
+		buf = buf[idLen:]
+		deletedAt := varint()
+		numBlockStarts := uvarint()
+		if decodeErr != nil || numBlockStarts > uint64(len(buf)) {
+			return nil, errTombstonesFileCorrupt
+		}
+		blockStarts := make([]time.Time, 0, int(numBlockStarts))
+		for j := uint64(0); j < numBlockStarts; j++ {
+			blockStarts = append(blockStarts, time.Unix(0, varint()))
+		}
+		if decodeErr != nil {
+			return nil, decodeErr
+		}
+		tombstones = append(tombstones, ShardTombstone{
+			ID:          id,
+			DeletedAt:   time.Unix(0, deletedAt),
+			BlockStarts: blockStarts,
+		})
+	}
+	if len(buf) != 0 {
+		return nil, errTombstonesFileCorrupt
+	}
+	return tombstones, nil
+}
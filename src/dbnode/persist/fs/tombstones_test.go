@@ -0,0 +1,94 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardTombstonesWriteRead(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	opts := testDefaultOpts.SetFilePathPrefix(dir)
+	tombstones, err := ReadShardTombstones(opts, testNs1ID, 0)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(tombstones))
+
+	blockStart := testWriterStart.Truncate(testBlockSize)
+	expected := []ShardTombstone{
+		{
+			ID:          []byte("foo"),
+			DeletedAt:   blockStart.Add(time.Minute),
+			BlockStarts: []time.Time{blockStart.Add(-testBlockSize), blockStart},
+		},
+		{
+			ID:          []byte("bar"),
+			DeletedAt:   blockStart.Add(time.Second),
+			BlockStarts: []time.Time{},
+		},
+	}
+	require.NoError(t, WriteShardTombstones(opts, testNs1ID, 0, expected))
+
+	tombstones, err = ReadShardTombstones(opts, testNs1ID, 0)
+	require.NoError(t, err)
+	require.Equal(t, len(expected), len(tombstones))
+	for i := range expected {
+		require.Equal(t, expected[i].ID, tombstones[i].ID)
+		require.True(t, expected[i].DeletedAt.Equal(tombstones[i].DeletedAt))
+		require.Equal(t, len(expected[i].BlockStarts), len(tombstones[i].BlockStarts))
+		for j := range expected[i].BlockStarts {
+			require.True(t, expected[i].BlockStarts[j].Equal(tombstones[i].BlockStarts[j]))
+		}
+	}
+
+	// Replacing the tombstones leaves only the new ones.
+	require.NoError(t, WriteShardTombstones(opts, testNs1ID, 0, expected[1:]))
+	tombstones, err = ReadShardTombstones(opts, testNs1ID, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(tombstones))
+	require.Equal(t, []byte("bar"), tombstones[0].ID)
+}
+
+func TestShardTombstonesReadCorrupt(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	opts := testDefaultOpts.SetFilePathPrefix(dir)
+	require.NoError(t, WriteShardTombstones(opts, testNs1ID, 0, []ShardTombstone{
+		{ID: []byte("foo"), DeletedAt: testWriterStart},
+	}))
+
+	filePath := path.Join(ShardTombstonesDirPath(dir, testNs1ID, 0), tombstonesFileName)
+	buf, err := ioutil.ReadFile(filePath)
+	require.NoError(t, err)
+	buf[1] ^= 0xff
+	require.NoError(t, ioutil.WriteFile(filePath, buf, opts.NewFileMode()))
+
+	_, err = ReadShardTombstones(opts, testNs1ID, 0)
+	require.Equal(t, errTombstonesFileCorrupt, err)
+}
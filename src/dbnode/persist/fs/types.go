@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/clock"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs/msgpack"
 	"github.com/m3db/m3/src/dbnode/runtime"
@@ -59,6 +60,14 @@ type DataWriterOpenOptions struct {
 	FileSetContentType persist.FileSetContentType
 	Identifier         FileSetFileIdentifier
 	BlockSize          time.Duration
+	// CodecID is the codec the series data in the fileset is encoded with
+	CodecID codec.ID
+	// ExpiredSeriesRetentionPeriod is the longest series retention period
+	// already expired from the series written to the fileset.
+	ExpiredSeriesRetentionPeriod time.Duration
+	// Staged writes a flushed fileset to the staging directory so that it can
+	// replace an existing fileset once complete, see CommitStagedDataFileSet.
+	Staged bool
 	// Only used when writing snapshot files
 	Snapshot DataWriterSnapshotOptions
 }
@@ -142,6 +151,9 @@ type DataFileSetReader interface {
 	// Range returns the time range associated with data in the volume
 	Range() xtime.Range
 
+	// CodecID returns the codec the series data in the volume is encoded with
+	CodecID() codec.ID
+
 	// Entries returns the count of entries in the volume
 	Entries() int
 
@@ -177,6 +189,9 @@ type DataFileSetSeeker interface {
 	// Range returns the time range associated with data in the volume
 	Range() xtime.Range
 
+	// CodecID returns the codec the series data in the volume is encoded with
+	CodecID() codec.ID
+
 	// Entries returns the count of entries in the volume
 	Entries() int
 
@@ -232,6 +247,14 @@ type DataFileSetSeekerManager interface {
 	// ConcurrentIDBloomFilter returns a concurrent ID bloom filter for a given
 	// shard and block start time
 	ConcurrentIDBloomFilter(shard uint32, start time.Time) (*ManagedConcurrentBloomFilter, error)
+
+	// BlockRange returns the time range covered by the fileset holding a given
+	// shard and block start time, compacted filesets span several blocks.
+	BlockRange(shard uint32, start time.Time) (xtime.Range, error)
+
+	// InvalidateSeekers invalidates the seekers for the fileset holding a
+	// given shard and block start time once it has been replaced.
+	InvalidateSeekers(shard uint32, start time.Time)
 }
 
 // DataBlockRetriever provides a block retriever for TSDB file sets
@@ -443,6 +466,53 @@ type Options interface {
 
 	// FSTOptions returns the fst options
 	FSTOptions() fst.Options
+
+	// SetFileSetTier sets the secondary tier that flushed data filesets are
+	// migrated to, nil disables tiered storage
+	SetFileSetTier(value FileSetTier) Options
+
+	// FileSetTier returns the secondary tier that flushed data filesets are
+	// migrated to, nil if tiered storage is disabled
+	FileSetTier() FileSetTier
+
+	// SetFileSetTierMigrationAge sets the age past the end of a block at which
+	// its flushed data filesets are migrated to the fileset tier
+	SetFileSetTierMigrationAge(value time.Duration) Options
+
+	// FileSetTierMigrationAge returns the age past the end of a block at which
+	// its flushed data filesets are migrated to the fileset tier
+	FileSetTierMigrationAge() time.Duration
+
+	// SetFileSetCompactionBlocks sets the number of adjacent flushed data
+	// filesets that are compacted into a single larger fileset, values less
+	// than two disable compaction
+	SetFileSetCompactionBlocks(value int) Options
+
+	// FileSetCompactionBlocks returns the number of adjacent flushed data
+	// filesets that are compacted into a single larger fileset
+	FileSetCompactionBlocks() int
+
+	// SetFileSetCompactionAge sets the age past the end of a compacted range
+	// at which its flushed data filesets are compacted
+	SetFileSetCompactionAge(value time.Duration) Options
+
+	// FileSetCompactionAge returns the age past the end of a compacted range
+	// at which its flushed data filesets are compacted
+	FileSetCompactionAge() time.Duration
+}
+
+// FileSetTier is a secondary storage tier that holds flushed data filesets
+// once they are older than the tier migration age, allowing historical data to
+// be kept on cheaper storage than the primary file path prefix.
+type FileSetTier interface {
+	// FilePathPrefix returns the local path prefix filesets held by the tier
+	// are read from, it uses the same directory layout as the primary prefix.
+	FilePathPrefix() string
+
+	// MoveFileSet moves a complete fileset from the primary file path prefix
+	// into the tier, the fileset is only removed from the primary file path
+	// prefix once the tier holds a complete copy of it.
+	MoveFileSet(filePathPrefix string, fileset FileSetFile) error
 }
 
 // BlockRetrieverOptions represents the options for block retrieval
@@ -31,6 +31,7 @@ import (
 
 	"github.com/m3db/bloom"
 	"github.com/m3db/m3/src/dbnode/digest"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs/msgpack"
 	"github.com/m3db/m3/src/dbnode/persist/schema"
@@ -71,6 +72,9 @@ type writer struct {
 	start        time.Time
 	snapshotTime time.Time
 	snapshotID   []byte
+	codecID      codec.ID
+	// expiredSeriesRetentionPeriod is recorded in the info file.
+	expiredSeriesRetentionPeriod time.Duration
 
 	currIdx            int64
 	currOffset         int64
@@ -152,6 +156,8 @@ func (w *writer) Open(opts DataWriterOpenOptions) error {
 	w.start = blockStart
 	w.snapshotTime = opts.Snapshot.SnapshotTime
 	w.snapshotID = opts.Snapshot.SnapshotID
+	w.codecID = opts.CodecID
+	w.expiredSeriesRetentionPeriod = opts.ExpiredSeriesRetentionPeriod
 	w.currIdx = 0
 	w.currOffset = 0
 	w.err = nil
@@ -183,7 +189,11 @@ func (w *writer) Open(opts DataWriterOpenOptions) error {
 		dataFilepath = filesetPathFromTimeAndIndex(shardDir, blockStart, nextSnapshotIndex, dataFileSuffix)
 		digestFilepath = filesetPathFromTimeAndIndex(shardDir, blockStart, nextSnapshotIndex, digestFileSuffix)
 	case persist.FileSetFlushType:
-		shardDir = ShardDataDirPath(w.filePathPrefix, namespace, shard)
+		filePathPrefix := w.filePathPrefix
+		if opts.Staged {
+			filePathPrefix = StagingDirPath(filePathPrefix)
+		}
+		shardDir = ShardDataDirPath(filePathPrefix, namespace, shard)
 		if err := os.MkdirAll(shardDir, w.newDirectoryMode); err != nil {
 			return err
 		}
@@ -524,12 +534,14 @@ func (w *writer) writeInfoFileContents(
 	summaries int,
 ) error {
 	info := schema.IndexInfo{
-		BlockStart:   xtime.ToNanoseconds(w.start),
-		SnapshotTime: xtime.ToNanoseconds(w.snapshotTime),
-		SnapshotID:   w.snapshotID,
-		BlockSize:    int64(w.blockSize),
-		Entries:      w.currIdx,
-		MajorVersion: schema.MajorVersion,
+		BlockStart:                   xtime.ToNanoseconds(w.start),
+		SnapshotTime:                 xtime.ToNanoseconds(w.snapshotTime),
+		SnapshotID:                   w.snapshotID,
+		CodecID:                      w.codecID,
+		ExpiredSeriesRetentionPeriod: int64(w.expiredSeriesRetentionPeriod),
+		BlockSize:                    int64(w.blockSize),
+		Entries:                      w.currIdx,
+		MajorVersion:                 schema.MajorVersion,
 		Summaries: schema.IndexSummariesInfo{
 			Summaries: int64(summaries),
 		},
@@ -21,6 +21,7 @@
 package schema
 
 import (
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/persist"
 )
 
@@ -40,6 +41,10 @@ type IndexInfo struct {
 	SnapshotTime int64
 	FileType     persist.FileSetType
 	SnapshotID   []byte
+	CodecID      codec.ID
+	// ExpiredSeriesRetentionPeriod is the longest series retention period
+	// already expired from the fileset by rewriting it, in nanoseconds.
+	ExpiredSeriesRetentionPeriod int64
 }
 
 // IndexSummariesInfo stores metadata about the summaries
@@ -94,4 +99,5 @@ type LogMetadata struct {
 	Namespace   []byte
 	Shard       uint32
 	EncodedTags []byte
+	CodecID     codec.ID
 }
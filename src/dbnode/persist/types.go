@@ -28,6 +28,8 @@ import (
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/m3ninx/index/segment"
 	"github.com/m3db/m3x/ident"
+
+	"github.com/pborman/uuid"
 )
 
 // DataFn is a function that persists a m3db segment for a given ID.
@@ -41,6 +43,11 @@ type DataCloser func() error
 type PreparedDataPersist struct {
 	Persist DataFn
 	Close   DataCloser
+	// Abort is called instead of Close when persisting the data fails, a
+	// fileset replacing an existing one is then discarded rather than
+	// replacing it. It is nil if the data cannot be discarded, in which case
+	// Close should be called instead.
+	Abort DataCloser
 }
 
 // IndexFn is a function that persists a m3ninx MutableSegment.
@@ -98,6 +105,14 @@ type DataPrepareOptions struct {
 	Shard             uint32
 	FileSetType       FileSetType
 	DeleteIfExists    bool
+	// BlockSize overrides the namespace block size recorded for the fileset,
+	// zero uses the namespace block size. It is set when compacting several
+	// adjacent blocks into a single fileset.
+	BlockSize time.Duration
+	// ExpiredSeriesRetentionPeriod is recorded with the fileset when it is
+	// rewritten without the series whose retention period, given by the
+	// series retention rules of the namespace, has expired.
+	ExpiredSeriesRetentionPeriod time.Duration
 	// Snapshot options are applicable to snapshots (index yes, data yes)
 	Snapshot DataPrepareSnapshotOptions
 }
@@ -122,6 +137,7 @@ type IndexPrepareOptions struct {
 // information specific to read/writing snapshot files.
 type DataPrepareSnapshotOptions struct {
 	SnapshotTime time.Time
+	SnapshotID   uuid.UUID
 }
 
 // FileSetType is an enum that indicates what type of files a fileset contains
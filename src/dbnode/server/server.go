@@ -41,6 +41,7 @@ import (
 	"github.com/m3db/m3/src/cmd/services/m3dbnode/config"
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/dod"
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
 	"github.com/m3db/m3/src/dbnode/environment"
 	"github.com/m3db/m3/src/dbnode/kvconfig"
@@ -224,6 +225,11 @@ func Run(runOpts RunOptions) {
 		logger.Warnf("max index query IDs concurrency was not set, falling back to default value")
 	}
 
+	if cfg.Limits != nil {
+		opts = opts.SetLimitsOptions(cfg.Limits.NewOptions().
+			SetInstrumentOptions(iopts))
+	}
+
 	buildReporter := instrument.NewBuildReporter(iopts)
 	if err := buildReporter.Start(); err != nil {
 		logger.Fatalf("unable to start build reporter: %v", err)
@@ -317,6 +323,20 @@ func Run(runOpts RunOptions) {
 		SetTagDecoderPool(tagDecoderPool).
 		SetForceIndexSummariesMmapMemory(cfg.Filesystem.ForceIndexSummariesMmapMemory).
 		SetForceBloomFilterMmapMemory(cfg.Filesystem.ForceBloomFilterMmapMemory)
+	if tierCfg := cfg.Filesystem.Tier; tierCfg != nil {
+		logger.Infof("using fileset tier at %s with migration age %v",
+			tierCfg.FilePathPrefix, tierCfg.MigrationAge)
+		fsopts = fsopts.
+			SetFileSetTier(fs.NewLocalDirectoryTier(tierCfg.FilePathPrefix, fsopts)).
+			SetFileSetTierMigrationAge(tierCfg.MigrationAge)
+	}
+	if compactionCfg := cfg.Filesystem.Compaction; compactionCfg != nil {
+		logger.Infof("compacting filesets of every %d blocks older than %v",
+			compactionCfg.Blocks, compactionCfg.Age)
+		fsopts = fsopts.
+			SetFileSetCompactionBlocks(compactionCfg.Blocks).
+			SetFileSetCompactionAge(compactionCfg.Age)
+	}
 
 	var commitLogQueueSize int
 	specified := cfg.CommitLog.Queue.Size
@@ -346,15 +366,24 @@ func Run(runOpts RunOptions) {
 		commitLogQueueChannelSize = int(float64(commitLogQueueSize) / commitlog.MaximumQueueSizeQueueChannelSizeRatio)
 	}
 
-	opts = opts.SetCommitLogOptions(opts.CommitLogOptions().
+	commitLogStrategy := commitlog.StrategyWriteBehind
+	if cfg.CommitLog.Strategy != nil {
+		commitLogStrategy = *cfg.CommitLog.Strategy
+	}
+
+	commitLogOpts := opts.CommitLogOptions().
 		SetInstrumentOptions(opts.InstrumentOptions()).
 		SetFilesystemOptions(fsopts).
-		SetStrategy(commitlog.StrategyWriteBehind).
+		SetStrategy(commitLogStrategy).
 		SetFlushSize(cfg.CommitLog.FlushMaxBytes).
 		SetFlushInterval(cfg.CommitLog.FlushEvery).
 		SetBacklogQueueSize(commitLogQueueSize).
 		SetBacklogQueueChannelSize(commitLogQueueChannelSize).
-		SetBlockSize(cfg.CommitLog.BlockSize))
+		SetBlockSize(cfg.CommitLog.BlockSize)
+	if v := cfg.CommitLog.GroupCommitMaxBatchSize; v != nil {
+		commitLogOpts = commitLogOpts.SetGroupCommitMaxBatchSize(*v)
+	}
+	opts = opts.SetCommitLogOptions(commitLogOpts)
 
 	// Set the series cache policy
 	seriesCachePolicy := cfg.Cache.SeriesConfiguration().Policy
@@ -467,16 +496,23 @@ func Run(runOpts RunOptions) {
 			scope.SubScope("host-block-metadata-slice-pool")),
 		policy.HostBlockMetadataSlicePool.Capacity)
 
+	repairOpts := opts.RepairOptions().
+		SetAdminClient(m3dbClient).
+		SetRepairInterval(cfg.Repair.Interval).
+		SetRepairTimeOffset(cfg.Repair.Offset).
+		SetRepairTimeJitter(cfg.Repair.Jitter).
+		SetRepairThrottle(cfg.Repair.Throttle).
+		SetRepairCheckInterval(cfg.Repair.CheckInterval).
+		SetRepairDryRun(cfg.Repair.DryRun).
+		SetHostBlockMetadataSlicePool(hostBlockMetadataSlicePool)
+	if cfg.Repair.MaxBlocksPerSecond != nil {
+		repairOpts = repairOpts.
+			SetRepairMaxBlocksPerSecond(*cfg.Repair.MaxBlocksPerSecond)
+	}
+
 	opts = opts.
 		SetRepairEnabled(cfg.Repair.Enabled).
-		SetRepairOptions(opts.RepairOptions().
-			SetAdminClient(m3dbClient).
-			SetRepairInterval(cfg.Repair.Interval).
-			SetRepairTimeOffset(cfg.Repair.Offset).
-			SetRepairTimeJitter(cfg.Repair.Jitter).
-			SetRepairThrottle(cfg.Repair.Throttle).
-			SetRepairCheckInterval(cfg.Repair.CheckInterval).
-			SetHostBlockMetadataSlicePool(hostBlockMetadataSlicePool))
+		SetRepairOptions(repairOpts)
 
 	// Set tchannelthrift options
 	ttopts := tchannelthrift.NewOptions().
@@ -1080,7 +1116,10 @@ func withEncodingAndPoolingOptions(
 		SetContextPool(contextPool).
 		SetEncoderPool(encoderPool).
 		SetSegmentReaderPool(segmentReaderPool).
-		SetBytesPool(bytesPool)
+		SetBytesPool(bytesPool).
+		SetCodecRegistry(encoding.NewCodecRegistry(encodingOpts,
+			poolOptions(policy.EncoderPool, scope.SubScope("codec-pool")),
+			m3tsz.NewCodec(), m3tsz.NewXORCodec(), dod.NewCodec()))
 
 	if opts.SeriesCachePolicy() == series.CacheLRU {
 		var (
@@ -21,9 +21,13 @@
 package block
 
 import (
+	"errors"
 	"io"
+	"sync"
 
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
+	"github.com/m3db/m3/src/dbnode/encoding/dod"
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
@@ -44,6 +48,10 @@ const (
 	defaultCloseContextConcurrency = 4096
 )
 
+var (
+	errNoCodecRegistry = errors.New("no codec registry set")
+)
+
 type options struct {
 	clockOpts               clock.Options
 	databaseBlockAllocSize  int
@@ -56,6 +64,19 @@ type options struct {
 	readerIteratorPool      encoding.ReaderIteratorPool
 	multiReaderIteratorPool encoding.MultiReaderIteratorPool
 	wiredList               *WiredList
+	codecRegistry           encoding.CodecRegistry
+	codecOpts               *codecOptions
+}
+
+// codecOptions caches the options for each codec so that the pools for
+// a codec are only allocated once, it is shared by copies of the options.
+type codecOptions struct {
+	sync.Mutex
+	byCodec map[codec.ID]Options
+}
+
+func newCodecOptions() *codecOptions {
+	return &codecOptions{byCodec: make(map[codec.ID]Options)}
 }
 
 // NewOptions creates new database block options
@@ -77,6 +98,7 @@ func NewOptions() Options {
 		multiReaderIteratorPool: encoding.NewMultiReaderIteratorPool(nil),
 		segmentReaderPool:       segmentReaderPool,
 		bytesPool:               bytesPool,
+		codecOpts:               newCodecOptions(),
 	}
 	o.closeContextWorkers.Init()
 	o.databaseBlockPool.Init(func() DatabaseBlock {
@@ -102,6 +124,8 @@ func NewOptions() Options {
 	})
 	o.segmentReaderPool.Init()
 	o.bytesPool.Init()
+	o.codecRegistry = encoding.NewCodecRegistry(encodingOpts, nil,
+		m3tsz.NewCodec(), m3tsz.NewXORCodec(), dod.NewCodec())
 	return o
 }
 
@@ -214,3 +238,49 @@ func (o *options) SetWiredList(value *WiredList) Options {
 func (o *options) WiredList() *WiredList {
 	return o.wiredList
 }
+
+func (o *options) SetCodecRegistry(value encoding.CodecRegistry) Options {
+	opts := *o
+	opts.codecRegistry = value
+	// Options derived from the previous registry are no longer valid.
+	opts.codecOpts = newCodecOptions()
+	return &opts
+}
+
+func (o *options) CodecRegistry() encoding.CodecRegistry {
+	return o.codecRegistry
+}
+
+func (o *options) OptionsForCodec(id codec.ID) (Options, error) {
+	if id == codec.DefaultID {
+		// The default codec is encoded with the pools set on the options.
+		return o, nil
+	}
+
+	o.codecOpts.Lock()
+	defer o.codecOpts.Unlock()
+
+	if opts, ok := o.codecOpts.byCodec[id]; ok {
+		return opts, nil
+	}
+	if o.codecRegistry == nil {
+		return nil, errNoCodecRegistry
+	}
+	pools, err := o.codecRegistry.Pools(id)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := *o
+	opts.encoderPool = pools.EncoderPool
+	opts.readerIteratorPool = pools.ReaderIteratorPool
+	opts.multiReaderIteratorPool = pools.MultiReaderIteratorPool
+	// Blocks hold on to the options they are allocated with, so blocks for
+	// the codec need their own pool to merge with the codec's pools.
+	opts.databaseBlockPool = NewDatabaseBlockPool(nil)
+	opts.databaseBlockPool.Init(func() DatabaseBlock {
+		return NewDatabaseBlock(timeZero, 0, ts.Segment{}, &opts)
+	})
+	o.codecOpts.byCodec[id] = &opts
+	return &opts, nil
+}
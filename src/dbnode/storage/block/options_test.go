@@ -0,0 +1,61 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package block
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionsForCodecDefault(t *testing.T) {
+	opts := NewOptions()
+	codecOpts, err := opts.OptionsForCodec(codec.DefaultID)
+	require.NoError(t, err)
+	require.Equal(t, opts, codecOpts)
+}
+
+func TestOptionsForCodecCached(t *testing.T) {
+	opts := NewOptions()
+	codecOpts, err := opts.OptionsForCodec(codec.DeltaOfDelta)
+	require.NoError(t, err)
+	require.True(t, opts.EncoderPool() != codecOpts.EncoderPool())
+	require.True(t, opts.MultiReaderIteratorPool() != codecOpts.MultiReaderIteratorPool())
+	require.True(t, opts.DatabaseBlockPool() != codecOpts.DatabaseBlockPool())
+
+	// Copies of the options share the options derived for each codec.
+	again, err := opts.SetDatabaseBlockAllocSize(64).OptionsForCodec(codec.DeltaOfDelta)
+	require.NoError(t, err)
+	require.True(t, codecOpts == again)
+}
+
+func TestOptionsForCodecNotRegistered(t *testing.T) {
+	opts := NewOptions().SetCodecRegistry(encoding.NewCodecRegistry(nil, nil))
+	_, err := opts.OptionsForCodec(codec.XOR)
+	require.Error(t, err)
+
+	opts = NewOptions().SetCodecRegistry(nil)
+	_, err = opts.OptionsForCodec(codec.XOR)
+	require.Equal(t, errNoCodecRegistry, err)
+}
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/topology"
 	"github.com/m3db/m3/src/dbnode/ts"
@@ -272,6 +273,11 @@ type DatabaseBlockRetriever interface {
 		blockStart time.Time,
 		onRetrieve OnRetrieveBlock,
 	) (xio.BlockReader, error)
+
+	// InvalidateBlock invalidates any files opened for a given shard and
+	// block start once the fileset holding it has been replaced, so that
+	// later streams read from the fileset that replaced it.
+	InvalidateBlock(shard uint32, blockStart time.Time)
 }
 
 // DatabaseShardBlockRetriever is a block retriever bound to a shard.
@@ -433,4 +439,14 @@ type Options interface {
 
 	// WiredList returns the database block wired list
 	WiredList() *WiredList
+
+	// SetCodecRegistry sets the registry of codecs that blocks can be encoded with
+	SetCodecRegistry(value encoding.CodecRegistry) Options
+
+	// CodecRegistry returns the registry of codecs that blocks can be encoded with
+	CodecRegistry() encoding.CodecRegistry
+
+	// OptionsForCodec returns the options with the pools to encode and decode
+	// blocks with a codec, the options are returned as is for the default codec
+	OptionsForCodec(id codec.ID) (Options, error)
 }
@@ -29,6 +29,7 @@ import (
 
 	"github.com/m3db/m3/src/cluster/shard"
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
 	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
@@ -61,6 +62,7 @@ type newIteratorFn func(opts commitlog.IteratorOpts) (
 	iter commitlog.Iterator, corruptFiles []commitlog.ErrorWithPath, err error)
 type snapshotFilesFn func(filePathPrefix string, namespace ident.ID, shard uint32) (fs.FileSetFilesSlice, error)
 type newReaderFn func(bytesPool pool.CheckedBytesPool, opts fs.Options) (fs.DataFileSetReader, error)
+type snapshotMetadataFilesFn func(opts fs.Options) ([]fs.SnapshotMetadata, []fs.SnapshotMetadataErrorWithPaths, error)
 
 type commitLogSource struct {
 	opts Options
@@ -69,9 +71,10 @@ type commitLogSource struct {
 	// Filesystem inspection capture before node was started.
 	inspection fs.Inspection
 
-	newIteratorFn   newIteratorFn
-	snapshotFilesFn snapshotFilesFn
-	newReaderFn     newReaderFn
+	newIteratorFn           newIteratorFn
+	snapshotFilesFn         snapshotFilesFn
+	snapshotMetadataFilesFn snapshotMetadataFilesFn
+	newReaderFn             newReaderFn
 
 	metrics commitLogSourceDataAndIndexMetrics
 }
@@ -98,9 +101,10 @@ func newCommitLogSource(opts Options, inspection fs.Inspection) bootstrap.Source
 
 		inspection: inspection,
 
-		newIteratorFn:   commitlog.NewIterator,
-		snapshotFilesFn: fs.SnapshotFiles,
-		newReaderFn:     fs.NewReader,
+		newIteratorFn:           commitlog.NewIterator,
+		snapshotFilesFn:         fs.SnapshotFiles,
+		snapshotMetadataFilesFn: fs.SortedSnapshotMetadataFiles,
+		newReaderFn:             fs.NewReader,
 
 		metrics: newCommitLogSourceDataAndIndexMetrics(scope),
 	}
@@ -137,7 +141,10 @@ func (s *commitLogSource) AvailableData(
 //        has one exception which is in the case where there is no minimimum snapshot time across
 //        shards (the code treats this case as minimum snapshot time across shards == blockStart).
 //        In that case, we replay all commit log entries whose system timestamps overlap the range
-//        [blockStart.Add(-bufferFuture), blockStart.Add(blockSize).Add(bufferPast)].
+//        [blockStart.Add(-bufferFuture), blockStart.Add(blockSize).Add(bufferPast)]. In addition,
+//        if a snapshot metadata file exists then only the commit log file that it records (the one
+//        that was rotated to right before that snapshot began) and the ones after it are replayed
+//        since every write in the earlier commit log files was already flushed or snapshotted.
 //    4.  For each shard/blockStart combination, merge all of the M3TSZ encoders that we created from
 //        reading the commit log along with the data available in the corresponding snapshot file.
 //
@@ -196,6 +203,31 @@ func (s *commitLogSource) ReadData(
 		return result.NewDataBootstrapResult(), nil
 	}
 
+	src, err := s.sourceForCodec(ns.Options().CodecID())
+	if err != nil {
+		return nil, err
+	}
+	return src.readData(ns, shardsTimeRanges, runOpts)
+}
+
+// sourceForCodec returns a copy of the source whose result options encode
+// and merge data with the pools of the given codec.
+func (s *commitLogSource) sourceForCodec(id codec.ID) (*commitLogSource, error) {
+	resultOpts, err := s.opts.ResultOptions().OptionsForCodec(id)
+	if err != nil {
+		return nil, err
+	}
+	src := *s
+	src.opts = s.opts.SetResultOptions(resultOpts)
+	return &src, nil
+}
+
+func (s *commitLogSource) readData(
+	ns namespace.Metadata,
+	shardsTimeRanges result.ShardTimeRanges,
+	runOpts bootstrap.RunOptions,
+) (result.DataBootstrapResult, error) {
+
 	var (
 		// Emit bootstrapping gauge for duration of ReadData
 		doneReadingData        = s.metrics.data.emitBootstrapping()
@@ -780,7 +812,78 @@ func (s *commitLogSource) newReadCommitLogPredBasedOnAvailableSnapshotFiles(
 	// construct a new predicate based on the data structure we constructed earlier where the new
 	// predicate will check if there is any overlap between a commit log file and a temporary range
 	// we construct that begins with the minimum snapshot time and ends with the end of that block + bufferPast.
-	return s.newReadCommitLogPred(ns, minimumMostRecentSnapshotTimeByBlock), mostRecentCompleteSnapshotByBlockShard, nil
+	readCommitLogPred := s.newReadCommitLogPred(ns, minimumMostRecentSnapshotTimeByBlock)
+
+	// Finally, if a snapshot has completed across all namespaces and shards then every write in the
+	// commit log files that precede the one which was rotated to when that snapshot began has already
+	// been flushed or captured by a snapshot, so we only need to read that commit log file and the ones
+	// that came after it.
+	snapshotCommitLog, ok, err := s.mostRecentSnapshotCommitLog(ns)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ok {
+		readCommitLogPred = s.newReadCommitLogPredAfterSnapshot(readCommitLogPred, snapshotCommitLog)
+	}
+
+	return readCommitLogPred, mostRecentCompleteSnapshotByBlockShard, nil
+}
+
+// mostRecentSnapshotCommitLog returns the commit log file recorded in the most recent snapshot
+// metadata file, if any, which is the first commit log file that may contain writes that are not
+// captured by the snapshot.
+func (s *commitLogSource) mostRecentSnapshotCommitLog(
+	ns namespace.Metadata,
+) (commitlog.File, bool, error) {
+	if !ns.Options().SnapshotEnabled() {
+		// Snapshots never capture the data of namespaces that have them disabled.
+		return commitlog.File{}, false, nil
+	}
+
+	fsOpts := s.opts.CommitLogOptions().FilesystemOptions()
+	snapshotMetadatas, _, err := s.snapshotMetadataFilesFn(fsOpts)
+	if err != nil {
+		return commitlog.File{}, false, err
+	}
+	if len(snapshotMetadatas) == 0 {
+		return commitlog.File{}, false, nil
+	}
+
+	mostRecent := snapshotMetadatas[len(snapshotMetadatas)-1]
+	file, err := commitlog.FileFromSnapshotIdentifier(mostRecent.CommitlogIdentifier)
+	if err != nil {
+		// Not fatal since we can still decide which commit logs to read based
+		// on the snapshot times alone.
+		s.log.
+			WithFields(
+				xlog.NewField("snapshotMetadataFile", mostRecent.MetadataFilePath),
+				xlog.NewField("error", err.Error()),
+			).
+			Errorf("unable to determine commit log file from snapshot metadata")
+		return commitlog.File{}, false, nil
+	}
+
+	s.log.Infof(
+		"most recent snapshot: %s recorded commit log: %s",
+		mostRecent.ID.UUID.String(), file.FilePath)
+	return file, true, nil
+}
+
+func (s *commitLogSource) newReadCommitLogPredAfterSnapshot(
+	pred commitlog.FileFilterPredicate,
+	snapshotCommitLog commitlog.File,
+) commitlog.FileFilterPredicate {
+	return func(f commitlog.File) bool {
+		if f.IsBefore(snapshotCommitLog) {
+			s.log.
+				Infof(
+					"opting to skip commit log: %s with start: %s and duration: %s captured by snapshot",
+					f.FilePath, f.Start.String(), f.Duration.String())
+			return false
+		}
+
+		return pred(f)
+	}
 }
 
 func (s *commitLogSource) newReadCommitLogPred(
@@ -970,7 +1073,7 @@ func (s *commitLogSource) shouldIncludeInIndex(
 	shard uint32,
 	ts time.Time,
 	highestShard uint32,
-	indexBlockSize time.Duration,
+	indexOptions namespace.IndexOptions,
 	bootstrapRangesByShard []xtime.Ranges,
 ) bool {
 	if shard > highestShard {
@@ -987,8 +1090,9 @@ func (s *commitLogSource) shouldIncludeInIndex(
 
 	// Check if the timestamp corresponds to one of the index blocks we're
 	// trying to bootstrap.
-	indexBlockStart := ts.Truncate(indexBlockSize)
-	indexBlockEnd := indexBlockStart.Add(indexBlockSize)
+	indexBlockStart := indexOptions.BlockStartForTime(ts)
+	indexBlockEnd := indexBlockStart.Add(
+		indexOptions.BlockSizeForBlockStart(indexBlockStart))
 	indexBlockRange := xtime.Range{
 		Start: indexBlockStart,
 		End:   indexBlockEnd,
@@ -1347,10 +1451,9 @@ func (s *commitLogSource) ReadIndex(
 	var (
 		indexResult    = result.NewIndexBootstrapResult()
 		indexResults   = indexResult.IndexResults()
-		indexOptions   = ns.Options().IndexOptions()
-		indexBlockSize = indexOptions.BlockSize()
-		resultOptions  = s.opts.ResultOptions()
-		blockSize      = ns.Options().RetentionOptions().BlockSize()
+		indexOptions  = ns.Options().IndexOptions()
+		resultOptions = s.opts.ResultOptions()
+		blockSize     = ns.Options().RetentionOptions().BlockSize()
 	)
 
 	// Determine which commit log files we need to read based on which snapshot
@@ -1386,7 +1489,7 @@ func (s *commitLogSource) ReadIndex(
 			for block := range val.Blocks.AllBlocks() {
 				s.maybeAddToIndex(
 					id, val.Tags, shard, highestShard, block.ToTime(), bootstrapRangesByShard,
-					indexResults, indexOptions, indexBlockSize, resultOptions)
+					indexResults, indexOptions, resultOptions)
 			}
 		}
 	}
@@ -1409,7 +1512,7 @@ func (s *commitLogSource) ReadIndex(
 
 		s.maybeAddToIndex(
 			series.ID, series.Tags, series.Shard, highestShard, dp.Timestamp, bootstrapRangesByShard,
-			indexResults, indexOptions, indexBlockSize, resultOptions)
+			indexResults, indexOptions, resultOptions)
 	}
 
 	if iterErr := iter.Err(); iterErr != nil {
@@ -1495,11 +1598,10 @@ func (s commitLogSource) maybeAddToIndex(
 	bootstrapRangesByShard []xtime.Ranges,
 	indexResults result.IndexResults,
 	indexOptions namespace.IndexOptions,
-	indexBlockSize time.Duration,
 	resultOptions result.Options,
 ) error {
 	if !s.shouldIncludeInIndex(
-		shard, blockStart, highestShard, indexBlockSize, bootstrapRangesByShard) {
+		shard, blockStart, highestShard, indexOptions, bootstrapRangesByShard) {
 		return nil
 	}
 
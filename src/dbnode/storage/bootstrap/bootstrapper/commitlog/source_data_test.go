@@ -23,6 +23,7 @@ package commitlog
 import (
 	"fmt"
 	"io"
+	"path"
 	"reflect"
 	"sort"
 	"testing"
@@ -46,6 +47,7 @@ import (
 	xtime "github.com/m3db/m3x/time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -386,6 +388,64 @@ func TestItMergesSnapshotsAndCommitLogs(t *testing.T) {
 		expectedValues, blockSize, res.ShardResults(), opts))
 }
 
+func TestReadCommitLogPredSkipsCommitLogsBeforeSnapshot(t *testing.T) {
+	var (
+		opts             = testDefaultOpts
+		md               = testNsMetadata(t)
+		blockSize        = md.Options().RetentionOptions().BlockSize()
+		start            = time.Now().Truncate(blockSize).Add(-blockSize)
+		commitLogsDir    = fs.CommitLogsDirPath(opts.CommitLogOptions().FilesystemOptions().FilePathPrefix())
+		newCommitLogFile = func(index int64) commitlog.File {
+			return commitlog.File{
+				FilePath: path.Join(commitLogsDir, fmt.Sprintf("commitlog-%d-%d.db", start.UnixNano(), index)),
+				Start:    start,
+				Duration: blockSize,
+				Index:    index,
+			}
+		}
+		files      = []commitlog.File{newCommitLogFile(0), newCommitLogFile(1), newCommitLogFile(2)}
+		inspection = fs.Inspection{}
+		ranges     = xtime.Ranges{}.AddRange(xtime.Range{Start: start, End: start.Add(blockSize)})
+	)
+	for _, f := range files {
+		inspection.SortedCommitLogFiles = append(inspection.SortedCommitLogFiles, f.FilePath)
+	}
+
+	src := newCommitLogSource(opts, inspection).(*commitLogSource)
+	src.snapshotMetadataFilesFn = func(fs.Options) ([]fs.SnapshotMetadata, []fs.SnapshotMetadataErrorWithPaths, error) {
+		return []fs.SnapshotMetadata{
+			{
+				ID:                  fs.SnapshotMetadataIdentifier{Index: 0, UUID: uuid.NewRandom()},
+				CommitlogIdentifier: files[0].SnapshotIdentifier(),
+			},
+			{
+				ID:                  fs.SnapshotMetadataIdentifier{Index: 1, UUID: uuid.NewRandom()},
+				CommitlogIdentifier: files[1].SnapshotIdentifier(),
+			},
+		}, nil, nil
+	}
+
+	// Only the commit log rotated to by the most recent snapshot and the ones
+	// after it need to be read.
+	pred, _, err := src.newReadCommitLogPredBasedOnAvailableSnapshotFiles(
+		md, result.ShardTimeRanges{0: ranges}, map[uint32]fs.FileSetFilesSlice{})
+	require.NoError(t, err)
+	require.False(t, pred(files[0]))
+	require.True(t, pred(files[1]))
+	require.True(t, pred(files[2]))
+
+	// Snapshots do not capture namespaces that have snapshotting disabled.
+	noSnapshotMd, err := namespace.NewMetadata(testNamespaceID,
+		namespace.NewOptions().SetSnapshotEnabled(false))
+	require.NoError(t, err)
+	pred, _, err = src.newReadCommitLogPredBasedOnAvailableSnapshotFiles(
+		noSnapshotMd, result.ShardTimeRanges{0: ranges}, map[uint32]fs.FileSetFilesSlice{})
+	require.NoError(t, err)
+	for _, f := range files {
+		require.True(t, pred(f))
+	}
+}
+
 type testValue struct {
 	s ts.Series
 	t time.Time
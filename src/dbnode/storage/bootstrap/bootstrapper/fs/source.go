@@ -182,8 +182,17 @@ func (s *fileSystemSource) shardAvailability(
 		return xtime.Ranges{}
 	}
 
-	readInfoFilesResults := fs.ReadInfoFiles(s.fsopts.FilePathPrefix(),
-		namespace, shard, s.fsopts.InfoReaderBufferSize(), s.fsopts.DecodingOptions())
+	// NB: Finish or discard any fileset rewrite interrupted by a restart
+	// before deciding which blocks are available on disk.
+	if err := fs.RecoverStagedDataFileSets(s.fsopts, namespace, shard); err != nil {
+		s.log.WithFields(
+			xlog.NewField("shard", shard),
+			xlog.NewField("namespace", namespace.String()),
+			xlog.NewField("error", err.Error()),
+		).Error("unable to recover staged filesets in shardAvailability")
+	}
+
+	readInfoFilesResults := fs.ReadInfoFilesAllTiers(s.fsopts, namespace, shard)
 
 	var tr xtime.Ranges
 	for i := 0; i < len(readInfoFilesResults); i++ {
@@ -266,21 +275,19 @@ func (s *fileSystemSource) enqueueReadersGroupedByBlockSize(
 	readerPool *readerPool,
 	readersCh chan<- timeWindowReaders,
 ) {
-	// First bucket the shard time ranges by block size
-	var blockSize time.Duration
+	// Group the shard time ranges by block size
+	var groupedByBlockSize []shardTimeRangesTimeWindowGroup
 	switch run {
 	case bootstrapDataRunType:
-		blockSize = ns.Options().RetentionOptions().BlockSize()
+		blockSize := ns.Options().RetentionOptions().BlockSize()
+		groupedByBlockSize = newShardTimeRangesTimeWindowGroups(shardTimeRanges, blockSize)
 	case bootstrapIndexRunType:
-		blockSize = ns.Options().IndexOptions().BlockSize()
+		groupedByBlockSize = newShardTimeRangesIndexBlockGroups(shardTimeRanges,
+			ns.Options().IndexOptions())
 	default:
 		panic(fmt.Errorf("unrecognized run type: %d", run))
 	}
 
-	// Group them by block size
-	groupFn := newShardTimeRangesTimeWindowGroups
-	groupedByBlockSize := groupFn(shardTimeRanges, blockSize)
-
 	// Now enqueue across all shards by block size
 	for _, group := range groupedByBlockSize {
 		readers := make(map[shardID]shardReaders, len(group.ranges))
@@ -298,8 +305,7 @@ func (s *fileSystemSource) newShardReaders(
 	shard uint32,
 	tr xtime.Ranges,
 ) shardReaders {
-	readInfoFilesResults := fs.ReadInfoFiles(s.fsopts.FilePathPrefix(),
-		ns.ID(), shard, s.fsopts.InfoReaderBufferSize(), s.fsopts.DecodingOptions())
+	readInfoFilesResults := fs.ReadInfoFilesAllTiers(s.fsopts, ns.ID(), shard)
 	if len(readInfoFilesResults) == 0 {
 		return shardReaders{} // No readers
 	}
@@ -324,13 +330,26 @@ func (s *fileSystemSource) newShardReaders(
 		blockStart := xtime.FromNanoseconds(info.BlockStart)
 		if !tr.Overlaps(xtime.Range{
 			Start: blockStart,
-			End:   blockStart.Add(ns.Options().RetentionOptions().BlockSize()),
+			End:   blockStart.Add(time.Duration(info.BlockSize)),
 		}) {
 			// Errors are marked unfulfilled by markRunResultErrorsAndUnfulfilled
 			// and will be re-attempted by the next bootstrapper
 			continue
 		}
 
+		if info.CodecID != ns.Options().CodecID() {
+			s.log.WithFields(
+				xlog.NewField("shard", shard),
+				xlog.NewField("namespace", ns.ID().String()),
+				xlog.NewField("blockStart", blockStart.String()),
+				xlog.NewField("filesetCodec", info.CodecID.String()),
+				xlog.NewField("namespaceCodec", ns.Options().CodecID().String()),
+			).Error("fs bootstrapper skipping fileset encoded with a different codec")
+			// Errors are marked unfulfilled by markRunResultErrorsAndUnfulfilled
+			// and will be re-attempted by the next bootstrapper
+			continue
+		}
+
 		r, err := readerPool.get()
 		if err != nil {
 			s.log.Errorf("unable to get reader from pool")
@@ -368,13 +387,13 @@ func (s *fileSystemSource) bootstrapFromReaders(
 	ns namespace.Metadata,
 	run runType,
 	runOpts bootstrap.RunOptions,
+	resultOpts result.Options,
 	readerPool *readerPool,
 	retriever block.DatabaseBlockRetriever,
 	readersCh <-chan timeWindowReaders,
 ) *runResult {
 	var (
 		runResult         = newRunResult()
-		resultOpts        = s.opts.ResultOptions()
 		shardRetrieverMgr block.DatabaseShardBlockRetrieverManager
 		wg                sync.WaitGroup
 		processors        xsync.WorkerPool
@@ -745,8 +764,9 @@ func (s *fileSystemSource) persistBootstrapIndexSegment(
 	// determine if we covered a full block exactly (which should
 	// occur since we always group readers by block size)
 	min, max := requestedRanges.MinMax()
-	blockSize := ns.Options().IndexOptions().BlockSize()
-	blockStart := min.Truncate(blockSize)
+	indexOpts := ns.Options().IndexOptions()
+	blockStart := indexOpts.BlockStartForTime(min)
+	blockSize := indexOpts.BlockSizeForBlockStart(blockStart)
 
 	shards := make(map[uint32]struct{})
 	expectedRanges := make(result.ShardTimeRanges, len(requestedRanges))
@@ -963,6 +983,13 @@ func (s *fileSystemSource) read(
 		}
 	}
 
+	// Blocks are allocated with the pools of the codec that the namespace
+	// data is encoded with.
+	resultOpts, err := s.opts.ResultOptions().OptionsForCodec(md.Options().CodecID())
+	if err != nil {
+		return nil, err
+	}
+
 	// Create a reader pool once per bootstrap as we don't really want to
 	// allocate and keep around readers outside of the bootstrapping process,
 	// hence why its created on demand each time.
@@ -971,7 +998,7 @@ func (s *fileSystemSource) read(
 	go s.enqueueReaders(md, run, runOpts, shardsTimeRanges,
 		readerPool, readersCh)
 	bootstrapFromDataReadersResult := s.bootstrapFromReaders(md, run, runOpts,
-		readerPool, blockRetriever, readersCh)
+		resultOpts, readerPool, blockRetriever, readersCh)
 
 	// Merge any existing results if necessary
 	setOrMergeResult(bootstrapFromDataReadersResult)
@@ -1048,7 +1075,7 @@ func (s *fileSystemSource) bootstrapFromIndexPersistedBlocks(
 		fulfilled: result.ShardTimeRanges{},
 	}
 
-	indexBlockSize := ns.Options().IndexOptions().BlockSize()
+	indexOpts := ns.Options().IndexOptions()
 	infoFiles := fs.ReadIndexInfoFiles(s.fsopts.FilePathPrefix(), ns.ID(),
 		s.fsopts.InfoReaderBufferSize())
 
@@ -1067,7 +1094,7 @@ func (s *fileSystemSource) bootstrapFromIndexPersistedBlocks(
 		indexBlockStart := xtime.UnixNano(info.BlockStart).ToTime()
 		indexBlockRange := xtime.Range{
 			Start: indexBlockStart,
-			End:   indexBlockStart.Add(indexBlockSize),
+			End:   indexBlockStart.Add(indexOpts.BlockSizeForBlockStart(indexBlockStart)),
 		}
 		willFulfill := result.ShardTimeRanges{}
 		for _, shard := range info.Shards {
@@ -1301,6 +1328,44 @@ func newShardTimeRangesTimeWindowGroups(
 	return grouped
 }
 
+// newShardTimeRangesIndexBlockGroups groups the shard time ranges by index
+// block, index blocks starting before a change of the index block size keep
+// the previous block size.
+func newShardTimeRangesIndexBlockGroups(
+	shardTimeRanges result.ShardTimeRanges,
+	indexOpts namespace.IndexOptions,
+) []shardTimeRangesTimeWindowGroup {
+	change := indexOpts.BlockSizeChange()
+	if change.IsZero() {
+		return newShardTimeRangesTimeWindowGroups(shardTimeRanges, indexOpts.BlockSize())
+	}
+
+	min, max := shardTimeRanges.MinMax()
+	if !change.Start.After(min) {
+		return newShardTimeRangesTimeWindowGroups(shardTimeRanges, indexOpts.BlockSize())
+	}
+	if !change.Start.Before(max) {
+		return newShardTimeRangesTimeWindowGroups(shardTimeRanges, change.PreviousBlockSize)
+	}
+
+	var (
+		before = make(result.ShardTimeRanges, len(shardTimeRanges))
+		after  = make(result.ShardTimeRanges, len(shardTimeRanges))
+	)
+	for shard, tr := range shardTimeRanges {
+		if r := tr.RemoveRange(xtime.Range{Start: change.Start, End: max}); !r.IsEmpty() {
+			before[shard] = r
+		}
+		if r := tr.RemoveRange(xtime.Range{Start: min, End: change.Start}); !r.IsEmpty() {
+			after[shard] = r
+		}
+	}
+
+	grouped := newShardTimeRangesTimeWindowGroups(before, change.PreviousBlockSize)
+	return append(grouped,
+		newShardTimeRangesTimeWindowGroups(after, indexOpts.BlockSize())...)
+}
+
 func minTime(x, y time.Time) time.Time {
 	if x.Before(y) {
 		return x
@@ -147,6 +147,14 @@ func (s *peersSource) ReadData(
 		persistFlush = persist
 	}
 
+	// Blocks fetched from peers are allocated with the pools of the codec
+	// that the namespace data is encoded with.
+	resultOpts, err := s.opts.ResultOptions().OptionsForCodec(
+		nsMetadata.Options().CodecID())
+	if err != nil {
+		return nil, err
+	}
+
 	result := result.NewDataBootstrapResult()
 	session, err := s.opts.AdminClient().DefaultAdminSession()
 	if err != nil {
@@ -161,7 +169,6 @@ func (s *peersSource) ReadData(
 		persistenceWorkerDoneCh = make(chan struct{})
 		persistenceMaxQueueSize = s.opts.PersistenceMaxQueueSize()
 		persistenceQueue        = make(chan persistenceFlush, persistenceMaxQueueSize)
-		resultOpts              = s.opts.ResultOptions()
 		count                   = len(shardsTimeRanges)
 		concurrency             = s.opts.DefaultShardConcurrency()
 		blockSize               = nsMetadata.Options().RetentionOptions().BlockSize()
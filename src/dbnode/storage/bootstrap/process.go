@@ -286,11 +286,17 @@ func (b bootstrapProcess) targetRangesForData(
 	at time.Time,
 	ropts retention.Options,
 ) []TargetRange {
+	blockSize := ropts.BlockSize()
 	return b.targetRanges(at, targetRangesOptions{
 		retentionPeriod: ropts.RetentionPeriod(),
-		blockSize:       ropts.BlockSize(),
-		bufferPast:      ropts.BufferPast(),
-		bufferFuture:    ropts.BufferFuture(),
+		blockStartFn: func(t time.Time) time.Time {
+			return t.Truncate(blockSize)
+		},
+		blockSizeFn: func(time.Time) time.Duration {
+			return blockSize
+		},
+		bufferPast:   ropts.BufferPast(),
+		bufferFuture: ropts.BufferFuture(),
 	})
 }
 
@@ -299,9 +305,12 @@ func (b bootstrapProcess) targetRangesForIndex(
 	ropts retention.Options,
 	idxopts namespace.IndexOptions,
 ) []TargetRange {
+	// NB: Index blocks starting before a change of the index block size keep
+	// the previous block size.
 	return b.targetRanges(at, targetRangesOptions{
 		retentionPeriod: ropts.RetentionPeriod(),
-		blockSize:       idxopts.BlockSize(),
+		blockStartFn:    idxopts.BlockStartForTime,
+		blockSizeFn:     idxopts.BlockSizeForBlockStart,
 		bufferPast:      ropts.BufferPast(),
 		bufferFuture:    ropts.BufferFuture(),
 	})
@@ -309,7 +318,8 @@ func (b bootstrapProcess) targetRangesForIndex(
 
 type targetRangesOptions struct {
 	retentionPeriod time.Duration
-	blockSize       time.Duration
+	blockStartFn    func(t time.Time) time.Time
+	blockSizeFn     func(blockStart time.Time) time.Duration
 	bufferPast      time.Duration
 	bufferFuture    time.Duration
 }
@@ -318,18 +328,12 @@ func (b bootstrapProcess) targetRanges(
 	at time.Time,
 	opts targetRangesOptions,
 ) []TargetRange {
-	start := at.Add(-opts.retentionPeriod).
-		Truncate(opts.blockSize)
-	midPoint := at.
-		Add(-opts.blockSize).
-		Add(-opts.bufferPast).
-		Truncate(opts.blockSize).
-		// NB(r): Since "end" is exclusive we need to add a
-		// an extra block size when specifying the end time.
-		Add(opts.blockSize)
-	cutover := at.Add(opts.bufferFuture).
-		Truncate(opts.blockSize).
-		Add(opts.blockSize)
+	start := opts.blockStartFn(at.Add(-opts.retentionPeriod))
+	midPoint := opts.blockStartFn(at.Add(-opts.bufferPast))
+	// NB(r): Since "end" is exclusive we need to add a
+	// an extra block size when specifying the end time.
+	cutoverBlockStart := opts.blockStartFn(at.Add(opts.bufferFuture))
+	cutover := cutoverBlockStart.Add(opts.blockSizeFn(cutoverBlockStart))
 
 	// NB(r): We want the large initial time range bootstrapped to
 	// bootstrap with persistence so we don't keep the full raw
@@ -22,6 +22,7 @@ package result
 
 import (
 	"github.com/m3db/m3/src/dbnode/clock"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/series"
 	"github.com/m3db/m3x/instrument"
@@ -82,6 +83,17 @@ func (o *options) DatabaseBlockOptions() block.Options {
 	return o.blockOpts
 }
 
+func (o *options) OptionsForCodec(id codec.ID) (Options, error) {
+	blockOpts, err := o.blockOpts.OptionsForCodec(id)
+	if err != nil {
+		return nil, err
+	}
+	if blockOpts == o.blockOpts {
+		return o, nil
+	}
+	return o.SetDatabaseBlockOptions(blockOpts), nil
+}
+
 func (o *options) SetNewBlocksLen(value int) Options {
 	opts := *o
 	opts.newBlocksLen = value
@@ -103,7 +103,7 @@ func (r IndexResults) GetOrAddSegment(
 	// there is only one entry for this time is because index blocks must be a
 	// positive multiple of the data block size, making it easy to map a data
 	// block entry to at most one index block entry.
-	blockStart := t.Truncate(idxopts.BlockSize())
+	blockStart := idxopts.BlockStartForTime(t)
 	blockStartNanos := xtime.ToUnixNano(blockStart)
 
 	block, exists := r[blockStartNanos]
@@ -139,12 +139,12 @@ func (r IndexResults) MarkFulfilled(
 	// there is only one entry for this time is because index blocks must be a
 	// positive multiple of the data block size, making it easy to map a data
 	// block entry to at most one index block entry.
-	blockStart := t.Truncate(idxopts.BlockSize())
+	blockStart := idxopts.BlockStartForTime(t)
 	blockStartNanos := xtime.ToUnixNano(blockStart)
 
 	blockRange := xtime.Range{
 		Start: blockStart,
-		End:   blockStart.Add(idxopts.BlockSize()),
+		End:   blockStart.Add(idxopts.BlockSizeForBlockStart(blockStart)),
 	}
 
 	// First check fulfilled is correct
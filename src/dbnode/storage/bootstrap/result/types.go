@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/clock"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/series"
 	"github.com/m3db/m3/src/m3ninx/index/segment"
@@ -143,6 +144,10 @@ type Options interface {
 	// DatabaseBlockOptions returns the database block options.
 	DatabaseBlockOptions() block.Options
 
+	// OptionsForCodec returns the options with database block options that
+	// allocate blocks and their encoders with the pools of the given codec.
+	OptionsForCodec(id codec.ID) (Options, error)
+
 	// SetNewBlocksLen sets the size of a new blocks map size.
 	SetNewBlocksLen(value int) Options
 
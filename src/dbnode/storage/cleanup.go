@@ -29,6 +29,8 @@ import (
 	"github.com/m3db/m3/src/dbnode/persist/fs"
 	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
 	"github.com/m3db/m3/src/dbnode/retention"
+	"github.com/m3db/m3/src/dbnode/storage/block"
+	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	xerrors "github.com/m3db/m3x/errors"
 	"github.com/m3db/m3x/ident"
 
@@ -37,10 +39,14 @@ import (
 
 type commitLogFilesFn func(commitlog.Options) ([]commitlog.File, []commitlog.ErrorWithPath, error)
 
+type snapshotMetadataFilesFn func(fs.Options) ([]fs.SnapshotMetadata, []fs.SnapshotMetadataErrorWithPaths, error)
+
 type deleteFilesFn func(files []string) error
 
 type deleteInactiveDirectoriesFn func(parentDirPath string, activeDirNames []string) error
 
+type migrateDataFileSetsFn func(opts fs.Options, namespace ident.ID, shard uint32, t time.Time) ([]time.Time, error)
+
 // Narrow interface so as not to expose all the functionality of the commitlog
 // to the cleanup manager.
 type activeCommitlogs interface {
@@ -58,24 +64,34 @@ type cleanupManager struct {
 	filePathPrefix              string
 	commitLogsDir               string
 	commitLogFilesFn            commitLogFilesFn
+	snapshotMetadataFilesFn     snapshotMetadataFilesFn
 	deleteFilesFn               deleteFilesFn
 	deleteInactiveDirectoriesFn deleteInactiveDirectoriesFn
+	migrateDataFileSetsFn       migrateDataFileSetsFn
 	cleanupInProgress           bool
 	metrics                     cleanupManagerMetrics
 }
 
 type cleanupManagerMetrics struct {
-	status               tally.Gauge
-	corruptCommitlogFile tally.Counter
-	deletedCommitlogFile tally.Counter
+	status                      tally.Gauge
+	corruptCommitlogFile        tally.Counter
+	deletedCommitlogFile        tally.Counter
+	corruptSnapshotMetadataFile tally.Counter
+	deletedSnapshotMetadataFile tally.Counter
+	migratedDataFileSet         tally.Counter
 }
 
 func newCleanupManagerMetrics(scope tally.Scope) cleanupManagerMetrics {
 	clScope := scope.SubScope("commitlog")
+	smScope := scope.SubScope("snapshot-metadata")
+	tierScope := scope.SubScope("fileset-tier")
 	return cleanupManagerMetrics{
-		status:               scope.Gauge("cleanup"),
-		corruptCommitlogFile: clScope.Counter("corrupt"),
-		deletedCommitlogFile: clScope.Counter("deleted"),
+		status:                      scope.Gauge("cleanup"),
+		corruptCommitlogFile:        clScope.Counter("corrupt"),
+		deletedCommitlogFile:        clScope.Counter("deleted"),
+		corruptSnapshotMetadataFile: smScope.Counter("corrupt"),
+		deletedSnapshotMetadataFile: smScope.Counter("deleted"),
+		migratedDataFileSet:         tierScope.Counter("migrated"),
 	}
 }
 
@@ -94,8 +110,10 @@ func newCleanupManager(
 		filePathPrefix:              filePathPrefix,
 		commitLogsDir:               commitLogsDir,
 		commitLogFilesFn:            commitlog.Files,
+		snapshotMetadataFilesFn:     fs.SortedSnapshotMetadataFiles,
 		deleteFilesFn:               fs.DeleteFiles,
 		deleteInactiveDirectoriesFn: fs.DeleteInactiveDirectories,
+		migrateDataFileSetsFn:       fs.MigrateDataFileSetsBefore,
 		metrics:                     newCleanupManagerMetrics(scope),
 	}
 }
@@ -117,6 +135,11 @@ func (m *cleanupManager) Cleanup(t time.Time) error {
 			"encountered errors when cleaning up data files for %v: %v", t, err))
 	}
 
+	if err := m.migrateDataFilesToTier(t); err != nil {
+		multiErr = multiErr.Add(fmt.Errorf(
+			"encountered errors when migrating data files to fileset tier for %v: %v", t, err))
+	}
+
 	if err := m.cleanupExpiredIndexFiles(t); err != nil {
 		multiErr = multiErr.Add(fmt.Errorf(
 			"encountered errors when cleaning up index files for %v: %v", t, err))
@@ -142,6 +165,11 @@ func (m *cleanupManager) Cleanup(t time.Time) error {
 			"encountered errors when deleting inactive namespace files for %v: %v", t, err))
 	}
 
+	if err := m.cleanupSnapshotMetadataFiles(); err != nil {
+		multiErr = multiErr.Add(fmt.Errorf(
+			"encountered errors when cleaning up snapshot metadata files: %v", err))
+	}
+
 	filesToCleanup, err := m.commitLogTimes(t)
 	if err != nil {
 		multiErr = multiErr.Add(fmt.Errorf(
@@ -173,8 +201,6 @@ func (m *cleanupManager) Report() {
 
 func (m *cleanupManager) deleteInactiveNamespaceFiles() error {
 	var namespaceDirNames []string
-	filePathPrefix := m.database.Options().CommitLogOptions().FilesystemOptions().FilePathPrefix()
-	dataDirPath := fs.DataDirPath(filePathPrefix)
 	namespaces, err := m.database.GetOwnedNamespaces()
 	if err != nil {
 		return err
@@ -184,24 +210,47 @@ func (m *cleanupManager) deleteInactiveNamespaceFiles() error {
 		namespaceDirNames = append(namespaceDirNames, n.ID().String())
 	}
 
-	return m.deleteInactiveDirectoriesFn(dataDirPath, namespaceDirNames)
+	multiErr := xerrors.NewMultiError()
+	for _, filePathPrefix := range m.dataFilePathPrefixes() {
+		dataDirPath := fs.DataDirPath(filePathPrefix)
+		multiErr = multiErr.Add(m.deleteInactiveDirectoriesFn(dataDirPath, namespaceDirNames))
+	}
+	return multiErr.FinalError()
 }
 
 // deleteInactiveDataFiles will delete data files for shards that the node no longer owns
 // which can occur in the case of topology changes
 func (m *cleanupManager) deleteInactiveDataFiles() error {
-	return m.deleteInactiveDataFileSetFiles(fs.NamespaceDataDirPath)
+	multiErr := xerrors.NewMultiError()
+	for _, filePathPrefix := range m.dataFilePathPrefixes() {
+		multiErr = multiErr.Add(m.deleteInactiveDataFileSetFiles(filePathPrefix, fs.NamespaceDataDirPath))
+	}
+	return multiErr.FinalError()
 }
 
 // deleteInactiveDataSnapshotFiles will delete snapshot files for shards that the node no longer owns
 // which can occur in the case of topology changes
 func (m *cleanupManager) deleteInactiveDataSnapshotFiles() error {
-	return m.deleteInactiveDataFileSetFiles(fs.NamespaceSnapshotsDirPath)
+	filePathPrefix := m.database.Options().CommitLogOptions().FilesystemOptions().FilePathPrefix()
+	return m.deleteInactiveDataFileSetFiles(filePathPrefix, fs.NamespaceSnapshotsDirPath)
 }
 
-func (m *cleanupManager) deleteInactiveDataFileSetFiles(filesetFilesDirPathFn func(string, ident.ID) string) error {
+// dataFilePathPrefixes returns the file path prefixes that can hold data files,
+// which includes the fileset tier if one is configured.
+func (m *cleanupManager) dataFilePathPrefixes() []string {
+	fsOpts := m.database.Options().CommitLogOptions().FilesystemOptions()
+	filePathPrefixes := []string{fsOpts.FilePathPrefix()}
+	if tier := fsOpts.FileSetTier(); tier != nil {
+		filePathPrefixes = append(filePathPrefixes, tier.FilePathPrefix())
+	}
+	return filePathPrefixes
+}
+
+func (m *cleanupManager) deleteInactiveDataFileSetFiles(
+	filePathPrefix string,
+	filesetFilesDirPathFn func(string, ident.ID) string,
+) error {
 	multiErr := xerrors.NewMultiError()
-	filePathPrefix := m.database.Options().CommitLogOptions().FilesystemOptions().FilePathPrefix()
 	namespaces, err := m.database.GetOwnedNamespaces()
 	if err != nil {
 		return err
@@ -236,6 +285,66 @@ func (m *cleanupManager) cleanupExpiredDataFiles(t time.Time) error {
 	return multiErr.FinalError()
 }
 
+// migrateDataFilesToTier moves flushed data filesets for blocks that ended more
+// than the tier migration age ago from the primary file path prefix to the
+// fileset tier, if one is configured.
+func (m *cleanupManager) migrateDataFilesToTier(t time.Time) error {
+	fsOpts := m.database.Options().CommitLogOptions().FilesystemOptions()
+	if fsOpts.FileSetTier() == nil {
+		return nil
+	}
+
+	namespaces, err := m.database.GetOwnedNamespaces()
+	if err != nil {
+		return err
+	}
+
+	multiErr := xerrors.NewMultiError()
+	for _, n := range namespaces {
+		retriever, err := m.blockRetriever(n)
+		if err != nil {
+			multiErr = multiErr.Add(err)
+			continue
+		}
+
+		blockSize := n.Options().RetentionOptions().BlockSize()
+		migrateBefore := t.Add(-fsOpts.FileSetTierMigrationAge()).Truncate(blockSize)
+		for _, shard := range n.GetOwnedShards() {
+			migrated, err := m.migrateDataFileSetsFn(fsOpts, n.ID(), shard.ID(), migrateBefore)
+			m.metrics.migratedDataFileSet.Inc(int64(len(migrated)))
+			if err != nil {
+				multiErr = multiErr.Add(err)
+			}
+			// NB: Seekers opened for the migrated filesets still reference the
+			// removed files in the primary file path prefix.
+			if retriever == nil {
+				continue
+			}
+			for _, blockStart := range migrated {
+				retriever.InvalidateBlock(shard.ID(), blockStart)
+			}
+		}
+	}
+	return multiErr.FinalError()
+}
+
+// blockRetriever returns the block retriever of the namespace, if any.
+func (m *cleanupManager) blockRetriever(
+	n databaseNamespace,
+) (block.DatabaseBlockRetriever, error) {
+	mgr := m.database.Options().DatabaseBlockRetrieverManager()
+	if mgr == nil {
+		return nil, nil
+	}
+	// NB: The retriever manager returns the retriever already created for
+	// the namespace, retrievers are keyed by namespace ID.
+	md, err := namespace.NewMetadata(n.ID(), n.Options())
+	if err != nil {
+		return nil, err
+	}
+	return mgr.Retriever(md)
+}
+
 func (m *cleanupManager) cleanupExpiredIndexFiles(t time.Time) error {
 	namespaces, err := m.database.GetOwnedNamespaces()
 	if err != nil {
@@ -294,6 +403,86 @@ func (m *cleanupManager) cleanupNamespaceSnapshotFiles(earliestToRetain time.Tim
 	return multiErr.FinalError()
 }
 
+// cleanupSnapshotMetadataFiles deletes all the snapshot metadata files except for the
+// most recent one, which is the only one used to determine which commit log files are
+// captured by snapshots, as well as any snapshot metadata files that are corrupt.
+func (m *cleanupManager) cleanupSnapshotMetadataFiles() error {
+	fsOpts := m.opts.CommitLogOptions().FilesystemOptions()
+	snapshotMetadatas, corruptFiles, err := m.snapshotMetadataFilesFn(fsOpts)
+	if err != nil {
+		return err
+	}
+
+	var filesToDelete []string
+	for i := 0; i < len(snapshotMetadatas)-1; i++ {
+		// Delete the checkpoint file first so that a partially deleted snapshot
+		// metadata file is never considered complete.
+		filesToDelete = append(filesToDelete,
+			snapshotMetadatas[i].CheckpointFilePath,
+			snapshotMetadatas[i].MetadataFilePath)
+	}
+	numDeleted := len(filesToDelete) / 2
+
+	for _, errorWithPaths := range corruptFiles {
+		m.metrics.corruptSnapshotMetadataFile.Inc(1)
+		m.opts.InstrumentOptions().Logger().Errorf(
+			"encountered corrupt snapshot metadata file during cleanup, marking file for deletion: %s: %v",
+			errorWithPaths.MetadataFilePath, errorWithPaths.Error)
+
+		// The checkpoint file is not necessarily present for corrupt snapshot metadata
+		// files (I.E the node failed in the middle of writing one out) so only delete
+		// it if it exists.
+		if errorWithPaths.CheckpointFilePath != "" {
+			exists, err := fs.FileExists(errorWithPaths.CheckpointFilePath)
+			if err != nil {
+				return err
+			}
+			if exists {
+				filesToDelete = append(filesToDelete, errorWithPaths.CheckpointFilePath)
+			}
+		}
+		filesToDelete = append(filesToDelete, errorWithPaths.MetadataFilePath)
+		numDeleted++
+	}
+
+	if len(filesToDelete) == 0 {
+		return nil
+	}
+
+	if err := m.deleteFilesFn(filesToDelete); err != nil {
+		return err
+	}
+	m.metrics.deletedSnapshotMetadataFile.Inc(int64(numDeleted))
+	return nil
+}
+
+// mostRecentSnapshotCommitLog returns the commit log file recorded in the most recent
+// snapshot metadata file, every commit log file that precedes it has had all of its
+// writes either flushed or captured by a snapshot.
+func (m *cleanupManager) mostRecentSnapshotCommitLog() (commitlog.File, bool, error) {
+	fsOpts := m.opts.CommitLogOptions().FilesystemOptions()
+	snapshotMetadatas, _, err := m.snapshotMetadataFilesFn(fsOpts)
+	if err != nil {
+		return commitlog.File{}, false, err
+	}
+	if len(snapshotMetadatas) == 0 {
+		return commitlog.File{}, false, nil
+	}
+
+	mostRecent := snapshotMetadatas[len(snapshotMetadatas)-1]
+	file, err := commitlog.FileFromSnapshotIdentifier(mostRecent.CommitlogIdentifier)
+	if err != nil {
+		// Not fatal since commit log files can still be cleaned up based
+		// on the flush and snapshot state of each namespace.
+		m.opts.InstrumentOptions().Logger().Errorf(
+			"unable to determine commit log file from snapshot metadata file %s: %v",
+			mostRecent.MetadataFilePath, err)
+		return commitlog.File{}, false, nil
+	}
+
+	return file, true, nil
+}
+
 // commitLogTimes returns the earliest time before which the commit logs are expired,
 // as well as a list of times we need to clean up commit log files for.
 func (m *cleanupManager) commitLogTimes(t time.Time) ([]commitLogFileWithErrorAndPath, error) {
@@ -302,6 +491,11 @@ func (m *cleanupManager) commitLogTimes(t time.Time) ([]commitLogFileWithErrorAn
 		return nil, err
 	}
 
+	snapshotCommitLog, hasSnapshotCommitLog, err := m.mostRecentSnapshotCommitLog()
+	if err != nil {
+		return nil, err
+	}
+
 	// We list the commit log files on disk before we determine what the currently active commitlog
 	// is to ensure that the logic remains correct even if the commitlog is rotated while this
 	// function is executing. For example, imagine the following commitlogs are on disk:
@@ -333,6 +527,12 @@ func (m *cleanupManager) commitLogTimes(t time.Time) ([]commitLogFileWithErrorAn
 			return false, nil
 		}
 
+		if hasSnapshotCommitLog && f.IsBefore(snapshotCommitLog) {
+			// All the writes in the commit log file have been either flushed or
+			// captured by the most recent complete snapshot so its safe to clean up.
+			return true, nil
+		}
+
 		for _, ns := range namespaces {
 			var (
 				start                      = f.Start
@@ -27,13 +27,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/persist/fs"
 	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
 	"github.com/m3db/m3/src/dbnode/retention"
+	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3x/ident"
 	xtest "github.com/m3db/m3x/test"
 
 	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/require"
 	"github.com/uber-go/tally"
 )
@@ -247,6 +250,74 @@ func TestDeleteInactiveDataAndSnapshotFileSetFiles(t *testing.T) {
 	}
 }
 
+func TestCleanupManagerMigratesDataFilesToTier(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ts := timeFor(36000)
+
+	var (
+		migrationAge = 6 * time.Hour
+		tierPrefix   = "/var/lib/m3db-tier"
+		opts         = testDatabaseOptions()
+		clOpts       = opts.CommitLogOptions()
+		fsOpts       = clOpts.FilesystemOptions().
+				SetFileSetTier(fs.NewLocalDirectoryTier(tierPrefix, clOpts.FilesystemOptions())).
+				SetFileSetTierMigrationAge(migrationAge)
+	)
+	// The retriever must stop reading the migrated fileset from the primary
+	// file path prefix.
+	blockSize := namespaceOptions.RetentionOptions().BlockSize()
+	migratedBlockStart := ts.Add(-migrationAge).Truncate(blockSize).Add(-blockSize)
+	retriever := block.NewMockDatabaseBlockRetriever(ctrl)
+	retriever.EXPECT().InvalidateBlock(uint32(3), migratedBlockStart)
+	retrieverMgr := block.NewMockDatabaseBlockRetrieverManager(ctrl)
+	retrieverMgr.EXPECT().Retriever(gomock.Any()).Return(retriever, nil)
+	opts = opts.
+		SetCommitLogOptions(clOpts.SetFilesystemOptions(fsOpts)).
+		SetDatabaseBlockRetrieverManager(retrieverMgr)
+
+	ns := NewMockdatabaseNamespace(ctrl)
+	ns.EXPECT().Options().Return(namespaceOptions).AnyTimes()
+	ns.EXPECT().ID().Return(ident.StringID("nsID")).AnyTimes()
+
+	shard := NewMockdatabaseShard(ctrl)
+	shard.EXPECT().ID().Return(uint32(3)).AnyTimes()
+	ns.EXPECT().GetOwnedShards().Return([]databaseShard{shard}).AnyTimes()
+	namespaces := []databaseNamespace{ns}
+
+	db := NewMockdatabase(ctrl)
+	db.EXPECT().Options().Return(opts).AnyTimes()
+	db.EXPECT().GetOwnedNamespaces().Return(namespaces, nil).AnyTimes()
+	mgr := newCleanupManager(db, newNoopFakeActiveLogs(), tally.NoopScope).(*cleanupManager)
+
+	var migrateCalls []time.Time
+	mgr.migrateDataFileSetsFn = func(
+		opts fs.Options,
+		namespace ident.ID,
+		shard uint32,
+		t time.Time,
+	) (int, error) {
+		require.Equal(t, "nsID", namespace.String())
+		require.Equal(t, uint32(3), shard)
+		migrateCalls = append(migrateCalls, t)
+		return []time.Time{migratedBlockStart}, nil
+	}
+	var inactiveDirCalls []string
+	mgr.deleteInactiveDirectoriesFn = func(parentDirPath string, activeDirNames []string) error {
+		inactiveDirCalls = append(inactiveDirCalls, parentDirPath)
+		return nil
+	}
+
+	require.NoError(t, mgr.migrateDataFilesToTier(ts))
+	require.Equal(t, []time.Time{ts.Add(-migrationAge).Truncate(blockSize)}, migrateCalls)
+
+	// Inactive data directories are also cleaned up in the tier.
+	require.NoError(t, mgr.deleteInactiveDataFiles())
+	require.NoError(t, mgr.deleteInactiveNamespaceFiles())
+	require.Contains(t, inactiveDirCalls, fs.NamespaceDataDirPath(tierPrefix, ident.StringID("nsID")))
+	require.Contains(t, inactiveDirCalls, fs.DataDirPath(tierPrefix))
+}
+
 func TestCleanupManagerPropagatesGetOwnedNamespacesError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -766,6 +837,76 @@ func TestCleanupManagerIgnoresActiveCommitLogFiles(t *testing.T) {
 	require.Empty(t, filesToCleanup, path)
 }
 
+func TestCleanupManagerCommitLogTimesCapturedBySnapshotMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		ns, mgr  = newCleanupManagerCommitLogTimesTest(t, ctrl)
+		time20ID = []byte(fmt.Sprintf("/var/lib/m3db/commitlogs/commitlog-%d-0.db", time20.UnixNano()))
+	)
+	mgr.commitLogFilesFn = func(_ commitlog.Options) ([]commitlog.File, []commitlog.ErrorWithPath, error) {
+		return []commitlog.File{
+			commitlog.File{Start: time10, Duration: commitLogBlockSize},
+			commitlog.File{Start: time20, Duration: commitLogBlockSize},
+			commitlog.File{Start: time30, Duration: commitLogBlockSize},
+		}, nil, nil
+	}
+	mgr.snapshotMetadataFilesFn = func(fs.Options) ([]fs.SnapshotMetadata, []fs.SnapshotMetadataErrorWithPaths, error) {
+		return []fs.SnapshotMetadata{
+			{
+				ID:                  fs.SnapshotMetadataIdentifier{Index: 0, UUID: uuid.NewRandom()},
+				CommitlogIdentifier: time20ID,
+			},
+		}, nil, nil
+	}
+
+	// Commit log with start time10 precedes the commit log that was rotated
+	// to by the most recent snapshot so the namespace state is not consulted.
+	gomock.InOrder(
+		ns.EXPECT().NeedsFlush(time20, time30).Return(true),
+		ns.EXPECT().IsCapturedBySnapshot(
+			gomock.Any(), gomock.Any(), time30).Return(false, nil),
+		ns.EXPECT().NeedsFlush(time30, time40).Return(true),
+		ns.EXPECT().IsCapturedBySnapshot(
+			gomock.Any(), gomock.Any(), time40).Return(false, nil),
+	)
+
+	filesToCleanup, err := mgr.commitLogTimes(currentTime)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(filesToCleanup))
+	require.True(t, contains(filesToCleanup, time10))
+}
+
+func TestCleanupManagerCleanupSnapshotMetadataFiles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, mgr := newCleanupManagerCommitLogTimesTest(t, ctrl)
+	mgr.snapshotMetadataFilesFn = func(fs.Options) ([]fs.SnapshotMetadata, []fs.SnapshotMetadataErrorWithPaths, error) {
+		return []fs.SnapshotMetadata{
+			{MetadataFilePath: "metadata-0", CheckpointFilePath: "checkpoint-0"},
+			{MetadataFilePath: "metadata-1", CheckpointFilePath: "checkpoint-1"},
+			{MetadataFilePath: "metadata-2", CheckpointFilePath: "checkpoint-2"},
+		}, []fs.SnapshotMetadataErrorWithPaths{
+			{Error: errors.New("some_error"), MetadataFilePath: "corrupt-metadata"},
+		}, nil
+	}
+	var deletedFiles []string
+	mgr.deleteFilesFn = func(files []string) error {
+		deletedFiles = append(deletedFiles, files...)
+		return nil
+	}
+
+	// Only the most recent snapshot metadata file is retained.
+	require.NoError(t, mgr.cleanupSnapshotMetadataFiles())
+	require.Equal(t, []string{
+		"checkpoint-0", "metadata-0",
+		"checkpoint-1", "metadata-1",
+		"corrupt-metadata",
+	}, deletedFiles)
+}
+
 type fakeActiveLogs struct {
 	activeLogs []commitlog.File
 }
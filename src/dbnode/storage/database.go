@@ -117,6 +117,7 @@ type databaseMetrics struct {
 	unknownNamespaceFetchBlocks         tally.Counter
 	unknownNamespaceFetchBlocksMetadata tally.Counter
 	unknownNamespaceQueryIDs            tally.Counter
+	unknownNamespaceAggregateQuery      tally.Counter
 	errQueryIDsIndexDisabled            tally.Counter
 	errWriteTaggedIndexDisabled         tally.Counter
 }
@@ -134,6 +135,7 @@ func newDatabaseMetrics(scope tally.Scope) databaseMetrics {
 		unknownNamespaceFetchBlocks:         unknownNamespaceScope.Counter("fetch-blocks"),
 		unknownNamespaceFetchBlocksMetadata: unknownNamespaceScope.Counter("fetch-blocks-metadata"),
 		unknownNamespaceQueryIDs:            unknownNamespaceScope.Counter("query-ids"),
+		unknownNamespaceAggregateQuery:      unknownNamespaceScope.Counter("aggregate-query"),
 		errQueryIDsIndexDisabled:            indexDisabledScope.Counter("err-query-ids"),
 		errWriteTaggedIndexDisabled:         indexDisabledScope.Counter("err-write-tagged"),
 	}
@@ -237,9 +239,12 @@ func (d *db) UpdateOwnedNamespaces(newNamespaces namespace.Map) error {
 		return err
 	}
 
-	// log that updates and removals are skipped
-	if len(removes) > 0 || len(updates) > 0 {
-		d.log.Warnf("skipping namespace removals and updates, restart process if you want changes to take effect.")
+	// apply any namespace updates that are safe to apply online
+	d.updateNamespacesWithLock(updates)
+
+	// log that removals are skipped
+	if len(removes) > 0 {
+		d.log.Warnf("skipping namespace removals, restart process if you want changes to take effect.")
 	}
 
 	// enqueue bootstraps if new namespaces
@@ -316,7 +321,7 @@ func (d *db) logNamespaceUpdate(removes []ident.ID, adds, updates []namespace.Me
 	).Infof("updating database namespaces")
 
 	// NB(prateek): as noted in `UpdateOwnedNamespaces()` above, the current implementation
-	// does not apply updates, and removals until the m3dbnode process is restarted.
+	// does not apply removals until the m3dbnode process is restarted.
 
 	return nil
 }
@@ -339,6 +344,23 @@ func (d *db) addNamespacesWithLock(namespaces []namespace.Metadata) error {
 	return nil
 }
 
+func (d *db) updateNamespacesWithLock(namespaces []namespace.Metadata) {
+	for _, n := range namespaces {
+		ns, ok := d.namespaces.Get(n.ID())
+		if !ok { // should never happen
+			d.log.Errorf("missing namespace marked for update: %v", n.ID().String())
+			continue
+		}
+
+		// NB: updates that are not safe to apply online, such as a change
+		// of block size, are skipped until the process is restarted.
+		if err := ns.UpdateMetadata(n); err != nil {
+			d.log.Warnf("skipping update of namespace %s, restart process "+
+				"if you want changes to take effect: %v", n.ID().String(), err)
+		}
+	}
+}
+
 func (d *db) newDatabaseNamespaceWithLock(
 	md namespace.Metadata,
 ) (databaseNamespace, error) {
@@ -736,6 +758,21 @@ func (d *db) QueryIDs(
 	return n.QueryIDs(ctx, query, opts)
 }
 
+func (d *db) AggregateQuery(
+	ctx context.Context,
+	namespace ident.ID,
+	query index.Query,
+	opts index.AggregationOptions,
+) (index.AggregateQueryResult, error) {
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		d.metrics.unknownNamespaceAggregateQuery.Inc(1)
+		return index.AggregateQueryResult{}, err
+	}
+
+	return n.AggregateQuery(ctx, query, opts)
+}
+
 func (d *db) ReadEncoded(
 	ctx context.Context,
 	namespace ident.ID,
@@ -864,6 +901,30 @@ func (d *db) Truncate(namespace ident.ID) (int64, error) {
 	return n.Truncate()
 }
 
+func (d *db) DeleteSeries(
+	ctx context.Context,
+	namespace ident.ID,
+	ids []ident.ID,
+) ([]ident.ID, error) {
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return n.DeleteSeries(ctx, ids)
+}
+
+func (d *db) DeleteTagged(
+	ctx context.Context,
+	namespace ident.ID,
+	query index.Query,
+) ([]ident.ID, error) {
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return n.DeleteTagged(ctx, query)
+}
+
 func (d *db) IsOverloaded() bool {
 	return d.errors.Count(d.errWindow) > d.errThreshold
 }
@@ -616,17 +616,55 @@ func TestDatabaseUpdateNamespace(t *testing.T) {
 	<-updateCh
 	time.Sleep(10 * time.Millisecond)
 
-	// ensure the namespaces have old properties
+	// ensure the retention update has been applied
 	nses = d.Namespaces()
 	require.Len(t, nses, 2)
 	ns1, ok := d.Namespace(defaultTestNs1ID)
 	require.True(t, ok)
-	require.Equal(t, defaultTestNs1Opts, ns1.Options())
+	require.Equal(t, md1.Options(), ns1.Options())
 	ns2, ok := d.Namespace(defaultTestNs2ID)
 	require.True(t, ok)
 	require.Equal(t, defaultTestNs2Opts, ns2.Options())
 }
 
+func TestDatabaseUpdateNamespaceBlockSizeSkipped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	d, mapCh, _ := newTestDatabase(t, ctrl, Bootstrapped)
+	require.NoError(t, d.Open())
+	defer func() {
+		close(mapCh)
+		require.NoError(t, d.Close())
+		leaktest.CheckTimeout(t, time.Second)()
+	}()
+
+	// retrieve the update channel to track propatation
+	updateCh := d.opts.NamespaceInitializer().(*mockNsInitializer).updateCh
+
+	// construct new namespace Map with a different block size
+	ropts := defaultTestNs1Opts.RetentionOptions().SetBlockSize(time.Hour)
+	md1, err := namespace.NewMetadata(defaultTestNs1ID, defaultTestNs1Opts.SetRetentionOptions(ropts))
+	require.NoError(t, err)
+	md2, err := namespace.NewMetadata(defaultTestNs2ID, defaultTestNs2Opts)
+	require.NoError(t, err)
+	nsMap, err := namespace.NewMap([]namespace.Metadata{md1, md2})
+	require.NoError(t, err)
+
+	// update the database watch with new Map
+	mapCh <- nsMap
+
+	// wait till the update has propagated
+	<-updateCh
+	<-updateCh
+	time.Sleep(10 * time.Millisecond)
+
+	// ensure the namespaces have old properties
+	ns1, ok := d.Namespace(defaultTestNs1ID)
+	require.True(t, ok)
+	require.Equal(t, defaultTestNs1Opts, ns1.Options())
+}
+
 func TestDatabaseNamespaceIndexFunctions(t *testing.T) {
 	testDatabaseNamespaceIndexFunctions(t, true)
 }
@@ -700,6 +738,14 @@ func testDatabaseNamespaceIndexFunctions(t *testing.T, commitlogEnabled bool) {
 	_, err = d.QueryIDs(ctx, ident.StringID("testns"), q, opts)
 	require.Error(t, err)
 
+	aggOpts := index.AggregationOptions{}
+	ns.EXPECT().AggregateQuery(ctx, q, aggOpts).Return(index.AggregateQueryResult{}, nil)
+	_, err = d.AggregateQuery(ctx, ident.StringID("testns"), q, aggOpts)
+	require.NoError(t, err)
+
+	_, err = d.AggregateQuery(ctx, ident.StringID("unknown"), q, aggOpts)
+	require.Error(t, err)
+
 	ns.EXPECT().Close().Return(nil)
 
 	// Ensure commitlog is set before closing because this will call commitlog.Close()
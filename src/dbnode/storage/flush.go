@@ -27,9 +27,12 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
 	"github.com/m3db/m3/src/dbnode/retention"
 	xerrors "github.com/m3db/m3x/errors"
 
+	"github.com/pborman/uuid"
 	"github.com/uber-go/tally"
 )
 
@@ -37,6 +40,16 @@ var (
 	errFlushOperationsInProgress = errors.New("flush operations already in progress")
 )
 
+type nextSnapshotMetadataFileIndexFn func(opts fs.Options) (int64, error)
+
+type writeSnapshotMetadataFn func(opts fs.Options, args fs.SnapshotMetadataWriteArgs) error
+
+// Narrow interface so as not to expose all the functionality of the commitlog
+// to the flush manager.
+type rotatableCommitlog interface {
+	RotateLogs() (commitlog.File, error)
+}
+
 type flushManagerState int
 
 const (
@@ -52,9 +65,13 @@ const (
 type flushManager struct {
 	sync.RWMutex
 
-	database database
-	opts     Options
-	pm       persist.Manager
+	database  database
+	commitlog rotatableCommitlog
+	opts      Options
+	pm        persist.Manager
+
+	nextSnapshotMetadataFileIndexFn nextSnapshotMetadataFileIndexFn
+	writeSnapshotMetadataFn         writeSnapshotMetadataFn
 
 	// state is used to protect the flush manager against concurrent use,
 	// while isFlushing, isSnapshotting, and isIndexFlushing are more
@@ -70,12 +87,19 @@ type flushManager struct {
 	lastSuccessfulSnapshotStartTime time.Time
 }
 
-func newFlushManager(database database, scope tally.Scope) databaseFlushManager {
+func newFlushManager(
+	database database,
+	commitlog rotatableCommitlog,
+	scope tally.Scope,
+) databaseFlushManager {
 	opts := database.Options()
 	return &flushManager{
 		database:                        database,
+		commitlog:                       commitlog,
 		opts:                            opts,
 		pm:                              opts.PersistManager(),
+		nextSnapshotMetadataFileIndexFn: fs.NextSnapshotMetadataFileIndex,
+		writeSnapshotMetadataFn:         writeSnapshotMetadata,
 		isFlushing:                      scope.Gauge("flush"),
 		isSnapshotting:                  scope.Gauge("snapshot"),
 		isIndexFlushing:                 scope.Gauge("index-flush"),
@@ -119,6 +143,33 @@ func (m *flushManager) Flush(
 	// snapshot any unflushed blocks which would be wasteful if the block is already
 	// flushable.
 	multiErr := xerrors.NewMultiError()
+
+	// NB(rartoul): We need to make decisions about whether to snapshot or not as an
+	// all-or-nothing decision, we can't decide on a namespace-by-namespace or
+	// shard-by-shard basis because the model we're moving towards is that once a snapshot
+	// has completed, then all data that had been received by the dbnode up until the
+	// snapshot "start time" has been persisted durably.
+	//
+	// The commit log is rotated before anything is flushed so that every write that
+	// landed in a commit log file before the rotated one is either flushed, cold
+	// flushed or snapshotted by the end of this pass. If all of that succeeds then
+	// a snapshot metadata file is written which records the rotated commit log file
+	// so that the commit log bootstrapper only has to replay it and its successors,
+	// and so that cleanup can remove every commit log file that came before it.
+	var (
+		shouldSnapshot   = tickStart.Sub(m.lastSuccessfulSnapshotStartTime) >= m.opts.MinimumSnapshotInterval()
+		snapshotID       = uuid.NewRandom()
+		rotatedCommitlog commitlog.File
+	)
+	if shouldSnapshot {
+		rotatedCommitlog, err = m.commitlog.RotateLogs()
+		if err != nil {
+			multiErr = multiErr.Add(fmt.Errorf(
+				"error rotating commitlog before snapshot: %v", err))
+			shouldSnapshot = false
+		}
+	}
+
 	m.setState(flushManagerFlushInProgress)
 	for _, ns := range namespaces {
 		// Flush first because we will only snapshot if there are no outstanding flushes
@@ -133,12 +184,36 @@ func (m *flushManager) Flush(
 		multiErr = multiErr.Add(m.flushNamespaceWithTimes(ns, shardBootstrapTimes, flushTimes, flush))
 	}
 
-	// NB(rartoul): We need to make decisions about whether to snapshot or not as an
-	// all-or-nothing decision, we can't decide on a namespace-by-namespace or
-	// shard-by-shard basis because the model we're moving towards is that once a snapshot
-	// has completed, then all data that had been received by the dbnode up until the
-	// snapshot "start time" has been persisted durably.
-	shouldSnapshot := tickStart.Sub(m.lastSuccessfulSnapshotStartTime) >= m.opts.MinimumSnapshotInterval()
+	// Rewrite any flushed blocks with cold writes, blocks repaired from peers or
+	// that still contain deleted series, this happens after flushing so that
+	// blocks flushed during this pass are rewritten too. Cold writes and repairs
+	// are rewritten first since rewriting a block also drops any deleted series
+	// from it. Flushed blocks are compacted last so that the blocks they compact
+	// have already been rewritten.
+	for _, ns := range namespaces {
+		if err := ns.ColdFlush(flush); err != nil {
+			detailedErr := fmt.Errorf("namespace %s failed to cold flush: %v",
+				ns.ID().String(), err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+		if err := ns.FlushRepairs(flush); err != nil {
+			detailedErr := fmt.Errorf("namespace %s failed to flush repairs: %v",
+				ns.ID().String(), err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+		if err := ns.FlushDeletes(flush); err != nil {
+			detailedErr := fmt.Errorf("namespace %s failed to flush deletes: %v",
+				ns.ID().String(), err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+		if err := ns.CompactFlushedBlocks(flush); err != nil {
+			detailedErr := fmt.Errorf("namespace %s failed to compact flushed blocks: %v",
+				ns.ID().String(), err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+	}
+
+	snapshotCapturesAllShards := true
 	if shouldSnapshot {
 		m.setState(flushManagerSnapshotInProgress)
 		maxBlocksSnapshottedByNamespace := 0
@@ -155,12 +230,16 @@ func (m *flushManager) Flush(
 				continue
 			}
 
+			if !m.snapshotCapturesNamespace(ns, shardBootstrapTimes) {
+				snapshotCapturesAllShards = false
+			}
+
 			if len(snapshotBlockStarts) > maxBlocksSnapshottedByNamespace {
 				maxBlocksSnapshottedByNamespace = len(snapshotBlockStarts)
 			}
 			for _, snapshotBlockStart := range snapshotBlockStarts {
 				err := ns.Snapshot(
-					snapshotBlockStart, tickStart, shardBootstrapTimes, flush)
+					snapshotBlockStart, tickStart, snapshotID, shardBootstrapTimes, flush)
 
 				if err != nil {
 					detailedErr := fmt.Errorf("namespace %s failed to snapshot data: %v",
@@ -178,6 +257,9 @@ func (m *flushManager) Flush(
 	if shouldSnapshot {
 		if multiErr.NumErrors() == 0 {
 			m.lastSuccessfulSnapshotStartTime = tickStart
+			if snapshotCapturesAllShards {
+				multiErr = multiErr.Add(m.snapshotMetadata(snapshotID, rotatedCommitlog))
+			}
 		}
 	}
 
@@ -275,6 +357,54 @@ func (m *flushManager) namespaceSnapshotTimes(ns databaseNamespace, curr time.Ti
 	})
 }
 
+// snapshotCapturesNamespace returns whether snapshotting the namespace will capture
+// the unflushed data of every shard that it owns, shards that were not bootstrapped
+// at the start of the tick and namespaces that have snapshots disabled are skipped
+// when snapshotting so their data is only present in the commit log.
+func (m *flushManager) snapshotCapturesNamespace(
+	ns databaseNamespace,
+	shardBootstrapStatesAtTickStart ShardBootstrapStates,
+) bool {
+	if !ns.Options().SnapshotEnabled() {
+		return false
+	}
+
+	for _, shard := range ns.GetOwnedShards() {
+		state, ok := shardBootstrapStatesAtTickStart[shard.ID()]
+		if !ok || state != Bootstrapped {
+			return false
+		}
+	}
+
+	return true
+}
+
+// snapshotMetadata writes out the snapshot metadata file which marks a snapshot
+// as complete and records the commit log file that was rotated to before it began.
+func (m *flushManager) snapshotMetadata(
+	snapshotID uuid.UUID,
+	rotatedCommitlog commitlog.File,
+) error {
+	fsOpts := m.opts.CommitLogOptions().FilesystemOptions()
+	index, err := m.nextSnapshotMetadataFileIndexFn(fsOpts)
+	if err != nil {
+		return fmt.Errorf("error determining next snapshot metadata file index: %v", err)
+	}
+
+	err = m.writeSnapshotMetadataFn(fsOpts, fs.SnapshotMetadataWriteArgs{
+		ID: fs.SnapshotMetadataIdentifier{
+			Index: index,
+			UUID:  snapshotID,
+		},
+		CommitlogIdentifier: rotatedCommitlog.SnapshotIdentifier(),
+	})
+	if err != nil {
+		return fmt.Errorf("error writing snapshot metadata: %v", err)
+	}
+
+	return nil
+}
+
 // flushWithTime flushes in-memory data for a given namespace, at a given
 // time, returning any error encountered during flushing
 func (m *flushManager) flushNamespaceWithTimes(
@@ -299,3 +429,7 @@ func (m *flushManager) flushNamespaceWithTimes(
 func (m *flushManager) LastSuccessfulSnapshotStartTime() (time.Time, bool) {
 	return m.lastSuccessfulSnapshotStartTime, !m.lastSuccessfulSnapshotStartTime.IsZero()
 }
+
+func writeSnapshotMetadata(opts fs.Options, args fs.SnapshotMetadataWriteArgs) error {
+	return fs.NewSnapshotMetadataWriter(opts).Write(args)
+}
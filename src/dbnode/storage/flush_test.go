@@ -28,12 +28,15 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3x/ident"
 	xtest "github.com/m3db/m3x/test"
 
 	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/require"
 	"github.com/uber-go/tally"
 )
@@ -47,16 +50,40 @@ func newMultipleFlushManagerNeedsFlush(t *testing.T, ctrl *gomock.Controller) (
 	namespace := NewMockdatabaseNamespace(ctrl)
 	namespace.EXPECT().Options().Return(options).AnyTimes()
 	namespace.EXPECT().ID().Return(defaultTestNs1ID).AnyTimes()
+	namespace.EXPECT().FlushDeletes(gomock.Any()).Return(nil).AnyTimes()
+	namespace.EXPECT().CompactFlushedBlocks(gomock.Any()).Return(nil).AnyTimes()
+	namespace.EXPECT().FlushRepairs(gomock.Any()).Return(nil).AnyTimes()
+	namespace.EXPECT().ColdFlush(gomock.Any()).Return(nil).AnyTimes()
 	otherNamespace := NewMockdatabaseNamespace(ctrl)
 	otherNamespace.EXPECT().Options().Return(options).AnyTimes()
 	otherNamespace.EXPECT().ID().Return(ident.StringID("someString")).AnyTimes()
+	otherNamespace.EXPECT().FlushDeletes(gomock.Any()).Return(nil).AnyTimes()
+	otherNamespace.EXPECT().CompactFlushedBlocks(gomock.Any()).Return(nil).AnyTimes()
+	otherNamespace.EXPECT().FlushRepairs(gomock.Any()).Return(nil).AnyTimes()
+	otherNamespace.EXPECT().ColdFlush(gomock.Any()).Return(nil).AnyTimes()
 
 	db := newMockdatabase(ctrl, namespace, otherNamespace)
-	fm := newFlushManager(db, tally.NoopScope).(*flushManager)
+	fm := newTestFlushManager(ctrl, db)
 
 	return fm, namespace, otherNamespace
 }
 
+// newTestFlushManager returns a flush manager that rotates a mock commit log
+// and does not write snapshot metadata files to disk.
+func newTestFlushManager(ctrl *gomock.Controller, db database) *flushManager {
+	commitLog := commitlog.NewMockCommitLog(ctrl)
+	commitLog.EXPECT().RotateLogs().Return(commitlog.File{}, nil).AnyTimes()
+
+	fm := newFlushManager(db, commitLog, tally.NoopScope).(*flushManager)
+	fm.nextSnapshotMetadataFileIndexFn = func(fs.Options) (int64, error) {
+		return 0, nil
+	}
+	fm.writeSnapshotMetadataFn = func(fs.Options, fs.SnapshotMetadataWriteArgs) error {
+		return nil
+	}
+	return fm
+}
+
 func TestFlushManagerFlushAlreadyInProgress(t *testing.T) {
 	ctrl := gomock.NewController(xtest.Reporter{T: t})
 	defer ctrl.Finish()
@@ -86,7 +113,7 @@ func TestFlushManagerFlushAlreadyInProgress(t *testing.T) {
 	db.EXPECT().Options().Return(testOpts).AnyTimes()
 	db.EXPECT().GetOwnedNamespaces().Return(nil, nil).AnyTimes()
 
-	fm := newFlushManager(db, tally.NoopScope).(*flushManager)
+	fm := newTestFlushManager(ctrl, db)
 	fm.pm = mockPersistManager
 
 	now := time.Unix(0, 0)
@@ -129,7 +156,7 @@ func TestFlushManagerFlushDoneDataError(t *testing.T) {
 	db.EXPECT().Options().Return(testOpts).AnyTimes()
 	db.EXPECT().GetOwnedNamespaces().Return(nil, nil)
 
-	fm := newFlushManager(db, tally.NoopScope).(*flushManager)
+	fm := newTestFlushManager(ctrl, db)
 	fm.pm = mockPersistManager
 
 	now := time.Unix(0, 0)
@@ -155,7 +182,7 @@ func TestFlushManagerFlushDoneIndexError(t *testing.T) {
 	db.EXPECT().Options().Return(testOpts).AnyTimes()
 	db.EXPECT().GetOwnedNamespaces().Return(nil, nil)
 
-	fm := newFlushManager(db, tally.NoopScope).(*flushManager)
+	fm := newTestFlushManager(ctrl, db)
 	fm.pm = mockPersistManager
 
 	now := time.Unix(0, 0)
@@ -172,7 +199,12 @@ func TestFlushManagerSkipNamespaceIndexingDisabled(t *testing.T) {
 	ns.EXPECT().ID().Return(defaultTestNs1ID).AnyTimes()
 	ns.EXPECT().NeedsFlush(gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 	ns.EXPECT().Flush(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	ns.EXPECT().Snapshot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	ns.EXPECT().Snapshot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	ns.EXPECT().FlushDeletes(gomock.Any()).Return(nil).AnyTimes()
+	ns.EXPECT().CompactFlushedBlocks(gomock.Any()).Return(nil).AnyTimes()
+	ns.EXPECT().FlushRepairs(gomock.Any()).Return(nil).AnyTimes()
+	ns.EXPECT().ColdFlush(gomock.Any()).Return(nil).AnyTimes()
+	ns.EXPECT().GetOwnedShards().Return(nil).AnyTimes()
 
 	mockFlusher := persist.NewMockDataFlush(ctrl)
 	mockFlusher.EXPECT().DoneData().Return(nil)
@@ -188,7 +220,7 @@ func TestFlushManagerSkipNamespaceIndexingDisabled(t *testing.T) {
 	db.EXPECT().Options().Return(testOpts).AnyTimes()
 	db.EXPECT().GetOwnedNamespaces().Return([]databaseNamespace{ns}, nil)
 
-	fm := newFlushManager(db, tally.NoopScope).(*flushManager)
+	fm := newTestFlushManager(ctrl, db)
 	fm.pm = mockPersistManager
 
 	now := time.Unix(0, 0)
@@ -210,7 +242,12 @@ func TestFlushManagerNamespaceIndexingEnabled(t *testing.T) {
 	ns.EXPECT().ID().Return(defaultTestNs1ID).AnyTimes()
 	ns.EXPECT().NeedsFlush(gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 	ns.EXPECT().Flush(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	ns.EXPECT().Snapshot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	ns.EXPECT().Snapshot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	ns.EXPECT().FlushDeletes(gomock.Any()).Return(nil).AnyTimes()
+	ns.EXPECT().CompactFlushedBlocks(gomock.Any()).Return(nil).AnyTimes()
+	ns.EXPECT().FlushRepairs(gomock.Any()).Return(nil).AnyTimes()
+	ns.EXPECT().ColdFlush(gomock.Any()).Return(nil).AnyTimes()
+	ns.EXPECT().GetOwnedShards().Return(nil).AnyTimes()
 	ns.EXPECT().FlushIndex(gomock.Any()).Return(nil)
 
 	mockFlusher := persist.NewMockDataFlush(ctrl)
@@ -227,7 +264,7 @@ func TestFlushManagerNamespaceIndexingEnabled(t *testing.T) {
 	db.EXPECT().Options().Return(testOpts).AnyTimes()
 	db.EXPECT().GetOwnedNamespaces().Return([]databaseNamespace{ns}, nil)
 
-	fm := newFlushManager(db, tally.NoopScope).(*flushManager)
+	fm := newTestFlushManager(ctrl, db)
 	fm.pm = mockPersistManager
 
 	now := time.Unix(0, 0)
@@ -349,15 +386,35 @@ func TestFlushManagerFlushSnapshot(t *testing.T) {
 	defer ctrl.Finish()
 
 	var (
-		fm, ns1, ns2 = newMultipleFlushManagerNeedsFlush(t, ctrl)
-		now          = time.Now()
+		fm, ns1, ns2     = newMultipleFlushManagerNeedsFlush(t, ctrl)
+		now              = time.Now()
+		commitLog        = commitlog.NewMockCommitLog(ctrl)
+		rotatedCommitlog = commitlog.File{
+			FilePath: "/var/lib/m3db/commitlogs/commitlog-0-1.db",
+			Index:    1,
+		}
+		snapshotIDs       = make(map[string]struct{})
+		writtenMetadata   []fs.SnapshotMetadataWriteArgs
+		recordSnapshotIDs = func(_, _ time.Time, snapshotID uuid.UUID, _ ShardBootstrapStates, _ persist.DataFlush) {
+			snapshotIDs[snapshotID.String()] = struct{}{}
+		}
 	)
+	commitLog.EXPECT().RotateLogs().Return(rotatedCommitlog, nil)
+	fm.commitlog = commitLog
+	fm.nextSnapshotMetadataFileIndexFn = func(fs.Options) (int64, error) {
+		return 5, nil
+	}
+	fm.writeSnapshotMetadataFn = func(_ fs.Options, args fs.SnapshotMetadataWriteArgs) error {
+		writtenMetadata = append(writtenMetadata, args)
+		return nil
+	}
 
 	// Haven't snapshotted yet.
 	_, ok := fm.LastSuccessfulSnapshotStartTime()
 	require.False(t, ok)
 
 	for _, ns := range []*MockdatabaseNamespace{ns1, ns2} {
+		ns.EXPECT().GetOwnedShards().Return(nil).AnyTimes()
 		rOpts := ns.Options().RetentionOptions()
 		blockSize := rOpts.BlockSize()
 		bufferFuture := rOpts.BufferFuture()
@@ -376,7 +433,9 @@ func TestFlushManagerFlushSnapshot(t *testing.T) {
 		for i := 0; i < num; i++ {
 			st := start.Add(time.Duration(i) * blockSize)
 			ns.EXPECT().NeedsFlush(st, st).Return(true)
-			ns.EXPECT().Snapshot(st, now, gomock.Any(), gomock.Any())
+			ns.EXPECT().
+				Snapshot(st, now, gomock.Any(), gomock.Any(), gomock.Any()).
+				Do(recordSnapshotIDs)
 		}
 	}
 
@@ -391,6 +450,86 @@ func TestFlushManagerFlushSnapshot(t *testing.T) {
 	lastSuccessfulSnapshot, ok := fm.LastSuccessfulSnapshotStartTime()
 	require.True(t, ok)
 	require.Equal(t, now, lastSuccessfulSnapshot)
+
+	// Every namespace should have been snapshotted with the same snapshot ID
+	// which is then recorded in the snapshot metadata with the rotated commitlog.
+	require.Equal(t, 1, len(snapshotIDs))
+	require.Equal(t, 1, len(writtenMetadata))
+	require.Equal(t, int64(5), writtenMetadata[0].ID.Index)
+	_, ok = snapshotIDs[writtenMetadata[0].ID.UUID.String()]
+	require.True(t, ok)
+	require.Equal(t, rotatedCommitlog.SnapshotIdentifier(), writtenMetadata[0].CommitlogIdentifier)
+}
+
+func TestFlushManagerFlushSnapshotSkipsMetadataUnlessAllShardsCaptured(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		fm, ns1, ns2    = newMultipleFlushManagerNeedsFlush(t, ctrl)
+		now             = time.Now()
+		shard           = NewMockdatabaseShard(ctrl)
+		writtenMetadata = 0
+	)
+	fm.writeSnapshotMetadataFn = func(fs.Options, fs.SnapshotMetadataWriteArgs) error {
+		writtenMetadata++
+		return nil
+	}
+
+	// Shards that were not bootstrapped at the start of the tick are not
+	// snapshotted so a snapshot does not capture all their data.
+	shard.EXPECT().ID().Return(uint32(0)).AnyTimes()
+	for _, ns := range []*MockdatabaseNamespace{ns1, ns2} {
+		ns.EXPECT().GetOwnedShards().Return([]databaseShard{shard}).AnyTimes()
+		ns.EXPECT().NeedsFlush(gomock.Any(), gomock.Any()).Return(false).AnyTimes()
+	}
+
+	bootstrapStates := DatabaseBootstrapState{
+		NamespaceBootstrapStates: map[string]ShardBootstrapStates{
+			ns1.ID().String(): ShardBootstrapStates{0: Bootstrapped},
+			ns2.ID().String(): ShardBootstrapStates{0: Bootstrapping},
+		},
+	}
+	require.NoError(t, fm.Flush(now, bootstrapStates))
+	require.Equal(t, 0, writtenMetadata)
+
+	// Once all shards are bootstrapped the snapshot metadata is written.
+	bootstrapStates.NamespaceBootstrapStates[ns2.ID().String()] = ShardBootstrapStates{0: Bootstrapped}
+	require.NoError(t, fm.Flush(now.Add(fm.opts.MinimumSnapshotInterval()), bootstrapStates))
+	require.Equal(t, 1, writtenMetadata)
+}
+
+func TestFlushManagerFlushSnapshotRotateLogsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		fm, ns1, ns2 = newMultipleFlushManagerNeedsFlush(t, ctrl)
+		now          = time.Now()
+		commitLog    = commitlog.NewMockCommitLog(ctrl)
+	)
+	commitLog.EXPECT().RotateLogs().Return(commitlog.File{}, errors.New("an error"))
+	fm.commitlog = commitLog
+	fm.writeSnapshotMetadataFn = func(fs.Options, fs.SnapshotMetadataWriteArgs) error {
+		require.FailNow(t, "snapshot metadata should not be written")
+		return nil
+	}
+
+	// Expect flushes but not snapshots.
+	for _, ns := range []*MockdatabaseNamespace{ns1, ns2} {
+		ns.EXPECT().NeedsFlush(gomock.Any(), gomock.Any()).Return(false).AnyTimes()
+	}
+
+	bootstrapStates := DatabaseBootstrapState{
+		NamespaceBootstrapStates: map[string]ShardBootstrapStates{
+			ns1.ID().String(): ShardBootstrapStates{},
+			ns2.ID().String(): ShardBootstrapStates{},
+		},
+	}
+	require.Error(t, fm.Flush(now, bootstrapStates))
+
+	_, ok := fm.LastSuccessfulSnapshotStartTime()
+	require.False(t, ok)
 }
 
 func TestFlushManagerFlushSnapshotHonorsMinimumInterval(t *testing.T) {
@@ -61,6 +61,7 @@ var (
 	errDbIndexUnableToWriteClosed         = errors.New("unable to write to database index, already closed")
 	errDbIndexUnableToQueryClosed         = errors.New("unable to query database index, already closed")
 	errDbIndexUnableToFlushClosed         = errors.New("unable to flush database index, already closed")
+	errDbIndexUnableToDeleteClosed        = errors.New("unable to delete from database index, already closed")
 	errDbIndexUnableToCleanupClosed       = errors.New("unable to cleanup database index, already closed")
 	errDbIndexTerminatingTickCancellation = errors.New("terminating tick early due to cancellation")
 	errDbIndexIsBootstrapping             = errors.New("index is already bootstrapping")
@@ -77,11 +78,7 @@ type nsIndex struct {
 
 	// all the vars below this line are not modified past the ctor
 	// and don't require a lock when being accessed.
-	nowFn           clock.NowFn
-	blockSize       time.Duration
-	retentionPeriod time.Duration
-	bufferPast      time.Duration
-	bufferFuture    time.Duration
+	nowFn clock.NowFn
 
 	indexFilesetsBeforeFn indexFilesetsBeforeFn
 	deleteFilesFn         deleteFilesFn
@@ -128,6 +125,20 @@ type nsIndexState struct {
 	// chronological order. This is used at query time to enforce determinism about results
 	// returned.
 	blockStartsDescOrder []xtime.UnixNano
+
+	// NB: `deletedIDs` contains the IDs of deleted series mapped to the time
+	// they were deleted. Documents cannot be removed from sealed segments so
+	// these IDs are filtered from query results until the series is indexed
+	// again or every block it could be indexed in has expired.
+	deletedIDs map[string]time.Time
+
+	// NB: the retention period and buffers are updated along with the
+	// namespace retention options, and the index options along with the
+	// namespace index block size.
+	retentionPeriod time.Duration
+	bufferPast      time.Duration
+	bufferFuture    time.Duration
+	indexOpts       namespace.IndexOptions
 }
 
 // NB: nsIndexRuntimeOptions does not contain its own mutex as some of the variables
@@ -230,14 +241,15 @@ func newNamespaceIndexWithOptions(
 				insertMode:            indexOpts.InsertMode(), // FOLLOWUP(prateek): wire to allow this to be tweaked at runtime
 				flushBlockNumSegments: runtime.DefaultFlushIndexBlockNumSegments,
 			},
-			blocksByTime: make(map[xtime.UnixNano]index.Block),
+			blocksByTime:    make(map[xtime.UnixNano]index.Block),
+			deletedIDs:      make(map[string]time.Time),
+			retentionPeriod: nsMD.Options().RetentionOptions().RetentionPeriod(),
+			bufferPast:      nsMD.Options().RetentionOptions().BufferPast(),
+			bufferFuture:    nsMD.Options().RetentionOptions().BufferFuture(),
+			indexOpts:       nsMD.Options().IndexOptions(),
 		},
 
-		nowFn:           nowFn,
-		blockSize:       nsMD.Options().IndexOptions().BlockSize(),
-		retentionPeriod: nsMD.Options().RetentionOptions().RetentionPeriod(),
-		bufferPast:      nsMD.Options().RetentionOptions().BufferPast(),
-		bufferFuture:    nsMD.Options().RetentionOptions().BufferFuture(),
+		nowFn: nowFn,
 
 		indexFilesetsBeforeFn: fs.IndexFileSetsBefore,
 		deleteFilesFn:         fs.DeleteFiles,
@@ -263,8 +275,8 @@ func newNamespaceIndexWithOptions(
 	idx.state.insertQueue = queue
 
 	// allocate the current block to ensure we're able to index as soon as we return
-	currentBlock := nowFn().Truncate(idx.blockSize)
 	idx.state.RLock()
+	currentBlock := idx.state.indexOpts.BlockStartForTime(nowFn())
 	_, err := idx.ensureBlockPresentWithRLock(currentBlock)
 	idx.state.RUnlock()
 	if err != nil {
@@ -384,7 +396,10 @@ func (i *nsIndex) reportStats() error {
 }
 
 func (i *nsIndex) BlockStartForWriteTime(writeTime time.Time) xtime.UnixNano {
-	return xtime.ToUnixNano(writeTime.Truncate(i.blockSize))
+	i.state.RLock()
+	blockStart := i.state.indexOpts.BlockStartForTime(writeTime)
+	i.state.RUnlock()
+	return xtime.ToUnixNano(blockStart)
 }
 
 // NB(prateek): including the call chains leading to this point:
@@ -465,8 +480,12 @@ func (i *nsIndex) writeBatches(
 		return
 	}
 	now := i.nowFn()
-	futureLimit := now.Add(1 * i.bufferFuture)
-	pastLimit := now.Add(-1 * i.bufferPast)
+	futureLimit := now.Add(1 * i.state.bufferFuture)
+	pastLimit := now.Add(-1 * i.state.bufferPast)
+	// NB: the batch may have been created before the index block size was
+	// last updated, so group it by the current index block sizes.
+	batch.SetIndexBlockSize(i.state.indexOpts.BlockSize(),
+		i.state.indexOpts.BlockSizeChange())
 	// NB(r): Release lock early to avoid writing batches impacting ticking
 	// speed, etc.
 	// Sometimes foreground compaction can take a long time during heavy inserts.
@@ -515,6 +534,10 @@ func (i *nsIndex) writeBatchForBlockStart(
 		return
 	}
 
+	// Series that were deleted and have since been written again
+	// should be returned by queries again.
+	i.undeleteIfAny(batch.PendingDocs())
+
 	// NB(r): Capture pending entries so we can emit the latencies
 	pending := batch.PendingEntries()
 
@@ -550,6 +573,37 @@ func (i *nsIndex) writeBatchForBlockStart(
 	}
 }
 
+func (i *nsIndex) undeleteIfAny(docs []doc.Document) {
+	i.state.RLock()
+	numDeleted := len(i.state.deletedIDs)
+	i.state.RUnlock()
+	if numDeleted == 0 {
+		return
+	}
+
+	i.state.Lock()
+	for _, d := range docs {
+		delete(i.state.deletedIDs, string(d.ID))
+	}
+	i.state.Unlock()
+}
+
+// Delete excludes the given IDs from query results, documents already
+// indexed cannot be removed from the index blocks themselves.
+func (i *nsIndex) Delete(ids []ident.ID) error {
+	now := i.nowFn()
+
+	i.state.Lock()
+	defer i.state.Unlock()
+	if !i.isOpenWithRLock() {
+		return errDbIndexUnableToDeleteClosed
+	}
+	for _, id := range ids {
+		i.state.deletedIDs[id.String()] = now
+	}
+	return nil
+}
+
 // Bootstrap bootstraps the index with the provide blocks.
 func (i *nsIndex) Bootstrap(
 	bootstrapResults result.IndexResults,
@@ -586,6 +640,20 @@ func (i *nsIndex) Bootstrap(
 	return multiErr.FinalError()
 }
 
+func (i *nsIndex) UpdateRetentionOptions(value retention.Options) {
+	i.state.Lock()
+	i.state.retentionPeriod = value.RetentionPeriod()
+	i.state.bufferPast = value.BufferPast()
+	i.state.bufferFuture = value.BufferFuture()
+	i.state.Unlock()
+}
+
+func (i *nsIndex) UpdateIndexOptions(value namespace.IndexOptions) {
+	i.state.Lock()
+	i.state.indexOpts = value
+	i.state.Unlock()
+}
+
 func (i *nsIndex) BootstrapsDone() uint {
 	i.state.RLock()
 	result := i.state.bootstrapsDone
@@ -594,11 +662,7 @@ func (i *nsIndex) BootstrapsDone() uint {
 }
 
 func (i *nsIndex) Tick(c context.Cancellable, tickStart time.Time) (namespaceIndexTickResult, error) {
-	var (
-		result                     = namespaceIndexTickResult{}
-		earliestBlockStartToRetain = retention.FlushTimeStartForRetentionPeriod(i.retentionPeriod, i.blockSize, tickStart)
-		lastSealableBlockStart     = retention.FlushTimeEndForBlockSize(i.blockSize, tickStart.Add(-i.bufferPast))
-	)
+	result := namespaceIndexTickResult{}
 
 	i.state.Lock()
 	defer func() {
@@ -606,8 +670,20 @@ func (i *nsIndex) Tick(c context.Cancellable, tickStart time.Time) (namespaceInd
 		i.state.Unlock()
 	}()
 
+	var (
+		earliestBlockStartToRetain = i.earliestBlockStartToRetainWithRLock(tickStart)
+		lastSealableBlockStart     = i.lastSealableBlockStartWithRLock(tickStart)
+	)
+
 	result.NumBlocks = int64(len(i.state.blocksByTime))
 
+	// drop any deleted IDs once every block they could be indexed in has expired
+	for id, deletedAt := range i.state.deletedIDs {
+		if deletedAt.Before(earliestBlockStartToRetain) {
+			delete(i.state.deletedIDs, id)
+		}
+	}
+
 	var multiErr xerrors.MultiError
 	for blockStart, block := range i.state.blocksByTime {
 		if c.IsCancelled() {
@@ -739,7 +815,11 @@ func (i *nsIndex) flushBlock(
 ) ([]segment.Segment, error) {
 	i.state.RLock()
 	numSegments := i.state.runtimeOpts.flushBlockNumSegments
+	md, err := i.nsMetadataWithRLock()
 	i.state.RUnlock()
+	if err != nil {
+		return nil, err
+	}
 
 	allShards := make(map[uint32]struct{})
 	segmentShards := make([][]databaseShard, numSegments)
@@ -753,7 +833,7 @@ func (i *nsIndex) flushBlock(
 	}
 
 	preparedPersist, err := flush.PrepareIndex(persist.IndexPrepareOptions{
-		NamespaceMetadata: i.nsMetadata,
+		NamespaceMetadata: md,
 		BlockStart:        indexBlock.StartTime(),
 		FileSetType:       persist.FileSetFlushType,
 		Shards:            allShards,
@@ -890,10 +970,11 @@ func (i *nsIndex) Query(
 		// Results contains all concurrent mutable state below.
 		results = struct {
 			sync.Mutex
-			multiErr   xerrors.MultiError
-			merged     index.Results
-			exhaustive bool
-			returned   bool
+			multiErr    xerrors.MultiError
+			merged      index.Results
+			docsMatched int
+			exhaustive  bool
+			returned    bool
 		}{
 			merged:     nil,
 			exhaustive: true,
@@ -937,6 +1018,10 @@ func (i *nsIndex) Query(
 			return
 		}
 
+		// NB: Count documents matched across blocks before checking the error
+		// so that a block that failed on the docs limit is always accounted.
+		results.docsMatched += blockResults.Size()
+
 		if err != nil {
 			results.multiErr = results.multiErr.Add(err)
 			return
@@ -989,10 +1074,11 @@ func (i *nsIndex) Query(
 		if alreadyNotExhaustive {
 			results.exhaustive = false
 		}
+		alreadyExceededDocsLimit := opts.DocsLimitExceeded(results.docsMatched)
 		results.Unlock()
 
-		if alreadyNotExhaustive {
-			// Break out if already exhaustive.
+		if alreadyNotExhaustive || alreadyExceededDocsLimit {
+			// Break out if already exhaustive or failed on the docs limit.
 			break
 		}
 
@@ -1070,9 +1156,17 @@ func (i *nsIndex) Query(
 	// lock/unlock cleanup to not deadlock with this locked code block.
 	exhaustive := results.exhaustive
 	mergedResults := results.merged
+	docsMatched := results.docsMatched
 	err = results.multiErr.FinalError()
 	results.Unlock()
 
+	if opts.DocsLimitExceeded(docsMatched) {
+		// NB: Return the limit error in favor of any other block errors so
+		// that callers can reliably classify the failure.
+		return index.QueryResults{}, index.NewDocsLimitExceededError(
+			docsMatched, opts.DocsLimit)
+	}
+
 	if err != nil {
 		return index.QueryResults{}, err
 	}
@@ -1083,12 +1177,104 @@ func (i *nsIndex) Query(
 		mergedResults.Reset(i.nsMetadata.ID())
 	}
 
+	i.state.RLock()
+	if len(i.state.deletedIDs) > 0 {
+		resultsMap := mergedResults.Map()
+		for _, entry := range resultsMap.Iter() {
+			id := entry.Key()
+			if _, deleted := i.state.deletedIDs[id.String()]; deleted {
+				resultsMap.Delete(id)
+			}
+		}
+	}
+	i.state.RUnlock()
+
 	return index.QueryResults{
 		Exhaustive: exhaustive,
 		Results:    mergedResults,
 	}, nil
 }
 
+func (i *nsIndex) AggregateQuery(
+	ctx context.Context,
+	query index.Query,
+	opts index.AggregationOptions,
+) (index.AggregateQueryResult, error) {
+	// Capture start before needing to acquire lock.
+	start := i.nowFn()
+
+	if err := opts.Type.Validate(); err != nil {
+		return index.AggregateQueryResult{}, err
+	}
+
+	i.state.RLock()
+	if !i.isOpenWithRLock() {
+		i.state.RUnlock()
+		return index.AggregateQueryResult{}, errDbIndexUnableToQueryClosed
+	}
+
+	// Track this as an inflight query that needs to finish
+	// when the index is closed.
+	i.queriesWg.Add(1)
+	defer i.queriesWg.Done()
+
+	// Enact overrides for query options
+	opts.QueryOptions = i.overriddenOptsForQueryWithRLock(opts.QueryOptions)
+	timeout := i.timeoutForQueryWithRLock(ctx)
+
+	blocks, err := i.blocksForQueryWithRLock(xtime.NewRanges(xtime.Range{
+		Start: opts.StartInclusive,
+		End:   opts.EndExclusive,
+	}))
+
+	// Can now release the lock and execute the query without holding the lock.
+	i.state.RUnlock()
+
+	if err != nil {
+		return index.AggregateQueryResult{}, err
+	}
+
+	// NB: Blocks are aggregated sequentially into a single set of results
+	// since the results dedupe fields and terms across blocks and aggregating
+	// from the terms dictionaries is cheap relative to a full document query.
+	// Series deleted but not yet flushed may still contribute terms until the
+	// block containing them is rewritten.
+	var (
+		deadline   = start.Add(timeout)
+		results    = index.NewAggregateResults(i.nsMetadata.ID(), opts.Type)
+		exhaustive = true
+	)
+	for _, block := range blocks {
+		if opts.LimitExceeded(results.Size()) {
+			exhaustive = false
+			break
+		}
+
+		if timeout > 0 && !i.nowFn().Before(deadline) {
+			return index.AggregateQueryResult{},
+				fmt.Errorf("index query timed out: %s", timeout.String())
+		}
+
+		blockExhaustive, err := block.Aggregate(query, opts, results)
+		if err == index.ErrUnableToQueryBlockClosed {
+			// Block slid out of retention while querying, see Query.
+			continue
+		}
+		if err != nil {
+			return index.AggregateQueryResult{}, err
+		}
+		if !blockExhaustive {
+			exhaustive = false
+			break
+		}
+	}
+
+	return index.AggregateQueryResult{
+		Results:    results,
+		Exhaustive: exhaustive,
+	}, nil
+}
+
 func (i *nsIndex) timeoutForQueryWithRLock(
 	ctx context.Context,
 ) time.Duration {
@@ -1107,6 +1293,11 @@ func (i *nsIndex) overriddenOptsForQueryWithRLock(
 			opts.Limit, i.state.runtimeOpts.maxQueryLimit) // FOLLOWUP(prateek): log query too once it's serializable.
 		opts.Limit = int(i.state.runtimeOpts.maxQueryLimit)
 	}
+	// Override query docs limit if needed.
+	if max := i.opts.LimitsOptions().MaxDocsMatchedPerQuery(); max > 0 &&
+		(opts.DocsLimit == 0 || opts.DocsLimit > max) {
+		opts.DocsLimit = max
+	}
 	return opts
 }
 
@@ -1190,7 +1381,11 @@ func (i *nsIndex) ensureBlockPresentWithRLock(blockStart time.Time) (index.Block
 	}
 
 	// ok now we know for sure we have to alloc
-	block, err := i.newBlockFn(blockStart, i.nsMetadata, i.opts.IndexOptions())
+	md, err := i.nsMetadataWithRLock()
+	if err != nil {
+		return nil, i.unableToAllocBlockInvariantError(err)
+	}
+	block, err := i.newBlockFn(blockStart, md, i.opts.IndexOptions())
 	if err != nil { // unable to allocate the block, should never happen.
 		return nil, i.unableToAllocBlockInvariantError(err)
 	}
@@ -1203,6 +1398,25 @@ func (i *nsIndex) ensureBlockPresentWithRLock(blockStart time.Time) (index.Block
 	return block, nil
 }
 
+// nsMetadataWithRLock returns the namespace metadata with the current index
+// options, which are updated along with the namespace index block size.
+func (i *nsIndex) nsMetadataWithRLock() (namespace.Metadata, error) {
+	opts := i.nsMetadata.Options().SetIndexOptions(i.state.indexOpts)
+	return namespace.NewMetadata(i.nsMetadata.ID(), opts)
+}
+
+func (i *nsIndex) earliestBlockStartToRetainWithRLock(t time.Time) time.Time {
+	return i.state.indexOpts.BlockStartForTime(t.Add(-i.state.retentionPeriod))
+}
+
+// lastSealableBlockStartWithRLock returns the start of the latest index
+// block which can no longer be written to, i.e. the block before the block
+// containing the earliest time that can still be written to.
+func (i *nsIndex) lastSealableBlockStartWithRLock(t time.Time) time.Time {
+	writable := i.state.indexOpts.BlockStartForTime(t.Add(-i.state.bufferPast))
+	return i.state.indexOpts.BlockStartForTime(writable.Add(-time.Nanosecond))
+}
+
 func (i *nsIndex) updateBlockStartsWithLock() {
 	// update ordered blockStarts slice
 	var (
@@ -1244,7 +1458,7 @@ func (i *nsIndex) CleanupExpiredFileSets(t time.Time) error {
 	}
 
 	// earliest block to retain based on retention period
-	earliestBlockStartToRetain := retention.FlushTimeStartForRetentionPeriod(i.retentionPeriod, i.blockSize, t)
+	earliestBlockStartToRetain := i.earliestBlockStartToRetainWithRLock(t)
 
 	// now we loop through the blocks we hold, to ensure we don't delete any data for them.
 	for t := range i.state.blocksByTime {
@@ -0,0 +1,112 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/m3db/m3x/ident"
+)
+
+type aggregateResults struct {
+	nsID    ident.ID
+	aggType AggregationType
+	size    int
+	fields  map[string]map[string]struct{}
+}
+
+// NewAggregateResults returns a new aggregate results object.
+func NewAggregateResults(
+	nsID ident.ID,
+	aggType AggregationType,
+) AggregateResults {
+	return &aggregateResults{
+		nsID:    nsID,
+		aggType: aggType,
+		fields:  make(map[string]map[string]struct{}),
+	}
+}
+
+func (r *aggregateResults) Namespace() ident.ID {
+	return r.nsID
+}
+
+func (r *aggregateResults) Type() AggregationType {
+	return r.aggType
+}
+
+func (r *aggregateResults) Size() int {
+	return r.size
+}
+
+func (r *aggregateResults) AddField(field []byte) int {
+	r.termsFor(field)
+	return r.size
+}
+
+func (r *aggregateResults) AddFieldAndTerm(field, term []byte) int {
+	terms := r.termsFor(field)
+	if r.aggType == AggregateTagNames {
+		return r.size
+	}
+	// NB: the string conversion in the map lookup does not allocate, only
+	// the insert of a new key makes a copy of the term.
+	if _, ok := terms[string(term)]; !ok {
+		terms[string(term)] = struct{}{}
+		r.size++
+	}
+	return r.size
+}
+
+func (r *aggregateResults) termsFor(field []byte) map[string]struct{} {
+	terms, ok := r.fields[string(field)]
+	if ok {
+		return terms
+	}
+	terms = make(map[string]struct{})
+	r.fields[string(field)] = terms
+	if r.aggType == AggregateTagNames {
+		r.size++
+	}
+	return terms
+}
+
+func (r *aggregateResults) Fields() []AggregateField {
+	fields := make([]AggregateField, 0, len(r.fields))
+	for name, terms := range r.fields {
+		field := AggregateField{Name: []byte(name)}
+		if len(terms) > 0 {
+			field.Terms = make([][]byte, 0, len(terms))
+			for term := range terms {
+				field.Terms = append(field.Terms, []byte(term))
+			}
+			sort.Slice(field.Terms, func(i, j int) bool {
+				return bytes.Compare(field.Terms[i], field.Terms[j]) < 0
+			})
+		}
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return bytes.Compare(fields[i].Name, fields[j].Name) < 0
+	})
+	return fields
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/m3db/m3x/ident"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateResultsAddFieldAndTerm(t *testing.T) {
+	res := NewAggregateResults(ident.StringID("ns"), AggregateTagNamesAndValues)
+	require.Equal(t, 1, res.AddFieldAndTerm([]byte("foo"), []byte("b")))
+	require.Equal(t, 2, res.AddFieldAndTerm([]byte("foo"), []byte("a")))
+	require.Equal(t, 2, res.AddFieldAndTerm([]byte("foo"), []byte("a")))
+	require.Equal(t, 3, res.AddFieldAndTerm([]byte("bar"), []byte("a")))
+	require.Equal(t, 3, res.Size())
+	require.Equal(t, "ns", res.Namespace().String())
+
+	require.Equal(t, []AggregateField{
+		{Name: []byte("bar"), Terms: [][]byte{[]byte("a")}},
+		{Name: []byte("foo"), Terms: [][]byte{[]byte("a"), []byte("b")}},
+	}, res.Fields())
+}
+
+func TestAggregateResultsTagNamesIgnoresTerms(t *testing.T) {
+	res := NewAggregateResults(nil, AggregateTagNames)
+	require.Equal(t, 1, res.AddField([]byte("foo")))
+	require.Equal(t, 1, res.AddFieldAndTerm([]byte("foo"), []byte("a")))
+	require.Equal(t, 2, res.AddFieldAndTerm([]byte("bar"), []byte("a")))
+
+	require.Equal(t, []AggregateField{
+		{Name: []byte("bar")},
+		{Name: []byte("foo")},
+	}, res.Fields())
+}
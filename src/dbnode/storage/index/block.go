@@ -21,6 +21,7 @@
 package index
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"sync"
@@ -35,6 +36,7 @@ import (
 	"github.com/m3db/m3/src/m3ninx/index/segment"
 	"github.com/m3db/m3/src/m3ninx/index/segment/builder"
 	"github.com/m3db/m3/src/m3ninx/index/segment/fst"
+	"github.com/m3db/m3/src/m3ninx/postings"
 	"github.com/m3db/m3/src/m3ninx/search"
 	"github.com/m3db/m3/src/m3ninx/search/executor"
 	"github.com/m3db/m3x/context"
@@ -189,7 +191,7 @@ func NewBlock(
 		return nil, err
 	}
 
-	blockSize := md.Options().IndexOptions().BlockSize()
+	blockSize := md.Options().IndexOptions().BlockSizeForBlockStart(blockStart)
 	iopts := opts.InstrumentOptions()
 	b := &block{
 		state:               blockStateOpen,
@@ -792,6 +794,10 @@ func (b *block) Query(
 		if err != nil {
 			return false, err
 		}
+
+		if opts.DocsLimitExceeded(size) {
+			return false, NewDocsLimitExceededError(size, opts.DocsLimit)
+		}
 	}
 
 	if err := iter.Err(); err != nil {
@@ -810,6 +816,177 @@ func (b *block) Query(
 	return exhaustive, nil
 }
 
+func (b *block) Aggregate(
+	query Query,
+	opts AggregationOptions,
+	results AggregateResults,
+) (bool, error) {
+	b.RLock()
+	defer b.RUnlock()
+	if b.state == blockStateClosed {
+		return false, ErrUnableToQueryBlockClosed
+	}
+
+	// NB: an empty query matches every document, in which case every term
+	// of every field is included without resolving any postings lists.
+	var searcher search.Searcher
+	if q := query.Query.SearchQuery(); q != nil {
+		s, err := q.Searcher()
+		if err != nil {
+			return false, err
+		}
+		searcher = s
+	}
+
+	for _, seg := range b.segmentsWithRLock() {
+		exhaustive, err := aggregateSegment(seg, searcher, opts, results)
+		if err != nil {
+			return false, err
+		}
+		if !exhaustive {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (b *block) segmentsWithRLock() []segment.Segment {
+	numSegments := len(b.foregroundSegments) + len(b.backgroundSegments)
+	for _, group := range b.shardRangesSegments {
+		numSegments += len(group.segments)
+	}
+
+	segments := make([]segment.Segment, 0, numSegments)
+	for _, seg := range b.foregroundSegments {
+		segments = append(segments, seg.Segment())
+	}
+	for _, seg := range b.backgroundSegments {
+		segments = append(segments, seg.Segment())
+	}
+	for _, group := range b.shardRangesSegments {
+		segments = append(segments, group.segments...)
+	}
+	return segments
+}
+
+func aggregateSegment(
+	seg segment.Segment,
+	searcher search.Searcher,
+	opts AggregationOptions,
+	results AggregateResults,
+) (bool, error) {
+	var matched postings.List
+	if searcher != nil {
+		reader, err := seg.Reader()
+		if err != nil {
+			return false, err
+		}
+		defer reader.Close()
+
+		matched, err = searcher.Search(reader)
+		if err != nil {
+			return false, err
+		}
+		if matched.IsEmpty() {
+			return true, nil
+		}
+	}
+
+	fieldsIter, err := seg.FieldsIterable().Fields()
+	if err != nil {
+		return false, err
+	}
+	fieldsCloser := safeCloser{closable: fieldsIter}
+	defer fieldsCloser.Close()
+
+	for fieldsIter.Next() {
+		field := fieldsIter.Current()
+		if bytes.Equal(field, doc.IDReservedFieldName) ||
+			!opts.FieldFilter.Matches(field) {
+			continue
+		}
+
+		if opts.LimitExceeded(results.Size()) {
+			return false, nil
+		}
+
+		if opts.Type == AggregateTagNames && matched == nil {
+			results.AddField(field)
+			continue
+		}
+
+		exhaustive, err := aggregateTerms(seg, field, matched, opts, results)
+		if err != nil {
+			return false, err
+		}
+		if !exhaustive {
+			return false, nil
+		}
+	}
+
+	if err := fieldsIter.Err(); err != nil {
+		return false, err
+	}
+
+	return true, fieldsCloser.Close()
+}
+
+func aggregateTerms(
+	seg segment.Segment,
+	field []byte,
+	matched postings.List,
+	opts AggregationOptions,
+	results AggregateResults,
+) (bool, error) {
+	termsIter, err := seg.TermsIterable().Terms(field)
+	if err != nil {
+		return false, err
+	}
+	termsCloser := safeCloser{closable: termsIter}
+	defer termsCloser.Close()
+
+	for termsIter.Next() {
+		term, pl := termsIter.Current()
+		if matched != nil && !postingsIntersect(matched, pl) {
+			continue
+		}
+
+		if opts.Type == AggregateTagNames {
+			// Only need a single matching term to include the field.
+			results.AddField(field)
+			break
+		}
+
+		if opts.LimitExceeded(results.Size()) {
+			return false, nil
+		}
+		results.AddFieldAndTerm(field, term)
+	}
+
+	if err := termsIter.Err(); err != nil {
+		return false, err
+	}
+
+	return true, termsCloser.Close()
+}
+
+// postingsIntersect returns whether the two postings lists share at least
+// one document, it iterates the shorter of the two lists.
+func postingsIntersect(a, b postings.List) bool {
+	if a.Len() > b.Len() {
+		a, b = b, a
+	}
+	iter := a.Iterator()
+	defer iter.Close()
+	for iter.Next() {
+		if b.Contains(iter.Current()) {
+			return true
+		}
+	}
+	return false
+}
+
 func (b *block) AddResults(
 	results result.IndexBlock,
 ) error {
@@ -28,6 +28,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/storage/index/compaction"
+	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/m3ninx/doc"
 	"github.com/m3db/m3/src/m3ninx/idx"
@@ -1155,6 +1156,161 @@ func TestBlockE2EInsertQuery(t *testing.T) {
 		ident.NewTagsIterator(t2)))
 }
 
+func TestBlockE2EInsertQueryDocsLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blockSize := time.Hour
+
+	testMD := newTestNSMetadata(t)
+	now := time.Now()
+	blockStart := now.Truncate(blockSize)
+
+	nowNotBlockStartAligned := now.
+		Truncate(blockSize).
+		Add(time.Minute)
+
+	blk, err := NewBlock(blockStart, testMD, testOpts)
+	require.NoError(t, err)
+	b, ok := blk.(*block)
+	require.True(t, ok)
+
+	batch := NewWriteBatch(WriteBatchOptions{
+		IndexBlockSize: blockSize,
+	})
+	for _, d := range []doc.Document{testDoc1(), testDoc2()} {
+		h := NewMockOnIndexSeries(ctrl)
+		h.EXPECT().OnIndexFinalize(xtime.ToUnixNano(blockStart))
+		h.EXPECT().OnIndexSuccess(xtime.ToUnixNano(blockStart))
+		batch.Append(WriteBatchEntry{
+			Timestamp:     nowNotBlockStartAligned,
+			OnIndexSeries: h,
+		}, d)
+	}
+
+	res, err := b.WriteBatch(batch)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), res.NumSuccess)
+
+	q, err := idx.NewRegexpQuery([]byte("bar"), []byte("b.*"))
+	require.NoError(t, err)
+
+	// Matching exactly the docs limit succeeds.
+	results := NewResults(testOpts)
+	exhaustive, err := b.Query(Query{q}, QueryOptions{DocsLimit: 2}, results)
+	require.NoError(t, err)
+	require.True(t, exhaustive)
+	require.Equal(t, 2, results.Size())
+
+	// Matching more than the docs limit fails rather than truncating.
+	results = NewResults(testOpts)
+	_, err = b.Query(Query{q}, QueryOptions{DocsLimit: 1}, results)
+	require.Error(t, err)
+	require.True(t, limits.IsQueryLimitExceededError(err))
+}
+
+func TestBlockE2EInsertAggregate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blockSize := time.Hour
+	testMD := newTestNSMetadata(t)
+	now := time.Now()
+	blockStart := now.Truncate(blockSize)
+
+	blk, err := NewBlock(blockStart, testMD, testOpts)
+	require.NoError(t, err)
+	b, ok := blk.(*block)
+	require.True(t, ok)
+
+	batch := NewWriteBatch(WriteBatchOptions{
+		IndexBlockSize: blockSize,
+	})
+	for _, d := range []doc.Document{testDoc1(), testDoc2(), testDoc3()} {
+		h := NewMockOnIndexSeries(ctrl)
+		h.EXPECT().OnIndexFinalize(xtime.ToUnixNano(blockStart))
+		h.EXPECT().OnIndexSuccess(xtime.ToUnixNano(blockStart))
+		batch.Append(WriteBatchEntry{
+			Timestamp:     blockStart.Add(time.Minute),
+			OnIndexSeries: h,
+		}, d)
+	}
+
+	res, err := b.WriteBatch(batch)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), res.NumSuccess)
+
+	toStrings := func(fields []AggregateField) map[string][]string {
+		out := make(map[string][]string, len(fields))
+		for _, f := range fields {
+			terms := []string{}
+			for _, term := range f.Terms {
+				terms = append(terms, string(term))
+			}
+			out[string(f.Name)] = terms
+		}
+		return out
+	}
+
+	// Empty query matches all documents.
+	results := NewAggregateResults(nil, AggregateTagNamesAndValues)
+	exhaustive, err := b.Aggregate(Query{}, AggregationOptions{}, results)
+	require.NoError(t, err)
+	require.True(t, exhaustive)
+	require.Equal(t, 4, results.Size())
+	require.Equal(t, map[string][]string{
+		"bar":  {"baz", "qux"},
+		"some": {"more", "other"},
+	}, toStrings(results.Fields()))
+
+	// Terms are restricted to those of matching documents.
+	q, err := idx.NewTermQuery([]byte("bar"), []byte("qux"))
+	require.NoError(t, err)
+	results = NewAggregateResults(nil, AggregateTagNamesAndValues)
+	exhaustive, err = b.Aggregate(Query{q}, AggregationOptions{}, results)
+	require.NoError(t, err)
+	require.True(t, exhaustive)
+	require.Equal(t, map[string][]string{
+		"bar":  {"qux"},
+		"some": {"other"},
+	}, toStrings(results.Fields()))
+
+	// Field filter and tag names only.
+	q, err = idx.NewRegexpQuery([]byte("bar"), []byte("b.*"))
+	require.NoError(t, err)
+	results = NewAggregateResults(nil, AggregateTagNames)
+	exhaustive, err = b.Aggregate(Query{q}, AggregationOptions{
+		FieldFilter: AggregateFieldFilter{[]byte("some")},
+		Type:        AggregateTagNames,
+	}, results)
+	require.NoError(t, err)
+	require.True(t, exhaustive)
+	require.Equal(t, map[string][]string{
+		"some": {},
+	}, toStrings(results.Fields()))
+
+	// Limit.
+	results = NewAggregateResults(nil, AggregateTagNamesAndValues)
+	exhaustive, err = b.Aggregate(Query{}, AggregationOptions{
+		QueryOptions: QueryOptions{Limit: 1},
+	}, results)
+	require.NoError(t, err)
+	require.False(t, exhaustive)
+	require.Equal(t, 1, results.Size())
+}
+
+func TestBlockAggregateAfterClose(t *testing.T) {
+	testMD := newTestNSMetadata(t)
+	start := time.Now().Truncate(time.Hour)
+	b, err := NewBlock(start, testMD, testOpts)
+	require.NoError(t, err)
+	require.NoError(t, b.Close())
+
+	results := NewAggregateResults(nil, AggregateTagNamesAndValues)
+	_, err = b.Aggregate(Query{}, AggregationOptions{}, results)
+	require.Equal(t, ErrUnableToQueryBlockClosed, err)
+}
+
 func TestBlockE2EInsertQueryLimit(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
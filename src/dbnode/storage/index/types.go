@@ -21,13 +21,17 @@
 package index
 
 import (
+	"bytes"
 	"fmt"
 	"sort"
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/clock"
+	"github.com/m3db/m3/src/dbnode/encoding/downsample"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/storage/index/compaction"
+	"github.com/m3db/m3/src/dbnode/storage/limits"
+	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/m3ninx/doc"
 	"github.com/m3db/m3/src/m3ninx/idx"
 	"github.com/m3db/m3/src/m3ninx/index/segment/builder"
@@ -65,6 +69,15 @@ type QueryOptions struct {
 	StartInclusive time.Time
 	EndExclusive   time.Time
 	Limit          int
+	// DocsLimit, if set, fails the query rather than truncating results
+	// when more documents than the limit are matched.
+	DocsLimit int
+	// Downsample, if enabled, downsamples the data of the matched series
+	// server side before it is returned.
+	// NB: this is not set by the coordinator, whose range functions need
+	// the raw datapoints of a series, so it is only used by clients of the
+	// node which set it directly.
+	Downsample downsample.Options
 }
 
 // LimitExceeded returns whether a given size exceeds the limit
@@ -73,6 +86,19 @@ func (o QueryOptions) LimitExceeded(size int) bool {
 	return o.Limit > 0 && size >= o.Limit
 }
 
+// DocsLimitExceeded returns whether a given number of matched documents
+// exceeds the docs limit the query options imposes, if it is enabled.
+func (o QueryOptions) DocsLimitExceeded(size int) bool {
+	return o.DocsLimit > 0 && size > o.DocsLimit
+}
+
+// NewDocsLimitExceededError returns the error for a query that matched
+// more documents than its docs limit.
+func NewDocsLimitExceededError(docsMatched, docsLimit int) error {
+	return limits.NewQueryLimitExceededError("docs-matched-per-query",
+		int64(docsMatched), int64(docsLimit))
+}
+
 // QueryResults is the collection of results for a query.
 type QueryResults struct {
 	Results    Results
@@ -115,6 +141,93 @@ type Results interface {
 	) (added bool, size int, err error)
 }
 
+// AggregationType specifies what an aggregate query collects.
+type AggregationType uint8
+
+const (
+	// AggregateTagNamesAndValues collects distinct tag names along with the
+	// distinct values seen for each of them.
+	AggregateTagNamesAndValues AggregationType = iota
+	// AggregateTagNames collects only the distinct tag names.
+	AggregateTagNames
+)
+
+// Validate validates the aggregation type.
+func (t AggregationType) Validate() error {
+	switch t {
+	case AggregateTagNamesAndValues, AggregateTagNames:
+		return nil
+	}
+	return fmt.Errorf("unknown aggregation type: %d", t)
+}
+
+// AggregateFieldFilter restricts an aggregate query to a set of field names,
+// an empty filter matches every field.
+type AggregateFieldFilter [][]byte
+
+// Matches returns whether the filter allows the provided field.
+func (f AggregateFieldFilter) Matches(field []byte) bool {
+	if len(f) == 0 {
+		return true
+	}
+	for _, allowed := range f {
+		if bytes.Equal(allowed, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// AggregationOptions enables users to specify constraints on aggregate queries.
+type AggregationOptions struct {
+	QueryOptions
+
+	// FieldFilter restricts the fields returned, if non-empty.
+	FieldFilter AggregateFieldFilter
+
+	// Type is the type of aggregation to perform.
+	Type AggregationType
+}
+
+// AggregateQueryResult is the collection of results for an aggregate query.
+type AggregateQueryResult struct {
+	Results    AggregateResults
+	Exhaustive bool
+}
+
+// AggregateField is a field name and the distinct terms seen for it, terms
+// are empty when only tag names were aggregated.
+type AggregateField struct {
+	Name  []byte
+	Terms [][]byte
+}
+
+// AggregateResults is a collection of distinct fields and terms returned by
+// an aggregate query. It is not safe for concurrent use.
+type AggregateResults interface {
+	// Namespace returns the namespace associated with the result.
+	Namespace() ident.ID
+
+	// Type returns the aggregation type of the results.
+	Type() AggregationType
+
+	// Size returns the number of fields tracked when aggregating tag names,
+	// otherwise the number of distinct field and term pairs tracked.
+	Size() int
+
+	// AddField adds a field, it makes a copy of the provided bytes and returns
+	// the size of the results after the add.
+	AddField(field []byte) (size int)
+
+	// AddFieldAndTerm adds a field and term pair, it makes a copy of the
+	// provided bytes and returns the size of the results after the add.
+	AddFieldAndTerm(field, term []byte) (size int)
+
+	// Fields returns the tracked fields ordered by name, with terms ordered
+	// lexicographically.
+	Fields() []AggregateField
+}
+
 // ResultsAllocator allocates Results types.
 type ResultsAllocator func() Results
 
@@ -164,6 +277,15 @@ type Block interface {
 		results Results,
 	) (exhaustive bool, err error)
 
+	// Aggregate resolves the given query into the distinct fields and terms
+	// of the matching documents, reading directly from segment terms
+	// dictionaries rather than materializing documents.
+	Aggregate(
+		query Query,
+		opts AggregationOptions,
+		results AggregateResults,
+	) (exhaustive bool, err error)
+
 	// AddResults adds bootstrap results to the block, if c.
 	AddResults(results result.IndexBlock) error
 
@@ -277,8 +399,9 @@ const (
 
 // WriteBatchOptions is a set of options required for a write batch.
 type WriteBatchOptions struct {
-	InitialCapacity int
-	IndexBlockSize  time.Duration
+	InitialCapacity      int
+	IndexBlockSize       time.Duration
+	IndexBlockSizeChange namespace.IndexBlockSizeChange
 }
 
 // NewWriteBatch creates a new write batch.
@@ -290,6 +413,16 @@ func NewWriteBatch(opts WriteBatchOptions) *WriteBatch {
 	}
 }
 
+// SetIndexBlockSize sets the index block size, and any change from a
+// previous index block size, used to group the entries by index block.
+func (b *WriteBatch) SetIndexBlockSize(
+	blockSize time.Duration,
+	change namespace.IndexBlockSizeChange,
+) {
+	b.opts.IndexBlockSize = blockSize
+	b.opts.IndexBlockSizeChange = change
+}
+
 // Append appends an entry with accompanying document.
 func (b *WriteBatch) Append(
 	entry WriteBatchEntry,
@@ -371,7 +504,6 @@ func (b *WriteBatch) ForEachUnmarkedBatchByBlockStart(
 	}()
 
 	var (
-		blockSize      = b.opts.IndexBlockSize
 		startIdx       = 0
 		lastBlockStart xtime.UnixNano
 	)
@@ -386,7 +518,7 @@ func (b *WriteBatch) ForEachUnmarkedBatchByBlockStart(
 			return
 		}
 
-		blockStart := allEntries[i].indexBlockStart(blockSize)
+		blockStart := allEntries[i].indexBlockStart(b.opts)
 		if !blockStart.Equal(lastBlockStart) {
 			prevLastBlockStart := lastBlockStart.ToTime()
 			lastBlockStart = blockStart
@@ -480,7 +612,7 @@ func (b *WriteBatch) SortByEnqueued() {
 func (b *WriteBatch) MarkUnmarkedEntriesSuccess() {
 	for idx := range b.entries {
 		if !b.entries[idx].result.Done {
-			blockStart := b.entries[idx].indexBlockStart(b.opts.IndexBlockSize)
+			blockStart := b.entries[idx].indexBlockStart(b.opts)
 			b.entries[idx].OnIndexSeries.OnIndexSuccess(blockStart)
 			b.entries[idx].OnIndexSeries.OnIndexFinalize(blockStart)
 			b.entries[idx].result.Done = true
@@ -502,7 +634,7 @@ func (b *WriteBatch) MarkUnmarkedEntryError(
 	idx int,
 ) {
 	if b.entries[idx].OnIndexSeries != nil {
-		blockStart := b.entries[idx].indexBlockStart(b.opts.IndexBlockSize)
+		blockStart := b.entries[idx].indexBlockStart(b.opts)
 		b.entries[idx].OnIndexSeries.OnIndexFinalize(blockStart)
 		b.entries[idx].result.Done = true
 		b.entries[idx].result.Err = err
@@ -543,8 +675,8 @@ func (b *WriteBatch) Less(i, j int) bool {
 	}
 
 	// They're either both unmarked or marked
-	blockStartI := b.entries[i].indexBlockStart(b.opts.IndexBlockSize)
-	blockStartJ := b.entries[j].indexBlockStart(b.opts.IndexBlockSize)
+	blockStartI := b.entries[i].indexBlockStart(b.opts)
+	blockStartJ := b.entries[j].indexBlockStart(b.opts)
 	return blockStartI.Before(blockStartJ)
 }
 
@@ -579,9 +711,11 @@ type WriteBatchEntryResult struct {
 }
 
 func (e WriteBatchEntry) indexBlockStart(
-	indexBlockSize time.Duration,
+	opts WriteBatchOptions,
 ) xtime.UnixNano {
-	return xtime.ToUnixNano(e.Timestamp.Truncate(indexBlockSize))
+	blockStart := opts.IndexBlockSizeChange.BlockStart(e.Timestamp,
+		opts.IndexBlockSize)
+	return xtime.ToUnixNano(blockStart)
 }
 
 // Result returns the result for this entry.
@@ -28,6 +28,7 @@ import (
 
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/m3ninx/doc"
 	"github.com/m3db/m3/src/m3ninx/index/segment"
@@ -549,3 +550,191 @@ func TestNamespaceIndexBlockQuery(t *testing.T) {
 	_, err = idx.Query(ctx, q, qOpts)
 	require.NoError(t, err)
 }
+
+func TestNamespaceIndexBlockQueryDocsLimit(t *testing.T) {
+	ctrl := gomock.NewController(xtest.Reporter{T: t})
+	defer ctrl.Finish()
+
+	retention := 2 * time.Hour
+	blockSize := time.Hour
+	now := time.Now().Truncate(blockSize).Add(10 * time.Minute)
+	t0 := now.Truncate(blockSize)
+	t0Nanos := xtime.ToUnixNano(t0)
+	t1 := t0.Add(1 * blockSize)
+	t1Nanos := xtime.ToUnixNano(t1)
+	t2 := t1.Add(1 * blockSize)
+	var nowLock sync.Mutex
+	nowFn := func() time.Time {
+		nowLock.Lock()
+		defer nowLock.Unlock()
+		return now
+	}
+	opts := testDatabaseOptions()
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(nowFn))
+	opts = opts.SetLimitsOptions(opts.LimitsOptions().SetMaxDocsMatchedPerQuery(3))
+
+	b0 := index.NewMockBlock(ctrl)
+	b0.EXPECT().Stats(gomock.Any()).Return(nil).AnyTimes()
+	b0.EXPECT().Close().Return(nil)
+	b0.EXPECT().StartTime().Return(t0).AnyTimes()
+	b0.EXPECT().EndTime().Return(t0.Add(blockSize)).AnyTimes()
+	b1 := index.NewMockBlock(ctrl)
+	b1.EXPECT().Stats(gomock.Any()).Return(nil).AnyTimes()
+	b1.EXPECT().Close().Return(nil)
+	b1.EXPECT().StartTime().Return(t1).AnyTimes()
+	b1.EXPECT().EndTime().Return(t1.Add(blockSize)).AnyTimes()
+	newBlockFn := func(ts time.Time, md namespace.Metadata, io index.Options) (index.Block, error) {
+		if ts.Equal(t0) {
+			return b0, nil
+		}
+		if ts.Equal(t1) {
+			return b1, nil
+		}
+		panic("should never get here")
+	}
+	md := testNamespaceMetadata(blockSize, retention)
+	idx, err := newNamespaceIndexWithNewBlockFn(md, newBlockFn, opts)
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, idx.Close())
+	}()
+
+	seg1 := segment.NewMockSegment(ctrl)
+	seg2 := segment.NewMockSegment(ctrl)
+	bootstrapResults := result.IndexResults{
+		t0Nanos: result.NewIndexBlock(t0, []segment.Segment{seg1}, result.NewShardTimeRanges(t0, t1, 1, 2, 3)),
+		t1Nanos: result.NewIndexBlock(t1, []segment.Segment{seg2}, result.NewShardTimeRanges(t1, t2, 1, 2, 3)),
+	}
+
+	b0.EXPECT().AddResults(bootstrapResults[t0Nanos]).Return(nil)
+	b1.EXPECT().AddResults(bootstrapResults[t1Nanos]).Return(nil)
+	require.NoError(t, idx.Bootstrap(bootstrapResults))
+
+	queryFn := func(ids ...string) func(index.Query, index.QueryOptions, index.Results) (bool, error) {
+		return func(_ index.Query, _ index.QueryOptions, r index.Results) (bool, error) {
+			for _, id := range ids {
+				if _, _, err := r.AddIDAndTags(ident.StringID(id), ident.Tags{}); err != nil {
+					return false, err
+				}
+			}
+			return true, nil
+		}
+	}
+
+	// the docs limit applies to documents matched across all blocks and
+	// fails the query rather than truncating results
+	ctx := context.NewContext()
+	defer ctx.Close()
+	q := index.Query{}
+	qOpts := index.QueryOptions{
+		StartInclusive: t0,
+		EndExclusive:   t2.Add(time.Minute),
+	}
+	blockOpts := qOpts
+	blockOpts.DocsLimit = 3
+	b0.EXPECT().Query(q, blockOpts, gomock.Any()).DoAndReturn(queryFn("foo", "bar"))
+	b1.EXPECT().Query(q, blockOpts, gomock.Any()).DoAndReturn(queryFn("baz", "qux"))
+	_, err = idx.Query(ctx, q, qOpts)
+	require.Error(t, err)
+	require.True(t, limits.IsQueryLimitExceededError(err))
+}
+
+func TestNamespaceIndexBlockAggregateQuery(t *testing.T) {
+	ctrl := gomock.NewController(xtest.Reporter{T: t})
+	defer ctrl.Finish()
+
+	retention := 2 * time.Hour
+	blockSize := time.Hour
+	now := time.Now().Truncate(blockSize).Add(10 * time.Minute)
+	t0 := now.Truncate(blockSize)
+	t0Nanos := xtime.ToUnixNano(t0)
+	t1 := t0.Add(1 * blockSize)
+	t1Nanos := xtime.ToUnixNano(t1)
+	t2 := t1.Add(1 * blockSize)
+	var nowLock sync.Mutex
+	nowFn := func() time.Time {
+		nowLock.Lock()
+		defer nowLock.Unlock()
+		return now
+	}
+	opts := testDatabaseOptions()
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(nowFn))
+
+	b0 := index.NewMockBlock(ctrl)
+	b0.EXPECT().Stats(gomock.Any()).Return(nil).AnyTimes()
+	b0.EXPECT().Close().Return(nil)
+	b0.EXPECT().StartTime().Return(t0).AnyTimes()
+	b0.EXPECT().EndTime().Return(t0.Add(blockSize)).AnyTimes()
+	b1 := index.NewMockBlock(ctrl)
+	b1.EXPECT().Stats(gomock.Any()).Return(nil).AnyTimes()
+	b1.EXPECT().Close().Return(nil)
+	b1.EXPECT().StartTime().Return(t1).AnyTimes()
+	b1.EXPECT().EndTime().Return(t1.Add(blockSize)).AnyTimes()
+	newBlockFn := func(ts time.Time, md namespace.Metadata, io index.Options) (index.Block, error) {
+		if ts.Equal(t0) {
+			return b0, nil
+		}
+		if ts.Equal(t1) {
+			return b1, nil
+		}
+		panic("should never get here")
+	}
+	md := testNamespaceMetadata(blockSize, retention)
+	idx, err := newNamespaceIndexWithNewBlockFn(md, newBlockFn, opts)
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, idx.Close())
+	}()
+
+	seg1 := segment.NewMockSegment(ctrl)
+	seg2 := segment.NewMockSegment(ctrl)
+	bootstrapResults := result.IndexResults{
+		t0Nanos: result.NewIndexBlock(t0, []segment.Segment{seg1}, result.NewShardTimeRanges(t0, t1, 1, 2, 3)),
+		t1Nanos: result.NewIndexBlock(t1, []segment.Segment{seg2}, result.NewShardTimeRanges(t1, t2, 1, 2, 3)),
+	}
+
+	b0.EXPECT().AddResults(bootstrapResults[t0Nanos]).Return(nil)
+	b1.EXPECT().AddResults(bootstrapResults[t1Nanos]).Return(nil)
+	require.NoError(t, idx.Bootstrap(bootstrapResults))
+
+	// aggregates the results of every block into a single result
+	ctx := context.NewContext()
+	q := index.Query{}
+	aggOpts := index.AggregationOptions{
+		QueryOptions: index.QueryOptions{
+			StartInclusive: t0,
+			EndExclusive:   t2.Add(time.Minute),
+		},
+	}
+	b0.EXPECT().Aggregate(q, aggOpts, gomock.Any()).DoAndReturn(
+		func(_ index.Query, _ index.AggregationOptions, r index.AggregateResults) (bool, error) {
+			r.AddFieldAndTerm([]byte("foo"), []byte("bar"))
+			return true, nil
+		})
+	b1.EXPECT().Aggregate(q, aggOpts, gomock.Any()).DoAndReturn(
+		func(_ index.Query, _ index.AggregationOptions, r index.AggregateResults) (bool, error) {
+			r.AddFieldAndTerm([]byte("foo"), []byte("bar"))
+			r.AddFieldAndTerm([]byte("foo"), []byte("baz"))
+			return true, nil
+		})
+	res, err := idx.AggregateQuery(ctx, q, aggOpts)
+	require.NoError(t, err)
+	require.True(t, res.Exhaustive)
+	require.Equal(t, 2, res.Results.Size())
+	require.Equal(t, []index.AggregateField{
+		{Name: []byte("foo"), Terms: [][]byte{[]byte("bar"), []byte("baz")}},
+	}, res.Results.Fields())
+
+	// stops querying once a block returns non-exhaustive
+	b0.EXPECT().Aggregate(q, aggOpts, gomock.Any()).Return(false, nil)
+	res, err = idx.AggregateQuery(ctx, q, aggOpts)
+	require.NoError(t, err)
+	require.False(t, res.Exhaustive)
+
+	// rejects unknown aggregation types
+	aggOpts.Type = index.AggregationType(100)
+	_, err = idx.AggregateQuery(ctx, q, aggOpts)
+	require.Error(t, err)
+}
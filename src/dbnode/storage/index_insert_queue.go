@@ -263,8 +263,10 @@ func newNsIndexInsertBatch(
 }
 
 func (b *nsIndexInsertBatch) allocateAllInserts() {
+	indexOpts := b.namespace.Options().IndexOptions()
 	b.allInserts = index.NewWriteBatch(index.WriteBatchOptions{
-		IndexBlockSize: b.namespace.Options().IndexOptions().BlockSize(),
+		IndexBlockSize:       indexOpts.BlockSize(),
+		IndexBlockSizeChange: indexOpts.BlockSizeChange(),
 	})
 	b.allInsertsLastReset = b.nowFn()
 }
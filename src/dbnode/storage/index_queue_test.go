@@ -136,10 +136,10 @@ func TestNamespaceIndexWriteAfterClose(t *testing.T) {
 
 	lifecycle := index.NewMockOnIndexSeries(ctrl)
 	lifecycle.EXPECT().
-		OnIndexFinalize(xtime.ToUnixNano(now.Truncate(idx.blockSize)))
+		OnIndexFinalize(xtime.ToUnixNano(now.Truncate(idx.state.indexOpts.BlockSize())))
 	entry, document := testWriteBatchEntry(id, tags, now, lifecycle)
 	assert.Error(t, idx.WriteBatch(testWriteBatch(entry, document,
-		testWriteBatchBlockSizeOption(idx.blockSize))))
+		testWriteBatchBlockSizeOption(idx.state.indexOpts.BlockSize()))))
 }
 
 func TestNamespaceIndexWriteQueueError(t *testing.T) {
@@ -158,13 +158,13 @@ func TestNamespaceIndexWriteQueueError(t *testing.T) {
 	n := time.Now()
 	lifecycle := index.NewMockOnIndexSeries(ctrl)
 	lifecycle.EXPECT().
-		OnIndexFinalize(xtime.ToUnixNano(n.Truncate(idx.blockSize)))
+		OnIndexFinalize(xtime.ToUnixNano(n.Truncate(idx.state.indexOpts.BlockSize())))
 	q.EXPECT().
 		InsertBatch(gomock.Any()).
 		Return(nil, fmt.Errorf("random err"))
 	entry, document := testWriteBatchEntry(id, tags, n, lifecycle)
 	assert.Error(t, idx.WriteBatch(testWriteBatch(entry, document,
-		testWriteBatchBlockSizeOption(idx.blockSize))))
+		testWriteBatchBlockSizeOption(idx.state.indexOpts.BlockSize()))))
 }
 
 func TestNamespaceIndexInsertOlderThanRetentionPeriod(t *testing.T) {
@@ -201,11 +201,11 @@ func TestNamespaceIndexInsertOlderThanRetentionPeriod(t *testing.T) {
 		lifecycle = index.NewMockOnIndexSeries(ctrl)
 	)
 
-	tooOld := now.Add(-1 * idx.bufferPast).Add(-1 * time.Second)
+	tooOld := now.Add(-1 * idx.state.bufferPast).Add(-1 * time.Second)
 	lifecycle.EXPECT().
-		OnIndexFinalize(xtime.ToUnixNano(tooOld.Truncate(idx.blockSize)))
+		OnIndexFinalize(xtime.ToUnixNano(tooOld.Truncate(idx.state.indexOpts.BlockSize())))
 	entry, document := testWriteBatchEntry(id, tags, tooOld, lifecycle)
-	batch := testWriteBatch(entry, document, testWriteBatchBlockSizeOption(idx.blockSize))
+	batch := testWriteBatch(entry, document, testWriteBatchBlockSizeOption(idx.state.indexOpts.BlockSize()))
 
 	assert.Error(t, idx.WriteBatch(batch))
 
@@ -222,11 +222,11 @@ func TestNamespaceIndexInsertOlderThanRetentionPeriod(t *testing.T) {
 	})
 	require.Equal(t, 1, verified)
 
-	tooNew := now.Add(1 * idx.bufferFuture).Add(1 * time.Second)
+	tooNew := now.Add(1 * idx.state.bufferFuture).Add(1 * time.Second)
 	lifecycle.EXPECT().
-		OnIndexFinalize(xtime.ToUnixNano(tooNew.Truncate(idx.blockSize)))
+		OnIndexFinalize(xtime.ToUnixNano(tooNew.Truncate(idx.state.indexOpts.BlockSize())))
 	entry, document = testWriteBatchEntry(id, tags, tooNew, lifecycle)
-	batch = testWriteBatch(entry, document, testWriteBatchBlockSizeOption(idx.blockSize))
+	batch = testWriteBatch(entry, document, testWriteBatchBlockSizeOption(idx.state.indexOpts.BlockSize()))
 	assert.Error(t, idx.WriteBatch(batch))
 
 	verified = 0
@@ -287,7 +287,7 @@ func TestNamespaceIndexInsertQuery(t *testing.T) {
 	defer idx.Close()
 
 	var (
-		blockSize = idx.(*nsIndex).blockSize
+		blockSize = idx.(*nsIndex).state.indexOpts.BlockSize()
 		ts        = idx.(*nsIndex).state.latestBlock.StartTime()
 		now       = time.Now()
 		id        = ident.StringID("foo")
@@ -30,6 +30,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
+	"github.com/m3db/m3/src/m3ninx/doc"
 	"github.com/m3db/m3/src/m3ninx/idx"
 	"github.com/m3db/m3/src/m3ninx/index/segment"
 	"github.com/m3db/m3x/context"
@@ -308,6 +309,87 @@ func TestNamespaceIndexQueryNoMatchingBlocks(t *testing.T) {
 	assert.Equal(t, 0, result.Results.Size())
 }
 
+func TestNamespaceIndexQueryExcludesDeleted(t *testing.T) {
+	ctrl := gomock.NewController(xtest.Reporter{T: t})
+	defer ctrl.Finish()
+
+	test := newTestIndex(t, ctrl)
+
+	now := time.Now().Truncate(test.indexBlockSize)
+	query := index.Query{Query: idx.NewTermQuery([]byte("foo"), []byte("bar"))}
+	idx := test.index.(*nsIndex)
+
+	defer func() {
+		require.NoError(t, idx.Close())
+	}()
+
+	mockBlock := index.NewMockBlock(ctrl)
+	mockBlock.EXPECT().Stats(gomock.Any()).Return(nil).AnyTimes()
+	blockTime := now.Add(-1 * test.indexBlockSize)
+	mockBlock.EXPECT().StartTime().Return(blockTime).AnyTimes()
+	mockBlock.EXPECT().EndTime().Return(blockTime.Add(test.indexBlockSize)).AnyTimes()
+	mockBlock.EXPECT().
+		Query(query, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ index.Query, _ index.QueryOptions, results index.Results) (bool, error) {
+			for _, id := range []string{"foo", "bar"} {
+				_, _, err := results.AddIDAndTags(ident.StringID(id), ident.Tags{})
+				require.NoError(t, err)
+			}
+			return true, nil
+		}).
+		Times(2)
+	mockBlock.EXPECT().Close().Return(nil)
+	idx.state.blocksByTime[xtime.ToUnixNano(blockTime)] = mockBlock
+	idx.updateBlockStartsWithLock()
+
+	require.NoError(t, idx.Delete([]ident.ID{ident.StringID("foo")}))
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	queryOpts := index.QueryOptions{
+		StartInclusive: blockTime,
+		EndExclusive:   now,
+	}
+	result, err := idx.Query(ctx, query, queryOpts)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Results.Size())
+	_, ok := result.Results.Map().Get(ident.StringID("bar"))
+	assert.True(t, ok)
+
+	// Indexing the series again should include it in results again.
+	idx.undeleteIfAny([]doc.Document{{ID: []byte("foo")}})
+	result, err = idx.Query(ctx, query, queryOpts)
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Results.Size())
+}
+
+func TestNamespaceIndexTickRemovesExpiredDeletes(t *testing.T) {
+	ctrl := gomock.NewController(xtest.Reporter{T: t})
+	defer ctrl.Finish()
+
+	test := newTestIndex(t, ctrl)
+	idx := test.index.(*nsIndex)
+
+	defer func() {
+		require.NoError(t, idx.Close())
+	}()
+
+	now := time.Now()
+	idx.nowFn = func() time.Time { return now.Add(-2 * test.retention) }
+	require.NoError(t, idx.Delete([]ident.ID{ident.StringID("foo")}))
+	idx.nowFn = func() time.Time { return now }
+	require.NoError(t, idx.Delete([]ident.ID{ident.StringID("bar")}))
+	require.Equal(t, 2, len(idx.state.deletedIDs))
+
+	_, err := idx.Tick(context.NewCancellable(), now)
+	require.NoError(t, err)
+
+	_, ok := idx.state.deletedIDs["bar"]
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(idx.state.deletedIDs))
+}
+
 type testIndex struct {
 	index          namespaceIndex
 	metadata       namespace.Metadata
@@ -0,0 +1,155 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package limits
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/m3db/m3x/instrument"
+)
+
+const (
+	defaultLookback = 15 * time.Second
+)
+
+var (
+	errInvalidMaxDocsMatchedPerQuery    = errors.New("max docs matched per query must be non-negative")
+	errInvalidMaxSeriesFetchedPerQuery  = errors.New("max series fetched per query must be non-negative")
+	errInvalidMaxBytesReadPerQuery      = errors.New("max bytes read per query must be non-negative")
+	errInvalidMaxDatapointsReadPerQuery = errors.New("max datapoints read per query must be non-negative")
+)
+
+type options struct {
+	iOpts                     instrument.Options
+	maxDocsMatchedPerQuery    int
+	maxSeriesFetchedPerQuery  int
+	maxBytesReadPerQuery      int64
+	maxDatapointsReadPerQuery int64
+	docsLimitOpts             LookbackLimitOptions
+	bytesReadLimitOpts        LookbackLimitOptions
+}
+
+// NewOptions creates new limit options, by default all limits are disabled.
+func NewOptions() Options {
+	return &options{
+		iOpts: instrument.NewOptions(),
+		docsLimitOpts: LookbackLimitOptions{
+			Lookback: defaultLookback,
+		},
+		bytesReadLimitOpts: LookbackLimitOptions{
+			Lookback: defaultLookback,
+		},
+	}
+}
+
+func (o *options) Validate() error {
+	if o.maxDocsMatchedPerQuery < 0 {
+		return errInvalidMaxDocsMatchedPerQuery
+	}
+	if o.maxSeriesFetchedPerQuery < 0 {
+		return errInvalidMaxSeriesFetchedPerQuery
+	}
+	if o.maxBytesReadPerQuery < 0 {
+		return errInvalidMaxBytesReadPerQuery
+	}
+	if o.maxDatapointsReadPerQuery < 0 {
+		return errInvalidMaxDatapointsReadPerQuery
+	}
+	if err := o.docsLimitOpts.Validate(); err != nil {
+		return fmt.Errorf("invalid docs limit options: %v", err)
+	}
+	if err := o.bytesReadLimitOpts.Validate(); err != nil {
+		return fmt.Errorf("invalid bytes read limit options: %v", err)
+	}
+	return nil
+}
+
+func (o *options) SetInstrumentOptions(value instrument.Options) Options {
+	opts := *o
+	opts.iOpts = value
+	return &opts
+}
+
+func (o *options) InstrumentOptions() instrument.Options {
+	return o.iOpts
+}
+
+func (o *options) SetMaxDocsMatchedPerQuery(value int) Options {
+	opts := *o
+	opts.maxDocsMatchedPerQuery = value
+	return &opts
+}
+
+func (o *options) MaxDocsMatchedPerQuery() int {
+	return o.maxDocsMatchedPerQuery
+}
+
+func (o *options) SetMaxSeriesFetchedPerQuery(value int) Options {
+	opts := *o
+	opts.maxSeriesFetchedPerQuery = value
+	return &opts
+}
+
+func (o *options) MaxSeriesFetchedPerQuery() int {
+	return o.maxSeriesFetchedPerQuery
+}
+
+func (o *options) SetMaxBytesReadPerQuery(value int64) Options {
+	opts := *o
+	opts.maxBytesReadPerQuery = value
+	return &opts
+}
+
+func (o *options) MaxBytesReadPerQuery() int64 {
+	return o.maxBytesReadPerQuery
+}
+
+func (o *options) SetMaxDatapointsReadPerQuery(value int64) Options {
+	opts := *o
+	opts.maxDatapointsReadPerQuery = value
+	return &opts
+}
+
+func (o *options) MaxDatapointsReadPerQuery() int64 {
+	return o.maxDatapointsReadPerQuery
+}
+
+func (o *options) SetDocsLimitOptions(value LookbackLimitOptions) Options {
+	opts := *o
+	opts.docsLimitOpts = value
+	return &opts
+}
+
+func (o *options) DocsLimitOptions() LookbackLimitOptions {
+	return o.docsLimitOpts
+}
+
+func (o *options) SetBytesReadLimitOptions(value LookbackLimitOptions) Options {
+	opts := *o
+	opts.bytesReadLimitOpts = value
+	return &opts
+}
+
+func (o *options) BytesReadLimitOptions() LookbackLimitOptions {
+	return o.bytesReadLimitOpts
+}
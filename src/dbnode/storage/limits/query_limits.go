@@ -0,0 +1,204 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package limits
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	docsLimitName      = "docs-matched"
+	bytesReadLimitName = "bytes-read"
+)
+
+var (
+	errInvalidLimit    = errors.New("limit must be non-negative")
+	errInvalidLookback = errors.New("lookback must be positive")
+)
+
+type queryLimits struct {
+	sync.Mutex
+
+	docsLimit      *lookbackLimit
+	bytesReadLimit *lookbackLimit
+
+	started bool
+	closeCh chan struct{}
+	doneWg  sync.WaitGroup
+}
+
+type lookbackLimit struct {
+	// NB: recent is accessed atomically so is kept first for alignment.
+	recent int64
+
+	name    string
+	options LookbackLimitOptions
+	metrics lookbackLimitMetrics
+}
+
+type lookbackLimitMetrics struct {
+	recentCount tally.Gauge
+	exceeded    tally.Counter
+}
+
+var (
+	_ QueryLimits   = (*queryLimits)(nil)
+	_ LookbackLimit = (*lookbackLimit)(nil)
+)
+
+// NewQueryLimits returns a new set of lookback query limits.
+func NewQueryLimits(opts Options) (QueryLimits, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	scope := opts.InstrumentOptions().MetricsScope().SubScope("query-limit")
+	return &queryLimits{
+		docsLimit: newLookbackLimit(docsLimitName,
+			opts.DocsLimitOptions(), scope),
+		bytesReadLimit: newLookbackLimit(bytesReadLimitName,
+			opts.BytesReadLimitOptions(), scope),
+		closeCh: make(chan struct{}),
+	}, nil
+}
+
+func newLookbackLimit(
+	name string,
+	opts LookbackLimitOptions,
+	scope tally.Scope,
+) *lookbackLimit {
+	scope = scope.Tagged(map[string]string{"limit": name})
+	return &lookbackLimit{
+		name:    name,
+		options: opts,
+		metrics: lookbackLimitMetrics{
+			recentCount: scope.Gauge("recent-count"),
+			exceeded:    scope.Counter("exceeded"),
+		},
+	}
+}
+
+func (q *queryLimits) DocsLimit() LookbackLimit {
+	return q.docsLimit
+}
+
+func (q *queryLimits) BytesReadLimit() LookbackLimit {
+	return q.bytesReadLimit
+}
+
+func (q *queryLimits) AnyExceeded() error {
+	if err := q.docsLimit.Exceeded(); err != nil {
+		return err
+	}
+	return q.bytesReadLimit.Exceeded()
+}
+
+func (q *queryLimits) Start() {
+	q.Lock()
+	defer q.Unlock()
+	if q.started {
+		return
+	}
+	q.started = true
+	for _, limit := range []*lookbackLimit{q.docsLimit, q.bytesReadLimit} {
+		if !limit.enabled() {
+			continue
+		}
+		q.doneWg.Add(1)
+		go limit.resetEvery(q.closeCh, &q.doneWg)
+	}
+}
+
+func (q *queryLimits) Stop() {
+	q.Lock()
+	defer q.Unlock()
+	if !q.started {
+		return
+	}
+	q.started = false
+	close(q.closeCh)
+	q.doneWg.Wait()
+	q.closeCh = make(chan struct{})
+}
+
+func (q *lookbackLimit) enabled() bool {
+	return q.options.Limit > 0
+}
+
+func (q *lookbackLimit) Inc(new int) error {
+	if !q.enabled() || new <= 0 {
+		return nil
+	}
+	recent := atomic.AddInt64(&q.recent, int64(new))
+	return q.checkLimit(recent)
+}
+
+func (q *lookbackLimit) Exceeded() error {
+	if !q.enabled() {
+		return nil
+	}
+	return q.checkLimit(atomic.LoadInt64(&q.recent))
+}
+
+func (q *lookbackLimit) checkLimit(recent int64) error {
+	if recent <= q.options.Limit {
+		return nil
+	}
+	q.metrics.exceeded.Inc(1)
+	return NewQueryLimitExceededError(q.name, recent, q.options.Limit)
+}
+
+func (q *lookbackLimit) resetEvery(closeCh <-chan struct{}, doneWg *sync.WaitGroup) {
+	defer doneWg.Done()
+
+	ticker := time.NewTicker(q.options.Lookback)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			q.reset()
+		case <-closeCh:
+			return
+		}
+	}
+}
+
+func (q *lookbackLimit) reset() {
+	recent := atomic.SwapInt64(&q.recent, 0)
+	q.metrics.recentCount.Update(float64(recent))
+}
+
+// Validate validates the lookback limit options.
+func (opts LookbackLimitOptions) Validate() error {
+	if opts.Limit < 0 {
+		return errInvalidLimit
+	}
+	if opts.Limit > 0 && opts.Lookback <= 0 {
+		return errInvalidLookback
+	}
+	return nil
+}
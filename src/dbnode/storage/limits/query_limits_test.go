@@ -0,0 +1,129 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package limits
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	xerrors "github.com/m3db/m3x/errors"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookbackLimitDisabled(t *testing.T) {
+	limits, err := NewQueryLimits(NewOptions())
+	require.NoError(t, err)
+
+	require.NoError(t, limits.DocsLimit().Inc(1<<20))
+	require.NoError(t, limits.BytesReadLimit().Inc(1<<20))
+	require.NoError(t, limits.AnyExceeded())
+}
+
+func TestLookbackLimitExceeded(t *testing.T) {
+	opts := NewOptions().SetDocsLimitOptions(LookbackLimitOptions{
+		Limit:    10,
+		Lookback: time.Hour,
+	})
+	limits, err := NewQueryLimits(opts)
+	require.NoError(t, err)
+
+	require.NoError(t, limits.DocsLimit().Inc(10))
+	require.NoError(t, limits.AnyExceeded())
+
+	err = limits.DocsLimit().Inc(1)
+	require.Error(t, err)
+	require.True(t, IsQueryLimitExceededError(err))
+
+	err = limits.AnyExceeded()
+	require.Error(t, err)
+	require.True(t, IsQueryLimitExceededError(err))
+
+	// Bytes read limit is independent and disabled.
+	require.NoError(t, limits.BytesReadLimit().Inc(100))
+}
+
+func TestLookbackLimitResets(t *testing.T) {
+	opts := NewOptions().SetBytesReadLimitOptions(LookbackLimitOptions{
+		Limit:    10,
+		Lookback: 10 * time.Millisecond,
+	})
+	limits, err := NewQueryLimits(opts)
+	require.NoError(t, err)
+
+	limits.Start()
+	defer limits.Stop()
+
+	require.Error(t, limits.BytesReadLimit().Inc(11))
+	require.True(t, waitUntil(func() bool {
+		return limits.AnyExceeded() == nil
+	}, time.Second))
+	require.NoError(t, limits.BytesReadLimit().Inc(5))
+}
+
+func TestLookbackLimitStartStopIdempotent(t *testing.T) {
+	opts := NewOptions().SetDocsLimitOptions(LookbackLimitOptions{
+		Limit:    10,
+		Lookback: time.Millisecond,
+	})
+	limits, err := NewQueryLimits(opts)
+	require.NoError(t, err)
+
+	limits.Stop()
+	limits.Start()
+	limits.Start()
+	limits.Stop()
+	limits.Stop()
+}
+
+func TestOptionsValidate(t *testing.T) {
+	require.NoError(t, NewOptions().Validate())
+	require.Error(t, NewOptions().SetMaxDocsMatchedPerQuery(-1).Validate())
+	require.Error(t, NewOptions().SetMaxSeriesFetchedPerQuery(-1).Validate())
+	require.Error(t, NewOptions().SetMaxBytesReadPerQuery(-1).Validate())
+	require.Error(t, NewOptions().SetDocsLimitOptions(LookbackLimitOptions{
+		Limit: 1,
+	}).Validate())
+
+	_, err := NewQueryLimits(NewOptions().SetBytesReadLimitOptions(
+		LookbackLimitOptions{Limit: -1, Lookback: time.Second}))
+	require.Error(t, err)
+}
+
+func TestIsQueryLimitExceededError(t *testing.T) {
+	err := NewQueryLimitExceededError("test", 2, 1)
+	require.True(t, IsQueryLimitExceededError(err))
+	require.True(t, IsQueryLimitExceededError(xerrors.NewInvalidParamsError(err)))
+	require.False(t, IsQueryLimitExceededError(errors.New("boom")))
+	require.False(t, IsQueryLimitExceededError(nil))
+}
+
+func waitUntil(fn func() bool, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if fn() {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package limits contains the per-query and per-namespace resource limits
+// enforced by the database on its index and fetch paths.
+package limits
+
+import (
+	"time"
+
+	"github.com/m3db/m3x/instrument"
+)
+
+// QueryLimits provides an interface for managing the rolling lookback
+// limits of a namespace.
+type QueryLimits interface {
+	// DocsLimit limits the number of index documents matched by queries
+	// within the lookback window.
+	DocsLimit() LookbackLimit
+
+	// BytesReadLimit limits the number of bytes read from blocks by queries
+	// within the lookback window.
+	BytesReadLimit() LookbackLimit
+
+	// AnyExceeded returns an error if any of the lookback limits have been
+	// exceeded within their current window.
+	AnyExceeded() error
+
+	// Start begins the background resetting of the lookback windows.
+	Start()
+
+	// Stop stops the background resetting of the lookback windows.
+	Stop()
+}
+
+// LookbackLimit provides an interface for a limit that is reset every
+// lookback period.
+type LookbackLimit interface {
+	// Inc increments the recent value for the limit, returning an error
+	// if the limit has been exceeded.
+	Inc(new int) error
+
+	// Exceeded returns an error if the limit has been exceeded within the
+	// current lookback window.
+	Exceeded() error
+}
+
+// LookbackLimitOptions holds options for a lookback limit.
+type LookbackLimitOptions struct {
+	// Limit past which errors will be returned, zero disables the limit.
+	Limit int64
+	// Lookback is the period over which the limit is enforced.
+	Lookback time.Duration
+}
+
+// Options is a set of limit options.
+type Options interface {
+	// Validate validates the options.
+	Validate() error
+
+	// SetInstrumentOptions sets the instrument options.
+	SetInstrumentOptions(value instrument.Options) Options
+
+	// InstrumentOptions returns the instrument options.
+	InstrumentOptions() instrument.Options
+
+	// SetMaxDocsMatchedPerQuery sets the maximum number of index documents
+	// a single query may match, zero disables the limit.
+	SetMaxDocsMatchedPerQuery(value int) Options
+
+	// MaxDocsMatchedPerQuery returns the maximum number of index documents
+	// a single query may match, zero disables the limit.
+	MaxDocsMatchedPerQuery() int
+
+	// SetMaxSeriesFetchedPerQuery sets the maximum number of series a single
+	// query may read data for, zero disables the limit.
+	SetMaxSeriesFetchedPerQuery(value int) Options
+
+	// MaxSeriesFetchedPerQuery returns the maximum number of series a single
+	// query may read data for, zero disables the limit.
+	MaxSeriesFetchedPerQuery() int
+
+	// SetMaxBytesReadPerQuery sets the maximum number of bytes a single query
+	// may read from blocks, zero disables the limit.
+	SetMaxBytesReadPerQuery(value int64) Options
+
+	// MaxBytesReadPerQuery returns the maximum number of bytes a single query
+	// may read from blocks, zero disables the limit.
+	MaxBytesReadPerQuery() int64
+
+	// SetMaxDatapointsReadPerQuery sets the maximum number of datapoints a
+	// single query may read from blocks, zero disables the limit.
+	SetMaxDatapointsReadPerQuery(value int64) Options
+
+	// MaxDatapointsReadPerQuery returns the maximum number of datapoints a
+	// single query may read from blocks, zero disables the limit.
+	MaxDatapointsReadPerQuery() int64
+
+	// SetDocsLimitOptions sets the per-namespace lookback limit options for
+	// index documents matched.
+	SetDocsLimitOptions(value LookbackLimitOptions) Options
+
+	// DocsLimitOptions returns the per-namespace lookback limit options for
+	// index documents matched.
+	DocsLimitOptions() LookbackLimitOptions
+
+	// SetBytesReadLimitOptions sets the per-namespace lookback limit options
+	// for bytes read from blocks.
+	SetBytesReadLimitOptions(value LookbackLimitOptions) Options
+
+	// BytesReadLimitOptions returns the per-namespace lookback limit options
+	// for bytes read from blocks.
+	BytesReadLimitOptions() LookbackLimitOptions
+}
@@ -31,11 +31,13 @@ import (
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/sharding"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/storage/series"
 	"github.com/m3db/m3/src/dbnode/ts"
@@ -48,6 +50,7 @@ import (
 	xsync "github.com/m3db/m3x/sync"
 	xtime "github.com/m3db/m3x/time"
 
+	"github.com/pborman/uuid"
 	"github.com/uber-go/tally"
 )
 
@@ -104,9 +107,7 @@ type dbNamespace struct {
 	blockRetriever     block.DatabaseBlockRetriever
 	namespaceReaderMgr databaseNamespaceReaderManager
 	opts               Options
-	metadata           namespace.Metadata
-	nopts              namespace.Options
-	seriesOpts         series.Options
+	metadataState      dbNamespaceMetadataState
 	nowFn              clock.NowFn
 	snapshotFilesFn    snapshotFilesFn
 	log                xlog.Logger
@@ -119,6 +120,7 @@ type dbNamespace struct {
 	increasingIndex increasingIndex
 	commitLogWriter commitLogWriter
 	reverseIndex    namespaceIndex
+	queryLimits     limits.QueryLimits
 
 	tickWorkers            xsync.WorkerPool
 	tickWorkersConcurrency int
@@ -127,6 +129,15 @@ type dbNamespace struct {
 	metrics databaseNamespaceMetrics
 }
 
+// dbNamespaceMetadataState holds the namespace metadata and the options
+// derived from it, all of which are swapped when the namespace is updated.
+type dbNamespaceMetadataState struct {
+	sync.RWMutex
+	metadata   namespace.Metadata
+	nopts      namespace.Options
+	seriesOpts series.Options
+}
+
 type databaseNamespaceStatsLastTick struct {
 	sync.RWMutex
 	activeSeries int64
@@ -151,6 +162,8 @@ type databaseNamespaceMetrics struct {
 	fetchBlocks         instrument.MethodMetrics
 	fetchBlocksMetadata instrument.MethodMetrics
 	queryIDs            instrument.MethodMetrics
+	aggregateQuery      instrument.MethodMetrics
+	deleteSeries        instrument.MethodMetrics
 	unfulfilled         tally.Counter
 	bootstrapStart      tally.Counter
 	bootstrapEnd        tally.Counter
@@ -228,6 +241,8 @@ func newDatabaseNamespaceMetrics(scope tally.Scope, samplingRate float64) databa
 		fetchBlocks:         instrument.NewMethodMetrics(scope, "fetchBlocks", samplingRate),
 		fetchBlocksMetadata: instrument.NewMethodMetrics(scope, "fetchBlocksMetadata", samplingRate),
 		queryIDs:            instrument.NewMethodMetrics(scope, "queryIDs", samplingRate),
+		aggregateQuery:      instrument.NewMethodMetrics(scope, "aggregateQuery", samplingRate),
+		deleteSeries:        instrument.NewMethodMetrics(scope, "deleteSeries", samplingRate),
 		unfulfilled:         scope.Counter("bootstrap.unfulfilled"),
 		bootstrapStart:      scope.Counter("bootstrap.start"),
 		bootstrapEnd:        scope.Counter("bootstrap.end"),
@@ -289,6 +304,20 @@ func newDatabaseNamespace(
 	iops = iops.SetLogger(logger)
 	opts = opts.SetInstrumentOptions(iops)
 
+	// Series in the namespace are encoded and decoded with the pools of the
+	// codec the namespace was created with.
+	blockOpts, err := opts.DatabaseBlockOptions().OptionsForCodec(nopts.CodecID())
+	if err != nil {
+		return nil, fmt.Errorf(
+			"unable to create namespace %v, invalid codec: %v",
+			id.String(), err)
+	}
+	opts = opts.
+		SetDatabaseBlockOptions(blockOpts).
+		SetEncoderPool(blockOpts.EncoderPool()).
+		SetReaderIteratorPool(blockOpts.ReaderIteratorPool()).
+		SetMultiReaderIteratorPool(blockOpts.MultiReaderIteratorPool())
+
 	scope := iops.MetricsScope().SubScope("database").
 		Tagged(map[string]string{
 			"namespace": id.String(),
@@ -299,6 +328,7 @@ func newDatabaseNamespace(
 	tickWorkers.Init()
 
 	seriesOpts := NewSeriesOptionsFromOptions(opts, nopts.RetentionOptions()).
+		SetColdWritesEnabled(nopts.ColdWritesEnabled()).
 		SetStats(series.NewStats(scope))
 	if err := seriesOpts.Validate(); err != nil {
 		return nil, fmt.Errorf(
@@ -306,10 +336,7 @@ func newDatabaseNamespace(
 			metadata.ID().String(), err)
 	}
 
-	var (
-		index namespaceIndex
-		err   error
-	)
+	var index namespaceIndex
 	if metadata.Options().IndexOptions().Enabled() {
 		index, err = newNamespaceIndex(metadata, opts)
 		if err != nil {
@@ -317,28 +344,40 @@ func newDatabaseNamespace(
 		}
 	}
 
+	queryLimits, err := limits.NewQueryLimits(opts.LimitsOptions().
+		SetInstrumentOptions(iops.SetMetricsScope(scope)))
+	if err != nil {
+		return nil, fmt.Errorf(
+			"unable to create namespace %v, invalid limits options: %v",
+			metadata.ID().String(), err)
+	}
+
 	n := &dbNamespace{
-		id:                     id,
-		shutdownCh:             make(chan struct{}),
-		shardSet:               shardSet,
-		blockRetriever:         blockRetriever,
-		namespaceReaderMgr:     newNamespaceReaderManager(metadata, scope, opts),
-		opts:                   opts,
-		metadata:               metadata,
-		nopts:                  nopts,
-		seriesOpts:             seriesOpts,
+		id:                 id,
+		shutdownCh:         make(chan struct{}),
+		shardSet:           shardSet,
+		blockRetriever:     blockRetriever,
+		namespaceReaderMgr: newNamespaceReaderManager(metadata, scope, opts),
+		opts:               opts,
+		metadataState: dbNamespaceMetadataState{
+			metadata:   metadata,
+			nopts:      nopts,
+			seriesOpts: seriesOpts,
+		},
 		nowFn:                  opts.ClockOptions().NowFn(),
 		snapshotFilesFn:        fs.SnapshotFiles,
 		log:                    logger,
 		increasingIndex:        increasingIndex,
 		commitLogWriter:        commitLogWriter,
 		reverseIndex:           index,
+		queryLimits:            queryLimits,
 		tickWorkers:            tickWorkers,
 		tickWorkersConcurrency: tickWorkersConcurrency,
 		metrics:                newDatabaseNamespaceMetrics(scope, iops.MetricsSamplingRate()),
 	}
 
 	n.initShards(nopts.BootstrapEnabled())
+	n.queryLimits.Start()
 	go n.reportStatusLoop()
 
 	return n, nil
@@ -365,13 +404,34 @@ func (n *dbNamespace) reportStatusLoop() {
 }
 
 func (n *dbNamespace) Options() namespace.Options {
-	return n.nopts
+	n.metadataState.RLock()
+	nopts := n.metadataState.nopts
+	n.metadataState.RUnlock()
+	return nopts
+}
+
+func (n *dbNamespace) namespaceMetadata() namespace.Metadata {
+	n.metadataState.RLock()
+	metadata := n.metadataState.metadata
+	n.metadataState.RUnlock()
+	return metadata
+}
+
+func (n *dbNamespace) seriesOptions() series.Options {
+	n.metadataState.RLock()
+	seriesOpts := n.metadataState.seriesOpts
+	n.metadataState.RUnlock()
+	return seriesOpts
 }
 
 func (n *dbNamespace) ID() ident.ID {
 	return n.id
 }
 
+func (n *dbNamespace) QueryLimits() limits.QueryLimits {
+	return n.queryLimits
+}
+
 func (n *dbNamespace) NumSeries() int64 {
 	var count int64
 	for _, shard := range n.GetOwnedShards() {
@@ -417,10 +477,10 @@ func (n *dbNamespace) AssignShardSet(shardSet sharding.ShardSet) {
 		if int(shard) < len(existing) && existing[shard] != nil {
 			n.shards[shard] = existing[shard]
 		} else {
-			bootstrapEnabled := n.nopts.BootstrapEnabled()
-			n.shards[shard] = newDatabaseShard(n.metadata, shard, n.blockRetriever,
+			bootstrapEnabled := n.Options().BootstrapEnabled()
+			n.shards[shard] = newDatabaseShard(n.namespaceMetadata(), shard, n.blockRetriever,
 				n.namespaceReaderMgr, n.increasingIndex, n.reverseIndex,
-				bootstrapEnabled, n.opts, n.seriesOpts)
+				bootstrapEnabled, n.opts, n.seriesOptions())
 			n.metrics.shards.add.Inc(1)
 		}
 	}
@@ -609,8 +669,42 @@ func (n *dbNamespace) QueryIDs(
 		return index.QueryResults{}, xerrors.NewRetryableError(errIndexNotBootstrappedToRead)
 	}
 
+	if err := n.queryLimits.AnyExceeded(); err != nil {
+		n.metrics.queryIDs.ReportError(n.nowFn().Sub(callStart))
+		return index.QueryResults{}, err
+	}
+
 	res, err := n.reverseIndex.Query(ctx, query, opts)
+	if err == nil && n.opts.LimitsOptions().DocsLimitOptions().Limit > 0 {
+		err = n.queryLimits.DocsLimit().Inc(res.Results.Size())
+	}
 	n.metrics.queryIDs.ReportSuccessOrError(err, n.nowFn().Sub(callStart))
+	if err != nil {
+		return index.QueryResults{}, err
+	}
+	return res, nil
+}
+
+func (n *dbNamespace) AggregateQuery(
+	ctx context.Context,
+	query index.Query,
+	opts index.AggregationOptions,
+) (index.AggregateQueryResult, error) {
+	callStart := n.nowFn()
+	if n.reverseIndex == nil { // only happens if indexing is enabled.
+		n.metrics.aggregateQuery.ReportError(n.nowFn().Sub(callStart))
+		return index.AggregateQueryResult{}, errNamespaceIndexingDisabled
+	}
+
+	if n.reverseIndex.BootstrapsDone() < 1 {
+		// Similar to reading shard data, return not bootstrapped
+		n.metrics.aggregateQuery.ReportError(n.nowFn().Sub(callStart))
+		return index.AggregateQueryResult{},
+			xerrors.NewRetryableError(errIndexNotBootstrappedToRead)
+	}
+
+	res, err := n.reverseIndex.AggregateQuery(ctx, query, opts)
+	n.metrics.aggregateQuery.ReportSuccessOrError(err, n.nowFn().Sub(callStart))
 	return res, err
 }
 
@@ -620,11 +714,18 @@ func (n *dbNamespace) ReadEncoded(
 	start, end time.Time,
 ) ([][]xio.BlockReader, error) {
 	callStart := n.nowFn()
+	if err := n.queryLimits.AnyExceeded(); err != nil {
+		n.metrics.read.ReportError(n.nowFn().Sub(callStart))
+		return nil, err
+	}
 	shard, err := n.readableShardFor(id)
 	if err != nil {
 		n.metrics.read.ReportError(n.nowFn().Sub(callStart))
 		return nil, err
 	}
+	// NB: Bytes read are accounted against the namespace limits by the
+	// caller as the blocks are consumed, the blocks may still be being
+	// retrieved from disk at this point.
 	res, err := shard.ReadEncoded(ctx, id, start, end)
 	n.metrics.read.ReportSuccessOrError(err, n.nowFn().Sub(callStart))
 	return res, err
@@ -695,7 +796,7 @@ func (n *dbNamespace) Bootstrap(start time.Time, process bootstrap.Process) erro
 		n.metrics.bootstrapEnd.Inc(1)
 	}()
 
-	if !n.nopts.BootstrapEnabled() {
+	if !n.Options().BootstrapEnabled() {
 		success = true
 		n.metrics.bootstrap.ReportSuccess(n.nowFn().Sub(callStart))
 		return nil
@@ -721,7 +822,7 @@ func (n *dbNamespace) Bootstrap(start time.Time, process bootstrap.Process) erro
 		shardIDs[i] = shard.ID()
 	}
 
-	bootstrapResult, err := process.Run(start, n.metadata, shardIDs)
+	bootstrapResult, err := process.Run(start, n.namespaceMetadata(), shardIDs)
 	if err != nil {
 		n.log.Errorf("bootstrap for namespace %s aborted due to error: %v",
 			n.id.String(), err)
@@ -812,13 +913,13 @@ func (n *dbNamespace) Flush(
 	}
 	n.RUnlock()
 
-	if !n.nopts.FlushEnabled() {
+	if !n.Options().FlushEnabled() {
 		n.metrics.flush.ReportSuccess(n.nowFn().Sub(callStart))
 		return nil
 	}
 
 	// check if blockStart is aligned with the namespace's retention options
-	bs := n.nopts.RetentionOptions().BlockSize()
+	bs := n.Options().RetentionOptions().BlockSize()
 	if t := blockStart.Truncate(bs); !blockStart.Equal(t) {
 		return fmt.Errorf("failed to flush at time %v, not aligned to blockSize", blockStart.String())
 	}
@@ -874,7 +975,7 @@ func (n *dbNamespace) FlushIndex(
 	}
 	n.RUnlock()
 
-	if !n.nopts.FlushEnabled() || !n.nopts.IndexOptions().Enabled() {
+	if !n.Options().FlushEnabled() || !n.Options().IndexOptions().Enabled() {
 		n.metrics.flush.ReportSuccess(n.nowFn().Sub(callStart))
 		return nil
 	}
@@ -887,6 +988,7 @@ func (n *dbNamespace) FlushIndex(
 func (n *dbNamespace) Snapshot(
 	blockStart,
 	snapshotTime time.Time,
+	snapshotID uuid.UUID,
 	shardBootstrapStatesAtTickStart ShardBootstrapStates,
 	flush persist.DataFlush) error {
 	// NB(rartoul): This value can be used for emitting metrics, but should not be used
@@ -901,7 +1003,7 @@ func (n *dbNamespace) Snapshot(
 	}
 	n.RUnlock()
 
-	if !n.nopts.SnapshotEnabled() {
+	if !n.Options().SnapshotEnabled() {
 		n.metrics.snapshot.ReportSuccess(n.nowFn().Sub(callStart))
 		return nil
 	}
@@ -932,7 +1034,7 @@ func (n *dbNamespace) Snapshot(
 			continue
 		}
 
-		err := shard.Snapshot(blockStart, snapshotTime, flush)
+		err := shard.Snapshot(blockStart, snapshotTime, snapshotID, flush)
 		if err != nil {
 			detailedErr := fmt.Errorf("shard %d failed to snapshot: %v", shard.ID(), err)
 			multiErr = multiErr.Add(detailedErr)
@@ -959,7 +1061,7 @@ func (n *dbNamespace) NeedsFlush(
 func (n *dbNamespace) IsCapturedBySnapshot(
 	alignedInclusiveStart, alignedInclusiveEnd, capturedUpTo time.Time) (bool, error) {
 	var (
-		blockSize      = n.nopts.RetentionOptions().BlockSize()
+		blockSize      = n.Options().RetentionOptions().BlockSize()
 		blockStarts    = timesInRange(alignedInclusiveStart, alignedInclusiveEnd, blockSize)
 		filePathPrefix = n.opts.CommitLogOptions().FilesystemOptions().FilePathPrefix()
 	)
@@ -1005,7 +1107,7 @@ func (n *dbNamespace) IsCapturedBySnapshot(
 
 func (n *dbNamespace) needsFlushWithLock(alignedInclusiveStart time.Time, alignedInclusiveEnd time.Time) bool {
 	var (
-		blockSize   = n.nopts.RetentionOptions().BlockSize()
+		blockSize   = n.Options().RetentionOptions().BlockSize()
 		blockStarts = timesInRange(alignedInclusiveStart, alignedInclusiveEnd, blockSize)
 	)
 
@@ -1048,11 +1150,172 @@ func (n *dbNamespace) Truncate() (int64, error) {
 	return totalNumSeries, nil
 }
 
+func (n *dbNamespace) DeleteSeries(
+	ctx context.Context,
+	ids []ident.ID,
+) ([]ident.ID, error) {
+	callStart := n.nowFn()
+
+	n.RLock()
+	byShard := make(map[databaseShard][]ident.ID)
+	for _, id := range ids {
+		shardID := n.shardSet.Lookup(id)
+		if int(shardID) >= len(n.shards) || n.shards[shardID] == nil {
+			// Not responsible for this shard, another replica set will
+			// delete the series.
+			continue
+		}
+		shard := n.shards[shardID]
+		byShard[shard] = append(byShard[shard], id)
+	}
+	n.RUnlock()
+
+	var (
+		deleted  = make([]ident.ID, 0, len(ids))
+		multiErr = xerrors.NewMultiError()
+	)
+	for shard, shardIDs := range byShard {
+		if err := shard.DeleteSeries(shardIDs); err != nil {
+			multiErr = multiErr.Add(err)
+			continue
+		}
+		deleted = append(deleted, shardIDs...)
+	}
+
+	if n.reverseIndex != nil && len(deleted) > 0 {
+		if err := n.reverseIndex.Delete(deleted); err != nil {
+			multiErr = multiErr.Add(err)
+		}
+	}
+
+	err := multiErr.FinalError()
+	n.metrics.deleteSeries.ReportSuccessOrError(err, n.nowFn().Sub(callStart))
+	return deleted, err
+}
+
+func (n *dbNamespace) DeleteTagged(
+	ctx context.Context,
+	query index.Query,
+) ([]ident.ID, error) {
+	var (
+		now       = n.nowFn()
+		ropts     = n.Options().RetentionOptions()
+		queryOpts = index.QueryOptions{
+			StartInclusive: retention.FlushTimeStart(ropts, now),
+			EndExclusive:   now.Add(ropts.BufferFuture()).Add(ropts.BlockSize()),
+		}
+	)
+	res, err := n.QueryIDs(ctx, query, queryOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]ident.ID, 0, res.Results.Size())
+	for _, entry := range res.Results.Map().Iter() {
+		// Copy the IDs as the results are returned to the pool
+		// once the context is closed.
+		ids = append(ids, ident.StringID(entry.Key().String()))
+	}
+	return n.DeleteSeries(ctx, ids)
+}
+
+func (n *dbNamespace) FlushRepairs(flush persist.DataFlush) error {
+	n.RLock()
+	if n.bootstrapState != Bootstrapped {
+		n.RUnlock()
+		return errNamespaceNotBootstrapped
+	}
+	n.RUnlock()
+
+	if !n.Options().FlushEnabled() {
+		return nil
+	}
+
+	multiErr := xerrors.NewMultiError()
+	for _, shard := range n.GetOwnedShards() {
+		if err := shard.FlushRepairs(flush); err != nil {
+			detailedErr := fmt.Errorf("shard %d failed to flush repairs: %v",
+				shard.ID(), err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+	}
+	return multiErr.FinalError()
+}
+
+func (n *dbNamespace) ColdFlush(flush persist.DataFlush) error {
+	n.RLock()
+	if n.bootstrapState != Bootstrapped {
+		n.RUnlock()
+		return errNamespaceNotBootstrapped
+	}
+	n.RUnlock()
+
+	if !n.Options().FlushEnabled() || !n.Options().ColdWritesEnabled() {
+		return nil
+	}
+
+	multiErr := xerrors.NewMultiError()
+	for _, shard := range n.GetOwnedShards() {
+		if err := shard.ColdFlush(flush); err != nil {
+			detailedErr := fmt.Errorf("shard %d failed to cold flush: %v",
+				shard.ID(), err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+	}
+	return multiErr.FinalError()
+}
+
+func (n *dbNamespace) FlushDeletes(flush persist.DataFlush) error {
+	n.RLock()
+	if n.bootstrapState != Bootstrapped {
+		n.RUnlock()
+		return errNamespaceNotBootstrapped
+	}
+	n.RUnlock()
+
+	if !n.Options().FlushEnabled() {
+		return nil
+	}
+
+	multiErr := xerrors.NewMultiError()
+	for _, shard := range n.GetOwnedShards() {
+		if err := shard.FlushDeletes(flush); err != nil {
+			detailedErr := fmt.Errorf("shard %d failed to flush deletes: %v",
+				shard.ID(), err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+	}
+	return multiErr.FinalError()
+}
+
+func (n *dbNamespace) CompactFlushedBlocks(flush persist.DataFlush) error {
+	n.RLock()
+	if n.bootstrapState != Bootstrapped {
+		n.RUnlock()
+		return errNamespaceNotBootstrapped
+	}
+	n.RUnlock()
+
+	if !n.Options().FlushEnabled() {
+		return nil
+	}
+
+	multiErr := xerrors.NewMultiError()
+	for _, shard := range n.GetOwnedShards() {
+		if err := shard.CompactFlushedBlocks(flush); err != nil {
+			detailedErr := fmt.Errorf("shard %d failed to compact flushed blocks: %v",
+				shard.ID(), err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+	}
+	return multiErr.FinalError()
+}
+
 func (n *dbNamespace) Repair(
 	repairer databaseShardRepairer,
 	tr xtime.Range,
 ) error {
-	if !n.nopts.RepairEnabled() {
+	if !n.Options().RepairEnabled() {
 		return nil
 	}
 
@@ -1142,7 +1405,7 @@ func (n *dbNamespace) GetOwnedShards() []databaseShard {
 func (n *dbNamespace) GetIndex() (namespaceIndex, error) {
 	n.RLock()
 	defer n.RUnlock()
-	if !n.metadata.Options().IndexOptions().Enabled() {
+	if !n.namespaceMetadata().Options().IndexOptions().Enabled() {
 		return nil, errNamespaceIndexingDisabled
 	}
 	return n.reverseIndex, nil
@@ -1202,9 +1465,9 @@ func (n *dbNamespace) initShards(needBootstrap bool) {
 	shards := n.shardSet.AllIDs()
 	dbShards := make([]databaseShard, n.shardSet.Max()+1)
 	for _, shard := range shards {
-		dbShards[shard] = newDatabaseShard(n.metadata, shard, n.blockRetriever,
+		dbShards[shard] = newDatabaseShard(n.namespaceMetadata(), shard, n.blockRetriever,
 			n.namespaceReaderMgr, n.increasingIndex, n.reverseIndex,
-			needBootstrap, n.opts, n.seriesOpts)
+			needBootstrap, n.opts, n.seriesOptions())
 	}
 	n.shards = dbShards
 	n.Unlock()
@@ -1224,6 +1487,7 @@ func (n *dbNamespace) Close() error {
 	n.namespaceReaderMgr.close()
 	n.closeShards(shards, true)
 	close(n.shutdownCh)
+	n.queryLimits.Stop()
 	if n.reverseIndex != nil {
 		return n.reverseIndex.Close()
 	}
@@ -1242,3 +1506,36 @@ func (n *dbNamespace) BootstrapState() ShardBootstrapStates {
 	n.RUnlock()
 	return shardStates
 }
+
+func (n *dbNamespace) UpdateMetadata(metadata namespace.Metadata) error {
+	n.metadataState.Lock()
+	existing := n.metadataState.metadata
+	if err := namespace.ValidateUpdate(existing, metadata); err != nil {
+		n.metadataState.Unlock()
+		return err
+	}
+	nopts := metadata.Options()
+	seriesOpts := n.metadataState.seriesOpts.
+		SetRetentionOptions(nopts.RetentionOptions())
+	if err := seriesOpts.Validate(); err != nil {
+		n.metadataState.Unlock()
+		return fmt.Errorf("invalid series options: %v", err)
+	}
+	n.metadataState.metadata = metadata
+	n.metadataState.nopts = nopts
+	n.metadataState.seriesOpts = seriesOpts
+	n.metadataState.Unlock()
+
+	// Shards are retrieved after the metadata is swapped so that any shard
+	// assigned concurrently is either created with or updated to it.
+	for _, shard := range n.GetOwnedShards() {
+		shard.UpdateNamespaceMetadata(metadata, seriesOpts)
+	}
+
+	if n.reverseIndex != nil {
+		n.reverseIndex.UpdateRetentionOptions(nopts.RetentionOptions())
+		n.reverseIndex.UpdateIndexOptions(nopts.IndexOptions())
+	}
+
+	return nil
+}
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3x/ident"
 )
@@ -48,14 +49,17 @@ func (m *MapConfiguration) Map() (Map, error) {
 
 // MetadataConfiguration is the configuration for a single namespace
 type MetadataConfiguration struct {
-	ID                string                  `yaml:"id" validate:"nonzero"`
-	BootstrapEnabled  *bool                   `yaml:"bootstrapEnabled"`
-	FlushEnabled      *bool                   `yaml:"flushEnabled"`
-	WritesToCommitLog *bool                   `yaml:"writesToCommitLog"`
-	CleanupEnabled    *bool                   `yaml:"cleanupEnabled"`
-	RepairEnabled     *bool                   `yaml:"repairEnabled"`
-	Retention         retention.Configuration `yaml:"retention" validate:"nonzero"`
-	Index             IndexConfiguration      `yaml:"index"`
+	ID                string                         `yaml:"id" validate:"nonzero"`
+	BootstrapEnabled  *bool                          `yaml:"bootstrapEnabled"`
+	FlushEnabled      *bool                          `yaml:"flushEnabled"`
+	WritesToCommitLog *bool                          `yaml:"writesToCommitLog"`
+	CleanupEnabled    *bool                          `yaml:"cleanupEnabled"`
+	RepairEnabled     *bool                          `yaml:"repairEnabled"`
+	ColdWritesEnabled *bool                          `yaml:"coldWritesEnabled"`
+	Codec             *codec.ID                      `yaml:"codec"`
+	Retention         retention.Configuration        `yaml:"retention" validate:"nonzero"`
+	Index             IndexConfiguration             `yaml:"index"`
+	SeriesRetention   []SeriesRetentionConfiguration `yaml:"seriesRetention"`
 }
 
 // Metadata returns a Metadata corresponding to the receiver struct
@@ -80,9 +84,39 @@ func (mc *MetadataConfiguration) Metadata() (Metadata, error) {
 	if v := mc.RepairEnabled; v != nil {
 		opts = opts.SetRepairEnabled(*v)
 	}
+	if v := mc.ColdWritesEnabled; v != nil {
+		opts = opts.SetColdWritesEnabled(*v)
+	}
+	if v := mc.Codec; v != nil {
+		opts = opts.SetCodecID(*v)
+	}
+	if len(mc.SeriesRetention) > 0 {
+		rules := make(SeriesRetentionRules, 0, len(mc.SeriesRetention))
+		for _, rule := range mc.SeriesRetention {
+			rules = append(rules, rule.Rule())
+		}
+		opts = opts.SetSeriesRetentionRules(rules)
+	}
 	return NewMetadata(ident.StringID(mc.ID), opts)
 }
 
+// SeriesRetentionConfiguration is the configuration of a rule giving series
+// with a matching tag a shorter retention than the namespace retention.
+type SeriesRetentionConfiguration struct {
+	TagName         string        `yaml:"tagName" validate:"nonzero"`
+	TagValue        string        `yaml:"tagValue" validate:"nonzero"`
+	RetentionPeriod time.Duration `yaml:"retentionPeriod" validate:"nonzero"`
+}
+
+// Rule returns the SeriesRetentionRule corresponding to the receiver struct.
+func (c *SeriesRetentionConfiguration) Rule() SeriesRetentionRule {
+	return SeriesRetentionRule{
+		TagName:         c.TagName,
+		TagValue:        c.TagValue,
+		RetentionPeriod: c.RetentionPeriod,
+	}
+}
+
 // IndexConfiguration controls the knobs to tweak indexing configuration.
 type IndexConfiguration struct {
 	Enabled   bool          `yaml:"enabled" validate:"nonzero"`
@@ -24,6 +24,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	nsproto "github.com/m3db/m3/src/dbnode/generated/proto/namespace"
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3x/ident"
@@ -74,6 +75,12 @@ func ToIndexOptions(
 
 	iopts = iopts.SetEnabled(io.Enabled).
 		SetBlockSize(fromNanos(io.BlockSizeNanos))
+	if io.PreviousBlockSizeNanos != 0 {
+		iopts = iopts.SetBlockSizeChange(IndexBlockSizeChange{
+			PreviousBlockSize: fromNanos(io.PreviousBlockSizeNanos),
+			Start:             time.Unix(0, io.BlockSizeChangeStartNanos),
+		})
+	}
 
 	return iopts, nil
 }
@@ -97,15 +104,35 @@ func ToMetadata(
 		return nil, err
 	}
 
+	seriesRetention := make(SeriesRetentionRules, 0, len(opts.SeriesRetentionRules))
+	for _, rule := range opts.SeriesRetentionRules {
+		seriesRetention = append(seriesRetention, SeriesRetentionRule{
+			TagName:         rule.TagName,
+			TagValue:        rule.TagValue,
+			RetentionPeriod: fromNanos(rule.RetentionPeriodNanos),
+		})
+	}
+
+	codecID := codec.DefaultID
+	if opts.Codec != "" {
+		codecID, err = codec.ParseID(opts.Codec)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	mopts := NewOptions().
 		SetBootstrapEnabled(opts.BootstrapEnabled).
 		SetFlushEnabled(opts.FlushEnabled).
 		SetCleanupEnabled(opts.CleanupEnabled).
 		SetRepairEnabled(opts.RepairEnabled).
+		SetColdWritesEnabled(opts.ColdWritesEnabled).
 		SetWritesToCommitLog(opts.WritesToCommitLog).
 		SetSnapshotEnabled(opts.SnapshotEnabled).
+		SetCodecID(codecID).
 		SetRetentionOptions(ropts).
-		SetIndexOptions(iopts)
+		SetIndexOptions(iopts).
+		SetSeriesRetentionRules(seriesRetention)
 
 	return NewMetadata(ident.StringID(id), mopts)
 }
@@ -141,13 +168,24 @@ func OptionsToProto(opts Options) *nsproto.NamespaceOptions {
 	ropts := opts.RetentionOptions()
 	iopts := opts.IndexOptions()
 
+	var seriesRetention []*nsproto.SeriesRetentionRule
+	for _, rule := range opts.SeriesRetentionRules() {
+		seriesRetention = append(seriesRetention, &nsproto.SeriesRetentionRule{
+			TagName:              rule.TagName,
+			TagValue:             rule.TagValue,
+			RetentionPeriodNanos: rule.RetentionPeriod.Nanoseconds(),
+		})
+	}
+
 	return &nsproto.NamespaceOptions{
 		BootstrapEnabled:  opts.BootstrapEnabled(),
 		FlushEnabled:      opts.FlushEnabled(),
 		CleanupEnabled:    opts.CleanupEnabled(),
 		SnapshotEnabled:   opts.SnapshotEnabled(),
 		RepairEnabled:     opts.RepairEnabled(),
+		ColdWritesEnabled: opts.ColdWritesEnabled(),
 		WritesToCommitLog: opts.WritesToCommitLog(),
+		Codec:             opts.CodecID().String(),
 		RetentionOptions: &nsproto.RetentionOptions{
 			BlockSizeNanos:                           ropts.BlockSize().Nanoseconds(),
 			RetentionPeriodNanos:                     ropts.RetentionPeriod().Nanoseconds(),
@@ -156,9 +194,19 @@ func OptionsToProto(opts Options) *nsproto.NamespaceOptions {
 			BlockDataExpiry:                          ropts.BlockDataExpiry(),
 			BlockDataExpiryAfterNotAccessPeriodNanos: ropts.BlockDataExpiryAfterNotAccessedPeriod().Nanoseconds(),
 		},
-		IndexOptions: &nsproto.IndexOptions{
-			Enabled:        iopts.Enabled(),
-			BlockSizeNanos: iopts.BlockSize().Nanoseconds(),
-		},
+		IndexOptions:         indexOptionsToProto(iopts),
+		SeriesRetentionRules: seriesRetention,
+	}
+}
+
+func indexOptionsToProto(iopts IndexOptions) *nsproto.IndexOptions {
+	pb := &nsproto.IndexOptions{
+		Enabled:        iopts.Enabled(),
+		BlockSizeNanos: iopts.BlockSize().Nanoseconds(),
+	}
+	if change := iopts.BlockSizeChange(); !change.IsZero() {
+		pb.PreviousBlockSizeNanos = change.PreviousBlockSize.Nanoseconds()
+		pb.BlockSizeChangeStartNanos = change.Start.UnixNano()
 	}
+	return pb
 }
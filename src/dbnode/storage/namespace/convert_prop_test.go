@@ -27,6 +27,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3x/ident"
@@ -82,13 +83,15 @@ func genMap() gopter.Gen {
 func genMetadata() gopter.Gen {
 	return gopter.CombineGens(
 		gen.Identifier(),
-		gen.SliceOfN(7, gen.Bool()),
+		gen.SliceOfN(8, gen.Bool()),
 		genRetention(),
+		gen.IntRange(0, len(codec.ValidIDs())-1),
 	).Map(func(values []interface{}) namespace.Metadata {
 		var (
 			id        = values[0].(string)
 			bools     = values[1].([]bool)
 			retention = values[2].(retention.Options)
+			codecID   = codec.ValidIDs()[values[3].(int)]
 		)
 		md, err := namespace.NewMetadata(ident.StringID(id), namespace.NewOptions().
 			SetBootstrapEnabled(bools[0]).
@@ -97,6 +100,8 @@ func genMetadata() gopter.Gen {
 			SetRepairEnabled(bools[3]).
 			SetWritesToCommitLog(bools[4]).
 			SetSnapshotEnabled(bools[5]).
+			SetColdWritesEnabled(bools[7]).
+			SetCodecID(codecID).
 			SetRetentionOptions(retention).
 			SetIndexOptions(namespace.NewIndexOptions().
 				SetEnabled(bools[6]).
@@ -24,6 +24,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	nsproto "github.com/m3db/m3/src/dbnode/generated/proto/namespace"
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
@@ -67,9 +68,18 @@ var (
 			WritesToCommitLog: true,
 			CleanupEnabled:    true,
 			RepairEnabled:     true,
+			ColdWritesEnabled: true,
 			RetentionOptions:  &validRetentionOpts,
 			IndexOptions:      &validIndexOpts,
 		},
+		nsproto.NamespaceOptions{
+			BootstrapEnabled:  true,
+			FlushEnabled:      true,
+			WritesToCommitLog: true,
+			CleanupEnabled:    true,
+			Codec:             "deltaofdelta",
+			RetentionOptions:  &validRetentionOpts,
+		},
 	}
 
 	invalidRetentionOpts = []nsproto.RetentionOptions{
@@ -137,6 +147,13 @@ func TestToNamespaceInvalid(t *testing.T) {
 			require.Error(t, err)
 		}
 	}
+
+	for _, nsopts := range validNamespaceOpts {
+		opts := nsopts
+		opts.Codec = "gzip"
+		_, err := namespace.ToMetadata("abc", &opts)
+		require.Error(t, err)
+	}
 }
 
 func TestFromProto(t *testing.T) {
@@ -217,6 +234,53 @@ func TestFromProtoSnapshotEnabled(t *testing.T) {
 	assert.Equal(t, !namespace.NewOptions().SnapshotEnabled(), md.Options().SnapshotEnabled())
 }
 
+func TestSeriesRetentionRulesProtoRoundTrip(t *testing.T) {
+	rules := namespace.SeriesRetentionRules{
+		{TagName: "retention_days", TagValue: "1", RetentionPeriod: 24 * time.Hour},
+		{TagName: "debug", TagValue: "true", RetentionPeriod: 6 * time.Hour},
+	}
+	md, err := namespace.NewMetadata(ident.StringID("ns1"),
+		namespace.NewOptions().SetSeriesRetentionRules(rules))
+	require.NoError(t, err)
+
+	opts := namespace.OptionsToProto(md.Options())
+	require.Len(t, opts.SeriesRetentionRules, 2)
+	assert.Equal(t, "retention_days", opts.SeriesRetentionRules[0].TagName)
+	assert.Equal(t, int64(24*time.Hour), opts.SeriesRetentionRules[0].RetentionPeriodNanos)
+
+	// Rules survive being marshalled to the registry.
+	data, err := opts.Marshal()
+	require.NoError(t, err)
+	var unmarshalled nsproto.NamespaceOptions
+	require.NoError(t, unmarshalled.Unmarshal(data))
+
+	observed, err := namespace.ToMetadata("ns1", &unmarshalled)
+	require.NoError(t, err)
+	assert.True(t, rules.Equal(observed.Options().SeriesRetentionRules()))
+}
+
+func TestIndexBlockSizeChangeProtoRoundTrip(t *testing.T) {
+	change := namespace.NewIndexBlockSizeChange(2*time.Hour, 4*time.Hour,
+		time.Unix(0, 0).Add(100*time.Hour))
+	iopts := namespace.NewIndexOptions().
+		SetEnabled(true).
+		SetBlockSize(4 * time.Hour).
+		SetBlockSizeChange(change)
+	md, err := namespace.NewMetadata(ident.StringID("ns1"),
+		namespace.NewOptions().SetIndexOptions(iopts))
+	require.NoError(t, err)
+
+	// The change survives being marshalled to the registry.
+	data, err := namespace.OptionsToProto(md.Options()).Marshal()
+	require.NoError(t, err)
+	var unmarshalled nsproto.NamespaceOptions
+	require.NoError(t, unmarshalled.Unmarshal(data))
+
+	observed, err := namespace.ToMetadata("ns1", &unmarshalled)
+	require.NoError(t, err)
+	assert.True(t, iopts.Equal(observed.Options().IndexOptions()))
+}
+
 func assertEqualMetadata(t *testing.T, name string, expected nsproto.NamespaceOptions, observed namespace.Metadata) {
 	require.Equal(t, name, observed.ID().String())
 	opts := observed.Options()
@@ -226,6 +290,12 @@ func assertEqualMetadata(t *testing.T, name string, expected nsproto.NamespaceOp
 	require.Equal(t, expected.WritesToCommitLog, opts.WritesToCommitLog())
 	require.Equal(t, expected.CleanupEnabled, opts.CleanupEnabled())
 	require.Equal(t, expected.RepairEnabled, opts.RepairEnabled())
+	require.Equal(t, expected.ColdWritesEnabled, opts.ColdWritesEnabled())
+	if expected.Codec == "" {
+		require.Equal(t, codec.DefaultID, opts.CodecID())
+	} else {
+		require.Equal(t, expected.Codec, opts.CodecID().String())
+	}
 
 	assertEqualRetentions(t, *expected.RetentionOptions, opts.RetentionOptions())
 }
@@ -204,6 +204,13 @@ func (r *dynamicRegistry) run() {
 			continue
 		}
 
+		if err := ValidateMapUpdate(r.maps(), m); err != nil {
+			r.metrics.numInvalidUpdates.Inc(1)
+			r.logger.Warnf("dynamic namespace registry received unsafe update: %v, skipping",
+				err)
+			continue
+		}
+
 		r.logger.Infof("dynamic namespace registry updated to version: %d", val.Version())
 		r.Lock()
 		r.currentValue = val
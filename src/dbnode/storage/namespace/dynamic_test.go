@@ -28,6 +28,7 @@ import (
 	"github.com/m3db/m3/src/cluster/client"
 	"github.com/m3db/m3/src/cluster/kv"
 	nsproto "github.com/m3db/m3/src/dbnode/generated/proto/namespace"
+	"github.com/m3db/m3x/ident"
 	"github.com/m3db/m3x/instrument"
 	xtime "github.com/m3db/m3x/time"
 
@@ -313,6 +314,97 @@ func TestInitializerUpdateSuccess(t *testing.T) {
 	require.NoError(t, reg.Close())
 }
 
+func TestInitializerUpdateRetentionSuccess(t *testing.T) {
+	defer leaktest.CheckTimeout(t, time.Second)()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	initValue := singleTestValue()
+	w := newTestWatchable(t, initValue)
+	defer w.Close()
+
+	opts := newTestOpts(t, ctrl, w)
+	init := NewDynamicInitializer(opts)
+
+	reg, err := init.Init()
+	require.NoError(t, err)
+
+	rmap, err := reg.Watch()
+	require.NoError(t, err)
+	require.Equal(t, int64(0), numInvalidUpdates(opts))
+
+	// update retention period and buffers of the existing namespace
+	updated := *initValue.Namespaces["testns1"]
+	updatedRetention := *updated.RetentionOptions
+	updatedRetention.RetentionPeriodNanos = toNanosInt64(time.Hour * 96)
+	updatedRetention.BufferPastNanos = toNanosInt64(time.Minute * 30)
+	updatedRetention.BufferFutureNanos = toNanosInt64(time.Minute * 5)
+	updated.RetentionOptions = &updatedRetention
+	require.NoError(t, w.Update(&testValue{
+		version: 2,
+		Registry: nsproto.Registry{
+			Namespaces: map[string]*nsproto.NamespaceOptions{
+				"testns1": &updated,
+			},
+		},
+	}))
+
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, int64(0), numInvalidUpdates(opts))
+
+	md, err := rmap.Get().Get(ident.StringID("testns1"))
+	require.NoError(t, err)
+	ropts := md.Options().RetentionOptions()
+	require.Equal(t, time.Hour*96, ropts.RetentionPeriod())
+	require.Equal(t, time.Minute*30, ropts.BufferPast())
+	require.Equal(t, time.Minute*5, ropts.BufferFuture())
+	require.NoError(t, reg.Close())
+}
+
+func TestInitializerUpdateWithBlockSizeChange(t *testing.T) {
+	defer leaktest.CheckTimeout(t, time.Second)()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	initValue := singleTestValue()
+	w := newTestWatchable(t, initValue)
+	defer w.Close()
+
+	opts := newTestOpts(t, ctrl, w)
+	init := NewDynamicInitializer(opts)
+
+	reg, err := init.Init()
+	require.NoError(t, err)
+
+	rmap, err := reg.Watch()
+	require.NoError(t, err)
+	require.Equal(t, int64(0), numInvalidUpdates(opts))
+
+	// update block size of the existing namespace, which is not allowed
+	updated := *initValue.Namespaces["testns1"]
+	updatedRetention := *updated.RetentionOptions
+	updatedRetention.BlockSizeNanos = toNanosInt64(time.Hour)
+	updated.RetentionOptions = &updatedRetention
+	require.NoError(t, w.Update(&testValue{
+		version: 2,
+		Registry: nsproto.Registry{
+			Namespaces: map[string]*nsproto.NamespaceOptions{
+				"testns1": &updated,
+			},
+		},
+	}))
+
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, int64(1), numInvalidUpdates(opts))
+
+	md, err := rmap.Get().Get(ident.StringID("testns1"))
+	require.NoError(t, err)
+	require.Equal(t, time.Hour*2, md.Options().RetentionOptions().BlockSize())
+	require.NoError(t, reg.Close())
+}
+
 func singleTestValue() *testValue {
 	return &testValue{
 		version: 1,
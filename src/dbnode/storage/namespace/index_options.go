@@ -32,9 +32,81 @@ var (
 	defaultIndexBlockSize = 2 * time.Hour
 )
 
+// IndexBlockSizeChange is a change of the index block size of a namespace,
+// index blocks starting before the change keep the previous block size so
+// that existing index blocks stay aligned.
+type IndexBlockSizeChange struct {
+	// PreviousBlockSize is the block size of index blocks starting before
+	// the change.
+	PreviousBlockSize time.Duration
+
+	// Start is the start of the first index block with the new block size,
+	// it is a block start for both the previous and the new block size.
+	Start time.Time
+}
+
+// NewIndexBlockSizeChange returns the change from the previous index block
+// size to the new one starting at the first block start both block sizes
+// share after the given time.
+func NewIndexBlockSizeChange(
+	previousBlockSize time.Duration,
+	blockSize time.Duration,
+	after time.Time,
+) IndexBlockSizeChange {
+	shared := lcm(previousBlockSize, blockSize)
+	return IndexBlockSizeChange{
+		PreviousBlockSize: previousBlockSize,
+		Start:             after.Truncate(shared).Add(shared),
+	}
+}
+
+// IsZero returns whether there is no change of the index block size.
+func (c IndexBlockSizeChange) IsZero() bool {
+	return c.PreviousBlockSize == 0 && c.Start.IsZero()
+}
+
+// Equal returns whether the change is equal to another.
+func (c IndexBlockSizeChange) Equal(value IndexBlockSizeChange) bool {
+	return c.PreviousBlockSize == value.PreviousBlockSize &&
+		c.Start.Equal(value.Start)
+}
+
+// BlockStart returns the start of the index block containing the given time
+// for the block size after the change.
+func (c IndexBlockSizeChange) BlockStart(
+	t time.Time,
+	blockSize time.Duration,
+) time.Time {
+	if !c.IsZero() && t.Before(c.Start) {
+		return t.Truncate(c.PreviousBlockSize)
+	}
+	return t.Truncate(blockSize)
+}
+
+// BlockSize returns the size of the index block with the given block start
+// for the block size after the change.
+func (c IndexBlockSizeChange) BlockSize(
+	blockStart time.Time,
+	blockSize time.Duration,
+) time.Duration {
+	if !c.IsZero() && blockStart.Before(c.Start) {
+		return c.PreviousBlockSize
+	}
+	return blockSize
+}
+
+func lcm(a, b time.Duration) time.Duration {
+	x, y := a, b
+	for y != 0 {
+		x, y = y, x%y
+	}
+	return a / x * b
+}
+
 type indexOpts struct {
-	enabled   bool
-	blockSize time.Duration
+	enabled         bool
+	blockSize       time.Duration
+	blockSizeChange IndexBlockSizeChange
 }
 
 // NewIndexOptions returns a new IndexOptions.
@@ -47,7 +119,8 @@ func NewIndexOptions() IndexOptions {
 
 func (i *indexOpts) Equal(value IndexOptions) bool {
 	return i.Enabled() == value.Enabled() &&
-		i.BlockSize() == value.BlockSize()
+		i.BlockSize() == value.BlockSize() &&
+		i.BlockSizeChange().Equal(value.BlockSizeChange())
 }
 
 func (i *indexOpts) SetEnabled(value bool) IndexOptions {
@@ -69,3 +142,21 @@ func (i *indexOpts) SetBlockSize(value time.Duration) IndexOptions {
 func (i *indexOpts) BlockSize() time.Duration {
 	return i.blockSize
 }
+
+func (i *indexOpts) SetBlockSizeChange(value IndexBlockSizeChange) IndexOptions {
+	io := *i
+	io.blockSizeChange = value
+	return &io
+}
+
+func (i *indexOpts) BlockSizeChange() IndexBlockSizeChange {
+	return i.blockSizeChange
+}
+
+func (i *indexOpts) BlockStartForTime(t time.Time) time.Time {
+	return i.blockSizeChange.BlockStart(t, i.blockSize)
+}
+
+func (i *indexOpts) BlockSizeForBlockStart(blockStart time.Time) time.Duration {
+	return i.blockSizeChange.BlockSize(blockStart, i.blockSize)
+}
@@ -45,3 +45,29 @@ func TestIndexOptionsBlockSize(t *testing.T) {
 	opts := NewIndexOptions()
 	require.Equal(t, time.Hour, opts.SetBlockSize(time.Hour).BlockSize())
 }
+
+func TestIndexOptionsBlockSizeChange(t *testing.T) {
+	var (
+		blockSize = 6 * time.Hour
+		after     = time.Unix(0, 0).Add(25 * time.Hour)
+		change    = NewIndexBlockSizeChange(4*time.Hour, blockSize, after)
+		opts      = NewIndexOptions().
+				SetBlockSize(blockSize).
+				SetBlockSizeChange(change)
+	)
+
+	// The change starts at the next block start both block sizes share.
+	start := time.Unix(0, 0).Add(36 * time.Hour)
+	require.Equal(t, IndexBlockSizeChange{
+		PreviousBlockSize: 4 * time.Hour,
+		Start:             start,
+	}, change)
+	require.False(t, opts.Equal(opts.SetBlockSizeChange(IndexBlockSizeChange{})))
+
+	require.Equal(t, start.Add(-4*time.Hour),
+		opts.BlockStartForTime(start.Add(-time.Nanosecond)))
+	require.Equal(t, 4*time.Hour,
+		opts.BlockSizeForBlockStart(start.Add(-4*time.Hour)))
+	require.Equal(t, start, opts.BlockStartForTime(start.Add(5*time.Hour)))
+	require.Equal(t, blockSize, opts.BlockSizeForBlockStart(start))
+}
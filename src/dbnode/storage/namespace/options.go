@@ -23,6 +23,7 @@ package namespace
 import (
 	"errors"
 
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/retention"
 )
 
@@ -44,6 +45,9 @@ const (
 
 	// Namespace requires repair disabled by default.
 	defaultRepairEnabled = false
+
+	// Namespace rejects writes older than buffer past by default.
+	defaultColdWritesEnabled = false
 )
 
 var (
@@ -59,8 +63,11 @@ type options struct {
 	writesToCommitLog bool
 	cleanupEnabled    bool
 	repairEnabled     bool
+	coldWritesEnabled bool
+	codecID           codec.ID
 	retentionOpts     retention.Options
 	indexOpts         IndexOptions
+	seriesRetention   SeriesRetentionRules
 }
 
 // NewOptions creates a new namespace options
@@ -72,6 +79,8 @@ func NewOptions() Options {
 		writesToCommitLog: defaultWritesToCommitLog,
 		cleanupEnabled:    defaultCleanupEnabled,
 		repairEnabled:     defaultRepairEnabled,
+		coldWritesEnabled: defaultColdWritesEnabled,
+		codecID:           codec.DefaultID,
 		retentionOpts:     retention.NewOptions(),
 		indexOpts:         NewIndexOptions(),
 	}
@@ -81,6 +90,13 @@ func (o *options) Validate() error {
 	if err := o.retentionOpts.Validate(); err != nil {
 		return err
 	}
+	if err := o.codecID.Validate(); err != nil {
+		return err
+	}
+	err := o.seriesRetention.Validate(o.retentionOpts.RetentionPeriod())
+	if err != nil {
+		return err
+	}
 	if !o.indexOpts.Enabled() {
 		return nil
 	}
@@ -108,8 +124,11 @@ func (o *options) Equal(value Options) bool {
 		o.snapshotEnabled == value.SnapshotEnabled() &&
 		o.cleanupEnabled == value.CleanupEnabled() &&
 		o.repairEnabled == value.RepairEnabled() &&
+		o.coldWritesEnabled == value.ColdWritesEnabled() &&
+		o.codecID == value.CodecID() &&
 		o.retentionOpts.Equal(value.RetentionOptions()) &&
-		o.indexOpts.Equal(value.IndexOptions())
+		o.indexOpts.Equal(value.IndexOptions()) &&
+		o.seriesRetention.Equal(value.SeriesRetentionRules())
 }
 
 func (o *options) SetBootstrapEnabled(value bool) Options {
@@ -172,6 +191,26 @@ func (o *options) RepairEnabled() bool {
 	return o.repairEnabled
 }
 
+func (o *options) SetColdWritesEnabled(value bool) Options {
+	opts := *o
+	opts.coldWritesEnabled = value
+	return &opts
+}
+
+func (o *options) ColdWritesEnabled() bool {
+	return o.coldWritesEnabled
+}
+
+func (o *options) SetCodecID(value codec.ID) Options {
+	opts := *o
+	opts.codecID = value
+	return &opts
+}
+
+func (o *options) CodecID() codec.ID {
+	return o.codecID
+}
+
 func (o *options) SetRetentionOptions(value retention.Options) Options {
 	opts := *o
 	opts.retentionOpts = value
@@ -191,3 +230,13 @@ func (o *options) SetIndexOptions(value IndexOptions) Options {
 func (o *options) IndexOptions() IndexOptions {
 	return o.indexOpts
 }
+
+func (o *options) SetSeriesRetentionRules(value SeriesRetentionRules) Options {
+	opts := *o
+	opts.seriesRetention = value
+	return &opts
+}
+
+func (o *options) SeriesRetentionRules() SeriesRetentionRules {
+	return o.seriesRetention
+}
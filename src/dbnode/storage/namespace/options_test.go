@@ -25,6 +25,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/retention"
 
 	"github.com/golang/mock/gomock"
@@ -40,6 +41,34 @@ func TestOptionsEquals(t *testing.T) {
 	require.True(t, o2.Equal(o1))
 }
 
+func TestOptionsEqualsColdWritesEnabled(t *testing.T) {
+	o1 := NewOptions()
+	require.False(t, o1.ColdWritesEnabled())
+
+	o2 := o1.SetColdWritesEnabled(true)
+	require.True(t, o2.ColdWritesEnabled())
+	require.False(t, o1.Equal(o2))
+	require.False(t, o2.Equal(o1))
+}
+
+func TestOptionsEqualsCodecID(t *testing.T) {
+	o1 := NewOptions()
+	require.Equal(t, codec.DefaultID, o1.CodecID())
+
+	o2 := o1.SetCodecID(codec.DeltaOfDelta)
+	require.Equal(t, codec.DeltaOfDelta, o2.CodecID())
+	require.False(t, o1.Equal(o2))
+	require.False(t, o2.Equal(o1))
+}
+
+func TestOptionsValidateCodecID(t *testing.T) {
+	o1 := NewOptions().SetCodecID(codec.XOR)
+	require.NoError(t, o1.Validate())
+
+	o2 := o1.SetCodecID(codec.ID(255))
+	require.Error(t, o2.Validate())
+}
+
 func TestOptionsEqualsIndexOpts(t *testing.T) {
 	o1 := NewOptions()
 	o2 := o1.SetIndexOptions(
@@ -0,0 +1,119 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package namespace
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/m3db/m3x/ident"
+)
+
+var (
+	errSeriesRetentionRuleTagName  = errors.New("series retention rule tag name must be set")
+	errSeriesRetentionRuleTagValue = errors.New("series retention rule tag value must be set")
+)
+
+// SeriesRetentionRule gives the series with a matching tag a shorter
+// retention than the retention of the namespace, e.g. expiring series
+// tagged with retention_days=1 after a day.
+type SeriesRetentionRule struct {
+	// TagName is the name of the tag matched by the rule.
+	TagName string
+
+	// TagValue is the value the tag must have to match the rule.
+	TagValue string
+
+	// RetentionPeriod is the retention period of the matching series.
+	RetentionPeriod time.Duration
+}
+
+// SeriesRetentionRules is the set of series retention rules of a namespace.
+type SeriesRetentionRules []SeriesRetentionRule
+
+// Validate validates the rules, each must match a tag and have a
+// retention period no longer than the given namespace retention period.
+func (r SeriesRetentionRules) Validate(retentionPeriod time.Duration) error {
+	for _, rule := range r {
+		if rule.TagName == "" {
+			return errSeriesRetentionRuleTagName
+		}
+		if rule.TagValue == "" {
+			return errSeriesRetentionRuleTagValue
+		}
+		if rule.RetentionPeriod <= 0 || rule.RetentionPeriod > retentionPeriod {
+			return fmt.Errorf(
+				"series retention rule %s=%s retention period %v must be positive and <= namespace retention period %v",
+				rule.TagName, rule.TagValue, rule.RetentionPeriod, retentionPeriod)
+		}
+	}
+	return nil
+}
+
+// Equal returns true if the provide value is equal to this one.
+func (r SeriesRetentionRules) Equal(value SeriesRetentionRules) bool {
+	if len(r) != len(value) {
+		return false
+	}
+	for i := range r {
+		if r[i] != value[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// RetentionPeriod returns the shortest retention period of the rules
+// matching the given series tags, or false if no rule matches.
+func (r SeriesRetentionRules) RetentionPeriod(tags ident.Tags) (time.Duration, bool) {
+	var (
+		result  time.Duration
+		matched bool
+	)
+	if len(r) == 0 {
+		return result, matched
+	}
+	for _, tag := range tags.Values() {
+		for _, rule := range r {
+			if matched && rule.RetentionPeriod >= result {
+				continue
+			}
+			if string(tag.Name.Bytes()) == rule.TagName &&
+				string(tag.Value.Bytes()) == rule.TagValue {
+				result, matched = rule.RetentionPeriod, true
+			}
+		}
+	}
+	return result, matched
+}
+
+// ExpiredRetentionPeriod returns the longest retention period of the rules
+// that has expired for data of the given age, or zero if none has expired.
+func (r SeriesRetentionRules) ExpiredRetentionPeriod(age time.Duration) time.Duration {
+	var result time.Duration
+	for _, rule := range r {
+		if rule.RetentionPeriod <= age && rule.RetentionPeriod > result {
+			result = rule.RetentionPeriod
+		}
+	}
+	return result
+}
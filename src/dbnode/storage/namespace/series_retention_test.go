@@ -0,0 +1,81 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package namespace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3x/ident"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSeriesRetentionRules = SeriesRetentionRules{
+	{TagName: "retention_days", TagValue: "1", RetentionPeriod: 24 * time.Hour},
+	{TagName: "debug", TagValue: "true", RetentionPeriod: 6 * time.Hour},
+}
+
+func TestSeriesRetentionRulesRetentionPeriod(t *testing.T) {
+	period, ok := testSeriesRetentionRules.RetentionPeriod(ident.NewTags(
+		ident.StringTag("retention_days", "1")))
+	require.True(t, ok)
+	assert.Equal(t, 24*time.Hour, period)
+
+	// The shortest retention of the matching rules applies.
+	period, ok = testSeriesRetentionRules.RetentionPeriod(ident.NewTags(
+		ident.StringTag("debug", "true"),
+		ident.StringTag("retention_days", "1")))
+	require.True(t, ok)
+	assert.Equal(t, 6*time.Hour, period)
+
+	_, ok = testSeriesRetentionRules.RetentionPeriod(ident.NewTags(
+		ident.StringTag("retention_days", "2")))
+	assert.False(t, ok)
+
+	_, ok = SeriesRetentionRules(nil).RetentionPeriod(ident.NewTags(
+		ident.StringTag("debug", "true")))
+	assert.False(t, ok)
+}
+
+func TestSeriesRetentionRulesExpiredRetentionPeriod(t *testing.T) {
+	assert.Equal(t, time.Duration(0), testSeriesRetentionRules.ExpiredRetentionPeriod(time.Hour))
+	assert.Equal(t, 6*time.Hour, testSeriesRetentionRules.ExpiredRetentionPeriod(12*time.Hour))
+	assert.Equal(t, 24*time.Hour, testSeriesRetentionRules.ExpiredRetentionPeriod(48*time.Hour))
+}
+
+func TestOptionsValidateSeriesRetentionRules(t *testing.T) {
+	opts := NewOptions().SetSeriesRetentionRules(testSeriesRetentionRules)
+	require.NoError(t, opts.Validate())
+	require.False(t, opts.Equal(NewOptions()))
+
+	opts = NewOptions().SetSeriesRetentionRules(SeriesRetentionRules{
+		{TagName: "debug", TagValue: "true",
+			RetentionPeriod: 2 * NewOptions().RetentionOptions().RetentionPeriod()},
+	})
+	require.Error(t, opts.Validate())
+
+	opts = NewOptions().SetSeriesRetentionRules(SeriesRetentionRules{
+		{TagName: "debug", RetentionPeriod: time.Hour},
+	})
+	require.Equal(t, errSeriesRetentionRuleTagValue, opts.Validate())
+}
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/cluster/client"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3x/ident"
 	"github.com/m3db/m3x/instrument"
@@ -73,6 +74,22 @@ type Options interface {
 	// RepairEnabled returns whether the data for this namespace needs to be repaired
 	RepairEnabled() bool
 
+	// SetColdWritesEnabled sets whether writes older than buffer past are
+	// accepted for this namespace and merged into already flushed blocks
+	SetColdWritesEnabled(value bool) Options
+
+	// ColdWritesEnabled returns whether writes older than buffer past are
+	// accepted for this namespace and merged into already flushed blocks
+	ColdWritesEnabled() bool
+
+	// SetCodecID sets the compression codec used to encode the series data
+	// of this namespace, it cannot be changed once the namespace is created
+	SetCodecID(value codec.ID) Options
+
+	// CodecID returns the compression codec used to encode the series data
+	// of this namespace, it cannot be changed once the namespace is created
+	CodecID() codec.ID
+
 	// SetRetentionOptions sets the retention options for this namespace
 	SetRetentionOptions(value retention.Options) Options
 
@@ -84,6 +101,14 @@ type Options interface {
 
 	// IndexOptions returns the IndexOptions.
 	IndexOptions() IndexOptions
+
+	// SetSeriesRetentionRules sets the rules giving series with matching tags
+	// a shorter retention than the namespace retention
+	SetSeriesRetentionRules(value SeriesRetentionRules) Options
+
+	// SeriesRetentionRules returns the rules giving series with matching tags
+	// a shorter retention than the namespace retention
+	SeriesRetentionRules() SeriesRetentionRules
 }
 
 // IndexOptions controls the indexing options for a namespace.
@@ -102,6 +127,22 @@ type IndexOptions interface {
 
 	// BlockSize returns the block size.
 	BlockSize() time.Duration
+
+	// SetBlockSizeChange sets the change from a previous block size to the
+	// block size, if the block size has changed.
+	SetBlockSizeChange(value IndexBlockSizeChange) IndexOptions
+
+	// BlockSizeChange returns the change from a previous block size to the
+	// block size, if the block size has changed.
+	BlockSizeChange() IndexBlockSizeChange
+
+	// BlockStartForTime returns the start of the index block containing
+	// the given time.
+	BlockStartForTime(t time.Time) time.Time
+
+	// BlockSizeForBlockStart returns the size of the index block with the
+	// given block start.
+	BlockSizeForBlockStart(blockStart time.Time) time.Duration
 }
 
 // Metadata represents namespace metadata information
@@ -0,0 +1,121 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package namespace
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	errUpdateIDMismatch                  = errors.New("namespace update ID does not match existing namespace")
+	errUpdateBlockSizeChanged            = errors.New("namespace block size can not be updated")
+	errUpdateIndexBlockSizeChangeInvalid = errors.New("namespace index block size change must be from " +
+		"the existing index block size and start at a block start of both index block sizes")
+	errUpdateOptionsNotAllowed = errors.New("only namespace retention period, buffer past, " +
+		"buffer future, index block size and series retention rules can be updated")
+)
+
+// ValidateUpdate returns an error if applying the update to an existing
+// namespace is not a safe transition. Only the retention period, buffer past,
+// buffer future, index block size and series retention rules of a namespace
+// can be updated online. An index block size update must carry a change from
+// the existing index block size, see NewIndexBlockSizeChange.
+func ValidateUpdate(existing, update Metadata) error {
+	if !existing.ID().Equal(update.ID()) {
+		return errUpdateIDMismatch
+	}
+
+	var (
+		existingOpts    = existing.Options()
+		updateOpts      = update.Options()
+		existingRopts   = existingOpts.RetentionOptions()
+		updateRopts     = updateOpts.RetentionOptions()
+		existingIdxOpts = existingOpts.IndexOptions()
+		updateIdxOpts   = updateOpts.IndexOptions()
+	)
+	if existingRopts.BlockSize() != updateRopts.BlockSize() {
+		return errUpdateBlockSizeChanged
+	}
+	// NB: Existing index blocks keep their block size, only index blocks
+	// starting from the change use the new block size.
+	expectedIdxOpts := existingIdxOpts
+	if existingIdxOpts.BlockSize() != updateIdxOpts.BlockSize() {
+		if !validIndexBlockSizeChange(existingIdxOpts, updateIdxOpts) {
+			return errUpdateIndexBlockSizeChangeInvalid
+		}
+		expectedIdxOpts = existingIdxOpts.
+			SetBlockSize(updateIdxOpts.BlockSize()).
+			SetBlockSizeChange(updateIdxOpts.BlockSizeChange())
+	}
+
+	if err := updateOpts.Validate(); err != nil {
+		return fmt.Errorf("unable to validate options: %v", err)
+	}
+
+	// Apply the updatable fields to the existing options, what remains must
+	// be identical to the update otherwise a non-updatable field was changed.
+	expected := existingOpts.
+		SetRetentionOptions(existingRopts.
+			SetRetentionPeriod(updateRopts.RetentionPeriod()).
+			SetBufferPast(updateRopts.BufferPast()).
+			SetBufferFuture(updateRopts.BufferFuture())).
+		SetIndexOptions(expectedIdxOpts).
+		SetSeriesRetentionRules(updateOpts.SeriesRetentionRules())
+	if !expected.Equal(updateOpts) {
+		return errUpdateOptionsNotAllowed
+	}
+
+	return nil
+}
+
+func validIndexBlockSizeChange(existing, update IndexOptions) bool {
+	var (
+		change   = update.BlockSizeChange()
+		previous = existing.BlockSizeChange()
+	)
+	if change.PreviousBlockSize != existing.BlockSize() {
+		return false
+	}
+	if !change.Start.Truncate(change.PreviousBlockSize).Equal(change.Start) ||
+		!change.Start.Truncate(update.BlockSize()).Equal(change.Start) {
+		return false
+	}
+	return previous.IsZero() || change.Start.After(previous.Start)
+}
+
+// ValidateMapUpdate returns an error if any namespace present in both the
+// existing and the updated map fails ValidateUpdate. Namespaces that are
+// added or removed by the update are not validated.
+func ValidateMapUpdate(existing, update Map) error {
+	for _, md := range update.Metadatas() {
+		existingMd, err := existing.Get(md.ID())
+		if err != nil {
+			// New namespace.
+			continue
+		}
+		if err := ValidateUpdate(existingMd, md); err != nil {
+			return fmt.Errorf("invalid update for namespace %s: %v",
+				md.ID().String(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package namespace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3x/ident"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUpdateMetadata(t *testing.T, id string, opts Options) Metadata {
+	md, err := NewMetadata(ident.StringID(id), opts)
+	require.NoError(t, err)
+	return md
+}
+
+func TestValidateUpdateRetentionAndIndexBlockSize(t *testing.T) {
+	opts := NewOptions()
+	existing := newTestUpdateMetadata(t, "ns", opts)
+
+	ropts := opts.RetentionOptions().
+		SetRetentionPeriod(4 * 24 * time.Hour).
+		SetBufferPast(20 * time.Minute).
+		SetBufferFuture(5 * time.Minute)
+	change := NewIndexBlockSizeChange(2*time.Hour, 4*time.Hour, time.Now())
+	update := newTestUpdateMetadata(t, "ns", opts.
+		SetRetentionOptions(ropts).
+		SetIndexOptions(opts.IndexOptions().
+			SetBlockSize(4*time.Hour).
+			SetBlockSizeChange(change)))
+
+	require.NoError(t, ValidateUpdate(existing, update))
+
+	// The change is kept by later updates which do not change the block size.
+	next := newTestUpdateMetadata(t, "ns", update.Options().
+		SetRetentionOptions(ropts.SetRetentionPeriod(5*24*time.Hour)))
+	require.NoError(t, ValidateUpdate(update, next))
+
+	dropped := newTestUpdateMetadata(t, "ns", next.Options().
+		SetIndexOptions(next.Options().IndexOptions().
+			SetBlockSizeChange(IndexBlockSizeChange{})))
+	require.Equal(t, errUpdateOptionsNotAllowed, ValidateUpdate(update, dropped))
+}
+
+func TestValidateUpdateSeriesRetentionRules(t *testing.T) {
+	opts := NewOptions()
+	existing := newTestUpdateMetadata(t, "ns", opts)
+	update := newTestUpdateMetadata(t, "ns", opts.SetSeriesRetentionRules(
+		SeriesRetentionRules{
+			{TagName: "retention_days", TagValue: "1", RetentionPeriod: 24 * time.Hour},
+		}))
+
+	require.NoError(t, ValidateUpdate(existing, update))
+}
+
+func TestValidateUpdateIDMismatch(t *testing.T) {
+	opts := NewOptions()
+	existing := newTestUpdateMetadata(t, "ns1", opts)
+	update := newTestUpdateMetadata(t, "ns2", opts)
+
+	require.Equal(t, errUpdateIDMismatch, ValidateUpdate(existing, update))
+}
+
+func TestValidateUpdateBlockSizeChanged(t *testing.T) {
+	opts := NewOptions()
+	existing := newTestUpdateMetadata(t, "ns", opts)
+	update := newTestUpdateMetadata(t, "ns", opts.SetRetentionOptions(
+		opts.RetentionOptions().SetBlockSize(time.Hour)))
+
+	require.Equal(t, errUpdateBlockSizeChanged, ValidateUpdate(existing, update))
+}
+
+func TestValidateUpdateIndexBlockSizeChangeInvalid(t *testing.T) {
+	var (
+		opts     = NewOptions()
+		existing = newTestUpdateMetadata(t, "ns", opts)
+		start    = time.Now().Truncate(4 * time.Hour).Add(4 * time.Hour)
+	)
+
+	for _, change := range []IndexBlockSizeChange{
+		// No change from the existing block size.
+		{},
+		// Change from a block size other than the existing block size.
+		{PreviousBlockSize: time.Hour, Start: start},
+		// Change not starting at a block start of the new block size.
+		{PreviousBlockSize: 2 * time.Hour, Start: start.Add(2 * time.Hour)},
+	} {
+		update := newTestUpdateMetadata(t, "ns", opts.SetIndexOptions(
+			opts.IndexOptions().
+				SetBlockSize(4*time.Hour).
+				SetBlockSizeChange(change)))
+		require.Equal(t, errUpdateIndexBlockSizeChangeInvalid,
+			ValidateUpdate(existing, update))
+	}
+}
+
+func TestValidateUpdateOptionsNotAllowed(t *testing.T) {
+	opts := NewOptions()
+	existing := newTestUpdateMetadata(t, "ns", opts)
+	update := newTestUpdateMetadata(t, "ns", opts.
+		SetFlushEnabled(!opts.FlushEnabled()))
+
+	require.Equal(t, errUpdateOptionsNotAllowed, ValidateUpdate(existing, update))
+
+	update = newTestUpdateMetadata(t, "ns", opts.
+		SetIndexOptions(opts.IndexOptions().SetEnabled(!opts.IndexOptions().Enabled())))
+	require.Equal(t, errUpdateOptionsNotAllowed, ValidateUpdate(existing, update))
+}
+
+func TestValidateMapUpdate(t *testing.T) {
+	opts := NewOptions()
+	existing, err := NewMap([]Metadata{
+		newTestUpdateMetadata(t, "ns1", opts),
+	})
+	require.NoError(t, err)
+
+	// Adding a namespace alongside a valid update is allowed.
+	valid, err := NewMap([]Metadata{
+		newTestUpdateMetadata(t, "ns1", opts.SetRetentionOptions(
+			opts.RetentionOptions().SetRetentionPeriod(24*time.Hour))),
+		newTestUpdateMetadata(t, "ns2", opts.SetRepairEnabled(true)),
+	})
+	require.NoError(t, err)
+	require.NoError(t, ValidateMapUpdate(existing, valid))
+
+	invalid, err := NewMap([]Metadata{
+		newTestUpdateMetadata(t, "ns1", opts.SetRepairEnabled(true)),
+	})
+	require.NoError(t, err)
+	require.Error(t, ValidateMapUpdate(existing, invalid))
+}
@@ -58,10 +58,11 @@ const (
 )
 
 type databaseNamespaceReaderManager interface {
-	filesetExistsAt(
+	filesetAt(
 		shard uint32,
 		blockStart time.Time,
-	) (bool, error)
+		blockSize time.Duration,
+	) (fs.DataFileSetRange, bool, error)
 
 	get(
 		shard uint32,
@@ -76,12 +77,13 @@ type databaseNamespaceReaderManager interface {
 	close()
 }
 
-type fsFileSetExistsAtFn func(
-	prefix string,
+type fsCoveringDataFileSetFn func(
+	opts fs.Options,
 	namespace ident.ID,
 	shard uint32,
 	blockStart time.Time,
-) (bool, error)
+	blockSize time.Duration,
+) (fs.DataFileSetRange, bool, error)
 
 type fsNewReaderFn func(
 	bytesPool pool.CheckedBytesPool,
@@ -91,7 +93,7 @@ type fsNewReaderFn func(
 type namespaceReaderManager struct {
 	sync.Mutex
 
-	filesetExistsAtFn fsFileSetExistsAtFn
+	coveringFilesetFn fsCoveringDataFileSetFn
 	newReaderFn       fsNewReaderFn
 
 	namespace namespace.Metadata
@@ -149,7 +151,7 @@ func newNamespaceReaderManager(
 	opts Options,
 ) databaseNamespaceReaderManager {
 	return &namespaceReaderManager{
-		filesetExistsAtFn: fs.DataFileSetExistsAt,
+		coveringFilesetFn: fs.CoveringDataFileSet,
 		newReaderFn:       fs.NewReader,
 		namespace:         namespace,
 		fsOpts:            opts.CommitLogOptions().FilesystemOptions(),
@@ -160,12 +162,13 @@ func newNamespaceReaderManager(
 	}
 }
 
-func (m *namespaceReaderManager) filesetExistsAt(
+func (m *namespaceReaderManager) filesetAt(
 	shard uint32,
 	blockStart time.Time,
-) (bool, error) {
-	return m.filesetExistsAtFn(m.fsOpts.FilePathPrefix(),
-		m.namespace.ID(), shard, blockStart)
+	blockSize time.Duration,
+) (fs.DataFileSetRange, bool, error) {
+	return m.coveringFilesetFn(m.fsOpts, m.namespace.ID(), shard,
+		blockStart, blockSize)
 }
 
 type cachedReaderForKeyResult struct {
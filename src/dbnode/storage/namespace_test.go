@@ -35,8 +35,10 @@ import (
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/storage/repair"
+	"github.com/m3db/m3/src/dbnode/storage/series"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/metrics"
 	"github.com/m3db/m3x/context"
@@ -47,6 +49,7 @@ import (
 
 	"github.com/fortytw2/leaktest"
 	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/uber-go/tally"
@@ -293,7 +296,7 @@ func TestNamespaceBootstrapAllShards(t *testing.T) {
 	errs := []error{nil, errors.New("foo")}
 	bs := bootstrap.NewMockProcess(ctrl)
 	bs.EXPECT().
-		Run(start, ns.metadata, sharding.IDs(testShardIDs)).
+		Run(start, ns.namespaceMetadata(), sharding.IDs(testShardIDs)).
 		Return(bootstrap.ProcessResult{
 			DataResult:  result.NewDataBootstrapResult(),
 			IndexResult: result.NewIndexBootstrapResult(),
@@ -333,7 +336,7 @@ func TestNamespaceBootstrapOnlyNonBootstrappedShards(t *testing.T) {
 
 	bs := bootstrap.NewMockProcess(ctrl)
 	bs.EXPECT().
-		Run(start, ns.metadata, sharding.IDs(needsBootstrap)).
+		Run(start, ns.namespaceMetadata(), sharding.IDs(needsBootstrap)).
 		Return(bootstrap.ProcessResult{
 			DataResult:  result.NewDataBootstrapResult(),
 			IndexResult: result.NewIndexBootstrapResult(),
@@ -451,7 +454,7 @@ func TestNamespaceSnapshotNotBootstrapped(t *testing.T) {
 
 	blockSize := ns.Options().RetentionOptions().BlockSize()
 	blockStart := time.Now().Truncate(blockSize)
-	require.Equal(t, errNamespaceNotBootstrapped, ns.Snapshot(blockStart, blockStart, nil, nil))
+	require.Equal(t, errNamespaceNotBootstrapped, ns.Snapshot(blockStart, blockStart, nil, nil, nil))
 }
 
 func TestNamespaceSnapshotShardIsSnapshotting(t *testing.T) {
@@ -540,6 +543,7 @@ func testSnapshotWithShardSnapshotErrs(t *testing.T, shardMethodResults []snapsh
 		shardBootstrapStates = ShardBootstrapStates{}
 		blockSize            = ns.Options().RetentionOptions().BlockSize()
 		blockStart           = now.Truncate(blockSize)
+		snapshotID           = uuid.NewRandom()
 	)
 
 	for i, tc := range shardMethodResults {
@@ -554,13 +558,13 @@ func testSnapshotWithShardSnapshotErrs(t *testing.T, shardMethodResults []snapsh
 		shardID := uint32(i)
 		shard.EXPECT().ID().Return(uint32(i)).AnyTimes()
 		if tc.expectSnapshot {
-			shard.EXPECT().Snapshot(blockStart, now, nil).Return(tc.shardSnapshotErr)
+			shard.EXPECT().Snapshot(blockStart, now, snapshotID, nil).Return(tc.shardSnapshotErr)
 		}
 		ns.shards[testShardIDs[i].ID()] = shard
 		shardBootstrapStates[shardID] = tc.shardBootstrapStateBeforeTick
 	}
 
-	return ns.Snapshot(blockStart, now, shardBootstrapStates, nil)
+	return ns.Snapshot(blockStart, now, snapshotID, shardBootstrapStates, nil)
 }
 
 func TestNamespaceTruncate(t *testing.T) {
@@ -582,6 +586,166 @@ func TestNamespaceTruncate(t *testing.T) {
 	require.True(t, ns.shards[testShardIDs[0].ID()].IsBootstrapped())
 }
 
+func TestNamespaceDeleteSeries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	idx := NewMocknamespaceIndex(ctrl)
+	ns, closer := newTestNamespaceWithIndex(t, idx)
+	defer closer()
+
+	ids := []ident.ID{ident.StringID("foo"), ident.StringID("bar")}
+	shard := NewMockdatabaseShard(ctrl)
+	shard.EXPECT().DeleteSeries(ids).Return(nil)
+	ns.shards[testShardIDs[0].ID()] = shard
+
+	idx.EXPECT().Delete(ids).Return(errors.New("err"))
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	deleted, err := ns.DeleteSeries(ctx, ids)
+	require.Error(t, err)
+	require.Equal(t, ids, deleted)
+}
+
+func TestNamespaceDeleteSeriesShardNotOwned(t *testing.T) {
+	ns, closer := newTestNamespace(t)
+	defer closer()
+	for i := range ns.shards {
+		ns.shards[i] = nil
+	}
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	deleted, err := ns.DeleteSeries(ctx, []ident.ID{ident.StringID("foo")})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(deleted))
+}
+
+func TestNamespaceDeleteTagged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	idx := NewMocknamespaceIndex(ctrl)
+	idx.EXPECT().BootstrapsDone().Return(uint(1))
+
+	ns, closer := newTestNamespaceWithIndex(t, idx)
+	defer closer()
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	results := index.NewResults(testNamespaceIndexOptions())
+	results.Reset(ns.ID())
+	_, _, err := results.AddIDAndTags(ident.StringID("foo"), ident.Tags{})
+	require.NoError(t, err)
+
+	query := index.Query{}
+	idx.EXPECT().Query(ctx, query, gomock.Any()).Return(index.QueryResults{
+		Results:    results,
+		Exhaustive: true,
+	}, nil)
+
+	shard := NewMockdatabaseShard(ctrl)
+	shard.EXPECT().DeleteSeries(gomock.Any()).Return(nil)
+	ns.shards[testShardIDs[0].ID()] = shard
+
+	idx.EXPECT().Delete(gomock.Any()).Return(nil)
+
+	deleted, err := ns.DeleteTagged(ctx, query)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(deleted))
+	require.Equal(t, "foo", deleted[0].String())
+}
+
+func TestNamespaceFlushDeletesNotBootstrapped(t *testing.T) {
+	ns, closer := newTestNamespace(t)
+	defer closer()
+	require.Equal(t, errNamespaceNotBootstrapped, ns.FlushDeletes(nil))
+}
+
+func TestNamespaceFlushDeletesAllShards(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ns, closer := newTestNamespace(t)
+	defer closer()
+	ns.bootstrapState = Bootstrapped
+
+	errs := []error{nil, errors.New("foo")}
+	for i := range errs {
+		shard := NewMockdatabaseShard(ctrl)
+		shard.EXPECT().ID().Return(uint32(i)).AnyTimes()
+		shard.EXPECT().FlushDeletes(gomock.Any()).Return(errs[i])
+		ns.shards[testShardIDs[i].ID()] = shard
+	}
+
+	require.Error(t, ns.FlushDeletes(nil))
+}
+
+func TestNamespaceFlushRepairsNotBootstrapped(t *testing.T) {
+	ns, closer := newTestNamespace(t)
+	defer closer()
+	require.Equal(t, errNamespaceNotBootstrapped, ns.FlushRepairs(nil))
+}
+
+func TestNamespaceFlushRepairsAllShards(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ns, closer := newTestNamespace(t)
+	defer closer()
+	ns.bootstrapState = Bootstrapped
+
+	errs := []error{nil, errors.New("foo")}
+	for i := range errs {
+		shard := NewMockdatabaseShard(ctrl)
+		shard.EXPECT().ID().Return(uint32(i)).AnyTimes()
+		shard.EXPECT().FlushRepairs(gomock.Any()).Return(errs[i])
+		ns.shards[testShardIDs[i].ID()] = shard
+	}
+
+	require.Error(t, ns.FlushRepairs(nil))
+}
+
+func TestNamespaceColdFlushColdWritesDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ns, closer := newTestNamespace(t)
+	defer closer()
+	ns.bootstrapState = Bootstrapped
+
+	// No shard expectations, cold flushing is a no-op without cold writes.
+	for i := range testShardIDs {
+		ns.shards[testShardIDs[i].ID()] = NewMockdatabaseShard(ctrl)
+	}
+
+	require.NoError(t, ns.ColdFlush(nil))
+}
+
+func TestNamespaceColdFlushAllShards(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ns, closer := newTestNamespaceWithIDOpts(t, defaultTestNs1ID,
+		defaultTestNs1Opts.SetColdWritesEnabled(true))
+	defer closer()
+	ns.bootstrapState = Bootstrapped
+
+	errs := []error{nil, errors.New("foo")}
+	for i := range errs {
+		shard := NewMockdatabaseShard(ctrl)
+		shard.EXPECT().ID().Return(uint32(i)).AnyTimes()
+		shard.EXPECT().ColdFlush(gomock.Any()).Return(errs[i])
+		ns.shards[testShardIDs[i].ID()] = shard
+	}
+
+	require.Error(t, ns.ColdFlush(nil))
+}
+
 func TestNamespaceRepair(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1103,6 +1267,28 @@ func TestNamespaceIndexQuery(t *testing.T) {
 	require.NoError(t, ns.Close())
 }
 
+func TestNamespaceIndexAggregateQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	idx := NewMocknamespaceIndex(ctrl)
+	idx.EXPECT().BootstrapsDone().Return(uint(1))
+
+	ns, closer := newTestNamespaceWithIndex(t, idx)
+	defer closer()
+
+	ctx := context.NewContext()
+	query := index.Query{}
+	opts := index.AggregationOptions{Type: index.AggregateTagNames}
+
+	idx.EXPECT().AggregateQuery(ctx, query, opts)
+	_, err := ns.AggregateQuery(ctx, query, opts)
+	require.NoError(t, err)
+
+	idx.EXPECT().Close().Return(nil)
+	require.NoError(t, ns.Close())
+}
+
 func TestNamespaceTicksIndex(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1131,6 +1317,62 @@ func TestNamespaceIndexDisabledQuery(t *testing.T) {
 	require.NoError(t, ns.Close())
 }
 
+func TestNamespaceQueryIDsDocsLookbackLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	metadata := newTestNamespaceMetadata(t)
+	shardSet, err := sharding.NewShardSet(testShardIDs, sharding.DefaultHashFn(1))
+	require.NoError(t, err)
+	dopts := testDatabaseOptions().
+		SetLimitsOptions(limits.NewOptions().
+			SetDocsLimitOptions(limits.LookbackLimitOptions{
+				Limit:    1,
+				Lookback: time.Hour,
+			}))
+	dbNs, err := newDatabaseNamespace(metadata, shardSet, nil, nil, nil, dopts)
+	require.NoError(t, err)
+
+	ns := dbNs.(*dbNamespace)
+	idx := NewMocknamespaceIndex(ctrl)
+	idx.EXPECT().BootstrapsDone().Return(uint(1)).AnyTimes()
+	ns.reverseIndex = idx
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	results := index.NewResults(testNamespaceIndexOptions())
+	results.Reset(ns.ID())
+	for _, id := range []string{"foo", "bar"} {
+		_, _, err := results.AddIDAndTags(ident.StringID(id), ident.Tags{})
+		require.NoError(t, err)
+	}
+
+	query := index.Query{}
+	opts := index.QueryOptions{}
+	idx.EXPECT().Query(ctx, query, opts).Return(index.QueryResults{
+		Results:    results,
+		Exhaustive: true,
+	}, nil)
+
+	_, err = ns.QueryIDs(ctx, query, opts)
+	require.Error(t, err)
+	require.True(t, limits.IsQueryLimitExceededError(err))
+
+	// Subsequent queries are rejected without hitting the index until the
+	// lookback window resets.
+	_, err = ns.QueryIDs(ctx, query, opts)
+	require.Error(t, err)
+	require.True(t, limits.IsQueryLimitExceededError(err))
+
+	_, err = ns.ReadEncoded(ctx, ident.StringID("foo"), time.Now(), time.Now())
+	require.Error(t, err)
+	require.True(t, limits.IsQueryLimitExceededError(err))
+
+	idx.EXPECT().Close().Return(nil)
+	require.NoError(t, ns.Close())
+}
+
 func TestNamespaceBootstrapState(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1163,7 +1405,7 @@ func TestNamespaceIsCapturedBySnapshot(t *testing.T) {
 
 	var (
 		testTime   = time.Now()
-		blockSize  = ns.nopts.RetentionOptions().BlockSize()
+		blockSize  = ns.Options().RetentionOptions().BlockSize()
 		blockStart = time.Now().Truncate(blockSize)
 		testCases  = []struct {
 			title                 string
@@ -1343,3 +1585,50 @@ func waitForStats(
 
 	wg.Wait()
 }
+
+func TestNamespaceUpdateMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ns, closer := newTestNamespace(t)
+	defer closer()
+
+	ropts := defaultTestNs1Opts.RetentionOptions().
+		SetRetentionPeriod(4 * 24 * time.Hour).
+		SetBufferPast(20 * time.Minute)
+	md := newTestNamespaceMetadataWithIDOpts(t, defaultTestNs1ID,
+		defaultTestNs1Opts.SetRetentionOptions(ropts))
+
+	for i := range testShardIDs {
+		shard := NewMockdatabaseShard(ctrl)
+		shard.EXPECT().UpdateNamespaceMetadata(md, gomock.Any()).
+			Do(func(_ namespace.Metadata, seriesOpts series.Options) {
+				require.Equal(t, ropts, seriesOpts.RetentionOptions())
+			})
+		ns.shards[testShardIDs[i].ID()] = shard
+	}
+
+	require.NoError(t, ns.UpdateMetadata(md))
+	require.Equal(t, md, ns.namespaceMetadata())
+	require.Equal(t, md.Options(), ns.Options())
+	require.Equal(t, ropts, ns.seriesOptions().RetentionOptions())
+}
+
+func TestNamespaceUpdateMetadataInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ns, closer := newTestNamespace(t)
+	defer closer()
+
+	for i := range testShardIDs {
+		ns.shards[testShardIDs[i].ID()] = NewMockdatabaseShard(ctrl)
+	}
+
+	ropts := defaultTestNs1Opts.RetentionOptions().SetBlockSize(time.Hour)
+	md := newTestNamespaceMetadataWithIDOpts(t, defaultTestNs1ID,
+		defaultTestNs1Opts.SetRetentionOptions(ropts))
+
+	require.Error(t, ns.UpdateMetadata(md))
+	require.Equal(t, defaultTestNs1Opts, ns.Options())
+}
@@ -30,6 +30,7 @@ import (
 
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/dod"
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
@@ -38,6 +39,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
 	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/storage/repair"
 	"github.com/m3db/m3/src/dbnode/storage/series"
@@ -88,6 +90,7 @@ var (
 	errNamespaceInitializerNotSet = errors.New("namespace registry initializer not set")
 	errRepairOptionsNotSet        = errors.New("repair enabled but repair options are not set")
 	errIndexOptionsNotSet         = errors.New("index enabled but index options are not set")
+	errLimitsOptionsNotSet        = errors.New("limits options are not set")
 	errPersistManagerNotSet       = errors.New("persist manager is not set")
 )
 
@@ -122,6 +125,7 @@ type options struct {
 	indexingEnabled                bool
 	repairEnabled                  bool
 	indexOpts                      index.Options
+	limitsOpts                     limits.Options
 	repairOpts                     repair.Options
 	newEncoderFn                   encoding.NewEncoderFn
 	newDecoderFn                   encoding.NewDecoderFn
@@ -176,6 +180,7 @@ func newOptions(poolOpts pool.ObjectPoolOptions) Options {
 		errThresholdForLoad:      defaultErrorThresholdForLoad,
 		indexingEnabled:          defaultIndexingEnabled,
 		indexOpts:                index.NewOptions(),
+		limitsOpts:               limits.NewOptions(),
 		repairEnabled:            defaultRepairEnabled,
 		repairOpts:               repair.NewOptions(),
 		bootstrapProcessProvider: defaultBootstrapProcessProvider,
@@ -238,6 +243,15 @@ func (o *options) Validate() error {
 		return fmt.Errorf("unable to validate index options, err: %v", err)
 	}
 
+	// validate limits options
+	lOpts := o.LimitsOptions()
+	if lOpts == nil {
+		return errLimitsOptionsNotSet
+	}
+	if err := lOpts.Validate(); err != nil {
+		return fmt.Errorf("unable to validate limits options, err: %v", err)
+	}
+
 	// validate that persist manager is present, if not return
 	// error if error occurred during default creation otherwise
 	// it was set to nil by a caller
@@ -267,6 +281,7 @@ func (o *options) SetInstrumentOptions(value instrument.Options) Options {
 	opts.instrumentOpts = value
 	opts.commitLogOpts = opts.commitLogOpts.SetInstrumentOptions(value)
 	opts.indexOpts = opts.indexOpts.SetInstrumentOptions(value)
+	opts.limitsOpts = opts.limitsOpts.SetInstrumentOptions(value)
 	opts.seriesOpts = NewSeriesOptionsFromOptions(&opts, nil)
 	return &opts
 }
@@ -356,6 +371,16 @@ func (o *options) IndexOptions() index.Options {
 	return o.indexOpts
 }
 
+func (o *options) SetLimitsOptions(value limits.Options) Options {
+	opts := *o
+	opts.limitsOpts = value
+	return &opts
+}
+
+func (o *options) LimitsOptions() limits.Options {
+	return o.limitsOpts
+}
+
 func (o *options) SetRepairEnabled(b bool) Options {
 	opts := *o
 	opts.repairEnabled = b
@@ -432,7 +457,9 @@ func (o *options) SetEncodingM3TSZPooled() Options {
 		SetEncoderPool(encoderPool).
 		SetReaderIteratorPool(readerIteratorPool).
 		SetMultiReaderIteratorPool(multiReaderIteratorPool).
-		SetBytesPool(bytesPool)
+		SetBytesPool(bytesPool).
+		SetCodecRegistry(encoding.NewCodecRegistry(encodingOpts, opts.poolOpts,
+			m3tsz.NewCodec(), m3tsz.NewXORCodec(), dod.NewCodec()))
 
 	opts.seriesOpts = NewSeriesOptionsFromOptions(&opts, nil)
 	return &opts
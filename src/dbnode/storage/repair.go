@@ -34,7 +34,9 @@ import (
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
+	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/storage/repair"
+	"github.com/m3db/m3/src/dbnode/topology"
 	"github.com/m3db/m3x/context"
 	xerrors "github.com/m3db/m3x/errors"
 	"github.com/m3db/m3x/ident"
@@ -56,6 +58,7 @@ type shardRepairer struct {
 	rpopts   repair.Options
 	client   client.AdminClient
 	recordFn recordFn
+	sleepFn  sleepFn
 	logger   xlog.Logger
 	scope    tally.Scope
 	nowFn    clock.NowFn
@@ -66,12 +69,13 @@ func newShardRepairer(opts Options, rpopts repair.Options) databaseShardRepairer
 	scope := iopts.MetricsScope().SubScope("repair")
 
 	r := shardRepairer{
-		opts:   opts,
-		rpopts: rpopts,
-		client: rpopts.AdminClient(),
-		logger: iopts.Logger(),
-		scope:  scope,
-		nowFn:  opts.ClockOptions().NowFn(),
+		opts:    opts,
+		rpopts:  rpopts,
+		client:  rpopts.AdminClient(),
+		sleepFn: time.Sleep,
+		logger:  iopts.Logger(),
+		scope:   scope,
+		nowFn:   opts.ClockOptions().NowFn(),
 	}
 	r.recordFn = r.recordDifferences
 
@@ -84,7 +88,7 @@ func (r shardRepairer) Options() repair.Options {
 
 func (r shardRepairer) Repair(
 	ctx context.Context,
-	namespace ident.ID,
+	nsMeta namespace.Metadata,
 	tr xtime.Range,
 	shard databaseShard,
 ) (repair.MetadataComparisonResult, error) {
@@ -98,6 +102,7 @@ func (r shardRepairer) Repair(
 		end      = tr.End
 		origin   = session.Origin()
 		replicas = session.Replicas()
+		nsID     = nsMeta.ID()
 	)
 
 	metadata := repair.NewReplicaMetadataComparer(replicas, r.rpopts)
@@ -114,6 +119,11 @@ func (r shardRepairer) Repair(
 	}
 	ctx.RegisterCloser(localMetadata)
 
+	localIDs := make(map[string]struct{}, len(localMetadata.Results()))
+	for _, result := range localMetadata.Results() {
+		localIDs[result.ID.String()] = struct{}{}
+	}
+
 	localIter := block.NewFilteredBlocksMetadataIter(localMetadata)
 	err = metadata.AddLocalMetadata(origin, localIter)
 	if err != nil {
@@ -122,20 +132,245 @@ func (r shardRepairer) Repair(
 
 	// Add peer metadata
 	level := r.rpopts.RepairConsistencyLevel()
-	peerIter, err := session.FetchBlocksMetadataFromPeers(namespace, shard.ID(), start, end,
+	peerIter, err := session.FetchBlocksMetadataFromPeers(nsID, shard.ID(), start, end,
 		level, result.NewOptions())
 	if err != nil {
 		return repair.MetadataComparisonResult{}, err
 	}
-	if err := metadata.AddPeerMetadata(peerIter); err != nil {
+	tagsIter := newMissingSeriesTagsIter(peerIter, localIDs)
+	if err := metadata.AddPeerMetadata(tagsIter); err != nil {
 		return repair.MetadataComparisonResult{}, err
 	}
 
 	metadataRes := metadata.Compare()
 
-	r.recordFn(namespace, shard, metadataRes)
+	r.recordFn(nsID, shard, metadataRes)
+
+	if r.rpopts.RepairDryRun() {
+		return metadataRes, nil
+	}
+
+	err = r.repairDifferences(session, nsMeta, shard, origin, metadataRes, tagsIter.tags)
+	return metadataRes, err
+}
+
+// repairDifferences fetches every peer replica of the blocks that differ from
+// the local replica and queues them on the shard, the shard merges them with
+// its flushed data the next time it flushes so that all replicas converge.
+func (r shardRepairer) repairDifferences(
+	session client.AdminSession,
+	nsMeta namespace.Metadata,
+	shard databaseShard,
+	origin topology.Host,
+	diffRes repair.MetadataComparisonResult,
+	missingTags map[string]ident.Tags,
+) error {
+	metadatas := peerReplicaMetadatas(origin, diffRes)
+
+	var (
+		nsID       = nsMeta.ID()
+		level      = r.rpopts.RepairConsistencyLevel()
+		batchSize  = r.rpopts.RepairMaxBlocksPerSecond()
+		numBlocks  = len(metadatas)
+		shardScope = r.scope.Tagged(map[string]string{
+			"namespace": nsID.String(),
+			"shard":     strconv.Itoa(int(shard.ID())),
+		})
+		pendingGauge   = shardScope.Gauge("pending-blocks")
+		progressGauge  = shardScope.Gauge("progress")
+		repairedBlocks = shardScope.Counter("repaired-blocks")
+		repairErrors   = shardScope.Counter("repair-errors")
+		numRepaired    int
+		numErrors      int
+		multiErr       = xerrors.NewMultiError()
+	)
+	if numBlocks == 0 {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = numBlocks
+	}
+
+	r.logger.WithFields(
+		xlog.NewField("namespace", nsID.String()),
+		xlog.NewField("shard", shard.ID()),
+		xlog.NewField("numBlocks", numBlocks),
+	).Infof("repairing differing blocks from peers")
+
+	for batchStart := 0; batchStart < numBlocks; batchStart += batchSize {
+		batchEnd := batchStart + batchSize
+		if batchEnd > numBlocks {
+			batchEnd = numBlocks
+		}
+
+		fetchStart := r.nowFn()
+		pendingGauge.Update(float64(numBlocks - batchStart))
+
+		n, err := r.fetchAndAddBlocks(session, nsMeta, shard, level,
+			metadatas[batchStart:batchEnd], missingTags)
+		numRepaired += n
+		repairedBlocks.Inc(int64(n))
+		if err != nil {
+			numErrors++
+			repairErrors.Inc(1)
+			multiErr = multiErr.Add(err)
+		}
+		progressGauge.Update(float64(batchEnd) / float64(numBlocks))
+
+		// Rate limit the blocks fetched from peers, sleeping for the remainder
+		// of the second before fetching the next batch.
+		if batchEnd < numBlocks {
+			if elapsed := r.nowFn().Sub(fetchStart); elapsed < time.Second {
+				r.sleepFn(time.Second - elapsed)
+			}
+		}
+	}
+	pendingGauge.Update(0)
+
+	r.logger.WithFields(
+		xlog.NewField("namespace", nsID.String()),
+		xlog.NewField("shard", shard.ID()),
+		xlog.NewField("numBlocks", numBlocks),
+		xlog.NewField("numRepaired", numRepaired),
+		xlog.NewField("numErrors", numErrors),
+	).Infof("finished repairing differing blocks from peers")
+
+	return multiErr.FinalError()
+}
+
+func (r shardRepairer) fetchAndAddBlocks(
+	session client.AdminSession,
+	nsMeta namespace.Metadata,
+	shard databaseShard,
+	level topology.ReadConsistencyLevel,
+	metadatas []block.ReplicaMetadata,
+	missingTags map[string]ident.Tags,
+) (int, error) {
+	iter, err := session.FetchBlocksFromPeers(nsMeta, shard.ID(), level,
+		metadatas, result.NewOptions())
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		numAdded int
+		multiErr = xerrors.NewMultiError()
+	)
+	for iter.Next() {
+		_, currID, repaired := iter.Current()
+		// NB: The ID is only valid until the next call to Next so it must be
+		// copied since the shard holds on to it until the block is flushed.
+		id := ident.StringID(currID.String())
+		// NB: Tags are only required for series the local replica is missing
+		// entirely, existing series already have their tags persisted.
+		tags := missingTags[id.String()]
+		if err := shard.AddRepairedBlock(id, tags, repaired); err != nil {
+			repaired.Close()
+			multiErr = multiErr.Add(err)
+			continue
+		}
+		numAdded++
+	}
+	if err := iter.Err(); err != nil {
+		multiErr = multiErr.Add(err)
+	}
+	return numAdded, multiErr.FinalError()
+}
+
+// peerReplicaMetadatas returns the metadata of every peer replica of the
+// blocks with size or checksum differences, peers that do not have a replica
+// of a block are skipped.
+func peerReplicaMetadatas(
+	origin topology.Host,
+	diffRes repair.MetadataComparisonResult,
+) []block.ReplicaMetadata {
+	var (
+		metadatas []block.ReplicaMetadata
+		seen      = make(map[string]struct{})
+	)
+	for _, diffs := range []repair.ReplicaSeriesMetadata{
+		diffRes.ChecksumDifferences,
+		diffRes.SizeDifferences,
+	} {
+		if diffs == nil {
+			continue
+		}
+		for _, entry := range diffs.Series().Iter() {
+			series := entry.Value()
+			for blockStart, blockMetadata := range series.Metadata.Blocks() {
+				for _, hostMetadata := range blockMetadata.Metadata() {
+					if hostMetadata.Host.ID() == origin.ID() {
+						continue
+					}
+					if hostMetadata.Size == 0 && hostMetadata.Checksum == nil {
+						continue
+					}
+					key := fmt.Sprintf("%s/%d/%s", series.ID.String(),
+						blockStart, hostMetadata.Host.ID())
+					if _, ok := seen[key]; ok {
+						continue
+					}
+					seen[key] = struct{}{}
+					metadatas = append(metadatas, block.ReplicaMetadata{
+						Metadata: block.NewMetadata(series.ID, ident.Tags{},
+							blockMetadata.Start(), hostMetadata.Size,
+							hostMetadata.Checksum, time.Time{}),
+						Host: hostMetadata.Host,
+					})
+				}
+			}
+		}
+	}
+	return metadatas
+}
+
+// missingSeriesTagsIter wraps a peer metadata iterator and captures the tags
+// of series that are not present locally, the tags are required to persist
+// series that the repair adds to the local replica.
+type missingSeriesTagsIter struct {
+	client.PeerBlockMetadataIter
+
+	localIDs map[string]struct{}
+	tags     map[string]ident.Tags
+	host     topology.Host
+	metadata block.Metadata
+}
+
+func newMissingSeriesTagsIter(
+	iter client.PeerBlockMetadataIter,
+	localIDs map[string]struct{},
+) *missingSeriesTagsIter {
+	return &missingSeriesTagsIter{
+		PeerBlockMetadataIter: iter,
+		localIDs:              localIDs,
+		tags:                  make(map[string]ident.Tags),
+	}
+}
+
+func (it *missingSeriesTagsIter) Next() bool {
+	if !it.PeerBlockMetadataIter.Next() {
+		return false
+	}
+	it.host, it.metadata = it.PeerBlockMetadataIter.Current()
+	id := it.metadata.ID.String()
+	if _, ok := it.localIDs[id]; ok {
+		return true
+	}
+	if _, ok := it.tags[id]; ok {
+		return true
+	}
+	// Copy the tags since they are only valid until the next call to Next.
+	values := it.metadata.Tags.Values()
+	tags := make([]ident.Tag, 0, len(values))
+	for _, tag := range values {
+		tags = append(tags, ident.StringTag(tag.Name.String(), tag.Value.String()))
+	}
+	it.tags[id] = ident.NewTags(tags...)
+	return true
+}
 
-	return metadataRes, nil
+func (it *missingSeriesTagsIter) Current() (topology.Host, block.Metadata) {
+	return it.host, it.metadata
 }
 
 func (r shardRepairer) recordDifferences(
@@ -37,6 +37,8 @@ const (
 	defaultRepairThrottle         = 90 * time.Second
 	defaultRepairMaxRetries       = 3
 	defaultRepairShardConcurrency = 1
+	defaultRepairDryRun           = false
+	defaultRepairMaxBlocksPerSec  = 1000
 )
 
 var (
@@ -49,6 +51,7 @@ var (
 	errRepairCheckIntervalTooBig    = errors.New("repair check interval too big in repair options")
 	errInvalidRepairThrottle        = errors.New("invalid repair throttle in repair options")
 	errInvalidRepairMaxRetries      = errors.New("invalid repair max retries in repair options")
+	errInvalidRepairMaxBlocksPerSec = errors.New("invalid repair max blocks per second in repair options")
 	errNoHostBlockMetadataSlicePool = errors.New("no host block metadata pool in repair options")
 )
 
@@ -62,6 +65,8 @@ type options struct {
 	repairCheckInterval        time.Duration
 	repairThrottle             time.Duration
 	repairMaxRetries           int
+	repairDryRun               bool
+	repairMaxBlocksPerSecond   int
 	hostBlockMetadataSlicePool HostBlockMetadataSlicePool
 }
 
@@ -76,6 +81,8 @@ func NewOptions() Options {
 		repairCheckInterval:        defaultRepairCheckInterval,
 		repairThrottle:             defaultRepairThrottle,
 		repairMaxRetries:           defaultRepairMaxRetries,
+		repairDryRun:               defaultRepairDryRun,
+		repairMaxBlocksPerSecond:   defaultRepairMaxBlocksPerSec,
 		hostBlockMetadataSlicePool: NewHostBlockMetadataSlicePool(nil, 0),
 	}
 }
@@ -170,6 +177,26 @@ func (o *options) RepairMaxRetries() int {
 	return o.repairMaxRetries
 }
 
+func (o *options) SetRepairDryRun(value bool) Options {
+	opts := *o
+	opts.repairDryRun = value
+	return &opts
+}
+
+func (o *options) RepairDryRun() bool {
+	return o.repairDryRun
+}
+
+func (o *options) SetRepairMaxBlocksPerSecond(value int) Options {
+	opts := *o
+	opts.repairMaxBlocksPerSecond = value
+	return &opts
+}
+
+func (o *options) RepairMaxBlocksPerSecond() int {
+	return o.repairMaxBlocksPerSecond
+}
+
 func (o *options) SetHostBlockMetadataSlicePool(value HostBlockMetadataSlicePool) Options {
 	opts := *o
 	opts.hostBlockMetadataSlicePool = value
@@ -208,6 +235,9 @@ func (o *options) Validate() error {
 	if o.repairMaxRetries < 0 {
 		return errInvalidRepairMaxRetries
 	}
+	if o.repairMaxBlocksPerSecond < 0 {
+		return errInvalidRepairMaxBlocksPerSec
+	}
 	if o.hostBlockMetadataSlicePool == nil {
 		return errNoHostBlockMetadataSlicePool
 	}
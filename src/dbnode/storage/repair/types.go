@@ -206,6 +206,22 @@ type Options interface {
 	// MaxRepairRetries returns the max number of retries for a block start
 	RepairMaxRetries() int
 
+	// SetRepairDryRun sets whether repairs only record differences between
+	// replicas rather than fetching and persisting the differing blocks
+	SetRepairDryRun(value bool) Options
+
+	// RepairDryRun returns whether repairs only record differences between
+	// replicas rather than fetching and persisting the differing blocks
+	RepairDryRun() bool
+
+	// SetRepairMaxBlocksPerSecond sets the max number of differing blocks to
+	// fetch from peers per second per shard, zero means unlimited
+	SetRepairMaxBlocksPerSecond(value int) Options
+
+	// RepairMaxBlocksPerSecond returns the max number of differing blocks to
+	// fetch from peers per second per shard, zero means unlimited
+	RepairMaxBlocksPerSecond() int
+
 	// SetHostBlockMetadataSlicePool sets the hostBlockMetadataSlice pool
 	SetHostBlockMetadataSlicePool(value HostBlockMetadataSlicePool) Options
 
@@ -29,6 +29,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/storage/block"
+	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/storage/repair"
 	"github.com/m3db/m3/src/dbnode/topology"
@@ -209,6 +210,14 @@ func TestDatabaseRepairerRepairNotBootstrapped(t *testing.T) {
 }
 
 func TestDatabaseShardRepairerRepair(t *testing.T) {
+	testDatabaseShardRepairerRepair(t, false)
+}
+
+func TestDatabaseShardRepairerRepairDryRun(t *testing.T) {
+	testDatabaseShardRepairerRepair(t, true)
+}
+
+func testDatabaseShardRepairerRepair(t *testing.T, dryRun bool) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -219,7 +228,9 @@ func TestDatabaseShardRepairerRepair(t *testing.T) {
 	mockClient := client.NewMockAdminClient(ctrl)
 	mockClient.EXPECT().DefaultAdminSession().Return(session, nil)
 
-	rpOpts := testRepairOptions(ctrl).SetAdminClient(mockClient)
+	rpOpts := testRepairOptions(ctrl).
+		SetAdminClient(mockClient).
+		SetRepairDryRun(dryRun)
 
 	now := time.Now()
 	nowFn := func() time.Time { return now }
@@ -232,7 +243,7 @@ func TestDatabaseShardRepairerRepair(t *testing.T) {
 		SetInstrumentOptions(iopts.SetMetricsScope(tally.NoopScope))
 
 	var (
-		namespace       = ident.StringID("testNamespace")
+		nsMeta, _       = namespace.NewMetadata(ident.StringID("testNamespace"), namespace.NewOptions())
 		start           = now
 		end             = now.Add(rtopts.BlockSize())
 		repairTimeRange = xtime.Range{Start: start, End: end}
@@ -291,10 +302,36 @@ func TestDatabaseShardRepairerRepair(t *testing.T) {
 		peerIter.EXPECT().Err().Return(nil),
 	)
 	session.EXPECT().
-		FetchBlocksMetadataFromPeers(namespace, shardID, start, end,
+		FetchBlocksMetadataFromPeers(nsMeta.ID(), shardID, start, end,
 			rpOpts.RepairConsistencyLevel(), gomock.Any()).
 		Return(peerIter, nil)
 
+	var fetchedMetadatas []block.ReplicaMetadata
+	if !dryRun {
+		// The block with a size difference is fetched from the peer and
+		// queued on the shard to be merged with the local replica.
+		repairedBlock := block.NewMockDatabaseBlock(ctrl)
+		blocksIter := client.NewMockPeerBlocksIter(ctrl)
+		gomock.InOrder(
+			blocksIter.EXPECT().Next().Return(true),
+			blocksIter.EXPECT().Current().
+				Return(inputBlocks[1].host, ident.StringID("foo"), repairedBlock),
+			blocksIter.EXPECT().Next().Return(false),
+			blocksIter.EXPECT().Err().Return(nil),
+		)
+		session.EXPECT().
+			FetchBlocksFromPeers(nsMeta, shardID, rpOpts.RepairConsistencyLevel(),
+				gomock.Any(), gomock.Any()).
+			Do(func(_ namespace.Metadata, _ uint32, _ topology.ReadConsistencyLevel,
+				metadatas []block.ReplicaMetadata, _ result.Options) {
+				fetchedMetadatas = metadatas
+			}).
+			Return(blocksIter, nil)
+		shard.EXPECT().
+			AddRepairedBlock(ident.NewIDMatcher("foo"), ident.Tags{}, repairedBlock).
+			Return(nil)
+	}
+
 	var (
 		resNamespace ident.ID
 		resShard     databaseShard
@@ -310,8 +347,9 @@ func TestDatabaseShardRepairerRepair(t *testing.T) {
 	}
 
 	ctx := context.NewContext()
-	repairer.Repair(ctx, namespace, repairTimeRange, shard)
-	require.Equal(t, namespace, resNamespace)
+	_, err := repairer.Repair(ctx, nsMeta, repairTimeRange, shard)
+	require.NoError(t, err)
+	require.Equal(t, nsMeta.ID(), resNamespace)
 	require.Equal(t, resShard, shard)
 	require.Equal(t, int64(2), resDiff.NumSeries)
 	require.Equal(t, int64(3), resDiff.NumBlocks)
@@ -330,6 +368,80 @@ func TestDatabaseShardRepairerRepair(t *testing.T) {
 		{Host: topology.NewHost("1", "addr1"), Size: sizes[0], Checksum: &checksums[1]},
 	}
 	require.Equal(t, expected, block.Metadata())
+
+	if dryRun {
+		require.Equal(t, 0, len(fetchedMetadatas))
+		return
+	}
+	require.Equal(t, 1, len(fetchedMetadatas))
+	require.Equal(t, "foo", fetchedMetadatas[0].ID.String())
+	require.Equal(t, now.Add(time.Hour), fetchedMetadatas[0].Start)
+	require.Equal(t, sizes[0], fetchedMetadatas[0].Size)
+	require.Equal(t, checksums[1], *fetchedMetadatas[0].Checksum)
+	require.Equal(t, "1", fetchedMetadatas[0].Host.ID())
+}
+
+func TestDatabaseShardRepairerRepairDifferencesRateLimited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	session := client.NewMockAdminSession(ctrl)
+	rpOpts := testRepairOptions(ctrl).SetRepairMaxBlocksPerSecond(2)
+
+	now := time.Now()
+	opts := testDatabaseOptions()
+	opts = opts.
+		SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time { return now })).
+		SetInstrumentOptions(opts.InstrumentOptions().SetMetricsScope(tally.NoopScope))
+
+	nsMeta, err := namespace.NewMetadata(ident.StringID("testNamespace"), namespace.NewOptions())
+	require.NoError(t, err)
+
+	shard := NewMockdatabaseShard(ctrl)
+	shard.EXPECT().ID().Return(uint32(0)).AnyTimes()
+
+	// Five series with a checksum difference against a single peer.
+	var (
+		origin    = topology.NewHost("0", "addr0")
+		peer      = topology.NewHost("1", "addr1")
+		checksums = []uint32{1, 2}
+		slicePool = repair.NewHostBlockMetadataSlicePool(nil, 2)
+		diffs     = repair.NewReplicaSeriesMetadata()
+	)
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		blockMetadata := repair.NewReplicaBlockMetadata(now, slicePool.Get())
+		blockMetadata.Add(repair.HostBlockMetadata{Host: origin, Size: 1, Checksum: &checksums[0]})
+		blockMetadata.Add(repair.HostBlockMetadata{Host: peer, Size: 1, Checksum: &checksums[1]})
+		diffs.GetOrAdd(ident.StringID(id)).Add(blockMetadata)
+	}
+	diffRes := repair.MetadataComparisonResult{
+		SizeDifferences:     repair.NewReplicaSeriesMetadata(),
+		ChecksumDifferences: diffs,
+	}
+
+	var batchSizes []int
+	session.EXPECT().
+		FetchBlocksFromPeers(nsMeta, uint32(0), rpOpts.RepairConsistencyLevel(),
+			gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ namespace.Metadata, _ uint32, _ topology.ReadConsistencyLevel,
+			metadatas []block.ReplicaMetadata, _ result.Options) (client.PeerBlocksIter, error) {
+			batchSizes = append(batchSizes, len(metadatas))
+			iter := client.NewMockPeerBlocksIter(ctrl)
+			iter.EXPECT().Next().Return(false)
+			iter.EXPECT().Err().Return(nil)
+			return iter, nil
+		}).
+		Times(3)
+
+	repairer := newShardRepairer(opts, rpOpts).(shardRepairer)
+	var slept []time.Duration
+	repairer.sleepFn = func(d time.Duration) {
+		slept = append(slept, d)
+	}
+
+	require.NoError(t, repairer.repairDifferences(session, nsMeta, shard, origin, diffRes, nil))
+	require.Equal(t, []int{2, 2, 1}, batchSizes)
+	require.Equal(t, []time.Duration{time.Second, time.Second}, slept)
 }
 
 func TestRepairerRepairTimes(t *testing.T) {
@@ -28,6 +28,7 @@ import (
 
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/ts"
@@ -85,6 +86,18 @@ type databaseBuffer interface {
 		opts FetchBlocksMetadataOptions,
 	) block.FetchBlockMetadataResults
 
+	// ColdStreams returns the streams of the cold writes buffered for a
+	// block start that has been drained from the buffer.
+	ColdStreams(ctx context.Context, blockStart time.Time) []xio.BlockReader
+
+	// FetchColdBlocks returns a copy of the cold writes buffered for each
+	// block start accepted by the filter.
+	FetchColdBlocks(filter ColdBlockFilter) ([]ColdBlock, error)
+
+	// ReleaseColdBlock removes and returns the cold writes buffered for a
+	// block start if no more have been written since the given version.
+	ReleaseColdBlock(blockStart time.Time, version int) (block.DatabaseBlock, bool, error)
+
 	IsEmpty() bool
 
 	Stats() bufferStats
@@ -103,6 +116,10 @@ type databaseBuffer interface {
 	Bootstrap(bl block.DatabaseBlock) error
 
 	Reset(opts Options)
+
+	// SetOptions updates the options of the buffer without resetting its
+	// buckets, the block size of the new options must match the buffer's.
+	SetOptions(opts Options)
 }
 
 type bufferStats struct {
@@ -127,6 +144,11 @@ type dbBuffer struct {
 	blockSize         time.Duration
 	bufferPast        time.Duration
 	bufferFuture      time.Duration
+
+	// coldBuckets hold writes for blocks that have already been drained
+	// from the buffer, they are kept until merged into the flushed block
+	// by a cold flush.
+	coldBuckets map[xtime.UnixNano]*dbBufferBucket
 }
 
 type databaseBufferDrainFn func(b block.DatabaseBlock)
@@ -149,6 +171,23 @@ func (b *dbBuffer) Reset(opts Options) {
 	b.bufferFuture = ropts.BufferFuture()
 	// Avoid capturing any variables with callback
 	b.computedForEachBucketAsc(computeAndResetBucketIdx, bucketResetStart)
+	for _, bucket := range b.coldBuckets {
+		bucket.finalize()
+	}
+	b.coldBuckets = nil
+}
+
+func (b *dbBuffer) SetOptions(opts Options) {
+	b.opts = opts
+	ropts := opts.RetentionOptions()
+	b.bufferPast = ropts.BufferPast()
+	b.bufferFuture = ropts.BufferFuture()
+	for i := range b.buckets {
+		b.buckets[i].opts = opts
+	}
+	for _, bucket := range b.coldBuckets {
+		bucket.opts = opts
+	}
 }
 
 func bucketResetStart(now time.Time, b *dbBuffer, idx int, start time.Time) int {
@@ -189,7 +228,15 @@ func (b *dbBuffer) Write(
 		return m3dberrors.ErrTooFuture
 	}
 	if !pastLimit.Before(timestamp) {
-		return m3dberrors.ErrTooPast
+		if !b.opts.ColdWritesEnabled() {
+			return m3dberrors.ErrTooPast
+		}
+		blockStart := timestamp.Truncate(b.blockSize)
+		if !blockStart.Add(b.blockSize).After(pastLimit) {
+			return b.writeCold(now, blockStart, timestamp, value, unit, annotation)
+		}
+		// The block is not yet eligible to be flushed so the write can be
+		// buffered alongside the other writes for the block.
 	}
 
 	bucketStart := timestamp.Truncate(b.blockSize)
@@ -202,6 +249,46 @@ func (b *dbBuffer) Write(
 	return b.buckets[idx].write(timestamp, value, unit, annotation)
 }
 
+// writeCold buffers a write for a block that is eligible to be flushed, or
+// has already been flushed, in a cold bucket for the block.
+func (b *dbBuffer) writeCold(
+	now time.Time,
+	blockStart time.Time,
+	timestamp time.Time,
+	value float64,
+	unit xtime.Unit,
+	annotation []byte,
+) error {
+	if blockStart.Before(retention.FlushTimeStart(b.opts.RetentionOptions(), now)) {
+		return m3dberrors.ErrTooPast
+	}
+
+	// If the block has not been drained from the buffer yet then the write
+	// is drained and flushed along with the rest of the block.
+	for i := range b.buckets {
+		if b.buckets[i].start.Equal(blockStart) && b.buckets[i].canRead() {
+			return b.buckets[i].write(timestamp, value, unit, annotation)
+		}
+	}
+
+	if b.coldBuckets == nil {
+		b.coldBuckets = make(map[xtime.UnixNano]*dbBufferBucket)
+	}
+	key := xtime.ToUnixNano(blockStart)
+	bucket, ok := b.coldBuckets[key]
+	if !ok {
+		bucket = &dbBufferBucket{opts: b.opts}
+		bucket.resetTo(blockStart)
+		b.coldBuckets[key] = bucket
+	}
+
+	if err := bucket.write(timestamp, value, unit, annotation); err != nil {
+		return err
+	}
+	bucket.version++
+	return nil
+}
+
 func (b *dbBuffer) writableBucketIdx(t time.Time) int {
 	return int(t.Truncate(b.blockSize).UnixNano() / int64(b.blockSize) % bucketsLen)
 }
@@ -211,6 +298,9 @@ func (b *dbBuffer) IsEmpty() bool {
 	for i := range b.buckets {
 		canReadAny = canReadAny || b.buckets[i].canRead()
 	}
+	for _, bucket := range b.coldBuckets {
+		canReadAny = canReadAny || bucket.canRead()
+	}
 	return !canReadAny
 }
 
@@ -226,6 +316,11 @@ func (b *dbBuffer) Stats() bufferStats {
 		}
 		stats.wiredBlocks++
 	}
+	for _, bucket := range b.coldBuckets {
+		if bucket.canRead() {
+			stats.wiredBlocks++
+		}
+	}
 	return stats
 }
 
@@ -244,11 +339,42 @@ func bucketNeedsDrain(now time.Time, b *dbBuffer, idx int, start time.Time) int
 func (b *dbBuffer) Tick() bufferTickResult {
 	// Avoid capturing any variables with callback
 	mergedOutOfOrder := b.computedForEachBucketAsc(computeAndResetBucketIdx, bucketTick)
+	mergedOutOfOrder += b.tickColdBuckets()
 	return bufferTickResult{
 		mergedOutOfOrderBlocks: mergedOutOfOrder,
 	}
 }
 
+// tickColdBuckets removes cold buckets that have fallen out of retention and
+// merges the out of order encoders of the remaining ones.
+func (b *dbBuffer) tickColdBuckets() int {
+	if len(b.coldBuckets) == 0 {
+		return 0
+	}
+
+	var (
+		mergedOutOfOrderBlocks int
+		earliest               = retention.FlushTimeStart(b.opts.RetentionOptions(), b.nowFn())
+	)
+	for key, bucket := range b.coldBuckets {
+		if key.ToTime().Before(earliest) {
+			bucket.finalize()
+			delete(b.coldBuckets, key)
+			continue
+		}
+
+		r, err := bucket.merge()
+		if err != nil {
+			log := b.opts.InstrumentOptions().Logger()
+			log.Errorf("buffer cold bucket merge encode error: %v", err)
+		}
+		if r.merges > 0 {
+			mergedOutOfOrderBlocks++
+		}
+	}
+	return mergedOutOfOrderBlocks
+}
+
 func bucketTick(now time.Time, b *dbBuffer, idx int, start time.Time) int {
 	// Perform a drain and reset if necessary
 	mergedOutOfOrderBlocks := bucketDrainAndReset(now, b, idx, start)
@@ -474,35 +600,107 @@ func (b *dbBuffer) FetchBlocksMetadata(
 	blockSize := b.opts.RetentionOptions().BlockSize()
 	res := b.opts.FetchBlockMetadataResultsPool().Get()
 	b.forEachBucketAsc(func(bucket *dbBufferBucket) {
-		if !bucket.canRead() {
-			return
-		}
-		if !start.Before(bucket.start.Add(blockSize)) || !bucket.start.Before(end) {
-			return
-		}
-		size := int64(bucket.streamsLen())
-		// If we have no data in this bucket, return early without appending it to the result.
-		if size == 0 {
-			return
+		b.appendBucketMetadata(bucket, start, end, blockSize, opts, res)
+	})
+	for _, bucket := range b.coldBuckets {
+		b.appendBucketMetadata(bucket, start, end, blockSize, opts, res)
+	}
+
+	return res
+}
+
+func (b *dbBuffer) appendBucketMetadata(
+	bucket *dbBufferBucket,
+	start, end time.Time,
+	blockSize time.Duration,
+	opts FetchBlocksMetadataOptions,
+	res block.FetchBlockMetadataResults,
+) {
+	if !bucket.canRead() {
+		return
+	}
+	if !start.Before(bucket.start.Add(blockSize)) || !bucket.start.Before(end) {
+		return
+	}
+	size := int64(bucket.streamsLen())
+	// If we have no data in this bucket, return early without appending it to the result.
+	if size == 0 {
+		return
+	}
+	var resultSize int64
+	if opts.IncludeSizes {
+		resultSize = size
+	}
+	var resultLastRead time.Time
+	if opts.IncludeLastRead {
+		resultLastRead = bucket.lastRead()
+	}
+	// NB(r): Ignore if opts.IncludeChecksum because we avoid
+	// calculating checksum since block is open and is being mutated
+	res.Add(block.FetchBlockMetadataResult{
+		Start:    bucket.start,
+		Size:     resultSize,
+		LastRead: resultLastRead,
+	})
+}
+
+func (b *dbBuffer) ColdStreams(ctx context.Context, blockStart time.Time) []xio.BlockReader {
+	bucket, ok := b.coldBuckets[xtime.ToUnixNano(blockStart)]
+	if !ok || !bucket.canRead() {
+		return nil
+	}
+	return bucket.streams(ctx)
+}
+
+func (b *dbBuffer) FetchColdBlocks(filter ColdBlockFilter) ([]ColdBlock, error) {
+	var res []ColdBlock
+	for _, bucket := range b.coldBuckets {
+		if !bucket.canRead() || !filter(bucket.start) {
+			continue
 		}
-		var resultSize int64
-		if opts.IncludeSizes {
-			resultSize = size
+
+		copied, err := bucket.copyBlock()
+		if err != nil {
+			for _, coldBlock := range res {
+				coldBlock.Block.Close()
+			}
+			return nil, err
 		}
-		var resultLastRead time.Time
-		if opts.IncludeLastRead {
-			resultLastRead = bucket.lastRead()
+		if copied == nil {
+			continue
 		}
-		// NB(r): Ignore if opts.IncludeChecksum because we avoid
-		// calculating checksum since block is open and is being mutated
-		res.Add(block.FetchBlockMetadataResult{
-			Start:    bucket.start,
-			Size:     resultSize,
-			LastRead: resultLastRead,
+
+		res = append(res, ColdBlock{
+			Block:   copied,
+			Version: bucket.version,
 		})
-	})
+	}
+	return res, nil
+}
 
-	return res
+func (b *dbBuffer) ReleaseColdBlock(
+	blockStart time.Time,
+	version int,
+) (block.DatabaseBlock, bool, error) {
+	key := xtime.ToUnixNano(blockStart)
+	bucket, ok := b.coldBuckets[key]
+	if !ok || bucket.version != version {
+		// Cold writes arrived since the copy was flushed, keep them all
+		// buffered until the next cold flush.
+		return nil, false, nil
+	}
+
+	delete(b.coldBuckets, key)
+	if !bucket.canRead() {
+		bucket.finalize()
+		return nil, false, nil
+	}
+
+	result, err := bucket.discardMerged()
+	if err != nil {
+		return nil, false, err
+	}
+	return result.block, true, nil
 }
 
 type dbBufferBucket struct {
@@ -512,6 +710,8 @@ type dbBufferBucket struct {
 	bootstrapped      []block.DatabaseBlock
 	lastReadUnixNanos int64
 	drained           bool
+	// version is incremented with each cold write to the bucket.
+	version int
 }
 
 type inOrderEncoder struct {
@@ -536,6 +736,7 @@ func (b *dbBufferBucket) resetTo(
 	b.bootstrapped = nil
 	atomic.StoreInt64(&b.lastReadUnixNanos, 0)
 	b.drained = false
+	b.version = 0
 }
 
 func (b *dbBufferBucket) finalize() {
@@ -829,6 +1030,36 @@ func (b *dbBufferBucket) merge() (mergeResult, error) {
 	return mergeResult{merges: merges}, nil
 }
 
+// copyBlock returns a block holding a copy of the data in the bucket, the
+// bucket itself is left untouched other than merging its encoders.
+func (b *dbBufferBucket) copyBlock() (block.DatabaseBlock, error) {
+	if _, err := b.merge(); err != nil {
+		return nil, err
+	}
+
+	var stream xio.SegmentReader
+	switch {
+	case len(b.encoders) > 0:
+		stream = b.encoders[0].encoder.Stream()
+	case len(b.bootstrapped) > 0:
+		return nil, errMoreThanOneStreamAfterMerge
+	}
+	if stream == nil {
+		return nil, nil
+	}
+
+	// NB: The encoder stream holds a copy of the encoded data, the new block
+	// takes ownership of the copy so the reader must not be finalized.
+	segment, err := stream.Segment()
+	if err != nil {
+		return nil, err
+	}
+
+	newBlock := b.opts.DatabaseBlockOptions().DatabaseBlockPool().Get()
+	newBlock.Reset(b.start, b.opts.RetentionOptions().BlockSize(), segment)
+	return newBlock, nil
+}
+
 type discardMergedResult struct {
 	block  block.DatabaseBlock
 	merges int
@@ -103,6 +103,111 @@ func TestBufferWriteTooPast(t *testing.T) {
 	assert.True(t, xerrors.IsInvalidParams(err))
 }
 
+func TestBufferSetOptionsUpdatesBufferPast(t *testing.T) {
+	opts := newBufferTestOptions()
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer(nil).(*dbBuffer)
+	buffer.Reset(opts)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	past := curr.Add(-1 * rops.BufferPast())
+	err := buffer.Write(ctx, past, 1, xtime.Second, nil)
+	assert.Error(t, err)
+
+	buffer.SetOptions(opts.SetRetentionOptions(
+		rops.SetBufferPast(2 * rops.BufferPast())))
+	assert.NoError(t, buffer.Write(ctx, past, 1, xtime.Second, nil))
+	for i := range buffer.buckets {
+		assert.Equal(t, 2*rops.BufferPast(),
+			buffer.buckets[i].opts.RetentionOptions().BufferPast())
+	}
+}
+
+func TestBufferWriteColdWritesEnabled(t *testing.T) {
+	opts := newBufferTestOptions().SetColdWritesEnabled(true)
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer(nil).(*dbBuffer)
+	buffer.Reset(opts)
+
+	blockStart := curr.Add(-5 * rops.BlockSize())
+	data := []value{
+		{blockStart.Add(secs(1)), 1, xtime.Second, nil},
+		{blockStart.Add(secs(2)), 2, xtime.Second, nil},
+	}
+
+	for _, v := range data {
+		ctx := context.NewContext()
+		assert.NoError(t, buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation))
+		ctx.Close()
+	}
+
+	// Writes beyond retention are still rejected.
+	ctx := context.NewContext()
+	defer ctx.Close()
+	err := buffer.Write(ctx, curr.Add(-2*rops.RetentionPeriod()), 1, xtime.Second, nil)
+	assert.Error(t, err)
+	assert.True(t, xerrors.IsInvalidParams(err))
+
+	assert.False(t, buffer.IsEmpty())
+	assert.Nil(t, buffer.ColdStreams(ctx, curr))
+
+	results := [][]xio.BlockReader{buffer.ColdStreams(ctx, blockStart)}
+	assertValuesEqual(t, data, results, opts)
+}
+
+func TestBufferFetchAndReleaseColdBlock(t *testing.T) {
+	opts := newBufferTestOptions().SetColdWritesEnabled(true)
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer(nil).(*dbBuffer)
+	buffer.Reset(opts)
+
+	blockStart := curr.Add(-5 * rops.BlockSize())
+	ctx := context.NewContext()
+	defer ctx.Close()
+	require.NoError(t, buffer.Write(ctx, blockStart.Add(secs(1)), 1, xtime.Second, nil))
+
+	filtered, err := buffer.FetchColdBlocks(func(time.Time) bool { return false })
+	require.NoError(t, err)
+	require.Len(t, filtered, 0)
+
+	coldBlocks, err := buffer.FetchColdBlocks(func(time.Time) bool { return true })
+	require.NoError(t, err)
+	require.Len(t, coldBlocks, 1)
+	require.Equal(t, blockStart, coldBlocks[0].Block.StartTime())
+	require.Equal(t, 1, coldBlocks[0].Version)
+	coldBlocks[0].Block.Close()
+
+	// A write that arrives after the copy was taken keeps the bucket buffered.
+	require.NoError(t, buffer.Write(ctx, blockStart.Add(secs(2)), 2, xtime.Second, nil))
+	released, ok, err := buffer.ReleaseColdBlock(blockStart, coldBlocks[0].Version)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Nil(t, released)
+	require.NotNil(t, buffer.ColdStreams(ctx, blockStart))
+
+	released, ok, err = buffer.ReleaseColdBlock(blockStart, 2)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NotNil(t, released)
+	released.Close()
+	require.Nil(t, buffer.ColdStreams(ctx, blockStart))
+	require.True(t, buffer.IsEmpty())
+}
+
 func TestBufferWriteRead(t *testing.T) {
 	opts := newBufferTestOptions()
 	rops := opts.RetentionOptions()
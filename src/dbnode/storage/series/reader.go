@@ -115,42 +115,30 @@ func (r Reader) readersWithBlocksMapAndBuffer(
 		alignedEnd = latest
 	}
 
+	// NB: Blocks held by compacted filesets are retrieved as the whole range
+	// of the fileset, so there is no need to retrieve the other blocks it
+	// spans once one of them has been.
+	var retrievedUntil time.Time
 	first, last := alignedStart, alignedEnd
 	for blockAt := first; !blockAt.After(last); blockAt = blockAt.Add(size) {
-		if seriesBlocks != nil {
-			if block, ok := seriesBlocks.BlockAt(blockAt); ok {
-				// Block served from in-memory or in-memory metadata
-				// will defer to disk read
-				streamedBlock, err := block.Stream(ctx)
-				if err != nil {
-					return nil, err
-				}
-				if streamedBlock.IsNotEmpty() {
-					results = append(results, []xio.BlockReader{streamedBlock})
-					// NB(r): Mark this block as read now
-					block.SetLastReadTime(now)
-					if r.onRead != nil {
-						r.onRead.OnReadBlock(block)
-					}
-				}
-				continue
+		blockResults, err := r.readersForBlock(ctx, blockAt, now, cachePolicy,
+			seriesBlocks, retrievedUntil)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range blockResults {
+			if end := result.Start.Add(result.BlockSize); end.After(retrievedUntil) {
+				retrievedUntil = end
 			}
 		}
 
-		switch {
-		case cachePolicy == CacheAll:
-			// No-op, block metadata should have been in-memory
-		case r.retriever != nil:
-			// Try to stream from disk
-			if r.retriever.IsBlockRetrievable(blockAt) {
-				streamedBlock, err := r.retriever.Stream(ctx, r.id, blockAt, r.onRetrieve)
-				if err != nil {
-					return nil, err
-				}
-				if streamedBlock.IsNotEmpty() {
-					results = append(results, []xio.BlockReader{streamedBlock})
-				}
-			}
+		if seriesBuffer != nil {
+			// Cold writes for the block are merged with the block data.
+			blockResults = append(blockResults, seriesBuffer.ColdStreams(ctx, blockAt)...)
+		}
+
+		if len(blockResults) > 0 {
+			results = append(results, blockResults)
 		}
 	}
 
@@ -164,6 +152,53 @@ func (r Reader) readersWithBlocksMapAndBuffer(
 	return results, nil
 }
 
+func (r Reader) readersForBlock(
+	ctx context.Context,
+	blockAt time.Time,
+	now time.Time,
+	cachePolicy CachePolicy,
+	seriesBlocks block.DatabaseSeriesBlocks,
+	retrievedUntil time.Time,
+) ([]xio.BlockReader, error) {
+	if seriesBlocks != nil {
+		if block, ok := seriesBlocks.BlockAt(blockAt); ok {
+			// Block served from in-memory or in-memory metadata
+			// will defer to disk read
+			streamedBlock, err := block.Stream(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if !streamedBlock.IsNotEmpty() {
+				return nil, nil
+			}
+			// NB(r): Mark this block as read now
+			block.SetLastReadTime(now)
+			if r.onRead != nil {
+				r.onRead.OnReadBlock(block)
+			}
+			return []xio.BlockReader{streamedBlock}, nil
+		}
+	}
+
+	switch {
+	case cachePolicy == CacheAll:
+		// No-op, block metadata should have been in-memory
+	case r.retriever != nil && !blockAt.Before(retrievedUntil):
+		// Try to stream from disk
+		if r.retriever.IsBlockRetrievable(blockAt) {
+			streamedBlock, err := r.retriever.Stream(ctx, r.id, blockAt, r.onRetrieve)
+			if err != nil {
+				return nil, err
+			}
+			if streamedBlock.IsNotEmpty() {
+				return []xio.BlockReader{streamedBlock}, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
 // FetchBlocks returns data blocks given a list of block start times using
 // just a block retriever.
 func (r Reader) FetchBlocks(
@@ -189,8 +224,18 @@ func (r Reader) fetchBlocksWithBlocksMapAndBuffer(
 		// eventually cache all series in memory when we stream results to a
 		// peer.
 		onRetrieve block.OnRetrieveBlock
+		// NB: Blocks held by compacted filesets are retrieved as the whole
+		// range of the fileset, so there is no need to retrieve the other
+		// blocks it spans once one of them has been.
+		retrieved []xio.BlockReader
 	)
 	for _, start := range starts {
+		var coldStreams []xio.BlockReader
+		if seriesBuffer != nil {
+			// Cold writes for the block are returned along with the block data.
+			coldStreams = seriesBuffer.ColdStreams(ctx, start)
+		}
+
 		if seriesBlocks != nil {
 			if b, exists := seriesBlocks.BlockAt(start); exists {
 				streamedBlock, err := b.Stream(ctx)
@@ -201,9 +246,11 @@ func (r Reader) fetchBlocksWithBlocksMapAndBuffer(
 					res = append(res, r)
 				}
 				if streamedBlock.IsNotEmpty() {
-					b := []xio.BlockReader{streamedBlock}
+					b := append([]xio.BlockReader{streamedBlock}, coldStreams...)
 					r := block.NewFetchBlockResult(start, b, nil)
 					res = append(res, r)
+				} else if len(coldStreams) > 0 {
+					res = append(res, block.NewFetchBlockResult(start, coldStreams, nil))
 				}
 				continue
 			}
@@ -211,7 +258,7 @@ func (r Reader) fetchBlocksWithBlocksMapAndBuffer(
 		switch {
 		case cachePolicy == CacheAll:
 			// No-op, block metadata should have been in-memory
-		case r.retriever != nil:
+		case r.retriever != nil && !retrievedBlockAt(retrieved, start):
 			// Try to stream from disk
 			if r.retriever.IsBlockRetrievable(start) {
 				streamedBlock, err := r.retriever.Stream(ctx, r.id, start, onRetrieve)
@@ -222,12 +269,17 @@ func (r Reader) fetchBlocksWithBlocksMapAndBuffer(
 					res = append(res, r)
 				}
 				if streamedBlock.IsNotEmpty() {
-					b := []xio.BlockReader{streamedBlock}
+					retrieved = append(retrieved, streamedBlock)
+					b := append([]xio.BlockReader{streamedBlock}, coldStreams...)
 					r := block.NewFetchBlockResult(start, b, nil)
 					res = append(res, r)
+					continue
 				}
 			}
 		}
+		if len(coldStreams) > 0 {
+			res = append(res, block.NewFetchBlockResult(start, coldStreams, nil))
+		}
 	}
 
 	if seriesBuffer != nil && !seriesBuffer.IsEmpty() {
@@ -239,3 +291,15 @@ func (r Reader) fetchBlocksWithBlocksMapAndBuffer(
 
 	return res, nil
 }
+
+// retrievedBlockAt returns whether the block with the given start is held by
+// one of the retrieved blocks, which is the case for blocks held by a
+// compacted fileset once another block it spans has been retrieved.
+func retrievedBlockAt(retrieved []xio.BlockReader, start time.Time) bool {
+	for _, b := range retrieved {
+		if !start.Before(b.Start) && start.Before(b.Start.Add(b.BlockSize)) {
+			return true
+		}
+	}
+	return false
+}
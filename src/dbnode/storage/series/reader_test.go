@@ -144,3 +144,43 @@ func TestReaderUsingRetrieverFetchBlocks(t *testing.T) {
 		assert.Equal(t, blockReaders[i], br)
 	}
 }
+
+func TestReaderUsingRetrieverFetchBlocksCompacted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSeriesTestOptions()
+	ropts := opts.RetentionOptions()
+
+	end := opts.ClockOptions().NowFn()().Truncate(ropts.BlockSize())
+	start := end.Add(-2 * ropts.BlockSize())
+
+	// Both blocks are held by a compacted fileset, which is retrieved once.
+	compacted := xio.BlockReader{
+		SegmentReader: xio.NewMockSegmentReader(ctrl),
+		Start:         start,
+		BlockSize:     2 * ropts.BlockSize(),
+	}
+
+	ctx := opts.ContextPool().Get()
+	defer ctx.Close()
+
+	retriever := NewMockQueryableBlockRetriever(ctrl)
+	retriever.EXPECT().IsBlockRetrievable(start).Return(true)
+	retriever.EXPECT().
+		Stream(ctx, ident.NewIDMatcher("foo"), start, nil).
+		Return(compacted, nil)
+
+	reader := NewReaderUsingRetriever(
+		ident.StringID("foo"), retriever, nil, nil, opts)
+
+	r, err := reader.FetchBlocks(ctx, []time.Time{
+		start,
+		start.Add(ropts.BlockSize()),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(r))
+	assert.Equal(t, start, r[0].Start)
+	require.Equal(t, 1, len(r[0].Blocks))
+	assert.Equal(t, compacted, r[0].Blocks[0])
+}
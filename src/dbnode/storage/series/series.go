@@ -676,6 +676,52 @@ func (s *dbSeries) Snapshot(
 	return persistFn(s.id, s.tags, segment, digest.SegmentChecksum(segment))
 }
 
+func (s *dbSeries) FetchColdBlocks(filter ColdBlockFilter) ([]ColdBlock, error) {
+	// Need a write lock because fetching the cold blocks mutates the
+	// buffer state (by performing a pro-active merge).
+	s.Lock()
+	defer s.Unlock()
+
+	if s.bs != bootstrapped {
+		return nil, errSeriesNotBootstrapped
+	}
+
+	return s.buffer.FetchColdBlocks(filter)
+}
+
+func (s *dbSeries) ColdBlockFlushed(blockStart time.Time, version int) error {
+	s.Lock()
+	defer s.Unlock()
+
+	flushed, ok, err := s.buffer.ReleaseColdBlock(blockStart, version)
+	if err != nil || !ok {
+		return err
+	}
+
+	existing, exists := s.blocks.BlockAt(blockStart)
+	switch {
+	case !exists:
+		flushed.Close()
+	case existing.WasRetrievedFromDisk():
+		// The cached block does not contain the cold writes, remove it so
+		// that it is retrieved from the rewritten volume next time it's read.
+		// If using the LRU policy the WiredList is responsible for closing it.
+		s.blocks.RemoveBlockAt(blockStart)
+		if s.opts.CachePolicy() != CacheLRU {
+			existing.Close()
+		}
+		flushed.Close()
+	default:
+		// Blocks that are held in memory are never read from disk again, so
+		// the cold writes need to be merged into the in-memory block too.
+		if err := existing.Merge(flushed); err != nil {
+			flushed.Close()
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *dbSeries) Close() {
 	s.Lock()
 	defer s.Unlock()
@@ -709,6 +755,13 @@ func (s *dbSeries) Close() {
 	}
 }
 
+func (s *dbSeries) UpdateOptions(opts Options) {
+	s.Lock()
+	s.opts = opts
+	s.buffer.SetOptions(opts)
+	s.Unlock()
+}
+
 func (s *dbSeries) Reset(
 	id ident.ID,
 	tags ident.Tags,
@@ -550,6 +550,7 @@ func TestSeriesFetchBlocks(t *testing.T) {
 	// Set up the buffer
 	buffer := NewMockdatabaseBuffer(ctrl)
 	buffer.EXPECT().IsEmpty().Return(false)
+	buffer.EXPECT().ColdStreams(ctx, gomock.Any()).Return(nil).AnyTimes()
 	buffer.EXPECT().
 		FetchBlocks(ctx, starts).
 		Return([]block.FetchBlockResult{block.NewFetchBlockResult(starts[2], nil, nil)})
@@ -98,6 +98,19 @@ type DatabaseSeries interface {
 	// not been rotated into a block yet
 	Snapshot(ctx context.Context, blockStart time.Time, persistFn persist.DataFn) error
 
+	// FetchColdBlocks returns a copy of the cold writes buffered for each
+	// block start accepted by the filter
+	FetchColdBlocks(filter ColdBlockFilter) ([]ColdBlock, error)
+
+	// ColdBlockFlushed releases the cold writes for a block start once they
+	// have been merged into the flushed block, cold writes are retained if
+	// any more have been written since the version that was flushed
+	ColdBlockFlushed(blockStart time.Time, version int) error
+
+	// UpdateOptions updates the options of the series in place, the block
+	// size of the new options must match the block size of the series
+	UpdateOptions(opts Options)
+
 	// Close will close the series and if pooled returned to the pool
 	Close()
 
@@ -112,6 +125,17 @@ type DatabaseSeries interface {
 	)
 }
 
+// ColdBlockFilter returns whether the cold writes for a block start should
+// be fetched.
+type ColdBlockFilter func(blockStart time.Time) bool
+
+// ColdBlock is a copy of the cold writes buffered for a block start, along
+// with the version of the cold writes the copy was taken at.
+type ColdBlock struct {
+	Block   block.DatabaseBlock
+	Version int
+}
+
 // FetchBlocksMetadataOptions encapsulates block fetch metadata options
 // and specifies a few series specific options too.
 type FetchBlocksMetadataOptions struct {
@@ -226,6 +250,14 @@ type Options interface {
 	// CachePolicy returns the series cache policy
 	CachePolicy() CachePolicy
 
+	// SetColdWritesEnabled sets whether writes older than buffer past are
+	// accepted and buffered until they are merged into flushed blocks
+	SetColdWritesEnabled(value bool) Options
+
+	// ColdWritesEnabled returns whether writes older than buffer past are
+	// accepted and buffered until they are merged into flushed blocks
+	ColdWritesEnabled() bool
+
 	// SetContextPool sets the contextPool
 	SetContextPool(value context.Pool) Options
 
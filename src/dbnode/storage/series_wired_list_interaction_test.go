@@ -86,10 +86,10 @@ func TestSeriesWiredListConcurrentInteractions(t *testing.T) {
 		)
 		shard  = testDatabaseShard(t, opts)
 		id     = ident.StringID("foo")
-		series = series.NewDatabaseSeries(id, ident.Tags{}, shard.seriesOpts)
+		series = series.NewDatabaseSeries(id, ident.Tags{}, shard.seriesOptions())
 	)
 
-	series.Reset(id, ident.Tags{}, nil, shard.seriesOnRetrieveBlock, shard, shard.seriesOpts)
+	series.Reset(id, ident.Tags{}, nil, shard.seriesOnRetrieveBlock, shard, shard.seriesOptions())
 	series.Bootstrap(nil)
 	shard.Lock()
 	shard.insertNewShardEntryWithLock(lookup.NewEntry(series, 0))
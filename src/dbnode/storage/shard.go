@@ -31,6 +31,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/clock"
+	"github.com/m3db/m3/src/dbnode/digest"
 	"github.com/m3db/m3/src/dbnode/generated/proto/pagetoken"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
@@ -47,14 +48,17 @@ import (
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/m3ninx/doc"
+	"github.com/m3db/m3x/checked"
 	xclose "github.com/m3db/m3x/close"
 	"github.com/m3db/m3x/context"
 	xerrors "github.com/m3db/m3x/errors"
 	"github.com/m3db/m3x/ident"
 	xlog "github.com/m3db/m3x/log"
+	"github.com/m3db/m3x/pool"
 	xtime "github.com/m3db/m3x/time"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/pborman/uuid"
 	"github.com/uber-go/tally"
 )
 
@@ -82,6 +86,15 @@ type filesetBeforeFn func(
 
 type snapshotFilesFn func(filePathPrefix string, namespace ident.ID, shard uint32) (fs.FileSetFilesSlice, error)
 
+type readTombstonesFn func(opts fs.Options, namespace ident.ID, shard uint32) ([]fs.ShardTombstone, error)
+
+type writeTombstonesFn func(
+	opts fs.Options,
+	namespace ident.ID,
+	shard uint32,
+	tombstones []fs.ShardTombstone,
+) error
+
 type tickPolicy int
 
 const (
@@ -136,10 +149,9 @@ type dbShard struct {
 	sync.RWMutex
 	block.DatabaseBlockRetriever
 	opts                     Options
-	seriesOpts               series.Options
 	nowFn                    clock.NowFn
 	state                    dbShardState
-	namespace                namespace.Metadata
+	namespaceState           shardNamespaceState
 	seriesBlockRetriever     series.QueryableBlockRetriever
 	seriesOnRetrieveBlock    block.OnRetrieveBlock
 	namespaceReaderMgr       databaseNamespaceReaderManager
@@ -158,6 +170,14 @@ type dbShard struct {
 	contextPool              context.Pool
 	flushState               shardFlushState
 	snapshotState            shardSnapshotState
+	tombstones               *shardTombstones
+	tombstonesPersistLock    sync.Mutex
+	readTombstonesFn         readTombstonesFn
+	writeTombstonesFn        writeTombstonesFn
+	repairs                  *shardRepairs
+	seriesRetention          *shardSeriesRetention
+	newReaderFn              fsNewReaderFn
+	newSeekerFn              fsNewSeekerFn
 	tickWg                   *sync.WaitGroup
 	runtimeOptsListenClosers []xclose.SimpleCloser
 	currRuntimeOptions       dbShardRuntimeOptions
@@ -189,6 +209,10 @@ type dbShardMetrics struct {
 	insertAsyncWriteErrors        tally.Counter
 	seriesBootstrapBlocksToBuffer tally.Counter
 	seriesBootstrapBlocksMerged   tally.Counter
+	deleteSeries                  tally.Counter
+	repairedSeries                tally.Counter
+	coldFlushedSeries             tally.Counter
+	compactedFileSets             tally.Counter
 }
 
 func newDatabaseShardMetrics(scope tally.Scope) dbShardMetrics {
@@ -209,6 +233,10 @@ func newDatabaseShardMetrics(scope tally.Scope) dbShardMetrics {
 		}).Counter("insert-async.errors"),
 		seriesBootstrapBlocksToBuffer: seriesBootstrapScope.Counter("blocks-to-buffer"),
 		seriesBootstrapBlocksMerged:   seriesBootstrapScope.Counter("blocks-merged"),
+		deleteSeries:                  scope.Counter("delete-series"),
+		repairedSeries:                scope.Counter("repaired-series"),
+		coldFlushedSeries:             scope.Counter("cold-flushed-series"),
+		compactedFileSets:             scope.Counter("compacted-filesets"),
 	}
 }
 
@@ -221,14 +249,26 @@ type shardListElement *list.Element
 type shardFlushState struct {
 	sync.RWMutex
 	statesByTime map[xtime.UnixNano]fileOpState
+	// compacted holds the starts of the compacted filesets, which span
+	// several flushed blocks.
+	compacted map[xtime.UnixNano]struct{}
 }
 
 func newShardFlushState() shardFlushState {
 	return shardFlushState{
 		statesByTime: make(map[xtime.UnixNano]fileOpState),
+		compacted:    make(map[xtime.UnixNano]struct{}),
 	}
 }
 
+// shardNamespaceState holds the namespace metadata and the series options
+// derived from it, both are swapped when the namespace retention is updated.
+type shardNamespaceState struct {
+	sync.RWMutex
+	metadata   namespace.Metadata
+	seriesOpts series.Options
+}
+
 type shardSnapshotState struct {
 	sync.RWMutex
 	isSnapshotting         bool
@@ -250,11 +290,13 @@ func newDatabaseShard(
 		SubScope("dbshard")
 
 	s := &dbShard{
-		opts:               opts,
-		seriesOpts:         seriesOpts,
-		nowFn:              opts.ClockOptions().NowFn(),
-		state:              dbShardStateOpen,
-		namespace:          namespaceMetadata,
+		opts:  opts,
+		nowFn: opts.ClockOptions().NowFn(),
+		state: dbShardStateOpen,
+		namespaceState: shardNamespaceState{
+			metadata:   namespaceMetadata,
+			seriesOpts: seriesOpts,
+		},
 		shard:              shard,
 		namespaceReaderMgr: namespaceReaderMgr,
 		increasingIndex:    increasingIndex,
@@ -269,6 +311,13 @@ func newDatabaseShard(
 		identifierPool:     opts.IdentifierPool(),
 		contextPool:        opts.ContextPool(),
 		flushState:         newShardFlushState(),
+		tombstones:         newShardTombstones(),
+		readTombstonesFn:   fs.ReadShardTombstones,
+		writeTombstonesFn:  fs.WriteShardTombstones,
+		repairs:            newShardRepairs(),
+		seriesRetention:    newShardSeriesRetention(),
+		newReaderFn:        fs.NewReader,
+		newSeekerFn:        newFileSetSeeker,
 		tickWg:             &sync.WaitGroup{},
 		logger:             opts.InstrumentOptions().Logger(),
 		metrics:            newDatabaseShardMetrics(scope),
@@ -320,6 +369,20 @@ func (s *dbShard) SetRuntimeOptions(value runtime.Options) {
 	s.Unlock()
 }
 
+func (s *dbShard) namespaceMetadata() namespace.Metadata {
+	s.namespaceState.RLock()
+	md := s.namespaceState.metadata
+	s.namespaceState.RUnlock()
+	return md
+}
+
+func (s *dbShard) seriesOptions() series.Options {
+	s.namespaceState.RLock()
+	opts := s.namespaceState.seriesOpts
+	s.namespaceState.RUnlock()
+	return opts
+}
+
 func (s *dbShard) ID() uint32 {
 	return s.shard
 }
@@ -338,6 +401,10 @@ func (s *dbShard) Stream(
 	blockStart time.Time,
 	onRetrieve block.OnRetrieveBlock,
 ) (xio.BlockReader, error) {
+	if s.tombstones.contains(id, blockStart) {
+		// Series was deleted after this block was flushed.
+		return xio.EmptyBlockReader, nil
+	}
 	return s.DatabaseBlockRetriever.Stream(ctx, s.shard, id, blockStart, onRetrieve)
 }
 
@@ -360,6 +427,11 @@ func (s *dbShard) OnRetrieveBlock(
 	startTime time.Time,
 	segment ts.Segment,
 ) {
+	if s.tombstones.contains(id, startTime) {
+		// Series was deleted while the block was being retrieved.
+		return
+	}
+
 	s.RLock()
 	entry, _, err := s.lookupEntryWithLock(id)
 	if entry != nil {
@@ -572,6 +644,9 @@ func (s *dbShard) isClosingWithLock() bool {
 
 func (s *dbShard) Tick(c context.Cancellable, tickStart time.Time) (tickResult, error) {
 	s.removeAnyFlushStatesTooEarly(tickStart)
+	s.removeAnyTombstonesTooEarly(tickStart)
+	s.removeAnySeriesRetentionTooEarly(tickStart)
+	s.removeAnyRepairsTooEarly(tickStart)
 	return s.tickAndExpire(c, tickPolicyRegular)
 }
 
@@ -872,10 +947,11 @@ func (s *dbShard) writeAndIndex(
 	// Write commit log
 	series := ts.Series{
 		UniqueIndex: commitLogSeriesUniqueIndex,
-		Namespace:   s.namespace.ID(),
+		Namespace:   s.namespaceMetadata().ID(),
 		ID:          commitLogSeriesID,
 		Tags:        commitLogSeriesTags,
 		Shard:       s.shard,
+		CodecID:     s.namespaceMetadata().Options().CodecID(),
 	}
 
 	return series, nil
@@ -912,7 +988,7 @@ func (s *dbShard) ReadEncoded(
 
 	retriever := s.seriesBlockRetriever
 	onRetrieve := s.seriesOnRetrieveBlock
-	opts := s.seriesOpts
+	opts := s.seriesOptions()
 	reader := series.NewReaderUsingRetriever(id, retriever, onRetrieve, nil, opts)
 	return reader.ReadEncoded(ctx, start, end)
 }
@@ -1037,7 +1113,7 @@ func (s *dbShard) newShardEntry(
 
 	series := s.seriesPool.Get()
 	series.Reset(seriesID, seriesTags, s.seriesBlockRetriever,
-		s.seriesOnRetrieveBlock, s, s.seriesOpts)
+		s.seriesOnRetrieveBlock, s, s.seriesOptions())
 	uniqueIndex := s.increasingIndex.nextIndex()
 	return lookup.NewEntry(series, uniqueIndex), nil
 }
@@ -1270,10 +1346,11 @@ func (s *dbShard) insertSeriesBatch(inserts []dbShardInsert) error {
 	// Perform any indexing, pending writes or pending retrieved blocks outside of lock
 	ctx := s.contextPool.Get()
 	// TODO(prateek): pool this type
-	indexBlockSize := s.namespace.Options().IndexOptions().BlockSize()
+	indexOpts := s.namespaceMetadata().Options().IndexOptions()
 	indexBatch := index.NewWriteBatch(index.WriteBatchOptions{
-		InitialCapacity: numPendingIndexing,
-		IndexBlockSize:  indexBlockSize,
+		InitialCapacity:      numPendingIndexing,
+		IndexBlockSize:       indexOpts.BlockSize(),
+		IndexBlockSizeChange: indexOpts.BlockSizeChange(),
 	})
 	for i := range inserts {
 		var (
@@ -1368,7 +1445,7 @@ func (s *dbShard) FetchBlocks(
 
 	retriever := s.seriesBlockRetriever
 	onRetrieve := s.seriesOnRetrieveBlock
-	opts := s.seriesOpts
+	opts := s.seriesOptions()
 	// Nil for onRead callback because we don't want peer bootstrapping to impact
 	// the behavior of the LRU
 	var onReadCb block.OnReadBlock
@@ -1547,7 +1624,7 @@ func (s *dbShard) FetchBlocksMetadataV2(
 	// flushed block and work backwards.
 	var (
 		result    = s.opts.FetchBlocksMetadataResultsPool().Get()
-		ropts     = s.namespace.Options().RetentionOptions()
+		ropts     = s.namespaceMetadata().Options().RetentionOptions()
 		blockSize = ropts.BlockSize()
 		// Subtract one blocksize because all fetch requests are exclusive on the end side
 		blockStart      = end.Truncate(blockSize).Add(-1 * blockSize)
@@ -1562,7 +1639,8 @@ func (s *dbShard) FetchBlocksMetadataV2(
 	// Work backwards while in requested range and not before retention
 	for !blockStart.Before(start) &&
 		!blockStart.Before(retention.FlushTimeStart(ropts, s.nowFn())) {
-		exists, err := s.namespaceReaderMgr.filesetExistsAt(s.shard, blockStart)
+		fileSet, exists, err := s.namespaceReaderMgr.filesetAt(s.shard,
+			blockStart, blockSize)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -1572,6 +1650,12 @@ func (s *dbShard) FetchBlocksMetadataV2(
 			continue
 		}
 
+		// Compacted filesets are returned once at the start of the range
+		// they span, peers fetching the block receive it with the larger
+		// block size and split it back into blocks.
+		blockStart = fileSet.BlockStart
+		fileSetRange := fileSet.Range()
+
 		var pos readerPosition
 		if !tokenBlockStart.IsZero() {
 			// Was previously seeking through a previous block, need to validate
@@ -1616,6 +1700,13 @@ func (s *dbShard) FetchBlocksMetadataV2(
 					blockStart, err)
 			}
 
+			if s.tombstonedFileSet(id, fileSetRange) {
+				// Series was deleted after this block was flushed.
+				id.Finalize()
+				tags.Close()
+				continue
+			}
+
 			blockResult := s.opts.FetchBlockMetadataResultsPool().Get()
 			value := block.FetchBlockMetadataResult{
 				Start: blockStart,
@@ -1679,10 +1770,34 @@ func (s *dbShard) Bootstrap(
 	var (
 		shardBootstrapResult = dbShardBootstrapResult{}
 		multiErr             = xerrors.NewMultiError()
+		fsOpts               = s.opts.CommitLogOptions().FilesystemOptions()
 	)
+
+	// Restore the tombstones persisted before the process restarted so that
+	// the data of deleted series is not bootstrapped again from commit logs,
+	// snapshots or peers.
+	tombstones, err := s.readTombstonesFn(fsOpts, s.namespaceMetadata().ID(), s.shard)
+	if err != nil {
+		multiErr = multiErr.Add(fmt.Errorf(
+			"shard %d failed to read tombstones: %v", s.ID(), err))
+	}
+	s.tombstones.restore(tombstones)
+
 	for _, elem := range bootstrappedSeries.Iter() {
 		dbBlocks := elem.Value()
 
+		if deletedAt, deleted := s.tombstones.deletedAt(dbBlocks.ID); deleted {
+			remaining, err := s.removeDeletedData(dbBlocks.Blocks, deletedAt)
+			if err != nil {
+				multiErr = multiErr.Add(err)
+			}
+			if !remaining {
+				dbBlocks.Blocks.Close()
+				dbBlocks.Tags.Finalize()
+				continue
+			}
+		}
+
 		// First lookup if series already exists
 		entry, _, err := s.tryRetrieveWritableSeries(dbBlocks.ID)
 		if err != nil {
@@ -1742,29 +1857,63 @@ func (s *dbShard) Bootstrap(
 		return true
 	})
 
+	// Deleted series may have been indexed again by the index bootstrap,
+	// exclude those that were not written again since from query results.
+	if s.reverseIndex != nil {
+		var deleted []ident.ID
+		s.RLock()
+		for _, id := range s.tombstones.ids() {
+			if _, exists := s.lookup.Get(id); !exists {
+				deleted = append(deleted, id)
+			}
+		}
+		s.RUnlock()
+		if len(deleted) > 0 {
+			multiErr = multiErr.Add(s.reverseIndex.Delete(deleted))
+		}
+	}
+
+	// Finish or discard any fileset rewrite interrupted by a restart
+	// before the flushed filesets are inspected
+	if err := fs.RecoverStagedDataFileSets(fsOpts, s.namespaceMetadata().ID(), s.shard); err != nil {
+		s.logger.WithFields(
+			xlog.NewField("shard", s.ID()),
+			xlog.NewField("namespace", s.namespaceMetadata().ID()),
+			xlog.NewField("error", err.Error()),
+		).Error("unable to recover staged filesets in shard bootstrap")
+	}
+
 	// Now iterate flushed time ranges to determine which blocks are
 	// retrievable before servicing reads
-	fsOpts := s.opts.CommitLogOptions().FilesystemOptions()
-	readInfoFilesResults := fs.ReadInfoFiles(fsOpts.FilePathPrefix(), s.namespace.ID(), s.shard,
-		fsOpts.InfoReaderBufferSize(), fsOpts.DecodingOptions())
+	var (
+		blockSize            = s.namespaceMetadata().Options().RetentionOptions().BlockSize()
+		readInfoFilesResults = fs.ReadInfoFilesAllTiers(fsOpts, s.namespaceMetadata().ID(), s.shard)
+	)
 
 	for _, result := range readInfoFilesResults {
 		if result.Err.Error() != nil {
 			s.logger.WithFields(
 				xlog.NewField("shard", s.ID()),
-				xlog.NewField("namespace", s.namespace.ID()),
+				xlog.NewField("namespace", s.namespaceMetadata().ID()),
 				xlog.NewField("error", result.Err.Error()),
 				xlog.NewField("filepath", result.Err.Filepath()),
 			).Error("unable to read info files in shard bootstrap")
 			continue
 		}
+		// Compacted filesets hold every block in the range they span.
 		info := result.Info
-		at := xtime.FromNanoseconds(info.BlockStart)
-		fs := s.FlushState(at)
-		if fs.Status != fileOpNotStarted {
-			continue // Already recorded progress
+		start := xtime.FromNanoseconds(info.BlockStart)
+		end := start.Add(time.Duration(info.BlockSize))
+		if time.Duration(info.BlockSize) > blockSize {
+			s.markCompacted(start)
+		}
+		for at := start; at.Before(end); at = at.Add(blockSize) {
+			fs := s.FlushState(at)
+			if fs.Status != fileOpNotStarted {
+				continue // Already recorded progress
+			}
+			s.markFlushStateSuccess(at)
 		}
-		s.markFlushStateSuccess(at)
 	}
 
 	s.Lock()
@@ -1787,7 +1936,7 @@ func (s *dbShard) Flush(
 	s.RUnlock()
 
 	prepareOpts := persist.DataPrepareOptions{
-		NamespaceMetadata: s.namespace,
+		NamespaceMetadata: s.namespaceMetadata(),
 		Shard:             s.ID(),
 		BlockStart:        blockStart,
 		// We explicitly set delete if exists to false here as we track which
@@ -1804,6 +1953,10 @@ func (s *dbShard) Flush(
 	var multiErr xerrors.MultiError
 	tmpCtx := context.NewContext()
 
+	// Capture the latest tombstone so that any series deleted while
+	// flushing can be tombstoned for this block too.
+	tombstoneSeq := s.tombstones.currentSeq()
+
 	flushResult := dbShardFlushResult{}
 	s.forEachShardEntry(func(entry *lookup.Entry) bool {
 		curr := entry.Series
@@ -1831,12 +1984,24 @@ func (s *dbShard) Flush(
 		multiErr = multiErr.Add(err)
 	}
 
-	return s.markFlushStateSuccessOrError(blockStart, multiErr.FinalError())
+	err = multiErr.FinalError()
+	if err == nil {
+		s.tombstones.onFlushed(blockStart, tombstoneSeq)
+	}
+
+	if err := s.markFlushStateSuccessOrError(blockStart, err); err != nil {
+		return err
+	}
+
+	// Persist the tombstones of any series deleted while flushing as the
+	// flushed volume may contain them.
+	return s.persistTombstones()
 }
 
 func (s *dbShard) Snapshot(
 	blockStart time.Time,
 	snapshotTime time.Time,
+	snapshotID uuid.UUID,
 	flush persist.DataFlush,
 ) error {
 	// We don't snapshot data when the shard is still bootstrapping
@@ -1855,7 +2020,7 @@ func (s *dbShard) Snapshot(
 	}()
 
 	prepareOpts := persist.DataPrepareOptions{
-		NamespaceMetadata: s.namespace,
+		NamespaceMetadata: s.namespaceMetadata(),
 		Shard:             s.ID(),
 		BlockStart:        blockStart,
 		FileSetType:       persist.FileSetSnapshotType,
@@ -1866,6 +2031,7 @@ func (s *dbShard) Snapshot(
 		DeleteIfExists: false,
 		Snapshot: persist.DataPrepareSnapshotOptions{
 			SnapshotTime: snapshotTime,
+			SnapshotID:   snapshotID,
 		},
 	}
 	prepared, err := flush.PrepareData(prepareOpts)
@@ -1928,6 +2094,19 @@ func (s *dbShard) markFlushStateSuccess(blockStart time.Time) {
 	s.flushState.Unlock()
 }
 
+func (s *dbShard) markCompacted(blockStart time.Time) {
+	s.flushState.Lock()
+	s.flushState.compacted[xtime.ToUnixNano(blockStart)] = struct{}{}
+	s.flushState.Unlock()
+}
+
+func (s *dbShard) isCompacted(blockStart time.Time) bool {
+	s.flushState.RLock()
+	_, ok := s.flushState.compacted[xtime.ToUnixNano(blockStart)]
+	s.flushState.RUnlock()
+	return ok
+}
+
 func (s *dbShard) markFlushStateFail(blockStart time.Time) {
 	s.flushState.Lock()
 	state := s.flushState.statesByTime[xtime.ToUnixNano(blockStart)]
@@ -1939,117 +2118,1190 @@ func (s *dbShard) markFlushStateFail(blockStart time.Time) {
 
 func (s *dbShard) removeAnyFlushStatesTooEarly(tickStart time.Time) {
 	s.flushState.Lock()
-	earliestFlush := retention.FlushTimeStart(s.namespace.Options().RetentionOptions(), tickStart)
+	earliestFlush := retention.FlushTimeStart(s.namespaceMetadata().Options().RetentionOptions(), tickStart)
 	for t := range s.flushState.statesByTime {
 		if t.ToTime().Before(earliestFlush) {
 			delete(s.flushState.statesByTime, t)
 		}
 	}
+	for t := range s.flushState.compacted {
+		if t.ToTime().Before(earliestFlush) {
+			delete(s.flushState.compacted, t)
+		}
+	}
 	s.flushState.Unlock()
 }
 
-func (s *dbShard) SnapshotState() (bool, time.Time) {
-	s.snapshotState.RLock()
-	defer s.snapshotState.RUnlock()
-	return s.snapshotState.isSnapshotting, s.snapshotState.lastSuccessfulSnapshot
+func (s *dbShard) removeAnyTombstonesTooEarly(tickStart time.Time) {
+	earliestFlush := retention.FlushTimeStart(s.namespaceMetadata().Options().RetentionOptions(), tickStart)
+	s.tombstones.removeBefore(earliestFlush)
 }
 
-func (s *dbShard) markIsSnapshotting() {
-	s.snapshotState.Lock()
-	s.snapshotState.isSnapshotting = true
-	s.snapshotState.Unlock()
+func (s *dbShard) removeAnySeriesRetentionTooEarly(tickStart time.Time) {
+	earliestFlush := retention.FlushTimeStart(s.namespaceMetadata().Options().RetentionOptions(), tickStart)
+	s.seriesRetention.removeBefore(earliestFlush)
 }
 
-func (s *dbShard) markDoneSnapshotting(success bool, completionTime time.Time) {
-	s.snapshotState.Lock()
-	s.snapshotState.isSnapshotting = false
-	if success {
-		s.snapshotState.lastSuccessfulSnapshot = completionTime
+func (s *dbShard) removeAnyRepairsTooEarly(tickStart time.Time) {
+	earliestFlush := retention.FlushTimeStart(s.namespaceMetadata().Options().RetentionOptions(), tickStart)
+	s.repairs.removeBefore(earliestFlush)
+}
+
+func (s *dbShard) flushedBlockStarts() []xtime.UnixNano {
+	s.flushState.RLock()
+	blockStarts := make([]xtime.UnixNano, 0, len(s.flushState.statesByTime))
+	for blockStart, state := range s.flushState.statesByTime {
+		if state.Status == fileOpSuccess {
+			blockStarts = append(blockStarts, blockStart)
+		}
 	}
-	s.snapshotState.Unlock()
+	s.flushState.RUnlock()
+	return blockStarts
 }
 
-// CleanupSnapshots examines the snapshot files for the shard that are on disk and
-// determines which can be safely deleted. A snapshot file is safe to delete if it
-// meets one of the following criteria:
-// 		1) It contains data for a block start that is out of retention (as determined
-// 		   by the earliestToRetain argument.)
-// 		2) It contains data for a block start that has already been successfully flushed.
-// 		3) It contains data for a block start that hasn't been flushed yet, but a more
-// 		   recent set of snapshot files (higher index) exists for the same block start.
-// 		   This is because snapshot files are cumulative, so once a new one has been
-//         written out it's safe to delete any previous ones for that block start.
-func (s *dbShard) CleanupSnapshots(earliestToRetain time.Time) error {
-	filePathPrefix := s.opts.CommitLogOptions().FilesystemOptions().FilePathPrefix()
-	snapshotFiles, err := s.snapshotFilesFn(filePathPrefix, s.namespace.ID(), s.ID())
-	if err != nil {
-		return err
+func (s *dbShard) DeleteSeries(ids []ident.ID) error {
+	s.Lock()
+	if s.state != dbShardStateOpen {
+		s.Unlock()
+		return xerrors.NewInvalidParamsError(errShardNotOpen)
 	}
 
-	sort.Slice(snapshotFiles, func(i, j int) bool {
-		// Make sure they're sorted by blockStart/Index in ascending order.
-		if snapshotFiles[i].ID.BlockStart.Equal(snapshotFiles[j].ID.BlockStart) {
-			return snapshotFiles[i].ID.VolumeIndex < snapshotFiles[j].ID.VolumeIndex
+	var (
+		flushed   = s.flushedBlockStarts()
+		deletedAt = s.nowFn()
+	)
+	for _, id := range ids {
+		// NB: tombstone the series before removing it so that data already
+		// flushed for it cannot be retrieved into a new series in between.
+		s.tombstones.add(id, flushed, deletedAt)
+
+		elem, exists := s.lookup.Get(id)
+		if !exists {
+			continue
 		}
-		return snapshotFiles[i].ID.BlockStart.Before(snapshotFiles[j].ID.BlockStart)
-	})
+		entry := elem.Value.(*lookup.Entry)
+		s.list.Remove(elem)
+		s.lookup.Delete(id)
 
-	filesToDelete := []string{}
+		// If this series is currently being written to or read from we leave
+		// it to be reclaimed by the GC rather than closing it from under the
+		// other users, they will not be able to find it again after this.
+		if entry.ReaderWriterCount() == 0 {
+			entry.Series.Close()
+		}
+	}
+	s.Unlock()
 
-	for i := 0; i < len(snapshotFiles); i++ {
-		curr := snapshotFiles[i]
+	s.metrics.deleteSeries.Inc(int64(len(ids)))
 
-		if curr.ID.BlockStart.Before(earliestToRetain) {
-			// Delete snapshot files for blocks that have fallen out
-			// of retention.
-			filesToDelete = append(filesToDelete, curr.AbsoluteFilepaths...)
+	// Persist the tombstones before returning so that the deletes are
+	// applied to the commit logs, snapshots and peers bootstrapped from
+	// if the process restarts.
+	return s.persistTombstones()
+}
+
+// removeDeletedData removes the data of a deleted series at or before the
+// time it was deleted from its bootstrapped blocks, returning whether any
+// data remains.
+func (s *dbShard) removeDeletedData(
+	blocks block.DatabaseSeriesBlocks,
+	deletedAt time.Time,
+) (bool, error) {
+	var multiErr xerrors.MultiError
+	for _, b := range blocks.AllBlocks() {
+		start := b.StartTime()
+		if start.After(deletedAt) {
 			continue
 		}
 
-		if s.FlushState(curr.ID.BlockStart).Status == fileOpSuccess {
-			// Delete snapshot files for any block starts that have been
-			// successfully flushed.
-			filesToDelete = append(filesToDelete, curr.AbsoluteFilepaths...)
-			continue
+		if start.Add(b.BlockSize()).After(deletedAt) {
+			// Written again after being deleted, keep the later data.
+			segment, ok, err := s.dataAfter(b, deletedAt)
+			if err != nil {
+				multiErr = multiErr.Add(err)
+			}
+			if ok {
+				b.Reset(start, b.BlockSize(), segment)
+				continue
+			}
 		}
 
-		if i+1 < len(snapshotFiles) &&
-			snapshotFiles[i+1].ID.BlockStart == curr.ID.BlockStart &&
-			snapshotFiles[i+1].ID.VolumeIndex > curr.ID.VolumeIndex &&
-			snapshotFiles[i+1].HasCheckpointFile() {
-			// Delete any snapshot files which are not the most recent
-			// for that block start, but only of the set of snapshot files
-			// with the higher index is complete (checkpoint file exists)
-			filesToDelete = append(filesToDelete, curr.AbsoluteFilepaths...)
+		blocks.RemoveBlockAt(start)
+		b.Close()
+	}
+	return blocks.Len() > 0, multiErr.FinalError()
+}
+
+// dataAfter returns a segment with the data of a block after the given time,
+// if there is any.
+func (s *dbShard) dataAfter(
+	b block.DatabaseBlock,
+	after time.Time,
+) (ts.Segment, bool, error) {
+	ctx := s.contextPool.Get()
+	defer ctx.Close()
+
+	stream, err := b.Stream(ctx)
+	if err != nil || stream.SegmentReader == nil {
+		return ts.Segment{}, false, err
+	}
+
+	var (
+		bopts   = s.opts.DatabaseBlockOptions()
+		iter    = s.opts.MultiReaderIteratorPool().Get()
+		encoder = bopts.EncoderPool().Get()
+		encoded = false
+	)
+	defer iter.Close()
+
+	iter.Reset([]xio.SegmentReader{stream.SegmentReader}, b.StartTime(), b.BlockSize())
+	encoder.Reset(b.StartTime(), bopts.DatabaseBlockAllocSize())
+	for iter.Next() {
+		dp, unit, annotation := iter.Current()
+		if !dp.Timestamp.After(after) {
 			continue
 		}
+		if err := encoder.Encode(dp, unit, annotation); err != nil {
+			encoder.Close()
+			return ts.Segment{}, false, err
+		}
+		encoded = true
 	}
+	if err := iter.Err(); err != nil || !encoded {
+		encoder.Close()
+		return ts.Segment{}, false, err
+	}
+	return encoder.Discard(), true, nil
+}
 
-	return s.deleteFilesFn(filesToDelete)
+// persistTombstones persists the tombstones for the shard if they have
+// changed since they were last persisted.
+func (s *dbShard) persistTombstones() error {
+	s.tombstonesPersistLock.Lock()
+	defer s.tombstonesPersistLock.Unlock()
+
+	tombstones, version, ok := s.tombstones.unpersisted()
+	if !ok {
+		return nil
+	}
+
+	fsOpts := s.opts.CommitLogOptions().FilesystemOptions()
+	if err := s.writeTombstonesFn(fsOpts, s.namespaceMetadata().ID(), s.ID(), tombstones); err != nil {
+		return fmt.Errorf("shard %d failed to persist tombstones: %v", s.ID(), err)
+	}
+	s.tombstones.markPersisted(version)
+	return nil
 }
 
-func (s *dbShard) CleanupExpiredFileSets(earliestToRetain time.Time) error {
-	filePathPrefix := s.opts.CommitLogOptions().FilesystemOptions().FilePathPrefix()
-	multiErr := xerrors.NewMultiError()
-	expired, err := s.filesetBeforeFn(filePathPrefix, s.namespace.ID(), s.ID(), earliestToRetain)
-	if err != nil {
-		detailedErr :=
-			fmt.Errorf("encountered errors when getting fileset files for prefix %s namespace %s shard %d: %v",
-				filePathPrefix, s.namespace.ID(), s.ID(), err)
-		multiErr = multiErr.Add(detailedErr)
+func (s *dbShard) FlushDeletes(flush persist.DataFlush) error {
+	// We don't rewrite data when the shard is still bootstrapping
+	s.RLock()
+	if s.bootstrapState != Bootstrapped {
+		s.RUnlock()
+		return errShardNotBootstrappedToFlush
+	}
+	s.RUnlock()
+
+	var (
+		multiErr  xerrors.MultiError
+		rewritten = make(map[xtime.UnixNano]struct{})
+	)
+	blockStarts := append(s.tombstones.pendingRewriteBlockStarts(),
+		s.seriesRetention.pendingRewriteBlockStarts()...)
+	for _, blockStart := range blockStarts {
+		if _, ok := rewritten[xtime.ToUnixNano(blockStart)]; ok {
+			continue
+		}
+		rewritten[xtime.ToUnixNano(blockStart)] = struct{}{}
+
+		if s.FlushState(blockStart).Status != fileOpSuccess {
+			// Block has since fallen out of retention.
+			s.tombstones.markRewritten(blockStart)
+			s.seriesRetention.markRewritten(blockStart, math.MaxInt64)
+			continue
+		}
+
+		if err := s.rewriteFlushedBlock(blockStart, flush, nil); err != nil {
+			detailedErr := fmt.Errorf("shard %d failed to rewrite block %v without deleted or expired series: %v",
+				s.ID(), blockStart, err)
+			multiErr = multiErr.Add(detailedErr)
+		}
 	}
-	if err := s.deleteFilesFn(expired); err != nil {
+
+	// Persist any tombstones that changed since they were last persisted,
+	// such as those that fell out of retention or failed to persist before.
+	if err := s.persistTombstones(); err != nil {
 		multiErr = multiErr.Add(err)
 	}
+
 	return multiErr.FinalError()
 }
 
-func (s *dbShard) Repair(
-	ctx context.Context,
-	tr xtime.Range,
-	repairer databaseShardRepairer,
-) (repair.MetadataComparisonResult, error) {
-	return repairer.Repair(ctx, s.namespace.ID(), tr, s)
+func (s *dbShard) AddRepairedBlock(
+	id ident.ID,
+	tags ident.Tags,
+	repaired block.DatabaseBlock,
+) error {
+	s.RLock()
+	open := s.state == dbShardStateOpen
+	s.RUnlock()
+	if !open {
+		return xerrors.NewInvalidParamsError(errShardNotOpen)
+	}
+
+	return s.repairs.add(id, tags, repaired, s.nowFn())
+}
+
+func (s *dbShard) FlushRepairs(flush persist.DataFlush) error {
+	// We don't rewrite data when the shard is still bootstrapping
+	s.RLock()
+	if s.bootstrapState != Bootstrapped {
+		s.RUnlock()
+		return errShardNotBootstrappedToFlush
+	}
+	s.RUnlock()
+
+	var multiErr xerrors.MultiError
+	for _, blockStart := range s.repairs.pendingBlockStarts() {
+		if s.FlushState(blockStart).Status != fileOpSuccess {
+			// Block is yet to be flushed, repaired blocks are merged once it is.
+			continue
+		}
+
+		// NB: repaired blocks that fail to persist are dropped, they will be
+		// fetched again by the next repair of the block.
+		repaired := s.repairs.take(blockStart)
+		numRepaired := len(repaired)
+		if err := s.rewriteFlushedBlock(blockStart, flush, repaired); err != nil {
+			detailedErr := fmt.Errorf("shard %d failed to rewrite block %v with repaired series: %v",
+				s.ID(), blockStart, err)
+			multiErr = multiErr.Add(detailedErr)
+			continue
+		}
+
+		s.metrics.repairedSeries.Inc(int64(numRepaired))
+	}
+
+	return multiErr.FinalError()
+}
+
+func (s *dbShard) ColdFlush(flush persist.DataFlush) error {
+	// We don't rewrite data when the shard is still bootstrapping
+	s.RLock()
+	if s.bootstrapState != Bootstrapped {
+		s.RUnlock()
+		return errShardNotBootstrappedToFlush
+	}
+	s.RUnlock()
+
+	// Cold writes can only be merged into blocks that have been flushed,
+	// the others are flushed by the regular flush along with the cold writes.
+	flushed := func(blockStart time.Time) bool {
+		return s.FlushState(blockStart).Status == fileOpSuccess
+	}
+
+	var (
+		multiErr    xerrors.MultiError
+		coldBlocks  = make(map[xtime.UnixNano]map[string]seriesBlock)
+		coldFlushed = make(map[xtime.UnixNano][]coldFlushedSeries)
+	)
+	s.forEachShardEntry(func(entry *lookup.Entry) bool {
+		curr := entry.Series
+		blocks, err := curr.FetchColdBlocks(flushed)
+		if err != nil {
+			multiErr = multiErr.Add(err)
+			return true
+		}
+
+		for _, coldBlock := range blocks {
+			blockStart := xtime.ToUnixNano(coldBlock.Block.StartTime())
+			bySeries, ok := coldBlocks[blockStart]
+			if !ok {
+				bySeries = make(map[string]seriesBlock)
+				coldBlocks[blockStart] = bySeries
+			}
+			// NB: Since ID and Tags are garbage collected we can safely
+			// hold onto refs until the cold flush completes. A deleted series
+			// is removed from the shard, so the cold writes of a series are
+			// written no later than they are collected here.
+			bySeries[curr.ID().String()] = seriesBlock{
+				id:        curr.ID(),
+				tags:      curr.Tags(),
+				block:     coldBlock.Block,
+				writtenAt: s.nowFn(),
+			}
+			coldFlushed[blockStart] = append(coldFlushed[blockStart], coldFlushedSeries{
+				id:      curr.ID(),
+				version: coldBlock.Version,
+			})
+		}
+		return true
+	})
+
+	for blockStart, bySeries := range coldBlocks {
+		numSeries := len(bySeries)
+		if err := s.rewriteFlushedBlock(blockStart.ToTime(), flush, bySeries); err != nil {
+			detailedErr := fmt.Errorf("shard %d failed to rewrite block %v with cold writes: %v",
+				s.ID(), blockStart.ToTime(), err)
+			multiErr = multiErr.Add(detailedErr)
+			continue
+		}
+
+		s.metrics.coldFlushedSeries.Inc(int64(numSeries))
+
+		// Release the cold writes now persisted, series that were expired
+		// in the meantime no longer hold any cold writes to release.
+		for _, flushedSeries := range coldFlushed[blockStart] {
+			entry, _, err := s.tryRetrieveWritableSeries(flushedSeries.id)
+			if err != nil {
+				multiErr = multiErr.Add(err)
+				continue
+			}
+			if entry == nil {
+				continue
+			}
+			err = entry.Series.ColdBlockFlushed(blockStart.ToTime(), flushedSeries.version)
+			entry.DecrementReaderWriterCount()
+			if err != nil {
+				multiErr = multiErr.Add(err)
+			}
+		}
+	}
+
+	return multiErr.FinalError()
+}
+
+type coldFlushedSeries struct {
+	id      ident.ID
+	version int
+}
+
+// rewriteFlushedBlock rewrites the fileset volume for a flushed block,
+// streaming every series from the existing volume except deleted ones and
+// merging in the given series blocks, such as repaired blocks or cold writes.
+// Series blocks for series missing from the existing volume, or deleted from
+// it, are appended to the new volume. Series blocks of deleted series are only
+// kept if they were written after the series was deleted. Blocks held by a
+// compacted fileset rewrite the whole compacted fileset.
+//
+// NB: the given series blocks are closed by the rewrite, whether it succeeds
+// or not, callers must not reuse them.
+func (s *dbShard) rewriteFlushedBlock(
+	blockStart time.Time,
+	flush persist.DataFlush,
+	merges map[string]seriesBlock,
+) error {
+	defer func() {
+		for _, series := range merges {
+			series.block.Close()
+		}
+	}()
+
+	fileSetRange, err := s.flushedFileSetRange(blockStart)
+	if err != nil {
+		return err
+	}
+
+	reader, err := s.newReaderFn(s.opts.BytesPool(),
+		s.opts.CommitLogOptions().FilesystemOptions())
+	if err != nil {
+		return err
+	}
+
+	openOpts := fs.DataReaderOpenOptions{
+		Identifier: fs.FileSetFileIdentifier{
+			Namespace:  s.namespaceMetadata().ID(),
+			Shard:      s.ID(),
+			BlockStart: fileSetRange.Start,
+		},
+		FileSetType: persist.FileSetFlushType,
+	}
+	if err := reader.Open(openOpts); err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	var (
+		fileSetStart     = fileSetRange.Start
+		fileSetBlockSize = fileSetRange.End.Sub(fileSetRange.Start)
+		blockOpts        = s.opts.DatabaseBlockOptions()
+	)
+
+	// NB: the rewrite is staged and only replaces the existing volume once
+	// it is complete, a failed or interrupted rewrite leaves it untouched.
+	prepareOpts := persist.DataPrepareOptions{
+		NamespaceMetadata: s.namespaceMetadata(),
+		Shard:             s.ID(),
+		BlockStart:        fileSetStart,
+		FileSetType:       persist.FileSetFlushType,
+		DeleteIfExists:    true,
+		// NB: series are expired as of when they are rewritten, which is no
+		// earlier than now.
+		ExpiredSeriesRetentionPeriod: s.expiredSeriesRetentionPeriod(fileSetRange.End),
+	}
+	if blockSize := s.namespaceMetadata().Options().RetentionOptions().BlockSize(); fileSetBlockSize != blockSize {
+		prepareOpts.BlockSize = fileSetBlockSize
+	}
+	prepared, err := flush.PrepareData(prepareOpts)
+	if err != nil {
+		return err
+	}
+
+	var multiErr xerrors.MultiError
+	for {
+		id, tagsIter, data, checksum, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			multiErr = multiErr.Add(err)
+			break
+		}
+
+		series, isMerged := merges[id.String()]
+		if s.tombstonedFileSet(id, fileSetRange) {
+			// Any series block for it is appended below if it was written
+			// after the series was deleted.
+			id.Finalize()
+			tagsIter.Close()
+			data.Finalize()
+			continue
+		}
+
+		tags, err := convert.TagsFromTagsIter(id, tagsIter, s.identifierPool)
+		tagsIter.Close()
+		if err != nil {
+			multiErr = multiErr.Add(err)
+			break
+		}
+
+		if s.seriesRetentionExpired(tags, fileSetRange.End) {
+			// Any series block for it is closed along with the rest.
+			tags.Finalize()
+			id.Finalize()
+			data.Finalize()
+			continue
+		}
+
+		segment := ts.NewSegment(data, nil, ts.FinalizeHead)
+		if isMerged {
+			// Merge the local data with the series block, which is closed
+			// along with the local block once merged.
+			delete(merges, id.String())
+			local := block.NewDatabaseBlock(fileSetStart, fileSetBlockSize, segment, blockOpts)
+			err = local.Merge(series.block)
+			if err == nil {
+				err = s.persistBlock(id, tags, local, prepared)
+			} else {
+				series.block.Close()
+			}
+			local.Close()
+		} else {
+			err = prepared.Persist(id, tags, segment, checksum)
+			segment.Finalize()
+		}
+		tags.Finalize()
+		id.Finalize()
+		if err != nil {
+			multiErr = multiErr.Add(err)
+			break
+		}
+	}
+
+	// Append series blocks which the existing volume did not contain.
+	var undeleted []ident.ID
+	for _, series := range merges {
+		if !multiErr.Empty() {
+			break
+		}
+		if s.tombstones.deletes(series.id, blockStart, series.writtenAt) ||
+			s.seriesRetentionExpired(series.tags, fileSetRange.End) {
+			continue
+		}
+		if err := s.persistBlock(series.id, series.tags, series.block, prepared); err != nil {
+			multiErr = multiErr.Add(err)
+			continue
+		}
+		if s.tombstones.contains(series.id, blockStart) {
+			undeleted = append(undeleted, series.id)
+		}
+	}
+
+	if err := closePreparedData(prepared, multiErr.FinalError()); err != nil {
+		multiErr = multiErr.Add(err)
+	}
+
+	if err := multiErr.FinalError(); err != nil {
+		return err
+	}
+
+	// Rewriting also drops any deleted series from the volume.
+	s.invalidateRetrieverBlocks(fileSetRange)
+	s.markRewritten(fileSetRange)
+
+	// The volume now only holds data written after these series were
+	// deleted, which must no longer be excluded when read.
+	for _, id := range undeleted {
+		s.tombstones.removeBlock(id, blockStart)
+	}
+	return nil
+}
+
+// closePreparedData closes the prepared data persist, or discards what was
+// persisted if persisting it failed so that a staged fileset does not
+// replace the existing fileset.
+func closePreparedData(prepared persist.PreparedDataPersist, persistErr error) error {
+	if persistErr != nil && prepared.Abort != nil {
+		return prepared.Abort()
+	}
+	return prepared.Close()
+}
+
+// invalidateRetrieverBlocks invalidates the files the block retriever has
+// opened for the blocks in the given range once the filesets holding them
+// have been replaced, so that they are not read from anymore.
+func (s *dbShard) invalidateRetrieverBlocks(blocks xtime.Range) {
+	if s.DatabaseBlockRetriever == nil {
+		return
+	}
+	blockSize := s.namespaceMetadata().Options().RetentionOptions().BlockSize()
+	for t := blocks.Start; t.Before(blocks.End); t = t.Add(blockSize) {
+		s.DatabaseBlockRetriever.InvalidateBlock(s.shard, t)
+	}
+}
+
+// flushedFileSetRange returns the range of the flushed fileset holding the
+// given block, compacted filesets span several blocks.
+func (s *dbShard) flushedFileSetRange(blockStart time.Time) (xtime.Range, error) {
+	var (
+		fsOpts    = s.opts.CommitLogOptions().FilesystemOptions()
+		blockSize = s.namespaceMetadata().Options().RetentionOptions().BlockSize()
+	)
+	if fs.CompactedBlockSize(fsOpts, blockSize) == 0 {
+		return xtime.Range{Start: blockStart, End: blockStart.Add(blockSize)}, nil
+	}
+
+	fileSet, _, err := fs.CoveringDataFileSet(fsOpts, s.namespaceMetadata().ID(),
+		s.ID(), blockStart, blockSize)
+	if err != nil {
+		return xtime.Range{}, err
+	}
+	return fileSet.Range(), nil
+}
+
+// tombstonedFileSet returns whether the series data held by a flushed
+// fileset is deleted. Compacted filesets only hold data for a series
+// deleted before some of the blocks they span were flushed if it was written
+// again afterwards, so it is only deleted if every block they span is.
+func (s *dbShard) tombstonedFileSet(id ident.ID, fileSetRange xtime.Range) bool {
+	blockSize := s.namespaceMetadata().Options().RetentionOptions().BlockSize()
+	for t := fileSetRange.Start; t.Before(fileSetRange.End); t = t.Add(blockSize) {
+		if !s.tombstones.contains(id, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// seriesRetentionExpired returns whether the retention of a series given by
+// the series retention rules of the namespace has expired for the data of a
+// flushed fileset ending at the given time.
+func (s *dbShard) seriesRetentionExpired(tags ident.Tags, end time.Time) bool {
+	rules := s.namespaceMetadata().Options().SeriesRetentionRules()
+	period, ok := rules.RetentionPeriod(tags)
+	return ok && !end.After(s.nowFn().Add(-period))
+}
+
+// markRewritten records that the blocks held by a flushed fileset no longer
+// contain any deleted series or series whose retention has expired.
+//
+// NB: series are only expired from compacted filesets once their retention
+// has expired for the last block the fileset spans, which schedules another
+// rewrite when it does, so the earlier blocks are not rewritten until then.
+func (s *dbShard) markRewritten(fileSetRange xtime.Range) {
+	var (
+		nsOpts    = s.namespaceMetadata().Options()
+		rules     = nsOpts.SeriesRetentionRules()
+		blockSize = nsOpts.RetentionOptions().BlockSize()
+		now       = s.nowFn()
+	)
+	for t := fileSetRange.Start; t.Before(fileSetRange.End); t = t.Add(blockSize) {
+		s.tombstones.markRewritten(t)
+		expired := rules.ExpiredRetentionPeriod(now.Sub(t.Add(blockSize)))
+		s.seriesRetention.markRewritten(t, expired)
+	}
+}
+
+// persistBlock persists the stream of a block, merging it with any block
+// it has been merged with.
+func (s *dbShard) persistBlock(
+	id ident.ID,
+	tags ident.Tags,
+	b block.DatabaseBlock,
+	prepared persist.PreparedDataPersist,
+) error {
+	ctx := s.contextPool.Get()
+	defer ctx.Close()
+
+	stream, err := b.Stream(ctx)
+	if err != nil {
+		return err
+	}
+
+	segment, err := stream.Segment()
+	if err != nil {
+		return err
+	}
+
+	checksum := digest.SegmentChecksum(segment)
+	return prepared.Persist(id, tags, segment, checksum)
+}
+
+type fsNewSeekerFn func(
+	filePathPrefix string,
+	bytesPool pool.CheckedBytesPool,
+	opts fs.Options,
+) fs.DataFileSetSeeker
+
+func newFileSetSeeker(
+	filePathPrefix string,
+	bytesPool pool.CheckedBytesPool,
+	opts fs.Options,
+) fs.DataFileSetSeeker {
+	return fs.NewSeeker(filePathPrefix, opts.DataReaderBufferSize(),
+		opts.InfoReaderBufferSize(), opts.SeekReaderBufferSize(), bytesPool,
+		false, nil, opts)
+}
+
+// compactionVolume is a flushed fileset compacted into a larger fileset, it
+// is read from in full and seeked into for the series of earlier volumes.
+type compactionVolume struct {
+	blockStart time.Time
+	reader     fs.DataFileSetReader
+	seeker     fs.DataFileSetSeeker
+}
+
+func (v compactionVolume) close() error {
+	multiErr := xerrors.NewMultiError()
+	if v.reader != nil {
+		multiErr = multiErr.Add(v.reader.Close())
+	}
+	if v.seeker != nil {
+		multiErr = multiErr.Add(v.seeker.Close())
+	}
+	return multiErr.FinalError()
+}
+
+func (s *dbShard) CompactFlushedBlocks(flush persist.DataFlush) error {
+	// We don't rewrite data when the shard is still bootstrapping
+	s.RLock()
+	if s.bootstrapState != Bootstrapped {
+		s.RUnlock()
+		return errShardNotBootstrappedToFlush
+	}
+	s.RUnlock()
+
+	var (
+		fsOpts             = s.opts.CommitLogOptions().FilesystemOptions()
+		ropts              = s.namespaceMetadata().Options().RetentionOptions()
+		blockSize          = ropts.BlockSize()
+		compactedBlockSize = fs.CompactedBlockSize(fsOpts, blockSize)
+	)
+	// NB: series that cache all blocks load every block from its own fileset
+	// when bootstrapping, so their filesets are never compacted.
+	if compactedBlockSize == 0 || s.opts.SeriesCachePolicy() == series.CacheAll {
+		return nil
+	}
+	// NB: filesets are indexed into the index block of their block start when
+	// bootstrapping, so compacted filesets must never span index blocks.
+	indexOpts := s.namespaceMetadata().Options().IndexOptions()
+	if indexOpts.Enabled() && indexOpts.BlockSize()%compactedBlockSize != 0 {
+		return nil
+	}
+
+	// Compacted filesets start at a multiple of the compacted block size and
+	// only span blocks which are all in retention.
+	var (
+		now      = s.nowFn()
+		earliest = retention.FlushTimeStart(ropts, now)
+		first    = earliest.Truncate(compactedBlockSize)
+		latest   = now.Add(-fsOpts.FileSetCompactionAge())
+		multiErr xerrors.MultiError
+	)
+	if first.Before(earliest) {
+		first = first.Add(compactedBlockSize)
+	}
+	for start := first; !start.Add(compactedBlockSize).After(latest); start = start.Add(compactedBlockSize) {
+		if err := s.compactFlushedRange(start, compactedBlockSize, flush); err != nil {
+			detailedErr := fmt.Errorf("shard %d failed to compact blocks starting %v: %v",
+				s.ID(), start, err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+	}
+
+	return multiErr.FinalError()
+}
+
+// compactFlushedRange compacts the flushed filesets for the blocks in the
+// given range into a single fileset at the start of the range, once every
+// block in the range has been flushed. Deleted series are dropped from the
+// compacted fileset.
+func (s *dbShard) compactFlushedRange(
+	start time.Time,
+	compactedBlockSize time.Duration,
+	flush persist.DataFlush,
+) error {
+	if s.isCompacted(start) {
+		return nil
+	}
+
+	var (
+		nsID      = s.namespaceMetadata().ID()
+		fsOpts    = s.opts.CommitLogOptions().FilesystemOptions()
+		blockSize = s.namespaceMetadata().Options().RetentionOptions().BlockSize()
+		end       = start.Add(compactedBlockSize)
+	)
+	for t := start; t.Before(end); t = t.Add(blockSize) {
+		if s.FlushState(t).Status != fileOpSuccess {
+			return nil
+		}
+	}
+
+	compacted := fs.DataFileSetRange{
+		FilePathPrefix: fsOpts.FilePathPrefix(),
+		BlockStart:     start,
+		BlockSize:      compactedBlockSize,
+	}
+	existing, exists, err := fs.CoveringDataFileSet(fsOpts, nsID, s.ID(),
+		start, blockSize)
+	if err != nil {
+		return err
+	}
+	if exists && existing.BlockSize == compactedBlockSize {
+		// Compacted by a previous process, remove any filesets left over
+		// from it failing before they were removed.
+		if err := fs.DeleteCompactedDataFileSets(fsOpts, nsID, s.ID(),
+			existing, blockSize); err != nil {
+			return err
+		}
+		s.markCompacted(start)
+		return nil
+	}
+
+	volumes, err := s.openCompactionVolumes(start, end)
+	defer func() {
+		for _, volume := range volumes {
+			volume.close()
+		}
+	}()
+	if err != nil {
+		return err
+	}
+	if len(volumes) == 0 {
+		// Nothing was flushed for any of the blocks.
+		s.markCompacted(start)
+		return nil
+	}
+
+	// NB: the compacted fileset is staged and only replaces the fileset at
+	// the start of the range once it is complete, a failed or interrupted
+	// compaction leaves it untouched.
+	prepared, err := flush.PrepareData(persist.DataPrepareOptions{
+		NamespaceMetadata: s.namespaceMetadata(),
+		Shard:             s.ID(),
+		BlockStart:        start,
+		BlockSize:         compactedBlockSize,
+		FileSetType:       persist.FileSetFlushType,
+		DeleteIfExists:    true,
+		// Expired series are dropped from the compacted fileset.
+		ExpiredSeriesRetentionPeriod: s.expiredSeriesRetentionPeriod(
+			start.Add(compactedBlockSize)),
+	})
+	if err != nil {
+		return err
+	}
+
+	var (
+		multiErr xerrors.MultiError
+		seen     = make(map[string]struct{})
+	)
+	for i := range volumes {
+		if err := s.compactVolume(volumes[i:], compacted, seen, prepared); err != nil {
+			multiErr = multiErr.Add(err)
+			break
+		}
+	}
+
+	if err := closePreparedData(prepared, multiErr.FinalError()); err != nil {
+		multiErr = multiErr.Add(err)
+	}
+	if err := multiErr.FinalError(); err != nil {
+		return err
+	}
+
+	// The compacted fileset takes precedence over the filesets it replaces
+	// as soon as it is complete, so they can be removed afterwards.
+	s.markCompacted(start)
+	s.invalidateRetrieverBlocks(compacted.Range())
+	s.markRewritten(compacted.Range())
+	s.metrics.compactedFileSets.Inc(int64(len(volumes)))
+	return fs.DeleteCompactedDataFileSets(fsOpts, nsID, s.ID(), compacted, blockSize)
+}
+
+func (s *dbShard) openCompactionVolumes(
+	start, end time.Time,
+) ([]compactionVolume, error) {
+	var (
+		nsID      = s.namespaceMetadata().ID()
+		fsOpts    = s.opts.CommitLogOptions().FilesystemOptions()
+		blockSize = s.namespaceMetadata().Options().RetentionOptions().BlockSize()
+		volumes   []compactionVolume
+	)
+	for t := start; t.Before(end); t = t.Add(blockSize) {
+		filePathPrefix, exists, err := fs.DataFileSetPathPrefix(fsOpts, nsID,
+			s.ID(), t)
+		if err != nil {
+			return volumes, err
+		}
+		if !exists {
+			continue
+		}
+
+		reader, err := s.newReaderFn(s.opts.BytesPool(), fsOpts)
+		if err != nil {
+			return volumes, err
+		}
+		err = reader.Open(fs.DataReaderOpenOptions{
+			Identifier: fs.FileSetFileIdentifier{
+				Namespace:  nsID,
+				Shard:      s.ID(),
+				BlockStart: t,
+			},
+			FileSetType: persist.FileSetFlushType,
+		})
+		if err != nil {
+			return volumes, err
+		}
+		volume := compactionVolume{blockStart: t, reader: reader}
+		volumes = append(volumes, volume)
+
+		if len(volumes) == 1 {
+			// The first volume is never seeked into.
+			continue
+		}
+		seeker := s.newSeekerFn(filePathPrefix, s.opts.BytesPool(), fsOpts)
+		if err := seeker.Open(nsID, s.ID(), t); err != nil {
+			return volumes, err
+		}
+		volumes[len(volumes)-1].seeker = seeker
+	}
+	return volumes, nil
+}
+
+// compactVolume persists the series read from the first of the given volumes
+// which have not been seen in an earlier volume, merged with their data in
+// the later volumes.
+func (s *dbShard) compactVolume(
+	volumes []compactionVolume,
+	compacted fs.DataFileSetRange,
+	seen map[string]struct{},
+	prepared persist.PreparedDataPersist,
+) error {
+	var (
+		volume    = volumes[0]
+		blockOpts = s.opts.DatabaseBlockOptions()
+	)
+	for {
+		id, tagsIter, data, _, err := volume.reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, ok := seen[id.String()]; ok {
+			id.Finalize()
+			tagsIter.Close()
+			data.Finalize()
+			continue
+		}
+		seen[id.String()] = struct{}{}
+
+		// NB: every block is created spanning the compacted range so that
+		// merging them encodes the series across the whole range.
+		var (
+			merged   block.DatabaseBlock
+			mergeErr error
+			merge    = func(data checked.Bytes) {
+				b := block.NewDatabaseBlock(compacted.BlockStart,
+					compacted.BlockSize, ts.NewSegment(data, nil, ts.FinalizeHead),
+					blockOpts)
+				if merged == nil {
+					merged = b
+					return
+				}
+				mergeErr = merged.Merge(b)
+			}
+		)
+		if s.tombstones.contains(id, volume.blockStart) {
+			data.Finalize()
+		} else {
+			merge(data)
+		}
+
+		for _, later := range volumes[1:] {
+			if mergeErr != nil {
+				break
+			}
+			if s.tombstones.contains(id, later.blockStart) {
+				continue
+			}
+			data, err := later.seeker.SeekByID(id)
+			if err == fs.ErrSeekIDNotFound {
+				continue
+			}
+			if err != nil {
+				mergeErr = err
+				break
+			}
+			merge(data)
+		}
+		if mergeErr != nil {
+			tagsIter.Close()
+			id.Finalize()
+			if merged != nil {
+				merged.Close()
+			}
+			return mergeErr
+		}
+
+		if merged == nil {
+			// Series was deleted from every block it was flushed in.
+			id.Finalize()
+			tagsIter.Close()
+			continue
+		}
+
+		tags, err := convert.TagsFromTagsIter(id, tagsIter, s.identifierPool)
+		tagsIter.Close()
+		if err == nil {
+			if !s.seriesRetentionExpired(tags, compacted.Range().End) {
+				err = s.persistBlock(id, tags, merged, prepared)
+			}
+			tags.Finalize()
+		}
+		merged.Close()
+		id.Finalize()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *dbShard) SnapshotState() (bool, time.Time) {
+	s.snapshotState.RLock()
+	defer s.snapshotState.RUnlock()
+	return s.snapshotState.isSnapshotting, s.snapshotState.lastSuccessfulSnapshot
+}
+
+func (s *dbShard) markIsSnapshotting() {
+	s.snapshotState.Lock()
+	s.snapshotState.isSnapshotting = true
+	s.snapshotState.Unlock()
+}
+
+func (s *dbShard) markDoneSnapshotting(success bool, completionTime time.Time) {
+	s.snapshotState.Lock()
+	s.snapshotState.isSnapshotting = false
+	if success {
+		s.snapshotState.lastSuccessfulSnapshot = completionTime
+	}
+	s.snapshotState.Unlock()
+}
+
+// CleanupSnapshots examines the snapshot files for the shard that are on disk and
+// determines which can be safely deleted. A snapshot file is safe to delete if it
+// meets one of the following criteria:
+// 		1) It contains data for a block start that is out of retention (as determined
+// 		   by the earliestToRetain argument.)
+// 		2) It contains data for a block start that has already been successfully flushed.
+// 		3) It contains data for a block start that hasn't been flushed yet, but a more
+// 		   recent set of snapshot files (higher index) exists for the same block start.
+// 		   This is because snapshot files are cumulative, so once a new one has been
+//         written out it's safe to delete any previous ones for that block start.
+func (s *dbShard) CleanupSnapshots(earliestToRetain time.Time) error {
+	filePathPrefix := s.opts.CommitLogOptions().FilesystemOptions().FilePathPrefix()
+	snapshotFiles, err := s.snapshotFilesFn(filePathPrefix, s.namespaceMetadata().ID(), s.ID())
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(snapshotFiles, func(i, j int) bool {
+		// Make sure they're sorted by blockStart/Index in ascending order.
+		if snapshotFiles[i].ID.BlockStart.Equal(snapshotFiles[j].ID.BlockStart) {
+			return snapshotFiles[i].ID.VolumeIndex < snapshotFiles[j].ID.VolumeIndex
+		}
+		return snapshotFiles[i].ID.BlockStart.Before(snapshotFiles[j].ID.BlockStart)
+	})
+
+	filesToDelete := []string{}
+
+	for i := 0; i < len(snapshotFiles); i++ {
+		curr := snapshotFiles[i]
+
+		if curr.ID.BlockStart.Before(earliestToRetain) {
+			// Delete snapshot files for blocks that have fallen out
+			// of retention.
+			filesToDelete = append(filesToDelete, curr.AbsoluteFilepaths...)
+			continue
+		}
+
+		if s.FlushState(curr.ID.BlockStart).Status == fileOpSuccess {
+			// Delete snapshot files for any block starts that have been
+			// successfully flushed.
+			filesToDelete = append(filesToDelete, curr.AbsoluteFilepaths...)
+			continue
+		}
+
+		if i+1 < len(snapshotFiles) &&
+			snapshotFiles[i+1].ID.BlockStart == curr.ID.BlockStart &&
+			snapshotFiles[i+1].ID.VolumeIndex > curr.ID.VolumeIndex &&
+			snapshotFiles[i+1].HasCheckpointFile() {
+			// Delete any snapshot files which are not the most recent
+			// for that block start, but only of the set of snapshot files
+			// with the higher index is complete (checkpoint file exists)
+			filesToDelete = append(filesToDelete, curr.AbsoluteFilepaths...)
+			continue
+		}
+	}
+
+	return s.deleteFilesFn(filesToDelete)
+}
+
+func (s *dbShard) CleanupExpiredFileSets(earliestToRetain time.Time) error {
+	fsOpts := s.opts.CommitLogOptions().FilesystemOptions()
+	blockSize := s.namespaceMetadata().Options().RetentionOptions().BlockSize()
+	if compactedBlockSize := fs.CompactedBlockSize(fsOpts, blockSize); compactedBlockSize > 0 {
+		// Compacted filesets are retained until every block they span has
+		// fallen out of retention.
+		earliestToRetain = earliestToRetain.Add(blockSize - compactedBlockSize)
+	}
+
+	filePathPrefixes := []string{fsOpts.FilePathPrefix()}
+	if tier := fsOpts.FileSetTier(); tier != nil {
+		filePathPrefixes = append(filePathPrefixes, tier.FilePathPrefix())
+	}
+
+	multiErr := xerrors.NewMultiError()
+	for _, filePathPrefix := range filePathPrefixes {
+		expired, err := s.filesetBeforeFn(filePathPrefix, s.namespaceMetadata().ID(), s.ID(), earliestToRetain)
+		if err != nil {
+			detailedErr :=
+				fmt.Errorf("encountered errors when getting fileset files for prefix %s namespace %s shard %d: %v",
+					filePathPrefix, s.namespaceMetadata().ID(), s.ID(), err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+		if err := s.deleteFilesFn(expired); err != nil {
+			multiErr = multiErr.Add(err)
+		}
+	}
+
+	s.markSeriesRetentionExpired()
+	return multiErr.FinalError()
+}
+
+// markSeriesRetentionExpired schedules the flushed blocks older than the
+// retention period of any of the series retention rules of the namespace to
+// be rewritten without the series matching the rule.
+func (s *dbShard) markSeriesRetentionExpired() {
+	var (
+		nsOpts    = s.namespaceMetadata().Options()
+		rules     = nsOpts.SeriesRetentionRules()
+		blockSize = nsOpts.RetentionOptions().BlockSize()
+		now       = s.nowFn()
+	)
+	if len(rules) == 0 {
+		return
+	}
+
+	for _, blockStart := range s.flushedBlockStarts() {
+		blockEnd := blockStart.ToTime().Add(blockSize)
+		expired := rules.ExpiredRetentionPeriod(now.Sub(blockEnd))
+		if expired > 0 && !s.seriesRetention.isLoaded(blockStart.ToTime()) {
+			s.loadExpiredSeriesRetention(blockStart.ToTime())
+		}
+		s.seriesRetention.markExpired(blockStart.ToTime(), expired)
+	}
+}
+
+// loadExpiredSeriesRetention loads the series retention period already
+// expired from the flushed fileset holding a block from its info file, so
+// that it is not rewritten again after the process restarts.
+func (s *dbShard) loadExpiredSeriesRetention(blockStart time.Time) {
+	expired, err := s.readExpiredSeriesRetention(blockStart)
+	if err != nil {
+		// NB: the volume is rewritten since it is unknown whether the
+		// expired series were already removed from it.
+		s.logger.WithFields(
+			xlog.NewField("shard", s.ID()),
+			xlog.NewField("blockStart", blockStart.String()),
+			xlog.NewField("error", err.Error()),
+		).Warnf("unable to read expired series retention from fileset info file")
+	}
+	s.seriesRetention.markLoaded(blockStart, expired)
+}
+
+func (s *dbShard) readExpiredSeriesRetention(blockStart time.Time) (time.Duration, error) {
+	var (
+		fsOpts    = s.opts.CommitLogOptions().FilesystemOptions()
+		nsID      = s.namespaceMetadata().ID()
+		blockSize = s.namespaceMetadata().Options().RetentionOptions().BlockSize()
+	)
+	fileSet, exists, err := fs.CoveringDataFileSet(fsOpts, nsID, s.ID(),
+		blockStart, blockSize)
+	if err != nil || !exists {
+		return 0, err
+	}
+	info, err := fs.ReadDataInfoFile(fsOpts, fileSet.FilePathPrefix, nsID,
+		s.ID(), fileSet.BlockStart)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(info.ExpiredSeriesRetentionPeriod), nil
+}
+
+// expiredSeriesRetentionPeriod returns the longest series retention period
+// expired from a flushed fileset ending at the given time when it is written.
+func (s *dbShard) expiredSeriesRetentionPeriod(end time.Time) time.Duration {
+	rules := s.namespaceMetadata().Options().SeriesRetentionRules()
+	return rules.ExpiredRetentionPeriod(s.nowFn().Sub(end))
+}
+
+func (s *dbShard) Repair(
+	ctx context.Context,
+	tr xtime.Range,
+	repairer databaseShardRepairer,
+) (repair.MetadataComparisonResult, error) {
+	return repairer.Repair(ctx, s.namespaceMetadata(), tr, s)
+}
+
+func (s *dbShard) UpdateNamespaceMetadata(
+	metadata namespace.Metadata,
+	seriesOpts series.Options,
+) {
+	s.namespaceState.Lock()
+	s.namespaceState.metadata = metadata
+	s.namespaceState.seriesOpts = seriesOpts
+	s.namespaceState.Unlock()
+
+	// NB: Series created concurrently with the update may still be
+	// inserted with the previous options, they pick up the new options
+	// the next time the namespace is updated or once they are reset.
+	s.forEachShardEntry(func(entry *lookup.Entry) bool {
+		entry.Series.UpdateOptions(seriesOpts)
+		return true
+	})
 }
 
 func (s *dbShard) BootstrapState() BootstrapState {
@@ -0,0 +1,187 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/dbnode/storage/block"
+	"github.com/m3db/m3/src/dbnode/storage/series"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3x/checked"
+	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/pool"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardColdFlushShardNotBootstrapped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := testDatabaseShard(t, testDatabaseOptions())
+	defer s.Close()
+	s.bootstrapState = Bootstrapping
+
+	flush := persist.NewMockDataFlush(ctrl)
+	err := s.ColdFlush(flush)
+	require.Equal(t, errShardNotBootstrappedToFlush, err)
+}
+
+func TestShardColdFlushRewritesWithColdWrites(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		blockStart     = time.Unix(21600, 0)
+		unflushedStart = blockStart.Add(2 * time.Hour)
+	)
+
+	s := testDatabaseShard(t, testDatabaseOptions())
+	defer s.Close()
+	s.bootstrapState = Bootstrapped
+	s.markFlushStateSuccess(blockStart)
+
+	// The rewritten block is no longer read from the replaced fileset.
+	retriever := block.NewMockDatabaseBlockRetriever(ctrl)
+	retriever.EXPECT().InvalidateBlock(s.ID(), blockStart)
+	s.setBlockRetriever(retriever)
+
+	blockSize := s.namespaceMetadata().Options().RetentionOptions().BlockSize()
+	coldSegment := ts.NewSegment(checked.NewBytes([]byte{7, 8, 9}, nil), nil, ts.FinalizeNone)
+	coldBlock := block.NewDatabaseBlock(blockStart, blockSize, coldSegment,
+		s.opts.DatabaseBlockOptions())
+
+	foo := addMockSeries(ctrl, s, ident.StringID("foo"), ident.Tags{}, 0)
+	foo.EXPECT().Close().AnyTimes()
+	foo.EXPECT().FetchColdBlocks(gomock.Any()).DoAndReturn(
+		func(filter series.ColdBlockFilter) ([]series.ColdBlock, error) {
+			// Only flushed blocks are eligible for cold flushing.
+			assert.True(t, filter(blockStart))
+			assert.False(t, filter(unflushedStart))
+			return []series.ColdBlock{{Block: coldBlock, Version: 3}}, nil
+		})
+	foo.EXPECT().ColdBlockFlushed(blockStart, 3).Return(nil)
+
+	reader := fs.NewMockDataFileSetReader(ctrl)
+	s.newReaderFn = func(pool.CheckedBytesPool, fs.Options) (fs.DataFileSetReader, error) {
+		return reader, nil
+	}
+
+	gomock.InOrder(
+		reader.EXPECT().Open(fs.DataReaderOpenOptions{
+			Identifier: fs.FileSetFileIdentifier{
+				Namespace:  s.namespaceMetadata().ID(),
+				Shard:      s.ID(),
+				BlockStart: blockStart,
+			},
+			FileSetType: persist.FileSetFlushType,
+		}).Return(nil),
+		reader.EXPECT().Read().Return(ident.StringID("bar"), ident.EmptyTagIterator,
+			checked.NewBytes([]byte{1, 2, 3}, nil), uint32(1), nil),
+		reader.EXPECT().Read().Return(nil, nil, nil, uint32(0), io.EOF),
+		reader.EXPECT().Close().Return(nil),
+	)
+
+	var (
+		persisted = make(map[string][]byte)
+		closed    bool
+	)
+	flush := persist.NewMockDataFlush(ctrl)
+	flush.EXPECT().PrepareData(persist.DataPrepareOptions{
+		NamespaceMetadata: s.namespaceMetadata(),
+		Shard:             s.ID(),
+		BlockStart:        blockStart,
+		FileSetType:       persist.FileSetFlushType,
+		DeleteIfExists:    true,
+	}).Return(persist.PreparedDataPersist{
+		Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, _ uint32) error {
+			persisted[id.String()] = append([]byte(nil), segment.Head.Bytes()...)
+			return nil
+		},
+		Close: func() error { closed = true; return nil },
+	}, nil)
+
+	require.NoError(t, s.ColdFlush(flush))
+	assert.True(t, closed)
+	assert.Equal(t, map[string][]byte{
+		"bar": []byte{1, 2, 3},
+		"foo": []byte{7, 8, 9},
+	}, persisted)
+}
+
+func TestShardColdFlushAbortsFailedRewrite(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blockStart := time.Unix(21600, 0)
+
+	s := testDatabaseShard(t, testDatabaseOptions())
+	defer s.Close()
+	s.bootstrapState = Bootstrapped
+	s.markFlushStateSuccess(blockStart)
+
+	// Blocks are not invalidated since the existing fileset is kept.
+	retriever := block.NewMockDatabaseBlockRetriever(ctrl)
+	s.setBlockRetriever(retriever)
+
+	blockSize := s.namespaceMetadata().Options().RetentionOptions().BlockSize()
+	coldSegment := ts.NewSegment(checked.NewBytes([]byte{7, 8, 9}, nil), nil, ts.FinalizeNone)
+	coldBlock := block.NewDatabaseBlock(blockStart, blockSize, coldSegment,
+		s.opts.DatabaseBlockOptions())
+
+	foo := addMockSeries(ctrl, s, ident.StringID("foo"), ident.Tags{}, 0)
+	foo.EXPECT().Close().AnyTimes()
+	foo.EXPECT().FetchColdBlocks(gomock.Any()).
+		Return([]series.ColdBlock{{Block: coldBlock, Version: 1}}, nil)
+
+	reader := fs.NewMockDataFileSetReader(ctrl)
+	s.newReaderFn = func(pool.CheckedBytesPool, fs.Options) (fs.DataFileSetReader, error) {
+		return reader, nil
+	}
+	gomock.InOrder(
+		reader.EXPECT().Open(gomock.Any()).Return(nil),
+		reader.EXPECT().Read().Return(nil, nil, nil, uint32(0), errors.New("read error")),
+		reader.EXPECT().Close().Return(nil),
+	)
+
+	var aborted bool
+	flush := persist.NewMockDataFlush(ctrl)
+	flush.EXPECT().PrepareData(gomock.Any()).Return(persist.PreparedDataPersist{
+		Persist: func(ident.ID, ident.Tags, ts.Segment, uint32) error { return nil },
+		Close: func() error {
+			assert.Fail(t, "failed rewrite must not be committed")
+			return nil
+		},
+		Abort: func() error { aborted = true; return nil },
+	}, nil)
+
+	// The cold writes are kept for the next cold flush.
+	require.Error(t, s.ColdFlush(flush))
+	assert.True(t, aborted)
+}
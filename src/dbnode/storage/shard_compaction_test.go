@@ -0,0 +1,199 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/dbnode/storage/block"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3x/checked"
+	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/pool"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardCompactFlushedBlocksShardNotBootstrapped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := testDatabaseShard(t, testDatabaseOptions())
+	defer s.Close()
+	s.bootstrapState = Bootstrapping
+
+	flush := persist.NewMockDataFlush(ctrl)
+	err := s.CompactFlushedBlocks(flush)
+	require.Equal(t, errShardNotBootstrappedToFlush, err)
+}
+
+func TestShardCompactFlushedBlocksDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := testDatabaseShard(t, testDatabaseOptions())
+	defer s.Close()
+	s.bootstrapState = Bootstrapped
+
+	// No calls are expected on the flush since compaction is disabled.
+	flush := persist.NewMockDataFlush(ctrl)
+	require.NoError(t, s.CompactFlushedBlocks(flush))
+}
+
+func TestShardCompactFlushedBlocks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dir, err := ioutil.TempDir("", "shard-compaction")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		blockSize          = defaultTestRetentionOpts.BlockSize()
+		compactedBlockSize = 2 * blockSize
+		now                = time.Now()
+		compactedStart     = now.Add(-4 * compactedBlockSize).Truncate(compactedBlockSize)
+		laterStart         = compactedStart.Add(blockSize)
+		opts               = testDatabaseOptions()
+	)
+	fsOpts := opts.CommitLogOptions().FilesystemOptions().
+		SetFilePathPrefix(dir).
+		SetFileSetCompactionBlocks(2).
+		SetFileSetCompactionAge(compactedBlockSize)
+	opts = opts.
+		SetCommitLogOptions(opts.CommitLogOptions().SetFilesystemOptions(fsOpts)).
+		SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time { return now }))
+
+	s := testDatabaseShard(t, opts)
+	defer s.Close()
+	s.bootstrapState = Bootstrapped
+
+	// Both blocks are no longer read from the filesets they were flushed to.
+	retriever := block.NewMockDatabaseBlockRetriever(ctrl)
+	retriever.EXPECT().InvalidateBlock(s.ID(), compactedStart)
+	retriever.EXPECT().InvalidateBlock(s.ID(), laterStart)
+	s.setBlockRetriever(retriever)
+
+	// Write the filesets to compact, only the later one is expected to be
+	// deleted since the compacted fileset replaces the earlier one in place.
+	for _, blockStart := range []time.Time{compactedStart, laterStart} {
+		writer, err := fs.NewWriter(fsOpts)
+		require.NoError(t, err)
+		require.NoError(t, writer.Open(fs.DataWriterOpenOptions{
+			Identifier: fs.FileSetFileIdentifier{
+				Namespace:  s.namespaceMetadata().ID(),
+				Shard:      s.ID(),
+				BlockStart: blockStart,
+			},
+			BlockSize: blockSize,
+		}))
+		require.NoError(t, writer.Close())
+		s.markFlushStateSuccess(blockStart)
+	}
+
+	deleted := ident.StringID("baz")
+	s.tombstones.add(deleted, []xtime.UnixNano{xtime.ToUnixNano(compactedStart)}, compactedStart)
+
+	var (
+		readers = []*fs.MockDataFileSetReader{
+			fs.NewMockDataFileSetReader(ctrl),
+			fs.NewMockDataFileSetReader(ctrl),
+		}
+		seeker = fs.NewMockDataFileSetSeeker(ctrl)
+		opened int
+	)
+	s.newReaderFn = func(pool.CheckedBytesPool, fs.Options) (fs.DataFileSetReader, error) {
+		reader := readers[opened]
+		opened++
+		return reader, nil
+	}
+	s.newSeekerFn = func(string, pool.CheckedBytesPool, fs.Options) fs.DataFileSetSeeker {
+		return seeker
+	}
+
+	for i, blockStart := range []time.Time{compactedStart, laterStart} {
+		readers[i].EXPECT().Open(fs.DataReaderOpenOptions{
+			Identifier: fs.FileSetFileIdentifier{
+				Namespace:  s.namespaceMetadata().ID(),
+				Shard:      s.ID(),
+				BlockStart: blockStart,
+			},
+			FileSetType: persist.FileSetFlushType,
+		}).Return(nil)
+		readers[i].EXPECT().Close().Return(nil)
+	}
+	seeker.EXPECT().Open(s.namespaceMetadata().ID(), s.ID(), laterStart).Return(nil)
+	seeker.EXPECT().Close().Return(nil)
+
+	gomock.InOrder(
+		readers[0].EXPECT().Read().Return(ident.StringID("foo"), ident.EmptyTagIterator,
+			checked.NewBytes([]byte{1, 2, 3}, nil), uint32(1), nil),
+		readers[0].EXPECT().Read().Return(deleted, ident.EmptyTagIterator,
+			checked.NewBytes([]byte{4, 5, 6}, nil), uint32(2), nil),
+		readers[0].EXPECT().Read().Return(nil, nil, nil, uint32(0), io.EOF),
+		readers[1].EXPECT().Read().Return(ident.StringID("bar"), ident.EmptyTagIterator,
+			checked.NewBytes([]byte{7, 8, 9}, nil), uint32(3), nil),
+		readers[1].EXPECT().Read().Return(nil, nil, nil, uint32(0), io.EOF),
+	)
+	seeker.EXPECT().SeekByID(ident.NewIDMatcher("foo")).Return(nil, fs.ErrSeekIDNotFound)
+	seeker.EXPECT().SeekByID(ident.NewIDMatcher("baz")).Return(nil, fs.ErrSeekIDNotFound)
+
+	var (
+		persisted []string
+		closed    bool
+	)
+	flush := persist.NewMockDataFlush(ctrl)
+	flush.EXPECT().PrepareData(persist.DataPrepareOptions{
+		NamespaceMetadata: s.namespaceMetadata(),
+		Shard:             s.ID(),
+		BlockStart:        compactedStart,
+		BlockSize:         compactedBlockSize,
+		FileSetType:       persist.FileSetFlushType,
+		DeleteIfExists:    true,
+	}).Return(persist.PreparedDataPersist{
+		Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32) error {
+			persisted = append(persisted, id.String())
+			return nil
+		},
+		Close: func() error { closed = true; return nil },
+	}, nil)
+
+	require.NoError(t, s.CompactFlushedBlocks(flush))
+	assert.Equal(t, []string{"foo", "bar"}, persisted)
+	assert.True(t, closed)
+	assert.True(t, s.isCompacted(compactedStart))
+
+	exists, err := fs.DataFileSetExistsAt(dir, s.namespaceMetadata().ID(), s.ID(), laterStart)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Already compacted blocks are not compacted again.
+	require.NoError(t, s.CompactFlushedBlocks(flush))
+}
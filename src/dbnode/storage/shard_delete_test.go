@@ -0,0 +1,412 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/dbnode/storage/block"
+	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/dbnode/x/xio"
+	"github.com/m3db/m3x/checked"
+	"github.com/m3db/m3x/context"
+	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/pool"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardDeleteSeries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		flushedStart   = time.Unix(21600, 0)
+		unflushedStart = flushedStart.Add(2 * time.Hour)
+		id             = ident.StringID("foo")
+	)
+
+	s := testDatabaseShard(t, testDatabaseOptions())
+	defer s.Close()
+	s.markFlushStateSuccess(flushedStart)
+
+	var persisted []fs.ShardTombstone
+	s.writeTombstonesFn = func(_ fs.Options, _ ident.ID, _ uint32, tombstones []fs.ShardTombstone) error {
+		persisted = tombstones
+		return nil
+	}
+
+	series := addMockSeries(ctrl, s, id, ident.Tags{}, 0)
+	series.EXPECT().Close()
+	require.Equal(t, int64(1), s.NumSeries())
+
+	require.NoError(t, s.DeleteSeries([]ident.ID{id}))
+	assert.Equal(t, int64(0), s.NumSeries())
+
+	require.Equal(t, 1, len(persisted))
+	assert.Equal(t, id.Bytes(), persisted[0].ID)
+	assert.Equal(t, []time.Time{flushedStart}, persisted[0].BlockStarts)
+
+	_, exists := s.lookup.Get(id)
+	assert.False(t, exists)
+
+	assert.True(t, s.tombstones.contains(id, flushedStart))
+	assert.False(t, s.tombstones.contains(id, unflushedStart))
+	assert.Equal(t, []time.Time{flushedStart}, s.tombstones.pendingRewriteBlockStarts())
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	reader, err := s.Stream(ctx, id, flushedStart, nil)
+	require.NoError(t, err)
+	assert.Equal(t, xio.EmptyBlockReader, reader)
+}
+
+func TestShardDeleteSeriesShardNotOpen(t *testing.T) {
+	s := testDatabaseShard(t, testDatabaseOptions())
+	require.NoError(t, s.Close())
+
+	err := s.DeleteSeries([]ident.ID{ident.StringID("foo")})
+	require.Error(t, err)
+}
+
+func TestShardDeleteSeriesPersistError(t *testing.T) {
+	s := testDatabaseShard(t, testDatabaseOptions())
+	defer s.Close()
+
+	s.writeTombstonesFn = func(fs.Options, ident.ID, uint32, []fs.ShardTombstone) error {
+		return errors.New("persist error")
+	}
+
+	id := ident.StringID("foo")
+	require.Error(t, s.DeleteSeries([]ident.ID{id}))
+
+	// Still deleted in memory and persisted again by the next flush.
+	_, deleted := s.tombstones.deletedAt(id)
+	assert.True(t, deleted)
+	_, _, unpersisted := s.tombstones.unpersisted()
+	assert.True(t, unpersisted)
+}
+
+func TestShardFlushDeletesShardNotBootstrapped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := testDatabaseShard(t, testDatabaseOptions())
+	defer s.Close()
+	s.bootstrapState = Bootstrapping
+
+	flush := persist.NewMockDataFlush(ctrl)
+	err := s.FlushDeletes(flush)
+	require.Equal(t, errShardNotBootstrappedToFlush, err)
+}
+
+func TestShardFlushDeletesRewritesWithoutDeleted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blockStart := time.Unix(21600, 0)
+
+	s := testDatabaseShard(t, testDatabaseOptions())
+	defer s.Close()
+	s.bootstrapState = Bootstrapped
+	s.markFlushStateSuccess(blockStart)
+
+	var persists int
+	s.writeTombstonesFn = func(fs.Options, ident.ID, uint32, []fs.ShardTombstone) error {
+		persists++
+		return nil
+	}
+
+	deleted := ident.StringID("bar")
+	require.NoError(t, s.DeleteSeries([]ident.ID{deleted}))
+
+	reader := fs.NewMockDataFileSetReader(ctrl)
+	s.newReaderFn = func(pool.CheckedBytesPool, fs.Options) (fs.DataFileSetReader, error) {
+		return reader, nil
+	}
+
+	gomock.InOrder(
+		reader.EXPECT().Open(fs.DataReaderOpenOptions{
+			Identifier: fs.FileSetFileIdentifier{
+				Namespace:  s.namespaceMetadata().ID(),
+				Shard:      s.ID(),
+				BlockStart: blockStart,
+			},
+			FileSetType: persist.FileSetFlushType,
+		}).Return(nil),
+		reader.EXPECT().Read().Return(ident.StringID("foo"), ident.EmptyTagIterator,
+			checked.NewBytes([]byte{1, 2, 3}, nil), uint32(1), nil),
+		reader.EXPECT().Read().Return(ident.StringID("bar"), ident.EmptyTagIterator,
+			checked.NewBytes([]byte{4, 5, 6}, nil), uint32(2), nil),
+		reader.EXPECT().Read().Return(nil, nil, nil, uint32(0), io.EOF),
+		reader.EXPECT().Close().Return(nil),
+	)
+
+	var (
+		persisted []string
+		closed    bool
+	)
+	flush := persist.NewMockDataFlush(ctrl)
+	flush.EXPECT().PrepareData(persist.DataPrepareOptions{
+		NamespaceMetadata: s.namespaceMetadata(),
+		Shard:             s.ID(),
+		BlockStart:        blockStart,
+		FileSetType:       persist.FileSetFlushType,
+		DeleteIfExists:    true,
+	}).Return(persist.PreparedDataPersist{
+		Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32) error {
+			persisted = append(persisted, id.String())
+			assert.Equal(t, []byte{1, 2, 3}, segment.Head.Bytes())
+			assert.Equal(t, uint32(1), checksum)
+			return nil
+		},
+		Close: func() error { closed = true; return nil },
+	}, nil)
+
+	require.NoError(t, s.FlushDeletes(flush))
+	assert.Equal(t, []string{"foo"}, persisted)
+	assert.True(t, closed)
+	assert.Equal(t, 0, len(s.tombstones.pendingRewriteBlockStarts()))
+
+	// Deleted series remain excluded from reads.
+	assert.True(t, s.tombstones.contains(deleted, blockStart))
+
+	// Nothing further to rewrite, nor any changed tombstones to persist.
+	require.NoError(t, s.FlushDeletes(flush))
+	assert.Equal(t, 1, persists)
+}
+
+func TestShardRewriteFlushedBlockKeepsDataWrittenAfterDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blockStart := time.Unix(21600, 0)
+	deletedAt := blockStart.Add(4 * time.Hour)
+
+	s := testDatabaseShard(t, testDatabaseOptions())
+	defer s.Close()
+	s.bootstrapState = Bootstrapped
+	s.markFlushStateSuccess(blockStart)
+	s.writeTombstonesFn = func(fs.Options, ident.ID, uint32, []fs.ShardTombstone) error {
+		return nil
+	}
+
+	s.nowFn = func() time.Time { return deletedAt }
+	before, after := ident.StringID("before"), ident.StringID("after")
+	require.NoError(t, s.DeleteSeries([]ident.ID{before, after}))
+
+	reader := fs.NewMockDataFileSetReader(ctrl)
+	s.newReaderFn = func(pool.CheckedBytesPool, fs.Options) (fs.DataFileSetReader, error) {
+		return reader, nil
+	}
+
+	gomock.InOrder(
+		reader.EXPECT().Open(gomock.Any()).Return(nil),
+		reader.EXPECT().Read().Return(ident.StringID("before"), ident.EmptyTagIterator,
+			checked.NewBytes([]byte{1, 2, 3}, nil), uint32(1), nil),
+		reader.EXPECT().Read().Return(ident.StringID("after"), ident.EmptyTagIterator,
+			checked.NewBytes([]byte{4, 5, 6}, nil), uint32(2), nil),
+		reader.EXPECT().Read().Return(nil, nil, nil, uint32(0), io.EOF),
+		reader.EXPECT().Close().Return(nil),
+	)
+
+	persisted := make(map[string][]byte)
+	flush := persist.NewMockDataFlush(ctrl)
+	flush.EXPECT().PrepareData(gomock.Any()).Return(persist.PreparedDataPersist{
+		Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, _ uint32) error {
+			persisted[id.String()] = append([]byte(nil), segment.Head.Bytes()...)
+			return nil
+		},
+		Close: func() error { return nil },
+	}, nil)
+
+	blockSize := s.namespaceMetadata().Options().RetentionOptions().BlockSize()
+	newBlock := func(data []byte) block.DatabaseBlock {
+		segment := ts.NewSegment(checked.NewBytes(data, nil), nil, ts.FinalizeNone)
+		return block.NewDatabaseBlock(blockStart, blockSize, segment,
+			s.opts.DatabaseBlockOptions())
+	}
+	merges := map[string]seriesBlock{
+		"before": {
+			id:        before,
+			block:     newBlock([]byte{7}),
+			writtenAt: deletedAt.Add(-time.Minute),
+		},
+		"after": {
+			id:        after,
+			block:     newBlock([]byte{8}),
+			writtenAt: deletedAt.Add(time.Minute),
+		},
+	}
+
+	require.NoError(t, s.rewriteFlushedBlock(blockStart, flush, merges))
+
+	// Only the data written after the delete is kept.
+	assert.Equal(t, map[string][]byte{"after": []byte{8}}, persisted)
+	assert.True(t, s.tombstones.contains(before, blockStart))
+	assert.False(t, s.tombstones.contains(after, blockStart))
+}
+
+func TestShardTickRemovesExpiredTombstones(t *testing.T) {
+	s := testDatabaseShard(t, testDatabaseOptions())
+	defer s.Close()
+
+	var (
+		ropts      = s.namespaceMetadata().Options().RetentionOptions()
+		now        = time.Now()
+		earliest   = xtime.ToUnixNano(now.Add(-2 * ropts.RetentionPeriod()))
+		retained   = xtime.ToUnixNano(now.Truncate(ropts.BlockSize()))
+		expiredID  = ident.StringID("foo")
+		retainedID = ident.StringID("bar")
+	)
+
+	s.tombstones.add(expiredID, []xtime.UnixNano{earliest}, earliest.ToTime())
+	s.tombstones.add(retainedID, []xtime.UnixNano{retained}, retained.ToTime())
+
+	s.removeAnyTombstonesTooEarly(now)
+
+	assert.Equal(t, 1, s.tombstones.len())
+	assert.True(t, s.tombstones.contains(retainedID, retained.ToTime()))
+}
+
+func TestShardBootstrapAppliesTombstones(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		opts      = testDatabaseOptions()
+		blockSize = 2 * time.Hour
+		start     = time.Unix(21600, 0)
+		deletedAt = start.Add(blockSize).Add(time.Minute)
+		id        = ident.StringID("foo")
+	)
+
+	idx := NewMocknamespaceIndex(ctrl)
+	s := testDatabaseShardWithIndexFn(t, opts, idx)
+	defer s.Close()
+
+	s.readTombstonesFn = func(fs.Options, ident.ID, uint32) ([]fs.ShardTombstone, error) {
+		return []fs.ShardTombstone{
+			{ID: id.Bytes(), DeletedAt: deletedAt, BlockStarts: []time.Time{start}},
+		}, nil
+	}
+
+	// The series was not written again after being deleted so is excluded
+	// from the index once bootstrapped.
+	idx.EXPECT().Delete([]ident.ID{ident.StringID("foo")}).Return(nil)
+
+	blocks := block.NewDatabaseSeriesBlocks(0)
+	blocks.AddBlock(testEncodedBlock(t, opts, start, blockSize, start.Add(time.Minute)))
+	bootstrappedSeries := result.NewMap(result.MapOptions{})
+	bootstrappedSeries.Set(id, result.DatabaseSeriesBlocks{ID: id, Blocks: blocks})
+
+	require.NoError(t, s.Bootstrap(bootstrappedSeries))
+	assert.Equal(t, int64(0), s.NumSeries())
+	assert.True(t, s.tombstones.contains(id, start))
+	assert.Equal(t, []time.Time{start}, s.tombstones.pendingRewriteBlockStarts())
+}
+
+func TestShardRemoveDeletedData(t *testing.T) {
+	var (
+		opts      = testDatabaseOptions()
+		blockSize = 2 * time.Hour
+		start     = time.Unix(21600, 0)
+		spanning  = start.Add(blockSize)
+		later     = spanning.Add(blockSize)
+		deletedAt = spanning.Add(time.Hour)
+	)
+
+	s := testDatabaseShard(t, opts)
+	defer s.Close()
+
+	blocks := block.NewDatabaseSeriesBlocks(0)
+	blocks.AddBlock(testEncodedBlock(t, opts, start, blockSize, start.Add(time.Minute)))
+	blocks.AddBlock(testEncodedBlock(t, opts, spanning, blockSize,
+		spanning.Add(time.Minute), deletedAt, deletedAt.Add(time.Minute)))
+	blocks.AddBlock(testEncodedBlock(t, opts, later, blockSize, later.Add(time.Minute)))
+
+	remaining, err := s.removeDeletedData(blocks, deletedAt)
+	require.NoError(t, err)
+	require.True(t, remaining)
+	require.Equal(t, 2, blocks.Len())
+
+	_, ok := blocks.BlockAt(start)
+	assert.False(t, ok)
+
+	b, ok := blocks.BlockAt(spanning)
+	require.True(t, ok)
+	assert.Equal(t, []time.Time{deletedAt.Add(time.Minute)}, testBlockTimestamps(t, opts, b))
+
+	b, ok = blocks.BlockAt(later)
+	require.True(t, ok)
+	assert.Equal(t, []time.Time{later.Add(time.Minute)}, testBlockTimestamps(t, opts, b))
+
+	remaining, err = s.removeDeletedData(blocks, later.Add(blockSize))
+	require.NoError(t, err)
+	assert.False(t, remaining)
+}
+
+func testEncodedBlock(
+	t *testing.T,
+	opts Options,
+	start time.Time,
+	blockSize time.Duration,
+	timestamps ...time.Time,
+) block.DatabaseBlock {
+	bopts := opts.DatabaseBlockOptions()
+	encoder := bopts.EncoderPool().Get()
+	encoder.Reset(start, 0)
+	for i, at := range timestamps {
+		dp := ts.Datapoint{Timestamp: at, Value: float64(i)}
+		require.NoError(t, encoder.Encode(dp, xtime.Second, nil))
+	}
+	return block.NewDatabaseBlock(start, blockSize, encoder.Discard(), bopts)
+}
+
+func testBlockTimestamps(t *testing.T, opts Options, b block.DatabaseBlock) []time.Time {
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	stream, err := b.Stream(ctx)
+	require.NoError(t, err)
+
+	iter := opts.MultiReaderIteratorPool().Get()
+	defer iter.Close()
+	iter.Reset([]xio.SegmentReader{stream.SegmentReader}, b.StartTime(), b.BlockSize())
+
+	var timestamps []time.Time
+	for iter.Next() {
+		dp, _, _ := iter.Current()
+		timestamps = append(timestamps, dp.Timestamp)
+	}
+	require.NoError(t, iter.Err())
+	return timestamps
+}
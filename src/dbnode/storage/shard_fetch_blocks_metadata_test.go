@@ -171,7 +171,7 @@ func TestShardFetchBlocksMetadataV2WithSeriesCachePolicyNotCacheAll(t *testing.T
 
 		writerOpts := fs.DataWriterOpenOptions{
 			Identifier: fs.FileSetFileIdentifier{
-				Namespace:  shard.namespace.ID(),
+				Namespace:  shard.namespaceMetadata().ID(),
 				Shard:      shard.shard,
 				BlockStart: at,
 			},
@@ -0,0 +1,154 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/storage/block"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+)
+
+// shardRepairs tracks blocks fetched from peers by repair which differ from
+// the local replica. Repaired blocks are merged with the local data for the
+// block and persisted to the fileset volume during the next flush.
+//
+// NB: repaired blocks are only held in memory, so repairs that have not yet
+// been persisted are lost if the process restarts before the next flush and
+// will be repaired again by the next repair run.
+type shardRepairs struct {
+	sync.Mutex
+
+	byBlockStart map[xtime.UnixNano]map[string]seriesBlock
+}
+
+type seriesBlock struct {
+	id    ident.ID
+	tags  ident.Tags
+	block block.DatabaseBlock
+	// writtenAt is the earliest time the data of the block was written or
+	// received at, data written before a series was deleted is dropped.
+	writtenAt time.Time
+}
+
+func newShardRepairs() *shardRepairs {
+	return &shardRepairs{
+		byBlockStart: make(map[xtime.UnixNano]map[string]seriesBlock),
+	}
+}
+
+// add adds a repaired block for a series received at the given time, merging
+// it with any block already repaired for the series at the same block start.
+func (r *shardRepairs) add(
+	id ident.ID,
+	tags ident.Tags,
+	repaired block.DatabaseBlock,
+	receivedAt time.Time,
+) error {
+	blockStart := xtime.ToUnixNano(repaired.StartTime())
+
+	r.Lock()
+	defer r.Unlock()
+
+	bySeries, ok := r.byBlockStart[blockStart]
+	if !ok {
+		bySeries = make(map[string]seriesBlock)
+		r.byBlockStart[blockStart] = bySeries
+	}
+
+	existing, ok := bySeries[id.String()]
+	if !ok {
+		bySeries[id.String()] = seriesBlock{
+			id:        id,
+			tags:      tags,
+			block:     repaired,
+			writtenAt: receivedAt,
+		}
+		return nil
+	}
+
+	if len(existing.tags.Values()) == 0 && len(tags.Values()) > 0 {
+		existing.tags = tags
+		bySeries[id.String()] = existing
+	}
+
+	return existing.block.Merge(repaired)
+}
+
+// pendingBlockStarts returns the block starts with repaired blocks that
+// are yet to be persisted, in ascending order.
+func (r *shardRepairs) pendingBlockStarts() []time.Time {
+	r.Lock()
+	blockStarts := make([]time.Time, 0, len(r.byBlockStart))
+	for blockStart := range r.byBlockStart {
+		blockStarts = append(blockStarts, blockStart.ToTime())
+	}
+	r.Unlock()
+
+	sort.Slice(blockStarts, func(i, j int) bool {
+		return blockStarts[i].Before(blockStarts[j])
+	})
+	return blockStarts
+}
+
+// take removes and returns the repaired series for a block start.
+func (r *shardRepairs) take(blockStart time.Time) map[string]seriesBlock {
+	key := xtime.ToUnixNano(blockStart)
+
+	r.Lock()
+	bySeries := r.byBlockStart[key]
+	delete(r.byBlockStart, key)
+	r.Unlock()
+
+	return bySeries
+}
+
+// removeBefore removes repaired blocks before the given time, which have
+// fallen out of retention.
+func (r *shardRepairs) removeBefore(earliest time.Time) {
+	r.Lock()
+	defer r.Unlock()
+
+	for blockStart, bySeries := range r.byBlockStart {
+		if !blockStart.ToTime().Before(earliest) {
+			continue
+		}
+
+		for _, series := range bySeries {
+			series.block.Close()
+		}
+		delete(r.byBlockStart, blockStart)
+	}
+}
+
+// len returns the number of repaired blocks yet to be persisted.
+func (r *shardRepairs) len() int {
+	r.Lock()
+	n := 0
+	for _, bySeries := range r.byBlockStart {
+		n += len(bySeries)
+	}
+	r.Unlock()
+	return n
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/storage/block"
+	"github.com/m3db/m3x/ident"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardRepairsAddMergesSameBlockStart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		blockStart = time.Unix(7200, 0)
+		first      = block.NewMockDatabaseBlock(ctrl)
+		second     = block.NewMockDatabaseBlock(ctrl)
+		tags       = ident.NewTags(ident.StringTag("name", "value"))
+		repairs    = newShardRepairs()
+	)
+	first.EXPECT().StartTime().Return(blockStart).AnyTimes()
+	second.EXPECT().StartTime().Return(blockStart).AnyTimes()
+	first.EXPECT().Merge(second).Return(nil)
+
+	require.NoError(t, repairs.add(ident.StringID("foo"), ident.Tags{}, first, time.Now()))
+	require.NoError(t, repairs.add(ident.StringID("foo"), tags, second, time.Now()))
+	require.Equal(t, 1, repairs.len())
+
+	taken := repairs.take(blockStart)
+	require.Equal(t, 1, len(taken))
+	require.Equal(t, first, taken["foo"].block)
+	require.Equal(t, tags, taken["foo"].tags)
+	require.Equal(t, 0, repairs.len())
+	require.Equal(t, 0, len(repairs.take(blockStart)))
+}
+
+func TestShardRepairsPendingBlockStartsSorted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repairs := newShardRepairs()
+	blockStarts := []time.Time{time.Unix(14400, 0), time.Unix(0, 0), time.Unix(7200, 0)}
+	for _, blockStart := range blockStarts {
+		b := block.NewMockDatabaseBlock(ctrl)
+		b.EXPECT().StartTime().Return(blockStart).AnyTimes()
+		require.NoError(t, repairs.add(ident.StringID("foo"), ident.Tags{}, b, time.Now()))
+	}
+
+	require.Equal(t, []time.Time{
+		time.Unix(0, 0),
+		time.Unix(7200, 0),
+		time.Unix(14400, 0),
+	}, repairs.pendingBlockStarts())
+}
+
+func TestShardRepairsRemoveBeforeClosesBlocks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		expired = block.NewMockDatabaseBlock(ctrl)
+		current = block.NewMockDatabaseBlock(ctrl)
+		repairs = newShardRepairs()
+	)
+	expired.EXPECT().StartTime().Return(time.Unix(0, 0)).AnyTimes()
+	current.EXPECT().StartTime().Return(time.Unix(7200, 0)).AnyTimes()
+	expired.EXPECT().Close()
+
+	require.NoError(t, repairs.add(ident.StringID("foo"), ident.Tags{}, expired, time.Now()))
+	require.NoError(t, repairs.add(ident.StringID("bar"), ident.Tags{}, current, time.Now()))
+
+	repairs.removeBefore(time.Unix(7200, 0))
+	require.Equal(t, []time.Time{time.Unix(7200, 0)}, repairs.pendingBlockStarts())
+	require.Equal(t, 1, repairs.len())
+}
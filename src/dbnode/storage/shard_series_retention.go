@@ -0,0 +1,142 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	xtime "github.com/m3db/m3x/time"
+)
+
+// shardSeriesRetention tracks the flushed blocks of a shard with volumes
+// holding series whose retention, given by the series retention rules of
+// the namespace, has expired. Cleanup marks blocks as expired once they
+// are older than the retention period of a rule, and the volumes are
+// rewritten without the expired series during the next flush.
+//
+// NB: the retention period already expired from a volume is also recorded in
+// its info file when it is rewritten, so that it is not rewritten again after
+// the process restarts, see markLoaded.
+type shardSeriesRetention struct {
+	sync.Mutex
+
+	// applied is the longest retention period already expired from the
+	// volume of each flushed block.
+	applied        map[xtime.UnixNano]time.Duration
+	pendingRewrite map[xtime.UnixNano]time.Duration
+}
+
+func newShardSeriesRetention() *shardSeriesRetention {
+	return &shardSeriesRetention{
+		applied:        make(map[xtime.UnixNano]time.Duration),
+		pendingRewrite: make(map[xtime.UnixNano]time.Duration),
+	}
+}
+
+// markExpired records that series with a retention period up to the given
+// period have expired from a flushed block, scheduling a rewrite of its
+// volume unless they have already been expired from it.
+func (r *shardSeriesRetention) markExpired(blockStart time.Time, expired time.Duration) {
+	if expired <= 0 {
+		return
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	key := xtime.ToUnixNano(blockStart)
+	if expired <= r.applied[key] || expired <= r.pendingRewrite[key] {
+		return
+	}
+	r.pendingRewrite[key] = expired
+}
+
+// pendingRewriteBlockStarts returns the flushed blocks with volumes which
+// still contain expired series, in ascending order.
+func (r *shardSeriesRetention) pendingRewriteBlockStarts() []time.Time {
+	r.Lock()
+	blockStarts := make([]time.Time, 0, len(r.pendingRewrite))
+	for blockStart := range r.pendingRewrite {
+		blockStarts = append(blockStarts, blockStart.ToTime())
+	}
+	r.Unlock()
+
+	sort.Slice(blockStarts, func(i, j int) bool {
+		return blockStarts[i].Before(blockStarts[j])
+	})
+	return blockStarts
+}
+
+// markRewritten records that series with a retention period up to the
+// given period have been expired from the volume of a block.
+func (r *shardSeriesRetention) markRewritten(blockStart time.Time, expired time.Duration) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.markAppliedWithLock(xtime.ToUnixNano(blockStart), expired)
+}
+
+// isLoaded returns whether the retention period already expired from the
+// volume of a block is known, either since it was rewritten or loaded.
+func (r *shardSeriesRetention) isLoaded(blockStart time.Time) bool {
+	r.Lock()
+	_, ok := r.applied[xtime.ToUnixNano(blockStart)]
+	r.Unlock()
+	return ok
+}
+
+// markLoaded records the retention period already expired from the volume of
+// a block as recorded in its info file.
+func (r *shardSeriesRetention) markLoaded(blockStart time.Time, expired time.Duration) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.markAppliedWithLock(xtime.ToUnixNano(blockStart), expired)
+}
+
+func (r *shardSeriesRetention) markAppliedWithLock(key xtime.UnixNano, expired time.Duration) {
+	if applied, ok := r.applied[key]; !ok || expired > applied {
+		r.applied[key] = expired
+	}
+	if r.pendingRewrite[key] <= r.applied[key] {
+		delete(r.pendingRewrite, key)
+	}
+}
+
+// removeBefore removes the state for blocks before the given time, which
+// have fallen out of retention.
+func (r *shardSeriesRetention) removeBefore(earliest time.Time) {
+	r.Lock()
+	defer r.Unlock()
+
+	for blockStart := range r.applied {
+		if blockStart.ToTime().Before(earliest) {
+			delete(r.applied, blockStart)
+		}
+	}
+	for blockStart := range r.pendingRewrite {
+		if blockStart.ToTime().Before(earliest) {
+			delete(r.pendingRewrite, blockStart)
+		}
+	}
+}
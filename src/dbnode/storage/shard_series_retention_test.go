@@ -0,0 +1,215 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/dbnode/storage/namespace"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3x/checked"
+	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/pool"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func TestShardSeriesRetentionMarkExpired(t *testing.T) {
+	var (
+		retention = newShardSeriesRetention()
+		blockSize = 2 * time.Hour
+		start     = time.Now().Truncate(blockSize)
+	)
+
+	retention.markExpired(start, 0)
+	assert.Equal(t, 0, len(retention.pendingRewriteBlockStarts()))
+
+	retention.markExpired(start, 6*time.Hour)
+	assert.Equal(t, []time.Time{start}, retention.pendingRewriteBlockStarts())
+
+	retention.markRewritten(start, 6*time.Hour)
+	assert.Equal(t, 0, len(retention.pendingRewriteBlockStarts()))
+
+	// Already expired from the volume.
+	retention.markExpired(start, 6*time.Hour)
+	assert.Equal(t, 0, len(retention.pendingRewriteBlockStarts()))
+
+	// Rules with a longer retention period expiring require another rewrite.
+	retention.markExpired(start, 24*time.Hour)
+	assert.Equal(t, []time.Time{start}, retention.pendingRewriteBlockStarts())
+
+	retention.removeBefore(start.Add(blockSize))
+	assert.Equal(t, 0, len(retention.pendingRewriteBlockStarts()))
+	assert.Equal(t, 0, len(retention.applied))
+}
+
+func TestShardFlushDeletesDropsExpiredSeries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		blockSize  = defaultTestRetentionOpts.BlockSize()
+		now        = time.Now()
+		blockStart = now.Truncate(blockSize).Add(-4 * blockSize)
+		opts       = testDatabaseOptions()
+	)
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return now
+	}))
+
+	nsOpts := defaultTestNs1Opts.SetSeriesRetentionRules(namespace.SeriesRetentionRules{
+		{TagName: "debug", TagValue: "true", RetentionPeriod: blockSize},
+	})
+	metadata, err := namespace.NewMetadata(defaultTestNs1ID, nsOpts)
+	require.NoError(t, err)
+	nsReaderMgr := newNamespaceReaderManager(metadata, tally.NoopScope, opts)
+	seriesOpts := NewSeriesOptionsFromOptions(opts, nsOpts.RetentionOptions())
+	s := newDatabaseShard(metadata, 0, nil, nsReaderMgr,
+		&testIncreasingIndex{}, nil, true, opts, seriesOpts).(*dbShard)
+	defer s.Close()
+	s.bootstrapState = Bootstrapped
+	s.markFlushStateSuccess(blockStart)
+
+	s.filesetBeforeFn = func(string, ident.ID, uint32, time.Time) ([]string, error) {
+		return nil, nil
+	}
+	s.deleteFilesFn = func([]string) error { return nil }
+
+	// Cleanup schedules the block for a rewrite since it is older than the
+	// retention of the rule.
+	require.NoError(t, s.CleanupExpiredFileSets(now.Add(-nsOpts.RetentionOptions().RetentionPeriod())))
+	assert.Equal(t, []time.Time{blockStart}, s.seriesRetention.pendingRewriteBlockStarts())
+
+	reader := fs.NewMockDataFileSetReader(ctrl)
+	s.newReaderFn = func(pool.CheckedBytesPool, fs.Options) (fs.DataFileSetReader, error) {
+		return reader, nil
+	}
+
+	debugTags := ident.NewTags(ident.StringTag("debug", "true"))
+	gomock.InOrder(
+		reader.EXPECT().Open(fs.DataReaderOpenOptions{
+			Identifier: fs.FileSetFileIdentifier{
+				Namespace:  s.namespaceMetadata().ID(),
+				Shard:      s.ID(),
+				BlockStart: blockStart,
+			},
+			FileSetType: persist.FileSetFlushType,
+		}).Return(nil),
+		reader.EXPECT().Read().Return(ident.StringID("foo"), ident.EmptyTagIterator,
+			checked.NewBytes([]byte{1, 2, 3}, nil), uint32(1), nil),
+		reader.EXPECT().Read().Return(ident.StringID("bar"), ident.NewTagsIterator(debugTags),
+			checked.NewBytes([]byte{4, 5, 6}, nil), uint32(2), nil),
+		reader.EXPECT().Read().Return(nil, nil, nil, uint32(0), io.EOF),
+		reader.EXPECT().Close().Return(nil),
+	)
+
+	var persisted []string
+	flush := persist.NewMockDataFlush(ctrl)
+	flush.EXPECT().PrepareData(persist.DataPrepareOptions{
+		NamespaceMetadata:            s.namespaceMetadata(),
+		Shard:                        s.ID(),
+		BlockStart:                   blockStart,
+		FileSetType:                  persist.FileSetFlushType,
+		DeleteIfExists:               true,
+		ExpiredSeriesRetentionPeriod: blockSize,
+	}).Return(persist.PreparedDataPersist{
+		Persist: func(id ident.ID, _ ident.Tags, _ ts.Segment, _ uint32) error {
+			persisted = append(persisted, id.String())
+			return nil
+		},
+		Close: func() error { return nil },
+	}, nil)
+
+	require.NoError(t, s.FlushDeletes(flush))
+	assert.Equal(t, []string{"foo"}, persisted)
+	assert.Equal(t, 0, len(s.seriesRetention.pendingRewriteBlockStarts()))
+
+	// Cleaning up again does not schedule another rewrite.
+	require.NoError(t, s.CleanupExpiredFileSets(now.Add(-nsOpts.RetentionOptions().RetentionPeriod())))
+	assert.Equal(t, 0, len(s.seriesRetention.pendingRewriteBlockStarts()))
+	require.NoError(t, s.FlushDeletes(flush))
+}
+
+func TestShardSeriesRetentionLoadedFromInfoFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shard-series-retention")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		blockSize   = defaultTestRetentionOpts.BlockSize()
+		now         = time.Now()
+		rewritten   = now.Truncate(blockSize).Add(-4 * blockSize)
+		unrewritten = rewritten.Add(blockSize)
+		opts        = testDatabaseOptions()
+	)
+	fsOpts := opts.CommitLogOptions().FilesystemOptions().SetFilePathPrefix(dir)
+	opts = opts.
+		SetCommitLogOptions(opts.CommitLogOptions().SetFilesystemOptions(fsOpts)).
+		SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time { return now }))
+
+	nsOpts := defaultTestNs1Opts.SetSeriesRetentionRules(namespace.SeriesRetentionRules{
+		{TagName: "debug", TagValue: "true", RetentionPeriod: blockSize},
+	})
+	metadata, err := namespace.NewMetadata(defaultTestNs1ID, nsOpts)
+	require.NoError(t, err)
+	nsReaderMgr := newNamespaceReaderManager(metadata, tally.NoopScope, opts)
+	seriesOpts := NewSeriesOptionsFromOptions(opts, nsOpts.RetentionOptions())
+	s := newDatabaseShard(metadata, 0, nil, nsReaderMgr,
+		&testIncreasingIndex{}, nil, true, opts, seriesOpts).(*dbShard)
+	defer s.Close()
+	s.bootstrapState = Bootstrapped
+
+	// Only the first fileset was rewritten without the expired series before
+	// the shard was opened.
+	expired := map[time.Time]time.Duration{rewritten: blockSize, unrewritten: 0}
+	for blockStart, period := range expired {
+		writer, err := fs.NewWriter(fsOpts)
+		require.NoError(t, err)
+		require.NoError(t, writer.Open(fs.DataWriterOpenOptions{
+			Identifier: fs.FileSetFileIdentifier{
+				Namespace:  s.namespaceMetadata().ID(),
+				Shard:      s.ID(),
+				BlockStart: blockStart,
+			},
+			BlockSize:                    blockSize,
+			ExpiredSeriesRetentionPeriod: period,
+		}))
+		require.NoError(t, writer.Close())
+		s.markFlushStateSuccess(blockStart)
+	}
+
+	s.filesetBeforeFn = func(string, ident.ID, uint32, time.Time) ([]string, error) {
+		return nil, nil
+	}
+	s.deleteFilesFn = func([]string) error { return nil }
+
+	require.NoError(t, s.CleanupExpiredFileSets(now.Add(-nsOpts.RetentionOptions().RetentionPeriod())))
+	assert.Equal(t, []time.Time{unrewritten}, s.seriesRetention.pendingRewriteBlockStarts())
+}
@@ -49,6 +49,7 @@ import (
 	xtime "github.com/m3db/m3x/time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/uber-go/tally"
@@ -207,7 +208,7 @@ func TestShardFlushSeriesFlushError(t *testing.T) {
 		Close:   func() error { closed = true; return nil },
 	}
 	prepareOpts := xtest.CmpMatcher(persist.DataPrepareOptions{
-		NamespaceMetadata: s.namespace,
+		NamespaceMetadata: s.namespaceMetadata(),
 		Shard:             s.shard,
 		BlockStart:        blockStart,
 	})
@@ -273,7 +274,7 @@ func TestShardFlushSeriesFlushSuccess(t *testing.T) {
 	}
 
 	prepareOpts := xtest.CmpMatcher(persist.DataPrepareOptions{
-		NamespaceMetadata: s.namespace,
+		NamespaceMetadata: s.namespaceMetadata(),
 		Shard:             s.shard,
 		BlockStart:        blockStart,
 	})
@@ -323,7 +324,7 @@ func TestShardSnapshotShardNotBootstrapped(t *testing.T) {
 	s.bootstrapState = Bootstrapping
 
 	flush := persist.NewMockDataFlush(ctrl)
-	err := s.Snapshot(blockStart, blockStart, flush)
+	err := s.Snapshot(blockStart, blockStart, uuid.NewRandom(), flush)
 	require.Equal(t, errShardNotBootstrappedToSnapshot, err)
 }
 
@@ -331,7 +332,10 @@ func TestShardSnapshotSeriesSnapshotSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	blockStart := time.Unix(21600, 0)
+	var (
+		blockStart = time.Unix(21600, 0)
+		snapshotID = uuid.NewRandom()
+	)
 
 	s := testDatabaseShard(t, testDatabaseOptions())
 	defer s.Close()
@@ -345,12 +349,13 @@ func TestShardSnapshotSeriesSnapshotSuccess(t *testing.T) {
 	}
 
 	prepareOpts := xtest.CmpMatcher(persist.DataPrepareOptions{
-		NamespaceMetadata: s.namespace,
+		NamespaceMetadata: s.namespaceMetadata(),
 		Shard:             s.shard,
 		BlockStart:        blockStart,
 		FileSetType:       persist.FileSetSnapshotType,
 		Snapshot: persist.DataPrepareSnapshotOptions{
 			SnapshotTime: blockStart,
+			SnapshotID:   snapshotID,
 		},
 	})
 	flush.EXPECT().PrepareData(prepareOpts).Return(prepared, nil)
@@ -370,7 +375,7 @@ func TestShardSnapshotSeriesSnapshotSuccess(t *testing.T) {
 		s.list.PushBack(lookup.NewEntry(series, 0))
 	}
 
-	err := s.Snapshot(blockStart, blockStart, flush)
+	err := s.Snapshot(blockStart, blockStart, snapshotID, flush)
 
 	require.Equal(t, len(snapshotted), 2)
 	for i := 0; i < 2; i++ {
@@ -396,7 +401,7 @@ func addTestSeries(shard *dbShard, id ident.ID) series.DatabaseSeries {
 }
 
 func addTestSeriesWithCount(shard *dbShard, id ident.ID, count int32) series.DatabaseSeries {
-	series := series.NewDatabaseSeries(id, ident.Tags{}, shard.seriesOpts)
+	series := series.NewDatabaseSeries(id, ident.Tags{}, shard.seriesOptions())
 	series.Bootstrap(nil)
 	shard.Lock()
 	entry := lookup.NewEntry(series, 0)
@@ -1006,7 +1011,7 @@ func TestShardReadEncodedCachesSeriesWithRecentlyReadPolicy(t *testing.T) {
 	shard := testDatabaseShard(t, opts)
 	defer shard.Close()
 
-	ropts := shard.seriesOpts.RetentionOptions()
+	ropts := shard.seriesOptions().RetentionOptions()
 	end := opts.ClockOptions().NowFn()().Truncate(ropts.BlockSize())
 	start := end.Add(-2 * ropts.BlockSize())
 	shard.markFlushStateSuccess(start)
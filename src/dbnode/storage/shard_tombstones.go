@@ -0,0 +1,331 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+)
+
+// shardTombstones tracks series deleted from a shard whose data may still
+// exist in fileset volumes flushed before they were deleted, or in commit
+// logs and snapshots written before they were deleted. Data for a deleted
+// series is excluded when read from those volumes, and the volumes are
+// rewritten without the deleted series during the next flush.
+//
+// NB: tombstones are only dropped once the data they cover falls out of
+// retention rather than as soon as the volumes are rewritten since seekers
+// opened before a volume was rewritten may continue to serve deleted data,
+// and commit logs and snapshots replayed at bootstrap may still contain it.
+// Tombstones are persisted so that they are applied to the data bootstrapped
+// after a restart.
+type shardTombstones struct {
+	sync.RWMutex
+
+	seq              uint64
+	version          uint64
+	persistedVersion uint64
+	byID             map[string]*shardTombstone
+	pendingRewrite   map[xtime.UnixNano]struct{}
+}
+
+type shardTombstone struct {
+	seq         uint64
+	deletedAt   time.Time
+	blockStarts map[xtime.UnixNano]struct{}
+}
+
+func newShardTombstones() *shardTombstones {
+	return &shardTombstones{
+		byID:           make(map[string]*shardTombstone),
+		pendingRewrite: make(map[xtime.UnixNano]struct{}),
+	}
+}
+
+// add tombstones a series deleted at the given time, excluding its data from
+// the given flushed blocks.
+func (t *shardTombstones) add(id ident.ID, flushed []xtime.UnixNano, deletedAt time.Time) {
+	t.Lock()
+	t.seq++
+	t.version++
+	tombstone, ok := t.byID[string(id.Bytes())]
+	if !ok {
+		tombstone = &shardTombstone{
+			blockStarts: make(map[xtime.UnixNano]struct{}, len(flushed)),
+		}
+		t.byID[string(id.Bytes())] = tombstone
+	}
+	tombstone.seq = t.seq
+	if deletedAt.After(tombstone.deletedAt) {
+		tombstone.deletedAt = deletedAt
+	}
+	for _, blockStart := range flushed {
+		tombstone.blockStarts[blockStart] = struct{}{}
+		t.pendingRewrite[blockStart] = struct{}{}
+	}
+	t.Unlock()
+}
+
+// restore adds tombstones persisted before the process restarted, the
+// volumes for the blocks they cover are rewritten again in case the process
+// stopped before they were.
+func (t *shardTombstones) restore(tombstones []fs.ShardTombstone) {
+	t.Lock()
+	defer t.Unlock()
+
+	merged := false
+	for _, restored := range tombstones {
+		tombstone, ok := t.byID[string(restored.ID)]
+		if ok {
+			merged = true
+		} else {
+			tombstone = &shardTombstone{
+				blockStarts: make(map[xtime.UnixNano]struct{}, len(restored.BlockStarts)),
+			}
+			t.byID[string(restored.ID)] = tombstone
+		}
+		if restored.DeletedAt.After(tombstone.deletedAt) {
+			tombstone.deletedAt = restored.DeletedAt
+		}
+		for _, blockStart := range restored.BlockStarts {
+			tombstone.blockStarts[xtime.ToUnixNano(blockStart)] = struct{}{}
+			t.pendingRewrite[xtime.ToUnixNano(blockStart)] = struct{}{}
+		}
+	}
+
+	if merged {
+		// Tombstones added before restoring are persisted with the
+		// restored tombstones.
+		t.version++
+	}
+}
+
+// contains returns whether the series data for a flushed block is deleted.
+func (t *shardTombstones) contains(id ident.ID, blockStart time.Time) bool {
+	t.RLock()
+	defer t.RUnlock()
+
+	if len(t.byID) == 0 {
+		return false
+	}
+
+	tombstone, ok := t.byID[string(id.Bytes())]
+	if !ok {
+		return false
+	}
+
+	_, ok = tombstone.blockStarts[xtime.ToUnixNano(blockStart)]
+	return ok
+}
+
+// deletes returns whether the series data for a flushed block written at the
+// given time is deleted, data written after the series was last deleted is
+// not.
+func (t *shardTombstones) deletes(id ident.ID, blockStart time.Time, writtenAt time.Time) bool {
+	t.RLock()
+	defer t.RUnlock()
+
+	if len(t.byID) == 0 {
+		return false
+	}
+
+	tombstone, ok := t.byID[string(id.Bytes())]
+	if !ok {
+		return false
+	}
+
+	_, ok = tombstone.blockStarts[xtime.ToUnixNano(blockStart)]
+	return ok && !writtenAt.After(tombstone.deletedAt)
+}
+
+// removeBlock removes a flushed block from the tombstone of a series once
+// the block has been rewritten with data written after the series was
+// deleted, so that the data is no longer excluded when read.
+func (t *shardTombstones) removeBlock(id ident.ID, blockStart time.Time) {
+	t.Lock()
+	defer t.Unlock()
+
+	tombstone, ok := t.byID[string(id.Bytes())]
+	if !ok {
+		return
+	}
+
+	key := xtime.ToUnixNano(blockStart)
+	if _, ok := tombstone.blockStarts[key]; ok {
+		delete(tombstone.blockStarts, key)
+		t.version++
+	}
+}
+
+// deletedAt returns the time a series was last deleted at, if it was deleted.
+func (t *shardTombstones) deletedAt(id ident.ID) (time.Time, bool) {
+	t.RLock()
+	defer t.RUnlock()
+
+	if len(t.byID) == 0 {
+		return time.Time{}, false
+	}
+
+	tombstone, ok := t.byID[string(id.Bytes())]
+	if !ok {
+		return time.Time{}, false
+	}
+	return tombstone.deletedAt, true
+}
+
+// ids returns the IDs of the tombstoned series.
+func (t *shardTombstones) ids() []ident.ID {
+	t.RLock()
+	ids := make([]ident.ID, 0, len(t.byID))
+	for id := range t.byID {
+		ids = append(ids, ident.StringID(id))
+	}
+	t.RUnlock()
+	return ids
+}
+
+// currentSeq returns the sequence number of the latest tombstone, used to
+// find series deleted while a block was being flushed.
+func (t *shardTombstones) currentSeq() uint64 {
+	t.RLock()
+	seq := t.seq
+	t.RUnlock()
+	return seq
+}
+
+// onFlushed records a successful flush of a block which started when the
+// latest tombstone had the given sequence number. Series deleted while the
+// block was being flushed may have been written to the volume, so the block
+// is tombstoned for them too.
+func (t *shardTombstones) onFlushed(blockStart time.Time, seqAtFlushStart uint64) {
+	t.Lock()
+	defer t.Unlock()
+
+	flushed := xtime.ToUnixNano(blockStart)
+	for _, tombstone := range t.byID {
+		if tombstone.seq > seqAtFlushStart {
+			tombstone.blockStarts[flushed] = struct{}{}
+			t.pendingRewrite[flushed] = struct{}{}
+			t.version++
+		}
+	}
+}
+
+// pendingRewriteBlockStarts returns the flushed blocks with volumes which
+// still contain deleted series, in ascending order.
+func (t *shardTombstones) pendingRewriteBlockStarts() []time.Time {
+	t.RLock()
+	blockStarts := make([]time.Time, 0, len(t.pendingRewrite))
+	for blockStart := range t.pendingRewrite {
+		blockStarts = append(blockStarts, blockStart.ToTime())
+	}
+	t.RUnlock()
+
+	sort.Slice(blockStarts, func(i, j int) bool {
+		return blockStarts[i].Before(blockStarts[j])
+	})
+	return blockStarts
+}
+
+// markRewritten records that the volume for a block no longer contains any
+// deleted series.
+func (t *shardTombstones) markRewritten(blockStart time.Time) {
+	t.Lock()
+	delete(t.pendingRewrite, xtime.ToUnixNano(blockStart))
+	t.Unlock()
+}
+
+// removeBefore removes tombstones for blocks before the given time, which
+// have fallen out of retention. Tombstones for series deleted before the
+// given time are removed too since any data they cover has fallen out of
+// retention.
+func (t *shardTombstones) removeBefore(earliest time.Time) {
+	t.Lock()
+	defer t.Unlock()
+
+	for blockStart := range t.pendingRewrite {
+		if blockStart.ToTime().Before(earliest) {
+			delete(t.pendingRewrite, blockStart)
+		}
+	}
+
+	for id, tombstone := range t.byID {
+		for blockStart := range tombstone.blockStarts {
+			if blockStart.ToTime().Before(earliest) {
+				delete(tombstone.blockStarts, blockStart)
+				t.version++
+			}
+		}
+
+		if len(tombstone.blockStarts) == 0 && tombstone.deletedAt.Before(earliest) {
+			delete(t.byID, id)
+			t.version++
+		}
+	}
+}
+
+// unpersisted returns the tombstones along with their version if they have
+// changed since they were last persisted.
+func (t *shardTombstones) unpersisted() ([]fs.ShardTombstone, uint64, bool) {
+	t.RLock()
+	defer t.RUnlock()
+
+	if t.version == t.persistedVersion {
+		return nil, 0, false
+	}
+
+	tombstones := make([]fs.ShardTombstone, 0, len(t.byID))
+	for id, tombstone := range t.byID {
+		blockStarts := make([]time.Time, 0, len(tombstone.blockStarts))
+		for blockStart := range tombstone.blockStarts {
+			blockStarts = append(blockStarts, blockStart.ToTime())
+		}
+		tombstones = append(tombstones, fs.ShardTombstone{
+			ID:          []byte(id),
+			DeletedAt:   tombstone.deletedAt,
+			BlockStarts: blockStarts,
+		})
+	}
+	return tombstones, t.version, true
+}
+
+// markPersisted records that the tombstones at the given version have been
+// persisted.
+func (t *shardTombstones) markPersisted(version uint64) {
+	t.Lock()
+	if version > t.persistedVersion {
+		t.persistedVersion = version
+	}
+	t.Unlock()
+}
+
+// len returns the number of tombstoned series.
+func (t *shardTombstones) len() int {
+	t.RLock()
+	n := len(t.byID)
+	t.RUnlock()
+	return n
+}
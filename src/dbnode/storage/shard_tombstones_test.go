@@ -0,0 +1,189 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardTombstonesContains(t *testing.T) {
+	var (
+		tombstones = newShardTombstones()
+		blockSize  = 2 * time.Hour
+		start      = time.Now().Truncate(blockSize)
+		foo        = ident.StringID("foo")
+		bar        = ident.StringID("bar")
+	)
+
+	tombstones.add(foo, []xtime.UnixNano{xtime.ToUnixNano(start)}, start.Add(blockSize))
+
+	assert.True(t, tombstones.contains(foo, start))
+	assert.False(t, tombstones.contains(foo, start.Add(blockSize)))
+	assert.False(t, tombstones.contains(bar, start))
+	assert.Equal(t, 1, tombstones.len())
+	assert.Equal(t, []time.Time{start}, tombstones.pendingRewriteBlockStarts())
+
+	tombstones.markRewritten(start)
+	assert.Equal(t, 0, len(tombstones.pendingRewriteBlockStarts()))
+
+	// Still excluded from reads after being rewritten.
+	assert.True(t, tombstones.contains(foo, start))
+}
+
+func TestShardTombstonesDeletesWrittenBefore(t *testing.T) {
+	var (
+		tombstones = newShardTombstones()
+		blockSize  = 2 * time.Hour
+		start      = time.Now().Truncate(blockSize)
+		deletedAt  = start.Add(blockSize)
+		foo        = ident.StringID("foo")
+		bar        = ident.StringID("bar")
+	)
+
+	tombstones.add(foo, []xtime.UnixNano{xtime.ToUnixNano(start)}, deletedAt)
+
+	assert.True(t, tombstones.deletes(foo, start, deletedAt.Add(-time.Minute)))
+	assert.True(t, tombstones.deletes(foo, start, deletedAt))
+	assert.False(t, tombstones.deletes(foo, start, deletedAt.Add(time.Minute)))
+	assert.False(t, tombstones.deletes(foo, start.Add(blockSize), start))
+	assert.False(t, tombstones.deletes(bar, start, start))
+
+	// Once rewritten with data written after the delete the block is no
+	// longer excluded from reads.
+	tombstones.removeBlock(foo, start)
+	assert.False(t, tombstones.contains(foo, start))
+	assert.Equal(t, 1, tombstones.len())
+}
+
+func TestShardTombstonesOnFlushed(t *testing.T) {
+	var (
+		tombstones = newShardTombstones()
+		blockSize  = 2 * time.Hour
+		start      = time.Now().Truncate(blockSize)
+		foo        = ident.StringID("foo")
+		bar        = ident.StringID("bar")
+	)
+
+	// Deleted before the flush started without any flushed blocks.
+	tombstones.add(foo, nil, start)
+	seq := tombstones.currentSeq()
+
+	// Deleted while the flush was in progress.
+	tombstones.add(bar, nil, start)
+
+	tombstones.onFlushed(start, seq)
+
+	assert.False(t, tombstones.contains(foo, start))
+	assert.True(t, tombstones.contains(bar, start))
+	assert.Equal(t, 2, tombstones.len())
+	assert.Equal(t, []time.Time{start}, tombstones.pendingRewriteBlockStarts())
+}
+
+func TestShardTombstonesRemoveBefore(t *testing.T) {
+	var (
+		tombstones = newShardTombstones()
+		blockSize  = 2 * time.Hour
+		start      = time.Now().Truncate(blockSize)
+		next       = start.Add(blockSize)
+		foo        = ident.StringID("foo")
+		bar        = ident.StringID("bar")
+	)
+
+	tombstones.add(foo, []xtime.UnixNano{xtime.ToUnixNano(start)}, start)
+	tombstones.add(bar, []xtime.UnixNano{
+		xtime.ToUnixNano(start),
+		xtime.ToUnixNano(next),
+	}, start)
+	require.Equal(t, []time.Time{start, next}, tombstones.pendingRewriteBlockStarts())
+
+	tombstones.removeBefore(next)
+
+	assert.Equal(t, 1, tombstones.len())
+	assert.False(t, tombstones.contains(foo, start))
+	assert.False(t, tombstones.contains(bar, start))
+	assert.True(t, tombstones.contains(bar, next))
+	assert.Equal(t, []time.Time{next}, tombstones.pendingRewriteBlockStarts())
+}
+
+func TestShardTombstonesPersistRestore(t *testing.T) {
+	var (
+		tombstones = newShardTombstones()
+		blockSize  = 2 * time.Hour
+		start      = time.Now().Truncate(blockSize)
+		deletedAt  = start.Add(blockSize).Add(time.Minute)
+		foo        = ident.StringID("foo")
+	)
+
+	_, _, ok := tombstones.unpersisted()
+	require.False(t, ok)
+
+	tombstones.add(foo, []xtime.UnixNano{xtime.ToUnixNano(start)}, deletedAt)
+	persisted, version, ok := tombstones.unpersisted()
+	require.True(t, ok)
+	require.Equal(t, 1, len(persisted))
+	assert.Equal(t, foo.Bytes(), persisted[0].ID)
+	assert.True(t, deletedAt.Equal(persisted[0].DeletedAt))
+	assert.Equal(t, []time.Time{start}, persisted[0].BlockStarts)
+
+	tombstones.markPersisted(version)
+	_, _, ok = tombstones.unpersisted()
+	require.False(t, ok)
+
+	// Restored tombstones are rewritten again in case they were not
+	// before the process restarted.
+	restored := newShardTombstones()
+	restored.restore(persisted)
+	assert.True(t, restored.contains(foo, start))
+	assert.Equal(t, []time.Time{start}, restored.pendingRewriteBlockStarts())
+	restoredAt, ok := restored.deletedAt(foo)
+	require.True(t, ok)
+	assert.True(t, deletedAt.Equal(restoredAt))
+	_, _, ok = restored.unpersisted()
+	require.False(t, ok)
+}
+
+func TestShardTombstonesRemoveBeforeRetainsRecentDeletes(t *testing.T) {
+	var (
+		tombstones = newShardTombstones()
+		blockSize  = 2 * time.Hour
+		start      = time.Now().Truncate(blockSize)
+		next       = start.Add(blockSize)
+		foo        = ident.StringID("foo")
+	)
+
+	// Deleted series without flushed blocks are retained until the time they
+	// were deleted falls out of retention as the commit logs and snapshots
+	// may still contain their data.
+	tombstones.add(foo, nil, next)
+
+	tombstones.removeBefore(next)
+	assert.Equal(t, 1, tombstones.len())
+
+	tombstones.removeBefore(next.Add(blockSize))
+	assert.Equal(t, 0, tombstones.len())
+}
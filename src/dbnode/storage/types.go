@@ -29,12 +29,14 @@ import (
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
+	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/sharding"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/storage/repair"
 	"github.com/m3db/m3/src/dbnode/storage/series"
@@ -47,6 +49,8 @@ import (
 	"github.com/m3db/m3x/pool"
 	xsync "github.com/m3db/m3x/sync"
 	xtime "github.com/m3db/m3x/time"
+
+	"github.com/pborman/uuid"
 )
 
 // PageToken is an opaque paging token.
@@ -139,6 +143,15 @@ type Database interface {
 		opts index.QueryOptions,
 	) (index.QueryResults, error)
 
+	// AggregateQuery resolves the given query into the distinct tag names
+	// and values of the matching series.
+	AggregateQuery(
+		ctx context.Context,
+		namespace ident.ID,
+		query index.Query,
+		opts index.AggregationOptions,
+	) (index.AggregateQueryResult, error)
+
 	// ReadEncoded retrieves encoded segments for an ID
 	ReadEncoded(
 		ctx context.Context,
@@ -189,6 +202,22 @@ type Database interface {
 	// Truncate truncates data for the given namespace
 	Truncate(namespace ident.ID) (int64, error)
 
+	// DeleteSeries deletes the series with the given IDs from the namespace,
+	// returning the IDs of the series deleted.
+	DeleteSeries(
+		ctx context.Context,
+		namespace ident.ID,
+		ids []ident.ID,
+	) ([]ident.ID, error)
+
+	// DeleteTagged deletes the series matching the given query from the
+	// namespace, returning the IDs of the series deleted.
+	DeleteTagged(
+		ctx context.Context,
+		namespace ident.ID,
+		query index.Query,
+	) ([]ident.ID, error)
+
 	// BootstrapState captures and returns a snapshot of the databases' bootstrap state.
 	BootstrapState() DatabaseBootstrapState
 }
@@ -217,6 +246,9 @@ type Namespace interface {
 
 	// Shards returns the shard description
 	Shards() []Shard
+
+	// QueryLimits returns the rolling lookback query limits of the namespace
+	QueryLimits() limits.QueryLimits
 }
 
 // NamespacesByID is a sortable slice of namespaces by ID
@@ -274,6 +306,14 @@ type databaseNamespace interface {
 		opts index.QueryOptions,
 	) (index.QueryResults, error)
 
+	// AggregateQuery resolves the given query into the distinct tag names
+	// and values of the matching series.
+	AggregateQuery(
+		ctx context.Context,
+		query index.Query,
+		opts index.AggregationOptions,
+	) (index.AggregateQueryResult, error)
+
 	// ReadEncoded reads data for given id within [start, end)
 	ReadEncoded(
 		ctx context.Context,
@@ -318,6 +358,7 @@ type databaseNamespace interface {
 	Snapshot(
 		blockStart,
 		snapshotTime time.Time,
+		snapshotID uuid.UUID,
 		shardBootstrapStatesAtTickStart ShardBootstrapStates,
 		flush persist.DataFlush,
 	) error
@@ -340,11 +381,42 @@ type databaseNamespace interface {
 	// Truncate truncates the in-memory data for this namespace
 	Truncate() (int64, error)
 
+	// DeleteSeries deletes the series with the given IDs, excluding them from
+	// reads until flushed data has been rewritten without them. IDs belonging
+	// to shards not owned by the namespace are ignored.
+	// The IDs of the series deleted are returned.
+	DeleteSeries(ctx context.Context, ids []ident.ID) ([]ident.ID, error)
+
+	// DeleteTagged deletes the series matching the given query, returning
+	// the IDs of the series deleted.
+	DeleteTagged(ctx context.Context, query index.Query) ([]ident.ID, error)
+
+	// FlushDeletes rewrites flushed data without any deleted series or
+	// series whose retention given by the series retention rules has expired.
+	FlushDeletes(flush persist.DataFlush) error
+
+	// FlushRepairs rewrites flushed data merged with any blocks repaired
+	// from peers.
+	FlushRepairs(flush persist.DataFlush) error
+
+	// ColdFlush rewrites flushed data merged with any cold writes, which
+	// are writes that arrived after the block was eligible to be flushed.
+	ColdFlush(flush persist.DataFlush) error
+
+	// CompactFlushedBlocks compacts flushed blocks old enough to be compacted
+	// into filesets spanning several blocks.
+	CompactFlushedBlocks(flush persist.DataFlush) error
+
 	// Repair repairs the namespace data for a given time range
 	Repair(repairer databaseShardRepairer, tr xtime.Range) error
 
 	// BootstrapState captures and returns a snapshot of the namespaces' bootstrap state.
 	BootstrapState() ShardBootstrapStates
+
+	// UpdateMetadata updates the namespace metadata, only the retention
+	// period, buffers, index block size and series retention rules of a
+	// namespace can be updated.
+	UpdateMetadata(metadata namespace.Metadata) error
 }
 
 // Shard is a time series database shard
@@ -430,18 +502,49 @@ type databaseShard interface {
 	) error
 
 	// Snapshot snapshot's the unflushed series' in this shard.
-	Snapshot(blockStart, snapshotStart time.Time, flush persist.DataFlush) error
+	Snapshot(
+		blockStart,
+		snapshotStart time.Time,
+		snapshotID uuid.UUID,
+		flush persist.DataFlush,
+	) error
 
 	// FlushState returns the flush state for this shard at block start.
 	FlushState(blockStart time.Time) fileOpState
 
+	// DeleteSeries removes the series from the shard, excluding any data
+	// already flushed for them from reads until it has been rewritten. The
+	// deletes are persisted before returning.
+	DeleteSeries(ids []ident.ID) error
+
+	// FlushDeletes rewrites any flushed blocks containing deleted series or
+	// series whose retention given by the series retention rules has expired.
+	FlushDeletes(flush persist.DataFlush) error
+
+	// AddRepairedBlock adds a block for a series fetched from peers by
+	// repair, to be merged with the local data for the block once flushed.
+	AddRepairedBlock(id ident.ID, tags ident.Tags, repaired block.DatabaseBlock) error
+
+	// FlushRepairs rewrites any flushed blocks with blocks repaired from peers.
+	FlushRepairs(flush persist.DataFlush) error
+
+	// ColdFlush rewrites any flushed blocks with the cold writes buffered
+	// by the series for those blocks.
+	ColdFlush(flush persist.DataFlush) error
+
+	// CompactFlushedBlocks compacts the filesets of consecutive flushed
+	// blocks old enough to be compacted into a single fileset.
+	CompactFlushedBlocks(flush persist.DataFlush) error
+
 	// SnapshotState returns the snapshot state for this shard.
 	SnapshotState() (isSnapshotting bool, lastSuccessfulSnapshot time.Time)
 
 	// CleanupSnapshots cleans up snapshot files.
 	CleanupSnapshots(earliestToRetain time.Time) error
 
-	// CleanupExpiredFileSets removes expired fileset files.
+	// CleanupExpiredFileSets removes expired fileset files, and schedules
+	// flushed blocks holding series whose retention given by the series
+	// retention rules has expired to be rewritten without them.
 	CleanupExpiredFileSets(earliestToRetain time.Time) error
 
 	// Repair repairs the shard data for a given time.
@@ -450,6 +553,10 @@ type databaseShard interface {
 		tr xtime.Range,
 		repairer databaseShardRepairer,
 	) (repair.MetadataComparisonResult, error)
+
+	// UpdateNamespaceMetadata updates the namespace metadata of the shard
+	// and the options of the series it holds.
+	UpdateNamespaceMetadata(metadata namespace.Metadata, seriesOpts series.Options)
 }
 
 // namespaceIndex indexes namespace writes.
@@ -472,6 +579,18 @@ type namespaceIndex interface {
 		opts index.QueryOptions,
 	) (index.QueryResults, error)
 
+	// AggregateQuery resolves the given query into the distinct tag names
+	// and values of the matching documents.
+	AggregateQuery(
+		ctx context.Context,
+		query index.Query,
+		opts index.AggregationOptions,
+	) (index.AggregateQueryResult, error)
+
+	// Delete excludes the given IDs from query results until they
+	// are indexed again.
+	Delete(ids []ident.ID) error
+
 	// Bootstrap bootstraps the index the provided segments.
 	Bootstrap(
 		bootstrapResults result.IndexResults,
@@ -495,6 +614,14 @@ type namespaceIndex interface {
 		shards []databaseShard,
 	) error
 
+	// UpdateRetentionOptions updates the retention period and buffers used
+	// to accept writes and expire blocks.
+	UpdateRetentionOptions(value retention.Options)
+
+	// UpdateIndexOptions updates the index options used to determine the
+	// start and size of index blocks.
+	UpdateIndexOptions(value namespace.IndexOptions)
+
 	// Close will release the index resources and close the index.
 	Close() error
 }
@@ -606,7 +733,7 @@ type databaseShardRepairer interface {
 	// Repair repairs the data for a given namespace and shard
 	Repair(
 		ctx context.Context,
-		namespace ident.ID,
+		nsMeta namespace.Metadata,
 		tr xtime.Range,
 		shard databaseShard,
 	) (repair.MetadataComparisonResult, error)
@@ -753,6 +880,12 @@ type Options interface {
 	// IndexOptions returns the indexing options.
 	IndexOptions() index.Options
 
+	// SetLimitsOptions sets the query limits options.
+	SetLimitsOptions(value limits.Options) Options
+
+	// LimitsOptions returns the query limits options.
+	LimitsOptions() limits.Options
+
 	// SetRepairEnabled sets whether or not to enable the repair.
 	SetRepairEnabled(b bool) Options
 
@@ -23,6 +23,7 @@ package ts
 import (
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3x/ident"
 	xtime "github.com/m3db/m3x/time"
 )
@@ -70,6 +71,9 @@ type Series struct {
 
 	// Shard is the shard the series belongs to.
 	Shard uint32
+
+	// CodecID is the codec the namespace of the series is encoded with.
+	CodecID codec.ID
 }
 
 // A Datapoint is a single data value reported at a given time.
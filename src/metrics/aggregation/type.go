@@ -54,6 +54,8 @@ const (
 	P99
 	P999
 	P9999
+	Rate
+	DistinctCount
 
 	nextTypeID = iota
 )
@@ -77,28 +79,30 @@ var (
 
 	// ValidTypes is the list of all the valid aggregation types.
 	ValidTypes = map[Type]struct{}{
-		Last:   emptyStruct,
-		Min:    emptyStruct,
-		Max:    emptyStruct,
-		Mean:   emptyStruct,
-		Median: emptyStruct,
-		Count:  emptyStruct,
-		Sum:    emptyStruct,
-		SumSq:  emptyStruct,
-		Stdev:  emptyStruct,
-		P10:    emptyStruct,
-		P20:    emptyStruct,
-		P30:    emptyStruct,
-		P40:    emptyStruct,
-		P50:    emptyStruct,
-		P60:    emptyStruct,
-		P70:    emptyStruct,
-		P80:    emptyStruct,
-		P90:    emptyStruct,
-		P95:    emptyStruct,
-		P99:    emptyStruct,
-		P999:   emptyStruct,
-		P9999:  emptyStruct,
+		Last:          emptyStruct,
+		Min:           emptyStruct,
+		Max:           emptyStruct,
+		Mean:          emptyStruct,
+		Median:        emptyStruct,
+		Count:         emptyStruct,
+		Sum:           emptyStruct,
+		SumSq:         emptyStruct,
+		Stdev:         emptyStruct,
+		P10:           emptyStruct,
+		P20:           emptyStruct,
+		P30:           emptyStruct,
+		P40:           emptyStruct,
+		P50:           emptyStruct,
+		P60:           emptyStruct,
+		P70:           emptyStruct,
+		P80:           emptyStruct,
+		P90:           emptyStruct,
+		P95:           emptyStruct,
+		P99:           emptyStruct,
+		P999:          emptyStruct,
+		P9999:         emptyStruct,
+		Rate:          emptyStruct,
+		DistinctCount: emptyStruct,
 	}
 
 	typeStringMap map[string]Type
@@ -130,7 +134,7 @@ func (a Type) IsValid() bool {
 // IsValidForGauge if an Type is valid for Gauge.
 func (a Type) IsValidForGauge() bool {
 	switch a {
-	case Last, Min, Max, Mean, Count, Sum, SumSq, Stdev:
+	case Last, Min, Max, Mean, Count, Sum, SumSq, Stdev, DistinctCount:
 		return true
 	default:
 		return false
@@ -140,7 +144,7 @@ func (a Type) IsValidForGauge() bool {
 // IsValidForCounter if an Type is valid for Counter.
 func (a Type) IsValidForCounter() bool {
 	switch a {
-	case Min, Max, Mean, Count, Sum, SumSq, Stdev:
+	case Min, Max, Mean, Count, Sum, SumSq, Stdev, DistinctCount:
 		return true
 	default:
 		return false
@@ -150,7 +154,17 @@ func (a Type) IsValidForCounter() bool {
 // IsValidForTimer if an Type is valid for Timer.
 func (a Type) IsValidForTimer() bool {
 	switch a {
-	case Last:
+	case Last, Rate:
+		return false
+	default:
+		return true
+	}
+}
+
+// IsValidForHistogram if an Type is valid for Histogram.
+func (a Type) IsValidForHistogram() bool {
+	switch a {
+	case Last, SumSq, Stdev, DistinctCount:
 		return false
 	default:
 		return true
@@ -346,6 +360,16 @@ func (aggTypes Types) IsValidForTimer() bool {
 	return true
 }
 
+// IsValidForHistogram checks if the list of aggregation types is valid for Histogram.
+func (aggTypes Types) IsValidForHistogram() bool {
+	for _, aggType := range aggTypes {
+		if !aggType.IsValidForHistogram() {
+			return false
+		}
+	}
+	return true
+}
+
 // PooledQuantiles returns all the quantiles found in the list
 // of aggregation types. Using a floats pool if available.
 //
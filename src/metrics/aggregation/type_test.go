@@ -31,13 +31,13 @@ import (
 )
 
 func TestTypeIsValid(t *testing.T) {
-	require.True(t, P9999.IsValid())
-	require.False(t, Type(int(P9999)+1).IsValid())
+	require.True(t, DistinctCount.IsValid())
+	require.False(t, Type(int(DistinctCount)+1).IsValid())
 }
 
 func TestTypeMaxID(t *testing.T) {
-	require.Equal(t, maxTypeID, P9999.ID())
-	require.Equal(t, P9999, Type(maxTypeID))
+	require.Equal(t, maxTypeID, DistinctCount.ID())
+	require.Equal(t, DistinctCount, Type(maxTypeID))
 	require.Equal(t, maxTypeID, len(ValidTypes))
 }
 
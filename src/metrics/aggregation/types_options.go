@@ -57,6 +57,12 @@ type TypesOptions interface {
 	// DefaultGaugeAggregationTypes returns the default aggregation types for gauges.
 	DefaultGaugeAggregationTypes() Types
 
+	// SetDefaultHistogramAggregationTypes sets the default aggregation types for histograms.
+	SetDefaultHistogramAggregationTypes(value Types) TypesOptions
+
+	// DefaultHistogramAggregationTypes returns the default aggregation types for histograms.
+	DefaultHistogramAggregationTypes() Types
+
 	// SetQuantileTypeStringFn sets the quantile type string function for timers.
 	SetQuantileTypeStringFn(value QuantileTypeStringFn) TypesOptions
 
@@ -81,6 +87,12 @@ type TypesOptions interface {
 	// GaugeTypeStringTransformFn returns the transformation function for gauge type strings.
 	GaugeTypeStringTransformFn() TypeStringTransformFn
 
+	// SetHistogramTypeStringTransformFn sets the transformation function for histogram type strings.
+	SetHistogramTypeStringTransformFn(value TypeStringTransformFn) TypesOptions
+
+	// HistogramTypeStringTransformFn returns the transformation function for histogram type strings.
+	HistogramTypeStringTransformFn() TypeStringTransformFn
+
 	// SetTypesPool sets the aggregation types pool.
 	SetTypesPool(pool TypesPool) TypesOptions
 
@@ -104,6 +116,9 @@ type TypesOptions interface {
 	// TypeStringForGauge returns the type string for the aggregation type for gauges.
 	TypeStringForGauge(value Type) []byte
 
+	// TypeStringForHistogram returns the type string for the aggregation type for histograms.
+	TypeStringForHistogram(value Type) []byte
+
 	// TypeForCounter returns the aggregation type for given counter type string.
 	TypeForCounter(value []byte) Type
 
@@ -113,6 +128,9 @@ type TypesOptions interface {
 	// TypeForGauge returns the aggregation type for given gauge type string.
 	TypeForGauge(value []byte) Type
 
+	// TypeForHistogram returns the aggregation type for given histogram type string.
+	TypeForHistogram(value []byte) Type
+
 	// Quantiles returns the quantiles for timers.
 	Quantiles() []float64
 
@@ -141,46 +159,61 @@ var (
 	defaultDefaultGaugeAggregationTypes = Types{
 		Last,
 	}
+	defaultDefaultHistogramAggregationTypes = Types{
+		Count,
+		Sum,
+		Rate,
+		P50,
+		P95,
+		P99,
+	}
 	defaultTypeStringsMap = map[Type][]byte{
-		Last:   []byte("last"),
-		Sum:    []byte("sum"),
-		SumSq:  []byte("sum_sq"),
-		Mean:   []byte("mean"),
-		Min:    []byte("lower"),
-		Max:    []byte("upper"),
-		Count:  []byte("count"),
-		Stdev:  []byte("stdev"),
-		Median: []byte("median"),
+		Last:          []byte("last"),
+		Sum:           []byte("sum"),
+		SumSq:         []byte("sum_sq"),
+		Mean:          []byte("mean"),
+		Min:           []byte("lower"),
+		Max:           []byte("upper"),
+		Count:         []byte("count"),
+		Stdev:         []byte("stdev"),
+		Median:        []byte("median"),
+		Rate:          []byte("rate"),
+		DistinctCount: []byte("distinct_count"),
 	}
 )
 
 type options struct {
-	defaultCounterAggregationTypes Types
-	defaultTimerAggregationTypes   Types
-	defaultGaugeAggregationTypes   Types
-	quantileTypeStringFn           QuantileTypeStringFn
-	counterTypeStringTransformFn   TypeStringTransformFn
-	timerTypeStringTransformFn     TypeStringTransformFn
-	gaugeTypeStringTransformFn     TypeStringTransformFn
-	aggTypesPool                   TypesPool
-	quantilesPool                  pool.FloatsPool
-
-	counterTypeStrings [][]byte
-	timerTypeStrings   [][]byte
-	gaugeTypeStrings   [][]byte
-	quantiles          []float64
+	defaultCounterAggregationTypes   Types
+	defaultTimerAggregationTypes     Types
+	defaultGaugeAggregationTypes     Types
+	defaultHistogramAggregationTypes Types
+	quantileTypeStringFn             QuantileTypeStringFn
+	counterTypeStringTransformFn     TypeStringTransformFn
+	timerTypeStringTransformFn       TypeStringTransformFn
+	gaugeTypeStringTransformFn       TypeStringTransformFn
+	histogramTypeStringTransformFn   TypeStringTransformFn
+	aggTypesPool                     TypesPool
+	quantilesPool                    pool.FloatsPool
+
+	counterTypeStrings   [][]byte
+	timerTypeStrings     [][]byte
+	gaugeTypeStrings     [][]byte
+	histogramTypeStrings [][]byte
+	quantiles            []float64
 }
 
 // NewTypesOptions returns a default TypesOptions.
 func NewTypesOptions() TypesOptions {
 	o := &options{
-		defaultCounterAggregationTypes: defaultDefaultCounterAggregationTypes,
-		defaultGaugeAggregationTypes:   defaultDefaultGaugeAggregationTypes,
-		defaultTimerAggregationTypes:   defaultDefaultTimerAggregationTypes,
-		quantileTypeStringFn:           defaultQuantileTypeStringFn,
-		counterTypeStringTransformFn:   NoOpTransform,
-		timerTypeStringTransformFn:     NoOpTransform,
-		gaugeTypeStringTransformFn:     NoOpTransform,
+		defaultCounterAggregationTypes:   defaultDefaultCounterAggregationTypes,
+		defaultGaugeAggregationTypes:     defaultDefaultGaugeAggregationTypes,
+		defaultTimerAggregationTypes:     defaultDefaultTimerAggregationTypes,
+		defaultHistogramAggregationTypes: defaultDefaultHistogramAggregationTypes,
+		quantileTypeStringFn:             defaultQuantileTypeStringFn,
+		counterTypeStringTransformFn:     NoOpTransform,
+		timerTypeStringTransformFn:       NoOpTransform,
+		gaugeTypeStringTransformFn:       NoOpTransform,
+		histogramTypeStringTransformFn:   NoOpTransform,
 	}
 	o.initPools()
 	o.computeAllDerived()
@@ -230,6 +263,17 @@ func (o *options) DefaultGaugeAggregationTypes() Types {
 	return o.defaultGaugeAggregationTypes
 }
 
+func (o *options) SetDefaultHistogramAggregationTypes(aggTypes Types) TypesOptions {
+	opts := *o
+	opts.defaultHistogramAggregationTypes = aggTypes
+	opts.computeAllDerived()
+	return &opts
+}
+
+func (o *options) DefaultHistogramAggregationTypes() Types {
+	return o.defaultHistogramAggregationTypes
+}
+
 func (o *options) SetQuantileTypeStringFn(value QuantileTypeStringFn) TypesOptions {
 	opts := *o
 	opts.quantileTypeStringFn = value
@@ -274,6 +318,17 @@ func (o *options) GaugeTypeStringTransformFn() TypeStringTransformFn {
 	return o.gaugeTypeStringTransformFn
 }
 
+func (o *options) SetHistogramTypeStringTransformFn(value TypeStringTransformFn) TypesOptions {
+	opts := *o
+	opts.histogramTypeStringTransformFn = value
+	opts.computeAllDerived()
+	return &opts
+}
+
+func (o *options) HistogramTypeStringTransformFn() TypeStringTransformFn {
+	return o.histogramTypeStringTransformFn
+}
+
 func (o *options) SetTypesPool(pool TypesPool) TypesOptions {
 	opts := *o
 	opts.aggTypesPool = pool
@@ -306,6 +361,10 @@ func (o *options) TypeStringForGauge(aggType Type) []byte {
 	return o.gaugeTypeStrings[aggType.ID()]
 }
 
+func (o *options) TypeStringForHistogram(aggType Type) []byte {
+	return o.histogramTypeStrings[aggType.ID()]
+}
+
 func (o *options) TypeForCounter(value []byte) Type {
 	return typeFor(value, o.counterTypeStrings)
 }
@@ -318,6 +377,10 @@ func (o *options) TypeForGauge(value []byte) Type {
 	return typeFor(value, o.gaugeTypeStrings)
 }
 
+func (o *options) TypeForHistogram(value []byte) Type {
+	return typeFor(value, o.histogramTypeStrings)
+}
+
 func (o *options) Quantiles() []float64 {
 	return o.quantiles
 }
@@ -331,6 +394,8 @@ func (o *options) IsContainedInDefaultAggregationTypes(at Type, mt metric.Type)
 		aggTypes = o.DefaultGaugeAggregationTypes()
 	case metric.TimerType:
 		aggTypes = o.DefaultTimerAggregationTypes()
+	case metric.HistogramType:
+		aggTypes = o.DefaultHistogramAggregationTypes()
 	}
 	return aggTypes.Contains(at)
 }
@@ -340,6 +405,7 @@ func (o *options) computeAllDerived() {
 	o.computeCounterTypeStrings()
 	o.computeTimerTypeStrings()
 	o.computeGaugeTypeStrings()
+	o.computeHistogramTypeStrings()
 }
 
 func (o *options) computeQuantiles() {
@@ -358,6 +424,10 @@ func (o *options) computeGaugeTypeStrings() {
 	o.gaugeTypeStrings = o.computeTypeStrings(o.gaugeTypeStringTransformFn)
 }
 
+func (o *options) computeHistogramTypeStrings() {
+	o.histogramTypeStrings = o.computeTypeStrings(o.histogramTypeStringTransformFn)
+}
+
 func (o *options) computeTypeStrings(transformFn TypeStringTransformFn) [][]byte {
 	res := make([][]byte, maxTypeID+1)
 	for aggType := range ValidTypes {
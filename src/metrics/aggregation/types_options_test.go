@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/m3db/m3/src/metrics/metric"
 	"github.com/m3db/m3x/pool"
 
 	"github.com/stretchr/testify/require"
@@ -36,10 +37,12 @@ func TestTypesOptionsValidateDefault(t *testing.T) {
 	require.Equal(t, defaultDefaultCounterAggregationTypes, o.DefaultCounterAggregationTypes())
 	require.Equal(t, defaultDefaultTimerAggregationTypes, o.DefaultTimerAggregationTypes())
 	require.Equal(t, defaultDefaultGaugeAggregationTypes, o.DefaultGaugeAggregationTypes())
+	require.Equal(t, defaultDefaultHistogramAggregationTypes, o.DefaultHistogramAggregationTypes())
 	require.NotNil(t, o.QuantileTypeStringFn())
 	require.NotNil(t, o.CounterTypeStringTransformFn())
 	require.NotNil(t, o.TimerTypeStringTransformFn())
 	require.NotNil(t, o.GaugeTypeStringTransformFn())
+	require.NotNil(t, o.HistogramTypeStringTransformFn())
 
 	// Validate derived options
 	opts := o.(*options)
@@ -47,6 +50,7 @@ func TestTypesOptionsValidateDefault(t *testing.T) {
 	require.Equal(t, typeStrings(nil), opts.counterTypeStrings)
 	require.Equal(t, typeStrings(nil), opts.timerTypeStrings)
 	require.Equal(t, typeStrings(nil), opts.gaugeTypeStrings)
+	require.Equal(t, typeStrings(nil), opts.histogramTypeStrings)
 }
 
 func TestOptionsSetDefaultCounterAggregationTypes(t *testing.T) {
@@ -71,6 +75,15 @@ func TestOptionsSetDefaultGaugeAggregationTypes(t *testing.T) {
 	require.Equal(t, typeStrings(nil), o.(*options).gaugeTypeStrings)
 }
 
+func TestOptionsSetDefaultHistogramAggregationTypes(t *testing.T) {
+	aggTypes := Types{Count, Rate, P99}
+	o := NewTypesOptions().SetDefaultHistogramAggregationTypes(aggTypes)
+	require.Equal(t, aggTypes, o.DefaultHistogramAggregationTypes())
+	require.True(t, o.IsContainedInDefaultAggregationTypes(Rate, metric.HistogramType))
+	require.False(t, o.IsContainedInDefaultAggregationTypes(Sum, metric.HistogramType))
+	require.Equal(t, typeStrings(nil), o.(*options).histogramTypeStrings)
+}
+
 func TestOptionsSetTimerQuantileTypeStringFn(t *testing.T) {
 	fn := func(q float64) []byte { return []byte(fmt.Sprintf("%1.2f", q)) }
 	o := NewTypesOptions().SetQuantileTypeStringFn(fn)
@@ -367,6 +380,29 @@ func TestOptionsTypeForGauge(t *testing.T) {
 	}
 }
 
+func TestOptionsTypeForHistogram(t *testing.T) {
+	inputs := []struct {
+		typeStr  []byte
+		expected Type
+	}{
+		{typeStr: []byte(".count"), expected: Count},
+		{typeStr: []byte(".sum"), expected: Sum},
+		{typeStr: []byte(".rate"), expected: Rate},
+		{typeStr: []byte(".p50"), expected: P50},
+		{typeStr: []byte(".p99"), expected: P99},
+		{typeStr: []byte("rate"), expected: UnknownType},
+		{typeStr: []byte(nil), expected: UnknownType},
+	}
+
+	o := NewTypesOptions().SetHistogramTypeStringTransformFn(SuffixTransform)
+	for _, input := range inputs {
+		require.Equal(t, input.expected, o.TypeForHistogram(input.typeStr))
+		if input.expected != UnknownType {
+			require.Equal(t, input.typeStr, o.TypeStringForHistogram(input.expected))
+		}
+	}
+}
+
 func TestOptionQuantileTypeString(t *testing.T) {
 	o := NewTypesOptions()
 	cases := []struct {
@@ -450,28 +486,30 @@ func validateQuantiles(t *testing.T, o TypesOptions) {
 
 func typeStrings(overrides map[Type][]byte) [][]byte {
 	defaultTypeStrings := map[Type][]byte{
-		Last:   []byte("last"),
-		Min:    []byte("lower"),
-		Max:    []byte("upper"),
-		Mean:   []byte("mean"),
-		Median: []byte("median"),
-		Count:  []byte("count"),
-		Sum:    []byte("sum"),
-		SumSq:  []byte("sum_sq"),
-		Stdev:  []byte("stdev"),
-		P10:    []byte("p10"),
-		P20:    []byte("p20"),
-		P30:    []byte("p30"),
-		P40:    []byte("p40"),
-		P50:    []byte("p50"),
-		P60:    []byte("p60"),
-		P70:    []byte("p70"),
-		P80:    []byte("p80"),
-		P90:    []byte("p90"),
-		P95:    []byte("p95"),
-		P99:    []byte("p99"),
-		P999:   []byte("p999"),
-		P9999:  []byte("p9999"),
+		Last:          []byte("last"),
+		Min:           []byte("lower"),
+		Max:           []byte("upper"),
+		Mean:          []byte("mean"),
+		Median:        []byte("median"),
+		Count:         []byte("count"),
+		Sum:           []byte("sum"),
+		SumSq:         []byte("sum_sq"),
+		Stdev:         []byte("stdev"),
+		P10:           []byte("p10"),
+		P20:           []byte("p20"),
+		P30:           []byte("p30"),
+		P40:           []byte("p40"),
+		P50:           []byte("p50"),
+		P60:           []byte("p60"),
+		P70:           []byte("p70"),
+		P80:           []byte("p80"),
+		P90:           []byte("p90"),
+		P95:           []byte("p95"),
+		P99:           []byte("p99"),
+		P999:          []byte("p999"),
+		P9999:         []byte("p9999"),
+		Rate:          []byte("rate"),
+		DistinctCount: []byte("distinct_count"),
 	}
 	res := make([][]byte, maxTypeID+1)
 	for t, bstr := range defaultTypeStrings {
@@ -60,6 +60,14 @@ func toUnaggregatedMessageUnion(
 				StagedMetadatas: metadatas,
 			},
 		}, nil
+	case metric.HistogramType:
+		return encoding.UnaggregatedMessageUnion{
+			Type: encoding.HistogramWithMetadatasType,
+			HistogramWithMetadatas: unaggregated.HistogramWithMetadatas{
+				Histogram:       metricUnion.Histogram(),
+				StagedMetadatas: metadatas,
+			},
+		}, nil
 	default:
 		return encoding.UnaggregatedMessageUnion{}, fmt.Errorf("unknown metric type: %v", metricUnion.Type)
 	}
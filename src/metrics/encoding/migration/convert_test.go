@@ -29,6 +29,7 @@ import (
 	"github.com/m3db/m3/src/metrics/encoding"
 	"github.com/m3db/m3/src/metrics/metadata"
 	"github.com/m3db/m3/src/metrics/metric"
+	"github.com/m3db/m3/src/metrics/metric/histogram"
 	"github.com/m3db/m3/src/metrics/metric/unaggregated"
 	"github.com/m3db/m3/src/metrics/policy"
 	xtime "github.com/m3db/m3x/time"
@@ -52,6 +53,13 @@ var (
 		ID:       []byte("testConvertGauge"),
 		GaugeVal: 123.456,
 	}
+	testConvertHistogramUnion = unaggregated.MetricUnion{
+		Type:            metric.HistogramType,
+		ID:              []byte("testConvertHistogram"),
+		HistogramSchema: histogram.NewFixedSchema([]float64{1, 10, 100}),
+		HistogramCounts: []uint64{3, 0, 12, 1},
+		HistogramSum:    567.8,
+	}
 	testConvertPoliciesList = policy.PoliciesList{
 		// Default staged policies.
 		policy.DefaultStagedPolicies,
@@ -120,6 +128,12 @@ var (
 		ID:    []byte("testConvertGauge"),
 		Value: 123.456,
 	}
+	testConvertHistogram = unaggregated.Histogram{
+		ID:     []byte("testConvertHistogram"),
+		Schema: histogram.NewFixedSchema([]float64{1, 10, 100}),
+		Counts: []uint64{3, 0, 12, 1},
+		Sum:    567.8,
+	}
 	testConvertStagedMetadatas = metadata.StagedMetadatas{
 		metadata.DefaultStagedMetadata,
 		metadata.StagedMetadata{
@@ -193,6 +207,10 @@ func TestToUnaggregatedMessageUnion(t *testing.T) {
 			metricUnion:  testConvertGaugeUnion,
 			policiesList: testConvertPoliciesList,
 		},
+		{
+			metricUnion:  testConvertHistogramUnion,
+			policiesList: testConvertPoliciesList,
+		},
 	}
 	expected := []encoding.UnaggregatedMessageUnion{
 		{
@@ -216,6 +234,13 @@ func TestToUnaggregatedMessageUnion(t *testing.T) {
 				StagedMetadatas: testConvertStagedMetadatas,
 			},
 		},
+		{
+			Type: encoding.HistogramWithMetadatasType,
+			HistogramWithMetadatas: unaggregated.HistogramWithMetadatas{
+				Histogram:       testConvertHistogram,
+				StagedMetadatas: testConvertStagedMetadatas,
+			},
+		},
 	}
 
 	for i, input := range inputs {
@@ -66,6 +66,9 @@ const (
 
 	// Additional object types.
 	rawMetricWithStoragePolicyAndEncodeTimeType
+	histogramWithPoliciesListType
+	histogramType
+	histogramSchemaType
 
 	// Total number of object types.
 	numObjectTypes = iota - 1
@@ -76,11 +79,14 @@ const (
 	numCounterWithPoliciesListFields                 = 2
 	numBatchTimerWithPoliciesListFields              = 2
 	numGaugeWithPoliciesListFields                   = 2
+	numHistogramWithPoliciesListFields               = 2
 	numRawMetricWithStoragePolicyFields              = 2
 	numRawMetricWithStoragePolicyAndEncodeTimeFields = 3
 	numCounterFields                                 = 2
 	numBatchTimerFields                              = 2
 	numGaugeFields                                   = 2
+	numHistogramFields                               = 4
+	numHistogramSchemaFields                         = 5
 	numMetricFields                                  = 3
 	numDefaultStagedPoliciesListFields               = 1
 	numCustomStagedPoliciesListFields                = 2
@@ -117,11 +123,14 @@ func init() {
 	setNumFieldsForType(counterWithPoliciesListType, numCounterWithPoliciesListFields)
 	setNumFieldsForType(batchTimerWithPoliciesListType, numBatchTimerWithPoliciesListFields)
 	setNumFieldsForType(gaugeWithPoliciesListType, numGaugeWithPoliciesListFields)
+	setNumFieldsForType(histogramWithPoliciesListType, numHistogramWithPoliciesListFields)
 	setNumFieldsForType(rawMetricWithStoragePolicyType, numRawMetricWithStoragePolicyFields)
 	setNumFieldsForType(rawMetricWithStoragePolicyAndEncodeTimeType, numRawMetricWithStoragePolicyAndEncodeTimeFields)
 	setNumFieldsForType(counterType, numCounterFields)
 	setNumFieldsForType(timerType, numBatchTimerFields)
 	setNumFieldsForType(gaugeType, numGaugeFields)
+	setNumFieldsForType(histogramType, numHistogramFields)
+	setNumFieldsForType(histogramSchemaType, numHistogramSchemaFields)
 	setNumFieldsForType(metricType, numMetricFields)
 	setNumFieldsForType(defaultPoliciesListType, numDefaultStagedPoliciesListFields)
 	setNumFieldsForType(customPoliciesListType, numCustomStagedPoliciesListFields)
@@ -230,6 +230,9 @@ type UnaggregatedEncoder interface {
 	// EncodeGauge encodes a gauge.
 	EncodeGauge(g unaggregated.Gauge) error
 
+	// EncodeHistogram encodes a histogram.
+	EncodeHistogram(h unaggregated.Histogram) error
+
 	// EncodeCounterWithPoliciesList encodes a counter with applicable policies list.
 	EncodeCounterWithPoliciesList(cp unaggregated.CounterWithPoliciesList) error
 
@@ -239,6 +242,9 @@ type UnaggregatedEncoder interface {
 	// EncodeGaugeWithPoliciesList encodes a gauge with applicable policies list.
 	EncodeGaugeWithPoliciesList(gp unaggregated.GaugeWithPoliciesList) error
 
+	// EncodeHistogramWithPoliciesList encodes a histogram with applicable policies list.
+	EncodeHistogramWithPoliciesList(hp unaggregated.HistogramWithPoliciesList) error
+
 	// Encoder returns the encoder.
 	Encoder() BufferedEncoder
 
@@ -21,6 +21,7 @@
 package msgpack
 
 import (
+	"github.com/m3db/m3/src/metrics/metric/histogram"
 	"github.com/m3db/m3/src/metrics/metric/unaggregated"
 	"github.com/m3db/m3/src/metrics/policy"
 )
@@ -30,9 +31,11 @@ type encodeRootObjectFn func(objType objectType)
 type encodeCounterWithPoliciesListFn func(cp unaggregated.CounterWithPoliciesList)
 type encodeBatchTimerWithPoliciesListFn func(btp unaggregated.BatchTimerWithPoliciesList)
 type encodeGaugeWithPoliciesListFn func(gp unaggregated.GaugeWithPoliciesList)
+type encodeHistogramWithPoliciesListFn func(hp unaggregated.HistogramWithPoliciesList)
 type encodeCounterFn func(c unaggregated.Counter)
 type encodeBatchTimerFn func(bt unaggregated.BatchTimer)
 type encodeGaugeFn func(g unaggregated.Gauge)
+type encodeHistogramFn func(h unaggregated.Histogram)
 type encodePoliciesListFn func(spl policy.PoliciesList)
 
 // unaggregatedEncoder uses MessagePack for encoding different types of unaggregated metrics.
@@ -44,9 +47,11 @@ type unaggregatedEncoder struct {
 	encodeCounterWithPoliciesListFn    encodeCounterWithPoliciesListFn
 	encodeBatchTimerWithPoliciesListFn encodeBatchTimerWithPoliciesListFn
 	encodeGaugeWithPoliciesListFn      encodeGaugeWithPoliciesListFn
+	encodeHistogramWithPoliciesListFn  encodeHistogramWithPoliciesListFn
 	encodeCounterFn                    encodeCounterFn
 	encodeBatchTimerFn                 encodeBatchTimerFn
 	encodeGaugeFn                      encodeGaugeFn
+	encodeHistogramFn                  encodeHistogramFn
 	encodePoliciesListFn               encodePoliciesListFn
 }
 
@@ -58,9 +63,11 @@ func NewUnaggregatedEncoder(encoder BufferedEncoder) UnaggregatedEncoder {
 	enc.encodeCounterWithPoliciesListFn = enc.encodeCounterWithPoliciesList
 	enc.encodeBatchTimerWithPoliciesListFn = enc.encodeBatchTimerWithPoliciesList
 	enc.encodeGaugeWithPoliciesListFn = enc.encodeGaugeWithPoliciesList
+	enc.encodeHistogramWithPoliciesListFn = enc.encodeHistogramWithPoliciesList
 	enc.encodeCounterFn = enc.encodeCounter
 	enc.encodeBatchTimerFn = enc.encodeBatchTimer
 	enc.encodeGaugeFn = enc.encodeGauge
+	enc.encodeHistogramFn = enc.encodeHistogram
 	enc.encodePoliciesListFn = enc.encodePoliciesList
 
 	return enc
@@ -96,6 +103,15 @@ func (enc *unaggregatedEncoder) EncodeGauge(g unaggregated.Gauge) error {
 	return enc.err()
 }
 
+func (enc *unaggregatedEncoder) EncodeHistogram(h unaggregated.Histogram) error {
+	if err := enc.err(); err != nil {
+		return err
+	}
+	enc.encodeRootObjectFn(histogramType)
+	enc.encodeHistogramFn(h)
+	return enc.err()
+}
+
 func (enc *unaggregatedEncoder) EncodeCounterWithPoliciesList(cp unaggregated.CounterWithPoliciesList) error {
 	if err := enc.err(); err != nil {
 		return err
@@ -123,6 +139,15 @@ func (enc *unaggregatedEncoder) EncodeGaugeWithPoliciesList(gp unaggregated.Gaug
 	return enc.err()
 }
 
+func (enc *unaggregatedEncoder) EncodeHistogramWithPoliciesList(hp unaggregated.HistogramWithPoliciesList) error {
+	if err := enc.err(); err != nil {
+		return err
+	}
+	enc.encodeRootObjectFn(histogramWithPoliciesListType)
+	enc.encodeHistogramWithPoliciesListFn(hp)
+	return enc.err()
+}
+
 func (enc *unaggregatedEncoder) encodeRootObject(objType objectType) {
 	enc.encodeVersion(unaggregatedVersion)
 	enc.encodeNumObjectFields(numFieldsForType(rootObjectType))
@@ -147,6 +172,12 @@ func (enc *unaggregatedEncoder) encodeGaugeWithPoliciesList(gp unaggregated.Gaug
 	enc.encodePoliciesListFn(gp.PoliciesList)
 }
 
+func (enc *unaggregatedEncoder) encodeHistogramWithPoliciesList(hp unaggregated.HistogramWithPoliciesList) {
+	enc.encodeNumObjectFields(numFieldsForType(histogramWithPoliciesListType))
+	enc.encodeHistogramFn(hp.Histogram)
+	enc.encodePoliciesListFn(hp.PoliciesList)
+}
+
 func (enc *unaggregatedEncoder) encodeCounter(c unaggregated.Counter) {
 	enc.encodeNumObjectFields(numFieldsForType(counterType))
 	enc.encodeRawID(c.ID)
@@ -168,6 +199,29 @@ func (enc *unaggregatedEncoder) encodeGauge(g unaggregated.Gauge) {
 	enc.encodeFloat64(g.Value)
 }
 
+func (enc *unaggregatedEncoder) encodeHistogram(h unaggregated.Histogram) {
+	enc.encodeNumObjectFields(numFieldsForType(histogramType))
+	enc.encodeRawID(h.ID)
+	enc.encodeHistogramSchema(h.Schema)
+	enc.encodeArrayLen(len(h.Counts))
+	for _, c := range h.Counts {
+		enc.encodeVarint(int64(c))
+	}
+	enc.encodeFloat64(h.Sum)
+}
+
+func (enc *unaggregatedEncoder) encodeHistogramSchema(s histogram.Schema) {
+	enc.encodeNumObjectFields(numFieldsForType(histogramSchemaType))
+	enc.encodeVarint(int64(s.Type))
+	enc.encodeArrayLen(len(s.Bounds))
+	for _, b := range s.Bounds {
+		enc.encodeFloat64(b)
+	}
+	enc.encodeFloat64(s.Start)
+	enc.encodeFloat64(s.Factor)
+	enc.encodeVarint(int64(s.NumBuckets))
+}
+
 func (enc *unaggregatedEncoder) encodePoliciesList(pl policy.PoliciesList) {
 	if pl.IsDefault() {
 		enc.encodeNumObjectFields(numFieldsForType(defaultPoliciesListType))
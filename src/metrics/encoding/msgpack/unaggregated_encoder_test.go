@@ -28,6 +28,7 @@ import (
 
 	"github.com/m3db/m3/src/metrics/aggregation"
 	"github.com/m3db/m3/src/metrics/metric"
+	"github.com/m3db/m3/src/metrics/metric/histogram"
 	"github.com/m3db/m3/src/metrics/metric/unaggregated"
 	"github.com/m3db/m3/src/metrics/policy"
 	xtime "github.com/m3db/m3x/time"
@@ -63,6 +64,13 @@ func TestUnaggregatedEncodeGauge(t *testing.T) {
 	require.Equal(t, expected, *results)
 }
 
+func TestUnaggregatedEncodeHistogram(t *testing.T) {
+	encoder, results := testCapturingUnaggregatedEncoder()
+	require.NoError(t, testUnaggregatedEncodeMetric(encoder, testHistogram))
+	expected := expectedResultsForUnaggregatedMetric(t, testHistogram)
+	require.Equal(t, expected, *results)
+}
+
 func TestUnaggregatedEncodeCounterWithDefaultPoliciesList(t *testing.T) {
 	policies := testDefaultStagedPoliciesList
 	encoder, results := testCapturingUnaggregatedEncoder()
@@ -88,7 +96,7 @@ func TestUnaggregatedEncodeGaugeWithDefaultPoliciesList(t *testing.T) {
 }
 
 func TestUnaggregatedEncodeAllMetricTypes(t *testing.T) {
-	inputs := []unaggregated.MetricUnion{testCounter, testBatchTimer, testGauge}
+	inputs := []unaggregated.MetricUnion{testCounter, testBatchTimer, testGauge, testHistogram}
 	var expected []interface{}
 	encoder, results := testCapturingUnaggregatedEncoder()
 	for _, input := range inputs {
@@ -326,6 +334,31 @@ func expectedResultsForPoliciesList(pl policy.PoliciesList) []interface{} {
 	return results
 }
 
+func expectedResultsForHistogram(h unaggregated.Histogram) []interface{} {
+	results := []interface{}{
+		numFieldsForType(histogramType),
+		[]byte(h.ID),
+	}
+	results = append(results, expectedResultsForHistogramSchema(h.Schema)...)
+	results = append(results, len(h.Counts))
+	for _, c := range h.Counts {
+		results = append(results, int64(c))
+	}
+	return append(results, h.Sum)
+}
+
+func expectedResultsForHistogramSchema(s histogram.Schema) []interface{} {
+	results := []interface{}{
+		numFieldsForType(histogramSchemaType),
+		int64(s.Type),
+		len(s.Bounds),
+	}
+	for _, b := range s.Bounds {
+		results = append(results, b)
+	}
+	return append(results, s.Start, s.Factor, int64(s.NumBuckets))
+}
+
 func expectedResultsForUnaggregatedMetric(t *testing.T, m unaggregated.MetricUnion) []interface{} {
 	results := []interface{}{
 		int64(unaggregatedVersion),
@@ -357,6 +390,11 @@ func expectedResultsForUnaggregatedMetric(t *testing.T, m unaggregated.MetricUni
 			[]byte(m.ID),
 			m.GaugeVal,
 		}...)
+	case metric.HistogramType:
+		results = append(results, []interface{}{
+			int64(histogramType),
+		}...)
+		results = append(results, expectedResultsForHistogram(m.Histogram())...)
 	default:
 		require.Fail(t, fmt.Sprintf("unrecognized metric type %v", m.Type))
 	}
@@ -402,6 +440,12 @@ func expectedResultsForUnaggregatedMetricWithPoliciesList(
 			[]byte(m.ID),
 			m.GaugeVal,
 		}...)
+	case metric.HistogramType:
+		results = append(results, []interface{}{
+			int64(histogramWithPoliciesListType),
+			numFieldsForType(histogramWithPoliciesListType),
+		}...)
+		results = append(results, expectedResultsForHistogram(m.Histogram())...)
 	default:
 		require.Fail(t, fmt.Sprintf("unrecognized metric type %v", m.Type))
 	}
@@ -26,6 +26,7 @@ import (
 	"math"
 
 	"github.com/m3db/m3/src/metrics/metric"
+	"github.com/m3db/m3/src/metrics/metric/histogram"
 	"github.com/m3db/m3/src/metrics/metric/id"
 	"github.com/m3db/m3/src/metrics/metric/unaggregated"
 	"github.com/m3db/m3/src/metrics/policy"
@@ -51,6 +52,8 @@ type unaggregatedIterator struct {
 	policiesList       policy.PoliciesList
 	id                 id.RawID
 	timerValues        []float64
+	histogramBounds    []float64
+	histogramCounts    []uint64
 	cachedPolicies     [][]policy.Policy
 	cachedPoliciesList policy.PoliciesList
 }
@@ -95,6 +98,8 @@ func (it *unaggregatedIterator) Next() bool {
 	// Reset the pointers in metric union to reduce GC sweep overhead.
 	it.metric.BatchTimerVal = nil
 	it.metric.TimerValPool = nil
+	it.metric.HistogramSchema.Bounds = nil
+	it.metric.HistogramCounts = nil
 
 	return it.decodeRootObject()
 }
@@ -139,9 +144,10 @@ func (it *unaggregatedIterator) decodeRootObject() bool {
 		return false
 	}
 	switch objType {
-	case counterType, timerType, gaugeType:
+	case counterType, timerType, gaugeType, histogramType:
 		it.decodeMetric(objType)
-	case counterWithPoliciesListType, batchTimerWithPoliciesListType, gaugeWithPoliciesListType:
+	case counterWithPoliciesListType, batchTimerWithPoliciesListType, gaugeWithPoliciesListType,
+		histogramWithPoliciesListType:
 		it.decodeMetricWithPoliciesList(objType)
 	default:
 		it.setErr(fmt.Errorf("unrecognized object type %v", objType))
@@ -159,6 +165,8 @@ func (it *unaggregatedIterator) decodeMetric(objType objectType) {
 		it.decodeBatchTimer()
 	case gaugeType:
 		it.decodeGauge()
+	case histogramType:
+		it.decodeHistogram()
 	default:
 		it.setErr(fmt.Errorf("unrecognized metric type %v", objType))
 	}
@@ -176,6 +184,8 @@ func (it *unaggregatedIterator) decodeMetricWithPoliciesList(objType objectType)
 		it.decodeBatchTimer()
 	case gaugeWithPoliciesListType:
 		it.decodeGauge()
+	case histogramWithPoliciesListType:
+		it.decodeHistogram()
 	default:
 		it.setErr(fmt.Errorf("unrecognized metric with policies type %v", objType))
 		return
@@ -242,6 +252,62 @@ func (it *unaggregatedIterator) decodeGauge() {
 	it.skip(numActualFields - numExpectedFields)
 }
 
+func (it *unaggregatedIterator) decodeHistogram() {
+	numExpectedFields, numActualFields, ok := it.checkNumFieldsForType(histogramType)
+	if !ok {
+		return
+	}
+	it.metric.Type = metric.HistogramType
+	it.metric.ID = it.decodeID()
+	it.metric.HistogramSchema = it.decodeHistogramSchema()
+	numCounts := it.decodeArrayLen()
+	if it.err() != nil {
+		return
+	}
+	if cap(it.histogramCounts) < numCounts {
+		it.histogramCounts = make([]uint64, 0, numCounts)
+	} else {
+		it.histogramCounts = it.histogramCounts[:0]
+	}
+	for i := 0; i < numCounts; i++ {
+		it.histogramCounts = append(it.histogramCounts, uint64(it.decodeVarint()))
+	}
+	it.metric.HistogramCounts = it.histogramCounts
+	it.metric.HistogramSum = it.decodeFloat64()
+	it.skip(numActualFields - numExpectedFields)
+}
+
+func (it *unaggregatedIterator) decodeHistogramSchema() histogram.Schema {
+	numExpectedFields, numActualFields, ok := it.checkNumFieldsForType(histogramSchemaType)
+	if !ok {
+		return histogram.Schema{}
+	}
+	var s histogram.Schema
+	s.Type = histogram.SchemaType(it.decodeVarint())
+	numBounds := it.decodeArrayLen()
+	if it.err() != nil {
+		return histogram.Schema{}
+	}
+	// NB: only fixed bucket schemas have bounds, leave them nil otherwise so the
+	// decoded schema matches the encoded one.
+	if numBounds > 0 {
+		if cap(it.histogramBounds) < numBounds {
+			it.histogramBounds = make([]float64, 0, numBounds)
+		} else {
+			it.histogramBounds = it.histogramBounds[:0]
+		}
+		for i := 0; i < numBounds; i++ {
+			it.histogramBounds = append(it.histogramBounds, it.decodeFloat64())
+		}
+		s.Bounds = it.histogramBounds
+	}
+	s.Start = it.decodeFloat64()
+	s.Factor = it.decodeFloat64()
+	s.NumBuckets = int(it.decodeVarint())
+	it.skip(numActualFields - numExpectedFields)
+	return s
+}
+
 func (it *unaggregatedIterator) decodePoliciesList() {
 	numActualFields := it.decodeNumObjectFields()
 	policiesListType := it.decodeObjectType()
@@ -30,6 +30,7 @@ import (
 
 	"github.com/m3db/m3/src/metrics/aggregation"
 	"github.com/m3db/m3/src/metrics/metric"
+	"github.com/m3db/m3/src/metrics/metric/histogram"
 	"github.com/m3db/m3/src/metrics/metric/unaggregated"
 	"github.com/m3db/m3/src/metrics/policy"
 	xtime "github.com/m3db/m3x/time"
@@ -56,6 +57,14 @@ var (
 		GaugeVal: 123.456,
 	}
 
+	testHistogram = unaggregated.MetricUnion{
+		Type:            metric.HistogramType,
+		ID:              []byte("foo"),
+		HistogramSchema: histogram.NewFixedSchema([]float64{1, 10, 100}),
+		HistogramCounts: []uint64{3, 0, 12, 1},
+		HistogramSum:    567.8,
+	}
+
 	testDefaultStagedPoliciesList = policy.DefaultPoliciesList
 
 	testSingleCustomStagedPoliciesList = policy.PoliciesList{
@@ -112,6 +121,10 @@ var (
 			metric:       testGauge,
 			policiesList: testDefaultStagedPoliciesList,
 		},
+		{
+			metric:       testHistogram,
+			policiesList: testDefaultStagedPoliciesList,
+		},
 	}
 
 	testInputWithAllTypesAndSingleCustomPoliciesList = []metricWithPoliciesList{
@@ -234,6 +247,24 @@ func TestUnaggregatedEncodeDecodeGauge(t *testing.T) {
 	validateUnaggregatedMetricRoundtrip(t, testGauge)
 }
 
+func TestUnaggregatedEncodeDecodeHistogram(t *testing.T) {
+	validateUnaggregatedMetricRoundtrip(t, testHistogram)
+}
+
+func TestUnaggregatedEncodeDecodeExponentialHistogram(t *testing.T) {
+	h := unaggregated.MetricUnion{
+		Type:            metric.HistogramType,
+		ID:              []byte("bar"),
+		HistogramSchema: histogram.NewExponentialSchema(0.001, 2, 3),
+		HistogramCounts: []uint64{0, 5, 7, 2},
+		HistogramSum:    0.0314,
+	}
+
+	// Decoding an exponential histogram after a fixed one should not leak the
+	// previous bucket bounds into the decoded schema.
+	validateUnaggregatedMetricRoundtrip(t, testHistogram, h)
+}
+
 func TestUnaggregatedEncodeDecodeCounterWithDefaultPoliciesList(t *testing.T) {
 	validateUnaggregatedMetricWithPoliciesListRoundtrip(t, metricWithPoliciesList{
 		metric:       testCounter,
@@ -255,8 +286,15 @@ func TestUnaggregatedEncodeDecodeGaugeWithDefaultPoliciesList(t *testing.T) {
 	})
 }
 
+func TestUnaggregatedEncodeDecodeHistogramWithDefaultPoliciesList(t *testing.T) {
+	validateUnaggregatedMetricWithPoliciesListRoundtrip(t, metricWithPoliciesList{
+		metric:       testHistogram,
+		policiesList: testDefaultStagedPoliciesList,
+	})
+}
+
 func TestUnaggregatedEncodeDecodeAllMetricTypes(t *testing.T) {
-	inputs := []unaggregated.MetricUnion{testCounter, testBatchTimer, testGauge}
+	inputs := []unaggregated.MetricUnion{testCounter, testBatchTimer, testGauge, testHistogram}
 	validateUnaggregatedMetricRoundtrip(t, inputs...)
 }
 
@@ -275,7 +313,7 @@ func TestUnaggregatedEncodeDecodeAllTypesWithMultiCustomPoliciesList(t *testing.
 func TestUnaggregatedEncodeDecodeMetricStress(t *testing.T) {
 	numIter := 10
 	numMetrics := 10000
-	allMetrics := []unaggregated.MetricUnion{testCounter, testBatchTimer, testGauge}
+	allMetrics := []unaggregated.MetricUnion{testCounter, testBatchTimer, testGauge, testHistogram}
 	encoder := testUnaggregatedEncoder()
 	iterator := testUnaggregatedIterator(nil)
 	for i := 0; i < numIter; i++ {
@@ -291,7 +329,7 @@ func TestUnaggregatedEncodeDecodeMetricStress(t *testing.T) {
 func TestUnaggregatedEncodeDecodeMetricWithPoliciesListStress(t *testing.T) {
 	numIter := 10
 	numMetrics := 10000
-	allMetrics := []unaggregated.MetricUnion{testCounter, testBatchTimer, testGauge}
+	allMetrics := []unaggregated.MetricUnion{testCounter, testBatchTimer, testGauge, testHistogram}
 	allPolicies := []policy.PoliciesList{
 		testDefaultStagedPoliciesList,
 		policy.PoliciesList{
@@ -390,6 +428,8 @@ func testUnaggregatedEncodeMetric(encoder UnaggregatedEncoder, m unaggregated.Me
 		return encoder.EncodeBatchTimer(m.BatchTimer())
 	case metric.GaugeType:
 		return encoder.EncodeGauge(m.Gauge())
+	case metric.HistogramType:
+		return encoder.EncodeHistogram(m.Histogram())
 	default:
 		return fmt.Errorf("unrecognized metric type %v", m.Type)
 	}
@@ -416,6 +456,11 @@ func testUnaggregatedEncodeMetricWithPoliciesList(
 			Gauge:        m.Gauge(),
 			PoliciesList: pl,
 		})
+	case metric.HistogramType:
+		return encoder.EncodeHistogramWithPoliciesList(unaggregated.HistogramWithPoliciesList{
+			Histogram:    m.Histogram(),
+			PoliciesList: pl,
+		})
 	default:
 		return fmt.Errorf("unrecognized metric type %v", m.Type)
 	}
@@ -434,6 +479,8 @@ func compareUnaggregatedMetric(
 		require.Equal(t, expected.BatchTimer(), actual.BatchTimer())
 	case metric.GaugeType:
 		require.Equal(t, expected.Gauge(), actual.Gauge())
+	case metric.HistogramType:
+		require.Equal(t, expected.Histogram(), actual.Histogram())
 	default:
 		require.Fail(t, fmt.Sprintf("unrecognized metric type %v", expected.Type))
 	}
@@ -48,6 +48,7 @@ func resetMetricWithMetadatasProto(pb *metricpb.MetricWithMetadatas) {
 	resetGaugeWithMetadatasProto(pb.GaugeWithMetadatas)
 	resetForwardedMetricWithMetadataProto(pb.ForwardedMetricWithMetadata)
 	resetTimedMetricWithMetadataProto(pb.TimedMetricWithMetadata)
+	resetHistogramWithMetadatasProto(pb.HistogramWithMetadatas)
 }
 
 func resetCounterWithMetadatasProto(pb *metricpb.CounterWithMetadatas) {
@@ -90,6 +91,14 @@ func resetTimedMetricWithMetadataProto(pb *metricpb.TimedMetricWithMetadata) {
 	resetTimedMetadata(&pb.Metadata)
 }
 
+func resetHistogramWithMetadatasProto(pb *metricpb.HistogramWithMetadatas) {
+	if pb == nil {
+		return
+	}
+	resetHistogram(&pb.Histogram)
+	resetMetadatas(&pb.Metadatas)
+}
+
 func resetTimedMetricWithStoragePolicyProto(pb *metricpb.TimedMetricWithStoragePolicy) {
 	if pb == nil {
 		return
@@ -122,6 +131,20 @@ func resetGauge(pb *metricpb.Gauge) {
 	pb.Value = 0.0
 }
 
+func resetHistogram(pb *metricpb.Histogram) {
+	if pb == nil {
+		return
+	}
+	pb.Id = pb.Id[:0]
+	pb.SchemaType = metricpb.HistogramSchemaType_FIXED_BUCKETS
+	pb.Bounds = pb.Bounds[:0]
+	pb.Start = 0
+	pb.Factor = 0
+	pb.NumBuckets = 0
+	pb.Counts = pb.Counts[:0]
+	pb.Sum = 0
+}
+
 func resetForwardedMetric(pb *metricpb.ForwardedMetric) {
 	if pb == nil {
 		return
@@ -57,6 +57,18 @@ var (
 		Id:    []byte{},
 		Value: 0.0,
 	}
+	testHistogramBeforeResetProto = metricpb.Histogram{
+		Id:         []byte("testHistogram"),
+		SchemaType: metricpb.HistogramSchemaType_FIXED_BUCKETS,
+		Bounds:     []float64{1, 5, 10},
+		Counts:     []uint64{2, 0, 9, 1},
+		Sum:        74.5,
+	}
+	testHistogramAfterResetProto = metricpb.Histogram{
+		Id:     []byte{},
+		Bounds: []float64{},
+		Counts: []uint64{},
+	}
 	testTimedMetricBeforeResetProto = metricpb.TimedMetric{
 		Type:      metricpb.MetricType_COUNTER,
 		Id:        []byte("testTimedMetric"),
@@ -208,6 +220,29 @@ func TestResetMetricWithMetadatasProtoOnlyGauge(t *testing.T) {
 	require.True(t, cap(input.GaugeWithMetadatas.Metadatas.Metadatas) > 0)
 }
 
+func TestResetMetricWithMetadatasProtoOnlyHistogram(t *testing.T) {
+	input := &metricpb.MetricWithMetadatas{
+		Type: metricpb.MetricWithMetadatas_HISTOGRAM_WITH_METADATAS,
+		HistogramWithMetadatas: &metricpb.HistogramWithMetadatas{
+			Histogram: testHistogramBeforeResetProto,
+			Metadatas: testMetadatasBeforeResetProto,
+		},
+	}
+	expected := &metricpb.MetricWithMetadatas{
+		Type: metricpb.MetricWithMetadatas_UNKNOWN,
+		HistogramWithMetadatas: &metricpb.HistogramWithMetadatas{
+			Histogram: testHistogramAfterResetProto,
+			Metadatas: testMetadatasAfterResetProto,
+		},
+	}
+	resetMetricWithMetadatasProto(input)
+	require.Equal(t, expected, input)
+	require.True(t, cap(input.HistogramWithMetadatas.Histogram.Id) > 0)
+	require.True(t, cap(input.HistogramWithMetadatas.Histogram.Bounds) > 0)
+	require.True(t, cap(input.HistogramWithMetadatas.Histogram.Counts) > 0)
+	require.True(t, cap(input.HistogramWithMetadatas.Metadatas.Metadatas) > 0)
+}
+
 func TestResetMetricWithMetadatasProtoOnlyForwardedMetric(t *testing.T) {
 	input := &metricpb.MetricWithMetadatas{
 		Type: metricpb.MetricWithMetadatas_FORWARDED_METRIC_WITH_METADATA,
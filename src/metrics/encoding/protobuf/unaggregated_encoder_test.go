@@ -35,6 +35,7 @@ import (
 	"github.com/m3db/m3/src/metrics/metadata"
 	"github.com/m3db/m3/src/metrics/metric"
 	"github.com/m3db/m3/src/metrics/metric/aggregated"
+	"github.com/m3db/m3/src/metrics/metric/histogram"
 	"github.com/m3db/m3/src/metrics/metric/unaggregated"
 	"github.com/m3db/m3/src/metrics/pipeline"
 	"github.com/m3db/m3/src/metrics/pipeline/applied"
@@ -72,6 +73,18 @@ var (
 		ID:    []byte("testGauge2"),
 		Value: 234231.345,
 	}
+	testHistogram1 = unaggregated.Histogram{
+		ID:     []byte("testHistogram1"),
+		Schema: histogram.NewFixedSchema([]float64{1, 10, 100}),
+		Counts: []uint64{4, 0, 17, 2},
+		Sum:    1234.5,
+	}
+	testHistogram2 = unaggregated.Histogram{
+		ID:     []byte("testHistogram2"),
+		Schema: histogram.NewExponentialSchema(0.001, 2, 20),
+		Counts: []uint64{0, 1, 5, 300},
+		Sum:    98.76,
+	}
 	testForwardedMetric1 = aggregated.ForwardedMetric{
 		Type:      metric.CounterType,
 		ID:        []byte("testForwardedMetric1"),
@@ -263,6 +276,22 @@ var (
 		Id:    []byte("testGauge2"),
 		Value: 234231.345,
 	}
+	testHistogram1Proto = metricpb.Histogram{
+		Id:         []byte("testHistogram1"),
+		SchemaType: metricpb.HistogramSchemaType_FIXED_BUCKETS,
+		Bounds:     []float64{1, 10, 100},
+		Counts:     []uint64{4, 0, 17, 2},
+		Sum:        1234.5,
+	}
+	testHistogram2Proto = metricpb.Histogram{
+		Id:         []byte("testHistogram2"),
+		SchemaType: metricpb.HistogramSchemaType_EXPONENTIAL_BUCKETS,
+		Start:      0.001,
+		Factor:     2,
+		NumBuckets: 20,
+		Counts:     []uint64{0, 1, 5, 300},
+		Sum:        98.76,
+	}
 	testForwardedMetric1Proto = metricpb.ForwardedMetric{
 		Type:      metricpb.MetricType_COUNTER,
 		Id:        []byte("testForwardedMetric1"),
@@ -702,6 +731,50 @@ func TestUnaggregatedEncoderEncodeGaugeWithMetadatas(t *testing.T) {
 	}
 }
 
+func TestUnaggregatedEncoderEncodeHistogramWithMetadatas(t *testing.T) {
+	inputs := []unaggregated.HistogramWithMetadatas{
+		{
+			Histogram:       testHistogram1,
+			StagedMetadatas: testStagedMetadatas1,
+		},
+		{
+			Histogram:       testHistogram2,
+			StagedMetadatas: testStagedMetadatas2,
+		},
+	}
+	expected := []metricpb.HistogramWithMetadatas{
+		{
+			Histogram: testHistogram1Proto,
+			Metadatas: testStagedMetadatas1Proto,
+		},
+		{
+			Histogram: testHistogram2Proto,
+			Metadatas: testStagedMetadatas2Proto,
+		},
+	}
+
+	var (
+		sizeRes int
+		pbRes   metricpb.MetricWithMetadatas
+	)
+	enc := NewUnaggregatedEncoder(NewUnaggregatedOptions())
+	enc.(*unaggregatedEncoder).encodeMessageSizeFn = func(size int) { sizeRes = size }
+	enc.(*unaggregatedEncoder).encodeMessageFn = func(pb metricpb.MetricWithMetadatas) error { pbRes = pb; return nil }
+	for i, input := range inputs {
+		require.NoError(t, enc.EncodeMessage(encoding.UnaggregatedMessageUnion{
+			Type:                   encoding.HistogramWithMetadatasType,
+			HistogramWithMetadatas: input,
+		}))
+		expectedProto := metricpb.MetricWithMetadatas{
+			Type:                   metricpb.MetricWithMetadatas_HISTOGRAM_WITH_METADATAS,
+			HistogramWithMetadatas: &expected[i],
+		}
+		expectedMsgSize := expectedProto.Size()
+		require.Equal(t, expectedMsgSize, sizeRes)
+		require.Equal(t, expectedProto, pbRes)
+	}
+}
+
 func TestUnaggregatedEncoderEncodeForwardedMetricWithMetadata(t *testing.T) {
 	inputs := []aggregated.ForwardedMetricWithMetadata{
 		{
@@ -25,9 +25,11 @@
 Package aggregationpb is a generated protocol buffer package.
 
 It is generated from these files:
+
 	github.com/m3db/m3/src/metrics/generated/proto/aggregationpb/aggregation.proto
 
 It has these top-level messages:
+
 	AggregationID
 */
 package aggregationpb
@@ -52,29 +54,31 @@ const _ = proto.GoGoProtoPackageIsVersion2 // please upgrade the proto package
 type AggregationType int32
 
 const (
-	AggregationType_UNKNOWN AggregationType = 0
-	AggregationType_LAST    AggregationType = 1
-	AggregationType_MIN     AggregationType = 2
-	AggregationType_MAX     AggregationType = 3
-	AggregationType_MEAN    AggregationType = 4
-	AggregationType_MEDIAN  AggregationType = 5
-	AggregationType_COUNT   AggregationType = 6
-	AggregationType_SUM     AggregationType = 7
-	AggregationType_SUMSQ   AggregationType = 8
-	AggregationType_STDEV   AggregationType = 9
-	AggregationType_P10     AggregationType = 10
-	AggregationType_P20     AggregationType = 11
-	AggregationType_P30     AggregationType = 12
-	AggregationType_P40     AggregationType = 13
-	AggregationType_P50     AggregationType = 14
-	AggregationType_P60     AggregationType = 15
-	AggregationType_P70     AggregationType = 16
-	AggregationType_P80     AggregationType = 17
-	AggregationType_P90     AggregationType = 18
-	AggregationType_P95     AggregationType = 19
-	AggregationType_P99     AggregationType = 20
-	AggregationType_P999    AggregationType = 21
-	AggregationType_P9999   AggregationType = 22
+	AggregationType_UNKNOWN        AggregationType = 0
+	AggregationType_LAST           AggregationType = 1
+	AggregationType_MIN            AggregationType = 2
+	AggregationType_MAX            AggregationType = 3
+	AggregationType_MEAN           AggregationType = 4
+	AggregationType_MEDIAN         AggregationType = 5
+	AggregationType_COUNT          AggregationType = 6
+	AggregationType_SUM            AggregationType = 7
+	AggregationType_SUMSQ          AggregationType = 8
+	AggregationType_STDEV          AggregationType = 9
+	AggregationType_P10            AggregationType = 10
+	AggregationType_P20            AggregationType = 11
+	AggregationType_P30            AggregationType = 12
+	AggregationType_P40            AggregationType = 13
+	AggregationType_P50            AggregationType = 14
+	AggregationType_P60            AggregationType = 15
+	AggregationType_P70            AggregationType = 16
+	AggregationType_P80            AggregationType = 17
+	AggregationType_P90            AggregationType = 18
+	AggregationType_P95            AggregationType = 19
+	AggregationType_P99            AggregationType = 20
+	AggregationType_P999           AggregationType = 21
+	AggregationType_P9999          AggregationType = 22
+	AggregationType_RATE           AggregationType = 23
+	AggregationType_DISTINCT_COUNT AggregationType = 24
 )
 
 var AggregationType_name = map[int32]string{
@@ -101,31 +105,35 @@ var AggregationType_name = map[int32]string{
 	20: "P99",
 	21: "P999",
 	22: "P9999",
+	23: "RATE",
+	24: "DISTINCT_COUNT",
 }
 var AggregationType_value = map[string]int32{
-	"UNKNOWN": 0,
-	"LAST":    1,
-	"MIN":     2,
-	"MAX":     3,
-	"MEAN":    4,
-	"MEDIAN":  5,
-	"COUNT":   6,
-	"SUM":     7,
-	"SUMSQ":   8,
-	"STDEV":   9,
-	"P10":     10,
-	"P20":     11,
-	"P30":     12,
-	"P40":     13,
-	"P50":     14,
-	"P60":     15,
-	"P70":     16,
-	"P80":     17,
-	"P90":     18,
-	"P95":     19,
-	"P99":     20,
-	"P999":    21,
-	"P9999":   22,
+	"UNKNOWN":        0,
+	"LAST":           1,
+	"MIN":            2,
+	"MAX":            3,
+	"MEAN":           4,
+	"MEDIAN":         5,
+	"COUNT":          6,
+	"SUM":            7,
+	"SUMSQ":          8,
+	"STDEV":          9,
+	"P10":            10,
+	"P20":            11,
+	"P30":            12,
+	"P40":            13,
+	"P50":            14,
+	"P60":            15,
+	"P70":            16,
+	"P80":            17,
+	"P90":            18,
+	"P95":            19,
+	"P99":            20,
+	"P999":           21,
+	"P9999":          22,
+	"RATE":           23,
+	"DISTINCT_COUNT": 24,
 }
 
 func (x AggregationType) String() string {
@@ -38,6 +38,7 @@
 		TimedMetricWithStoragePolicy
 		AggregatedMetric
 		MetricWithMetadatas
+		HistogramWithMetadatas
 		PipelineMetadata
 		Metadata
 		StagedMetadata
@@ -49,6 +50,7 @@
 		Gauge
 		TimedMetric
 		ForwardedMetric
+		Histogram
 */
 package metricpb
 
@@ -80,6 +82,7 @@ const (
 	MetricWithMetadatas_GAUGE_WITH_METADATAS           MetricWithMetadatas_Type = 3
 	MetricWithMetadatas_FORWARDED_METRIC_WITH_METADATA MetricWithMetadatas_Type = 4
 	MetricWithMetadatas_TIMED_METRIC_WITH_METADATA     MetricWithMetadatas_Type = 5
+	MetricWithMetadatas_HISTOGRAM_WITH_METADATAS       MetricWithMetadatas_Type = 6
 )
 
 var MetricWithMetadatas_Type_name = map[int32]string{
@@ -89,6 +92,7 @@ var MetricWithMetadatas_Type_name = map[int32]string{
 	3: "GAUGE_WITH_METADATAS",
 	4: "FORWARDED_METRIC_WITH_METADATA",
 	5: "TIMED_METRIC_WITH_METADATA",
+	6: "HISTOGRAM_WITH_METADATAS",
 }
 var MetricWithMetadatas_Type_value = map[string]int32{
 	"UNKNOWN":                        0,
@@ -97,6 +101,7 @@ var MetricWithMetadatas_Type_value = map[string]int32{
 	"GAUGE_WITH_METADATAS":           3,
 	"FORWARDED_METRIC_WITH_METADATA": 4,
 	"TIMED_METRIC_WITH_METADATA":     5,
+	"HISTOGRAM_WITH_METADATAS":       6,
 }
 
 func (x MetricWithMetadatas_Type) String() string {
@@ -291,6 +296,7 @@ type MetricWithMetadatas struct {
 	GaugeWithMetadatas          *GaugeWithMetadatas          `protobuf:"bytes,4,opt,name=gauge_with_metadatas,json=gaugeWithMetadatas" json:"gauge_with_metadatas,omitempty"`
 	ForwardedMetricWithMetadata *ForwardedMetricWithMetadata `protobuf:"bytes,5,opt,name=forwarded_metric_with_metadata,json=forwardedMetricWithMetadata" json:"forwarded_metric_with_metadata,omitempty"`
 	TimedMetricWithMetadata     *TimedMetricWithMetadata     `protobuf:"bytes,6,opt,name=timed_metric_with_metadata,json=timedMetricWithMetadata" json:"timed_metric_with_metadata,omitempty"`
+	HistogramWithMetadatas      *HistogramWithMetadatas      `protobuf:"bytes,7,opt,name=histogram_with_metadatas,json=histogramWithMetadatas" json:"histogram_with_metadatas,omitempty"`
 }
 
 func (m *MetricWithMetadatas) Reset()                    { *m = MetricWithMetadatas{} }
@@ -340,6 +346,37 @@ func (m *MetricWithMetadatas) GetTimedMetricWithMetadata() *TimedMetricWithMetad
 	return nil
 }
 
+func (m *MetricWithMetadatas) GetHistogramWithMetadatas() *HistogramWithMetadatas {
+	if m != nil {
+		return m.HistogramWithMetadatas
+	}
+	return nil
+}
+
+type HistogramWithMetadatas struct {
+	Histogram Histogram       `protobuf:"bytes,1,opt,name=histogram" json:"histogram"`
+	Metadatas StagedMetadatas `protobuf:"bytes,2,opt,name=metadatas" json:"metadatas"`
+}
+
+func (m *HistogramWithMetadatas) Reset()                    { *m = HistogramWithMetadatas{} }
+func (m *HistogramWithMetadatas) String() string            { return proto.CompactTextString(m) }
+func (*HistogramWithMetadatas) ProtoMessage()               {}
+func (*HistogramWithMetadatas) Descriptor() ([]byte, []int) { return fileDescriptorComposite, []int{8} }
+
+func (m *HistogramWithMetadatas) GetHistogram() Histogram {
+	if m != nil {
+		return m.Histogram
+	}
+	return Histogram{}
+}
+
+func (m *HistogramWithMetadatas) GetMetadatas() StagedMetadatas {
+	if m != nil {
+		return m.Metadatas
+	}
+	return StagedMetadatas{}
+}
+
 func init() {
 	proto.RegisterType((*CounterWithMetadatas)(nil), "metricpb.CounterWithMetadatas")
 	proto.RegisterType((*BatchTimerWithMetadatas)(nil), "metricpb.BatchTimerWithMetadatas")
@@ -349,6 +386,7 @@ func init() {
 	proto.RegisterType((*TimedMetricWithStoragePolicy)(nil), "metricpb.TimedMetricWithStoragePolicy")
 	proto.RegisterType((*AggregatedMetric)(nil), "metricpb.AggregatedMetric")
 	proto.RegisterType((*MetricWithMetadatas)(nil), "metricpb.MetricWithMetadatas")
+	proto.RegisterType((*HistogramWithMetadatas)(nil), "metricpb.HistogramWithMetadatas")
 	proto.RegisterEnum("metricpb.MetricWithMetadatas_Type", MetricWithMetadatas_Type_name, MetricWithMetadatas_Type_value)
 }
 func (m *CounterWithMetadatas) Marshal() (dAtA []byte, err error) {
@@ -656,6 +694,50 @@ func (m *MetricWithMetadatas) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i += n18
 	}
+	if m.HistogramWithMetadatas != nil {
+		dAtA[i] = 0x3a
+		i++
+		i = encodeVarintComposite(dAtA, i, uint64(m.HistogramWithMetadatas.Size()))
+		n19, err := m.HistogramWithMetadatas.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n19
+	}
+	return i, nil
+}
+
+func (m *HistogramWithMetadatas) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *HistogramWithMetadatas) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	dAtA[i] = 0xa
+	i++
+	i = encodeVarintComposite(dAtA, i, uint64(m.Histogram.Size()))
+	n20, err := m.Histogram.MarshalTo(dAtA[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n20
+	dAtA[i] = 0x12
+	i++
+	i = encodeVarintComposite(dAtA, i, uint64(m.Metadatas.Size()))
+	n21, err := m.Metadatas.MarshalTo(dAtA[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n21
 	return i, nil
 }
 
@@ -765,6 +847,20 @@ func (m *MetricWithMetadatas) Size() (n int) {
 		l = m.TimedMetricWithMetadata.Size()
 		n += 1 + l + sovComposite(uint64(l))
 	}
+	if m.HistogramWithMetadatas != nil {
+		l = m.HistogramWithMetadatas.Size()
+		n += 1 + l + sovComposite(uint64(l))
+	}
+	return n
+}
+
+func (m *HistogramWithMetadatas) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Histogram.Size()
+	n += 1 + l + sovComposite(uint64(l))
+	l = m.Metadatas.Size()
+	n += 1 + l + sovComposite(uint64(l))
 	return n
 }
 
@@ -1753,6 +1849,149 @@ func (m *MetricWithMetadatas) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HistogramWithMetadatas", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowComposite
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthComposite
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.HistogramWithMetadatas == nil {
+				m.HistogramWithMetadatas = &HistogramWithMetadatas{}
+			}
+			if err := m.HistogramWithMetadatas.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipComposite(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthComposite
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *HistogramWithMetadatas) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowComposite
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: HistogramWithMetadatas: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: HistogramWithMetadatas: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Histogram", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowComposite
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthComposite
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Histogram.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metadatas", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowComposite
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthComposite
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Metadatas.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipComposite(dAtA[iNdEx:])
@@ -46,6 +46,8 @@ import _ "github.com/gogo/protobuf/gogoproto"
 import aggregationpb "github.com/m3db/m3/src/metrics/generated/proto/aggregationpb"
 import transformationpb "github.com/m3db/m3/src/metrics/generated/proto/transformationpb"
 
+import binary "encoding/binary"
+
 import io "io"
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -129,7 +131,9 @@ func (m *AggregationOp) GetType() aggregationpb.AggregationType {
 }
 
 type TransformationOp struct {
-	Type transformationpb.TransformationType `protobuf:"varint,1,opt,name=type,proto3,enum=transformationpb.TransformationType" json:"type,omitempty"`
+	Type        transformationpb.TransformationType `protobuf:"varint,1,opt,name=type,proto3,enum=transformationpb.TransformationType" json:"type,omitempty"`
+	Constant    float64                             `protobuf:"fixed64,2,opt,name=constant,proto3" json:"constant,omitempty"`
+	WindowNanos int64                               `protobuf:"varint,3,opt,name=window_nanos,json=windowNanos,proto3" json:"window_nanos,omitempty"`
 }
 
 func (m *TransformationOp) Reset()                    { *m = TransformationOp{} }
@@ -144,10 +148,25 @@ func (m *TransformationOp) GetType() transformationpb.TransformationType {
 	return transformationpb.TransformationType_UNKNOWN
 }
 
+func (m *TransformationOp) GetConstant() float64 {
+	if m != nil {
+		return m.Constant
+	}
+	return 0
+}
+
+func (m *TransformationOp) GetWindowNanos() int64 {
+	if m != nil {
+		return m.WindowNanos
+	}
+	return 0
+}
+
 type RollupOp struct {
 	NewName          string                          `protobuf:"bytes,1,opt,name=new_name,json=newName,proto3" json:"new_name,omitempty"`
 	Tags             []string                        `protobuf:"bytes,2,rep,name=tags" json:"tags,omitempty"`
 	AggregationTypes []aggregationpb.AggregationType `protobuf:"varint,3,rep,packed,name=aggregation_types,json=aggregationTypes,enum=aggregationpb.AggregationType" json:"aggregation_types,omitempty"`
+	DistinctTag      string                          `protobuf:"bytes,4,opt,name=distinct_tag,json=distinctTag,proto3" json:"distinct_tag,omitempty"`
 }
 
 func (m *RollupOp) Reset()                    { *m = RollupOp{} }
@@ -176,6 +195,13 @@ func (m *RollupOp) GetAggregationTypes() []aggregationpb.AggregationType {
 	return nil
 }
 
+func (m *RollupOp) GetDistinctTag() string {
+	if m != nil {
+		return m.DistinctTag
+	}
+	return ""
+}
+
 type PipelineOp struct {
 	Type           PipelineOp_Type   `protobuf:"varint,1,opt,name=type,proto3,enum=pipelinepb.PipelineOp_Type" json:"type,omitempty"`
 	Aggregation    *AggregationOp    `protobuf:"bytes,2,opt,name=aggregation" json:"aggregation,omitempty"`
@@ -236,7 +262,8 @@ func (m *Pipeline) GetOps() []PipelineOp {
 // applied against a metric.
 type AppliedRollupOp struct {
 	Id            []byte                      `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	AggregationId aggregationpb.AggregationID `protobuf:"bytes,2,opt,name=aggregation_id,json=aggregationId" json:"aggregation_id"`
+	AggregationId    aggregationpb.AggregationID `protobuf:"bytes,2,opt,name=aggregation_id,json=aggregationId" json:"aggregation_id"`
+	DistinctTagValue []byte                      `protobuf:"bytes,3,opt,name=distinct_tag_value,json=distinctTagValue,proto3" json:"distinct_tag_value,omitempty"`
 }
 
 func (m *AppliedRollupOp) Reset()                    { *m = AppliedRollupOp{} }
@@ -258,6 +285,13 @@ func (m *AppliedRollupOp) GetAggregationId() aggregationpb.AggregationID {
 	return aggregationpb.AggregationID{}
 }
 
+func (m *AppliedRollupOp) GetDistinctTagValue() []byte {
+	if m != nil {
+		return m.DistinctTagValue
+	}
+	return nil
+}
+
 // AppliedPipelineOp is a pipeline operation that has
 // been applied against a metric.
 type AppliedPipelineOp struct {
@@ -365,6 +399,17 @@ func (m *TransformationOp) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintPipeline(dAtA, i, uint64(m.Type))
 	}
+	if m.Constant != 0 {
+		dAtA[i] = 0x11
+		i++
+		binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.Constant))))
+		i += 8
+	}
+	if m.WindowNanos != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintPipeline(dAtA, i, uint64(m.WindowNanos))
+	}
 	return i, nil
 }
 
@@ -421,6 +466,12 @@ func (m *RollupOp) MarshalTo(dAtA []byte) (int, error) {
 		i = encodeVarintPipeline(dAtA, i, uint64(j1))
 		i += copy(dAtA[i:], dAtA2[:j1])
 	}
+	if len(m.DistinctTag) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintPipeline(dAtA, i, uint64(len(m.DistinctTag)))
+		i += copy(dAtA[i:], m.DistinctTag)
+	}
 	return i, nil
 }
 
@@ -536,6 +587,12 @@ func (m *AppliedRollupOp) MarshalTo(dAtA []byte) (int, error) {
 		return 0, err
 	}
 	i += n6
+	if len(m.DistinctTagValue) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintPipeline(dAtA, i, uint64(len(m.DistinctTagValue)))
+		i += copy(dAtA[i:], m.DistinctTagValue)
+	}
 	return i, nil
 }
 
@@ -636,6 +693,12 @@ func (m *TransformationOp) Size() (n int) {
 	if m.Type != 0 {
 		n += 1 + sovPipeline(uint64(m.Type))
 	}
+	if m.Constant != 0 {
+		n += 9
+	}
+	if m.WindowNanos != 0 {
+		n += 1 + sovPipeline(uint64(m.WindowNanos))
+	}
 	return n
 }
 
@@ -659,6 +722,10 @@ func (m *RollupOp) Size() (n int) {
 		}
 		n += 1 + sovPipeline(uint64(l)) + l
 	}
+	l = len(m.DistinctTag)
+	if l > 0 {
+		n += 1 + l + sovPipeline(uint64(l))
+	}
 	return n
 }
 
@@ -704,6 +771,10 @@ func (m *AppliedRollupOp) Size() (n int) {
 	}
 	l = m.AggregationId.Size()
 	n += 1 + l + sovPipeline(uint64(l))
+	l = len(m.DistinctTagValue)
+	if l > 0 {
+		n += 1 + l + sovPipeline(uint64(l))
+	}
 	return n
 }
 
@@ -866,6 +937,36 @@ func (m *TransformationOp) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 2:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Constant", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.Constant = float64(math.Float64frombits(v))
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field WindowNanos", wireType)
+			}
+			m.WindowNanos = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPipeline
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.WindowNanos |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipPipeline(dAtA[iNdEx:])
@@ -1036,6 +1137,35 @@ func (m *RollupOp) Unmarshal(dAtA []byte) error {
 			} else {
 				return fmt.Errorf("proto: wrong wireType = %d for field AggregationTypes", wireType)
 			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DistinctTag", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPipeline
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPipeline
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DistinctTag = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipPipeline(dAtA[iNdEx:])
@@ -1396,6 +1526,37 @@ func (m *AppliedRollupOp) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DistinctTagValue", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPipeline
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthPipeline
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DistinctTagValue = append(m.DistinctTagValue[:0], dAtA[iNdEx:postIndex]...)
+			if m.DistinctTagValue == nil {
+				m.DistinctTagValue = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipPipeline(dAtA[iNdEx:])
@@ -1743,42 +1904,44 @@ func init() {
 }
 
 var fileDescriptorPipeline = []byte{
-	// 587 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x94, 0x4d, 0x6e, 0xd3, 0x40,
-	0x1c, 0xc5, 0x3b, 0x76, 0x94, 0xa6, 0xff, 0xd0, 0x34, 0x1d, 0x21, 0xe4, 0x7e, 0x10, 0x22, 0x8b,
-	0x45, 0x16, 0x60, 0x4b, 0x89, 0x40, 0x7c, 0xac, 0x52, 0x02, 0x69, 0x94, 0x60, 0x57, 0x43, 0x2a,
-	0x24, 0x36, 0x95, 0x1d, 0x4f, 0x8d, 0xa5, 0xd8, 0x1e, 0xd9, 0x8e, 0xaa, 0x5e, 0x80, 0x35, 0x87,
-	0xe1, 0x10, 0x5d, 0x72, 0x02, 0x84, 0xc2, 0x31, 0xd8, 0xa0, 0xd8, 0x4e, 0x32, 0x93, 0x04, 0x44,
-	0xbb, 0x1b, 0x8f, 0xdf, 0x7b, 0xf3, 0x9f, 0xf7, 0xb3, 0x0c, 0xa7, 0xae, 0x97, 0x7c, 0x9e, 0xd8,
-	0xda, 0x28, 0xf4, 0x75, 0xbf, 0xe5, 0xd8, 0xba, 0xdf, 0xd2, 0xe3, 0x68, 0xa4, 0xfb, 0x34, 0x89,
-	0xbc, 0x51, 0xac, 0xbb, 0x34, 0xa0, 0x91, 0x95, 0x50, 0x47, 0x67, 0x51, 0x98, 0x84, 0x3a, 0xf3,
-	0x18, 0x1d, 0x7b, 0x01, 0x65, 0xf6, 0x62, 0xa9, 0xa5, 0x6f, 0x30, 0x2c, 0x5f, 0x1d, 0x3e, 0xe5,
-	0x52, 0xdd, 0xd0, 0x0d, 0x33, 0xb3, 0x3d, 0xb9, 0x4c, 0x9f, 0xb2, 0xa4, 0xd9, 0x2a, 0xb3, 0x1e,
-	0x1a, 0xb7, 0x1c, 0xc2, 0x72, 0xdd, 0x88, 0xba, 0x56, 0xe2, 0x85, 0x01, 0xb3, 0xf9, 0xa7, 0x3c,
-	0x6f, 0x78, 0xcb, 0xbc, 0x24, 0xb2, 0x82, 0xf8, 0x32, 0x8c, 0xfc, 0x79, 0xa4, 0xb8, 0x91, 0xa5,
-	0xaa, 0x6f, 0x60, 0xb7, 0xbd, 0x3c, 0xca, 0x64, 0xb8, 0x09, 0x85, 0xe4, 0x9a, 0x51, 0x05, 0xd5,
-	0x51, 0xa3, 0xd2, 0xac, 0x69, 0xc2, 0x58, 0x1a, 0xa7, 0x1d, 0x5e, 0x33, 0x4a, 0x52, 0xad, 0x3a,
-	0x80, 0xea, 0x50, 0x08, 0x37, 0x19, 0x7e, 0x21, 0xe4, 0x3c, 0xd6, 0x56, 0xc7, 0xd1, 0x44, 0x07,
-	0x97, 0xf6, 0x05, 0x41, 0x89, 0x84, 0xe3, 0xf1, 0x84, 0x99, 0x0c, 0x1f, 0x40, 0x29, 0xa0, 0x57,
-	0x17, 0x81, 0xe5, 0x67, 0x51, 0x3b, 0x64, 0x3b, 0xa0, 0x57, 0x86, 0xe5, 0x53, 0x8c, 0xa1, 0x90,
-	0x58, 0x6e, 0xac, 0x48, 0x75, 0xb9, 0xb1, 0x43, 0xd2, 0x35, 0xee, 0xc3, 0x3e, 0x37, 0xf0, 0xc5,
-	0x2c, 0x2f, 0x56, 0xe4, 0xba, 0xfc, 0x1f, 0x57, 0xa9, 0x5a, 0xe2, 0x46, 0xac, 0x7e, 0x93, 0x00,
-	0xce, 0x72, 0xfe, 0x26, 0xc3, 0xba, 0x70, 0xa3, 0x23, 0x6d, 0xf9, 0x69, 0x68, 0x4b, 0x95, 0xb6,
-	0xbc, 0x08, 0x7e, 0x0d, 0x65, 0x2e, 0x53, 0x91, 0xea, 0xa8, 0x51, 0x6e, 0x1e, 0xf0, 0x3e, 0xa1,
-	0x7a, 0xc2, 0xab, 0x71, 0x07, 0x2a, 0x62, 0x65, 0x8a, 0x9c, 0xfa, 0x8f, 0x79, 0xff, 0x6a, 0xeb,
-	0x64, 0xc5, 0x83, 0x9f, 0x40, 0x31, 0x4a, 0xab, 0x54, 0x0a, 0xa9, 0xfb, 0x3e, 0xef, 0x9e, 0x97,
-	0x4c, 0x72, 0x8d, 0xda, 0x81, 0xc2, 0x6c, 0x7c, 0x5c, 0x86, 0xed, 0x73, 0xa3, 0x6f, 0x98, 0x1f,
-	0x8d, 0xea, 0x16, 0xde, 0x83, 0x72, 0xbb, 0xdb, 0x25, 0x6f, 0xbb, 0xed, 0x61, 0xcf, 0x34, 0xaa,
-	0x08, 0x63, 0xa8, 0x0c, 0x49, 0xdb, 0xf8, 0xf0, 0xce, 0x24, 0xef, 0xb3, 0x3d, 0x09, 0x03, 0x14,
-	0x89, 0x39, 0x18, 0x9c, 0x9f, 0x55, 0x65, 0xf5, 0x15, 0x94, 0xe6, 0x7d, 0x60, 0x0d, 0xe4, 0x90,
-	0xc5, 0x0a, 0xaa, 0xcb, 0x8d, 0x72, 0xf3, 0xc1, 0xe6, 0xca, 0x4e, 0x0a, 0x37, 0x3f, 0x1e, 0x6d,
-	0x91, 0x99, 0x50, 0x1d, 0xc3, 0x5e, 0x9b, 0xb1, 0xb1, 0x47, 0x9d, 0xc5, 0x17, 0x50, 0x01, 0xc9,
-	0x73, 0xd2, 0xd2, 0xef, 0x11, 0xc9, 0x73, 0x70, 0x0f, 0x2a, 0x3c, 0x62, 0xcf, 0xc9, 0x8b, 0x3d,
-	0xfe, 0x3b, 0xdf, 0x5e, 0x27, 0x3f, 0x63, 0x97, 0x93, 0xf4, 0x1c, 0xf5, 0x37, 0x82, 0xfd, 0xfc,
-	0x38, 0x8e, 0xf3, 0x73, 0x81, 0xb3, 0x2a, 0xf0, 0x5a, 0x15, 0xf3, 0xb8, 0xd7, 0x89, 0x49, 0x77,
-	0x20, 0xd6, 0x5a, 0x10, 0xcb, 0x78, 0x1f, 0x6d, 0x38, 0x7f, 0x0d, 0x5c, 0x6b, 0x13, 0xb8, 0x75,
-	0x4e, 0x88, 0xe3, 0x24, 0xa9, 0xa7, 0x8b, 0xae, 0x17, 0xb8, 0x9e, 0xf1, 0xb8, 0x1e, 0xfe, 0xf3,
-	0xe6, 0x1c, 0xb5, 0x93, 0xfe, 0xcd, 0xb4, 0x86, 0xbe, 0x4f, 0x6b, 0xe8, 0xe7, 0xb4, 0x86, 0xbe,
-	0xfe, 0xaa, 0x6d, 0x7d, 0x7a, 0x79, 0xe7, 0x3f, 0xb0, 0x5d, 0x4c, 0x77, 0x5a, 0x7f, 0x02, 0x00,
-	0x00, 0xff, 0xff, 0x26, 0x29, 0x00, 0xcc, 0xc5, 0x05, 0x00, 0x00,
+	// 611 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9d, 0x54, 0xcb, 0x6e, 0xd3, 0x40,
+	0x14, 0x8d, 0xed, 0x28, 0x0d, 0xd7, 0x6d, 0x9a, 0x8e, 0x10, 0x4a, 0x53, 0x08, 0x60, 0xb1, 0x60,
+	0x41, 0x6d, 0x29, 0x11, 0x88, 0xc7, 0xca, 0x25, 0x90, 0x46, 0x29, 0x4e, 0x35, 0xa4, 0xaa, 0xc4,
+	0xa6, 0x72, 0x62, 0xd7, 0xb5, 0x14, 0x7b, 0x2c, 0xdb, 0x51, 0xc5, 0x0f, 0xb0, 0x61, 0xc3, 0xc7,
+	0xf0, 0x11, 0x5d, 0xf2, 0x05, 0x08, 0xc1, 0x67, 0xb0, 0x61, 0xfc, 0x88, 0x33, 0x93, 0x04, 0x44,
+	0xbb, 0xb0, 0x35, 0x73, 0xe7, 0xdc, 0x73, 0x8f, 0xcf, 0x19, 0x19, 0x0e, 0x1d, 0x37, 0xbe, 0x98,
+	0x8d, 0xd5, 0x09, 0xf1, 0x34, 0xaf, 0x63, 0x8d, 0xe9, 0x4b, 0x8b, 0xc2, 0x89, 0xe6, 0xd9, 0x71,
+	0xe8, 0x4e, 0x22, 0xcd, 0xb1, 0x7d, 0x3b, 0x34, 0x63, 0xdb, 0xd2, 0x82, 0x90, 0xc4, 0x44, 0x0b,
+	0xdc, 0xc0, 0x9e, 0xba, 0xbe, 0x1d, 0x8c, 0x8b, 0xa5, 0x9a, 0x9e, 0x20, 0x58, 0x1c, 0x35, 0xf7,
+	0x19, 0x56, 0x87, 0x38, 0x24, 0x6b, 0x1e, 0xcf, 0xce, 0xd3, 0x5d, 0xc6, 0x94, 0xac, 0xb2, 0xd6,
+	0xa6, 0x71, 0x4d, 0x11, 0xa6, 0xe3, 0x84, 0xb6, 0x63, 0xc6, 0x2e, 0xf1, 0xa9, 0x0e, 0x66, 0x97,
+	0xf3, 0x8d, 0xae, 0xc9, 0x17, 0x87, 0xa6, 0x1f, 0x9d, 0x93, 0xd0, 0x9b, 0x53, 0xf2, 0x85, 0x8c,
+	0x55, 0x79, 0x0d, 0x5b, 0xfa, 0x62, 0xd4, 0x30, 0x40, 0x6d, 0x28, 0xc7, 0x1f, 0x03, 0xbb, 0x21,
+	0x3c, 0x10, 0x1e, 0xd7, 0xda, 0x2d, 0x95, 0x93, 0xa5, 0x32, 0xd8, 0x11, 0x45, 0xe1, 0x14, 0xab,
+	0x7c, 0x16, 0xa0, 0x3e, 0xe2, 0xd8, 0x29, 0xd1, 0x73, 0x8e, 0xe8, 0x91, 0xba, 0xac, 0x47, 0xe5,
+	0x3b, 0x16, 0x74, 0xa8, 0x09, 0xd5, 0x09, 0xf1, 0xa3, 0xd8, 0xf4, 0xe3, 0x86, 0x48, 0xbb, 0x05,
+	0x5c, 0xec, 0xd1, 0x43, 0xd8, 0xbc, 0x74, 0x7d, 0x8b, 0x5c, 0x9e, 0xf9, 0xa6, 0x4f, 0xa2, 0x86,
+	0x44, 0xcf, 0x25, 0x2c, 0x67, 0x35, 0x23, 0x29, 0x29, 0x9f, 0x04, 0xa8, 0x62, 0x32, 0x9d, 0xce,
+	0x02, 0xaa, 0x62, 0x17, 0xaa, 0xbe, 0x9d, 0x80, 0xbd, 0x4c, 0xc9, 0x2d, 0xbc, 0x41, 0xf7, 0x06,
+	0xdd, 0x22, 0x44, 0x05, 0x9a, 0x4e, 0x44, 0x47, 0x48, 0xb4, 0x9c, 0xae, 0xd1, 0x00, 0x76, 0x98,
+	0x0f, 0x3e, 0x4b, 0xe4, 0x24, 0x33, 0xa4, 0xff, 0xb0, 0xa2, 0x6e, 0xf2, 0x85, 0x48, 0xf9, 0x2a,
+	0x02, 0x1c, 0xe7, 0xf7, 0x87, 0x4a, 0xd1, 0x38, 0x43, 0xf6, 0xd4, 0xc5, 0xd5, 0x52, 0x17, 0x28,
+	0x95, 0xf1, 0xe1, 0x15, 0xc8, 0x0c, 0x67, 0x6a, 0x85, 0xdc, 0xde, 0x65, 0xfb, 0xb8, 0xe8, 0x30,
+	0x8b, 0x46, 0x5d, 0xa8, 0xf1, 0x8e, 0xa7, 0x56, 0xc9, 0xed, 0xbb, 0x6c, 0xff, 0x72, 0x68, 0x78,
+	0xa9, 0x07, 0x3d, 0x81, 0x4a, 0x98, 0x5a, 0xd9, 0x28, 0xa7, 0xdd, 0xb7, 0xd9, 0xee, 0xb9, 0xc9,
+	0x38, 0xc7, 0x28, 0x5d, 0x28, 0x27, 0xf2, 0x91, 0x0c, 0x1b, 0x27, 0xc6, 0xc0, 0x18, 0x9e, 0x1a,
+	0xf5, 0x12, 0xda, 0x06, 0x59, 0xef, 0xf5, 0xf0, 0x9b, 0x9e, 0x3e, 0xea, 0x0f, 0x8d, 0xba, 0x40,
+	0x7d, 0xaf, 0x8d, 0xb0, 0x6e, 0xbc, 0x7f, 0x3b, 0xc4, 0xef, 0xb2, 0x9a, 0x88, 0x00, 0x2a, 0x78,
+	0x78, 0x74, 0x74, 0x72, 0x5c, 0x97, 0x94, 0x97, 0x50, 0x9d, 0xfb, 0x81, 0x54, 0x90, 0x48, 0x10,
+	0x51, 0xcb, 0x24, 0x3a, 0xfc, 0xce, 0x7a, 0xcb, 0x0e, 0xca, 0x57, 0xdf, 0xef, 0x97, 0x70, 0x02,
+	0x54, 0xa6, 0xb0, 0xad, 0x07, 0xc1, 0xd4, 0xb5, 0xad, 0xe2, 0x06, 0xd4, 0x40, 0x74, 0xad, 0xd4,
+	0xf4, 0x4d, 0x4c, 0x57, 0xa8, 0x0f, 0x35, 0x36, 0x62, 0x7a, 0x26, 0xe6, 0xc6, 0xfc, 0x35, 0xdf,
+	0x7e, 0x37, 0x9f, 0xb1, 0xc5, 0x40, 0xfa, 0x96, 0xf2, 0x5b, 0x80, 0x9d, 0x7c, 0x1c, 0x93, 0xf3,
+	0x33, 0x2e, 0x67, 0x85, 0xcb, 0x6b, 0x19, 0xcc, 0xc6, 0xbd, 0x9a, 0x98, 0x78, 0x83, 0xc4, 0x3a,
+	0x45, 0x62, 0x59, 0xde, 0x7b, 0x6b, 0xe6, 0xaf, 0x04, 0xd7, 0x59, 0x17, 0xdc, 0x6a, 0x4e, 0x02,
+	0x93, 0x93, 0xa8, 0x1c, 0x16, 0x5e, 0x17, 0x71, 0x3d, 0x65, 0xe3, 0xba, 0xf7, 0xcf, 0x2f, 0x67,
+	0x52, 0x3b, 0x18, 0x5c, 0xfd, 0x6c, 0x09, 0xdf, 0xe8, 0xf3, 0x83, 0x3e, 0x5f, 0x7e, 0xb5, 0x4a,
+	0x1f, 0x5e, 0xdc, 0xf8, 0x0f, 0x3e, 0xae, 0xa4, 0x95, 0xce, 0x1f, 0x7f, 0xa4, 0x4c, 0x77, 0x05,
+	0x06, 0x00, 0x00,
 }
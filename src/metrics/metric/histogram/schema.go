@@ -0,0 +1,246 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package histogram provides bucket schemas for native histogram metrics.
+package histogram
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/m3db/m3/src/metrics/generated/proto/metricpb"
+)
+
+var (
+	errNoBounds              = errors.New("fixed bucket schema has no bounds")
+	errBoundsNotIncreasing   = errors.New("fixed bucket schema bounds are not strictly increasing")
+	errNonPositiveStart      = errors.New("exponential bucket schema start must be positive")
+	errInvalidFactor         = errors.New("exponential bucket schema factor must be greater than one")
+	errNonPositiveNumBuckets = errors.New("exponential bucket schema must have at least one bucket")
+)
+
+// SchemaType is the type of a histogram bucket schema.
+type SchemaType int
+
+// A list of supported schema types.
+const (
+	// FixedBuckets are buckets with explicitly configured upper bounds.
+	FixedBuckets SchemaType = iota
+
+	// ExponentialBuckets are buckets whose upper bounds grow by a constant factor,
+	// i.e. the upper bound of the i-th bucket is start * factor^i.
+	ExponentialBuckets
+)
+
+func (t SchemaType) String() string {
+	switch t {
+	case FixedBuckets:
+		return "fixed"
+	case ExponentialBuckets:
+		return "exponential"
+	default:
+		return fmt.Sprintf("unknown schema type: %d", t)
+	}
+}
+
+// Schema describes the buckets of a histogram. Bucket counts are not cumulative,
+// i.e. each bucket counts the values that are greater than the upper bound of the
+// previous bucket and less than or equal to its own upper bound. There is always
+// an extra overflow bucket whose upper bound is positive infinity.
+type Schema struct {
+	Type SchemaType
+
+	// Bounds are the upper bounds of the fixed buckets in ascending order.
+	Bounds []float64
+
+	// Start is the upper bound of the first exponential bucket.
+	Start float64
+
+	// Factor is the growth factor between adjacent exponential buckets.
+	Factor float64
+
+	// NumBuckets is the number of exponential buckets excluding the overflow bucket.
+	NumBuckets int
+}
+
+// NewFixedSchema creates a new schema with fixed bucket upper bounds.
+func NewFixedSchema(bounds []float64) Schema {
+	return Schema{Type: FixedBuckets, Bounds: bounds}
+}
+
+// NewExponentialSchema creates a new schema with exponentially growing buckets.
+func NewExponentialSchema(start, factor float64, numBuckets int) Schema {
+	return Schema{
+		Type:       ExponentialBuckets,
+		Start:      start,
+		Factor:     factor,
+		NumBuckets: numBuckets,
+	}
+}
+
+// Validate validates the schema.
+func (s Schema) Validate() error {
+	switch s.Type {
+	case FixedBuckets:
+		if len(s.Bounds) == 0 {
+			return errNoBounds
+		}
+		for i, b := range s.Bounds {
+			if math.IsNaN(b) || math.IsInf(b, 0) {
+				return fmt.Errorf("fixed bucket schema has invalid bound %v", b)
+			}
+			if i > 0 && b <= s.Bounds[i-1] {
+				return errBoundsNotIncreasing
+			}
+		}
+		return nil
+	case ExponentialBuckets:
+		if !(s.Start > 0) || math.IsInf(s.Start, 0) {
+			return errNonPositiveStart
+		}
+		if !(s.Factor > 1) || math.IsInf(s.Factor, 0) {
+			return errInvalidFactor
+		}
+		if s.NumBuckets <= 0 {
+			return errNonPositiveNumBuckets
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown schema type: %v", s.Type)
+	}
+}
+
+// Len returns the total number of buckets including the overflow bucket.
+func (s Schema) Len() int {
+	if s.Type == ExponentialBuckets {
+		return s.NumBuckets + 1
+	}
+	return len(s.Bounds) + 1
+}
+
+// UpperBound returns the upper bound of the bucket at the given index.
+func (s Schema) UpperBound(idx int) float64 {
+	if idx >= s.Len()-1 {
+		return math.Inf(1)
+	}
+	if s.Type == ExponentialBuckets {
+		return s.Start * math.Pow(s.Factor, float64(idx))
+	}
+	return s.Bounds[idx]
+}
+
+// LowerBound returns the lower bound of the bucket at the given index.
+func (s Schema) LowerBound(idx int) float64 {
+	if idx <= 0 {
+		if s.Type == ExponentialBuckets {
+			return 0
+		}
+		return math.Inf(-1)
+	}
+	return s.UpperBound(idx - 1)
+}
+
+// BucketIndex returns the index of the bucket the value falls into.
+func (s Schema) BucketIndex(value float64) int {
+	n := s.Len() - 1
+	if math.IsNaN(value) {
+		return n
+	}
+	if s.Type != ExponentialBuckets {
+		return sort.SearchFloat64s(s.Bounds, value)
+	}
+	if value <= s.Start {
+		return 0
+	}
+	// NB: the logarithm gives a close estimate of the bucket index which is
+	// then corrected for floating point errors against the actual bounds.
+	idx := int(math.Ceil(math.Log(value/s.Start) / math.Log(s.Factor)))
+	if idx > n {
+		idx = n
+	}
+	for idx > 0 && value <= s.UpperBound(idx-1) {
+		idx--
+	}
+	for idx < n && value > s.UpperBound(idx) {
+		idx++
+	}
+	return idx
+}
+
+// Equal returns true if two schemas describe the same buckets.
+func (s Schema) Equal(other Schema) bool {
+	if s.Type != other.Type {
+		return false
+	}
+	if s.Type == ExponentialBuckets {
+		return s.Start == other.Start &&
+			s.Factor == other.Factor &&
+			s.NumBuckets == other.NumBuckets
+	}
+	if len(s.Bounds) != len(other.Bounds) {
+		return false
+	}
+	for i := range s.Bounds {
+		if s.Bounds[i] != other.Bounds[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// String is the string representation of the schema.
+func (s Schema) String() string {
+	if s.Type == ExponentialBuckets {
+		return fmt.Sprintf("{type:%s,start:%v,factor:%v,numBuckets:%d}", s.Type, s.Start, s.Factor, s.NumBuckets)
+	}
+	return fmt.Sprintf("{type:%s,bounds:%v}", s.Type, s.Bounds)
+}
+
+// ToProto converts the schema to a protobuf message in place.
+func (s Schema) ToProto(pb *metricpb.Histogram) error {
+	switch s.Type {
+	case FixedBuckets:
+		pb.SchemaType = metricpb.HistogramSchemaType_FIXED_BUCKETS
+	case ExponentialBuckets:
+		pb.SchemaType = metricpb.HistogramSchemaType_EXPONENTIAL_BUCKETS
+	default:
+		return fmt.Errorf("unknown schema type: %v", s.Type)
+	}
+	pb.Bounds = s.Bounds
+	pb.Start = s.Start
+	pb.Factor = s.Factor
+	pb.NumBuckets = int32(s.NumBuckets)
+	return nil
+}
+
+// FromProto converts the protobuf message to a schema in place.
+func (s *Schema) FromProto(pb metricpb.Histogram) error {
+	switch pb.SchemaType {
+	case metricpb.HistogramSchemaType_FIXED_BUCKETS:
+		*s = NewFixedSchema(pb.Bounds)
+	case metricpb.HistogramSchemaType_EXPONENTIAL_BUCKETS:
+		*s = NewExponentialSchema(pb.Start, pb.Factor, int(pb.NumBuckets))
+	default:
+		return fmt.Errorf("unknown schema type in proto: %v", pb.SchemaType)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package histogram
+
+import (
+	"math"
+	"testing"
+
+	"github.com/m3db/m3/src/metrics/generated/proto/metricpb"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	inputs := []struct {
+		schema      Schema
+		expectedErr bool
+	}{
+		{schema: NewFixedSchema([]float64{1, 2, 5}), expectedErr: false},
+		{schema: NewFixedSchema(nil), expectedErr: true},
+		{schema: NewFixedSchema([]float64{1, 1}), expectedErr: true},
+		{schema: NewFixedSchema([]float64{1, math.NaN()}), expectedErr: true},
+		{schema: NewExponentialSchema(1, 2, 10), expectedErr: false},
+		{schema: NewExponentialSchema(0, 2, 10), expectedErr: true},
+		{schema: NewExponentialSchema(1, 1, 10), expectedErr: true},
+		{schema: NewExponentialSchema(1, 2, 0), expectedErr: true},
+		{schema: Schema{Type: SchemaType(100)}, expectedErr: true},
+	}
+	for _, input := range inputs {
+		err := input.schema.Validate()
+		if input.expectedErr {
+			require.Error(t, err, input.schema.String())
+		} else {
+			require.NoError(t, err, input.schema.String())
+		}
+	}
+}
+
+func TestFixedSchemaBuckets(t *testing.T) {
+	s := NewFixedSchema([]float64{1, 2, 5})
+	require.Equal(t, 4, s.Len())
+	require.Equal(t, 2.0, s.UpperBound(1))
+	require.True(t, math.IsInf(s.UpperBound(3), 1))
+	require.True(t, math.IsInf(s.LowerBound(0), -1))
+	require.Equal(t, 2.0, s.LowerBound(2))
+
+	inputs := []struct {
+		value    float64
+		expected int
+	}{
+		{value: -10, expected: 0},
+		{value: 1, expected: 0},
+		{value: 1.5, expected: 1},
+		{value: 2, expected: 1},
+		{value: 5, expected: 2},
+		{value: 5.1, expected: 3},
+		{value: math.NaN(), expected: 3},
+	}
+	for _, input := range inputs {
+		require.Equal(t, input.expected, s.BucketIndex(input.value))
+	}
+}
+
+func TestExponentialSchemaBuckets(t *testing.T) {
+	s := NewExponentialSchema(0.1, 10, 3)
+	require.Equal(t, 4, s.Len())
+	require.InDelta(t, 1.0, s.UpperBound(1), 1e-12)
+	require.True(t, math.IsInf(s.UpperBound(3), 1))
+	require.Equal(t, 0.0, s.LowerBound(0))
+
+	for idx := 0; idx < s.Len()-1; idx++ {
+		ub := s.UpperBound(idx)
+		require.Equal(t, idx, s.BucketIndex(ub))
+		require.Equal(t, idx+1, s.BucketIndex(math.Nextafter(ub, math.Inf(1))))
+	}
+	require.Equal(t, 0, s.BucketIndex(-1))
+	require.Equal(t, 3, s.BucketIndex(1e6))
+}
+
+func TestSchemaEqual(t *testing.T) {
+	require.True(t, NewFixedSchema([]float64{1, 2}).Equal(NewFixedSchema([]float64{1, 2})))
+	require.False(t, NewFixedSchema([]float64{1, 2}).Equal(NewFixedSchema([]float64{1, 3})))
+	require.False(t, NewFixedSchema([]float64{1, 2}).Equal(NewExponentialSchema(1, 2, 1)))
+	require.True(t, NewExponentialSchema(1, 2, 5).Equal(NewExponentialSchema(1, 2, 5)))
+	require.False(t, NewExponentialSchema(1, 2, 5).Equal(NewExponentialSchema(1, 2, 6)))
+}
+
+func TestSchemaProtoRoundTrip(t *testing.T) {
+	inputs := []Schema{
+		NewFixedSchema([]float64{1, 2, 5}),
+		NewExponentialSchema(0.5, 2, 8),
+	}
+	for _, input := range inputs {
+		var pb metricpb.Histogram
+		require.NoError(t, input.ToProto(&pb))
+		var res Schema
+		require.NoError(t, res.FromProto(pb))
+		require.Equal(t, input, res)
+	}
+}
+
+func TestSchemaFromProtoBadType(t *testing.T) {
+	var s Schema
+	require.Error(t, s.FromProto(metricpb.Histogram{SchemaType: metricpb.HistogramSchemaType(100)}))
+}
@@ -36,6 +36,7 @@ const (
 	CounterType
 	TimerType
 	GaugeType
+	HistogramType
 )
 
 // validTypes is a list of valid types.
@@ -43,6 +44,7 @@ var validTypes = []Type{
 	CounterType,
 	TimerType,
 	GaugeType,
+	HistogramType,
 }
 
 func (t Type) String() string {
@@ -55,6 +57,8 @@ func (t Type) String() string {
 		return "timer"
 	case GaugeType:
 		return "gauge"
+	case HistogramType:
+		return "histogram"
 	default:
 		return fmt.Sprintf("unknown type: %d", t)
 	}
@@ -71,6 +75,8 @@ func (t Type) ToProto(pb *metricpb.MetricType) error {
 		*pb = metricpb.MetricType_TIMER
 	case GaugeType:
 		*pb = metricpb.MetricType_GAUGE
+	case HistogramType:
+		*pb = metricpb.MetricType_HISTOGRAM
 	default:
 		return fmt.Errorf("unknown metric type: %v", t)
 	}
@@ -88,6 +94,8 @@ func (t *Type) FromProto(pb metricpb.MetricType) error {
 		*t = TimerType
 	case metricpb.MetricType_GAUGE:
 		*t = GaugeType
+	case metricpb.MetricType_HISTOGRAM:
+		*t = HistogramType
 	default:
 		return fmt.Errorf("unknown metric type in proto: %v", pb)
 	}
@@ -27,6 +27,7 @@ import (
 	"github.com/m3db/m3/src/metrics/generated/proto/metricpb"
 	"github.com/m3db/m3/src/metrics/metadata"
 	"github.com/m3db/m3/src/metrics/metric"
+	"github.com/m3db/m3/src/metrics/metric/histogram"
 	"github.com/m3db/m3/src/metrics/metric/id"
 	"github.com/m3db/m3/src/metrics/policy"
 	"github.com/m3db/m3x/pool"
@@ -36,6 +37,7 @@ var (
 	errNilCounterWithMetadatasProto    = errors.New("nil counter with metadatas proto message")
 	errNilBatchTimerWithMetadatasProto = errors.New("nil batch timer with metadatas proto message")
 	errNilGaugeWithMetadatasProto      = errors.New("nil gauge with metadatas proto message")
+	errNilHistogramWithMetadatasProto  = errors.New("nil histogram with metadatas proto message")
 )
 
 // Counter is a counter containing the counter ID and the counter value.
@@ -119,6 +121,48 @@ func (g *Gauge) FromProto(pb metricpb.Gauge) {
 	g.Value = pb.Value
 }
 
+// Histogram is a histogram containing the histogram ID, the bucket schema,
+// the per-bucket value counts and the sum of all values.
+type Histogram struct {
+	ID     id.RawID
+	Schema histogram.Schema
+	Counts []uint64
+	Sum    float64
+}
+
+// ToUnion converts the histogram to a metric union.
+func (h Histogram) ToUnion() MetricUnion {
+	return MetricUnion{
+		Type:            metric.HistogramType,
+		ID:              h.ID,
+		HistogramSchema: h.Schema,
+		HistogramCounts: h.Counts,
+		HistogramSum:    h.Sum,
+	}
+}
+
+// ToProto converts the histogram to a protobuf message in place.
+func (h Histogram) ToProto(pb *metricpb.Histogram) error {
+	if err := h.Schema.ToProto(pb); err != nil {
+		return err
+	}
+	pb.Id = h.ID
+	pb.Counts = h.Counts
+	pb.Sum = h.Sum
+	return nil
+}
+
+// FromProto converts the protobuf message to a histogram in place.
+func (h *Histogram) FromProto(pb metricpb.Histogram) error {
+	if err := h.Schema.FromProto(pb); err != nil {
+		return err
+	}
+	h.ID = pb.Id
+	h.Counts = pb.Counts
+	h.Sum = pb.Sum
+	return nil
+}
+
 // CounterWithPoliciesList is a counter with applicable policies list.
 type CounterWithPoliciesList struct {
 	Counter
@@ -137,6 +181,12 @@ type GaugeWithPoliciesList struct {
 	policy.PoliciesList
 }
 
+// HistogramWithPoliciesList is a histogram with applicable policies list.
+type HistogramWithPoliciesList struct {
+	Histogram
+	policy.PoliciesList
+}
+
 // CounterWithMetadatas is a counter with applicable metadatas.
 type CounterWithMetadatas struct {
 	Counter
@@ -218,18 +268,46 @@ func (gm *GaugeWithMetadatas) FromProto(pb *metricpb.GaugeWithMetadatas) error {
 	return nil
 }
 
+// HistogramWithMetadatas is a histogram with applicable metadatas.
+type HistogramWithMetadatas struct {
+	Histogram
+	metadata.StagedMetadatas
+}
+
+// ToProto converts the histogram with metadatas to a protobuf message in place.
+func (hm HistogramWithMetadatas) ToProto(pb *metricpb.HistogramWithMetadatas) error {
+	if err := hm.StagedMetadatas.ToProto(&pb.Metadatas); err != nil {
+		return err
+	}
+	return hm.Histogram.ToProto(&pb.Histogram)
+}
+
+// FromProto converts the protobuf message to a histogram with metadatas in place.
+func (hm *HistogramWithMetadatas) FromProto(pb *metricpb.HistogramWithMetadatas) error {
+	if pb == nil {
+		return errNilHistogramWithMetadatasProto
+	}
+	if err := hm.StagedMetadatas.FromProto(pb.Metadatas); err != nil {
+		return err
+	}
+	return hm.Histogram.FromProto(pb.Histogram)
+}
+
 // MetricUnion is a union of different types of metrics, only one of which is valid
 // at any given time. The actual type of the metric depends on the type field,
 // which determines which value field is valid. Note that if the timer values are
 // allocated from a pool, the TimerValPool should be set to the originating pool,
 // and the caller is responsible for returning the timer values to the pool.
 type MetricUnion struct {
-	Type          metric.Type
-	ID            id.RawID
-	CounterVal    int64
-	BatchTimerVal []float64
-	GaugeVal      float64
-	TimerValPool  pool.FloatsPool
+	Type            metric.Type
+	ID              id.RawID
+	CounterVal      int64
+	BatchTimerVal   []float64
+	GaugeVal        float64
+	TimerValPool    pool.FloatsPool
+	HistogramSchema histogram.Schema
+	HistogramCounts []uint64
+	HistogramSum    float64
 }
 
 var emptyMetricUnion MetricUnion
@@ -243,6 +321,11 @@ func (m *MetricUnion) String() string {
 		return fmt.Sprintf("{type:%s,id:%s,value:%v}", m.Type, m.ID.String(), m.BatchTimerVal)
 	case metric.GaugeType:
 		return fmt.Sprintf("{type:%s,id:%s,value:%f}", m.Type, m.ID.String(), m.GaugeVal)
+	case metric.HistogramType:
+		return fmt.Sprintf(
+			"{type:%s,id:%s,schema:%s,counts:%v,sum:%f}",
+			m.Type, m.ID.String(), m.HistogramSchema.String(), m.HistogramCounts, m.HistogramSum,
+		)
 	default:
 		return fmt.Sprintf(
 			"{type:%d,id:%s,counterVal:%d,batchTimerVal:%v,gaugeVal:%f}",
@@ -262,3 +345,13 @@ func (m *MetricUnion) BatchTimer() BatchTimer { return BatchTimer{ID: m.ID, Valu
 
 // Gauge returns the gauge metric.
 func (m *MetricUnion) Gauge() Gauge { return Gauge{ID: m.ID, Value: m.GaugeVal} }
+
+// Histogram returns the histogram metric.
+func (m *MetricUnion) Histogram() Histogram {
+	return Histogram{
+		ID:     m.ID,
+		Schema: m.HistogramSchema,
+		Counts: m.HistogramCounts,
+		Sum:    m.HistogramSum,
+	}
+}
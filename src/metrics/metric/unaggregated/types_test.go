@@ -32,6 +32,7 @@ import (
 	"github.com/m3db/m3/src/metrics/generated/proto/transformationpb"
 	"github.com/m3db/m3/src/metrics/metadata"
 	"github.com/m3db/m3/src/metrics/metric"
+	"github.com/m3db/m3/src/metrics/metric/histogram"
 	"github.com/m3db/m3/src/metrics/pipeline"
 	"github.com/m3db/m3/src/metrics/pipeline/applied"
 	"github.com/m3db/m3/src/metrics/policy"
@@ -69,6 +70,19 @@ var (
 		ID:       []byte("testGauge"),
 		GaugeVal: 45.28,
 	}
+	testHistogram = Histogram{
+		ID:     []byte("testHistogram"),
+		Schema: histogram.NewExponentialSchema(0.5, 2, 4),
+		Counts: []uint64{3, 0, 12, 1, 7},
+		Sum:    123.45,
+	}
+	testHistogramUnion = MetricUnion{
+		Type:            metric.HistogramType,
+		ID:              []byte("testHistogram"),
+		HistogramSchema: histogram.NewExponentialSchema(0.5, 2, 4),
+		HistogramCounts: []uint64{3, 0, 12, 1, 7},
+		HistogramSum:    123.45,
+	}
 	testMetadatas = metadata.StagedMetadatas{
 		{
 			CutoverNanos: 1234,
@@ -163,6 +177,10 @@ var (
 		Gauge:           testGauge,
 		StagedMetadatas: testMetadatas,
 	}
+	testHistogramWithMetadatas = HistogramWithMetadatas{
+		Histogram:       testHistogram,
+		StagedMetadatas: testMetadatas,
+	}
 	testCounterProto = metricpb.Counter{
 		Id:    []byte("testCounter"),
 		Value: 1234,
@@ -175,6 +193,15 @@ var (
 		Id:    []byte("testGauge"),
 		Value: 45.28,
 	}
+	testHistogramProto = metricpb.Histogram{
+		Id:         []byte("testHistogram"),
+		SchemaType: metricpb.HistogramSchemaType_EXPONENTIAL_BUCKETS,
+		Start:      0.5,
+		Factor:     2,
+		NumBuckets: 4,
+		Counts:     []uint64{3, 0, 12, 1, 7},
+		Sum:        123.45,
+	}
 	testMetadatasProto = metricpb.StagedMetadatas{
 		Metadatas: []metricpb.StagedMetadata{
 			{
@@ -300,6 +327,10 @@ var (
 		Gauge:     testGaugeProto,
 		Metadatas: testMetadatasProto,
 	}
+	testHistogramWithMetadatasProto = metricpb.HistogramWithMetadatas{
+		Histogram: testHistogramProto,
+		Metadatas: testMetadatasProto,
+	}
 )
 
 func TestCounterToUnion(t *testing.T) {
@@ -380,6 +411,38 @@ func TestGaugeRoundTrip(t *testing.T) {
 	require.Equal(t, testGauge, c)
 }
 
+func TestHistogramToUnion(t *testing.T) {
+	require.Equal(t, testHistogramUnion, testHistogram.ToUnion())
+}
+
+func TestHistogramToProto(t *testing.T) {
+	var pb metricpb.Histogram
+	require.NoError(t, testHistogram.ToProto(&pb))
+	require.Equal(t, testHistogramProto, pb)
+}
+
+func TestHistogramToProtoBadSchema(t *testing.T) {
+	var pb metricpb.Histogram
+	h := Histogram{ID: []byte("foo"), Schema: histogram.Schema{Type: histogram.SchemaType(100)}}
+	require.Error(t, h.ToProto(&pb))
+}
+
+func TestHistogramFromProto(t *testing.T) {
+	var h Histogram
+	require.NoError(t, h.FromProto(testHistogramProto))
+	require.Equal(t, testHistogram, h)
+}
+
+func TestHistogramRoundTrip(t *testing.T) {
+	var (
+		pb metricpb.Histogram
+		h  Histogram
+	)
+	require.NoError(t, testHistogram.ToProto(&pb))
+	require.NoError(t, h.FromProto(pb))
+	require.Equal(t, testHistogram, h)
+}
+
 func TestCounterWithMetadatasToProto(t *testing.T) {
 	var pb metricpb.CounterWithMetadatas
 	require.NoError(t, testCounterWithMetadatas.ToProto(&pb))
@@ -514,3 +577,43 @@ func TestGaugeWithMetadatasRoundTrip(t *testing.T) {
 	require.NoError(t, g.FromProto(&pb))
 	require.Equal(t, testGaugeWithMetadatas, g)
 }
+
+func TestHistogramWithMetadatasToProto(t *testing.T) {
+	var pb metricpb.HistogramWithMetadatas
+	require.NoError(t, testHistogramWithMetadatas.ToProto(&pb))
+	require.Equal(t, testHistogramWithMetadatasProto, pb)
+}
+
+func TestHistogramWithMetadatasFromProto(t *testing.T) {
+	var h HistogramWithMetadatas
+	require.NoError(t, h.FromProto(&testHistogramWithMetadatasProto))
+	require.Equal(t, testHistogramWithMetadatas, h)
+}
+
+func TestHistogramWithMetadatasFromProtoNilProto(t *testing.T) {
+	var h HistogramWithMetadatas
+	require.Equal(t, errNilHistogramWithMetadatasProto, h.FromProto(nil))
+}
+
+func TestHistogramWithMetadatasFromProtoBadProto(t *testing.T) {
+	var h HistogramWithMetadatas
+	badHistogramWithMetadatasProto := metricpb.HistogramWithMetadatas{
+		Histogram: testHistogramProto,
+		Metadatas: testBadMetadatasProto,
+	}
+	require.Error(t, h.FromProto(&badHistogramWithMetadatasProto))
+}
+
+func TestHistogramWithMetadatasRoundTrip(t *testing.T) {
+	var (
+		pb metricpb.HistogramWithMetadatas
+		h  HistogramWithMetadatas
+	)
+	require.NoError(t, testHistogramWithMetadatas.ToProto(&pb))
+	require.NoError(t, h.FromProto(&pb))
+	require.Equal(t, testHistogramWithMetadatas, h)
+}
+
+func TestMetricUnionHistogram(t *testing.T) {
+	require.Equal(t, testHistogram, testHistogramUnion.Histogram())
+}
@@ -43,21 +43,38 @@ type RollupOp struct {
 	ID []byte
 	// Type of aggregations performed within each unique dimension combination.
 	AggregationID aggregation.ID
+	// Value of the distinct count tag of the metric the operation is applied against.
+	DistinctTagValue []byte
 }
 
 // Equal determines whether two rollup operations are equal.
 func (op RollupOp) Equal(other RollupOp) bool {
-	return op.AggregationID == other.AggregationID && bytes.Equal(op.ID, other.ID)
+	return op.AggregationID == other.AggregationID &&
+		bytes.Equal(op.ID, other.ID) &&
+		bytes.Equal(op.DistinctTagValue, other.DistinctTagValue)
 }
 
 // Clone clones the rollup operation.
 func (op RollupOp) Clone() RollupOp {
 	idClone := make([]byte, len(op.ID))
 	copy(idClone, op.ID)
-	return RollupOp{ID: idClone, AggregationID: op.AggregationID}
+	var distinctTagValueClone []byte
+	if op.DistinctTagValue != nil {
+		distinctTagValueClone = make([]byte, len(op.DistinctTagValue))
+		copy(distinctTagValueClone, op.DistinctTagValue)
+	}
+	return RollupOp{
+		ID:               idClone,
+		AggregationID:    op.AggregationID,
+		DistinctTagValue: distinctTagValueClone,
+	}
 }
 
 func (op RollupOp) String() string {
+	if len(op.DistinctTagValue) > 0 {
+		return fmt.Sprintf("{id: %s, aggregation: %v, distinctTagValue: %s}",
+			op.ID, op.AggregationID, op.DistinctTagValue)
+	}
 	return fmt.Sprintf("{id: %s, aggregation: %v}", op.ID, op.AggregationID)
 }
 
@@ -67,6 +84,7 @@ func (op RollupOp) ToProto(pb *pipelinepb.AppliedRollupOp) error {
 		return err
 	}
 	pb.Id = op.ID
+	pb.DistinctTagValue = op.DistinctTagValue
 	return nil
 }
 
@@ -79,6 +97,7 @@ func (op *RollupOp) FromProto(pb *pipelinepb.AppliedRollupOp) error {
 		return err
 	}
 	op.ID = pb.Id
+	op.DistinctTagValue = pb.DistinctTagValue
 	return nil
 }
 
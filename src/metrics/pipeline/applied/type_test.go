@@ -630,3 +630,23 @@ func TestPipelineRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestRollupOpDistinctTagValueRoundTrip(t *testing.T) {
+	op := RollupOp{
+		ID:               []byte("foo"),
+		AggregationID:    aggregation.MustCompressTypes(aggregation.DistinctCount),
+		DistinctTagValue: []byte("bar"),
+	}
+	var pb pipelinepb.AppliedRollupOp
+	require.NoError(t, op.ToProto(&pb))
+	require.Equal(t, []byte("bar"), pb.DistinctTagValue)
+
+	var res RollupOp
+	require.NoError(t, res.FromProto(&pb))
+	require.True(t, op.Equal(res))
+
+	// Rollup operations with different distinct tag values are not equal.
+	other := op.Clone()
+	other.DistinctTagValue = []byte("baz")
+	require.False(t, op.Equal(other))
+}
@@ -26,6 +26,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/m3db/m3/src/metrics/aggregation"
 	"github.com/m3db/m3/src/metrics/generated/proto/pipelinepb"
@@ -124,6 +125,10 @@ func (op *AggregationOp) UnmarshalYAML(unmarshal func(interface{}) error) error
 type TransformationOp struct {
 	// Type of transformation performed.
 	Type transformation.Type
+	// Constant the transformation is parameterized by if it is a constant transformation.
+	Constant float64
+	// Window the transformation is computed over if it is a window transformation.
+	Window time.Duration
 }
 
 // NewTransformationOpFromProto creates a new transformation op from proto.
@@ -137,7 +142,7 @@ func NewTransformationOpFromProto(pb *pipelinepb.TransformationOp) (Transformati
 
 // Equal determines whether two transformation operations are equal.
 func (op TransformationOp) Equal(other TransformationOp) bool {
-	return op.Type == other.Type
+	return op.Type == other.Type && op.Constant == other.Constant && op.Window == other.Window
 }
 
 // Clone clones the transformation operation.
@@ -155,12 +160,23 @@ func (op TransformationOp) Proto() (*pipelinepb.TransformationOp, error) {
 }
 
 func (op TransformationOp) String() string {
+	if op.Type.IsConstantTransform() {
+		return fmt.Sprintf("%s(%v)", op.Type.String(), op.Constant)
+	}
+	if op.Type.IsWindowTransform() {
+		return fmt.Sprintf("%s(%v)", op.Type.String(), op.Window)
+	}
 	return op.Type.String()
 }
 
 // ToProto converts the transformation op to a protobuf message in place.
 func (op TransformationOp) ToProto(pb *pipelinepb.TransformationOp) error {
-	return op.Type.ToProto(&pb.Type)
+	if err := op.Type.ToProto(&pb.Type); err != nil {
+		return err
+	}
+	pb.Constant = op.Constant
+	pb.WindowNanos = op.Window.Nanoseconds()
+	return nil
 }
 
 // FromProto converts the protobuf message to a transformation in place.
@@ -168,34 +184,79 @@ func (op *TransformationOp) FromProto(pb *pipelinepb.TransformationOp) error {
 	if pb == nil {
 		return errNilTransformationOpProto
 	}
-	return op.Type.FromProto(pb.Type)
+	if err := op.Type.FromProto(pb.Type); err != nil {
+		return err
+	}
+	op.Constant = pb.Constant
+	op.Window = time.Duration(pb.WindowNanos)
+	return nil
 }
 
 // MarshalJSON returns the JSON encoding of a transformation operation.
+// Transformations parameterized by a constant or computed over a window
+// are encoded as objects and the others are encoded as their type names.
 func (op TransformationOp) MarshalJSON() ([]byte, error) {
+	if op.Type.IsConstantTransform() || op.Type.IsWindowTransform() {
+		return json.Marshal(newTransformationMarshaler(op))
+	}
 	return json.Marshal(op.Type)
 }
 
 // UnmarshalJSON unmarshals JSON-encoded data into a transformation operation.
 func (op *TransformationOp) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var converted transformationMarshaler
+		if err := json.Unmarshal(data, &converted); err != nil {
+			return err
+		}
+		*op = converted.TransformationOp()
+		return nil
+	}
 	var t transformation.Type
 	if err := json.Unmarshal(data, &t); err != nil {
 		return err
 	}
-	op.Type = t
+	*op = TransformationOp{Type: t}
 	return nil
 }
 
 // UnmarshalYAML unmarshals YAML-encoded data into a transformation operation.
 func (op *TransformationOp) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	var t transformation.Type
-	if err := unmarshal(&t); err != nil {
+	if err := unmarshal(&t); err == nil {
+		*op = TransformationOp{Type: t}
+		return nil
+	}
+	var converted transformationMarshaler
+	if err := unmarshal(&converted); err != nil {
 		return err
 	}
-	op.Type = t
+	*op = converted.TransformationOp()
 	return nil
 }
 
+type transformationMarshaler struct {
+	Type     transformation.Type `json:"type" yaml:"type"`
+	Constant float64             `json:"constant,omitempty" yaml:"constant"`
+	Window   time.Duration       `json:"window,omitempty" yaml:"window"`
+}
+
+func newTransformationMarshaler(op TransformationOp) transformationMarshaler {
+	return transformationMarshaler{
+		Type:     op.Type,
+		Constant: op.Constant,
+		Window:   op.Window,
+	}
+}
+
+func (m transformationMarshaler) TransformationOp() TransformationOp {
+	return TransformationOp{
+		Type:     m.Type,
+		Constant: m.Constant,
+		Window:   m.Window,
+	}
+}
+
 // RollupOp is a rollup operation.
 type RollupOp struct {
 	// New metric name generated as a result of the rollup.
@@ -204,6 +265,8 @@ type RollupOp struct {
 	Tags [][]byte
 	// Types of aggregation performed within each unique dimension combination.
 	AggregationID aggregation.ID
+	// Tag whose distinct values are counted by the distinct count aggregation.
+	DistinctTag []byte
 }
 
 // NewRollupOpFromProto creates a new rollup op from proto.
@@ -220,11 +283,15 @@ func NewRollupOpFromProto(pb *pipelinepb.RollupOp) (RollupOp, error) {
 	tags := make([]string, len(pb.Tags))
 	copy(tags, pb.Tags)
 	sort.Strings(tags)
-	return RollupOp{
+	rollup = RollupOp{
 		NewName:       []byte(pb.NewName),
 		Tags:          xbytes.ArraysFromStringArray(tags),
 		AggregationID: aggregationID,
-	}, nil
+	}
+	if pb.DistinctTag != "" {
+		rollup.DistinctTag = []byte(pb.DistinctTag)
+	}
+	return rollup, nil
 }
 
 // SameTransform returns true if the two rollup operations have the same rollup transformation
@@ -254,6 +321,9 @@ func (op RollupOp) Equal(other RollupOp) bool {
 	if !op.AggregationID.Equal(other.AggregationID) {
 		return false
 	}
+	if !bytes.Equal(op.DistinctTag, other.DistinctTag) {
+		return false
+	}
 	return op.SameTransform(other)
 }
 
@@ -261,10 +331,16 @@ func (op RollupOp) Equal(other RollupOp) bool {
 func (op RollupOp) Clone() RollupOp {
 	newName := make([]byte, len(op.NewName))
 	copy(newName, op.NewName)
+	var distinctTag []byte
+	if op.DistinctTag != nil {
+		distinctTag = make([]byte, len(op.DistinctTag))
+		copy(distinctTag, op.DistinctTag)
+	}
 	return RollupOp{
 		NewName:       newName,
 		Tags:          xbytes.ArrayCopy(op.Tags),
 		AggregationID: op.AggregationID,
+		DistinctTag:   distinctTag,
 	}
 }
 
@@ -282,6 +358,7 @@ func (op RollupOp) Proto() (*pipelinepb.RollupOp, error) {
 		NewName:          string(op.NewName),
 		Tags:             xbytes.ArraysToStringArray(op.Tags),
 		AggregationTypes: pbAggTypes,
+		DistinctTag:      string(op.DistinctTag),
 	}, nil
 }
 
@@ -298,6 +375,9 @@ func (op RollupOp) String() string {
 	}
 	b.WriteString("], ")
 	fmt.Fprintf(&b, "aggregation: %v", op.AggregationID)
+	if len(op.DistinctTag) > 0 {
+		fmt.Fprintf(&b, ", distinctTag: %s", op.DistinctTag)
+	}
 	b.WriteString("}")
 	return b.String()
 }
@@ -331,6 +411,7 @@ type rollupMarshaler struct {
 	NewName       string         `json:"newName" yaml:"newName"`
 	Tags          []string       `json:"tags" yaml:"tags"`
 	AggregationID aggregation.ID `json:"aggregation,omitempty" yaml:"aggregation"`
+	DistinctTag   string         `json:"distinctTag,omitempty" yaml:"distinctTag,omitempty"`
 }
 
 func newRollupMarshaler(op RollupOp) rollupMarshaler {
@@ -338,15 +419,20 @@ func newRollupMarshaler(op RollupOp) rollupMarshaler {
 		NewName:       string(op.NewName),
 		Tags:          xbytes.ArraysToStringArray(op.Tags),
 		AggregationID: op.AggregationID,
+		DistinctTag:   string(op.DistinctTag),
 	}
 }
 
 func (m rollupMarshaler) RollupOp() RollupOp {
-	return RollupOp{
+	op := RollupOp{
 		NewName:       []byte(m.NewName),
 		Tags:          xbytes.ArraysFromStringArray(m.Tags),
 		AggregationID: m.AggregationID,
 	}
+	if m.DistinctTag != "" {
+		op.DistinctTag = []byte(m.DistinctTag)
+	}
+	return op
 }
 
 // OpUnion is a union of different types of operation.
@@ -23,6 +23,7 @@ package pipeline
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/m3db/m3/src/metrics/aggregation"
 	"github.com/m3db/m3/src/metrics/generated/proto/pipelinepb"
@@ -77,13 +78,33 @@ func TestTransformationOpEqual(t *testing.T) {
 		expected bool
 	}{
 		{
-			a1:       TransformationOp{transformation.Absolute},
-			a2:       TransformationOp{transformation.Absolute},
+			a1:       TransformationOp{Type: transformation.Absolute},
+			a2:       TransformationOp{Type: transformation.Absolute},
 			expected: true,
 		},
 		{
-			a1:       TransformationOp{transformation.Absolute},
-			a2:       TransformationOp{transformation.PerSecond},
+			a1:       TransformationOp{Type: transformation.Absolute},
+			a2:       TransformationOp{Type: transformation.PerSecond},
+			expected: false,
+		},
+		{
+			a1:       TransformationOp{Type: transformation.Add, Constant: 2},
+			a2:       TransformationOp{Type: transformation.Add, Constant: 2},
+			expected: true,
+		},
+		{
+			a1:       TransformationOp{Type: transformation.Add, Constant: 2},
+			a2:       TransformationOp{Type: transformation.Add, Constant: 3},
+			expected: false,
+		},
+		{
+			a1:       TransformationOp{Type: transformation.Rate, Window: time.Minute},
+			a2:       TransformationOp{Type: transformation.Rate, Window: time.Minute},
+			expected: true,
+		},
+		{
+			a1:       TransformationOp{Type: transformation.Rate, Window: time.Minute},
+			a2:       TransformationOp{Type: transformation.Rate, Window: 5 * time.Minute},
 			expected: false,
 		},
 	}
@@ -95,7 +116,7 @@ func TestTransformationOpEqual(t *testing.T) {
 }
 
 func TestTransformationOpClone(t *testing.T) {
-	source := TransformationOp{transformation.Absolute}
+	source := TransformationOp{Type: transformation.Absolute}
 	clone := source.Clone()
 	require.Equal(t, source, clone)
 	clone.Type = transformation.PerSecond
@@ -130,6 +151,25 @@ func TestPipelineString(t *testing.T) {
 			},
 			expected: "{operations: [{aggregation: Last}, {transformation: PerSecond}, {rollup: {name: foo, tags: [tag1, tag2], aggregation: Sum}}]}",
 		},
+		{
+			p: Pipeline{
+				operations: []OpUnion{
+					{
+						Type:           TransformationOpType,
+						Transformation: TransformationOp{Type: transformation.Increase},
+					},
+					{
+						Type:           TransformationOpType,
+						Transformation: TransformationOp{Type: transformation.Multiply, Constant: 2.5},
+					},
+					{
+						Type:           TransformationOpType,
+						Transformation: TransformationOp{Type: transformation.Rate, Window: time.Minute},
+					},
+				},
+			},
+			expected: "{operations: [{transformation: Increase}, {transformation: Multiply(2.5)}, {transformation: Rate(1m0s)}]}",
+		},
 		{
 			p: Pipeline{
 				operations: []OpUnion{
@@ -179,6 +219,36 @@ func TestTransformationOpRoundTrip(t *testing.T) {
 	require.Equal(t, testTransformationOp, res)
 }
 
+func TestTransformationOpWithConstantRoundTrip(t *testing.T) {
+	var (
+		op  = TransformationOp{Type: transformation.Add, Constant: -1.5}
+		pb  pipelinepb.TransformationOp
+		res TransformationOp
+	)
+	require.NoError(t, op.ToProto(&pb))
+	require.Equal(t, pipelinepb.TransformationOp{
+		Type:     transformationpb.TransformationType_ADD,
+		Constant: -1.5,
+	}, pb)
+	require.NoError(t, res.FromProto(&pb))
+	require.Equal(t, op, res)
+}
+
+func TestTransformationOpWithWindowRoundTrip(t *testing.T) {
+	var (
+		op  = TransformationOp{Type: transformation.Rate, Window: time.Minute}
+		pb  pipelinepb.TransformationOp
+		res TransformationOp
+	)
+	require.NoError(t, op.ToProto(&pb))
+	require.Equal(t, pipelinepb.TransformationOp{
+		Type:        transformationpb.TransformationType_RATE,
+		WindowNanos: time.Minute.Nanoseconds(),
+	}, pb)
+	require.NoError(t, res.FromProto(&pb))
+	require.Equal(t, op, res)
+}
+
 func TestRollupOpSameTransform(t *testing.T) {
 	rollupOp := RollupOp{
 		NewName: b("foo"),
@@ -241,6 +311,20 @@ func TestOpUnionMarshalJSON(t *testing.T) {
 			},
 			expected: `{"transformation":"PerSecond"}`,
 		},
+		{
+			op: OpUnion{
+				Type:           TransformationOpType,
+				Transformation: TransformationOp{Type: transformation.Multiply, Constant: 60},
+			},
+			expected: `{"transformation":{"type":"Multiply","constant":60}}`,
+		},
+		{
+			op: OpUnion{
+				Type:           TransformationOpType,
+				Transformation: TransformationOp{Type: transformation.Rate, Window: time.Minute},
+			},
+			expected: `{"transformation":{"type":"Rate","window":60000000000}}`,
+		},
 		{
 			op: OpUnion{
 				Type: RollupOpType,
@@ -288,6 +372,14 @@ func TestOpUnionMarshalJSONRoundtrip(t *testing.T) {
 			Type:           TransformationOpType,
 			Transformation: TransformationOp{Type: transformation.PerSecond},
 		},
+		{
+			Type:           TransformationOpType,
+			Transformation: TransformationOp{Type: transformation.Add, Constant: 1.5},
+		},
+		{
+			Type:           TransformationOpType,
+			Transformation: TransformationOp{Type: transformation.Rate, Window: time.Minute},
+		},
 		{
 			Type: RollupOpType,
 			Rollup: RollupOp{
@@ -304,6 +396,15 @@ func TestOpUnionMarshalJSONRoundtrip(t *testing.T) {
 				AggregationID: aggregation.DefaultID,
 			},
 		},
+		{
+			Type: RollupOpType,
+			Rollup: RollupOp{
+				NewName:       b("testRollup"),
+				Tags:          bs("tag1", "tag2"),
+				AggregationID: aggregation.MustCompressTypes(aggregation.DistinctCount),
+				DistinctTag:   b("tag3"),
+			},
+		},
 	}
 
 	for _, op := range ops {
@@ -390,6 +491,12 @@ func TestPipelineUnmarshalYAML(t *testing.T) {
 	input := `
 - aggregation: Sum
 - transformation: PerSecond
+- transformation:
+    type: Multiply
+    constant: 60
+- transformation:
+    type: Rate
+    window: 1m
 - rollup:
     newName: testRollup
     tags:
@@ -417,6 +524,14 @@ func TestPipelineUnmarshalYAML(t *testing.T) {
 			Type:           TransformationOpType,
 			Transformation: TransformationOp{Type: transformation.PerSecond},
 		},
+		{
+			Type:           TransformationOpType,
+			Transformation: TransformationOp{Type: transformation.Multiply, Constant: 60},
+		},
+		{
+			Type:           TransformationOpType,
+			Transformation: TransformationOp{Type: transformation.Rate, Window: time.Minute},
+		},
 		{
 			Type: RollupOpType,
 			Rollup: RollupOp{
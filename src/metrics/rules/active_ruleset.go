@@ -440,6 +440,21 @@ func (as *activeRuleSet) matchRollupTarget(
 	return as.newRollupIDFn(newName, tagPairs), true
 }
 
+// tagValue returns the value of the given tag in the sorted tag pairs, and
+// whether the tag was found.
+func (as *activeRuleSet) tagValue(sortedTagPairBytes []byte, tagName []byte) ([]byte, bool) {
+	sortedTagIter := as.tagsFilterOpts.SortedTagIteratorFn(sortedTagPairBytes)
+	defer sortedTagIter.Close()
+
+	for sortedTagIter.Next() {
+		name, value := sortedTagIter.Current()
+		if bytes.Equal(name, tagName) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
 func (as *activeRuleSet) applyIDToPipeline(
 	sortedTagPairBytes []byte,
 	pipeline mpipeline.Pipeline,
@@ -469,9 +484,22 @@ func (as *activeRuleSet) applyIDToPipeline(
 				err := fmt.Errorf("existing tag pairs %s do not contain all rollup tags %s", sortedTagPairBytes, rollupOp.Tags)
 				return applied.Pipeline{}, err
 			}
+			var distinctTagValue []byte
+			if len(rollupOp.DistinctTag) > 0 {
+				value, found := as.tagValue(sortedTagPairBytes, rollupOp.DistinctTag)
+				if !found {
+					err := fmt.Errorf("existing tag pairs %s do not contain distinct tag %s", sortedTagPairBytes, rollupOp.DistinctTag)
+					return applied.Pipeline{}, err
+				}
+				distinctTagValue = append([]byte(nil), value...)
+			}
 			opUnion = applied.OpUnion{
-				Type:   mpipeline.RollupOpType,
-				Rollup: applied.RollupOp{ID: rollupID, AggregationID: rollupOp.AggregationID},
+				Type: mpipeline.RollupOpType,
+				Rollup: applied.RollupOp{
+					ID:               rollupID,
+					AggregationID:    rollupOp.AggregationID,
+					DistinctTagValue: distinctTagValue,
+				},
 			}
 		default:
 			return applied.Pipeline{}, fmt.Errorf("unexpected pipeline op type: %v", pipelineOp.Type)
@@ -557,6 +557,46 @@ func TestActiveRuleSetForwardMatchWithMappingRules(t *testing.T) {
 	}
 }
 
+func TestActiveRuleSetApplyIDToPipelineWithDistinctTag(t *testing.T) {
+	as := newActiveRuleSet(
+		0,
+		nil,
+		nil,
+		testTagsFilterOptions(),
+		mockNewID,
+		nil,
+	)
+	distinctCountPipeline := pipeline.NewPipeline([]pipeline.OpUnion{
+		{
+			Type: pipeline.RollupOpType,
+			Rollup: pipeline.RollupOp{
+				NewName:       b("rName1"),
+				Tags:          bs("rtagName1"),
+				AggregationID: aggregation.MustCompressTypes(aggregation.DistinctCount),
+				DistinctTag:   b("rtagName2"),
+			},
+		},
+	})
+
+	res, err := as.applyIDToPipeline(b("rtagName1=rtagValue1,rtagName2=rtagValue2"), distinctCountPipeline, nil)
+	require.NoError(t, err)
+	expected := applied.NewPipeline([]applied.OpUnion{
+		{
+			Type: pipeline.RollupOpType,
+			Rollup: applied.RollupOp{
+				ID:               b("rName1|rtagName1=rtagValue1"),
+				AggregationID:    aggregation.MustCompressTypes(aggregation.DistinctCount),
+				DistinctTagValue: b("rtagValue2"),
+			},
+		},
+	})
+	require.True(t, expected.Equal(res))
+
+	// Metrics without the distinct tag can not be applied to the pipeline.
+	_, err = as.applyIDToPipeline(b("rtagName1=rtagValue1,rtagName3=rtagValue3"), distinctCountPipeline, nil)
+	require.Error(t, err)
+}
+
 func TestActiveRuleSetForwardMatchWithRollupRules(t *testing.T) {
 	inputs := []testMatchInput{
 		{
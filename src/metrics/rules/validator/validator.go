@@ -23,6 +23,7 @@ package validator
 import (
 	"errors"
 	"fmt"
+	"math"
 
 	"github.com/m3db/m3/src/metrics/aggregation"
 	merrors "github.com/m3db/m3/src/metrics/errors"
@@ -42,6 +43,9 @@ var (
 	errMoreThanOneAggregationOpInPipeline = errors.New("more than one aggregation operation in pipeline")
 	errAggregationOpNotFirstInPipeline    = errors.New("aggregation operation is not the first operation in pipeline")
 	errNoRollupOpInPipeline               = errors.New("no rollup operation in pipeline")
+	errNoDistinctTag                      = errors.New("no distinct tag for distinct count aggregation")
+	errDistinctTagWithoutDistinctCount    = errors.New("distinct tag without distinct count aggregation")
+	errDistinctCountRollupFirstInPipeline = errors.New("distinct count rollup operation is the first operation in pipeline")
 )
 
 type validator struct {
@@ -309,7 +313,7 @@ func (v *validator) validatePipeline(pipeline mpipeline.Pipeline, types []metric
 			}
 		case mpipeline.TransformationOpType:
 			transformOp := pipelineOp.Transformation
-			if transformOp.Type.IsBinaryTransform() {
+			if transformOp.Type.IsBinaryTransform() || transformOp.Type.IsWindowTransform() {
 				transformationDerivativeOrder++
 				if transformationDerivativeOrder > v.opts.MaxTransformationDerivativeOrder() {
 					return fmt.Errorf("transformation derivative order is %d higher than supported %d", transformationDerivativeOrder, v.opts.MaxTransformationDerivativeOrder())
@@ -359,6 +363,22 @@ func validateTransformationOp(transformationOp mpipeline.TransformationOp) error
 	if !transformationOp.Type.IsValid() {
 		return fmt.Errorf("invalid transformation type: %v", transformationOp.Type)
 	}
+	if transformationOp.Type.IsWindowTransform() {
+		if transformationOp.Window <= 0 {
+			return fmt.Errorf("invalid window %v for transformation type %v", transformationOp.Window, transformationOp.Type)
+		}
+	} else if transformationOp.Window != 0 {
+		return fmt.Errorf("transformation type %v does not take a window", transformationOp.Type)
+	}
+	if !transformationOp.Type.IsConstantTransform() {
+		if transformationOp.Constant != 0 {
+			return fmt.Errorf("transformation type %v does not take a constant", transformationOp.Type)
+		}
+		return nil
+	}
+	if math.IsNaN(transformationOp.Constant) || math.IsInf(transformationOp.Constant, 0) {
+		return fmt.Errorf("invalid constant %v for transformation type %v", transformationOp.Constant, transformationOp.Type)
+	}
 	return nil
 }
 
@@ -387,6 +407,46 @@ func (v *validator) validateRollupOp(
 		return fmt.Errorf("invalid aggregation ID %v: %v", rollupOp.AggregationID, err)
 	}
 
+	// Validate that the distinct tag is valid.
+	if err := v.validateDistinctTag(rollupOp, opIdxInPipeline, previousRollupTags); err != nil {
+		return fmt.Errorf("invalid distinct tag '%s': %v", rollupOp.DistinctTag, err)
+	}
+
+	return nil
+}
+
+func (v *validator) validateDistinctTag(
+	rollupOp mpipeline.RollupOp,
+	opIdxInPipeline int,
+	previousRollupTags map[string]struct{},
+) error {
+	hasDistinctCount := rollupOp.AggregationID.Contains(aggregation.DistinctCount)
+	if !hasDistinctCount {
+		if len(rollupOp.DistinctTag) > 0 {
+			return errDistinctTagWithoutDistinctCount
+		}
+		return nil
+	}
+	if len(rollupOp.DistinctTag) == 0 {
+		return errNoDistinctTag
+	}
+
+	// NB: The first operation in a pipeline is applied against the metric
+	// before it reaches the aggregator, at which point the distinct tag
+	// values of the individual metrics can no longer be told apart.
+	if opIdxInPipeline == 0 {
+		return errDistinctCountRollupFirstInPipeline
+	}
+	if err := v.opts.CheckInvalidCharactersForTagName(string(rollupOp.DistinctTag)); err != nil {
+		return err
+	}
+
+	// NB: `previousRollupTags` is nil for the first rollup operation.
+	if previousRollupTags != nil {
+		if _, exists := previousRollupTags[string(rollupOp.DistinctTag)]; !exists {
+			return fmt.Errorf("tag %s not found in previous rollup operations", rollupOp.DistinctTag)
+		}
+	}
 	return nil
 }
 
@@ -25,6 +25,7 @@ import (
 	"math"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
 	"github.com/m3db/m3/src/cluster/kv/mem"
@@ -721,6 +722,146 @@ func TestValidatorValidateRollupRulePipelineInvalidTransformationType(t *testing
 	require.True(t, strings.Contains(err.Error(), "invalid transformation operation at index 0"))
 }
 
+func TestValidatorValidateRollupRulePipelineInvalidTransformationConstant(t *testing.T) {
+	inputs := []struct {
+		op          pipeline.TransformationOp
+		expectedErr string
+	}{
+		{
+			op:          pipeline.TransformationOp{Type: transformation.PerSecond, Constant: 2},
+			expectedErr: "transformation type PerSecond does not take a constant",
+		},
+		{
+			op:          pipeline.TransformationOp{Type: transformation.Multiply, Constant: math.Inf(1)},
+			expectedErr: "invalid constant +Inf for transformation type Multiply",
+		},
+	}
+
+	for _, input := range inputs {
+		view := view.RuleSet{
+			RollupRules: []view.RollupRule{
+				{
+					Name:   "snapshot1",
+					Filter: testTypeTag + ":" + testCounterType,
+					Targets: []view.RollupTarget{
+						{
+							Pipeline: pipeline.NewPipeline([]pipeline.OpUnion{
+								{
+									Type:           pipeline.TransformationOpType,
+									Transformation: input.op,
+								},
+								{
+									Type: pipeline.RollupOpType,
+									Rollup: pipeline.RollupOp{
+										NewName:       []byte("rName1"),
+										Tags:          [][]byte{[]byte("rtagName1"), []byte("rtagName2")},
+										AggregationID: aggregation.DefaultID,
+									},
+								},
+							}),
+							StoragePolicies: testStoragePolicies(),
+						},
+					},
+				},
+			},
+		}
+		validator := NewValidator(testValidatorOptions())
+		err := validator.ValidateSnapshot(view)
+		require.Error(t, err)
+		require.True(t, strings.Contains(err.Error(), input.expectedErr))
+	}
+}
+
+func TestValidatorValidateRollupRulePipelineInvalidTransformationWindow(t *testing.T) {
+	inputs := []struct {
+		op          pipeline.TransformationOp
+		expectedErr string
+	}{
+		{
+			op:          pipeline.TransformationOp{Type: transformation.PerSecond, Window: time.Minute},
+			expectedErr: "transformation type PerSecond does not take a window",
+		},
+		{
+			op:          pipeline.TransformationOp{Type: transformation.Rate},
+			expectedErr: "invalid window 0s for transformation type Rate",
+		},
+		{
+			op:          pipeline.TransformationOp{Type: transformation.Rate, Window: -time.Minute},
+			expectedErr: "invalid window -1m0s for transformation type Rate",
+		},
+	}
+
+	for _, input := range inputs {
+		view := view.RuleSet{
+			RollupRules: []view.RollupRule{
+				{
+					Name:   "snapshot1",
+					Filter: testTypeTag + ":" + testCounterType,
+					Targets: []view.RollupTarget{
+						{
+							Pipeline: pipeline.NewPipeline([]pipeline.OpUnion{
+								{
+									Type:           pipeline.TransformationOpType,
+									Transformation: input.op,
+								},
+								{
+									Type: pipeline.RollupOpType,
+									Rollup: pipeline.RollupOp{
+										NewName:       []byte("rName1"),
+										Tags:          [][]byte{[]byte("rtagName1"), []byte("rtagName2")},
+										AggregationID: aggregation.DefaultID,
+									},
+								},
+							}),
+							StoragePolicies: testStoragePolicies(),
+						},
+					},
+				},
+			},
+		}
+		validator := NewValidator(testValidatorOptions())
+		err := validator.ValidateSnapshot(view)
+		require.Error(t, err)
+		require.True(t, strings.Contains(err.Error(), input.expectedErr))
+	}
+}
+
+func TestValidatorValidateRollupRulePipelineTransformationWithConstant(t *testing.T) {
+	view := view.RuleSet{
+		RollupRules: []view.RollupRule{
+			{
+				Name:   "snapshot1",
+				Filter: testTypeTag + ":" + testCounterType,
+				Targets: []view.RollupTarget{
+					{
+						Pipeline: pipeline.NewPipeline([]pipeline.OpUnion{
+							{
+								Type:           pipeline.TransformationOpType,
+								Transformation: pipeline.TransformationOp{Type: transformation.Rate, Window: time.Minute},
+							},
+							{
+								Type:           pipeline.TransformationOpType,
+								Transformation: pipeline.TransformationOp{Type: transformation.Multiply, Constant: 60},
+							},
+							{
+								Type: pipeline.RollupOpType,
+								Rollup: pipeline.RollupOp{
+									NewName:       []byte("rName1"),
+									Tags:          [][]byte{[]byte("rtagName1"), []byte("rtagName2")},
+									AggregationID: aggregation.DefaultID,
+								},
+							},
+						}),
+						StoragePolicies: testStoragePolicies(),
+					},
+				},
+			},
+		},
+	}
+	validator := NewValidator(testValidatorOptions())
+	require.NoError(t, validator.ValidateSnapshot(view))
+}
+
 func TestValidatorValidateRollupRulePipelineNoRollupOp(t *testing.T) {
 	view := view.RuleSet{
 		RollupRules: []view.RollupRule{
@@ -1288,6 +1429,77 @@ func TestValidatorValidateRollupRuleRollupOpNonFirstLevelAggregationTypes(t *tes
 	}
 }
 
+func TestValidatorValidateRollupRuleRollupOpDistinctTag(t *testing.T) {
+	distinctCountTypes := []aggregation.Type{aggregation.DistinctCount}
+	opts := testValidatorOptions().
+		SetDefaultAllowedFirstLevelAggregationTypes(distinctCountTypes).
+		SetDefaultAllowedNonFirstLevelAggregationTypes(distinctCountTypes)
+	perSecondOp := pipeline.OpUnion{
+		Type:           pipeline.TransformationOpType,
+		Transformation: pipeline.TransformationOp{Type: transformation.PerSecond},
+	}
+	newRollupOp := func(aggregationID aggregation.ID, distinctTag string) pipeline.OpUnion {
+		op := pipeline.OpUnion{
+			Type: pipeline.RollupOpType,
+			Rollup: pipeline.RollupOp{
+				NewName:       []byte("rName1"),
+				Tags:          [][]byte{[]byte("rtagName1"), []byte("rtagName2")},
+				AggregationID: aggregationID,
+			},
+		}
+		if distinctTag != "" {
+			op.Rollup.DistinctTag = []byte(distinctTag)
+		}
+		return op
+	}
+	distinctCountID := aggregation.MustCompressTypes(aggregation.DistinctCount)
+
+	inputs := []struct {
+		ops         []pipeline.OpUnion
+		expectedErr error
+	}{
+		{
+			ops: []pipeline.OpUnion{perSecondOp, newRollupOp(distinctCountID, "user")},
+		},
+		{
+			ops:         []pipeline.OpUnion{perSecondOp, newRollupOp(distinctCountID, "")},
+			expectedErr: errNoDistinctTag,
+		},
+		{
+			ops:         []pipeline.OpUnion{perSecondOp, newRollupOp(aggregation.DefaultID, "user")},
+			expectedErr: errDistinctTagWithoutDistinctCount,
+		},
+		{
+			ops:         []pipeline.OpUnion{newRollupOp(distinctCountID, "user")},
+			expectedErr: errDistinctCountRollupFirstInPipeline,
+		},
+	}
+
+	for _, input := range inputs {
+		view := view.RuleSet{
+			RollupRules: []view.RollupRule{
+				{
+					Name:   "snapshot1",
+					Filter: testTypeTag + ":" + testTimerType,
+					Targets: []view.RollupTarget{
+						{
+							Pipeline:        pipeline.NewPipeline(input.ops),
+							StoragePolicies: testStoragePolicies(),
+						},
+					},
+				},
+			},
+		}
+		err := NewValidator(opts).ValidateSnapshot(view)
+		if input.expectedErr == nil {
+			require.NoError(t, err)
+			continue
+		}
+		require.Error(t, err)
+		require.True(t, strings.Contains(err.Error(), input.expectedErr.Error()))
+	}
+}
+
 func TestValidatorValidateRollupRuleRollupTargetWithStoragePolicies(t *testing.T) {
 	storagePolicies := testStoragePolicies()
 	view := view.RuleSet{
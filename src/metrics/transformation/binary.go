@@ -45,3 +45,21 @@ func perSecond(prev, curr Datapoint) Datapoint {
 	rate := diff * float64(nanosPerSecond) / float64(curr.TimeNanos-prev.TimeNanos)
 	return Datapoint{TimeNanos: curr.TimeNanos, Value: rate}
 }
+
+// increase computes the increase between consecutive datapoints of a monotonic
+// counter, taking into account counter resets.
+// * It skips NaN values.
+// * It assumes the timestamps are monotonically increasing. If the condition is not
+//   met, an empty datapoint is returned.
+// * A decrease in value is treated as a counter reset, in which case the counter is
+//   assumed to have started from zero and the current value is the increase.
+func increase(prev, curr Datapoint) Datapoint {
+	if prev.TimeNanos >= curr.TimeNanos || math.IsNaN(prev.Value) || math.IsNaN(curr.Value) {
+		return emptyDatapoint
+	}
+	diff := curr.Value - prev.Value
+	if diff < 0 {
+		diff = curr.Value
+	}
+	return Datapoint{TimeNanos: curr.TimeNanos, Value: diff}
+}
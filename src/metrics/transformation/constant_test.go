@@ -0,0 +1,76 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package transformation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdd(t *testing.T) {
+	inputs := []struct {
+		dp       Datapoint
+		constant float64
+		expected Datapoint
+	}{
+		{
+			dp:       Datapoint{TimeNanos: 1234, Value: 1.5},
+			constant: 2,
+			expected: Datapoint{TimeNanos: 1234, Value: 3.5},
+		},
+		{
+			dp:       Datapoint{TimeNanos: 1234, Value: 1.5},
+			constant: -2,
+			expected: Datapoint{TimeNanos: 1234, Value: -0.5},
+		},
+	}
+
+	for _, input := range inputs {
+		require.Equal(t, input.expected, add(input.dp, input.constant))
+	}
+	require.True(t, add(emptyDatapoint, 2).IsEmpty())
+}
+
+func TestMultiply(t *testing.T) {
+	inputs := []struct {
+		dp       Datapoint
+		constant float64
+		expected Datapoint
+	}{
+		{
+			dp:       Datapoint{TimeNanos: 1234, Value: 1.5},
+			constant: 2,
+			expected: Datapoint{TimeNanos: 1234, Value: 3},
+		},
+		{
+			dp:       Datapoint{TimeNanos: 1234, Value: 1.5},
+			constant: 0,
+			expected: Datapoint{TimeNanos: 1234, Value: 0},
+		},
+	}
+
+	for _, input := range inputs {
+		require.Equal(t, input.expected, multiply(input.dp, input.constant))
+	}
+	require.True(t, multiply(Datapoint{Value: math.NaN()}, 2).IsEmpty())
+}
@@ -44,3 +44,14 @@ type UnaryTransform func(dp Datapoint) Datapoint
 // previous and the current datapoint as input and produces
 // a single datapoint as the transformation result.
 type BinaryTransform func(prev, curr Datapoint) Datapoint
+
+// ConstantTransform is a unary transformation parameterized by a constant
+// that takes a single datapoint and the constant as input and transforms
+// them into a datapoint as output.
+type ConstantTransform func(dp Datapoint, constant float64) Datapoint
+
+// WindowTransform is a transformation computed over a window that takes the
+// datapoints consumed within the window before the current datapoint, ordered
+// by time, and the current datapoint as input and produces a single datapoint
+// as the transformation result.
+type WindowTransform func(prev []Datapoint, curr Datapoint) Datapoint
@@ -35,11 +35,16 @@ const (
 	UnknownType Type = iota
 	Absolute
 	PerSecond
+	Increase
+	Rate
+	Add
+	Multiply
 )
 
 // IsValid checks if the transformation type is valid.
 func (t Type) IsValid() bool {
-	return t.IsUnaryTransform() || t.IsBinaryTransform()
+	return t.IsUnaryTransform() || t.IsBinaryTransform() || t.IsConstantTransform() ||
+		t.IsWindowTransform()
 }
 
 // IsUnaryTransform returns whether this is a unary transformation.
@@ -54,6 +59,18 @@ func (t Type) IsBinaryTransform() bool {
 	return exists
 }
 
+// IsConstantTransform returns whether this is a transformation parameterized by a constant.
+func (t Type) IsConstantTransform() bool {
+	_, exists := constantTransforms[t]
+	return exists
+}
+
+// IsWindowTransform returns whether this is a transformation computed over a window.
+func (t Type) IsWindowTransform() bool {
+	_, exists := windowTransforms[t]
+	return exists
+}
+
 // UnaryTransform returns the unary transformation function associated with
 // the transformation type if applicable, or an error otherwise.
 func (t Type) UnaryTransform() (UnaryTransform, error) {
@@ -94,6 +111,46 @@ func (t Type) MustBinaryTransform() BinaryTransform {
 	return tf
 }
 
+// ConstantTransform returns the constant transformation function associated with
+// the transformation type if applicable, or an error otherwise.
+func (t Type) ConstantTransform() (ConstantTransform, error) {
+	tf, exists := constantTransforms[t]
+	if !exists {
+		return nil, fmt.Errorf("%v is not a constant transformation", t)
+	}
+	return tf, nil
+}
+
+// MustConstantTransform returns the constant transformation function associated with
+// the transformation type if applicable, or panics otherwise.
+func (t Type) MustConstantTransform() ConstantTransform {
+	tf, err := t.ConstantTransform()
+	if err != nil {
+		panic(err)
+	}
+	return tf
+}
+
+// WindowTransform returns the window transformation function associated with
+// the transformation type if applicable, or an error otherwise.
+func (t Type) WindowTransform() (WindowTransform, error) {
+	tf, exists := windowTransforms[t]
+	if !exists {
+		return nil, fmt.Errorf("%v is not a window transformation", t)
+	}
+	return tf, nil
+}
+
+// MustWindowTransform returns the window transformation function associated with
+// the transformation type if applicable, or panics otherwise.
+func (t Type) MustWindowTransform() WindowTransform {
+	tf, err := t.WindowTransform()
+	if err != nil {
+		panic(err)
+	}
+	return tf
+}
+
 // ToProto converts the transformation type to a protobuf message in place.
 func (t Type) ToProto(pb *transformationpb.TransformationType) error {
 	switch t {
@@ -101,6 +158,14 @@ func (t Type) ToProto(pb *transformationpb.TransformationType) error {
 		*pb = transformationpb.TransformationType_ABSOLUTE
 	case PerSecond:
 		*pb = transformationpb.TransformationType_PERSECOND
+	case Increase:
+		*pb = transformationpb.TransformationType_INCREASE
+	case Rate:
+		*pb = transformationpb.TransformationType_RATE
+	case Add:
+		*pb = transformationpb.TransformationType_ADD
+	case Multiply:
+		*pb = transformationpb.TransformationType_MULTIPLY
 	default:
 		return fmt.Errorf("unknown transformation type: %v", t)
 	}
@@ -114,6 +179,14 @@ func (t *Type) FromProto(pb transformationpb.TransformationType) error {
 		*t = Absolute
 	case transformationpb.TransformationType_PERSECOND:
 		*t = PerSecond
+	case transformationpb.TransformationType_INCREASE:
+		*t = Increase
+	case transformationpb.TransformationType_RATE:
+		*t = Rate
+	case transformationpb.TransformationType_ADD:
+		*t = Add
+	case transformationpb.TransformationType_MULTIPLY:
+		*t = Multiply
 	default:
 		return fmt.Errorf("unknown transformation type in proto: %v", pb)
 	}
@@ -174,6 +247,14 @@ var (
 	}
 	binaryTransforms = map[Type]BinaryTransform{
 		PerSecond: perSecond,
+		Increase:  increase,
+	}
+	constantTransforms = map[Type]ConstantTransform{
+		Add:      add,
+		Multiply: multiply,
+	}
+	windowTransforms = map[Type]WindowTransform{
+		Rate: rate,
 	}
 	typeStringMap map[string]Type
 )
@@ -186,4 +267,10 @@ func init() {
 	for t := range binaryTransforms {
 		typeStringMap[t.String()] = t
 	}
+	for t := range constantTransforms {
+		typeStringMap[t.String()] = t
+	}
+	for t := range windowTransforms {
+		typeStringMap[t.String()] = t
+	}
 }
@@ -24,9 +24,9 @@ package transformation
 
 import "fmt"
 
-const _Type_name = "UnknownTypeAbsolutePerSecond"
+const _Type_name = "UnknownTypeAbsolutePerSecondIncreaseRateAddMultiply"
 
-var _Type_index = [...]uint8{0, 11, 19, 28}
+var _Type_index = [...]uint8{0, 11, 19, 28, 36, 40, 43, 51}
 
 func (i Type) String() string {
 	if i < 0 || i >= Type(len(_Type_index)-1) {
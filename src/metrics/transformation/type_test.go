@@ -43,6 +43,8 @@ func TestIsUnaryTransform(t *testing.T) {
 		{typ: Absolute, expected: true},
 		{typ: UnknownType, expected: false},
 		{typ: PerSecond, expected: false},
+		{typ: Increase, expected: false},
+		{typ: Add, expected: false},
 		{typ: Type(10000), expected: false},
 	}
 
@@ -57,8 +59,11 @@ func TestIsBinaryTransform(t *testing.T) {
 		expected bool
 	}{
 		{typ: PerSecond, expected: true},
+		{typ: Increase, expected: true},
 		{typ: UnknownType, expected: false},
+		{typ: Rate, expected: false},
 		{typ: Absolute, expected: false},
+		{typ: Multiply, expected: false},
 		{typ: Type(10000), expected: false},
 	}
 
@@ -67,6 +72,41 @@ func TestIsBinaryTransform(t *testing.T) {
 	}
 }
 
+func TestIsConstantTransform(t *testing.T) {
+	inputs := []struct {
+		typ      Type
+		expected bool
+	}{
+		{typ: Add, expected: true},
+		{typ: Multiply, expected: true},
+		{typ: UnknownType, expected: false},
+		{typ: Absolute, expected: false},
+		{typ: Rate, expected: false},
+		{typ: Type(10000), expected: false},
+	}
+
+	for _, input := range inputs {
+		require.Equal(t, input.expected, input.typ.IsConstantTransform())
+	}
+}
+
+func TestIsWindowTransform(t *testing.T) {
+	inputs := []struct {
+		typ      Type
+		expected bool
+	}{
+		{typ: Rate, expected: true},
+		{typ: UnknownType, expected: false},
+		{typ: Increase, expected: false},
+		{typ: Add, expected: false},
+		{typ: Type(10000), expected: false},
+	}
+
+	for _, input := range inputs {
+		require.Equal(t, input.expected, input.typ.IsWindowTransform())
+	}
+}
+
 func TestUnaryTransform(t *testing.T) {
 	inputs := []Type{
 		Absolute,
@@ -122,6 +162,7 @@ func TestMustUnaryTransformPanics(t *testing.T) {
 func TestBinaryTransform(t *testing.T) {
 	inputs := []Type{
 		PerSecond,
+		Increase,
 	}
 
 	for _, input := range inputs {
@@ -135,6 +176,7 @@ func TestBinaryTransformErrors(t *testing.T) {
 	inputs := []Type{
 		UnknownType,
 		Absolute,
+		Rate,
 		Type(10000),
 	}
 
@@ -148,6 +190,7 @@ func TestBinaryTransformErrors(t *testing.T) {
 func TestMustBinaryTransform(t *testing.T) {
 	inputs := []Type{
 		PerSecond,
+		Increase,
 	}
 
 	for _, input := range inputs {
@@ -161,6 +204,7 @@ func TestMustBinaryTransformPanics(t *testing.T) {
 	inputs := []Type{
 		UnknownType,
 		Absolute,
+		Rate,
 		Type(10000),
 	}
 
@@ -171,6 +215,114 @@ func TestMustBinaryTransformPanics(t *testing.T) {
 	}
 }
 
+func TestConstantTransform(t *testing.T) {
+	inputs := []Type{
+		Add,
+		Multiply,
+	}
+
+	for _, input := range inputs {
+		tf, err := input.ConstantTransform()
+		require.NoError(t, err)
+		require.NotNil(t, tf)
+	}
+}
+
+func TestConstantTransformErrors(t *testing.T) {
+	inputs := []Type{
+		UnknownType,
+		Absolute,
+		PerSecond,
+		Type(10000),
+	}
+
+	for _, input := range inputs {
+		tf, err := input.ConstantTransform()
+		require.Error(t, err)
+		require.Nil(t, tf)
+	}
+}
+
+func TestMustConstantTransform(t *testing.T) {
+	inputs := []Type{
+		Add,
+		Multiply,
+	}
+
+	for _, input := range inputs {
+		var tf ConstantTransform
+		require.NotPanics(t, func() { tf = input.MustConstantTransform() })
+		require.NotNil(t, tf)
+	}
+}
+
+func TestMustConstantTransformPanics(t *testing.T) {
+	inputs := []Type{
+		UnknownType,
+		Rate,
+		Type(10000),
+	}
+
+	for _, input := range inputs {
+		var tf ConstantTransform
+		require.Panics(t, func() { tf = input.MustConstantTransform() })
+		require.Nil(t, tf)
+	}
+}
+
+func TestWindowTransform(t *testing.T) {
+	inputs := []Type{
+		Rate,
+	}
+
+	for _, input := range inputs {
+		tf, err := input.WindowTransform()
+		require.NoError(t, err)
+		require.NotNil(t, tf)
+	}
+}
+
+func TestWindowTransformErrors(t *testing.T) {
+	inputs := []Type{
+		UnknownType,
+		Increase,
+		Add,
+		Type(10000),
+	}
+
+	for _, input := range inputs {
+		tf, err := input.WindowTransform()
+		require.Error(t, err)
+		require.Nil(t, tf)
+	}
+}
+
+func TestMustWindowTransform(t *testing.T) {
+	inputs := []Type{
+		Rate,
+	}
+
+	for _, input := range inputs {
+		var tf WindowTransform
+		require.NotPanics(t, func() { tf = input.MustWindowTransform() })
+		require.NotNil(t, tf)
+	}
+}
+
+func TestMustWindowTransformPanics(t *testing.T) {
+	inputs := []Type{
+		UnknownType,
+		PerSecond,
+		Type(10000),
+	}
+
+	for _, input := range inputs {
+		var tf WindowTransform
+		require.Panics(t, func() { tf = input.MustWindowTransform() })
+		require.Nil(t, tf)
+	}
+}
+
 func TestTypeString(t *testing.T) {
 	inputs := []struct {
 		typ      Type
@@ -179,6 +331,10 @@ func TestTypeString(t *testing.T) {
 		{typ: UnknownType, expected: "UnknownType"},
 		{typ: Absolute, expected: "Absolute"},
 		{typ: PerSecond, expected: "PerSecond"},
+		{typ: Increase, expected: "Increase"},
+		{typ: Rate, expected: "Rate"},
+		{typ: Add, expected: "Add"},
+		{typ: Multiply, expected: "Multiply"},
 		{typ: Type(1000), expected: "Type(1000)"},
 	}
 
@@ -218,3 +374,25 @@ func TestTypeRoundTrip(t *testing.T) {
 	require.NoError(t, res.FromProto(pb))
 	require.Equal(t, testType, res)
 }
+
+func TestTypeRoundTripAllTypes(t *testing.T) {
+	for _, input := range []Type{Absolute, PerSecond, Increase, Rate, Add, Multiply} {
+		var (
+			pb  transformationpb.TransformationType
+			res Type
+		)
+		require.NoError(t, input.ToProto(&pb))
+		require.NoError(t, res.FromProto(pb))
+		require.Equal(t, input, res)
+	}
+}
+
+func TestParseType(t *testing.T) {
+	for _, input := range []Type{Absolute, PerSecond, Increase, Rate, Add, Multiply} {
+		res, err := ParseType(input.String())
+		require.NoError(t, err)
+		require.Equal(t, input, res)
+	}
+	_, err := ParseType("UnknownType")
+	require.Error(t, err)
+}
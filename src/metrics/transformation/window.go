@@ -0,0 +1,69 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package transformation
+
+import "math"
+
+// rate computes the per second rate of increase of a monotonic counter over a
+// window, taking into account counter resets.
+// * It skips NaN values.
+// * The previous datapoints are those consumed within the window before the
+//   current datapoint, ordered by time. If there are no valid previous datapoints,
+//   an empty datapoint is returned.
+// * The increase over the window is the sum of the increases between consecutive
+//   datapoints, where a decrease in value is treated as a counter reset, and the
+//   rate is computed over the time elapsed between the earliest datapoint in the
+//   window and the current datapoint.
+func rate(prev []Datapoint, curr Datapoint) Datapoint {
+	if math.IsNaN(curr.Value) {
+		return emptyDatapoint
+	}
+	var (
+		first     = emptyDatapoint
+		last      = emptyDatapoint
+		increased float64
+	)
+	for _, dp := range prev {
+		if math.IsNaN(dp.Value) {
+			continue
+		}
+		if first.IsEmpty() {
+			first, last = dp, dp
+			continue
+		}
+		res := increase(last, dp)
+		if res.IsEmpty() {
+			return emptyDatapoint
+		}
+		increased += res.Value
+		last = dp
+	}
+	if first.IsEmpty() {
+		return emptyDatapoint
+	}
+	res := increase(last, curr)
+	if res.IsEmpty() {
+		return emptyDatapoint
+	}
+	increased += res.Value
+	value := increased * float64(nanosPerSecond) / float64(curr.TimeNanos-first.TimeNanos)
+	return Datapoint{TimeNanos: curr.TimeNanos, Value: value}
+}
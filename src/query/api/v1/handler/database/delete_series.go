@@ -0,0 +1,172 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package database
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/m3"
+	"github.com/m3db/m3/src/query/util/logging"
+	"github.com/m3db/m3/src/x/net/http"
+	"github.com/m3db/m3x/ident"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DeleteSeriesURL is the URL for the database delete series handler.
+	DeleteSeriesURL = handler.RoutePrefixV1 + "/database/series/delete"
+
+	// DeleteSeriesHTTPMethod is the HTTP method used with this resource.
+	DeleteSeriesHTTPMethod = http.MethodPost
+
+	namespaceParam = "namespace"
+	matchParam     = "match[]"
+	idParam        = "id[]"
+)
+
+var errNoSeriesSelected = errors.New("either match[] or id[] must be specified")
+
+// DeleteSeriesHandler is the handler for deleting series matching
+// a set of selectors, or with a set of IDs, from the database namespaces.
+type DeleteSeriesHandler struct {
+	clusters   m3.Clusters
+	tagOptions models.TagOptions
+}
+
+type deleteSeriesResponse struct {
+	NumSeries int64 `json:"numSeries"`
+}
+
+// NewDeleteSeriesHandler returns a new instance of a database delete series handler.
+func NewDeleteSeriesHandler(
+	clusters m3.Clusters,
+	tagOptions models.TagOptions,
+) http.Handler {
+	return &DeleteSeriesHandler{
+		clusters:   clusters,
+		tagOptions: tagOptions,
+	}
+}
+
+func (h *DeleteSeriesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.WithContext(ctx)
+
+	if err := r.ParseForm(); err != nil {
+		logger.Error("unable to parse request", zap.Error(err))
+		xhttp.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	ids := r.Form[idParam]
+	if len(ids) == 0 && len(r.Form[matchParam]) == 0 {
+		logger.Error("unable to parse request", zap.Error(errNoSeriesSelected))
+		xhttp.Error(w, errNoSeriesSelected, http.StatusBadRequest)
+		return
+	}
+
+	var tagMatchers []models.Matchers
+	if len(r.Form[matchParam]) > 0 {
+		query, rErr := prometheus.ParseSeriesMatchQuery(r, h.tagOptions)
+		if rErr != nil {
+			logger.Error("unable to parse request", zap.Any("error", rErr))
+			xhttp.Error(w, rErr.Inner(), rErr.Code())
+			return
+		}
+		tagMatchers = query.TagMatchers
+	}
+
+	namespaces, err := h.namespaces(r.Form.Get(namespaceParam))
+	if err != nil {
+		logger.Error("unable to resolve namespace", zap.Error(err))
+		xhttp.Error(w, err, http.StatusNotFound)
+		return
+	}
+
+	var numSeries int64
+	if len(ids) > 0 {
+		for _, ns := range namespaces {
+			n, err := ns.Session().DeleteSeries(ns.NamespaceID(), newIDsIterator(ids))
+			if err != nil {
+				logger.Error("unable to delete series",
+					zap.String("namespace", ns.NamespaceID().String()),
+					zap.Error(err))
+				xhttp.Error(w, err, http.StatusInternalServerError)
+				return
+			}
+			numSeries += n
+		}
+	}
+
+	for _, matchers := range tagMatchers {
+		q, err := storage.FetchQueryToM3Query(&storage.FetchQuery{
+			TagMatchers: matchers,
+		})
+		if err != nil {
+			logger.Error("unable to convert matchers", zap.Error(err))
+			xhttp.Error(w, err, http.StatusBadRequest)
+			return
+		}
+
+		for _, ns := range namespaces {
+			n, err := ns.Session().DeleteTagged(ns.NamespaceID(), q)
+			if err != nil {
+				logger.Error("unable to delete series",
+					zap.String("namespace", ns.NamespaceID().String()),
+					zap.Error(err))
+				xhttp.Error(w, err, http.StatusInternalServerError)
+				return
+			}
+			numSeries += n
+		}
+	}
+
+	xhttp.WriteJSONResponse(w, deleteSeriesResponse{NumSeries: numSeries}, logger)
+}
+
+func (h *DeleteSeriesHandler) namespaces(name string) (m3.ClusterNamespaces, error) {
+	all := h.clusters.ClusterNamespaces()
+	if name == "" {
+		return all, nil
+	}
+
+	for _, ns := range all {
+		if ns.NamespaceID().String() == name {
+			return m3.ClusterNamespaces{ns}, nil
+		}
+	}
+	return nil, fmt.Errorf("namespace %s not found", name)
+}
+
+func newIDsIterator(ids []string) ident.Iterator {
+	idents := make([]ident.ID, 0, len(ids))
+	for _, id := range ids {
+		idents = append(idents, ident.StringID(id))
+	}
+	return ident.NewIDsIterator(idents...)
+}
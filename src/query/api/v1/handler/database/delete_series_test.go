@@ -0,0 +1,167 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package database
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage/m3"
+	"github.com/m3db/m3x/ident"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDeleteSeriesTestHandler(
+	t *testing.T,
+	ctrl *gomock.Controller,
+) (http.Handler, *client.MockSession, *client.MockSession) {
+	unaggregated := client.NewMockSession(ctrl)
+	aggregated := client.NewMockSession(ctrl)
+	clusters, err := m3.NewClusters(m3.UnaggregatedClusterNamespaceDefinition{
+		NamespaceID: ident.StringID("metrics_unaggregated"),
+		Session:     unaggregated,
+		Retention:   24 * time.Hour,
+	}, m3.AggregatedClusterNamespaceDefinition{
+		NamespaceID: ident.StringID("metrics_aggregated"),
+		Session:     aggregated,
+		Retention:   30 * 24 * time.Hour,
+		Resolution:  time.Minute,
+	})
+	require.NoError(t, err)
+
+	return NewDeleteSeriesHandler(clusters, models.NewTagOptions()),
+		unaggregated, aggregated
+}
+
+func TestDeleteSeriesHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler, unaggregated, aggregated := newDeleteSeriesTestHandler(t, ctrl)
+	unaggregated.EXPECT().
+		DeleteTagged(ident.NewIDMatcher("metrics_unaggregated"), gomock.Any()).
+		Return(int64(3), nil)
+	aggregated.EXPECT().
+		DeleteTagged(ident.NewIDMatcher("metrics_aggregated"), gomock.Any()).
+		Return(int64(2), nil)
+
+	form := url.Values{"match[]": []string{`{tenant="foo"}`}}
+	req := httptest.NewRequest(DeleteSeriesHTTPMethod,
+		DeleteSeriesURL+"?"+form.Encode(), nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"numSeries":5}`, stripAllWhitespace(string(body)))
+}
+
+func TestDeleteSeriesHandlerNamespace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler, _, aggregated := newDeleteSeriesTestHandler(t, ctrl)
+	aggregated.EXPECT().
+		DeleteTagged(ident.NewIDMatcher("metrics_aggregated"), gomock.Any()).
+		Return(int64(2), nil)
+
+	form := url.Values{
+		"match[]":   []string{`{tenant="foo"}`},
+		"namespace": []string{"metrics_aggregated"},
+	}
+	req := httptest.NewRequest(DeleteSeriesHTTPMethod,
+		DeleteSeriesURL+"?"+form.Encode(), nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"numSeries":2}`, stripAllWhitespace(string(body)))
+}
+
+func TestDeleteSeriesHandlerIDs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler, _, aggregated := newDeleteSeriesTestHandler(t, ctrl)
+	var deleted []string
+	aggregated.EXPECT().
+		DeleteSeries(ident.NewIDMatcher("metrics_aggregated"), gomock.Any()).
+		Do(func(_ ident.ID, ids ident.Iterator) {
+			for ids.Next() {
+				deleted = append(deleted, ids.Current().String())
+			}
+		}).
+		Return(int64(2), nil)
+
+	form := url.Values{
+		"id[]":      []string{"foo", "bar"},
+		"namespace": []string{"metrics_aggregated"},
+	}
+	req := httptest.NewRequest(DeleteSeriesHTTPMethod,
+		DeleteSeriesURL+"?"+form.Encode(), nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"numSeries":2}`, stripAllWhitespace(string(body)))
+	assert.Equal(t, []string{"foo", "bar"}, deleted)
+}
+
+func TestDeleteSeriesHandlerErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler, _, _ := newDeleteSeriesTestHandler(t, ctrl)
+
+	// Missing selectors.
+	req := httptest.NewRequest(DeleteSeriesHTTPMethod, DeleteSeriesURL, nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+
+	// Unknown namespace.
+	form := url.Values{
+		"match[]":   []string{`{tenant="foo"}`},
+		"namespace": []string{"unknown"},
+	}
+	req = httptest.NewRequest(DeleteSeriesHTTPMethod,
+		DeleteSeriesURL+"?"+form.Encode(), nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+}
@@ -20,6 +20,13 @@
 
 package handler
 
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/m3db/m3/src/query/block"
+)
+
 const (
 	// WarningsHeader is the M3 warnings header when to display a warning to a user
 	WarningsHeader = "M3-Warnings"
@@ -32,4 +39,19 @@ const (
 
 	// DeprecatedHeader is the M3 deprecated header
 	DeprecatedHeader = "M3-Deprecated"
+
+	// ResolutionsHeader is the M3 header describing which namespace and
+	// resolution served each time range of a stitched result
+	ResolutionsHeader = "M3-Resolutions"
 )
+
+// AddResolutionsHeader adds the resolutions of a stitched result to the
+// response headers, one value per segment formatted as
+// "<namespace>;resolution=<duration>;start=<unix>;end=<unix>".
+func AddResolutionsHeader(w http.ResponseWriter, metadata block.ResultMetadata) {
+	for _, segment := range metadata.Resolutions {
+		w.Header().Add(ResolutionsHeader, fmt.Sprintf("%s;resolution=%s;start=%d;end=%d",
+			segment.Namespace, segment.Resolution.String(),
+			segment.Start.Unix(), segment.End.Unix()))
+	}
+}
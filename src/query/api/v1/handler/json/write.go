@@ -63,9 +63,10 @@ func NewWriteJSONHandler(store storage.Storage) http.Handler {
 // TODO: build this out to be a legitimate batched endpoint, change
 // Tags to take a list of tag structs
 type WriteQuery struct {
-	Tags      map[string]string `json:"tags" validate:"nonzero"`
-	Timestamp string            `json:"timestamp" validate:"nonzero"`
-	Value     float64           `json:"value" validate:"nonzero"`
+	Tags       map[string]string `json:"tags" validate:"nonzero"`
+	Timestamp  string            `json:"timestamp" validate:"nonzero"`
+	Value      float64           `json:"value" validate:"nonzero"`
+	Annotation string            `json:"annotation,omitempty"`
 }
 
 func (h *WriteJSONHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -98,6 +99,11 @@ func newStorageWriteQuery(req *WriteQuery) (*storage.WriteQuery, error) {
 		tags = tags.AddTag(models.Tag{Name: []byte(n), Value: []byte(v)})
 	}
 
+	var annotation []byte
+	if req.Annotation != "" {
+		annotation = []byte(req.Annotation)
+	}
+
 	return &storage.WriteQuery{
 		Tags: tags,
 		Datapoints: ts.Datapoints{
@@ -107,7 +113,7 @@ func newStorageWriteQuery(req *WriteQuery) (*storage.WriteQuery, error) {
 			},
 		},
 		Unit:       xtime.Millisecond,
-		Annotation: nil,
+		Annotation: annotation,
 	}, nil
 }
 
@@ -96,3 +96,39 @@ func TestJSONWrite(t *testing.T) {
 	writeErr := jsonWrite.store.Write(context.TODO(), writeQuery)
 	require.NoError(t, writeErr)
 }
+
+func TestJSONWriteWithAnnotation(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	storage, session := m3.NewStorageAndSession(t, ctrl)
+	session.EXPECT().
+		WriteTagged(gomock.Any(), gomock.Any(), gomock.Any(),
+			gomock.Any(), 10.0, gomock.Any(), []byte("trace-id")).
+		Return(nil)
+	session.EXPECT().IteratorPools().
+		Return(nil, nil).AnyTimes()
+
+	jsonWrite := &WriteJSONHandler{store: storage}
+
+	jsonReq := `{
+		"tags": { "tag_one": "val_one" },
+		"timestamp": "1534952005",
+		"value": 10.0,
+		"annotation": "trace-id"
+	}`
+	req, err := http.NewRequest(JSONWriteHTTPMethod, WriteJSONURL,
+		strings.NewReader(jsonReq))
+	require.NoError(t, err)
+
+	r, rErr := jsonWrite.parseRequest(req)
+	require.Nil(t, rErr, "unable to parse request")
+	require.Equal(t, "trace-id", r.Annotation)
+
+	writeQuery, err := newStorageWriteQuery(r)
+	require.NoError(t, err)
+	require.Equal(t, []byte("trace-id"), writeQuery.Annotation)
+
+	writeErr := jsonWrite.store.Write(context.TODO(), writeQuery)
+	require.NoError(t, writeErr)
+}
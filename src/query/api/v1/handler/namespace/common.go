@@ -100,6 +100,10 @@ func RegisterRoutes(r *mux.Router, client clusterclient.Client) {
 	r.HandleFunc(DeprecatedM3DBAddURL, addHandler).Methods(AddHTTPMethod)
 	r.HandleFunc(M3DBAddURL, addHandler).Methods(AddHTTPMethod)
 
+	// Update M3DB namespaces.
+	updateHandler := logged(NewUpdateHandler(client)).ServeHTTP
+	r.HandleFunc(M3DBUpdateURL, updateHandler).Methods(UpdateHTTPMethod)
+
 	// Delete M3DB namespaces.
 	deleteHandler := logged(NewDeleteHandler(client)).ServeHTTP
 	r.HandleFunc(DeprecatedM3DBDeleteURL, deleteHandler).Methods(DeleteHTTPMethod)
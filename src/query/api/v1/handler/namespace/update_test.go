@@ -0,0 +1,250 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package namespace
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/kv"
+	nsproto "github.com/m3db/m3/src/dbnode/generated/proto/namespace"
+	"github.com/m3db/m3/src/dbnode/storage/namespace"
+	"github.com/m3db/m3x/ident"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testUpdateJSON = `
+{
+    "name": "testNamespace",
+    "options": {
+      "bootstrapEnabled": true,
+      "flushEnabled": true,
+      "writesToCommitLog": true,
+      "cleanupEnabled": true,
+      "repairEnabled": true,
+      "retentionOptions": {
+        "retentionPeriodNanos": 345600000000000,
+        "blockSizeNanos": %d,
+        "bufferFutureNanos": 600000000000,
+        "bufferPastNanos": 1200000000000,
+        "blockDataExpiry": true,
+        "blockDataExpiryAfterNotAccessPeriodNanos": 300000000000
+      },
+      "snapshotEnabled": true,
+      "indexOptions": {
+        "enabled": true,
+        "blockSizeNanos": 7200000000000
+      }
+    }
+}
+`
+
+func testUpdateRegistry() nsproto.Registry {
+	return nsproto.Registry{
+		Namespaces: map[string]*nsproto.NamespaceOptions{
+			"testNamespace": &nsproto.NamespaceOptions{
+				BootstrapEnabled:  true,
+				FlushEnabled:      true,
+				WritesToCommitLog: true,
+				CleanupEnabled:    true,
+				RepairEnabled:     true,
+				SnapshotEnabled:   true,
+				RetentionOptions: &nsproto.RetentionOptions{
+					RetentionPeriodNanos:                     172800000000000,
+					BlockSizeNanos:                           7200000000000,
+					BufferFutureNanos:                        600000000000,
+					BufferPastNanos:                          600000000000,
+					BlockDataExpiry:                          true,
+					BlockDataExpiryAfterNotAccessPeriodNanos: 300000000000,
+				},
+				IndexOptions: &nsproto.IndexOptions{
+					Enabled:        true,
+					BlockSizeNanos: 7200000000000,
+				},
+			},
+		},
+	}
+}
+
+func newTestUpdateRequest(blockSizeNanos int64) *http.Request {
+	body := fmt.Sprintf(testUpdateJSON, blockSizeNanos)
+	return httptest.NewRequest(UpdateHTTPMethod, M3DBUpdateURL,
+		strings.NewReader(body))
+}
+
+func TestNamespaceUpdateHandlerNotFound(t *testing.T) {
+	mockClient, mockKV, _ := SetupNamespaceTest(t)
+	updateHandler := NewUpdateHandler(mockClient)
+
+	w := httptest.NewRecorder()
+	req := newTestUpdateRequest(7200000000000)
+	require.NotNil(t, req)
+
+	mockKV.EXPECT().Get(M3DBNodeNamespacesKey).Return(nil, kv.ErrNotFound)
+	updateHandler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "{\"error\":\"unable to find a namespace with specified name\"}\n", string(body))
+}
+
+func TestNamespaceUpdateHandlerInvalidUpdate(t *testing.T) {
+	mockClient, mockKV, ctrl := SetupNamespaceTest(t)
+	updateHandler := NewUpdateHandler(mockClient)
+
+	w := httptest.NewRecorder()
+	req := newTestUpdateRequest(3600000000000)
+	require.NotNil(t, req)
+
+	mockValue := kv.NewMockValue(ctrl)
+	mockValue.EXPECT().Unmarshal(gomock.Any()).Return(nil).SetArg(0, testUpdateRegistry())
+	mockValue.EXPECT().Version().Return(0)
+
+	mockKV.EXPECT().Get(M3DBNodeNamespacesKey).Return(mockValue, nil)
+	updateHandler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "{\"error\":\"invalid namespace update: namespace block size can not be updated\"}\n", string(body))
+}
+
+func TestNamespaceUpdateHandler(t *testing.T) {
+	mockClient, mockKV, ctrl := SetupNamespaceTest(t)
+	updateHandler := NewUpdateHandler(mockClient)
+
+	w := httptest.NewRecorder()
+	req := newTestUpdateRequest(7200000000000)
+	require.NotNil(t, req)
+
+	mockValue := kv.NewMockValue(ctrl)
+	mockValue.EXPECT().Unmarshal(gomock.Any()).Return(nil).SetArg(0, testUpdateRegistry())
+	mockValue.EXPECT().Version().Return(3)
+
+	var updated *nsproto.Registry
+	mockKV.EXPECT().Get(M3DBNodeNamespacesKey).Return(mockValue, nil)
+	mockKV.EXPECT().CheckAndSet(M3DBNodeNamespacesKey, 3, gomock.Any()).
+		DoAndReturn(func(_ string, _ int, v proto.Message) (int, error) {
+			updated = v.(*nsproto.Registry)
+			return 4, nil
+		})
+	updateHandler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NotNil(t, updated)
+	ropts := updated.Namespaces["testNamespace"].RetentionOptions
+	assert.Equal(t, int64(345600000000000), ropts.RetentionPeriodNanos)
+	assert.Equal(t, int64(1200000000000), ropts.BufferPastNanos)
+	assert.Equal(t, int64(7200000000000), ropts.BlockSizeNanos)
+}
+
+func TestNamespaceUpdateHandlerIndexBlockSize(t *testing.T) {
+	mockClient, mockKV, ctrl := SetupNamespaceTest(t)
+	updateHandler := NewUpdateHandler(mockClient)
+
+	w := httptest.NewRecorder()
+	req := newTestUpdateRequest(7200000000000)
+	require.NotNil(t, req)
+
+	registry := testUpdateRegistry()
+	registry.Namespaces["testNamespace"].IndexOptions.BlockSizeNanos = 14400000000000
+
+	mockValue := kv.NewMockValue(ctrl)
+	mockValue.EXPECT().Unmarshal(gomock.Any()).Return(nil).SetArg(0, registry)
+	mockValue.EXPECT().Version().Return(3)
+
+	var updated *nsproto.Registry
+	mockKV.EXPECT().Get(M3DBNodeNamespacesKey).Return(mockValue, nil)
+	mockKV.EXPECT().CheckAndSet(M3DBNodeNamespacesKey, 3, gomock.Any()).
+		DoAndReturn(func(_ string, _ int, v proto.Message) (int, error) {
+			updated = v.(*nsproto.Registry)
+			return 4, nil
+		})
+	updateHandler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NotNil(t, updated)
+	iopts := updated.Namespaces["testNamespace"].IndexOptions
+	assert.Equal(t, int64(7200000000000), iopts.BlockSizeNanos)
+	assert.Equal(t, int64(14400000000000), iopts.PreviousBlockSizeNanos)
+	assert.True(t, iopts.BlockSizeChangeStartNanos > time.Now().UnixNano())
+	assert.Equal(t, int64(0), iopts.BlockSizeChangeStartNanos%14400000000000)
+}
+
+func TestWithIndexBlockSizeChangeInRetention(t *testing.T) {
+	var (
+		now      = time.Now().Truncate(time.Hour)
+		existing = testIndexBlockSizeMetadata(t, 2*time.Hour,
+			namespace.IndexBlockSizeChange{
+				PreviousBlockSize: 4 * time.Hour,
+				Start:             now.Add(-24 * time.Hour).Truncate(4 * time.Hour),
+			})
+		update = testIndexBlockSizeMetadata(t, 8*time.Hour,
+			namespace.IndexBlockSizeChange{})
+	)
+
+	_, err := withIndexBlockSizeChange(existing, update, now)
+	require.Equal(t, errIndexBlockSizeChangeInRetention, err)
+
+	// Once the previous change expired the index block size can be updated.
+	retention := existing.Options().RetentionOptions().RetentionPeriod()
+	md, err := withIndexBlockSizeChange(existing, update, now.Add(retention))
+	require.NoError(t, err)
+	change := md.Options().IndexOptions().BlockSizeChange()
+	assert.Equal(t, 2*time.Hour, change.PreviousBlockSize)
+	assert.True(t, change.Start.After(now.Add(retention)))
+	require.NoError(t, namespace.ValidateUpdate(existing, md))
+
+	// An update of other options keeps the previous change.
+	md, err = withIndexBlockSizeChange(existing, existing, now)
+	require.NoError(t, err)
+	assert.True(t, md.Options().IndexOptions().BlockSizeChange().Equal(
+		existing.Options().IndexOptions().BlockSizeChange()))
+}
+
+func testIndexBlockSizeMetadata(
+	t *testing.T,
+	blockSize time.Duration,
+	change namespace.IndexBlockSizeChange,
+) namespace.Metadata {
+	opts := namespace.NewOptions()
+	opts = opts.SetIndexOptions(opts.IndexOptions().
+		SetEnabled(true).
+		SetBlockSize(blockSize).
+		SetBlockSizeChange(change))
+	md, err := namespace.NewMetadata(ident.StringID("testNamespace"), opts)
+	require.NoError(t, err)
+	return md
+}
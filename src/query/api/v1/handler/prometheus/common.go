@@ -21,6 +21,7 @@
 package prometheus
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -31,6 +32,7 @@ import (
 	"github.com/m3db/m3/src/query/models"
 	xpromql "github.com/m3db/m3/src/query/parser/promql"
 	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/m3"
 	"github.com/m3db/m3/src/query/util"
 	"github.com/m3db/m3/src/query/util/json"
 	xhttp "github.com/m3db/m3/src/x/net/http"
@@ -44,6 +46,7 @@ const (
 	// NameReplace is the parameter that gets replaced
 	NameReplace         = "name"
 	queryParam          = "query"
+	matchParam          = "match[]"
 	filterNameTagsParam = "tag"
 	errFormatStr        = "error parsing param: %s, error: %v"
 
@@ -59,6 +62,17 @@ type TimeoutOpts struct {
 	FetchTimeout time.Duration
 }
 
+// FetchErrorStatusCode returns the HTTP status code to respond with for an
+// error encountered while fetching data from storage. Queries that exceed
+// the resource limits of a storage node are client errors since retrying
+// them unchanged will fail again.
+func FetchErrorStatusCode(err error) int {
+	if m3.IsResourceExhaustedError(err) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
 // ParsePromCompressedRequest parses a snappy compressed request from Prometheus
 func ParsePromCompressedRequest(r *http.Request) ([]byte, *xhttp.ParseError) {
 	body := r.Body
@@ -176,12 +190,22 @@ func ParseSeriesMatchQuery(
 	tagOptions models.TagOptions,
 ) (*storage.SeriesMatchQuery, *xhttp.ParseError) {
 	r.ParseForm()
-	matcherValues := r.Form["match[]"]
+	matcherValues := r.Form[matchParam]
 	if len(matcherValues) == 0 {
 		return nil, xhttp.NewParseError(errors.ErrInvalidMatchers, http.StatusBadRequest)
 	}
 
-	start, err := parseTimeWithDefault(r, "start", time.Now().Add(time.Hour*24*-40))
+	return parseSeriesMatchQuery(r, matcherValues,
+		time.Now().Add(time.Hour*24*-40), tagOptions)
+}
+
+func parseSeriesMatchQuery(
+	r *http.Request,
+	matcherValues []string,
+	defaultStart time.Time,
+	tagOptions models.TagOptions,
+) (*storage.SeriesMatchQuery, *xhttp.ParseError) {
+	start, err := parseTimeWithDefault(r, "start", defaultStart)
 	if err != nil {
 		return nil, xhttp.NewParseError(err, http.StatusBadRequest)
 	}
@@ -213,28 +237,139 @@ func ParseSeriesMatchQuery(
 	}, nil
 }
 
-// ParseTagValuesToQuery parses a tag values request to a complete tags query
-func ParseTagValuesToQuery(
+// parseMetadataQuery parses the optional match[] selectors and time range
+// of a metadata request. If no selectors are given then every series which
+// has the default tag is matched.
+func parseMetadataQuery(
+	r *http.Request,
+	defaultTag []byte,
+	tagOptions models.TagOptions,
+) (*storage.SeriesMatchQuery, *xhttp.ParseError) {
+	r.ParseForm()
+	query, err := parseSeriesMatchQuery(r, r.Form[matchParam], time.Time{}, tagOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(query.TagMatchers) == 0 {
+		query.TagMatchers = []models.Matchers{
+			models.Matchers{
+				models.Matcher{
+					Type:  models.MatchRegexp,
+					Name:  defaultTag,
+					Value: matchValues,
+				},
+			},
+		}
+	}
+
+	return query, nil
+}
+
+func toCompleteTagsQueries(
+	query *storage.SeriesMatchQuery,
+	completeNameOnly bool,
+	filterNameTags [][]byte,
+) []*storage.CompleteTagsQuery {
+	queries := make([]*storage.CompleteTagsQuery, 0, len(query.TagMatchers))
+	for _, matchers := range query.TagMatchers {
+		queries = append(queries, &storage.CompleteTagsQuery{
+			CompleteNameOnly: completeNameOnly,
+			FilterNameTags:   filterNameTags,
+			TagMatchers:      matchers,
+			Start:            query.Start,
+			End:              query.End,
+		})
+	}
+
+	return queries
+}
+
+// ParseTagNamesToQueries parses a tag names request to complete tags
+// queries, one for each match[] selector given. If no selectors are given
+// then the names of tags on every series with a metric name are completed.
+func ParseTagNamesToQueries(
 	r *http.Request,
-) (*storage.CompleteTagsQuery, error) {
+	tagOptions models.TagOptions,
+) ([]*storage.CompleteTagsQuery, *xhttp.ParseError) {
+	query, err := parseMetadataQuery(r, tagOptions.MetricName(), tagOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return toCompleteTagsQueries(query, true, nil), nil
+}
+
+// ParseTagValuesToQueries parses a tag values request to complete tags
+// queries, one for each match[] selector given. If no selectors are given
+// then the values of the tag on every series are completed.
+func ParseTagValuesToQueries(
+	r *http.Request,
+	tagOptions models.TagOptions,
+) ([]*storage.CompleteTagsQuery, *xhttp.ParseError) {
 	vars := mux.Vars(r)
 	name, ok := vars[NameReplace]
 	if !ok || len(name) == 0 {
-		return nil, errors.ErrNoName
+		return nil, xhttp.NewParseError(errors.ErrNoName, http.StatusBadRequest)
 	}
 
 	nameBytes := []byte(name)
-	return &storage.CompleteTagsQuery{
-		CompleteNameOnly: false,
-		FilterNameTags:   [][]byte{nameBytes},
-		TagMatchers: models.Matchers{
-			models.Matcher{
-				Type:  models.MatchRegexp,
-				Name:  nameBytes,
-				Value: matchValues,
-			},
-		},
-	}, nil
+	query, err := parseMetadataQuery(r, nameBytes, tagOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return toCompleteTagsQueries(query, false, [][]byte{nameBytes}), nil
+}
+
+// CompleteTags runs each of the complete tags queries against the querier
+// and merges their results.
+func CompleteTags(
+	ctx context.Context,
+	querier storage.Querier,
+	queries []*storage.CompleteTagsQuery,
+	opts *storage.FetchOptions,
+) (*storage.CompleteTagsResult, error) {
+	completeNameOnly := len(queries) > 0 && queries[0].CompleteNameOnly
+	builder := storage.NewCompleteTagsResultBuilder(completeNameOnly)
+	for _, query := range queries {
+		result, err := querier.CompleteTags(ctx, query, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := builder.Add(result); err != nil {
+			return nil, err
+		}
+	}
+
+	result := builder.Build()
+	return &result, nil
+}
+
+// FetchTags runs a fetch tags query for each of the matchers in the series
+// match query against the querier.
+func FetchTags(
+	ctx context.Context,
+	querier storage.Querier,
+	query *storage.SeriesMatchQuery,
+	opts *storage.FetchOptions,
+) ([]*storage.SearchResults, error) {
+	results := make([]*storage.SearchResults, 0, len(query.TagMatchers))
+	for _, matchers := range query.TagMatchers {
+		result, err := querier.FetchTags(ctx, &storage.FetchQuery{
+			TagMatchers: matchers,
+			Start:       query.Start,
+			End:         query.End,
+		}, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
 }
 
 func renderNameOnlyTagCompletionResultsJSON(
@@ -301,6 +436,31 @@ func RenderTagCompletionResultsJSON(
 	return renderDefaultTagCompletionResultsJSON(w, results)
 }
 
+// RenderTagNamesResultsJSON renders tag names results to json format
+func RenderTagNamesResultsJSON(
+	w io.Writer,
+	result *storage.CompleteTagsResult,
+) error {
+	jw := json.NewWriter(w)
+	jw.BeginObject()
+
+	jw.BeginObjectField("status")
+	jw.WriteString("success")
+
+	jw.BeginObjectField("data")
+	jw.BeginArray()
+
+	for _, tag := range result.CompletedTags {
+		jw.WriteString(string(tag.Name))
+	}
+
+	jw.EndArray()
+
+	jw.EndObject()
+
+	return jw.Close()
+}
+
 // RenderTagValuesResultsJSON renders tag values results to json format
 func RenderTagValuesResultsJSON(
 	w io.Writer,
@@ -346,56 +506,11 @@ func RenderTagValuesResultsJSON(
 	return jw.Close()
 }
 
-type tag struct {
-	name  string
-	value string
-}
-
-func writeTagsHelper(
-	jw *json.Writer,
-	completedTags []storage.CompletedTag,
-	tags []tag,
-) {
-	if len(completedTags) == 0 {
-		jw.BeginObject()
-		for _, tag := range tags {
-			jw.BeginObjectField(tag.name)
-			jw.WriteString(tag.value)
-		}
-
-		jw.EndObject()
-		return
-	}
-
-	firstResult := completedTags[0]
-	name := string(firstResult.Name)
-
-	copiedTags := make([]tag, len(tags)+1)
-	copy(copiedTags, tags)
-	for _, value := range firstResult.Values {
-		copiedTags[len(tags)] = tag{name: name, value: string(value)}
-		writeTagsHelper(jw, completedTags[1:], copiedTags)
-	}
-}
-
-func writeTags(
-	jw *json.Writer,
-	results []*storage.CompleteTagsResult,
-) {
-	for _, result := range results {
-		jw.BeginArray()
-		tags := result.CompletedTags
-		if len(tags) > 0 {
-			writeTagsHelper(jw, result.CompletedTags, nil)
-		}
-		jw.EndArray()
-	}
-}
-
-// RenderSeriesMatchResultsJSON renders series match results to json format
+// RenderSeriesMatchResultsJSON renders series match results to json format,
+// series matched by more than one selector are only rendered once.
 func RenderSeriesMatchResultsJSON(
 	w io.Writer,
-	results []*storage.CompleteTagsResult,
+	results []*storage.SearchResults,
 ) error {
 	jw := json.NewWriter(w)
 	jw.BeginObject()
@@ -404,7 +519,29 @@ func RenderSeriesMatchResultsJSON(
 	jw.WriteString("success")
 
 	jw.BeginObjectField("data")
-	writeTags(jw, results)
+	jw.BeginArray()
+
+	seen := make(map[string]struct{})
+	for _, result := range results {
+		for _, metric := range result.Metrics {
+			id := string(metric.ID)
+			if _, ok := seen[id]; ok {
+				continue
+			}
+
+			seen[id] = struct{}{}
+			jw.BeginObject()
+			for _, tag := range metric.Tags.Tags {
+				jw.BeginObjectField(string(tag.Name))
+				jw.WriteString(string(tag.Value))
+			}
+
+			jw.EndObject()
+		}
+	}
+
+	jw.EndArray()
+
 	jw.EndObject()
 
 	return jw.Close()
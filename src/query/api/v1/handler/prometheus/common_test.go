@@ -22,15 +22,19 @@ package prometheus
 
 import (
 	"bytes"
+	"errors"
 	"net/http"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/m3"
 	"github.com/m3db/m3/src/query/test"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPromCompressedReadSuccess(t *testing.T) {
@@ -87,27 +91,50 @@ func (w *writer) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
-func makeResult() []*storage.CompleteTagsResult {
-	return []*storage.CompleteTagsResult{
-		&storage.CompleteTagsResult{
-			CompletedTags: []storage.CompletedTag{
-				storage.CompletedTag{
-					Name:   []byte("a"),
-					Values: [][]byte{[]byte("1"), []byte("2"), []byte("3")},
+func makeResult() []*storage.SearchResults {
+	return []*storage.SearchResults{
+		&storage.SearchResults{
+			Metrics: models.Metrics{
+				models.Metric{
+					ID: []byte("a=1,b=1"),
+					Tags: models.Tags{Tags: []models.Tag{
+						{Name: []byte("a"), Value: []byte("1")},
+						{Name: []byte("b"), Value: []byte("1")},
+					}},
 				},
-				storage.CompletedTag{
-					Name:   []byte("b"),
-					Values: [][]byte{[]byte("1"), []byte("2")},
+				models.Metric{
+					ID: []byte("a=1,b=2"),
+					Tags: models.Tags{Tags: []models.Tag{
+						{Name: []byte("a"), Value: []byte("1")},
+						{Name: []byte("b"), Value: []byte("2")},
+					}},
 				},
-				storage.CompletedTag{
-					Name:   []byte("c"),
-					Values: [][]byte{[]byte("1"), []byte("2"), []byte("3")},
+			},
+		},
+		&storage.SearchResults{
+			Metrics: models.Metrics{
+				models.Metric{
+					ID: []byte("a=1,b=2"),
+					Tags: models.Tags{Tags: []models.Tag{
+						{Name: []byte("a"), Value: []byte("1")},
+						{Name: []byte("b"), Value: []byte("2")},
+					}},
+				},
+				models.Metric{
+					ID: []byte("c=3"),
+					Tags: models.Tags{Tags: []models.Tag{
+						{Name: []byte("c"), Value: []byte("3")},
+					}},
 				},
 			},
 		},
 	}
 }
 
+func stripWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
+
 func TestRenderSeriesMatchResults(t *testing.T) {
 	w := &writer{value: ""}
 	seriesMatchResult := makeResult()
@@ -115,42 +142,21 @@ func TestRenderSeriesMatchResults(t *testing.T) {
 	expectedWhitespace := `{
 		"status":"success",
 		"data":[
-			{"a":"1","b":"1","c":"1"},
-			{"a":"1","b":"1","c":"2"},
-			{"a":"1","b":"1","c":"3"},
-			{"a":"1","b":"2","c":"1"},
-			{"a":"1","b":"2","c":"2"},
-			{"a":"1","b":"2","c":"3"},
-			{"a":"2","b":"1","c":"1"},
-			{"a":"2","b":"1","c":"2"},
-			{"a":"2","b":"1","c":"3"},
-			{"a":"2","b":"2","c":"1"},
-			{"a":"2","b":"2","c":"2"},
-			{"a":"2","b":"2","c":"3"},
-			{"a":"3","b":"1","c":"1"},
-			{"a":"3","b":"1","c":"2"},
-			{"a":"3","b":"1","c":"3"},
-			{"a":"3","b":"2","c":"1"},
-			{"a":"3","b":"2","c":"2"},
-			{"a":"3","b":"2","c":"3"}
+			{"a":"1","b":"1"},
+			{"a":"1","b":"2"},
+			{"c":"3"}
 		]
 	}`
 
 	err := RenderSeriesMatchResultsJSON(w, seriesMatchResult)
 	assert.NoError(t, err)
-	fields := strings.Fields(expectedWhitespace)
-	expected := ""
-	for _, field := range fields {
-		expected = expected + field
-	}
-
-	assert.Equal(t, expected, w.value)
+	assert.Equal(t, stripWhitespace(expectedWhitespace), w.value)
 }
 
 func TestRenderSeriesMatchResultsNoTags(t *testing.T) {
 	w := &writer{value: ""}
-	seriesMatchResult := []*storage.CompleteTagsResult{
-		&storage.CompleteTagsResult{},
+	seriesMatchResult := []*storage.SearchResults{
+		&storage.SearchResults{},
 	}
 
 	expectedWhitespace := `{
@@ -160,11 +166,75 @@ func TestRenderSeriesMatchResultsNoTags(t *testing.T) {
 
 	err := RenderSeriesMatchResultsJSON(w, seriesMatchResult)
 	assert.NoError(t, err)
-	fields := strings.Fields(expectedWhitespace)
-	expected := ""
-	for _, field := range fields {
-		expected = expected + field
+	assert.Equal(t, stripWhitespace(expectedWhitespace), w.value)
+}
+
+func TestRenderTagNamesResults(t *testing.T) {
+	w := &writer{value: ""}
+	result := &storage.CompleteTagsResult{
+		CompleteNameOnly: true,
+		CompletedTags: []storage.CompletedTag{
+			{Name: []byte("__name__")},
+			{Name: []byte("job")},
+		},
 	}
 
-	assert.Equal(t, expected, w.value)
+	err := RenderTagNamesResultsJSON(w, result)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"status":"success","data":["__name__","job"]}`, w.value)
+}
+
+func TestParseTagNamesToQueriesDefaultsToMetricName(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/labels", nil)
+	tagOptions := models.NewTagOptions()
+
+	queries, err := ParseTagNamesToQueries(req, tagOptions)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(queries))
+
+	query := queries[0]
+	assert.True(t, query.CompleteNameOnly)
+	assert.True(t, query.Start.IsZero())
+	assert.False(t, query.End.IsZero())
+	assert.Equal(t, models.Matchers{
+		models.Matcher{
+			Type:  models.MatchRegexp,
+			Name:  tagOptions.MetricName(),
+			Value: []byte(".*"),
+		},
+	}, query.TagMatchers)
+}
+
+func TestParseTagNamesToQueriesWithMatchers(t *testing.T) {
+	req, _ := http.NewRequest("GET",
+		"/labels?match[]=up&match[]=foo{job=%22bar%22}&start=100&end=200", nil)
+
+	queries, err := ParseTagNamesToQueries(req, models.NewTagOptions())
+	require.Nil(t, err)
+	require.Equal(t, 2, len(queries))
+	for _, query := range queries {
+		assert.True(t, query.CompleteNameOnly)
+		assert.Equal(t, time.Unix(100, 0), query.Start)
+		assert.Equal(t, time.Unix(200, 0), query.End)
+	}
+
+	assert.Equal(t, 1, len(queries[0].TagMatchers))
+	assert.Equal(t, 2, len(queries[1].TagMatchers))
+}
+
+func TestParseTagNamesToQueriesBadMatcher(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/labels?match[]=up{", nil)
+
+	_, err := ParseTagNamesToQueries(req, models.NewTagOptions())
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.Code())
+}
+
+func TestFetchErrorStatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusInternalServerError,
+		FetchErrorStatusCode(errors.New("fetch failed")))
+
+	err := m3.NewResourceExhaustedError(
+		errors.New("query aborted due to limit"))
+	assert.Equal(t, http.StatusBadRequest, FetchErrorStatusCode(err))
 }
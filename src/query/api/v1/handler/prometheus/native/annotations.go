@@ -0,0 +1,94 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/m3db/m3/src/query/functions/utils"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/util/json"
+	"github.com/m3db/m3/src/query/util/logging"
+
+	"go.uber.org/zap"
+)
+
+const annotationsParam = "annotations"
+
+func parseAnnotationsFlag(r *http.Request) bool {
+	var (
+		annotations bool
+		err         error
+	)
+
+	// Skip annotations if unable to parse annotations param
+	annotationsVal := r.FormValue(annotationsParam)
+	if annotationsVal != "" {
+		annotations, err = strconv.ParseBool(annotationsVal)
+		if err != nil {
+			logging.WithContext(r.Context()).Warn("unable to parse annotations flag", zap.Error(err))
+		}
+	}
+
+	return annotations
+}
+
+// newAnnotations returns the annotations to collect while executing the
+// request, nil if annotations were not requested.
+func newAnnotations(r *http.Request) *storage.Annotations {
+	if !parseAnnotationsFlag(r) {
+		return nil
+	}
+
+	return storage.NewAnnotations()
+}
+
+// renderAnnotationsJSON renders the annotated datapoints read from the series
+// with the given tags. Annotations are attached to raw datapoints so they are
+// only rendered for result series which share their tags with a fetched
+// series.
+func renderAnnotationsJSON(
+	jw *json.Writer,
+	annotations *storage.Annotations,
+	tags models.Tags,
+) {
+	if annotations == nil {
+		return
+	}
+
+	annotated := annotations.Series(tags)
+	if len(annotated) == 0 {
+		return
+	}
+
+	jw.BeginObjectField("annotations")
+	jw.BeginArray()
+	for _, dp := range annotated {
+		jw.BeginArray()
+		jw.WriteInt(int(dp.Timestamp.Unix()))
+		jw.WriteString(utils.FormatFloat(dp.Value))
+		jw.WriteString(string(dp.Annotation))
+		jw.EndArray()
+	}
+	jw.EndArray()
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/m3db/m3/src/query/errors"
 	"github.com/m3db/m3/src/query/functions/utils"
 	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/ts"
 	"github.com/m3db/m3/src/query/util"
 	"github.com/m3db/m3/src/query/util/json"
@@ -231,6 +232,7 @@ func renderResultsJSON(
 	w io.Writer,
 	series []*ts.Series,
 	params models.RequestParams,
+	annotations *storage.Annotations,
 ) {
 	jw := json.NewWriter(w)
 	jw.BeginObject()
@@ -278,6 +280,8 @@ func renderResultsJSON(
 		}
 		jw.EndArray()
 
+		renderAnnotationsJSON(jw, annotations, s.Tags)
+
 		fixedStep, ok := s.Values().(ts.FixedResolutionMutableValues)
 		if ok {
 			jw.BeginObjectField("step_size_ms")
@@ -296,6 +300,7 @@ func renderResultsJSON(
 func renderResultsInstantaneousJSON(
 	w io.Writer,
 	series []*ts.Series,
+	annotations *storage.Annotations,
 ) {
 	jw := json.NewWriter(w)
 	jw.BeginObject()
@@ -329,6 +334,8 @@ func renderResultsInstantaneousJSON(
 		jw.WriteInt(int(dp.Timestamp.Unix()))
 		jw.WriteString(utils.FormatFloat(dp.Value))
 		jw.EndArray()
+
+		renderAnnotationsJSON(jw, annotations, s.Tags)
 		jw.EndObject()
 	}
 	jw.EndArray()
@@ -343,6 +350,7 @@ func renderM3QLResultsJSON(
 	w io.Writer,
 	series []*ts.Series,
 	params models.RequestParams,
+	annotations *storage.Annotations,
 ) {
 	jw := json.NewWriter(w)
 	jw.BeginArray()
@@ -373,6 +381,8 @@ func renderM3QLResultsJSON(
 		}
 		jw.EndArray()
 
+		renderAnnotationsJSON(jw, annotations, s.Tags)
+
 		jw.BeginObjectField("step_size_ms")
 		jw.WriteInt(int(params.Step.Seconds() * 1000))
 
@@ -30,6 +30,7 @@ import (
 
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
 	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/test"
 	"github.com/m3db/m3/src/query/ts"
 	"github.com/m3db/m3/src/query/util/logging"
@@ -171,7 +172,7 @@ func TestRenderResultsJSON(t *testing.T) {
 			})),
 	}
 
-	renderResultsJSON(buffer, series, params)
+	renderResultsJSON(buffer, series, params, nil)
 
 	expected := mustPrettyJSON(t, `
 	{
@@ -221,6 +222,82 @@ func TestRenderResultsJSON(t *testing.T) {
 	assert.Equal(t, expected, actual, xtest.Diff(expected, actual))
 }
 
+func TestRenderResultsJSONWithAnnotations(t *testing.T) {
+	start := time.Unix(1535948880, 0)
+	buffer := bytes.NewBuffer(nil)
+	params := models.RequestParams{}
+	series := []*ts.Series{
+		ts.NewSeries([]byte("foo"),
+			ts.NewFixedStepValues(10*time.Second, 1, 1, start), test.TagSliceToTags([]models.Tag{
+				models.Tag{Name: []byte("bar"), Value: []byte("baz")},
+				models.Tag{Name: []byte("qux"), Value: []byte("qaz")},
+			})),
+		ts.NewSeries([]byte("bar"),
+			ts.NewFixedStepValues(10*time.Second, 1, 2, start), test.TagSliceToTags([]models.Tag{
+				models.Tag{Name: []byte("baz"), Value: []byte("bar")},
+			})),
+	}
+
+	// Annotations are matched to series regardless of tag order.
+	annotated := test.TagSliceToTags([]models.Tag{
+		models.Tag{Name: []byte("qux"), Value: []byte("qaz")},
+		models.Tag{Name: []byte("bar"), Value: []byte("baz")},
+	})
+	annotations := storage.NewAnnotations()
+	annotations.Add(annotated, storage.AnnotatedDatapoint{
+		Timestamp:  start.Add(-time.Second),
+		Value:      1,
+		Annotation: []byte("trace-id"),
+	})
+
+	renderResultsJSON(buffer, series, params, annotations)
+
+	expected := mustPrettyJSON(t, `
+	{
+		"status": "success",
+		"data": {
+			"resultType": "matrix",
+			"result": [
+				{
+					"metric": {
+						"bar": "baz",
+						"qux": "qaz"
+					},
+					"values": [
+						[
+							1535948880,
+							"1"
+						]
+					],
+					"annotations": [
+						[
+							1535948879,
+							"1",
+							"trace-id"
+						]
+					],
+					"step_size_ms": 10000
+				},
+				{
+					"metric": {
+						"baz": "bar"
+					},
+					"values": [
+						[
+							1535948880,
+							"2"
+						]
+					],
+					"step_size_ms": 10000
+				}
+			]
+		}
+	}
+	`)
+	actual := mustPrettyJSON(t, buffer.String())
+	assert.Equal(t, expected, actual, xtest.Diff(expected, actual))
+}
+
 func TestRenderInstantaneousResultsJSON(t *testing.T) {
 	start := time.Unix(1535948880, 0)
 	buffer := bytes.NewBuffer(nil)
@@ -237,7 +314,7 @@ func TestRenderInstantaneousResultsJSON(t *testing.T) {
 			})),
 	}
 
-	renderResultsInstantaneousJSON(buffer, series)
+	renderResultsInstantaneousJSON(buffer, series, nil)
 
 	expected := mustPrettyJSON(t, `
 	{
@@ -281,3 +358,52 @@ func mustPrettyJSON(t *testing.T, str string) string {
 	require.NoError(t, err)
 	return string(pretty)
 }
+
+func TestRenderM3QLResultsJSONWithAnnotations(t *testing.T) {
+	start := time.Unix(1535948880, 0)
+	buffer := bytes.NewBuffer(nil)
+	params := models.RequestParams{Step: 10 * time.Second}
+	tags := test.TagSliceToTags([]models.Tag{
+		models.Tag{Name: []byte("bar"), Value: []byte("baz")},
+	})
+	series := []*ts.Series{
+		ts.NewSeries([]byte("foo"),
+			ts.NewFixedStepValues(10*time.Second, 1, 1, start), tags),
+	}
+
+	annotations := storage.NewAnnotations()
+	annotations.Add(tags, storage.AnnotatedDatapoint{
+		Timestamp:  start.Add(-time.Second),
+		Value:      1,
+		Annotation: []byte("trace-id"),
+	})
+
+	renderM3QLResultsJSON(buffer, series, params, annotations)
+
+	expected := mustPrettyJSON(t, `
+	[
+		{
+			"target": "foo",
+			"tags": {
+				"bar": "baz"
+			},
+			"datapoints": [
+				[
+					1,
+					1535948880
+				]
+			],
+			"annotations": [
+				[
+					1535948879,
+					"1",
+					"trace-id"
+				]
+			],
+			"step_size_ms": 10000
+		}
+	]
+	`)
+	actual := mustPrettyJSON(t, buffer.String())
+	assert.Equal(t, expected, actual, xtest.Diff(expected, actual))
+}
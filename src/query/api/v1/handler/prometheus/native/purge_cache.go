@@ -0,0 +1,54 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"net/http"
+
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/cache"
+)
+
+const (
+	// PurgeCacheURL is the url for purging the results cache.
+	PurgeCacheURL = handler.RoutePrefixV1 + "/cache/purge"
+
+	// PurgeCacheHTTPMethod is the HTTP method used with this resource.
+	PurgeCacheHTTPMethod = http.MethodPost
+)
+
+// PurgeCacheHandler represents a handler for purging the results cache, such
+// as after backfilling or deleting data in time ranges that may be cached.
+type PurgeCacheHandler struct {
+	resultsCache cache.ResultsCache
+}
+
+// NewPurgeCacheHandler returns a new instance of handler.
+func NewPurgeCacheHandler(resultsCache cache.ResultsCache) http.Handler {
+	return &PurgeCacheHandler{
+		resultsCache: resultsCache,
+	}
+}
+
+func (h *PurgeCacheHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.resultsCache.Purge()
+	w.WriteHeader(http.StatusOK)
+}
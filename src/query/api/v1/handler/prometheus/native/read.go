@@ -24,13 +24,16 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/m3db/m3/src/cmd/services/m3query/config"
 	"github.com/m3db/m3/src/query/api/v1/handler"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
 	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/cache"
 	"github.com/m3db/m3/src/query/executor"
 	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/ts"
 	"github.com/m3db/m3/src/query/util/logging"
 	xhttp "github.com/m3db/m3/src/x/net/http"
@@ -63,6 +66,7 @@ type PromReadHandler struct {
 	limitsCfg       *config.LimitsConfiguration
 	promReadMetrics promReadMetrics
 	timeoutOps      *prometheus.TimeoutOpts
+	resultsCache    cache.ResultsCache
 }
 
 type promReadMetrics struct {
@@ -99,13 +103,15 @@ type RespError struct {
 	Code int
 }
 
-// NewPromReadHandler returns a new instance of handler.
+// NewPromReadHandler returns a new instance of handler, the results cache
+// is optional and results are not cached if it is nil.
 func NewPromReadHandler(
 	engine *executor.Engine,
 	tagOpts models.TagOptions,
 	limitsCfg *config.LimitsConfiguration,
 	scope tally.Scope,
 	timeoutOpts *prometheus.TimeoutOpts,
+	resultsCache cache.ResultsCache,
 ) *PromReadHandler {
 	h := &PromReadHandler{
 		engine:          engine,
@@ -113,6 +119,7 @@ func NewPromReadHandler(
 		limitsCfg:       limitsCfg,
 		promReadMetrics: newPromReadMetrics(scope),
 		timeoutOps:      timeoutOpts,
+		resultsCache:    resultsCache,
 	}
 
 	h.promReadMetrics.maxDatapoints.Update(float64(limitsCfg.MaxComputedDatapoints))
@@ -122,7 +129,9 @@ func NewPromReadHandler(
 func (h *PromReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	timer := h.promReadMetrics.fetchTimerSuccess.Start()
 
-	result, params, respErr := h.ServeHTTPWithEngine(w, r, h.engine)
+	annotations := newAnnotations(r)
+	result, params, respErr := h.serveHTTPWithEngine(w, r, h.engine,
+		h.resultsCache, annotations)
 	if respErr != nil {
 		xhttp.Error(w, respErr.Err, respErr.Code)
 		return
@@ -130,7 +139,7 @@ func (h *PromReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if params.FormatType == models.FormatM3QL {
-		renderM3QLResultsJSON(w, result, params)
+		renderM3QLResultsJSON(w, result, params, annotations)
 		h.promReadMetrics.fetchSuccess.Inc(1)
 		timer.Stop()
 		return
@@ -139,13 +148,25 @@ func (h *PromReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.promReadMetrics.fetchSuccess.Inc(1)
 	timer.Stop()
 	// TODO: Support multiple result types
-	renderResultsJSON(w, result, params)
+	renderResultsJSON(w, result, params, annotations)
 }
 
 // ServeHTTPWithEngine returns query results from the storage
 func (h *PromReadHandler) ServeHTTPWithEngine(
 	w http.ResponseWriter,
 	r *http.Request, engine *executor.Engine,
+) ([]*ts.Series, models.RequestParams, *RespError) {
+	// NB: results are only cached for the handler's own engine since other
+	// engines may be backed by different storage.
+	return h.serveHTTPWithEngine(w, r, engine, nil, nil)
+}
+
+func (h *PromReadHandler) serveHTTPWithEngine(
+	w http.ResponseWriter,
+	r *http.Request,
+	engine *executor.Engine,
+	resultsCache cache.ResultsCache,
+	annotations *storage.Annotations,
 ) ([]*ts.Series, models.RequestParams, *RespError) {
 	ctx := context.WithValue(r.Context(), handler.HeaderKey, r.Header)
 	logger := logging.WithContext(ctx)
@@ -165,16 +186,43 @@ func (h *PromReadHandler) ServeHTTPWithEngine(
 		return nil, emptyReqParams, &RespError{Err: err, Code: http.StatusBadRequest}
 	}
 
-	result, err := read(ctx, engine, h.tagOpts, w, params)
+	execute := func(params models.RequestParams) ([]*ts.Series, error) {
+		return read(ctx, engine, h.tagOpts, w, params, annotations)
+	}
+
+	var (
+		result []*ts.Series
+		err    error
+	)
+
+	// NB: Cached results do not carry the annotations of the datapoints
+	// they were computed from, so annotated requests skip the cache.
+	if resultsCache != nil && annotations == nil && !noCache(r) {
+		result, err = resultsCache.Execute(params, execute)
+	} else {
+		result, err = execute(params)
+	}
+
 	if err != nil {
 		logger.Error("unable to fetch data", zap.Error(err))
-		h.promReadMetrics.fetchErrorsServer.Inc(1)
-		return nil, emptyReqParams, &RespError{Err: err, Code: http.StatusInternalServerError}
+		code := prometheus.FetchErrorStatusCode(err)
+		if code == http.StatusBadRequest {
+			h.promReadMetrics.fetchErrorsClient.Inc(1)
+		} else {
+			h.promReadMetrics.fetchErrorsServer.Inc(1)
+		}
+		return nil, emptyReqParams, &RespError{Err: err, Code: code}
 	}
 
 	return result, params, nil
 }
 
+// noCache returns whether the request asks to be executed without serving
+// results from the results cache, such as to read data just backfilled.
+func noCache(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Cache-Control"), "no-cache")
+}
+
 func (h *PromReadHandler) validateRequest(params *models.RequestParams) error {
 	// Impose a rough limit on the number of returned time series. This is intended to prevent things like
 	// querying from the beginning of time with a 1s step size.
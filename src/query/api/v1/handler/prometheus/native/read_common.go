@@ -32,6 +32,7 @@ import (
 	"github.com/m3db/m3/src/query/executor"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/parser/promql"
+	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/ts"
 )
 
@@ -41,11 +42,14 @@ func read(
 	tagOpts models.TagOptions,
 	w http.ResponseWriter,
 	params models.RequestParams,
+	annotations *storage.Annotations,
 ) ([]*ts.Series, error) {
 	ctx, cancel := context.WithTimeout(reqCtx, params.Timeout)
 	defer cancel()
 
-	opts := &executor.EngineOptions{}
+	opts := &executor.EngineOptions{
+		Annotations: annotations,
+	}
 	// Detect clients closing connections
 	handler.CloseWatcher(ctx, cancel, w)
 
@@ -77,7 +77,8 @@ func (h *PromReadInstantHandler) ServeHTTP(w http.ResponseWriter, r *http.Reques
 		logger.Info("Request params", zap.Any("params", params))
 	}
 
-	result, err := read(ctx, h.engine, h.tagOpts, w, params)
+	annotations := newAnnotations(r)
+	result, err := read(ctx, h.engine, h.tagOpts, w, params, annotations)
 	if err != nil {
 		logger.Error("unable to fetch data", zap.Error(err))
 		xhttp.Error(w, err, http.StatusBadRequest)
@@ -86,5 +87,5 @@ func (h *PromReadInstantHandler) ServeHTTP(w http.ResponseWriter, r *http.Reques
 
 	// TODO: Support multiple result types
 	w.Header().Set("Content-Type", "application/json")
-	renderResultsInstantaneousJSON(w, result)
+	renderResultsInstantaneousJSON(w, result, annotations)
 }
@@ -60,7 +60,7 @@ func TestPromReadHandler_Read(t *testing.T) {
 	r, parseErr := parseParams(req, timeoutOpts)
 	require.Nil(t, parseErr)
 	assert.Equal(t, models.FormatPromQL, r.FormatType)
-	seriesList, err := read(context.TODO(), promRead.engine, promRead.tagOpts, httptest.NewRecorder(), r)
+	seriesList, err := read(context.TODO(), promRead.engine, promRead.tagOpts, httptest.NewRecorder(), r, nil)
 	require.NoError(t, err)
 	require.Len(t, seriesList, 2)
 	s := seriesList[0]
@@ -126,11 +126,12 @@ func newTestSetup() *testSetup {
 	return &testSetup{
 		Storage: mockStorage,
 		Handler: NewPromReadHandler(
-			executor.NewEngine(mockStorage, tally.NewTestScope("test", nil), time.Minute),
+			executor.NewEngine(mockStorage, tally.NewTestScope("test", nil), time.Minute, 0),
 			models.NewTagOptions(),
 			&config.LimitsConfiguration{},
 			tally.NewTestScope("", nil),
 			timeoutOpts,
+			nil,
 		),
 	}
 }
@@ -260,3 +261,11 @@ func TestPromReadHandler_validateRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestNoCache(t *testing.T) {
+	req := httptest.NewRequest(PromReadHTTPMethod, PromReadURL, nil)
+	assert.False(t, noCache(req))
+
+	req.Header.Set("Cache-Control", "max-age=0, no-cache")
+	assert.True(t, noCache(req))
+}
@@ -65,31 +65,19 @@ func (h *PromSeriesMatchHandler) ServeHTTP(w http.ResponseWriter, r *http.Reques
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	query, err := prometheus.ParseSeriesMatchQuery(r, h.tagOptions)
-	if err != nil {
-		logger.Error("unable to parse series match values to query", zap.Error(err))
-		xhttp.Error(w, err, http.StatusBadRequest)
+	query, rErr := prometheus.ParseSeriesMatchQuery(r, h.tagOptions)
+	if rErr != nil {
+		logger.Error("unable to parse series match values to query", zap.Error(rErr))
+		xhttp.Error(w, rErr.Inner(), rErr.Code())
 		return
 	}
 
 	opts := storage.NewFetchOptions()
-	matchers := query.TagMatchers
-	results := make([]*storage.CompleteTagsResult, len(matchers))
-	// TODO: parallel execution
-	for i, matcher := range matchers {
-		completeTagsQuery := &storage.CompleteTagsQuery{
-			CompleteNameOnly: false,
-			TagMatchers:      matcher,
-		}
-
-		result, err := h.storage.CompleteTags(ctx, completeTagsQuery, opts)
-		if err != nil {
-			logger.Error("unable to get matched series", zap.Error(err))
-			xhttp.Error(w, err, http.StatusBadRequest)
-			return
-		}
-
-		results[i] = result
+	results, err := prometheus.FetchTags(ctx, h.storage, query, opts)
+	if err != nil {
+		logger.Error("unable to get matched series", zap.Error(err))
+		xhttp.Error(w, err, http.StatusBadRequest)
+		return
 	}
 
 	// TODO: Support multiple result types
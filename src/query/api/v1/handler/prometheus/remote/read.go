@@ -98,9 +98,14 @@ func (h *PromReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	result, err := h.read(ctx, w, req, timeout)
 	if err != nil {
-		h.promReadMetrics.fetchErrorsServer.Inc(1)
+		code := prometheus.FetchErrorStatusCode(err)
+		if code == http.StatusBadRequest {
+			h.promReadMetrics.fetchErrorsClient.Inc(1)
+		} else {
+			h.promReadMetrics.fetchErrorsServer.Inc(1)
+		}
 		logger.Error("unable to fetch data", zap.Any("error", err))
-		xhttp.Error(w, err, http.StatusInternalServerError)
+		xhttp.Error(w, err, code)
 		return
 	}
 
@@ -179,6 +184,10 @@ func (h *PromReadHandler) read(
 			return nil, result.Err
 		}
 
+		if result.FetchResult.Metadata.Stitched() {
+			handler.AddResolutionsHeader(w, result.FetchResult.Metadata)
+		}
+
 		promRes := storage.FetchResultToPromResult(result.FetchResult)
 		promResults = append(promResults, promRes)
 	}
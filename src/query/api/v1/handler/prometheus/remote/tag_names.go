@@ -0,0 +1,86 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/util/logging"
+	"github.com/m3db/m3/src/x/net/http"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// TagNamesURL is the url for tag names.
+	TagNamesURL = handler.RoutePrefixV1 + "/labels"
+
+	// TagNamesHTTPMethod is the HTTP method used with this resource.
+	TagNamesHTTPMethod = http.MethodGet
+)
+
+// TagNamesHandler represents a handler for the tag names endpoint.
+type TagNamesHandler struct {
+	storage    storage.Storage
+	tagOptions models.TagOptions
+}
+
+// NewTagNamesHandler returns a new instance of handler.
+func NewTagNamesHandler(
+	storage storage.Storage,
+	tagOptions models.TagOptions,
+) http.Handler {
+	return &TagNamesHandler{
+		storage:    storage,
+		tagOptions: tagOptions,
+	}
+}
+
+func (h *TagNamesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := context.WithValue(r.Context(), handler.HeaderKey, r.Header)
+	logger := logging.WithContext(ctx)
+	w.Header().Set("Content-Type", "application/json")
+
+	queries, rErr := prometheus.ParseTagNamesToQueries(r, h.tagOptions)
+	if rErr != nil {
+		logger.Error("unable to parse tag names to query", zap.Error(rErr))
+		xhttp.Error(w, rErr.Inner(), rErr.Code())
+		return
+	}
+
+	opts := storage.NewFetchOptions()
+	result, err := prometheus.CompleteTags(ctx, h.storage, queries, opts)
+	if err != nil {
+		logger.Error("unable to get tag names", zap.Error(err))
+		xhttp.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := prometheus.RenderTagNamesResultsJSON(w, result); err != nil {
+		logger.Error("unable to render tag names", zap.Error(err))
+		xhttp.Error(w, err, http.StatusBadRequest)
+	}
+}
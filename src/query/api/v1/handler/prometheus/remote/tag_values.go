@@ -26,6 +26,7 @@ import (
 
 	"github.com/m3db/m3/src/query/api/v1/handler"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
+	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/util/logging"
 	"github.com/m3db/m3/src/x/net/http"
@@ -44,7 +45,8 @@ const (
 
 // TagValuesHandler represents a handler for search tags endpoint.
 type TagValuesHandler struct {
-	storage storage.Storage
+	storage    storage.Storage
+	tagOptions models.TagOptions
 }
 
 // TagValuesResponse is the response that gets returned to the user
@@ -55,9 +57,11 @@ type TagValuesResponse struct {
 // NewTagValuesHandler returns a new instance of handler.
 func NewTagValuesHandler(
 	storage storage.Storage,
+	tagOptions models.TagOptions,
 ) http.Handler {
 	return &TagValuesHandler{
-		storage: storage,
+		storage:    storage,
+		tagOptions: tagOptions,
 	}
 }
 
@@ -66,15 +70,15 @@ func (h *TagValuesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	logger := logging.WithContext(ctx)
 	w.Header().Set("Content-Type", "application/json")
 
-	query, err := prometheus.ParseTagValuesToQuery(r)
-	if err != nil {
-		logger.Error("unable to parse tag values to query", zap.Error(err))
-		xhttp.Error(w, err, http.StatusBadRequest)
+	queries, rErr := prometheus.ParseTagValuesToQueries(r, h.tagOptions)
+	if rErr != nil {
+		logger.Error("unable to parse tag values to query", zap.Error(rErr))
+		xhttp.Error(w, rErr.Inner(), rErr.Code())
 		return
 	}
 
 	opts := storage.NewFetchOptions()
-	result, err := h.storage.CompleteTags(ctx, query, opts)
+	result, err := prometheus.CompleteTags(ctx, h.storage, queries, opts)
 	if err != nil {
 		logger.Error("unable to get tag values", zap.Error(err))
 		xhttp.Error(w, err, http.StatusBadRequest)
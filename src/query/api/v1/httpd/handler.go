@@ -43,6 +43,7 @@ import (
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/remote"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/validator"
 	"github.com/m3db/m3/src/query/api/v1/handler/topic"
+	"github.com/m3db/m3/src/query/cache"
 	"github.com/m3db/m3/src/query/executor"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/storage"
@@ -50,6 +51,7 @@ import (
 	"github.com/m3db/m3/src/query/util/logging"
 	xhttp "github.com/m3db/m3/src/x/net/http"
 	"github.com/m3db/m3/src/x/net/http/cors"
+	"github.com/m3db/m3x/instrument"
 
 	"github.com/gorilla/mux"
 	"github.com/uber-go/tally"
@@ -159,12 +161,22 @@ func (h *Handler) RegisterRoutes() error {
 		return err
 	}
 
+	var resultsCache cache.ResultsCache
+	if cfg := h.config.ResultsCache; cfg != nil {
+		iOpts := instrument.NewOptions().SetMetricsScope(h.scope)
+		resultsCache, err = cfg.NewResultsCache(iOpts)
+		if err != nil {
+			return err
+		}
+	}
+
 	nativePromReadHandler := native.NewPromReadHandler(
 		h.engine,
 		h.tagOptions,
 		&h.config.Limits,
 		h.scope.Tagged(nativeSource),
 		h.timeoutOpts,
+		resultsCache,
 	)
 
 	h.router.HandleFunc(remote.PromReadURL,
@@ -176,6 +188,11 @@ func (h *Handler) RegisterRoutes() error {
 	h.router.HandleFunc(native.PromReadURL,
 		logged(nativePromReadHandler).ServeHTTP,
 	).Methods(native.PromReadHTTPMethod)
+	if resultsCache != nil {
+		h.router.HandleFunc(native.PurgeCacheURL,
+			logged(native.NewPurgeCacheHandler(resultsCache)).ServeHTTP,
+		).Methods(native.PurgeCacheHTTPMethod)
+	}
 	h.router.HandleFunc(native.PromReadInstantURL,
 		logged(native.NewPromReadInstantHandler(h.engine, h.tagOptions, h.timeoutOpts)).ServeHTTP,
 	).Methods(native.PromReadInstantHTTPMethod)
@@ -192,8 +209,11 @@ func (h *Handler) RegisterRoutes() error {
 	h.router.HandleFunc(native.CompleteTagsURL,
 		logged(native.NewCompleteTagsHandler(h.storage)).ServeHTTP,
 	).Methods(native.CompleteTagsHTTPMethod)
+	h.router.HandleFunc(remote.TagNamesURL,
+		logged(remote.NewTagNamesHandler(h.storage, h.tagOptions)).ServeHTTP,
+	).Methods(remote.TagNamesHTTPMethod)
 	h.router.HandleFunc(remote.TagValuesURL,
-		logged(remote.NewTagValuesHandler(h.storage)).ServeHTTP,
+		logged(remote.NewTagValuesHandler(h.storage, h.tagOptions)).ServeHTTP,
 	).Methods(remote.TagValuesHTTPMethod)
 
 	// Series match endpoints
@@ -215,6 +235,13 @@ func (h *Handler) RegisterRoutes() error {
 		logged(graphite.NewFindHandler(h.storage)).ServeHTTP,
 	).Methods(graphite.FindHTTPMethods...)
 
+	// Series deletion endpoints
+	if h.clusters != nil {
+		h.router.HandleFunc(database.DeleteSeriesURL,
+			logged(database.NewDeleteSeriesHandler(h.clusters, h.tagOptions)).ServeHTTP,
+		).Methods(database.DeleteSeriesHTTPMethod)
+	}
+
 	if h.clusterClient != nil {
 		placementOpts := placement.HandlerOptions{
 			ClusterClient:       h.clusterClient,
@@ -167,7 +167,33 @@ type Builder interface {
 
 // Result is the result from a block query.
 type Result struct {
-	Blocks []Block
+	Blocks   []Block
+	Metadata ResultMetadata
+}
+
+// ResultMetadata is metadata describing how a fetch result was served.
+type ResultMetadata struct {
+	// Resolutions are the time ranges of a result stitched together from
+	// several namespaces, in ascending time order, along with the namespace
+	// and resolution that served each. Empty if the result was not stitched.
+	Resolutions []ResolutionSegment
+}
+
+// ResolutionSegment is a time range of a result served by a single namespace.
+type ResolutionSegment struct {
+	// Start is the inclusive start of the segment.
+	Start time.Time
+	// End is the exclusive end of the segment.
+	End time.Time
+	// Namespace is the namespace that served the segment.
+	Namespace string
+	// Resolution is the resolution of the namespace, zero if unaggregated.
+	Resolution time.Duration
+}
+
+// Stitched returns true if the result was stitched from several namespaces.
+func (m ResultMetadata) Stitched() bool {
+	return len(m.Resolutions) > 1
 }
 
 // ConsolidationFunc consolidates a bunch of datapoints into a single float value.
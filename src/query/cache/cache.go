@@ -0,0 +1,322 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+
+	"github.com/prometheus/prometheus/promql"
+	"github.com/uber-go/tally"
+)
+
+type resultsCacheMetrics struct {
+	hits     tally.Counter
+	misses   tally.Counter
+	bypassed tally.Counter
+	purges   tally.Counter
+}
+
+func newResultsCacheMetrics(scope tally.Scope) resultsCacheMetrics {
+	return resultsCacheMetrics{
+		hits:     scope.Counter("hits"),
+		misses:   scope.Counter("misses"),
+		bypassed: scope.Counter("bypassed"),
+		purges:   scope.Counter("purges"),
+	}
+}
+
+type resultsCache struct {
+	backend      Backend
+	bucketSize   time.Duration
+	recentWindow time.Duration
+	entryTTL     time.Duration
+	metrics      resultsCacheMetrics
+}
+
+// NewResultsCache creates a new results cache.
+func NewResultsCache(opts Options) (ResultsCache, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	scope := opts.InstrumentOptions().MetricsScope().SubScope("results-cache")
+	return &resultsCache{
+		backend:      opts.Backend(),
+		bucketSize:   opts.BucketSize(),
+		recentWindow: opts.RecentWindow(),
+		entryTTL:     opts.EntryTTL(),
+		metrics:      newResultsCacheMetrics(scope),
+	}, nil
+}
+
+// segment is a step aligned range [start, end) of a query, segments which
+// cover a complete bucket that can be cached have a key set.
+type segment struct {
+	start time.Time
+	end   time.Time
+	key   string
+}
+
+// part is the series computed for a step aligned range of a query.
+type part struct {
+	start  time.Time
+	series []*ts.Series
+}
+
+func (c *resultsCache) Execute(
+	params models.RequestParams,
+	fn ExecuteFn,
+) ([]*ts.Series, error) {
+	segments := c.segments(params)
+	if len(segments) == 0 {
+		c.metrics.bypassed.Inc(1)
+		return fn(params)
+	}
+
+	var (
+		step    = params.Step
+		parts   = make([]part, 0, len(segments))
+		pending []segment
+	)
+
+	// Execute each run of consecutive segments not found in the cache as a
+	// single query, storing the buckets it covered once it completes.
+	executePending := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+
+		run := params
+		run.Start = pending[0].start
+		run.End = pending[len(pending)-1].end
+		run.IncludeEnd = false
+
+		series, err := fn(run)
+		if err != nil {
+			return err
+		}
+
+		for _, seg := range pending {
+			if seg.key != "" {
+				sliced := sliceSeries(series, run.Start, seg, step)
+				c.backend.Set(seg.key, sliced, c.entryTTL)
+			}
+		}
+
+		parts = append(parts, part{start: run.Start, series: series})
+		pending = pending[:0]
+		return nil
+	}
+
+	for _, seg := range segments {
+		if seg.key != "" {
+			if series, ok := c.backend.Get(seg.key); ok {
+				c.metrics.hits.Inc(1)
+				if err := executePending(); err != nil {
+					return nil, err
+				}
+
+				parts = append(parts, part{start: seg.start, series: series})
+				continue
+			}
+
+			c.metrics.misses.Inc(1)
+		}
+
+		pending = append(pending, seg)
+	}
+
+	if err := executePending(); err != nil {
+		return nil, err
+	}
+
+	start := segments[0].start
+	numSteps := int(segments[len(segments)-1].end.Sub(start) / step)
+	return mergeParts(parts, start, step, numSteps), nil
+}
+
+func (c *resultsCache) Purge() {
+	c.backend.Purge()
+	c.metrics.purges.Inc(1)
+}
+
+// segments splits the query into segments, returning none if the query
+// cannot be served from the cache.
+func (c *resultsCache) segments(params models.RequestParams) []segment {
+	step := params.Step
+	if step <= 0 || params.Start.UnixNano()%int64(step) != 0 {
+		// NB: queries not aligned to their step evaluate at different times
+		// to the bucket boundaries so cannot reuse cached buckets.
+		return nil
+	}
+
+	size := c.bucketSize / step * step
+	if size == 0 {
+		size = step
+	}
+
+	var (
+		start        = params.Start
+		end          = params.ExclusiveEnd()
+		cacheableEnd = params.Now.Add(-c.recentWindow)
+	)
+
+	if end.Before(cacheableEnd) {
+		cacheableEnd = end
+	}
+
+	firstBucket := time.Unix(0, start.UnixNano()/int64(size)*int64(size))
+	if firstBucket.Before(start) {
+		firstBucket = firstBucket.Add(size)
+	}
+
+	var (
+		query    = normalizeQuery(params.Query)
+		segments []segment
+	)
+
+	for bucket := firstBucket; !bucket.Add(size).After(cacheableEnd); bucket = bucket.Add(size) {
+		segments = append(segments, segment{
+			start: bucket,
+			end:   bucket.Add(size),
+			key:   fmt.Sprintf("%s;%d;%d;%d", query, step, size, bucket.UnixNano()),
+		})
+	}
+
+	if len(segments) == 0 {
+		return nil
+	}
+
+	if start.Before(firstBucket) {
+		segments = append([]segment{{start: start, end: firstBucket}}, segments...)
+	}
+
+	if last := segments[len(segments)-1].end; last.Before(end) {
+		segments = append(segments, segment{start: last, end: end})
+	}
+
+	return segments
+}
+
+// normalizeQuery returns the query in a canonical form so that queries that
+// only differ by formatting share cached results.
+func normalizeQuery(query string) string {
+	expr, err := promql.ParseExpr(query)
+	if err != nil {
+		return query
+	}
+
+	return expr.String()
+}
+
+// sliceSeries returns the values of the series that fall within the segment,
+// series without any values in the segment are omitted.
+func sliceSeries(
+	series []*ts.Series,
+	start time.Time,
+	seg segment,
+	step time.Duration,
+) []*ts.Series {
+	var (
+		offset   = int(seg.start.Sub(start) / step)
+		numSteps = int(seg.end.Sub(seg.start) / step)
+		sliced   = make([]*ts.Series, 0, len(series))
+	)
+
+	for _, s := range series {
+		var (
+			values    = s.Values()
+			segValues = ts.NewFixedStepValues(step, numSteps, math.NaN(), seg.start)
+			hasValue  = false
+		)
+
+		for i := 0; i < numSteps && offset+i < values.Len(); i++ {
+			v := values.ValueAt(offset + i)
+			if !math.IsNaN(v) {
+				hasValue = true
+			}
+
+			segValues.SetValueAt(i, v)
+		}
+
+		if hasValue {
+			sliced = append(sliced, ts.NewSeries(s.Name(), segValues, s.Tags))
+		}
+	}
+
+	return sliced
+}
+
+// mergeParts combines the series of each part into series covering the
+// whole query, steps not covered by a part for a series are left empty.
+func mergeParts(
+	parts []part,
+	start time.Time,
+	step time.Duration,
+	numSteps int,
+) []*ts.Series {
+	var (
+		merged  = make([]*ts.Series, 0)
+		values  = make([]ts.FixedResolutionMutableValues, 0)
+		indexes = make(map[string]int)
+	)
+
+	for _, p := range parts {
+		offset := int(p.start.Sub(start) / step)
+		for _, s := range p.series {
+			id := seriesID(s)
+			idx, ok := indexes[id]
+			if !ok {
+				idx = len(merged)
+				indexes[id] = idx
+				vals := ts.NewFixedStepValues(step, numSteps, math.NaN(), start)
+				values = append(values, vals)
+				merged = append(merged, ts.NewSeries(s.Name(), vals, s.Tags))
+			}
+
+			partValues := s.Values()
+			for i := 0; i < partValues.Len() && offset+i < numSteps; i++ {
+				values[idx].SetValueAt(offset+i, partValues.ValueAt(i))
+			}
+		}
+	}
+
+	return merged
+}
+
+func seriesID(s *ts.Series) string {
+	var buf bytes.Buffer
+	buf.Write(s.Name())
+	for _, tag := range s.Tags.Tags {
+		buf.WriteByte(0)
+		buf.Write(tag.Name)
+		buf.WriteByte(0)
+		buf.Write(tag.Value)
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testStart = time.Unix(0, 0).Add(1000 * time.Hour)
+
+type executeCall struct {
+	start time.Time
+	end   time.Time
+}
+
+// testExecuteFn returns a single series with the value at each step set to
+// the number of minutes since the test start time.
+func testExecuteFn(calls *[]executeCall) ExecuteFn {
+	return func(params models.RequestParams) ([]*ts.Series, error) {
+		end := params.ExclusiveEnd()
+		*calls = append(*calls, executeCall{start: params.Start, end: end})
+
+		numSteps := int(end.Sub(params.Start) / params.Step)
+		values := ts.NewFixedStepValues(params.Step, numSteps, math.NaN(), params.Start)
+		for i := 0; i < numSteps; i++ {
+			t := params.Start.Add(time.Duration(i) * params.Step)
+			values.SetValueAt(i, t.Sub(testStart).Minutes())
+		}
+
+		tags := models.Tags{Tags: []models.Tag{
+			{Name: []byte("foo"), Value: []byte("bar")},
+		}}
+		return []*ts.Series{ts.NewSeries([]byte("foo"), values, tags)}, nil
+	}
+}
+
+func newTestResultsCache(t *testing.T) ResultsCache {
+	opts := NewOptions().
+		SetBucketSize(10 * time.Minute).
+		SetRecentWindow(5 * time.Minute)
+	cache, err := NewResultsCache(opts)
+	require.NoError(t, err)
+	return cache
+}
+
+func newTestParams() models.RequestParams {
+	return models.RequestParams{
+		Start: testStart.Add(5 * time.Minute),
+		End:   testStart.Add(50 * time.Minute),
+		Now:   testStart.Add(50 * time.Minute),
+		Step:  time.Minute,
+		Query: "sum(rate(foo[1m]))",
+	}
+}
+
+func requireSeriesValues(
+	t *testing.T,
+	params models.RequestParams,
+	series []*ts.Series,
+) {
+	require.Equal(t, 1, len(series))
+	values := series[0].Values()
+	require.Equal(t, int(params.End.Sub(params.Start)/params.Step), values.Len())
+	for i := 0; i < values.Len(); i++ {
+		stepTime := params.Start.Add(time.Duration(i) * params.Step)
+		require.Equal(t, stepTime.Sub(testStart).Minutes(), values.ValueAt(i))
+	}
+}
+
+func TestResultsCacheExecute(t *testing.T) {
+	var (
+		cache  = newTestResultsCache(t)
+		params = newTestParams()
+		calls  []executeCall
+	)
+
+	series, err := cache.Execute(params, testExecuteFn(&calls))
+	require.NoError(t, err)
+	requireSeriesValues(t, params, series)
+	assert.Equal(t, []executeCall{
+		{start: params.Start, end: params.End},
+	}, calls)
+
+	// Buckets ending before the recent window should now be served from the
+	// cache, leaving only the head and live tail to execute.
+	calls = nil
+	params.Query = "sum( rate(foo[1m]) )"
+	series, err = cache.Execute(params, testExecuteFn(&calls))
+	require.NoError(t, err)
+	requireSeriesValues(t, params, series)
+	require.Equal(t, 2, len(calls))
+	assert.True(t, calls[0].start.Equal(params.Start))
+	assert.True(t, calls[0].end.Equal(testStart.Add(10*time.Minute)))
+	assert.True(t, calls[1].start.Equal(testStart.Add(40*time.Minute)))
+	assert.True(t, calls[1].end.Equal(params.End))
+}
+
+func TestResultsCacheExecuteDifferentStepMisses(t *testing.T) {
+	var (
+		cache  = newTestResultsCache(t)
+		params = newTestParams()
+		calls  []executeCall
+	)
+
+	_, err := cache.Execute(params, testExecuteFn(&calls))
+	require.NoError(t, err)
+
+	calls = nil
+	params.Step = 5 * time.Minute
+	series, err := cache.Execute(params, testExecuteFn(&calls))
+	require.NoError(t, err)
+	requireSeriesValues(t, params, series)
+	assert.Equal(t, 1, len(calls))
+}
+
+func TestResultsCacheExecuteBypassesUnalignedQueries(t *testing.T) {
+	var (
+		cache  = newTestResultsCache(t)
+		params = newTestParams()
+		calls  []executeCall
+	)
+
+	params.Start = params.Start.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		_, err := cache.Execute(params, testExecuteFn(&calls))
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 2, len(calls))
+	for _, call := range calls {
+		assert.Equal(t, executeCall{start: params.Start, end: params.End}, call)
+	}
+}
+
+func TestResultsCacheExecuteBypassesRecentQueries(t *testing.T) {
+	var (
+		cache  = newTestResultsCache(t)
+		params = newTestParams()
+		calls  []executeCall
+	)
+
+	params.Start = testStart.Add(41 * time.Minute)
+	for i := 0; i < 2; i++ {
+		_, err := cache.Execute(params, testExecuteFn(&calls))
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 2, len(calls))
+}
+
+func TestResultsCachePurge(t *testing.T) {
+	var (
+		cache  = newTestResultsCache(t)
+		params = newTestParams()
+		calls  []executeCall
+	)
+
+	_, err := cache.Execute(params, testExecuteFn(&calls))
+	require.NoError(t, err)
+
+	// Purged buckets are executed again.
+	calls = nil
+	cache.Purge()
+	series, err := cache.Execute(params, testExecuteFn(&calls))
+	require.NoError(t, err)
+	requireSeriesValues(t, params, series)
+	assert.Equal(t, []executeCall{
+		{start: params.Start, end: params.End},
+	}, calls)
+}
+
+func TestOptionsValidate(t *testing.T) {
+	assert.NoError(t, NewOptions().Validate())
+	assert.Error(t, NewOptions().SetBackend(nil).Validate())
+	assert.Error(t, NewOptions().SetBucketSize(0).Validate())
+	assert.Error(t, NewOptions().SetRecentWindow(-time.Second).Validate())
+	assert.Error(t, NewOptions().SetEntryTTL(-time.Second).Validate())
+}
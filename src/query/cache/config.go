@@ -0,0 +1,67 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"time"
+
+	"github.com/m3db/m3x/instrument"
+)
+
+// Configuration is the configuration for the results cache.
+type Configuration struct {
+	// Size is the maximum number of buckets held by the in memory backend.
+	Size int `yaml:"size" validate:"min=0"`
+
+	// BucketSize is the size of the time buckets results are cached in.
+	BucketSize time.Duration `yaml:"bucketSize" validate:"min=0"`
+
+	// RecentWindow is the window before the current time for which results
+	// are never cached.
+	RecentWindow *time.Duration `yaml:"recentWindow"`
+
+	// EntryTTL is how long each cached bucket is served for before it is
+	// executed again, cached buckets do not reflect data backfilled, repaired
+	// or deleted after they were cached until they expire. Zero never expires
+	// cached buckets.
+	EntryTTL *time.Duration `yaml:"entryTTL"`
+}
+
+// NewResultsCache creates a new results cache from the configuration.
+func (c Configuration) NewResultsCache(
+	instrumentOpts instrument.Options,
+) (ResultsCache, error) {
+	opts := NewOptions().SetInstrumentOptions(instrumentOpts)
+	if c.Size > 0 {
+		opts = opts.SetBackend(NewLRUBackend(c.Size))
+	}
+	if c.BucketSize > 0 {
+		opts = opts.SetBucketSize(c.BucketSize)
+	}
+	if c.RecentWindow != nil {
+		opts = opts.SetRecentWindow(*c.RecentWindow)
+	}
+	if c.EntryTTL != nil {
+		opts = opts.SetEntryTTL(*c.EntryTTL)
+	}
+
+	return NewResultsCache(opts)
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/query/ts"
+)
+
+type lruEntry struct {
+	key      string
+	series   []*ts.Series
+	expireAt time.Time
+}
+
+type lruBackend struct {
+	sync.Mutex
+
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+	nowFn      func() time.Time
+}
+
+// NewLRUBackend returns an in memory backend which holds at most max entries,
+// evicting the least recently used entry once full.
+func NewLRUBackend(maxEntries int) Backend {
+	return &lruBackend{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		nowFn:      time.Now,
+	}
+}
+
+func (b *lruBackend) Get(key string) ([]*ts.Series, bool) {
+	b.Lock()
+	defer b.Unlock()
+
+	elem, ok := b.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if !entry.expireAt.IsZero() && !b.nowFn().Before(entry.expireAt) {
+		b.order.Remove(elem)
+		delete(b.entries, key)
+		return nil, false
+	}
+
+	b.order.MoveToFront(elem)
+	return entry.series, true
+}
+
+func (b *lruBackend) Set(key string, series []*ts.Series, ttl time.Duration) {
+	if b.maxEntries <= 0 {
+		return
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	var expireAt time.Time
+	if ttl > 0 {
+		expireAt = b.nowFn().Add(ttl)
+	}
+
+	if elem, ok := b.entries[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.series = series
+		entry.expireAt = expireAt
+		b.order.MoveToFront(elem)
+		return
+	}
+
+	b.entries[key] = b.order.PushFront(&lruEntry{
+		key:      key,
+		series:   series,
+		expireAt: expireAt,
+	})
+	for b.order.Len() > b.maxEntries {
+		oldest := b.order.Back()
+		b.order.Remove(oldest)
+		delete(b.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (b *lruBackend) Purge() {
+	b.Lock()
+	b.entries = make(map[string]*list.Element)
+	b.order.Init()
+	b.Unlock()
+}
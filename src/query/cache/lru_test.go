@@ -0,0 +1,108 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUBackendEvictsLeastRecentlyUsed(t *testing.T) {
+	var (
+		backend = NewLRUBackend(2)
+		a       = []*ts.Series{ts.NewSeries([]byte("a"), nil, models.Tags{})}
+		b       = []*ts.Series{ts.NewSeries([]byte("b"), nil, models.Tags{})}
+		c       = []*ts.Series{ts.NewSeries([]byte("c"), nil, models.Tags{})}
+	)
+
+	backend.Set("a", a, 0)
+	backend.Set("b", b, 0)
+
+	// Reading a makes b the least recently used entry.
+	series, ok := backend.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, a, series)
+
+	backend.Set("c", c, 0)
+
+	_, ok = backend.Get("b")
+	assert.False(t, ok)
+
+	series, ok = backend.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, a, series)
+
+	series, ok = backend.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, c, series)
+}
+
+func TestLRUBackendZeroSizeStoresNothing(t *testing.T) {
+	backend := NewLRUBackend(0)
+	backend.Set("a", []*ts.Series{}, 0)
+
+	_, ok := backend.Get("a")
+	assert.False(t, ok)
+}
+
+func TestLRUBackendExpiresEntries(t *testing.T) {
+	var (
+		backend = NewLRUBackend(2)
+		now     = time.Unix(0, 0)
+		a       = []*ts.Series{ts.NewSeries([]byte("a"), nil, models.Tags{})}
+		b       = []*ts.Series{ts.NewSeries([]byte("b"), nil, models.Tags{})}
+	)
+
+	backend.(*lruBackend).nowFn = func() time.Time { return now }
+	backend.Set("a", a, time.Minute)
+	backend.Set("b", b, 0)
+
+	now = now.Add(time.Minute - time.Nanosecond)
+	_, ok := backend.Get("a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Nanosecond)
+	_, ok = backend.Get("a")
+	assert.False(t, ok)
+
+	// Entries without a TTL never expire.
+	series, ok := backend.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, b, series)
+}
+
+func TestLRUBackendPurge(t *testing.T) {
+	backend := NewLRUBackend(2)
+	backend.Set("a", []*ts.Series{}, 0)
+	backend.Purge()
+
+	_, ok := backend.Get("a")
+	assert.False(t, ok)
+
+	backend.Set("b", []*ts.Series{}, 0)
+	_, ok = backend.Get("b")
+	assert.True(t, ok)
+}
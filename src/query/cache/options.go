@@ -0,0 +1,128 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m3db/m3x/instrument"
+)
+
+const (
+	defaultLRUBackendSize = 10000
+	defaultBucketSize     = time.Hour
+	defaultRecentWindow   = 10 * time.Minute
+	defaultEntryTTL       = time.Hour
+)
+
+var (
+	errNoBackend           = errors.New("no results cache backend set")
+	errInvalidBucketSize   = errors.New("results cache bucket size must be positive")
+	errInvalidRecentWindow = errors.New("results cache recent window must not be negative")
+	errInvalidEntryTTL     = errors.New("results cache entry TTL must not be negative")
+)
+
+type options struct {
+	backend        Backend
+	bucketSize     time.Duration
+	recentWindow   time.Duration
+	entryTTL       time.Duration
+	instrumentOpts instrument.Options
+}
+
+// NewOptions creates a new set of results cache options, by default results
+// are cached in an in memory LRU backend.
+func NewOptions() Options {
+	return &options{
+		backend:        NewLRUBackend(defaultLRUBackendSize),
+		bucketSize:     defaultBucketSize,
+		recentWindow:   defaultRecentWindow,
+		entryTTL:       defaultEntryTTL,
+		instrumentOpts: instrument.NewOptions(),
+	}
+}
+
+func (o *options) Validate() error {
+	if o.backend == nil {
+		return errNoBackend
+	}
+	if o.bucketSize <= 0 {
+		return errInvalidBucketSize
+	}
+	if o.recentWindow < 0 {
+		return errInvalidRecentWindow
+	}
+	if o.entryTTL < 0 {
+		return errInvalidEntryTTL
+	}
+	return nil
+}
+
+func (o *options) SetBackend(value Backend) Options {
+	opts := *o
+	opts.backend = value
+	return &opts
+}
+
+func (o *options) Backend() Backend {
+	return o.backend
+}
+
+func (o *options) SetBucketSize(value time.Duration) Options {
+	opts := *o
+	opts.bucketSize = value
+	return &opts
+}
+
+func (o *options) BucketSize() time.Duration {
+	return o.bucketSize
+}
+
+func (o *options) SetRecentWindow(value time.Duration) Options {
+	opts := *o
+	opts.recentWindow = value
+	return &opts
+}
+
+func (o *options) RecentWindow() time.Duration {
+	return o.recentWindow
+}
+
+func (o *options) SetEntryTTL(value time.Duration) Options {
+	opts := *o
+	opts.entryTTL = value
+	return &opts
+}
+
+func (o *options) EntryTTL() time.Duration {
+	return o.entryTTL
+}
+
+func (o *options) SetInstrumentOptions(value instrument.Options) Options {
+	opts := *o
+	opts.instrumentOpts = value
+	return &opts
+}
+
+func (o *options) InstrumentOptions() instrument.Options {
+	return o.instrumentOpts
+}
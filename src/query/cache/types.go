@@ -0,0 +1,104 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package cache provides a cache of range query results, queries are split
+// into step aligned time buckets so that completed historical buckets can be
+// served from the cache and only the most recent data needs to be executed.
+package cache
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+
+	"github.com/m3db/m3x/instrument"
+)
+
+// Backend is a store of the series computed for buckets of range queries.
+type Backend interface {
+	// Get returns the series stored for the key, if any have not expired.
+	Get(key string) ([]*ts.Series, bool)
+
+	// Set stores the series for the key, expiring them after the TTL unless
+	// the TTL is zero.
+	Set(key string, series []*ts.Series, ttl time.Duration)
+
+	// Purge removes every stored entry.
+	Purge()
+}
+
+// ExecuteFn executes the query described by the request params.
+type ExecuteFn func(params models.RequestParams) ([]*ts.Series, error)
+
+// ResultsCache serves range queries from cached results where possible.
+type ResultsCache interface {
+	// Execute returns the results of the query described by the request
+	// params, completed buckets are served from the cache and only the
+	// remaining time ranges are executed using the execute function.
+	Execute(params models.RequestParams, fn ExecuteFn) ([]*ts.Series, error)
+
+	// Purge removes every cached result, such as after backfilling or
+	// deleting data in time ranges that may have been cached.
+	Purge()
+}
+
+// Options is a set of options for the results cache.
+type Options interface {
+	// Validate validates the options.
+	Validate() error
+
+	// SetBackend sets the backend used to store cached buckets.
+	SetBackend(value Backend) Options
+
+	// Backend returns the backend used to store cached buckets.
+	Backend() Backend
+
+	// SetBucketSize sets the size of the time buckets results are cached in,
+	// rounded down to a multiple of each query's step.
+	SetBucketSize(value time.Duration) Options
+
+	// BucketSize returns the size of the time buckets results are cached in.
+	BucketSize() time.Duration
+
+	// SetRecentWindow sets the window before the current time for which
+	// results are never cached since late writes may still change them.
+	SetRecentWindow(value time.Duration) Options
+
+	// RecentWindow returns the window before the current time for which
+	// results are never cached.
+	RecentWindow() time.Duration
+
+	// SetEntryTTL sets how long each cached bucket is served for before it is
+	// executed again, zero never expires cached buckets. Cached buckets do not
+	// reflect data backfilled, repaired or deleted after they were cached
+	// until they expire.
+	SetEntryTTL(value time.Duration) Options
+
+	// EntryTTL returns how long each cached bucket is served for before it is
+	// executed again.
+	EntryTTL() time.Duration
+
+	// SetInstrumentOptions sets the instrument options.
+	SetInstrumentOptions(value instrument.Options) Options
+
+	// InstrumentOptions returns the instrument options.
+	InstrumentOptions() instrument.Options
+}
@@ -33,13 +33,25 @@ import (
 
 // Engine executes a Query.
 type Engine struct {
-	metrics          *engineMetrics
-	store            storage.Storage
-	lookbackDuration time.Duration
+	metrics             *engineMetrics
+	store               storage.Storage
+	lookbackDuration    time.Duration
+	downsampleStepRatio int
 }
 
 // EngineOptions can be used to pass custom flags to engine
 type EngineOptions struct {
+	// Annotations collects the annotated datapoints fetched by the query, nil
+	// if annotations are not requested.
+	Annotations *storage.Annotations
+}
+
+func (o *EngineOptions) annotations() *storage.Annotations {
+	if o == nil {
+		return nil
+	}
+
+	return o.Annotations
 }
 
 // Query is the result after execution
@@ -48,12 +60,21 @@ type Query struct {
 	Result Result
 }
 
-// NewEngine returns a new instance of QueryExecutor.
-func NewEngine(store storage.Storage, scope tally.Scope, lookbackDuration time.Duration) *Engine {
+// NewEngine returns a new instance of QueryExecutor, series are downsampled
+// on the database nodes to a resolution the downsample step ratio times
+// smaller than the step of a query when that does not change its result,
+// or fetched raw if the ratio is zero.
+func NewEngine(
+	store storage.Storage,
+	scope tally.Scope,
+	lookbackDuration time.Duration,
+	downsampleStepRatio int,
+) *Engine {
 	return &Engine{
-		metrics:          newEngineMetrics(scope),
-		store:            store,
-		lookbackDuration: lookbackDuration,
+		metrics:             newEngineMetrics(scope),
+		store:               store,
+		lookbackDuration:    lookbackDuration,
+		downsampleStepRatio: downsampleStepRatio,
 	}
 }
 
@@ -113,6 +134,7 @@ func (e *Engine) Execute(
 	defer close(results)
 	fetchOpts := storage.NewFetchOptions()
 	fetchOpts.Limit = 0
+	fetchOpts.Annotations = opts.annotations()
 	result, err := e.store.Fetch(ctx, query, fetchOpts)
 	if err != nil {
 		results <- &storage.QueryResult{Err: err}
@@ -133,7 +155,7 @@ func (e *Engine) ExecuteExpr(
 ) {
 	defer close(results)
 
-	req := newRequest(e, params)
+	req := newRequest(e, params, opts)
 	defer req.finish()
 	nodes, edges, err := req.compile(ctx, parser)
 	if err != nil {
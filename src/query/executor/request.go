@@ -67,14 +67,20 @@ func (s State) durationString() string {
 type Request struct {
 	engine     *Engine
 	params     models.RequestParams
+	opts       *EngineOptions
 	parentSpan *span
 }
 
-func newRequest(engine *Engine, params models.RequestParams) *Request {
+func newRequest(
+	engine *Engine,
+	params models.RequestParams,
+	opts *EngineOptions,
+) *Request {
 	parentSpan := startSpan(engine.metrics.activeHist, engine.metrics.all)
 	return &Request{
 		engine:     engine,
 		params:     params,
+		opts:       opts,
 		parentSpan: parentSpan,
 	}
 }
@@ -114,6 +120,8 @@ func (r *Request) plan(ctx context.Context, nodes parser.Nodes, edges parser.Edg
 		return plan.PhysicalPlan{}, err
 	}
 
+	pp.DownsampleStepRatio = r.engine.downsampleStepRatio
+
 	if r.params.Debug {
 		logging.WithContext(ctx).Info("physical plan", zap.String("plan", pp.String()))
 	}
@@ -124,7 +132,7 @@ func (r *Request) plan(ctx context.Context, nodes parser.Nodes, edges parser.Edg
 
 func (r *Request) execute(ctx context.Context, pp plan.PhysicalPlan) (*ExecutionState, error) {
 	sp := startSpan(r.engine.metrics.executingHist, r.engine.metrics.executing)
-	state, err := GenerateExecutionState(pp, r.engine.store)
+	state, err := GenerateExecutionState(pp, r.engine.store, r.opts)
 	// free up resources
 	if err != nil {
 		sp.finish(err)
@@ -101,10 +101,12 @@ type ScalarParams interface {
 	) parser.Source
 }
 
-// GenerateExecutionState creates an execution state from the physical plan
+// GenerateExecutionState creates an execution state from the physical plan,
+// the engine options are optional.
 func GenerateExecutionState(
 	pplan plan.PhysicalPlan,
 	storage storage.Storage,
+	opts *EngineOptions,
 ) (*ExecutionState, error) {
 	result := pplan.ResultStep
 	state := &ExecutionState{
@@ -118,9 +120,13 @@ func GenerateExecutionState(
 	}
 
 	options := transform.Options{
-		TimeSpec:  pplan.TimeSpec,
-		Debug:     pplan.Debug,
-		BlockType: pplan.BlockType,
+		TimeSpec:    pplan.TimeSpec,
+		Debug:       pplan.Debug,
+		BlockType:   pplan.BlockType,
+		Annotations: opts.annotations(),
+		Downsample: transform.DownsampleOptions{
+			StepRatio: pplan.DownsampleStepRatio,
+		},
 	}
 
 	controller, err := state.createNode(step, options)
@@ -166,13 +172,18 @@ func (s *ExecutionState) createNode(
 	}
 
 	transformNode, controller := CreateTransform(step.ID(), transformParams, options)
+	parentOptions := options
+	if optionsOp, ok := transformParams.(transform.ParentOptionsOp); ok {
+		parentOptions = optionsOp.ParentOptions(options)
+	}
+
 	for _, parentID := range step.Parents {
 		parentStep, ok := s.plan.Step(parentID)
 		if !ok {
 			return nil, fmt.Errorf("incorrect parent reference, parentId: %s, node: %s", parentID, step.ID())
 		}
 
-		parentController, err := s.createNode(parentStep, options)
+		parentController, err := s.createNode(parentStep, parentOptions)
 		if err != nil {
 			return nil, err
 		}
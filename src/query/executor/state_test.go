@@ -58,7 +58,7 @@ func TestValidState(t *testing.T) {
 	store := mock.NewMockStorage()
 	p, err := plan.NewPhysicalPlan(lp, store, models.RequestParams{Now: time.Now()}, defaultLookbackDuration)
 	require.NoError(t, err)
-	state, err := GenerateExecutionState(p, store)
+	state, err := GenerateExecutionState(p, store, nil)
 	require.NoError(t, err)
 	require.Len(t, state.sources, 1)
 	err = state.Execute(context.Background())
@@ -75,7 +75,7 @@ func TestWithoutSources(t *testing.T) {
 	require.NoError(t, err)
 	p, err := plan.NewPhysicalPlan(lp, nil, models.RequestParams{Now: time.Now()}, defaultLookbackDuration)
 	require.NoError(t, err)
-	_, err = GenerateExecutionState(p, nil)
+	_, err = GenerateExecutionState(p, nil, nil)
 	assert.Error(t, err)
 }
 
@@ -87,7 +87,7 @@ func TestOnlySources(t *testing.T) {
 	require.NoError(t, err)
 	p, err := plan.NewPhysicalPlan(lp, nil, models.RequestParams{Now: time.Now()}, defaultLookbackDuration)
 	require.NoError(t, err)
-	state, err := GenerateExecutionState(p, nil)
+	state, err := GenerateExecutionState(p, nil, nil)
 	assert.NoError(t, err)
 	require.Len(t, state.sources, 1)
 }
@@ -114,7 +114,7 @@ func TestMultipleSources(t *testing.T) {
 	require.NoError(t, err)
 	p, err := plan.NewPhysicalPlan(lp, nil, models.RequestParams{Now: time.Now()}, defaultLookbackDuration)
 	require.NoError(t, err)
-	state, err := GenerateExecutionState(p, nil)
+	state, err := GenerateExecutionState(p, nil, nil)
 	assert.NoError(t, err)
 	require.Len(t, state.sources, 2)
 	assert.Contains(t, state.String(), "sources")
@@ -23,9 +23,11 @@ package transform
 import (
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/encoding/downsample"
 	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/parser"
+	"github.com/m3db/m3/src/query/storage"
 )
 
 // Options to create transform nodes
@@ -33,6 +35,56 @@ type Options struct {
 	TimeSpec  TimeSpec
 	Debug     bool
 	BlockType models.FetchedBlockType
+	// Annotations collects the annotated datapoints fetched by the query,
+	// nil if annotations are not requested.
+	Annotations *storage.Annotations
+	// Downsample describes how the series fetched by sources may be
+	// downsampled on the database nodes.
+	Downsample DownsampleOptions
+}
+
+// DownsampleOptions describe how the series fetched by sources may be
+// downsampled on the database nodes without changing the result of the
+// operations which consume them.
+type DownsampleOptions struct {
+	// StepRatio is how many times smaller than the step the resolution the
+	// series are downsampled to is, series are fetched raw if zero.
+	StepRatio int
+	// Aggregation is the aggregation applied to the datapoints of a window.
+	Aggregation downsample.AggregationType
+}
+
+// Enabled returns whether the series fetched by sources may be downsampled.
+func (o DownsampleOptions) Enabled() bool {
+	return o.StepRatio > 0
+}
+
+// FetchOptions returns how to downsample a fetch at the step, and over the
+// range if the fetch is for a range selector. The resolution is much smaller
+// than both and divides both exactly, so the windows, which are aligned to
+// the start of the fetch, never straddle a step or the edge of a range;
+// series are fetched raw if no such resolution exists.
+func (o DownsampleOptions) FetchOptions(
+	step, queryRange time.Duration,
+) downsample.Options {
+	if !o.Enabled() || step <= 0 || queryRange < 0 {
+		return downsample.Options{}
+	}
+
+	interval := step
+	if queryRange > 0 && queryRange < interval {
+		interval = queryRange
+	}
+
+	resolution := interval / time.Duration(o.StepRatio)
+	if resolution <= 0 || step%resolution != 0 || queryRange%resolution != 0 {
+		return downsample.Options{}
+	}
+
+	return downsample.Options{
+		Resolution:  resolution,
+		Aggregation: o.Aggregation,
+	}
 }
 
 // OpNode represents the execution node
@@ -89,6 +141,12 @@ type BoundOp interface {
 	Bounds() BoundSpec
 }
 
+// ParentOptionsOp is implemented by operations which require their parents
+// to be executed with different options, such as at a different resolution
+type ParentOptionsOp interface {
+	ParentOptions(opts Options) Options
+}
+
 // BoundSpec is the bound spec for an operation
 type BoundSpec struct {
 	Range  time.Duration
@@ -0,0 +1,87 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package transform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/encoding/downsample"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownsampleFetchOptions(t *testing.T) {
+	opts := DownsampleOptions{StepRatio: 10, Aggregation: downsample.Max}
+	tests := []struct {
+		name     string
+		opts     DownsampleOptions
+		step     time.Duration
+		rng      time.Duration
+		expected downsample.Options
+	}{
+		{
+			name:     "disabled",
+			opts:     DownsampleOptions{},
+			step:     time.Hour,
+			expected: downsample.Options{},
+		},
+		{
+			name: "step",
+			opts: opts,
+			step: time.Hour,
+			expected: downsample.Options{
+				Resolution:  6 * time.Minute,
+				Aggregation: downsample.Max,
+			},
+		},
+		{
+			name: "range smaller than step",
+			opts: opts,
+			step: time.Hour,
+			rng:  10 * time.Minute,
+			expected: downsample.Options{
+				Resolution:  time.Minute,
+				Aggregation: downsample.Max,
+			},
+		},
+		{
+			name:     "range not divisible",
+			opts:     opts,
+			step:     time.Hour,
+			rng:      time.Hour + time.Minute,
+			expected: downsample.Options{},
+		},
+		{
+			name:     "step too small",
+			opts:     opts,
+			step:     5 * time.Nanosecond,
+			expected: downsample.Options{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := test.opts.FetchOptions(test.step, test.rng)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
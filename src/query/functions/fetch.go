@@ -49,12 +49,14 @@ type FetchOp struct {
 // FetchNode is the execution node
 // TODO: Make FetchNode private
 type FetchNode struct {
-	debug      bool
-	blockType  models.FetchedBlockType
-	op         FetchOp
-	controller *transform.Controller
-	storage    storage.Storage
-	timespec   transform.TimeSpec
+	debug       bool
+	blockType   models.FetchedBlockType
+	annotations *storage.Annotations
+	downsample  transform.DownsampleOptions
+	op          FetchOp
+	controller  *transform.Controller
+	storage     storage.Storage
+	timespec    transform.TimeSpec
 }
 
 // OpType for the operator
@@ -78,12 +80,14 @@ func (o FetchOp) String() string {
 // Node creates an execution node
 func (o FetchOp) Node(controller *transform.Controller, storage storage.Storage, options transform.Options) parser.Source {
 	return &FetchNode{
-		op:         o,
-		controller: controller,
-		storage:    storage,
-		timespec:   options.TimeSpec,
-		debug:      options.Debug,
-		blockType:  options.BlockType,
+		op:          o,
+		controller:  controller,
+		storage:     storage,
+		timespec:    options.TimeSpec,
+		debug:       options.Debug,
+		blockType:   options.BlockType,
+		annotations: options.Annotations,
+		downsample:  options.Downsample,
 	}
 }
 
@@ -95,6 +99,8 @@ func (n *FetchNode) Execute(ctx context.Context) error {
 	endTime := timeSpec.End
 	opts := storage.NewFetchOptions()
 	opts.BlockType = n.blockType
+	opts.Annotations = n.annotations
+	opts.Downsample = n.downsample.FetchOptions(timeSpec.Step, n.op.Range)
 	blockResult, err := n.storage.FetchBlocks(ctx, &storage.FetchQuery{
 		Start:       startTime,
 		End:         endTime,
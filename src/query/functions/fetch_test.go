@@ -23,7 +23,9 @@ package functions
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/m3db/m3/src/dbnode/encoding/downsample"
 	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/executor/transform"
 	"github.com/m3db/m3/src/query/parser"
@@ -48,3 +50,28 @@ func TestFetch(t *testing.T) {
 	assert.Len(t, sink.Values, 2)
 	assert.Equal(t, expected, sink.Values)
 }
+
+func TestFetchDownsample(t *testing.T) {
+	values, bounds := test.GenerateValuesAndBounds(nil, nil)
+	b := test.NewBlockFromValues(bounds, values)
+	c, _ := executor.NewControllerWithSink(parser.NodeID(1))
+	mockStorage := mock.NewMockStorage()
+	mockStorage.SetFetchBlocksResult(block.Result{Blocks: []block.Block{b}}, nil)
+	source := (&FetchOp{Range: 5 * time.Minute}).Node(c, mockStorage, transform.Options{
+		TimeSpec: transform.TimeSpec{Step: time.Hour},
+		Downsample: transform.DownsampleOptions{
+			StepRatio:   10,
+			Aggregation: downsample.Max,
+		},
+	})
+	require.NoError(t, source.Execute(context.TODO()))
+
+	// The resolution is derived from the range since it is smaller than
+	// the step.
+	fetchOpts := mockStorage.FetchBlocksOptions()
+	require.Len(t, fetchOpts, 1)
+	assert.Equal(t, downsample.Options{
+		Resolution:  30 * time.Second,
+		Aggregation: downsample.Max,
+	}, fetchOpts[0].Downsample)
+}
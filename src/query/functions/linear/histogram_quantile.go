@@ -0,0 +1,348 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package linear
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/executor/transform"
+	"github.com/m3db/m3/src/query/functions/utils"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+)
+
+const (
+	// HistogramQuantileType calculates the quantile for histogram buckets.
+	//
+	// NB: each sample must contain a tag with the bucket name `le` that denotes
+	// the inclusive upper bound of that bucket; series without a valid upper
+	// bound are ignored.
+	HistogramQuantileType = "histogram_quantile"
+
+	initIndexBucketLength = 10
+)
+
+var bucketName = []byte("le")
+
+// NewHistogramQuantileOp creates a new histogram quantile operation
+func NewHistogramQuantileOp(
+	args []interface{},
+	opType string,
+) (parser.Params, error) {
+	if len(args) != 1 {
+		return emptyHistogramQuantileOp, fmt.Errorf(
+			"invalid number of args for histogram_quantile: %d", len(args))
+	}
+
+	if opType != HistogramQuantileType {
+		return emptyHistogramQuantileOp, fmt.Errorf("operator not supported: %s", opType)
+	}
+
+	q, ok := args[0].(float64)
+	if !ok {
+		return emptyHistogramQuantileOp, fmt.Errorf("unable to cast to scalar argument: %v", args[0])
+	}
+
+	return newHistogramQuantileOp(q, opType), nil
+}
+
+var emptyHistogramQuantileOp = histogramQuantileOp{}
+
+// histogramQuantileOp stores required properties for histogram quantile ops
+type histogramQuantileOp struct {
+	q      float64
+	opType string
+}
+
+// OpType for the operator
+func (o histogramQuantileOp) OpType() string {
+	return o.opType
+}
+
+// String representation
+func (o histogramQuantileOp) String() string {
+	return fmt.Sprintf("type: %s", o.OpType())
+}
+
+// Node creates an execution node
+func (o histogramQuantileOp) Node(
+	controller *transform.Controller,
+	_ transform.Options,
+) transform.OpNode {
+	return &histogramQuantileNode{
+		op:         o,
+		controller: controller,
+	}
+}
+
+func newHistogramQuantileOp(q float64, opType string) histogramQuantileOp {
+	return histogramQuantileOp{
+		q:      q,
+		opType: opType,
+	}
+}
+
+type histogramQuantileNode struct {
+	op         histogramQuantileOp
+	controller *transform.Controller
+}
+
+// indexedBucket is a bucket upper bound with the index of the series in the
+// incoming block which holds the values for that bucket.
+type indexedBucket struct {
+	upperBound float64
+	idx        int
+}
+
+type indexedBuckets []indexedBucket
+
+func (b indexedBuckets) Len() int      { return len(b) }
+func (b indexedBuckets) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b indexedBuckets) Less(i, j int) bool {
+	return b[i].upperBound < b[j].upperBound
+}
+
+// bucketedSeries is a set of buckets which belong to the same histogram,
+// i.e. all series sharing every tag except for the bucket and name tags.
+type bucketedSeries struct {
+	buckets indexedBuckets
+	tags    models.Tags
+}
+
+// gatherSeriesToBuckets groups series into histograms, returning the buckets
+// for each histogram sorted by upper bound, in order of first appearance.
+func gatherSeriesToBuckets(metas []block.SeriesMeta) []bucketedSeries {
+	bucketsForID := make(map[uint64]int, initIndexBucketLength)
+	histograms := make([]bucketedSeries, 0, initIndexBucketLength)
+	for i, meta := range metas {
+		tags := meta.Tags
+		bucket, found := tags.Get(bucketName)
+		if !found {
+			continue
+		}
+
+		upperBound, err := strconv.ParseFloat(string(bucket), 64)
+		if err != nil {
+			continue
+		}
+
+		excludeTags := [][]byte{tags.Opts.MetricName(), bucketName}
+		tags = tags.TagsWithoutKeys(excludeTags)
+		id := tags.HashedID()
+		newBucket := indexedBucket{
+			upperBound: upperBound,
+			idx:        i,
+		}
+
+		if histIdx, ok := bucketsForID[id]; ok {
+			histograms[histIdx].buckets = append(histograms[histIdx].buckets, newBucket)
+			continue
+		}
+
+		bucketsForID[id] = len(histograms)
+		histograms = append(histograms, bucketedSeries{
+			buckets: indexedBuckets{newBucket},
+			tags:    tags,
+		})
+	}
+
+	for _, histogram := range histograms {
+		sort.Sort(histogram.buckets)
+	}
+
+	return histograms
+}
+
+// bucketValue is a bucket upper bound with the cumulative count for the bucket
+// at a particular step.
+type bucketValue struct {
+	upperBound float64
+	value      float64
+}
+
+// Process the block
+func (n *histogramQuantileNode) Process(ID parser.NodeID, b block.Block) error {
+	stepIter, err := b.StepIter()
+	if err != nil {
+		return err
+	}
+
+	meta := stepIter.Meta()
+	seriesMetas := utils.FlattenMetadata(meta, stepIter.SeriesMeta())
+	histograms := gatherSeriesToBuckets(seriesMetas)
+
+	metas := make([]block.SeriesMeta, len(histograms))
+	for i, histogram := range histograms {
+		metas[i] = block.SeriesMeta{
+			Tags: histogram.tags,
+			Name: []byte(n.op.opType),
+		}
+	}
+
+	meta.Tags, metas = utils.DedupeMetadata(metas)
+	builder, err := n.controller.BlockBuilder(meta, metas)
+	if err != nil {
+		return err
+	}
+
+	if err = builder.AddCols(stepIter.StepCount()); err != nil {
+		return err
+	}
+
+	q := n.op.q
+	aggregatedValues := make([]float64, len(histograms))
+	bucketValues := make([]bucketValue, 0, initIndexBucketLength)
+	for index := 0; stepIter.Next(); index++ {
+		values := stepIter.Current().Values()
+		for i, histogram := range histograms {
+			bucketValues = bucketValues[:0]
+			for _, bucket := range histogram.buckets {
+				// NB: only consider buckets which have a value at this step.
+				if val := values[bucket.idx]; !math.IsNaN(val) {
+					bucketValues = append(bucketValues, bucketValue{
+						upperBound: bucket.upperBound,
+						value:      val,
+					})
+				}
+			}
+
+			aggregatedValues[i] = bucketQuantile(q, bucketValues)
+		}
+
+		if err := builder.AppendValues(index, aggregatedValues); err != nil {
+			return err
+		}
+	}
+
+	if err = stepIter.Err(); err != nil {
+		return err
+	}
+
+	nextBlock := builder.Build()
+	defer nextBlock.Close()
+	return n.controller.Process(nextBlock)
+}
+
+// bucketQuantile calculates the quantile 'q' based on the given buckets, which
+// must be sorted by upper bound. The buckets are treated as cumulative, and
+// the quantile is linearly interpolated within the bucket it falls into, as
+// in Prometheus.
+//
+// Special cases are:
+//   - no buckets, or a highest bucket which is not +Inf: NaN
+//   - q < 0: -Inf
+//   - q > 1: +Inf
+//   - the quantile falls into the highest bucket: upper bound of the second
+//     highest bucket
+//   - the quantile falls into the lowest bucket with an upper bound <= 0:
+//     that upper bound
+//
+// Counts which decrease with increasing upper bound are treated as the
+// maximum count seen in any lower bucket, which can happen when buckets are
+// scraped at slightly different times.
+func bucketQuantile(q float64, buckets []bucketValue) float64 {
+	if len(buckets) == 0 {
+		return math.NaN()
+	}
+
+	if q < 0 {
+		return math.Inf(-1)
+	}
+
+	if q > 1 {
+		return math.Inf(1)
+	}
+
+	if !math.IsInf(buckets[len(buckets)-1].upperBound, 1) {
+		return math.NaN()
+	}
+
+	buckets = coalesceBuckets(buckets)
+	ensureMonotonic(buckets)
+
+	if len(buckets) < 2 {
+		return math.NaN()
+	}
+
+	rank := q * buckets[len(buckets)-1].value
+	bucketIndex := sort.Search(len(buckets)-1, func(i int) bool {
+		return buckets[i].value >= rank
+	})
+
+	if bucketIndex == len(buckets)-1 {
+		return buckets[len(buckets)-2].upperBound
+	}
+
+	if bucketIndex == 0 && buckets[0].upperBound <= 0 {
+		return buckets[0].upperBound
+	}
+
+	var (
+		bucketStart float64
+		bucketEnd   = buckets[bucketIndex].upperBound
+		count       = buckets[bucketIndex].value
+	)
+
+	if bucketIndex > 0 {
+		bucketStart = buckets[bucketIndex-1].upperBound
+		count -= buckets[bucketIndex-1].value
+		rank -= buckets[bucketIndex-1].value
+	}
+
+	return bucketStart + (bucketEnd-bucketStart)*rank/count
+}
+
+// coalesceBuckets merges adjacent buckets with the same upper bound by
+// summing their values; this happens when the only differentiating tags were
+// dropped. The buckets are coalesced in place.
+func coalesceBuckets(buckets []bucketValue) []bucketValue {
+	last := buckets[0]
+	i := 0
+	for _, b := range buckets[1:] {
+		if b.upperBound == last.upperBound {
+			last.value += b.value
+		} else {
+			buckets[i] = last
+			last = b
+			i++
+		}
+	}
+
+	buckets[i] = last
+	return buckets[:i+1]
+}
+
+// ensureMonotonic makes sure bucket values never decrease as the upper bound
+// increases.
+func ensureMonotonic(buckets []bucketValue) {
+	max := math.Inf(-1)
+	for i := range buckets {
+		if buckets[i].value > max {
+			max = buckets[i].value
+		} else if buckets[i].value < max {
+			buckets[i].value = max
+		}
+	}
+}
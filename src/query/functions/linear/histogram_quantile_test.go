@@ -0,0 +1,184 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package linear
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/executor/transform"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+	"github.com/m3db/m3/src/query/test"
+	"github.com/m3db/m3/src/query/test/executor"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketQuantile(t *testing.T) {
+	buckets := []bucketValue{
+		{upperBound: 1, value: 10},
+		{upperBound: 2, value: 20},
+		{upperBound: 5, value: 40},
+		{upperBound: math.Inf(1), value: 40},
+	}
+
+	copyBuckets := func() []bucketValue {
+		return append([]bucketValue(nil), buckets...)
+	}
+
+	assert.Equal(t, 0.5, bucketQuantile(0.125, copyBuckets()))
+	assert.Equal(t, 1.0, bucketQuantile(0.25, copyBuckets()))
+	assert.Equal(t, 2.0, bucketQuantile(0.5, copyBuckets()))
+	assert.Equal(t, 3.5, bucketQuantile(0.75, copyBuckets()))
+	assert.Equal(t, math.Inf(-1), bucketQuantile(-1, copyBuckets()))
+	assert.Equal(t, math.Inf(1), bucketQuantile(2, copyBuckets()))
+
+	// Quantiles falling into the +Inf bucket return the highest finite bound.
+	buckets[3].value = 80
+	assert.Equal(t, 5.0, bucketQuantile(0.9, copyBuckets()))
+}
+
+func TestBucketQuantileSpecialCases(t *testing.T) {
+	assert.True(t, math.IsNaN(bucketQuantile(0.5, nil)))
+
+	// No +Inf bucket.
+	assert.True(t, math.IsNaN(bucketQuantile(0.5, []bucketValue{
+		{upperBound: 1, value: 1},
+		{upperBound: 2, value: 2},
+	})))
+
+	// Only a +Inf bucket.
+	assert.True(t, math.IsNaN(bucketQuantile(0.5, []bucketValue{
+		{upperBound: math.Inf(1), value: 1},
+	})))
+
+	// Lowest bucket has a non-positive upper bound.
+	assert.Equal(t, -1.0, bucketQuantile(0.1, []bucketValue{
+		{upperBound: -1, value: 5},
+		{upperBound: math.Inf(1), value: 10},
+	}))
+}
+
+func TestBucketQuantileNonMonotonic(t *testing.T) {
+	actual := bucketQuantile(0.5, []bucketValue{
+		{upperBound: 1, value: 10},
+		{upperBound: 2, value: 5},
+		{upperBound: 3, value: 20},
+		{upperBound: math.Inf(1), value: 20},
+	})
+
+	// The decreasing bucket is treated as having the previous bucket's value,
+	// so the 10th sample falls at the upper bound of the first bucket.
+	assert.Equal(t, 1.0, actual)
+}
+
+func TestBucketQuantileCoalesces(t *testing.T) {
+	actual := bucketQuantile(0.5, []bucketValue{
+		{upperBound: 1, value: 5},
+		{upperBound: 1, value: 5},
+		{upperBound: 2, value: 20},
+		{upperBound: math.Inf(1), value: 20},
+	})
+
+	assert.Equal(t, 1.0, actual)
+}
+
+func histogramMeta(name, le string, extra ...models.Tag) block.SeriesMeta {
+	tags := []models.Tag{
+		{Name: []byte("__name__"), Value: []byte(name)},
+		{Name: []byte("le"), Value: []byte(le)},
+	}
+
+	tags = append(tags, extra...)
+	return block.SeriesMeta{
+		Name: []byte(name),
+		Tags: test.TagSliceToTags(tags),
+	}
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	hostA := models.Tag{Name: []byte("host"), Value: []byte("a")}
+	hostB := models.Tag{Name: []byte("host"), Value: []byte("b")}
+	metas := []block.SeriesMeta{
+		histogramMeta("latency_bucket", "+Inf", hostA),
+		histogramMeta("latency_bucket", "1", hostA),
+		histogramMeta("latency_bucket", "5", hostA),
+		histogramMeta("latency_bucket", "1", hostB),
+		histogramMeta("latency_bucket", "+Inf", hostB),
+		histogramMeta("latency_bucket", "invalid", hostB),
+		test.NewSeriesMeta("no_bucket", 1)[0],
+	}
+
+	values := [][]float64{
+		{40, 40, 40},
+		{10, 10, math.NaN()},
+		{30, 30, 40},
+		{10, 20, 0},
+		{20, 20, 0},
+		{1, 1, 1},
+		{1, 1, 1},
+	}
+
+	bounds := models.Bounds{
+		Start:    time.Now(),
+		Duration: time.Minute * 3,
+		StepSize: time.Minute,
+	}
+
+	block := test.NewBlockFromValuesWithSeriesMeta(bounds, metas, values)
+	c, sink := executor.NewControllerWithSink(parser.NodeID(1))
+	op, err := NewHistogramQuantileOp([]interface{}{0.5}, HistogramQuantileType)
+	require.NoError(t, err)
+
+	node := op.(histogramQuantileOp).Node(c, transform.Options{})
+	err = node.Process(parser.NodeID(0), block)
+	require.NoError(t, err)
+
+	expected := [][]float64{
+		// host a: the last step has no value for the 1 bucket.
+		{3, 3, 2.5},
+		// host b: the second step has the entire histogram in the lowest
+		// bucket, the last step has no samples.
+		{1, 0.5, math.NaN()},
+	}
+
+	require.Len(t, sink.Metas, 2)
+	assert.Equal(t, []byte(HistogramQuantileType), sink.Metas[0].Name)
+	assert.Equal(t, []models.Tag{hostA}, sink.Metas[0].Tags.Tags)
+	assert.Equal(t, []models.Tag{hostB}, sink.Metas[1].Tags.Tags)
+	assert.Equal(t, bounds, sink.Meta.Bounds)
+	test.EqualsWithNans(t, expected, sink.Values)
+}
+
+func TestHistogramQuantileInvalidArgs(t *testing.T) {
+	_, err := NewHistogramQuantileOp([]interface{}{}, HistogramQuantileType)
+	assert.Error(t, err)
+
+	_, err = NewHistogramQuantileOp([]interface{}{"a"}, HistogramQuantileType)
+	assert.Error(t, err)
+
+	_, err = NewHistogramQuantileOp([]interface{}{0.5}, "unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package functions
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/encoding/downsample"
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/executor/transform"
+	"github.com/m3db/m3/src/query/functions/utils"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/ts"
+)
+
+// SubqueryType evaluates an expression at a given resolution over a range,
+// producing a range vector which can be consumed by temporal functions
+const SubqueryType = "subquery"
+
+// SubqueryOp stores required properties for subqueries
+type SubqueryOp struct {
+	Range  time.Duration
+	Step   time.Duration
+	Offset time.Duration
+}
+
+// NewSubqueryOp creates a new subquery op; a step of zero denotes that the
+// inner expression should be evaluated at the resolution of the query
+func NewSubqueryOp(
+	queryRange, step, offset time.Duration,
+) (SubqueryOp, error) {
+	if queryRange <= 0 {
+		return SubqueryOp{}, fmt.Errorf("invalid subquery range: %v", queryRange)
+	}
+
+	if step < 0 {
+		return SubqueryOp{}, fmt.Errorf("invalid subquery step: %v", step)
+	}
+
+	return SubqueryOp{
+		Range:  queryRange,
+		Step:   step,
+		Offset: offset,
+	}, nil
+}
+
+// OpType for the operator
+func (o SubqueryOp) OpType() string {
+	return SubqueryType
+}
+
+// Bounds returns the bounds for the spec
+// NB: the offset is not part of the bounds since it is applied to the time
+// spec of the inner expression in ParentOptions.
+func (o SubqueryOp) Bounds() transform.BoundSpec {
+	return transform.BoundSpec{
+		Range: o.Range,
+	}
+}
+
+// String representation
+func (o SubqueryOp) String() string {
+	return fmt.Sprintf("type: %s, range: %v, step: %v, offset: %v",
+		o.OpType(), o.Range, o.Step, o.Offset)
+}
+
+// ParentOptions returns the options the inner expression of the subquery is
+// executed with; the inner expression is evaluated at the subquery step, with
+// steps aligned to multiples of the step as in Prometheus, and shifted back
+// by the offset of the subquery. Since the inner expression is evaluated at
+// each step like any other query, its series may only be downsampled with
+// the last value of each window whatever the function consuming the subquery
+func (o SubqueryOp) ParentOptions(opts transform.Options) transform.Options {
+	opts.Downsample.Aggregation = downsample.Last
+
+	step := o.Step
+	if step == 0 {
+		step = opts.TimeSpec.Step
+	}
+
+	opts.TimeSpec.Start = opts.TimeSpec.Start.Add(-1 * o.Offset)
+	opts.TimeSpec.End = opts.TimeSpec.End.Add(-1 * o.Offset)
+	if step <= 0 {
+		return opts
+	}
+
+	start := opts.TimeSpec.Start
+	if rem := time.Duration(start.UnixNano() % int64(step)); rem > 0 {
+		start = start.Add(-1 * rem)
+	}
+
+	opts.TimeSpec.Start = start
+	opts.TimeSpec.Step = step
+	return opts
+}
+
+// Node creates an execution node
+func (o SubqueryOp) Node(
+	controller *transform.Controller,
+	opts transform.Options,
+) transform.OpNode {
+	return &subqueryNode{
+		op:         o,
+		controller: controller,
+		timespec:   opts.TimeSpec,
+	}
+}
+
+type subqueryNode struct {
+	op         SubqueryOp
+	controller *transform.Controller
+	timespec   transform.TimeSpec
+}
+
+// Process converts the consolidated output of the inner expression, which is
+// at the subquery resolution, into an unconsolidated block at the query
+// resolution, where each step holds the inner values since the previous step.
+// The inner values are moved forward by the offset of the subquery.
+func (n *subqueryNode) Process(ID parser.NodeID, b block.Block) error {
+	seriesIter, err := b.SeriesIter()
+	if err != nil {
+		return err
+	}
+
+	defer seriesIter.Close()
+	meta := seriesIter.Meta()
+	innerBounds := meta.Bounds
+	innerBounds.Start = innerBounds.Start.Add(n.op.Offset)
+	bounds, ok := n.outputBounds(innerBounds)
+	if !ok {
+		// No steps of the query are covered by this block.
+		return nil
+	}
+
+	seriesMetas := utils.FlattenMetadata(meta, seriesIter.SeriesMeta())
+	seriesList := make(ts.SeriesList, 0, len(seriesMetas))
+	for i := 0; seriesIter.Next(); i++ {
+		series := seriesIter.Current()
+		values := series.Values()
+		datapoints := make(ts.Datapoints, 0, len(values))
+		for j, v := range values {
+			if math.IsNaN(v) {
+				continue
+			}
+
+			t, err := meta.Bounds.TimeForIndex(j)
+			if err != nil {
+				return err
+			}
+
+			datapoints = append(datapoints, ts.Datapoint{
+				Timestamp: t.Add(n.op.Offset),
+				Value:     v,
+			})
+		}
+
+		seriesMeta := seriesMetas[i]
+		seriesList = append(seriesList,
+			ts.NewSeries(seriesMeta.Name, datapoints, seriesMeta.Tags))
+	}
+
+	if err := seriesIter.Err(); err != nil {
+		return err
+	}
+
+	// NB: a lookback of the entire subquery range ensures that inner values
+	// are only dropped once they can no longer fall within any range.
+	unconsolidated, err := storage.NewMultiSeriesBlock(seriesList,
+		&storage.FetchQuery{
+			Start:    bounds.Start,
+			End:      bounds.End(),
+			Interval: bounds.StepSize,
+		}, n.op.Range+bounds.StepSize)
+	if err != nil {
+		return err
+	}
+
+	nextBlock := storage.NewMultiBlockWrapper(unconsolidated)
+	defer nextBlock.Close()
+	return n.controller.Process(nextBlock)
+}
+
+// outputBounds returns the bounds at the query resolution which cover the
+// steps of the query falling within the given inner bounds.
+func (n *subqueryNode) outputBounds(inner models.Bounds) (models.Bounds, bool) {
+	query := n.timespec.Bounds()
+	if query.StepSize <= 0 {
+		return models.Bounds{}, false
+	}
+
+	stepsTo := func(t time.Time) int {
+		steps := int(math.Ceil(float64(t.Sub(query.Start)) / float64(query.StepSize)))
+		if steps < 0 {
+			return 0
+		}
+
+		if max := query.Steps(); steps > max {
+			return max
+		}
+
+		return steps
+	}
+
+	first, last := stepsTo(inner.Start), stepsTo(inner.End())
+	if first >= last {
+		return models.Bounds{}, false
+	}
+
+	return models.Bounds{
+		Start:    query.Start.Add(time.Duration(first) * query.StepSize),
+		Duration: time.Duration(last-first) * query.StepSize,
+		StepSize: query.StepSize,
+	}, true
+}
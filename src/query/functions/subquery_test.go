@@ -0,0 +1,173 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package functions
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/encoding/downsample"
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/executor/transform"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+	"github.com/m3db/m3/src/query/test"
+	"github.com/m3db/m3/src/query/ts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type blockSink struct {
+	blocks []block.Block
+}
+
+func (s *blockSink) Process(_ parser.NodeID, b block.Block) error {
+	s.blocks = append(s.blocks, b)
+	return nil
+}
+
+func TestNewSubqueryOp(t *testing.T) {
+	op, err := NewSubqueryOp(time.Hour, time.Minute, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, SubqueryType, op.OpType())
+	assert.Equal(t, transform.BoundSpec{Range: time.Hour}, op.Bounds())
+
+	_, err = NewSubqueryOp(0, time.Minute, 0)
+	assert.Error(t, err)
+
+	_, err = NewSubqueryOp(time.Hour, -1*time.Minute, 0)
+	assert.Error(t, err)
+}
+
+func TestSubqueryParentOptions(t *testing.T) {
+	start := time.Unix(1000, 0)
+	opts := transform.Options{
+		TimeSpec: transform.TimeSpec{
+			Start: start,
+			End:   start.Add(time.Hour),
+			Step:  time.Minute,
+		},
+	}
+
+	op, err := NewSubqueryOp(time.Hour, 30*time.Second, 0)
+	require.NoError(t, err)
+	parentOpts := op.ParentOptions(opts)
+	assert.Equal(t, 30*time.Second, parentOpts.TimeSpec.Step)
+	assert.Equal(t, time.Unix(990, 0), parentOpts.TimeSpec.Start)
+	assert.Equal(t, opts.TimeSpec.End, parentOpts.TimeSpec.End)
+
+	// Default to the query step.
+	op, err = NewSubqueryOp(time.Hour, 0, 0)
+	require.NoError(t, err)
+	parentOpts = op.ParentOptions(opts)
+	assert.Equal(t, time.Minute, parentOpts.TimeSpec.Step)
+	assert.Equal(t, time.Unix(960, 0), parentOpts.TimeSpec.Start)
+
+	// Shift the inner expression back by the offset.
+	op, err = NewSubqueryOp(time.Hour, 30*time.Second, time.Hour)
+	require.NoError(t, err)
+	parentOpts = op.ParentOptions(opts)
+	assert.Equal(t, time.Unix(990, 0).Add(-1*time.Hour), parentOpts.TimeSpec.Start)
+	assert.Equal(t, opts.TimeSpec.End.Add(-1*time.Hour), parentOpts.TimeSpec.End)
+
+	// The inner expression is evaluated at each step so only the last value
+	// of each window may be kept when downsampling.
+	opts.Downsample = transform.DownsampleOptions{
+		StepRatio:   10,
+		Aggregation: downsample.Max,
+	}
+	parentOpts = op.ParentOptions(opts)
+	assert.Equal(t, transform.DownsampleOptions{
+		StepRatio:   10,
+		Aggregation: downsample.Last,
+	}, parentOpts.Downsample)
+}
+
+func TestSubqueryProcess(t *testing.T) {
+	testSubqueryProcess(t, 0)
+}
+
+func TestSubqueryProcessOffset(t *testing.T) {
+	testSubqueryProcess(t, time.Hour)
+}
+
+// testSubqueryProcess evaluates a subquery with the given offset, the inner
+// values are read from a block shifted back by the offset and are expected at
+// the same query steps regardless of the offset.
+func testSubqueryProcess(t *testing.T, offset time.Duration) {
+	start := time.Unix(7200, 0)
+	innerBounds := models.Bounds{
+		Start:    start.Add(-1 * offset),
+		Duration: 3 * time.Minute,
+		StepSize: 30 * time.Second,
+	}
+
+	b := test.NewBlockFromValues(innerBounds, [][]float64{
+		{1, 2, 3, math.NaN(), 5, 6},
+	})
+
+	op, err := NewSubqueryOp(time.Minute, 30*time.Second, offset)
+	require.NoError(t, err)
+
+	sink := &blockSink{}
+	c := &transform.Controller{ID: parser.NodeID(1)}
+	c.AddTransform(sink)
+	node := op.Node(c, transform.Options{
+		TimeSpec: transform.TimeSpec{
+			Start: start,
+			End:   start.Add(3 * time.Minute),
+			Step:  time.Minute,
+		},
+	})
+
+	err = node.Process(parser.NodeID(0), b)
+	require.NoError(t, err)
+	require.Len(t, sink.blocks, 1)
+
+	unconsolidated, err := sink.blocks[0].Unconsolidated()
+	require.NoError(t, err)
+	iter, err := unconsolidated.SeriesIter()
+	require.NoError(t, err)
+	assert.Equal(t, models.Bounds{
+		Start:    start,
+		Duration: 3 * time.Minute,
+		StepSize: time.Minute,
+	}, iter.Meta().Bounds)
+
+	dp := func(d time.Duration, v float64) ts.Datapoint {
+		return ts.Datapoint{Timestamp: start.Add(d), Value: v}
+	}
+
+	require.True(t, iter.Next())
+	series := iter.Current()
+	require.Equal(t, 3, series.Len())
+	assert.Equal(t, ts.Datapoints{dp(0, 1)}, series.DatapointsAtStep(0))
+	assert.Equal(t, ts.Datapoints{
+		dp(30*time.Second, 2),
+		dp(time.Minute, 3),
+	}, series.DatapointsAtStep(1))
+	// NB: the NaN value is dropped, and values after the last step are not
+	// included in any step.
+	assert.Equal(t, ts.Datapoints{dp(2*time.Minute, 5)}, series.DatapointsAtStep(2))
+	assert.False(t, iter.Next())
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package temporal
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/executor/transform"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+)
+
+// NewAbsentOp creates a new temporal transform for absent_over_time. The
+// arguments are the range of the selector and the tags of the series returned
+// for steps at which none of the selected series have any values.
+func NewAbsentOp(args []interface{}) (transform.Params, error) {
+	if len(args) != 2 {
+		return emptyOp, fmt.Errorf("invalid number of args for %s: %d", AbsentType, len(args))
+	}
+
+	tags, ok := args[1].(models.Tags)
+	if !ok {
+		return emptyOp, fmt.Errorf("unable to cast to tags argument: %v for %s", args[1], AbsentType)
+	}
+
+	op, err := newBaseOp(args[:1], AbsentType, aggProcessor{aggFunc: presentOverTime})
+	if err != nil {
+		return emptyOp, err
+	}
+
+	return absentOp{baseOp: op, tags: tags}, nil
+}
+
+// absentOp evaluates present_over_time for each series and then reduces the
+// series to a single series which is 1 where none of them were present.
+type absentOp struct {
+	baseOp
+	tags models.Tags
+}
+
+// Node creates an execution node
+func (o absentOp) Node(controller *transform.Controller, opts transform.Options) transform.OpNode {
+	present := &transform.Controller{ID: controller.ID}
+	present.AddTransform(&absentNode{
+		op:         o,
+		controller: controller,
+	})
+
+	return o.baseOp.Node(present, opts)
+}
+
+type absentNode struct {
+	op         absentOp
+	controller *transform.Controller
+}
+
+// Process reduces the present_over_time values of a block to the single
+// absent series, which has a value at every step when no series were selected.
+func (n *absentNode) Process(_ parser.NodeID, b block.Block) error {
+	stepIter, err := b.StepIter()
+	if err != nil {
+		return err
+	}
+
+	meta := stepIter.Meta()
+	meta.Tags = models.NewTags(0, n.op.tags.Opts)
+	seriesMeta := []block.SeriesMeta{{
+		Name: n.op.tags.ID(),
+		Tags: n.op.tags,
+	}}
+
+	builder, err := n.controller.BlockBuilder(meta, seriesMeta)
+	if err != nil {
+		return err
+	}
+
+	if err = builder.AddCols(stepIter.StepCount()); err != nil {
+		return err
+	}
+
+	for index := 0; stepIter.Next(); index++ {
+		absent := 1.0
+		for _, v := range stepIter.Current().Values() {
+			if !math.IsNaN(v) {
+				absent = math.NaN()
+				break
+			}
+		}
+
+		builder.AppendValue(index, absent)
+	}
+
+	if err = stepIter.Err(); err != nil {
+		return err
+	}
+
+	nextBlock := builder.Build()
+	defer nextBlock.Close()
+	return n.controller.Process(nextBlock)
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package temporal
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/executor/transform"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+	"github.com/m3db/m3/src/query/test"
+	"github.com/m3db/m3/src/query/test/executor"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAbsent(t *testing.T, vals [][]float64, expected []float64) {
+	values, bounds := test.GenerateValuesAndBounds(vals, nil)
+	block := test.NewUnconsolidatedBlockFromDatapoints(bounds, values)
+	c, sink := executor.NewControllerWithSink(parser.NodeID(1))
+
+	tags := models.NewTags(1, models.NewTagOptions()).
+		AddTag(models.Tag{Name: []byte("job"), Value: []byte("foo")})
+	op, err := NewAbsentOp([]interface{}{time.Minute, tags})
+	require.NoError(t, err)
+
+	node := op.Node(c, transform.Options{
+		TimeSpec: transform.TimeSpec{
+			Start: bounds.Start,
+			End:   bounds.End(),
+			Step:  time.Minute,
+		},
+	})
+	require.NoError(t, node.Process(parser.NodeID(0), block))
+
+	require.Len(t, sink.Values, 1)
+	test.EqualsWithNansWithDelta(t, expected, sink.Values[0], 0.0001)
+	require.Len(t, sink.Metas, 1)
+	assert.Equal(t, tags, sink.Metas[0].Tags)
+}
+
+func TestAbsentNoSeries(t *testing.T) {
+	testAbsent(t, [][]float64{}, []float64{1, 1, 1, 1, 1})
+}
+
+func TestAbsentWithSeries(t *testing.T) {
+	testAbsent(t, [][]float64{
+		{1, math.NaN(), math.NaN(), 2, math.NaN()},
+		{math.NaN(), math.NaN(), 3, math.NaN(), math.NaN()},
+	}, []float64{math.NaN(), 1, math.NaN(), math.NaN(), 1})
+}
+
+func TestAbsentInvalidArgs(t *testing.T) {
+	_, err := NewAbsentOp([]interface{}{time.Minute})
+	require.Error(t, err)
+
+	_, err = NewAbsentOp([]interface{}{time.Minute, "foo"})
+	require.Error(t, err)
+}
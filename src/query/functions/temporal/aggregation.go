@@ -23,6 +23,7 @@ package temporal
 import (
 	"fmt"
 	"math"
+	"sort"
 	"time"
 
 	"github.com/m3db/m3/src/query/executor/transform"
@@ -50,19 +51,35 @@ const (
 
 	// StdVarType calculates the standard variance of all values in the specified interval.
 	StdVarType = "stdvar_over_time"
+
+	// QuantileType calculates the φ-quantile (0 ≤ φ ≤ 1) of all values in the specified interval.
+	QuantileType = "quantile_over_time"
+
+	// LastType returns the most recent value in the specified interval.
+	LastType = "last_over_time"
+
+	// PresentType returns 1 for any series with a value in the specified interval.
+	PresentType = "present_over_time"
+
+	// AbsentType returns a single series with the value 1 where none of the
+	// selected series have a value in the specified interval, including when no
+	// series match the selector.
+	AbsentType = "absent_over_time"
 )
 
 type aggFunc func([]float64) float64
 
 var (
 	aggFuncs = map[string]aggFunc{
-		AvgType:    avgOverTime,
-		CountType:  countOverTime,
-		MinType:    minOverTime,
-		MaxType:    maxOverTime,
-		SumType:    sumOverTime,
-		StdDevType: stddevOverTime,
-		StdVarType: stdvarOverTime,
+		AvgType:     avgOverTime,
+		CountType:   countOverTime,
+		MinType:     minOverTime,
+		MaxType:     maxOverTime,
+		SumType:     sumOverTime,
+		StdDevType:  stddevOverTime,
+		StdVarType:  stdvarOverTime,
+		LastType:    lastOverTime,
+		PresentType: presentOverTime,
 	}
 )
 
@@ -91,6 +108,28 @@ func NewAggOp(args []interface{}, optype string) (transform.Params, error) {
 	return nil, fmt.Errorf("unknown aggregation type: %s", optype)
 }
 
+// NewQuantileOp creates a new base temporal transform for quantile_over_time.
+func NewQuantileOp(args []interface{}, optype string) (transform.Params, error) {
+	if optype != QuantileType {
+		return emptyOp, fmt.Errorf("unknown quantile type: %s", optype)
+	}
+
+	if len(args) != 2 {
+		return emptyOp, fmt.Errorf("invalid number of args for %s: %d", QuantileType, len(args))
+	}
+
+	q, ok := args[0].(float64)
+	if !ok {
+		return emptyOp, fmt.Errorf("unable to cast to scalar argument: %v for %s", args[0], QuantileType)
+	}
+
+	a := aggProcessor{
+		aggFunc: makeQuantileOverTimeFn(q),
+	}
+
+	return newBaseOp(args[1:], optype, a)
+}
+
 type aggNode struct {
 	op         baseOp
 	controller *transform.Controller
@@ -176,6 +215,64 @@ func stdvarOverTime(values []float64) float64 {
 	return aux / count
 }
 
+func lastOverTime(values []float64) float64 {
+	for i := len(values) - 1; i >= 0; i-- {
+		if !math.IsNaN(values[i]) {
+			return values[i]
+		}
+	}
+
+	return math.NaN()
+}
+
+func presentOverTime(values []float64) float64 {
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			return 1
+		}
+	}
+
+	return math.NaN()
+}
+
+func makeQuantileOverTimeFn(q float64) aggFunc {
+	return func(values []float64) float64 {
+		return quantileOverTime(q, values)
+	}
+}
+
+// quantileOverTime calculates the q-quantile of the non-NaN values, using a
+// weighted average when the quantile lies between two values. Values of q
+// outside [0, 1] give the correctly signed infinity.
+func quantileOverTime(q float64, values []float64) float64 {
+	filtered := make([]float64, 0, len(values))
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			filtered = append(filtered, v)
+		}
+	}
+
+	l := float64(len(filtered))
+	if l == 0 {
+		return math.NaN()
+	}
+
+	if q < 0 {
+		return math.Inf(-1)
+	}
+
+	if q > 1 {
+		return math.Inf(1)
+	}
+
+	sort.Float64s(filtered)
+	rank := q * (l - 1)
+	leftIndex := math.Max(0, math.Floor(rank))
+	rightIndex := math.Min(l-1, leftIndex+1)
+	weight := rank - math.Floor(rank)
+	return filtered[int(leftIndex)]*(1-weight) + filtered[int(rightIndex)]*weight
+}
+
 func sumAndCount(values []float64) (float64, float64) {
 	sum := 0.0
 	count := 0.0
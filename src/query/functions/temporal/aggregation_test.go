@@ -128,6 +128,30 @@ var testCases = []testCase{
 			{2, 2, 2, 2, 2},
 		},
 	},
+	{
+		name:   "last_over_time",
+		opType: LastType,
+		afterBlockOne: [][]float64{
+			{math.NaN(), math.NaN(), math.NaN(), math.NaN(), 4},
+			{math.NaN(), math.NaN(), math.NaN(), math.NaN(), 9},
+		},
+		afterAllBlocks: [][]float64{
+			{0, 1, 2, 3, 4},
+			{5, 6, 7, 8, 9},
+		},
+	},
+	{
+		name:   "present_over_time",
+		opType: PresentType,
+		afterBlockOne: [][]float64{
+			{math.NaN(), math.NaN(), math.NaN(), math.NaN(), 1},
+			{math.NaN(), math.NaN(), math.NaN(), math.NaN(), 1},
+		},
+		afterAllBlocks: [][]float64{
+			{1, 1, 1, 1, 1},
+			{1, 1, 1, 1, 1},
+		},
+	},
 }
 
 func TestAggregation(t *testing.T) {
@@ -223,6 +247,30 @@ var testCasesNaNs = []testCase{
 			{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()},
 		},
 	},
+	{
+		name:   "last_over_time",
+		opType: LastType,
+		afterBlockOne: [][]float64{
+			{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()},
+			{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()},
+		},
+		afterAllBlocks: [][]float64{
+			{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()},
+			{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()},
+		},
+	},
+	{
+		name:   "present_over_time",
+		opType: PresentType,
+		afterBlockOne: [][]float64{
+			{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()},
+			{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()},
+		},
+		afterAllBlocks: [][]float64{
+			{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()},
+			{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()},
+		},
+	},
 }
 
 func TestAggregationAllNaNs(t *testing.T) {
@@ -311,3 +359,42 @@ func TestUnknownAggregation(t *testing.T) {
 	_, err := NewAggOp([]interface{}{5 * time.Minute}, "unknown_agg_func")
 	require.Error(t, err)
 }
+
+func TestQuantileOverTime(t *testing.T) {
+	values := []float64{math.NaN(), 4, 1, 3, 2}
+	fn := makeQuantileOverTimeFn(0.5)
+	assert.Equal(t, 2.5, fn(values))
+
+	fn = makeQuantileOverTimeFn(0.75)
+	assert.Equal(t, 3.25, fn(values))
+
+	fn = makeQuantileOverTimeFn(0)
+	assert.Equal(t, 1.0, fn(values))
+
+	fn = makeQuantileOverTimeFn(1)
+	assert.Equal(t, 4.0, fn(values))
+
+	fn = makeQuantileOverTimeFn(-1)
+	assert.Equal(t, math.Inf(-1), fn(values))
+
+	fn = makeQuantileOverTimeFn(2)
+	assert.Equal(t, math.Inf(1), fn(values))
+
+	assert.True(t, math.IsNaN(fn([]float64{math.NaN()})))
+}
+
+func TestQuantileOp(t *testing.T) {
+	op, err := NewQuantileOp([]interface{}{0.5, 5 * time.Minute}, QuantileType)
+	require.NoError(t, err)
+	assert.Equal(t, QuantileType, op.OpType())
+	assert.Equal(t, 5*time.Minute, op.(baseOp).duration)
+
+	_, err = NewQuantileOp([]interface{}{5 * time.Minute}, QuantileType)
+	assert.Error(t, err)
+
+	_, err = NewQuantileOp([]interface{}{5 * time.Minute, 0.5}, QuantileType)
+	assert.Error(t, err)
+
+	_, err = NewQuantileOp([]interface{}{0.5, 5 * time.Minute}, AvgType)
+	assert.Error(t, err)
+}
@@ -27,6 +27,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/encoding/downsample"
 	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/executor/transform"
 	"github.com/m3db/m3/src/query/models"
@@ -37,7 +38,19 @@ import (
 	"go.uber.org/zap"
 )
 
-var emptyOp = baseOp{}
+var (
+	emptyOp = baseOp{}
+
+	// downsampleAggregations are the aggregations series may be downsampled
+	// with for the temporal functions which give the same result whether
+	// they are applied to the raw datapoints or to the downsampled windows.
+	downsampleAggregations = map[string]downsample.AggregationType{
+		MinType:  downsample.Min,
+		MaxType:  downsample.Max,
+		SumType:  downsample.Sum,
+		LastType: downsample.Last,
+	}
+)
 
 // baseOp stores required properties for logical operations
 type baseOp struct {
@@ -77,6 +90,21 @@ func (o baseOp) String() string {
 	return fmt.Sprintf("type: %s, duration: %v", o.OpType(), o.duration)
 }
 
+// ParentOptions returns the options the range selector of the operation is
+// executed with; temporal functions consume the raw datapoints of a series
+// so the series may only be downsampled with the aggregation the function
+// itself applies over the range, such as the max for max_over_time
+func (o baseOp) ParentOptions(opts transform.Options) transform.Options {
+	aggregation, ok := downsampleAggregations[o.operatorType]
+	if !ok {
+		opts.Downsample = transform.DownsampleOptions{}
+		return opts
+	}
+
+	opts.Downsample.Aggregation = aggregation
+	return opts
+}
+
 // Node creates an execution node
 func (o baseOp) Node(controller *transform.Controller, opts transform.Options) transform.OpNode {
 	return &baseNode{
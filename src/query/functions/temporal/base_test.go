@@ -24,6 +24,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/encoding/downsample"
 	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/executor/transform"
 	"github.com/m3db/m3/src/query/models"
@@ -417,3 +418,40 @@ func TestSingleProcessRequest(t *testing.T) {
 
 	assert.Equal(t, expectedSeriesMetas, sink.Metas, "Process should pass along series meta, renaming to the ID")
 }
+
+func TestBaseParentOptions(t *testing.T) {
+	opts := transform.Options{
+		Downsample: transform.DownsampleOptions{StepRatio: 10},
+	}
+	args := []interface{}{5 * time.Minute}
+
+	// Functions which aggregate the range the same way as the windows may
+	// have their series downsampled with that aggregation.
+	for optype, aggregation := range map[string]downsample.AggregationType{
+		MinType:  downsample.Min,
+		MaxType:  downsample.Max,
+		SumType:  downsample.Sum,
+		LastType: downsample.Last,
+	} {
+		op, err := NewAggOp(args, optype)
+		require.NoError(t, err)
+		parentOpts := op.(transform.ParentOptionsOp).ParentOptions(opts)
+		assert.Equal(t, transform.DownsampleOptions{
+			StepRatio:   10,
+			Aggregation: aggregation,
+		}, parentOpts.Downsample, optype)
+	}
+
+	// Other functions must consume the raw datapoints.
+	for _, optype := range []string{AvgType, CountType, StdDevType} {
+		op, err := NewAggOp(args, optype)
+		require.NoError(t, err)
+		parentOpts := op.(transform.ParentOptionsOp).ParentOptions(opts)
+		assert.False(t, parentOpts.Downsample.Enabled(), optype)
+	}
+
+	op, err := NewRateOp(args, RateType)
+	require.NoError(t, err)
+	parentOpts := op.(transform.ParentOptionsOp).ParentOptions(opts)
+	assert.False(t, parentOpts.Downsample.Enabled())
+}
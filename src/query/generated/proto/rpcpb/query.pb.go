@@ -546,6 +546,7 @@ func (m *Tag) GetValue() []byte {
 type M3CompressedSeries struct {
 	CompressedTags []byte                       `protobuf:"bytes,1,opt,name=compressedTags,proto3" json:"compressedTags,omitempty"`
 	Replicas       []*M3CompressedValuesReplica `protobuf:"bytes,2,rep,name=replicas" json:"replicas,omitempty"`
+	CodecID        uint32                       `protobuf:"varint,3,opt,name=codecID,proto3" json:"codecID,omitempty"`
 }
 
 func (m *M3CompressedSeries) Reset()                    { *m = M3CompressedSeries{} }
@@ -567,6 +568,13 @@ func (m *M3CompressedSeries) GetReplicas() []*M3CompressedValuesReplica {
 	return nil
 }
 
+func (m *M3CompressedSeries) GetCodecID() uint32 {
+	if m != nil {
+		return m.CodecID
+	}
+	return 0
+}
+
 type M3CompressedValuesReplica struct {
 	Segments []*M3Segments `protobuf:"bytes,1,rep,name=segments" json:"segments,omitempty"`
 }
@@ -1869,6 +1877,11 @@ func (m *M3CompressedSeries) MarshalTo(dAtA []byte) (int, error) {
 			i += n
 		}
 	}
+	if m.CodecID != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.CodecID))
+	}
 	return i, nil
 }
 
@@ -2593,6 +2606,9 @@ func (m *M3CompressedSeries) Size() (n int) {
 			n += 1 + l + sovQuery(uint64(l))
 		}
 	}
+	if m.CodecID != 0 {
+		n += 1 + sovQuery(uint64(m.CodecID))
+	}
 	return n
 }
 
@@ -3933,6 +3949,25 @@ func (m *M3CompressedSeries) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CodecID", wireType)
+			}
+			m.CodecID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CodecID |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQuery(dAtA[iNdEx:])
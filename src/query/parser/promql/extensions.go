@@ -0,0 +1,293 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package promql
+
+import (
+	"fmt"
+	"strings"
+
+	pql "github.com/prometheus/prometheus/promql"
+)
+
+// extensionPrefix names the placeholder selectors which calls to M3 functions
+// and subqueries are replaced with before the rest of the query is parsed by
+// the vendored Prometheus parser.
+const extensionPrefix = "__m3_expr_"
+
+var errUnclosedParen = fmt.Errorf("unclosed parenthesis in query")
+
+// extensionParser parses the constructs in a query which the vendored
+// Prometheus parser does not support, being calls to M3 functions and
+// subqueries, and hands the rest of the query to the Prometheus parser with
+// each construct replaced by a placeholder selector. The placeholders are then
+// substituted with the parsed nodes.
+type extensionParser struct {
+	nodes map[string]pql.Expr
+}
+
+func newExtensionParser() *extensionParser {
+	return &extensionParser{
+		nodes: make(map[string]pql.Expr),
+	}
+}
+
+// parse parses the query into an expression which may contain calls to both
+// Prometheus and M3 functions as well as subqueries.
+//
+// NB: subqueries are replaced first, from left to right, and then calls. The
+// inner expression of a subquery and the arguments of a call are parsed
+// recursively, so any constructs nested in them are replaced along with it.
+func (p *extensionParser) parse(q string) (pql.Expr, error) {
+	for {
+		mask := stringMask(q)
+		open, close, found, err := nextSubqueryBrackets(q, mask)
+		if err != nil {
+			return nil, err
+		}
+
+		if !found {
+			break
+		}
+
+		if q, err = p.parseSubquery(q, mask, open, close); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		mask := stringMask(q)
+		start, open, fn, found := nextFunctionCall(q, mask)
+		if !found {
+			break
+		}
+
+		close := matchParenForward(q, mask, open)
+		if close < 0 {
+			return nil, errUnclosedParen
+		}
+
+		args, err := p.parseArgs(q[open+1:close], mask[open+1:close])
+		if err != nil {
+			return nil, err
+		}
+
+		if err := checkFunctionArgs(fn, args); err != nil {
+			return nil, err
+		}
+
+		name := p.add(&pql.Call{Func: fn, Args: args})
+		q = q[:start] + name + q[close+1:]
+	}
+
+	expr, err := pql.ParseExpr(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.substitute(expr)
+}
+
+// add adds a parsed node, returning the name of its placeholder.
+func (p *extensionParser) add(node pql.Expr) string {
+	name := fmt.Sprintf("%s%d__", extensionPrefix, len(p.nodes))
+	p.nodes[name] = node
+	return name
+}
+
+// parseArgs parses the comma separated arguments of a function call.
+func (p *extensionParser) parseArgs(q string, mask []bool) (pql.Expressions, error) {
+	if len(strings.TrimSpace(q)) == 0 {
+		return nil, nil
+	}
+
+	var (
+		args  pql.Expressions
+		depth int
+		start int
+	)
+
+	for i := 0; i <= len(q); i++ {
+		if i < len(q) {
+			if mask[i] {
+				continue
+			}
+
+			switch q[i] {
+			case '(', '[', '{':
+				depth++
+				continue
+			case ')', ']', '}':
+				depth--
+				continue
+			case ',':
+				if depth > 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+
+		arg, err := p.parse(q[start:i])
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, arg)
+		start = i + 1
+	}
+
+	return args, nil
+}
+
+// substitute replaces the placeholder selectors in the expression with the
+// nodes they stand for.
+func (p *extensionParser) substitute(expr pql.Expr) (pql.Expr, error) {
+	var err error
+	switch n := expr.(type) {
+	case *pql.AggregateExpr:
+		if n.Expr, err = p.substitute(n.Expr); err != nil {
+			return nil, err
+		}
+
+		if n.Param, err = p.substitute(n.Param); err != nil {
+			return nil, err
+		}
+
+	case *pql.BinaryExpr:
+		if n.LHS, err = p.substitute(n.LHS); err != nil {
+			return nil, err
+		}
+
+		if n.RHS, err = p.substitute(n.RHS); err != nil {
+			return nil, err
+		}
+
+	case *pql.Call:
+		for i, arg := range n.Args {
+			if n.Args[i], err = p.substitute(arg); err != nil {
+				return nil, err
+			}
+		}
+
+	case *pql.ParenExpr:
+		if n.Expr, err = p.substitute(n.Expr); err != nil {
+			return nil, err
+		}
+
+	case *pql.UnaryExpr:
+		if n.Expr, err = p.substitute(n.Expr); err != nil {
+			return nil, err
+		}
+
+	case *pql.VectorSelector:
+		node, ok := p.nodes[n.Name]
+		if !ok {
+			return n, nil
+		}
+
+		if n.Offset != 0 {
+			return nil, fmt.Errorf(
+				"offset modifier must be preceded by an instant or range selector, but follows %s instead",
+				node)
+		}
+
+		// NB: the only matcher of a placeholder is the one on its name.
+		if len(n.LabelMatchers) != 1 {
+			return nil, fmt.Errorf("label matchers must follow a metric name, but follow %s instead", node)
+		}
+
+		return node, nil
+
+	case *pql.MatrixSelector:
+		node, ok := p.nodes[n.Name]
+		if !ok {
+			return n, nil
+		}
+
+		subquery, ok := node.(*subqueryExpr)
+		if !ok || len(n.LabelMatchers) != 1 {
+			return nil, fmt.Errorf(
+				"range specification must be preceded by a metric selector, but follows %s instead",
+				node)
+		}
+
+		subquery.Range = n.Range
+		subquery.Offset = n.Offset
+		return subquery, nil
+	}
+
+	return expr, nil
+}
+
+// nextFunctionCall finds the first call to an M3 function in the query,
+// returning the start of the call, the position of its opening parenthesis and
+// the function called.
+func nextFunctionCall(q string, mask []bool) (int, int, *pql.Function, bool) {
+	for i := 0; i < len(q); i++ {
+		if mask[i] || !isIdentByte(q[i]) {
+			continue
+		}
+
+		end := i
+		for end < len(q) && isIdentByte(q[end]) && !mask[end] {
+			end++
+		}
+
+		fn, ok := m3Functions[q[i:end]]
+		if open := skipSpaceForward(q, end); ok && open < len(q) && q[open] == '(' {
+			return i, open, fn, true
+		}
+
+		i = end - 1
+	}
+
+	return 0, 0, nil, false
+}
+
+func matchParenForward(q string, mask []bool, open int) int {
+	depth := 0
+	for i := open; i < len(q); i++ {
+		if mask[i] {
+			continue
+		}
+
+		switch q[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+func skipSpaceForward(q string, i int) int {
+	for i < len(q) && (q[i] == ' ' || q[i] == '\t' || q[i] == '\n' || q[i] == '\r') {
+		i++
+	}
+
+	return i
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package promql
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/query/functions/temporal"
+	"github.com/m3db/m3/src/query/models"
+
+	pql "github.com/prometheus/prometheus/promql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtensionParse(t *testing.T) {
+	for _, q := range []string{
+		"last_over_time(x[5m])",
+		"present_over_time(x[5m]) + absent_over_time(y[1m])",
+		`max_over_time(x{a="last_over_time(y)"}[5m])`,
+		"abs(present_over_time(x[5m]))",
+	} {
+		t.Run(q, func(t *testing.T) {
+			expr, err := newExtensionParser().parse(q)
+			require.NoError(t, err)
+			assert.Equal(t, q, expr.String())
+		})
+	}
+}
+
+func TestExtensionParseCall(t *testing.T) {
+	expr, err := newExtensionParser().parse("last_over_time(x[5m])")
+	require.NoError(t, err)
+	call, ok := expr.(*pql.Call)
+	require.True(t, ok)
+	assert.Equal(t, temporal.LastType, call.Func.Name)
+	require.Len(t, call.Args, 1)
+	assert.Equal(t, pql.ValueTypeMatrix, call.Args[0].Type())
+}
+
+func TestExtensionParseErrors(t *testing.T) {
+	for _, q := range []string{
+		"last_over_time()",
+		"last_over_time(x)",
+		"last_over_time(x[5m], 1)",
+		"last_over_time(x[5m]",
+		"last_over_time(x[5m]) offset 5m",
+		"last_over_time(x[5m])[5m]",
+		`last_over_time(x[5m]){a="b"}`,
+		"rate(last_over_time(x[5m]))",
+	} {
+		t.Run(q, func(t *testing.T) {
+			_, err := newExtensionParser().parse(q)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAbsentTags(t *testing.T) {
+	expr, err := pql.ParseExpr(`up{job="foo",a="b",a="c",c=~"d",c="e"}[5m]`)
+	require.NoError(t, err)
+
+	tagOpts := models.NewTagOptions()
+	expected := models.NewTags(1, tagOpts).
+		AddTag(models.Tag{Name: []byte("job"), Value: []byte("foo")})
+	assert.Equal(t, expected, absentTags(expr, tagOpts))
+
+	expr, err = pql.ParseExpr("sum(up)")
+	require.NoError(t, err)
+	assert.Equal(t, models.NewTags(0, tagOpts), absentTags(expr, tagOpts))
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package promql
+
+import (
+	"fmt"
+
+	"github.com/m3db/m3/src/query/functions/temporal"
+
+	pql "github.com/prometheus/prometheus/promql"
+)
+
+// m3Functions are the functions which M3 supports but the vendored Prometheus
+// parser does not; calls to them are parsed by M3 and handed to the
+// Prometheus parser as placeholders. They have no Call implementation since
+// they are only ever executed by M3.
+var m3Functions = map[string]*pql.Function{
+	temporal.LastType: {
+		Name:       temporal.LastType,
+		ArgTypes:   []pql.ValueType{pql.ValueTypeMatrix},
+		ReturnType: pql.ValueTypeVector,
+	},
+	temporal.PresentType: {
+		Name:       temporal.PresentType,
+		ArgTypes:   []pql.ValueType{pql.ValueTypeMatrix},
+		ReturnType: pql.ValueTypeVector,
+	},
+	temporal.AbsentType: {
+		Name:       temporal.AbsentType,
+		ArgTypes:   []pql.ValueType{pql.ValueTypeMatrix},
+		ReturnType: pql.ValueTypeVector,
+	},
+}
+
+// checkFunctionArgs validates the arguments of a call to an M3 function in the
+// same way the Prometheus parser validates calls to its own functions.
+func checkFunctionArgs(fn *pql.Function, args pql.Expressions) error {
+	nargs := len(fn.ArgTypes)
+	if fn.Variadic == 0 {
+		if nargs != len(args) {
+			return fmt.Errorf("expected %d argument(s) in call to %q, got %d",
+				nargs, fn.Name, len(args))
+		}
+	} else {
+		na := nargs - 1
+		if na > len(args) {
+			return fmt.Errorf("expected at least %d argument(s) in call to %q, got %d",
+				na, fn.Name, len(args))
+		} else if nargsmax := na + fn.Variadic; fn.Variadic > 0 && nargsmax < len(args) {
+			return fmt.Errorf("expected at most %d argument(s) in call to %q, got %d",
+				nargsmax, fn.Name, len(args))
+		}
+	}
+
+	for i, arg := range args {
+		expected := fn.ArgTypes[nargs-1]
+		if i < nargs {
+			expected = fn.ArgTypes[i]
+		}
+
+		if arg.Type() != expected {
+			return fmt.Errorf("expected type %s in call to function %q, got %s",
+				expected, fn.Name, arg.Type())
+		}
+	}
+
+	return nil
+}
@@ -23,7 +23,9 @@ package promql
 import (
 	"fmt"
 
+	"github.com/m3db/m3/src/query/functions"
 	"github.com/m3db/m3/src/query/functions/scalar"
+	"github.com/m3db/m3/src/query/functions/temporal"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/parser"
 
@@ -37,7 +39,7 @@ type promParser struct {
 
 // Parse takes a promQL string and converts parses it into a DAG
 func Parse(q string, tagOpts models.TagOptions) (parser.Parser, error) {
-	expr, err := pql.ParseExpr(q)
+	expr, err := newExtensionParser().parse(q)
 	if err != nil {
 		return nil, err
 	}
@@ -141,7 +143,10 @@ func (p *parseState) walk(node pql.Node) error {
 			} else if argType == pql.ValueTypeString {
 				stringValues = append(stringValues, expr.(*pql.StringLiteral).Val)
 			} else {
-				if e, ok := expr.(*pql.MatrixSelector); ok {
+				switch e := expr.(type) {
+				case *pql.MatrixSelector:
+					argValues = append(argValues, e.Range)
+				case *subqueryExpr:
 					argValues = append(argValues, e.Range)
 				}
 
@@ -160,6 +165,10 @@ func (p *parseState) walk(node pql.Node) error {
 			}
 		}
 
+		if n.Func.Name == temporal.AbsentType {
+			argValues = append(argValues, absentTags(expressions[0], p.tagOpts))
+		}
+
 		op, ok, err := NewFunctionExpr(n.Func.Name, argValues, stringValues)
 		if err != nil {
 			return err
@@ -223,6 +232,25 @@ func (p *parseState) walk(node pql.Node) error {
 		// Evaluate inside of paren expressions
 		return p.walk(n.Expr)
 
+	case *subqueryExpr:
+		err := p.walk(n.Expr)
+		if err != nil {
+			return err
+		}
+
+		op, err := functions.NewSubqueryOp(n.Range, n.Step, n.Offset)
+		if err != nil {
+			return err
+		}
+
+		opTransform := parser.NewTransformFromOperation(op, p.transformLen())
+		p.edges = append(p.edges, parser.Edge{
+			ParentID: p.lastTransformID(),
+			ChildID:  opTransform.ID,
+		})
+		p.transforms = append(p.transforms, opTransform)
+		return nil
+
 	default:
 		return fmt.Errorf("promql.Walk: unhandled node type %T, %v", node, node)
 	}
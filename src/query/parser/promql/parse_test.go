@@ -22,6 +22,7 @@ package promql
 
 import (
 	"testing"
+	"time"
 
 	"github.com/m3db/m3/src/query/functions"
 	"github.com/m3db/m3/src/query/functions/aggregation"
@@ -119,6 +120,7 @@ var linearParseTests = []struct {
 	{"log10(up)", linear.Log10Type},
 	{"sqrt(up)", linear.SqrtType},
 	{"round(up, 10)", linear.RoundType},
+	{"histogram_quantile(0.9, up)", linear.HistogramQuantileType},
 
 	{"day_of_month(up)", linear.DayOfMonthType},
 	{"day_of_week(up)", linear.DayOfWeekType},
@@ -297,6 +299,10 @@ var temporalParseTests = []struct {
 	{"sum_over_time(up[5m])", temporal.SumType},
 	{"stddev_over_time(up[5m])", temporal.StdDevType},
 	{"stdvar_over_time(up[5m])", temporal.StdVarType},
+	{"quantile_over_time(0.9, up[5m])", temporal.QuantileType},
+	{"last_over_time(up[5m])", temporal.LastType},
+	{"present_over_time(up[5m])", temporal.PresentType},
+	{"absent_over_time(up[5m])", temporal.AbsentType},
 	{"irate(up[5m])", temporal.IRateType},
 	{"idelta(up[5m])", temporal.IDeltaType},
 	{"rate(up[5m])", temporal.RateType},
@@ -357,8 +363,61 @@ func TestTagParses(t *testing.T) {
 	}
 }
 
+func TestFailedTemporalArgumentParse(t *testing.T) {
+	for _, q := range []string{
+		"quantile_over_time(up[5m])",
+		"last_over_time(up)",
+		"present_over_time(up[5m], 1)",
+	} {
+		t.Run(q, func(t *testing.T) {
+			_, err := Parse(q, models.NewTagOptions())
+			require.Error(t, err)
+		})
+	}
+}
+
 func TestFailedTemporalParse(t *testing.T) {
 	q := "unknown_over_time(http_requests_total[5m])"
 	_, err := Parse(q, models.NewTagOptions())
 	require.Error(t, err)
 }
+
+func TestSubqueryParses(t *testing.T) {
+	q := "max_over_time(rate(http_requests_total[5m])[1h:1m])"
+	p, err := Parse(q, models.NewTagOptions())
+	require.NoError(t, err)
+	assert.Equal(t, q, p.String())
+	transforms, edges, err := p.DAG()
+	require.NoError(t, err)
+	require.Len(t, transforms, 4)
+	assert.Equal(t, transforms[0].Op.OpType(), functions.FetchType)
+	assert.Equal(t, transforms[1].Op.OpType(), temporal.RateType)
+	assert.Equal(t, transforms[2].Op.OpType(), functions.SubqueryType)
+	assert.Equal(t, transforms[3].Op.OpType(), temporal.MaxType)
+
+	subquery, ok := transforms[2].Op.(functions.SubqueryOp)
+	require.True(t, ok)
+	assert.Equal(t, time.Hour, subquery.Range)
+	assert.Equal(t, time.Minute, subquery.Step)
+
+	require.Len(t, edges, 3)
+	for i, edge := range edges {
+		assert.Equal(t, transforms[i].ID, edge.ParentID)
+		assert.Equal(t, transforms[i+1].ID, edge.ChildID)
+	}
+}
+
+func TestSubqueryOffsetParses(t *testing.T) {
+	q := "max_over_time(rate(http_requests_total[5m])[30m:1m] offset 1h)"
+	p, err := Parse(q, models.NewTagOptions())
+	require.NoError(t, err)
+	transforms, _, err := p.DAG()
+	require.NoError(t, err)
+	require.Len(t, transforms, 4)
+
+	subquery, ok := transforms[2].Op.(functions.SubqueryOp)
+	require.True(t, ok)
+	assert.Equal(t, 30*time.Minute, subquery.Range)
+	assert.Equal(t, time.Minute, subquery.Step)
+	assert.Equal(t, time.Hour, subquery.Offset)
+}
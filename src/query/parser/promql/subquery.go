@@ -0,0 +1,305 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package promql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	pql "github.com/prometheus/prometheus/promql"
+)
+
+var (
+	errEmptySubquery   = fmt.Errorf("subquery has no expression")
+	errUnclosedBracket = fmt.Errorf("unclosed bracket in query")
+)
+
+// subqueryExpr is a subquery of the form `<expr>[<range>:<step>] offset <offset>`,
+// which evaluates the inner expression at the given step over the range. A
+// step of zero denotes that the inner expression is evaluated at the
+// resolution of the query.
+type subqueryExpr struct {
+	pql.Expr
+
+	Range  time.Duration
+	Step   time.Duration
+	Offset time.Duration
+}
+
+// Type returns the type of the subquery, which is always a range vector.
+func (e *subqueryExpr) Type() pql.ValueType {
+	return pql.ValueTypeMatrix
+}
+
+func (e *subqueryExpr) String() string {
+	var step, offset string
+	if e.Step > 0 {
+		step = model.Duration(e.Step).String()
+	}
+
+	if e.Offset != 0 {
+		offset = fmt.Sprintf(" offset %s", model.Duration(e.Offset))
+	}
+
+	return fmt.Sprintf("%s[%s:%s]%s", e.Expr, model.Duration(e.Range), step, offset)
+}
+
+// parseSubquery parses the subquery whose brackets open and close at the given
+// positions, returning the query with the subquery replaced by a placeholder
+// range selector `<placeholder>[<range>]`. The range and offset of the
+// subquery are taken from the placeholder once the query has been parsed.
+//
+// NB: the leftmost subquery brackets can never be within the inner expression
+// of another subquery, so an inner expression may only contain placeholders
+// for subqueries which have already been parsed.
+func (p *extensionParser) parseSubquery(
+	q string,
+	mask []bool,
+	open, close int,
+) (string, error) {
+	start := subqueryExprStart(q, mask, open)
+	inner := strings.TrimSpace(q[start:open])
+	if len(inner) == 0 {
+		return "", errEmptySubquery
+	}
+
+	expr, err := p.parse(inner)
+	if err != nil {
+		return "", err
+	}
+
+	if expr.Type() != pql.ValueTypeVector {
+		return "", fmt.Errorf(
+			"subquery is only allowed on instant vector, got %s in %q instead",
+			expr.Type(), inner)
+	}
+
+	rangeStr, step, err := parseSubqueryRange(q[open+1 : close])
+	if err != nil {
+		return "", err
+	}
+
+	name := p.add(&subqueryExpr{Expr: expr, Step: step})
+	return q[:start] + name + "[" + rangeStr + "]" + q[close+1:], nil
+}
+
+// parseSubqueryRange parses the contents of subquery brackets, returning the
+// range as a string suitable for a range selector and the parsed step.
+func parseSubqueryRange(contents string) (string, time.Duration, error) {
+	parts := strings.SplitN(contents, ":", 2)
+	rangeStr := strings.TrimSpace(parts[0])
+	if _, err := model.ParseDuration(rangeStr); err != nil {
+		return "", 0, fmt.Errorf("invalid subquery range %q: %v", rangeStr, err)
+	}
+
+	stepStr := strings.TrimSpace(parts[1])
+	if len(stepStr) == 0 {
+		return rangeStr, 0, nil
+	}
+
+	step, err := model.ParseDuration(stepStr)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid subquery step %q: %v", stepStr, err)
+	}
+
+	return rangeStr, time.Duration(step), nil
+}
+
+// stringMask marks every byte in the query which is part of a string literal.
+func stringMask(q string) []bool {
+	mask := make([]bool, len(q))
+	var quote byte
+	for i := 0; i < len(q); i++ {
+		c := q[i]
+		if quote == 0 {
+			if c == '"' || c == '\'' || c == '`' {
+				quote = c
+				mask[i] = true
+			}
+
+			continue
+		}
+
+		mask[i] = true
+		if c == '\\' && quote != '`' && i+1 < len(q) {
+			i++
+			mask[i] = true
+		} else if c == quote {
+			quote = 0
+		}
+	}
+
+	return mask
+}
+
+// nextSubqueryBrackets finds the first pair of square brackets in the query
+// which contain a colon, denoting a subquery rather than a range selector.
+func nextSubqueryBrackets(q string, mask []bool) (int, int, bool, error) {
+	for i := 0; i < len(q); i++ {
+		if mask[i] || q[i] != '[' {
+			continue
+		}
+
+		close := strings.IndexByte(q[i:], ']')
+		if close < 0 {
+			return 0, 0, false, errUnclosedBracket
+		}
+
+		close += i
+		if strings.IndexByte(q[i:close], ':') >= 0 {
+			return i, close, true, nil
+		}
+
+		i = close
+	}
+
+	return 0, 0, false, nil
+}
+
+type exprToken int
+
+const (
+	noToken exprToken = iota
+	identToken
+	groupToken
+)
+
+// subqueryExprStart scans backwards from the opening subquery bracket to find
+// the start of the expression the subquery applies to. The expression is the
+// longest run of identifiers and bracketed groups which can form a single
+// operand, e.g. `rate(x[5m])`, `x{a="b"}` or `sum by (a) (x)`.
+func subqueryExprStart(q string, mask []bool, open int) int {
+	var (
+		start      = open
+		right      = noToken
+		rightIdent string
+	)
+
+	for i := skipSpaceBackward(q, open-1); i >= 0 && !mask[i]; {
+		c := q[i]
+		switch {
+		case c == ')' || c == '}' || c == ']':
+			// A group may only directly precede another group, or grouping
+			// keywords as in `sum(x) by (a)`.
+			if right == identToken && !isGroupingKeyword(rightIdent) {
+				return start
+			}
+
+			groupStart := matchBracketBackward(q, mask, i)
+			if groupStart < 0 {
+				return start
+			}
+
+			start, right = groupStart, groupToken
+			i = skipSpaceBackward(q, groupStart-1)
+
+		case isIdentByte(c):
+			identStart := i
+			for identStart > 0 && isIdentByte(q[identStart-1]) && !mask[identStart-1] {
+				identStart--
+			}
+
+			ident := q[identStart : i+1]
+			if isOperatorKeyword(ident) {
+				return start
+			}
+
+			// An identifier may only directly precede another identifier when
+			// that identifier is a grouping keyword, as in `sum by (a) (x)`.
+			if right == identToken && !isGroupingKeyword(rightIdent) {
+				return start
+			}
+
+			start, right, rightIdent = identStart, identToken, ident
+			i = skipSpaceBackward(q, identStart-1)
+
+		default:
+			return start
+		}
+	}
+
+	return start
+}
+
+func matchBracketBackward(q string, mask []bool, close int) int {
+	var open byte
+	switch q[close] {
+	case ')':
+		open = '('
+	case '}':
+		open = '{'
+	default:
+		open = '['
+	}
+
+	depth := 0
+	for i := close; i >= 0; i-- {
+		if mask[i] {
+			continue
+		}
+
+		switch q[i] {
+		case q[close]:
+			depth++
+		case open:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+func skipSpaceBackward(q string, i int) int {
+	for i >= 0 && (q[i] == ' ' || q[i] == '\t' || q[i] == '\n' || q[i] == '\r') {
+		i--
+	}
+
+	return i
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == ':' ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+func isGroupingKeyword(ident string) bool {
+	switch strings.ToLower(ident) {
+	case "by", "without":
+		return true
+	}
+
+	return false
+}
+
+func isOperatorKeyword(ident string) bool {
+	switch strings.ToLower(ident) {
+	case "and", "or", "unless", "on", "ignoring", "group_left",
+		"group_right", "bool", "offset":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package promql
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type expectedSubquery struct {
+	inner      string
+	queryRange time.Duration
+	step       time.Duration
+	offset     time.Duration
+}
+
+var parseSubqueryTests = []struct {
+	q          string
+	subqueries []expectedSubquery
+}{
+	{"x[5m]", nil},
+	{
+		"max_over_time(rate(x[5m])[1h:1m])",
+		[]expectedSubquery{{"rate(x[5m])", time.Hour, time.Minute, 0}},
+	},
+	{
+		"max_over_time(max_over_time(x[5m:])[1h:])",
+		[]expectedSubquery{
+			{"x", 5 * time.Minute, 0, 0},
+			{"max_over_time(x[5m:])", time.Hour, 0, 0},
+		},
+	},
+	{
+		`a + count_over_time(sum by (a) (rate(x{b="[c:d]"}[5m]))[1h:30s] offset 5m)`,
+		[]expectedSubquery{
+			{`sum by (a) (rate(x{b="[c:d]"}[5m]))`, time.Hour, 30 * time.Second, 5 * time.Minute},
+		},
+	},
+	{
+		"avg_over_time(sum(x) by (a)[10m:])",
+		[]expectedSubquery{{"sum(x) by (a)", 10 * time.Minute, 0, 0}},
+	},
+	{
+		"rate(x[1m])[5m:1m]",
+		[]expectedSubquery{{"rate(x[1m])", 5 * time.Minute, time.Minute, 0}},
+	},
+	{
+		"min_over_time((a + b)[5m:1m])",
+		[]expectedSubquery{{"(a + b)", 5 * time.Minute, time.Minute, 0}},
+	},
+	{
+		"max_over_time(last_over_time(x[5m])[1h:])",
+		[]expectedSubquery{{"last_over_time(x[5m])", time.Hour, 0, 0}},
+	},
+}
+
+func TestParseSubqueries(t *testing.T) {
+	for _, tt := range parseSubqueryTests {
+		t.Run(tt.q, func(t *testing.T) {
+			p := newExtensionParser()
+			_, err := p.parse(tt.q)
+			require.NoError(t, err)
+
+			var subqueries []*subqueryExpr
+			for i := 0; i < len(p.nodes); i++ {
+				name := fmt.Sprintf("%s%d__", extensionPrefix, i)
+				if sq, ok := p.nodes[name].(*subqueryExpr); ok {
+					subqueries = append(subqueries, sq)
+				}
+			}
+
+			require.Len(t, subqueries, len(tt.subqueries))
+			for i, expected := range tt.subqueries {
+				inner, err := newExtensionParser().parse(expected.inner)
+				require.NoError(t, err)
+				assert.Equal(t, inner.String(), subqueries[i].Expr.String())
+				assert.Equal(t, expected.queryRange, subqueries[i].Range)
+				assert.Equal(t, expected.step, subqueries[i].Step)
+				assert.Equal(t, expected.offset, subqueries[i].Offset)
+			}
+		})
+	}
+}
+
+func TestSubqueryString(t *testing.T) {
+	for _, q := range []string{
+		"max_over_time(rate(x[5m])[1h:1m])",
+		"max_over_time(max_over_time(x[5m:])[1h:])",
+		"max_over_time(rate(x[5m])[1h:] offset 5m)",
+	} {
+		t.Run(q, func(t *testing.T) {
+			expr, err := newExtensionParser().parse(q)
+			require.NoError(t, err)
+			assert.Equal(t, q, expr.String())
+		})
+	}
+}
+
+func TestParseSubqueriesErrors(t *testing.T) {
+	for _, q := range []string{
+		"[5m:]",
+		"max_over_time(x[5m][1h:])",
+		"max_over_time(x[1x:])",
+		"max_over_time(x[1h:1x])",
+		"max_over_time(x[1h:",
+		"a and rate(x[1m])[5m:1m]",
+		"max_over_time(rate(x[5m])[1h:]{a=\"b\"})",
+	} {
+		t.Run(q, func(t *testing.T) {
+			_, err := newExtensionParser().parse(q)
+			assert.Error(t, err)
+		})
+	}
+}
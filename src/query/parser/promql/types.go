@@ -193,6 +193,10 @@ func NewFunctionExpr(
 		p, err = linear.NewRoundOp(argValues)
 		return p, true, err
 
+	case linear.HistogramQuantileType:
+		p, err = linear.NewHistogramQuantileOp(argValues, name)
+		return p, true, err
+
 	case linear.DayOfMonthType, linear.DayOfWeekType, linear.DaysInMonthType, linear.HourType,
 		linear.MinuteType, linear.MonthType, linear.YearType:
 		p, err = linear.NewDateOp(name)
@@ -204,10 +208,18 @@ func NewFunctionExpr(
 
 	case temporal.AvgType, temporal.CountType, temporal.MinType,
 		temporal.MaxType, temporal.SumType, temporal.StdDevType,
-		temporal.StdVarType:
+		temporal.StdVarType, temporal.LastType, temporal.PresentType:
 		p, err = temporal.NewAggOp(argValues, name)
 		return p, true, err
 
+	case temporal.AbsentType:
+		p, err = temporal.NewAbsentOp(argValues)
+		return p, true, err
+
+	case temporal.QuantileType:
+		p, err = temporal.NewQuantileOp(argValues, name)
+		return p, true, err
+
 	case temporal.HoltWintersType:
 		p, err = temporal.NewHoltWintersOp(argValues)
 		return p, true, err
@@ -317,6 +329,38 @@ func LabelMatchersToModelMatcher(
 	return matchers, nil
 }
 
+// absentTags returns the tags of the series which absent_over_time returns
+// when nothing matches its selector. As in Prometheus, these are taken from the
+// equality matchers of the selector, excluding the metric name and any label
+// which is matched more than once or by any other type of matcher.
+func absentTags(expr promql.Expr, tagOpts models.TagOptions) models.Tags {
+	tags := models.NewTags(0, tagOpts)
+	selector, ok := expr.(*promql.MatrixSelector)
+	if !ok {
+		return tags
+	}
+
+	dropped := make(map[string]bool, len(selector.LabelMatchers))
+	seen := make(map[string]bool, len(selector.LabelMatchers))
+	for _, m := range selector.LabelMatchers {
+		if m.Type != labels.MatchEqual || seen[m.Name] {
+			dropped[m.Name] = true
+		}
+
+		seen[m.Name] = true
+	}
+
+	for _, m := range selector.LabelMatchers {
+		if m.Name == promDefaultName || dropped[m.Name] {
+			continue
+		}
+
+		tags = tags.AddTag(models.Tag{Name: []byte(m.Name), Value: []byte(m.Value)})
+	}
+
+	return tags
+}
+
 // promTypeToM3 converts a prometheus label type to m3 matcher type
 //TODO(nikunj): Consider merging with prompb code
 func promTypeToM3(labelType labels.MatchType) (models.MatchType, error) {
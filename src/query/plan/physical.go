@@ -39,6 +39,10 @@ type PhysicalPlan struct {
 	Debug            bool
 	BlockType        models.FetchedBlockType
 	LookbackDuration time.Duration
+	// DownsampleStepRatio is how many times smaller than the step the
+	// resolution series may be downsampled to on the database nodes is,
+	// series are fetched raw if zero.
+	DownsampleStepRatio int
 }
 
 // ResultOp is resonsible for delivering results to the clients
@@ -77,9 +81,8 @@ func NewPhysicalPlan(lp LogicalPlan, storage storage.Storage, params models.Requ
 }
 
 func (p PhysicalPlan) shiftTime() PhysicalPlan {
-	var maxRange time.Duration
 	// Start offset with lookback
-	maxOffset := p.LookbackDuration
+	startShift := p.LookbackDuration
 	for _, transformID := range p.pipeline {
 		node := p.steps[transformID]
 		boundOp, ok := node.Transform.Op.(transform.BoundOp)
@@ -87,22 +90,50 @@ func (p PhysicalPlan) shiftTime() PhysicalPlan {
 			continue
 		}
 
+		// NB: nodes nested within bound operations, such as the inner
+		// expression of a subquery, must also cover the bounds of every
+		// enclosing operation. The offset and range of each node are taken
+		// together, since taking the largest offset and largest range
+		// separately counts the enclosing bounds once for each of them.
 		spec := boundOp.Bounds()
-		if spec.Offset+p.LookbackDuration > maxOffset {
-			maxOffset = spec.Offset + p.LookbackDuration
-		}
-
-		if spec.Range > maxRange {
-			maxRange = spec.Range
+		enclosing := p.enclosingBounds(node)
+		shift := spec.Offset + spec.Range + enclosing.Offset + enclosing.Range +
+			p.LookbackDuration
+		if shift > startShift {
+			startShift = shift
 		}
 	}
 
-	startShift := maxOffset + maxRange
 	// keeping end the same for now, might optimize later
 	p.TimeSpec.Start = p.TimeSpec.Start.Add(-1 * startShift)
 	return p
 }
 
+// enclosingBounds returns the combined bounds of all bound operations which
+// consume the output of the given step, taking the largest across branches.
+func (p PhysicalPlan) enclosingBounds(step LogicalStep) transform.BoundSpec {
+	var max transform.BoundSpec
+	for _, childID := range step.Children {
+		child, ok := p.steps[childID]
+		if !ok {
+			continue
+		}
+
+		spec := p.enclosingBounds(child)
+		if boundOp, ok := child.Transform.Op.(transform.BoundOp); ok {
+			childSpec := boundOp.Bounds()
+			spec.Range += childSpec.Range
+			spec.Offset += childSpec.Offset
+		}
+
+		if spec.Range+spec.Offset > max.Range+max.Offset {
+			max = spec
+		}
+	}
+
+	return max
+}
+
 func (p PhysicalPlan) createResultNode() (PhysicalPlan, error) {
 	leaf, err := p.leafNode()
 	if err != nil {
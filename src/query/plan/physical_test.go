@@ -26,6 +26,7 @@ import (
 
 	"github.com/m3db/m3/src/query/functions"
 	"github.com/m3db/m3/src/query/functions/aggregation"
+	"github.com/m3db/m3/src/query/functions/binary"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/parser"
 
@@ -86,3 +87,64 @@ func TestShiftTime(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, p.TimeSpec.Start, start.Add(-1*(time.Minute+time.Hour+defaultLookbackDuration)), "start time offset by fetch")
 }
+
+func TestShiftTimeSubquery(t *testing.T) {
+	fetchTransform := parser.NewTransformFromOperation(functions.FetchOp{Range: 5 * time.Minute}, 1)
+	subquery, err := functions.NewSubqueryOp(time.Hour, time.Minute, 0)
+	require.NoError(t, err)
+	subqueryTransform := parser.NewTransformFromOperation(subquery, 2)
+	transforms := parser.Nodes{fetchTransform, subqueryTransform}
+	edges := parser.Edges{
+		parser.Edge{
+			ParentID: fetchTransform.ID,
+			ChildID:  subqueryTransform.ID,
+		},
+	}
+
+	lp, err := NewLogicalPlan(transforms, edges)
+	require.NoError(t, err)
+	now := time.Now()
+	start := now.Add(-1 * time.Hour)
+	p, err := NewPhysicalPlan(lp, nil, models.RequestParams{Now: now, Start: start}, defaultLookbackDuration)
+	require.NoError(t, err)
+	expected := start.Add(-1 * (time.Hour + 5*time.Minute + defaultLookbackDuration))
+	assert.Equal(t, expected, p.TimeSpec.Start, "start time offset by fetch and enclosing subquery")
+}
+
+func TestShiftTimeSubqueryBranches(t *testing.T) {
+	offsetTransform := parser.NewTransformFromOperation(functions.FetchOp{Offset: 2 * time.Hour}, 1)
+	fetchTransform := parser.NewTransformFromOperation(functions.FetchOp{Range: 5 * time.Minute}, 2)
+	subquery, err := functions.NewSubqueryOp(time.Hour, time.Minute, 0)
+	require.NoError(t, err)
+	subqueryTransform := parser.NewTransformFromOperation(subquery, 3)
+	plus, err := binary.NewOp(binary.PlusType, binary.NodeParams{
+		LNode: offsetTransform.ID,
+		RNode: subqueryTransform.ID,
+	})
+	require.NoError(t, err)
+	plusTransform := parser.NewTransformFromOperation(plus, 4)
+	transforms := parser.Nodes{offsetTransform, fetchTransform, subqueryTransform, plusTransform}
+	edges := parser.Edges{
+		parser.Edge{
+			ParentID: fetchTransform.ID,
+			ChildID:  subqueryTransform.ID,
+		},
+		parser.Edge{
+			ParentID: offsetTransform.ID,
+			ChildID:  plusTransform.ID,
+		},
+		parser.Edge{
+			ParentID: subqueryTransform.ID,
+			ChildID:  plusTransform.ID,
+		},
+	}
+
+	lp, err := NewLogicalPlan(transforms, edges)
+	require.NoError(t, err)
+	now := time.Now()
+	start := now.Add(-1 * time.Hour)
+	p, err := NewPhysicalPlan(lp, nil, models.RequestParams{Now: now, Start: start}, defaultLookbackDuration)
+	require.NoError(t, err)
+	expected := start.Add(-1 * (2*time.Hour + defaultLookbackDuration))
+	assert.Equal(t, expected, p.TimeSpec.Start, "start time offset by the largest branch only")
+}
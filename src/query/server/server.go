@@ -182,6 +182,11 @@ func Run(runOpts RunOptions) {
 	}
 	cfg.LookbackDuration = &lookbackDuration
 
+	downsampleStepRatio, err := cfg.ServerSideDownsample.StepRatioOrDefault()
+	if err != nil {
+		logger.Fatal("error validating ServerSideDownsample", zap.Error(err))
+	}
+
 	var (
 		m3dbClusters    m3.Clusters
 		m3dbPoolWrapper *pools.PoolWrapper
@@ -228,7 +233,8 @@ func Run(runOpts RunOptions) {
 		defer cleanup()
 	}
 
-	engine := executor.NewEngine(backendStorage, scope.SubScope("engine"), *cfg.LookbackDuration)
+	engine := executor.NewEngine(backendStorage, scope.SubScope("engine"),
+		*cfg.LookbackDuration, downsampleStepRatio)
 
 	downsamplerAndWriter, err := newDownsamplerAndWriter(backendStorage, downsampler)
 	if err != nil {
@@ -587,12 +593,18 @@ func newStorages(
 ) (storage.Storage, cleanupFn, error) {
 	cleanup := func() error { return nil }
 
+	var stitchingOpts m3.StitchingOptions
+	if cfg.Stitching != nil {
+		stitchingOpts = cfg.Stitching.NewStitchingOptions()
+	}
+
 	localStorage := m3.NewStorage(
 		clusters,
 		readWorkerPool,
 		writeWorkerPool,
 		tagOptions,
 		*cfg.LookbackDuration,
+		stitchingOpts,
 	)
 	stores := []storage.Storage{localStorage}
 	remoteEnabled := false
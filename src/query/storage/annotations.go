@@ -0,0 +1,218 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/query/models"
+)
+
+// AnnotatedDatapoint is a datapoint written with an annotation.
+type AnnotatedDatapoint struct {
+	Timestamp  time.Time
+	Value      float64
+	Annotation []byte
+}
+
+// Annotations collects the annotated datapoints read by a query keyed by the
+// tags of the series they were read from, it is safe for concurrent use.
+type Annotations struct {
+	sync.Mutex
+
+	series map[string]*annotatedSeries
+}
+
+type annotatedSeries struct {
+	datapoints []AnnotatedDatapoint
+	seen       map[int64]struct{}
+}
+
+// NewAnnotations returns a new set of annotations.
+func NewAnnotations() *Annotations {
+	return &Annotations{series: make(map[string]*annotatedSeries)}
+}
+
+// Add adds an annotated datapoint of the series with the given tags, the
+// annotation is copied.
+func (a *Annotations) Add(tags models.Tags, dp AnnotatedDatapoint) {
+	a.add(annotationsKey(tags), dp)
+}
+
+func (a *Annotations) add(key string, dp AnnotatedDatapoint) {
+	a.Lock()
+	defer a.Unlock()
+
+	series, ok := a.series[key]
+	if !ok {
+		series = &annotatedSeries{seen: make(map[int64]struct{})}
+		a.series[key] = series
+	}
+
+	// NB: The same datapoints may be read more than once if an iterator is
+	// reset, only record each annotated datapoint once.
+	nanos := dp.Timestamp.UnixNano()
+	if _, ok := series.seen[nanos]; ok {
+		return
+	}
+
+	series.seen[nanos] = struct{}{}
+	dp.Annotation = append([]byte(nil), dp.Annotation...)
+	series.datapoints = append(series.datapoints, dp)
+}
+
+// Series returns the annotated datapoints of the series with the given tags
+// in time order, the tags are matched regardless of their order.
+func (a *Annotations) Series(tags models.Tags) []AnnotatedDatapoint {
+	a.Lock()
+	defer a.Unlock()
+
+	series, ok := a.series[annotationsKey(tags)]
+	if !ok {
+		return nil
+	}
+
+	sort.Slice(series.datapoints, func(i, j int) bool {
+		return series.datapoints[i].Timestamp.Before(series.datapoints[j].Timestamp)
+	})
+
+	return series.datapoints
+}
+
+// annotationsKey returns a key for the tags which is independent of the
+// order of the tags.
+func annotationsKey(tags models.Tags) string {
+	sorted := make([]models.Tag, len(tags.Tags))
+	copy(sorted, tags.Tags)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Name, sorted[j].Name) < 0
+	})
+
+	var buf bytes.Buffer
+	for _, tag := range sorted {
+		buf.Write(tag.Name)
+		buf.WriteByte(0)
+		buf.Write(tag.Value)
+		buf.WriteByte(0)
+	}
+
+	return buf.String()
+}
+
+// NewAnnotatedSeriesIterators returns series iterators which add the
+// annotated datapoints read from each of the iterators to the annotations.
+func NewAnnotatedSeriesIterators(
+	iters encoding.SeriesIterators,
+	annotations *Annotations,
+	tagOptions models.TagOptions,
+) encoding.SeriesIterators {
+	annotated := make([]encoding.SeriesIterator, 0, iters.Len())
+	for _, iter := range iters.Iters() {
+		annotated = append(annotated,
+			NewAnnotatedSeriesIterator(iter, annotations, tagOptions))
+	}
+
+	return &annotatedSeriesIterators{
+		SeriesIterators: iters,
+		iters:           annotated,
+	}
+}
+
+// annotatedSeriesIterators wraps the iterators of a set of series iterators,
+// closing the set closes the underlying iterators.
+type annotatedSeriesIterators struct {
+	encoding.SeriesIterators
+
+	iters []encoding.SeriesIterator
+}
+
+func (it *annotatedSeriesIterators) Iters() []encoding.SeriesIterator {
+	return it.iters
+}
+
+// NewAnnotatedSeriesIterator returns a series iterator which adds the
+// annotated datapoints read from the iterator to the annotations.
+func NewAnnotatedSeriesIterator(
+	iter encoding.SeriesIterator,
+	annotations *Annotations,
+	tagOptions models.TagOptions,
+) encoding.SeriesIterator {
+	return &annotatedSeriesIterator{
+		SeriesIterator: iter,
+		annotations:    annotations,
+		tagOptions:     tagOptions,
+	}
+}
+
+type annotatedSeriesIterator struct {
+	encoding.SeriesIterator
+
+	annotations *Annotations
+	tagOptions  models.TagOptions
+	key         string
+	hasKey      bool
+	err         error
+}
+
+func (it *annotatedSeriesIterator) Next() bool {
+	if it.err != nil || !it.SeriesIterator.Next() {
+		return false
+	}
+
+	dp, _, annotation := it.SeriesIterator.Current()
+	if len(annotation) == 0 {
+		return true
+	}
+
+	if !it.hasKey {
+		// NB: Duplicate the tags so that the tags of the iterator are not
+		// consumed by resolving the key.
+		tags, err := FromIdentTagIteratorToTags(
+			it.SeriesIterator.Tags().Duplicate(), it.tagOptions)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.key = annotationsKey(tags)
+		it.hasKey = true
+	}
+
+	it.annotations.add(it.key, AnnotatedDatapoint{
+		Timestamp:  dp.Timestamp,
+		Value:      dp.Value,
+		Annotation: annotation,
+	})
+
+	return true
+}
+
+func (it *annotatedSeriesIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+
+	return it.SeriesIterator.Err()
+}
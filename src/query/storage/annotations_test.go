@@ -0,0 +1,115 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/encoding"
+	m3ts "github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotatedSeriesIterator(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		now        = time.Now()
+		annotation = []byte("trace-id")
+		tagOpts    = models.NewTagOptions()
+		tags       = ident.NewTagsIterator(ident.NewTags(
+			ident.StringTag("foo", "bar"),
+			ident.StringTag("baz", "qux"),
+		))
+		iter = encoding.NewMockSeriesIterator(ctrl)
+	)
+
+	iter.EXPECT().Tags().Return(tags).AnyTimes()
+	gomock.InOrder(
+		iter.EXPECT().Next().Return(true),
+		iter.EXPECT().Current().Return(m3ts.Datapoint{Timestamp: now, Value: 1},
+			xtime.Second, m3ts.Annotation(annotation)),
+		iter.EXPECT().Next().Return(true),
+		iter.EXPECT().Current().Return(m3ts.Datapoint{Timestamp: now.Add(time.Second), Value: 2},
+			xtime.Second, nil),
+		iter.EXPECT().Next().Return(false),
+	)
+
+	annotations := NewAnnotations()
+	annotated := NewAnnotatedSeriesIterator(iter, annotations, tagOpts)
+	for annotated.Next() {
+	}
+
+	// The iterator may reuse the annotation once it moves on.
+	annotation[0] = 'x'
+
+	// The tags of the iterator are not consumed and are matched regardless
+	// of their order.
+	assert.Equal(t, 2, tags.Remaining())
+	seriesTags := models.NewTags(2, tagOpts).AddTags([]models.Tag{
+		{Name: []byte("baz"), Value: []byte("qux")},
+		{Name: []byte("foo"), Value: []byte("bar")},
+	})
+	require.Equal(t, []AnnotatedDatapoint{
+		{Timestamp: now, Value: 1, Annotation: []byte("trace-id")},
+	}, annotations.Series(seriesTags))
+
+	otherTags := models.NewTags(1, tagOpts).AddTag(models.Tag{
+		Name:  []byte("foo"),
+		Value: []byte("bar"),
+	})
+	assert.Nil(t, annotations.Series(otherTags))
+}
+
+func TestAnnotationsAddDedupes(t *testing.T) {
+	var (
+		now     = time.Now()
+		tagOpts = models.NewTagOptions()
+		tags    = models.NewTags(1, tagOpts).AddTag(models.Tag{
+			Name:  []byte("foo"),
+			Value: []byte("bar"),
+		})
+		annotations = NewAnnotations()
+	)
+
+	annotations.Add(tags, AnnotatedDatapoint{
+		Timestamp: now.Add(time.Second), Value: 2, Annotation: []byte("b"),
+	})
+	annotations.Add(tags, AnnotatedDatapoint{
+		Timestamp: now, Value: 1, Annotation: []byte("a"),
+	})
+	annotations.Add(tags, AnnotatedDatapoint{
+		Timestamp: now.Add(time.Second), Value: 2, Annotation: []byte("b"),
+	})
+
+	assert.Equal(t, []AnnotatedDatapoint{
+		{Timestamp: now, Value: 1, Annotation: []byte("a")},
+		{Timestamp: now.Add(time.Second), Value: 2, Annotation: []byte("b")},
+	}, annotations.Series(tags))
+}
@@ -38,7 +38,8 @@ func FetchResultToBlockResult(result *FetchResult, query *FetchQuery, lookbackDu
 	}
 
 	return block.Result{
-		Blocks: []block.Block{NewMultiBlockWrapper(multiBlock)},
+		Blocks:   []block.Block{NewMultiBlockWrapper(multiBlock)},
+		Metadata: result.Metadata,
 	}, nil
 }
 
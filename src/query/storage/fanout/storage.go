@@ -90,6 +90,8 @@ func (s *fanoutStorage) FetchBlocks(
 		}
 
 		blockResult.Blocks = append(blockResult.Blocks, result.Blocks...)
+		blockResult.Metadata.Resolutions = append(blockResult.Metadata.Resolutions,
+			result.Metadata.Resolutions...)
 	}
 
 	return blockResult, nil
@@ -113,6 +115,8 @@ func handleFetchResponses(requests []execution.Request) (*storage.FetchResult, e
 		}
 
 		result.SeriesList = append(result.SeriesList, fetchreq.result.SeriesList...)
+		result.Metadata.Resolutions = append(result.Metadata.Resolutions,
+			fetchreq.result.Metadata.Resolutions...)
 	}
 
 	return result, nil
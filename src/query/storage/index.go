@@ -87,6 +87,7 @@ func FetchOptionsToM3Options(fetchOptions *FetchOptions, fetchQuery *FetchQuery)
 		Limit:          fetchOptions.Limit,
 		StartInclusive: fetchQuery.Start,
 		EndExclusive:   fetchQuery.End,
+		Downsample:     fetchOptions.Downsample,
 	}
 }
 
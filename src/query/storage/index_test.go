@@ -24,6 +24,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/encoding/downsample"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3x/ident"
 
@@ -149,3 +150,27 @@ func TestFetchQueryToM3Query(t *testing.T) {
 	}
 
 }
+
+func TestFetchOptionsToM3Options(t *testing.T) {
+	fetchQuery := &FetchQuery{
+		Raw:      "up",
+		Start:    now.Add(-5 * time.Minute),
+		End:      now,
+		Interval: 15 * time.Second,
+	}
+
+	fetchOptions := NewFetchOptions()
+	fetchOptions.Limit = 10
+	m3Options := FetchOptionsToM3Options(fetchOptions, fetchQuery)
+	assert.Equal(t, 10, m3Options.Limit)
+	assert.Equal(t, fetchQuery.Start, m3Options.StartInclusive)
+	assert.Equal(t, fetchQuery.End, m3Options.EndExclusive)
+	assert.False(t, m3Options.Downsample.Enabled())
+
+	fetchOptions.Downsample = downsample.Options{
+		Resolution:  time.Minute,
+		Aggregation: downsample.Max,
+	}
+	m3Options = FetchOptionsToM3Options(fetchOptions, fetchQuery)
+	assert.Equal(t, fetchOptions.Downsample, m3Options.Downsample)
+}
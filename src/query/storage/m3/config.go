@@ -310,3 +310,24 @@ func (c ClustersStaticConfiguration) NewClusters(
 	return NewClusters(unaggregatedClusterNamespace,
 		aggregatedClusterNamespaces...)
 }
+
+// StitchingConfiguration is the configuration for stitching results from
+// the unaggregated and aggregated namespaces together by resolution.
+type StitchingConfiguration struct {
+	// Enabled enables stitching for queries that span more than the
+	// retention of the unaggregated namespace.
+	Enabled bool `yaml:"enabled"`
+
+	// Precedence is the list of namespaces in the order they should be
+	// preferred when serving a time range, namespaces not listed are
+	// preferred in order of ascending resolution after those listed.
+	Precedence []string `yaml:"precedence"`
+}
+
+// NewStitchingOptions returns the stitching options for the configuration.
+func (c StitchingConfiguration) NewStitchingOptions() StitchingOptions {
+	return StitchingOptions{
+		Enabled:    c.Enabled,
+		Precedence: c.Precedence,
+	}
+}
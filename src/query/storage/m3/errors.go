@@ -0,0 +1,67 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package m3
+
+import (
+	"github.com/m3db/m3/src/dbnode/client"
+	xerrors "github.com/m3db/m3x/errors"
+)
+
+// resourceExhaustedError is returned when a query exceeds the resource limits
+// of a storage node, retrying the query unchanged will fail again.
+type resourceExhaustedError struct {
+	err error
+}
+
+// NewResourceExhaustedError wraps an error to mark that the query exceeded
+// the resource limits of a storage node.
+func NewResourceExhaustedError(err error) error {
+	return resourceExhaustedError{err: err}
+}
+
+func (e resourceExhaustedError) Error() string {
+	return e.err.Error()
+}
+
+func (e resourceExhaustedError) InnerError() error {
+	return e.err
+}
+
+// IsResourceExhaustedError returns whether an error returned by the storage
+// is caused by the query exceeding the resource limits of a storage node.
+func IsResourceExhaustedError(err error) bool {
+	for err != nil {
+		if _, ok := err.(resourceExhaustedError); ok {
+			return true
+		}
+		err = xerrors.InnerError(err)
+	}
+	return false
+}
+
+// convertSessionError converts an error returned by a session into an error
+// that callers of the storage can check without depending on the client.
+func convertSessionError(err error) error {
+	if err != nil && client.IsResourceExhaustedError(err) {
+		return NewResourceExhaustedError(err)
+	}
+	return err
+}
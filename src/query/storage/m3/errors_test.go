@@ -0,0 +1,49 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package m3
+
+import (
+	"errors"
+	"testing"
+
+	tterrors "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/errors"
+	xerrors "github.com/m3db/m3x/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertSessionError(t *testing.T) {
+	assert.NoError(t, convertSessionError(nil))
+
+	err := errors.New("fetch failed")
+	assert.Equal(t, err, convertSessionError(err))
+	assert.False(t, IsResourceExhaustedError(convertSessionError(err)))
+
+	exhausted := tterrors.NewResourceExhaustedError(
+		errors.New("query aborted due to limit"))
+	converted := convertSessionError(exhausted)
+	assert.True(t, IsResourceExhaustedError(converted))
+	assert.Equal(t, exhausted.Error(), converted.Error())
+
+	// Still detected once accumulated with errors from other namespaces.
+	multiErr := xerrors.NewMultiError().Add(err).Add(converted)
+	assert.True(t, IsResourceExhaustedError(multiErr.LastError()))
+}
@@ -0,0 +1,373 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package m3
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/storage"
+	xerrors "github.com/m3db/m3x/errors"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+)
+
+// StitchingOptions describes how results from the unaggregated and aggregated
+// namespaces are stitched together by resolution.
+type StitchingOptions struct {
+	// Enabled enables stitching, when disabled queries are fanned out to the
+	// namespaces that best cover the whole query range.
+	Enabled bool
+	// Precedence is the list of namespace IDs in the order they should be
+	// preferred when serving a time range, namespaces not listed are
+	// preferred in order of ascending resolution after those listed.
+	Precedence []string
+}
+
+// stitchSegment is a time range of a query served by a single namespace.
+type stitchSegment struct {
+	namespace ClusterNamespace
+	start     time.Time
+	end       time.Time
+}
+
+// resolveStitchSegments splits the query range into segments each served by
+// a single namespace, using the most preferred namespace for the most recent
+// range it covers and progressively less preferred namespaces for the older
+// ranges they cover. Only the unaggregated namespace and aggregated namespaces
+// that contain a complete set of metrics are considered, since any other
+// namespace could leave gaps in a series.
+//
+// Returns no segments if the query range is served by a single namespace, in
+// which case the query should be resolved by regular fanout.
+func resolveStitchSegments(
+	now time.Time,
+	clusters Clusters,
+	start time.Time,
+	end time.Time,
+	precedence []string,
+	opts *storage.FanoutOptions,
+) []stitchSegment {
+	if opts.FanoutUnaggregated == storage.FanoutForceDisable ||
+		opts.FanoutAggregated == storage.FanoutForceDisable ||
+		opts.FanoutAggregatedOptimized == storage.FanoutForceDisable {
+		// Stitching relies on selecting namespaces by their retention and
+		// completeness, so fall back to fanout if explicitly restricted.
+		return nil
+	}
+
+	var r reusedAggregatedNamespaceSlices
+	r = aggregatedNamespaces(clusters.ClusterNamespaces(), r, nil, opts)
+	candidates := make(ClusterNamespaces, 0, 1+len(r.completeAggregated))
+	candidates = append(candidates, clusters.UnaggregatedClusterNamespace())
+	candidates = append(candidates, r.completeAggregated...)
+
+	ranks := make(map[string]int, len(precedence))
+	for i, namespace := range precedence {
+		ranks[namespace] = i
+	}
+
+	rank := func(namespace ClusterNamespace) int {
+		if idx, ok := ranks[namespace.NamespaceID().String()]; ok {
+			return idx
+		}
+		return len(ranks)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ri, rj := rank(candidates[i]), rank(candidates[j])
+		if ri != rj {
+			return ri < rj
+		}
+
+		attrsI := candidates[i].Options().Attributes()
+		attrsJ := candidates[j].Options().Attributes()
+		if attrsI.Resolution != attrsJ.Resolution {
+			return attrsI.Resolution < attrsJ.Resolution
+		}
+		return attrsI.Retention > attrsJ.Retention
+	})
+
+	// Since every namespace covers the range from the start of its retention
+	// until now, walk back from the end of the query letting each namespace
+	// serve whatever is left of the range that it covers.
+	var (
+		segments []stitchSegment
+		cursor   = end
+	)
+	for _, namespace := range candidates {
+		if !cursor.After(start) {
+			break
+		}
+
+		namespaceStart := now.Add(-1 * namespace.Options().Attributes().Retention)
+		if !namespaceStart.Before(cursor) {
+			// Does not cover any of the remaining range.
+			continue
+		}
+
+		segmentStart := namespaceStart
+		if segmentStart.Before(start) {
+			segmentStart = start
+		}
+
+		segments = append(segments, stitchSegment{
+			namespace: namespace,
+			start:     segmentStart,
+			end:       cursor,
+		})
+		cursor = segmentStart
+	}
+
+	if len(segments) < 2 {
+		return nil
+	}
+
+	// Return segments in ascending time order.
+	for i, j := 0, len(segments)-1; i < j; i, j = i+1, j-1 {
+		segments[i], segments[j] = segments[j], segments[i]
+	}
+
+	return segments
+}
+
+func stitchSegmentsMetadata(segments []stitchSegment) block.ResultMetadata {
+	resolutions := make([]block.ResolutionSegment, 0, len(segments))
+	for _, segment := range segments {
+		resolutions = append(resolutions, block.ResolutionSegment{
+			Start:      segment.start,
+			End:        segment.end,
+			Namespace:  segment.namespace.NamespaceID().String(),
+			Resolution: segment.namespace.Options().Attributes().Resolution,
+		})
+	}
+
+	return block.ResultMetadata{Resolutions: resolutions}
+}
+
+// stitchedResult accumulates the series iterators fetched for each segment
+// of a stitched query and joins them into a single series iterator per ID.
+type stitchedResult struct {
+	sync.Mutex
+	segments    []stitchSegment
+	segmentIter []encoding.SeriesIterators
+	finalResult encoding.MutableSeriesIterators
+	finalAttrs  []storage.Attributes
+	err         xerrors.MultiError
+}
+
+func newStitchedResult(segments []stitchSegment) *stitchedResult {
+	return &stitchedResult{
+		segments:    segments,
+		segmentIter: make([]encoding.SeriesIterators, len(segments)),
+	}
+}
+
+func (r *stitchedResult) Add(
+	segment int,
+	iters encoding.SeriesIterators,
+	err error,
+) {
+	r.Lock()
+	defer r.Unlock()
+
+	if err != nil {
+		r.err = r.err.Add(err)
+		return
+	}
+
+	r.segmentIter[segment] = iters
+}
+
+func (r *stitchedResult) Metadata() block.ResultMetadata {
+	return stitchSegmentsMetadata(r.segments)
+}
+
+func (r *stitchedResult) FinalResult() (encoding.SeriesIterators, error) {
+	iters, _, err := r.FinalResultWithAttrs()
+	return iters, err
+}
+
+func (r *stitchedResult) FinalResultWithAttrs() (
+	encoding.SeriesIterators, []storage.Attributes, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if err := r.err.LastError(); err != nil {
+		return nil, nil, err
+	}
+	if r.finalResult != nil {
+		return r.finalResult, r.finalAttrs, nil
+	}
+
+	var (
+		ids       []string
+		byID      = make(map[string]*stitchedSeriesIterator)
+		attrsByID = make(map[string]storage.Attributes)
+	)
+	for i, segmentIters := range r.segmentIter {
+		if segmentIters == nil {
+			continue
+		}
+
+		attrs := r.segments[i].namespace.Options().Attributes()
+		for _, iter := range segmentIters.Iters() {
+			id := iter.ID().String()
+			stitched, ok := byID[id]
+			if !ok {
+				stitched = &stitchedSeriesIterator{}
+				byID[id] = stitched
+				ids = append(ids, id)
+				attrsByID[id] = attrs
+			}
+
+			stitched.iters = append(stitched.iters, iter)
+			// NB: report the coarsest resolution that served the series so
+			// consumers that consolidate by resolution do not overestimate
+			// the density of the stitched series.
+			if existing := attrsByID[id]; attrs.Resolution > existing.Resolution {
+				attrsByID[id] = attrs
+			}
+		}
+	}
+
+	stitchedIters := make([]encoding.SeriesIterator, 0, len(ids))
+	finalAttrs := make([]storage.Attributes, 0, len(ids))
+	for _, id := range ids {
+		stitchedIters = append(stitchedIters, byID[id])
+		finalAttrs = append(finalAttrs, attrsByID[id])
+	}
+
+	r.finalResult = encoding.NewSeriesIterators(stitchedIters, nil)
+	r.finalAttrs = finalAttrs
+	return r.finalResult, r.finalAttrs, nil
+}
+
+func (r *stitchedResult) Close() error {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, iters := range r.segmentIter {
+		if iters != nil {
+			iters.Close()
+		}
+	}
+	r.segmentIter = nil
+
+	if r.finalResult != nil {
+		// NB: the stitched iterators do not own the segment iterators, which
+		// were closed above, so closing them is a no-op.
+		r.finalResult.Close()
+		r.finalResult = nil
+	}
+
+	r.finalAttrs = nil
+	r.err = xerrors.NewMultiError()
+	return nil
+}
+
+// stitchedSeriesIterator iterates the series iterators of consecutive
+// segments of a stitched query in order as a single continuous series.
+//
+// NB: the iterator does not own the segment iterators and does not close them.
+type stitchedSeriesIterator struct {
+	iters []encoding.SeriesIterator
+	idx   int
+	err   error
+}
+
+func (it *stitchedSeriesIterator) Next() bool {
+	for it.err == nil && it.idx < len(it.iters) {
+		iter := it.iters[it.idx]
+		if iter.Next() {
+			return true
+		}
+
+		if err := iter.Err(); err != nil {
+			it.err = err
+			return false
+		}
+
+		it.idx++
+	}
+
+	return false
+}
+
+func (it *stitchedSeriesIterator) Current() (ts.Datapoint, xtime.Unit, ts.Annotation) {
+	return it.iters[it.idx].Current()
+}
+
+func (it *stitchedSeriesIterator) Err() error {
+	return it.err
+}
+
+func (it *stitchedSeriesIterator) Close() {}
+
+func (it *stitchedSeriesIterator) ID() ident.ID {
+	return it.iters[0].ID()
+}
+
+func (it *stitchedSeriesIterator) Namespace() ident.ID {
+	// Report the namespace serving the most recent data.
+	return it.iters[len(it.iters)-1].Namespace()
+}
+
+func (it *stitchedSeriesIterator) Tags() ident.TagIterator {
+	return it.iters[0].Tags()
+}
+
+func (it *stitchedSeriesIterator) Start() time.Time {
+	return it.iters[0].Start()
+}
+
+func (it *stitchedSeriesIterator) End() time.Time {
+	return it.iters[len(it.iters)-1].End()
+}
+
+// Reset is a no-op, stitched iterators are built from the segment iterators
+// of a stitched result and are never pooled.
+func (it *stitchedSeriesIterator) Reset(opts encoding.SeriesIteratorOptions) {}
+
+func (it *stitchedSeriesIterator) SetIterateEqualTimestampStrategy(
+	strategy encoding.IterateEqualTimestampStrategy,
+) {
+	for _, iter := range it.iters {
+		iter.SetIterateEqualTimestampStrategy(strategy)
+	}
+}
+
+// Replicas returns nil since the replicas of each segment cover data outside
+// of the segment, stitched results must be consumed by iterating the series.
+func (it *stitchedSeriesIterator) Replicas() []encoding.MultiReaderIterator {
+	return nil
+}
+
+// CodecID returns the codec of the first segment, since no replicas are
+// exposed it does not describe how to decode the stitched series.
+func (it *stitchedSeriesIterator) CodecID() codec.ID {
+	return it.iters[0].CodecID()
+}
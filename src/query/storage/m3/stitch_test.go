@@ -0,0 +1,319 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package m3
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/storage/index"
+	m3ts "github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/test/seriesiter"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSegment struct {
+	namespace string
+	start     time.Time
+	end       time.Time
+}
+
+func toTestSegments(segments []stitchSegment) []testSegment {
+	result := make([]testSegment, 0, len(segments))
+	for _, segment := range segments {
+		result = append(result, testSegment{
+			namespace: segment.namespace.NamespaceID().String(),
+			start:     segment.start,
+			end:       segment.end,
+		})
+	}
+
+	return result
+}
+
+func setupStitching(
+	t *testing.T,
+	ctrl *gomock.Controller,
+	now time.Time,
+	precedence ...string,
+) (*m3storage, testSessions) {
+	s, sessions := setup(t, ctrl)
+	store, ok := s.(*m3storage)
+	require.True(t, ok)
+	store.stitching = StitchingOptions{Enabled: true, Precedence: precedence}
+	store.nowFn = func() time.Time { return now }
+	return store, sessions
+}
+
+func TestResolveStitchSegmentsByResolution(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now().Truncate(time.Hour)
+	store, _ := setupStitching(t, ctrl, now)
+	start := now.Add(-2 * test6MonthRetention)
+	segments := resolveStitchSegments(now, store.clusters, start, now, nil,
+		storage.NewFetchOptions().FanoutOptions)
+
+	// NB: the partial aggregated namespace is never stitched in since it may
+	// not contain every series.
+	assert.Equal(t, []testSegment{
+		{
+			namespace: "metrics_aggregated_10m:365d",
+			start:     now.Add(-1 * test1YearRetention),
+			end:       now.Add(-1 * test3MonthRetention),
+		},
+		{
+			namespace: "metrics_aggregated_5m:90d",
+			start:     now.Add(-1 * test3MonthRetention),
+			end:       now.Add(-1 * test1MonthRetention),
+		},
+		{
+			namespace: "metrics_unaggregated",
+			start:     now.Add(-1 * test1MonthRetention),
+			end:       now,
+		},
+	}, toTestSegments(segments))
+}
+
+func TestResolveStitchSegmentsClampsToQueryRange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now().Truncate(time.Hour)
+	store, _ := setupStitching(t, ctrl, now)
+	start := now.Add(-2 * test1MonthRetention)
+	end := now.Add(-1 * time.Hour)
+	segments := resolveStitchSegments(now, store.clusters, start, end, nil,
+		storage.NewFetchOptions().FanoutOptions)
+
+	assert.Equal(t, []testSegment{
+		{
+			namespace: "metrics_aggregated_5m:90d",
+			start:     start,
+			end:       now.Add(-1 * test1MonthRetention),
+		},
+		{
+			namespace: "metrics_unaggregated",
+			start:     now.Add(-1 * test1MonthRetention),
+			end:       end,
+		},
+	}, toTestSegments(segments))
+}
+
+func TestResolveStitchSegmentsWithPrecedence(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now().Truncate(time.Hour)
+	store, _ := setupStitching(t, ctrl, now)
+	start := now.Add(-2 * test1MonthRetention)
+	segments := resolveStitchSegments(now, store.clusters, start, now,
+		[]string{"metrics_aggregated_1m:30d"},
+		storage.NewFetchOptions().FanoutOptions)
+
+	assert.Equal(t, []testSegment{
+		{
+			namespace: "metrics_aggregated_5m:90d",
+			start:     start,
+			end:       now.Add(-1 * test1MonthRetention),
+		},
+		{
+			namespace: "metrics_aggregated_1m:30d",
+			start:     now.Add(-1 * test1MonthRetention),
+			end:       now,
+		},
+	}, toTestSegments(segments))
+
+	// A namespace preferred for the whole range leaves nothing to stitch.
+	segments = resolveStitchSegments(now, store.clusters, start, now,
+		[]string{"metrics_aggregated_10m:365d"},
+		storage.NewFetchOptions().FanoutOptions)
+	assert.Nil(t, segments)
+}
+
+func TestResolveStitchSegmentsSingleNamespace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now().Truncate(time.Hour)
+	store, _ := setupStitching(t, ctrl, now)
+	segments := resolveStitchSegments(now, store.clusters,
+		now.Add(-1*time.Hour), now, nil, storage.NewFetchOptions().FanoutOptions)
+	assert.Nil(t, segments)
+}
+
+func TestResolveStitchSegmentsFanoutRestricted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now().Truncate(time.Hour)
+	store, _ := setupStitching(t, ctrl, now)
+	opts := &storage.FanoutOptions{
+		FanoutAggregatedOptimized: storage.FanoutForceDisable,
+	}
+
+	segments := resolveStitchSegments(now, store.clusters,
+		now.Add(-2*test1MonthRetention), now, nil, opts)
+	assert.Nil(t, segments)
+}
+
+func newTestStitchIter(
+	ctrl *gomock.Controller,
+	values ...float64,
+) encoding.SeriesIterator {
+	iter := encoding.NewMockSeriesIterator(ctrl)
+	now := time.Now()
+	for i, v := range values {
+		iter.EXPECT().Next().Return(true)
+		iter.EXPECT().Current().Return(m3ts.Datapoint{
+			Timestamp: now.Add(time.Duration(i) * time.Second),
+			Value:     v,
+		}, xtime.Second, nil)
+	}
+
+	iter.EXPECT().Next().Return(false)
+	iter.EXPECT().Err().Return(nil)
+	return iter
+}
+
+func newTestStitchIters(
+	ctrl *gomock.Controller,
+	tag ident.Tag,
+	values ...float64,
+) encoding.SeriesIterators {
+	iter := newTestStitchIter(ctrl, values...).(*encoding.MockSeriesIterator)
+	tags := seriesiter.GenerateSingleSampleTagIterator(ctrl, tag)
+	iter.EXPECT().ID().Return(ident.StringID("bar")).AnyTimes()
+	iter.EXPECT().Namespace().Return(ident.StringID("foo")).AnyTimes()
+	iter.EXPECT().Tags().Return(tags).AnyTimes()
+	iter.EXPECT().Close().AnyTimes()
+
+	iters := encoding.NewMockSeriesIterators(ctrl)
+	iters.EXPECT().Iters().Return([]encoding.SeriesIterator{iter}).AnyTimes()
+	iters.EXPECT().Len().Return(1).AnyTimes()
+	iters.EXPECT().Close().AnyTimes()
+	return iters
+}
+
+func TestStitchedSeriesIterator(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	iter := &stitchedSeriesIterator{
+		iters: []encoding.SeriesIterator{
+			newTestStitchIter(ctrl, 1, 2),
+			newTestStitchIter(ctrl),
+			newTestStitchIter(ctrl, 3),
+		},
+	}
+
+	var values []float64
+	for iter.Next() {
+		dp, _, _ := iter.Current()
+		values = append(values, dp.Value)
+	}
+
+	require.NoError(t, iter.Err())
+	assert.Equal(t, []float64{1, 2, 3}, values)
+}
+
+func TestStitchedSeriesIteratorError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	failing := encoding.NewMockSeriesIterator(ctrl)
+	failing.EXPECT().Next().Return(false)
+	failing.EXPECT().Err().Return(errors.New("bad iter"))
+
+	iter := &stitchedSeriesIterator{
+		iters: []encoding.SeriesIterator{failing, newTestStitchIter(ctrl)},
+	}
+
+	assert.False(t, iter.Next())
+	assert.EqualError(t, iter.Err(), "bad iter")
+}
+
+func TestLocalReadStitched(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now().Truncate(time.Hour)
+	store, sessions := setupStitching(t, ctrl, now)
+	testTag := seriesiter.GenerateTag()
+
+	var (
+		mu     sync.Mutex
+		ranges = make(map[string][2]time.Time)
+	)
+	for _, session := range []*client.MockSession{
+		sessions.unaggregated1MonthRetention,
+		sessions.aggregated3MonthRetention5MinuteResolution,
+	} {
+		session.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(
+				ns ident.ID,
+				_ index.Query,
+				opts index.QueryOptions,
+			) (encoding.SeriesIterators, bool, error) {
+				mu.Lock()
+				ranges[ns.String()] = [2]time.Time{opts.StartInclusive, opts.EndExclusive}
+				mu.Unlock()
+				return newTestStitchIters(ctrl, testTag, 1, 2), true, nil
+			})
+	}
+
+	searchReq := newFetchReq()
+	searchReq.Start = now.Add(-2 * test1MonthRetention)
+	searchReq.End = now
+	results, err := store.Fetch(context.TODO(), searchReq, buildFetchOpts())
+	require.NoError(t, err)
+	assertFetchResult(t, results, testTag)
+
+	// Values of both segments are stitched into the one series.
+	assert.Equal(t, 4, results.SeriesList[0].Len())
+	assert.Equal(t, 5*time.Minute, results.SeriesList[0].Resolution())
+
+	boundary := now.Add(-1 * test1MonthRetention)
+	assert.Equal(t, map[string][2]time.Time{
+		"metrics_aggregated_5m:90d": {searchReq.Start, boundary},
+		"metrics_unaggregated":      {boundary, now},
+	}, ranges)
+
+	require.True(t, results.Metadata.Stitched())
+	resolutions := results.Metadata.Resolutions
+	require.Len(t, resolutions, 2)
+	assert.Equal(t, "metrics_aggregated_5m:90d", resolutions[0].Namespace)
+	assert.Equal(t, 5*time.Minute, resolutions[0].Resolution)
+	assert.Equal(t, "metrics_unaggregated", resolutions[1].Namespace)
+	assert.Equal(t, time.Duration(0), resolutions[1].Resolution)
+}
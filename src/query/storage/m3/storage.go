@@ -21,7 +21,6 @@
 package m3
 
 import (
-	"bytes"
 	"context"
 	goerrors "errors"
 	"fmt"
@@ -29,6 +28,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/errors"
 	"github.com/m3db/m3/src/query/models"
@@ -61,9 +61,17 @@ type m3storage struct {
 	readWorkerPool  xsync.PooledWorkerPool
 	writeWorkerPool xsync.PooledWorkerPool
 	opts            m3db.Options
+	stitching       StitchingOptions
 	nowFn           func() time.Time
 }
 
+// fetchAccumulator is the accumulated result of a fetch across namespaces.
+type fetchAccumulator interface {
+	FinalResult() (encoding.SeriesIterators, error)
+	FinalResultWithAttrs() (encoding.SeriesIterators, []storage.Attributes, error)
+	Close() error
+}
+
 // NewStorage creates a new local m3storage instance.
 // TODO: consider taking in an iterator pools here.
 func NewStorage(
@@ -72,6 +80,7 @@ func NewStorage(
 	writeWorkerPool xsync.PooledWorkerPool,
 	tagOptions models.TagOptions,
 	lookbackDuration time.Duration,
+	stitching StitchingOptions,
 ) Storage {
 	opts := m3db.NewOptions().
 		SetTagOptions(tagOptions).
@@ -83,6 +92,7 @@ func NewStorage(
 		readWorkerPool:  readWorkerPool,
 		writeWorkerPool: writeWorkerPool,
 		opts:            opts,
+		stitching:       stitching,
 		nowFn:           time.Now,
 	}
 }
@@ -92,7 +102,7 @@ func (s *m3storage) Fetch(
 	query *storage.FetchQuery,
 	options *storage.FetchOptions,
 ) (*storage.FetchResult, error) {
-	accumulator, err := s.fetchCompressed(ctx, query, options)
+	accumulator, metadata, err := s.fetchStitchedOrCompressed(ctx, query, options)
 	if err != nil {
 		return nil, err
 	}
@@ -103,6 +113,11 @@ func (s *m3storage) Fetch(
 		return nil, err
 	}
 
+	if options.Annotations != nil {
+		iters = storage.NewAnnotatedSeriesIterators(iters, options.Annotations,
+			s.opts.TagOptions())
+	}
+
 	fetchResult, err := storage.SeriesIteratorsToFetchResult(
 		iters,
 		s.readWorkerPool,
@@ -122,6 +137,7 @@ func (s *m3storage) Fetch(
 		fetchResult.SeriesList[i].SetResolution(attrs[i].Resolution)
 	}
 
+	fetchResult.Metadata = metadata
 	return fetchResult, nil
 }
 
@@ -141,13 +157,30 @@ func (s *m3storage) FetchBlocks(
 		return storage.FetchResultToBlockResult(fetchResult, query, s.opts.LookbackDuration())
 	}
 
+	var (
+		raw      encoding.SeriesIterators
+		metadata block.ResultMetadata
+		err      error
+	)
+	if options.Annotations != nil {
+		opts = opts.SetAnnotations(options.Annotations)
+	}
+
 	// If using multiblock, update options to reflect this.
 	if options.BlockType == models.TypeMultiBlock {
 		opts = opts.
 			SetSplitSeriesByBlock(true)
+		// NB: splitting series by block requires the replicas of each series,
+		// which stitched series do not expose, so do not stitch these results.
+		raw, _, err = s.FetchCompressed(ctx, query, options)
+	} else {
+		var accumulator fetchAccumulator
+		accumulator, metadata, err = s.fetchStitchedOrCompressed(ctx, query, options)
+		if err == nil {
+			raw, err = accumulator.FinalResult()
+		}
 	}
 
-	raw, _, err := s.FetchCompressed(ctx, query, options)
 	if err != nil {
 		return block.Result{}, err
 	}
@@ -164,7 +197,8 @@ func (s *m3storage) FetchBlocks(
 	}
 
 	return block.Result{
-		Blocks: blocks,
+		Blocks:   blocks,
+		Metadata: metadata,
 	}, nil
 }
 
@@ -187,6 +221,96 @@ func (s *m3storage) FetchCompressed(
 	return iters, accumulator.Close, nil
 }
 
+// fetchStitchedOrCompressed fetches compressed series, stitching together
+// results from several namespaces by resolution if stitching is enabled and
+// the query range is not served by a single namespace.
+func (s *m3storage) fetchStitchedOrCompressed(
+	ctx context.Context,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+) (fetchAccumulator, block.ResultMetadata, error) {
+	if s.stitching.Enabled {
+		segments := resolveStitchSegments(
+			s.nowFn(),
+			s.clusters,
+			query.Start,
+			query.End,
+			s.stitching.Precedence,
+			options.FanoutOptions,
+		)
+
+		if len(segments) > 0 {
+			result, err := s.fetchStitched(ctx, query, options, segments)
+			if err != nil {
+				return nil, block.ResultMetadata{}, err
+			}
+
+			return result, result.Metadata(), nil
+		}
+	}
+
+	accumulator, err := s.fetchCompressed(ctx, query, options)
+	if err != nil {
+		return nil, block.ResultMetadata{}, err
+	}
+
+	return accumulator, block.ResultMetadata{}, nil
+}
+
+// fetches compressed series for each segment of a stitched query from the
+// namespace serving that segment.
+func (s *m3storage) fetchStitched(
+	ctx context.Context,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+	segments []stitchSegment,
+) (*stitchedResult, error) {
+	// Check if the query was interrupted.
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	m3query, err := storage.FetchQueryToM3Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		result = newStitchedResult(segments)
+		wg     sync.WaitGroup
+	)
+	for i, segment := range segments {
+		i, segment := i, segment // Capture vars
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			segmentQuery := *query
+			segmentQuery.Start = segment.start
+			segmentQuery.End = segment.end
+			opts := storage.FetchOptionsToM3Options(options, &segmentQuery)
+			session := segment.namespace.Session()
+			ns := segment.namespace.NamespaceID()
+			iters, _, err := session.FetchTagged(ns, m3query, opts)
+			result.Add(i, iters, convertSessionError(err))
+		}()
+	}
+
+	wg.Wait()
+
+	// Check if the query was interrupted.
+	select {
+	case <-ctx.Done():
+		result.Close()
+		return nil, ctx.Err()
+	default:
+	}
+
+	return result, nil
+}
+
 // fetches compressed series, returning a MultiFetchResult accumulator
 func (s *m3storage) fetchCompressed(
 	ctx context.Context,
@@ -245,7 +369,8 @@ func (s *m3storage) fetchCompressed(
 			iters, _, err := session.FetchTagged(ns, m3query, opts)
 			// Ignore error from getting iterator pools, since operation
 			// will not be dramatically impacted if pools is nil
-			result.Add(namespace.Options().Attributes(), iters, err)
+			result.Add(namespace.Options().Attributes(), iters,
+				convertSessionError(err))
 			wg.Done()
 		}()
 	}
@@ -300,58 +425,83 @@ func (s *m3storage) CompleteTags(
 	default:
 	}
 
-	// TODO: instead of aggregating locally, have the DB aggregate it before
-	// sending results back.
+	// NB: unless a range is specified complete tags matches every tag from
+	// the start of time until now.
+	end := query.End
+	if end.IsZero() {
+		end = time.Now()
+	}
+
 	fetchQuery := &storage.FetchQuery{
 		TagMatchers: query.TagMatchers,
-		// NB: complete tags matches every tag from the start of time until now
-		Start: time.Time{},
-		End:   time.Now(),
+		Start:       query.Start,
+		End:         end,
 	}
 
-	results, cleanup, err := s.SearchCompressed(ctx, fetchQuery, options)
-	defer cleanup()
+	m3query, err := storage.FetchQueryToM3Query(fetchQuery)
 	if err != nil {
 		return nil, err
 	}
 
-	accumulatedTags := storage.NewCompleteTagsResultBuilder(query.CompleteNameOnly)
-	// only filter if there are tags to filter on.
-	filtering := len(query.FilterNameTags) > 0
-	for _, elem := range results {
-		it := elem.Iter
-		tags := make([]storage.CompletedTag, 0, it.Len())
-		for i := 0; it.Next(); i++ {
-			tag := it.Current()
-			name := tag.Name.Bytes()
-			if filtering {
-				found := false
-				for _, filterName := range query.FilterNameTags {
-					if bytes.Equal(filterName, name) {
-						found = true
-						break
-					}
-				}
-
-				if !found {
-					continue
-				}
-			}
+	aggType := index.AggregateTagNamesAndValues
+	if query.CompleteNameOnly {
+		aggType = index.AggregateTagNames
+	}
 
-			tags = append(tags, storage.CompletedTag{
-				Name:   name,
-				Values: [][]byte{tag.Value.Bytes()},
-			})
+	var (
+		aggOpts = index.AggregationOptions{
+			QueryOptions: storage.FetchOptionsToM3Options(options, fetchQuery),
+			FieldFilter:  index.AggregateFieldFilter(query.FilterNameTags),
+			Type:         aggType,
 		}
+		namespaces      = s.clusters.ClusterNamespaces()
+		accumulatedTags = storage.NewCompleteTagsResultBuilder(query.CompleteNameOnly)
+		multiErr        syncMultiErrs
+		wg              sync.WaitGroup
+		mu              sync.Mutex
+	)
 
-		if err := elem.Iter.Err(); err != nil {
-			return nil, err
-		}
+	if len(namespaces) == 0 {
+		return nil, errNoNamespacesConfigured
+	}
 
-		accumulatedTags.Add(&storage.CompleteTagsResult{
-			CompleteNameOnly: query.CompleteNameOnly,
-			CompletedTags:    tags,
-		})
+	wg.Add(len(namespaces))
+	for _, namespace := range namespaces {
+		namespace := namespace // Capture var
+		go func() {
+			defer wg.Done()
+			session := namespace.Session()
+			namespaceID := namespace.NamespaceID()
+			results, _, err := session.Aggregate(namespaceID, m3query, aggOpts)
+			if err != nil {
+				multiErr.add(convertSessionError(err))
+				return
+			}
+
+			fields := results.Fields()
+			tags := make([]storage.CompletedTag, 0, len(fields))
+			for _, field := range fields {
+				tags = append(tags, storage.CompletedTag{
+					Name:   field.Name,
+					Values: field.Terms,
+				})
+			}
+
+			mu.Lock()
+			err = accumulatedTags.Add(&storage.CompleteTagsResult{
+				CompleteNameOnly: query.CompleteNameOnly,
+				CompletedTags:    tags,
+			})
+			mu.Unlock()
+			if err != nil {
+				multiErr.add(err)
+			}
+		}()
+	}
+
+	wg.Wait()
+	if err := multiErr.lastError(); err != nil {
+		return nil, err
 	}
 
 	built := accumulatedTags.Build()
@@ -393,7 +543,7 @@ func (s *m3storage) SearchCompressed(
 			session := namespace.Session()
 			namespaceID := namespace.NamespaceID()
 			iter, _, err := session.FetchTaggedIDs(namespaceID, m3query, m3opts)
-			result.Add(iter, err)
+			result.Add(iter, convertSessionError(err))
 			wg.Done()
 		}()
 	}
@@ -30,6 +30,7 @@ import (
 
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/test/seriesiter"
@@ -122,7 +123,7 @@ func newTestStorage(t *testing.T, clusters Clusters) storage.Storage {
 	require.NoError(t, err)
 	writePool.Init()
 	opts := models.NewTagOptions().SetMetricName([]byte("name"))
-	storage := NewStorage(clusters, nil, writePool, opts, time.Minute)
+	storage := NewStorage(clusters, nil, writePool, opts, time.Minute, StitchingOptions{})
 	return storage
 }
 
@@ -596,34 +597,29 @@ func TestLocalCompleteTagsSuccess(t *testing.T) {
 	defer ctrl.Finish()
 	store, sessions := setup(t, ctrl)
 
-	type testFetchTaggedID struct {
-		id        string
+	type testAggregate struct {
 		namespace string
 		tagName   string
 		tagValue  string
 	}
 
-	fetches := []testFetchTaggedID{
+	aggregates := []testAggregate{
 		{
-			id:        "foo",
 			namespace: "metrics_unaggregated",
 			tagName:   "qux",
 			tagValue:  "qaz",
 		},
 		{
-			id:        "bar",
 			namespace: "metrics_aggregated_1m:30d",
-			tagName:   "qel",
-			tagValue:  "quz",
+			tagName:   "qux",
+			tagValue:  "qaz",
 		},
 		{
-			id:        "baz",
 			namespace: "metrics_aggregated_5m:90d",
-			tagName:   "qam",
-			tagValue:  "qak",
+			tagName:   "qux",
+			tagValue:  "qaz2",
 		},
 		{
-			id:        "qux",
 			namespace: "metrics_aggregated_10m:365d",
 			tagName:   "qux",
 			tagValue:  "qaz2",
@@ -631,52 +627,43 @@ func TestLocalCompleteTagsSuccess(t *testing.T) {
 	}
 
 	sessions.forEach(func(session *client.MockSession) {
-		var f testFetchTaggedID
+		var a testAggregate
 		switch {
 		case session == sessions.unaggregated1MonthRetention:
-			f = fetches[0]
+			a = aggregates[0]
 		case session == sessions.aggregated1MonthRetention1MinuteResolution:
-			f = fetches[1]
+			a = aggregates[1]
 		case session == sessions.aggregated3MonthRetention5MinuteResolution:
-			f = fetches[2]
+			a = aggregates[2]
 		case session == sessions.aggregated1YearRetention10MinuteResolution:
-			f = fetches[3]
+			a = aggregates[3]
 		default:
-			// Not expecting from other (partial) namespaces
-			iter := client.NewMockTaggedIDsIterator(ctrl)
-			gomock.InOrder(
-				iter.EXPECT().Next().Return(false),
-				iter.EXPECT().Err().Return(nil),
-				iter.EXPECT().Finalize(),
-			)
-			session.EXPECT().FetchTaggedIDs(gomock.Any(), gomock.Any(), gomock.Any()).
-				Return(iter, true, nil)
-			session.EXPECT().IteratorPools().
-				Return(nil, nil).AnyTimes()
+			// Not expecting results from other (partial) namespaces
+			session.EXPECT().Aggregate(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(
+					ns ident.ID,
+					_ index.Query,
+					opts index.AggregationOptions,
+				) (index.AggregateResults, bool, error) {
+					return index.NewAggregateResults(ns, opts.Type), true, nil
+				})
 			return
 		}
-		iter := client.NewMockTaggedIDsIterator(ctrl)
-		gomock.InOrder(
-			iter.EXPECT().Next().Return(true),
-			iter.EXPECT().Current().Return(
-				ident.StringID(f.namespace),
-				ident.StringID(f.id),
-				ident.NewTagsIterator(ident.NewTags(
-					ident.Tag{
-						Name:  ident.StringID(f.tagName),
-						Value: ident.StringID(f.tagValue),
-					})),
-			),
-			iter.EXPECT().Next().Return(false),
-			iter.EXPECT().Err().Return(nil),
-			iter.EXPECT().Finalize(),
-		)
-
-		session.EXPECT().FetchTaggedIDs(gomock.Any(), gomock.Any(), gomock.Any()).
-			Return(iter, true, nil)
 
-		session.EXPECT().IteratorPools().
-			Return(nil, nil).AnyTimes()
+		session.EXPECT().Aggregate(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(
+				ns ident.ID,
+				_ index.Query,
+				opts index.AggregationOptions,
+			) (index.AggregateResults, bool, error) {
+				require.Equal(t, a.namespace, ns.String())
+				require.Equal(t, index.AggregateTagNamesAndValues, opts.Type)
+				require.Equal(t, index.AggregateFieldFilter{[]byte("qux")},
+					opts.FieldFilter)
+				results := index.NewAggregateResults(ns, opts.Type)
+				results.AddFieldAndTerm([]byte(a.tagName), []byte(a.tagValue))
+				return results, true, nil
+			})
 	})
 
 	req := newCompleteTagsReq()
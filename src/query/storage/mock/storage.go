@@ -41,6 +41,7 @@ type Storage interface {
 	SetFetchBlocksResult(block.Result, error)
 	SetCloseResult(error)
 	Writes() []*storage.WriteQuery
+	FetchBlocksOptions() []*storage.FetchOptions
 }
 
 type mockStorage struct {
@@ -70,7 +71,8 @@ type mockStorage struct {
 	closeResult struct {
 		err error
 	}
-	writes []*storage.WriteQuery
+	writes             []*storage.WriteQuery
+	fetchBlocksOptions []*storage.FetchOptions
 }
 
 // NewMockStorage creates a new mock Storage instance.
@@ -130,6 +132,12 @@ func (s *mockStorage) Writes() []*storage.WriteQuery {
 	return s.writes
 }
 
+func (s *mockStorage) FetchBlocksOptions() []*storage.FetchOptions {
+	s.RLock()
+	defer s.RUnlock()
+	return s.fetchBlocksOptions
+}
+
 func (s *mockStorage) Fetch(
 	ctx context.Context,
 	query *storage.FetchQuery,
@@ -145,8 +153,9 @@ func (s *mockStorage) FetchBlocks(
 	query *storage.FetchQuery,
 	options *storage.FetchOptions,
 ) (block.Result, error) {
-	s.RLock()
-	defer s.RUnlock()
+	s.Lock()
+	defer s.Unlock()
+	s.fetchBlocksOptions = append(s.fetchBlocksOptions, options)
 	return s.fetchBlocksResult.result, s.fetchBlocksResult.err
 }
 
@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/encoding/downsample"
 	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/ts"
@@ -86,6 +87,12 @@ type FetchOptions struct {
 	BlockType models.FetchedBlockType
 	// FanoutOptions are the options for the fetch namespace fanout.
 	FanoutOptions *FanoutOptions
+	// Annotations collects the annotated datapoints that are fetched, nil
+	// if annotations are not requested.
+	Annotations *Annotations
+	// Downsample describes how the series are downsampled on the database
+	// nodes before they are fetched, the series are fetched raw if disabled.
+	Downsample downsample.Options
 }
 
 // FanoutOptions describes which namespaces should be fanned out to for
@@ -177,6 +184,10 @@ type CompleteTagsQuery struct {
 	CompleteNameOnly bool
 	FilterNameTags   [][]byte
 	TagMatchers      models.Matchers
+	// Start and End restrict completion to series written within the range,
+	// a zero End completes tags up until now.
+	Start time.Time
+	End   time.Time
 }
 
 // SeriesMatchQuery represents a query that returns a set of series
@@ -230,6 +241,7 @@ type FetchResult struct {
 	SeriesList ts.SeriesList // The aggregated list of results across all underlying storage calls
 	LocalOnly  bool
 	HasNext    bool
+	Metadata   block.ResultMetadata
 }
 
 // QueryResult is the result from a query
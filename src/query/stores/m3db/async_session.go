@@ -136,6 +136,28 @@ func (s *AsyncSession) FetchIDs(namespace ident.ID, ids ident.Iterator, startInc
 	return s.session.FetchIDs(namespace, ids, startInclusive, endExclusive)
 }
 
+// FetchWithOptions fetches values from the database for an ID with the given fetch options
+func (s *AsyncSession) FetchWithOptions(namespace, id ident.ID, startInclusive, endExclusive time.Time, opts client.FetchOptions) (encoding.SeriesIterator, error) {
+	s.RLock()
+	defer s.RUnlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return s.session.FetchWithOptions(namespace, id, startInclusive, endExclusive, opts)
+}
+
+// FetchIDsWithOptions fetches values from the database for a set of IDs with the given fetch options
+func (s *AsyncSession) FetchIDsWithOptions(namespace ident.ID, ids ident.Iterator, startInclusive, endExclusive time.Time, opts client.FetchOptions) (encoding.SeriesIterators, error) {
+	s.RLock()
+	defer s.RUnlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return s.session.FetchIDsWithOptions(namespace, ids, startInclusive, endExclusive, opts)
+}
+
 // FetchTagged resolves the provided query to known IDs, and fetches the data for them
 func (s *AsyncSession) FetchTagged(namespace ident.ID, q index.Query, opts index.QueryOptions) (results encoding.SeriesIterators, exhaustive bool, err error) {
 	s.RLock()
@@ -158,6 +180,39 @@ func (s *AsyncSession) FetchTaggedIDs(namespace ident.ID, q index.Query, opts in
 	return s.session.FetchTaggedIDs(namespace, q, opts)
 }
 
+// Aggregate resolves the provided query to distinct tag names and values.
+func (s *AsyncSession) Aggregate(namespace ident.ID, q index.Query, opts index.AggregationOptions) (index.AggregateResults, bool, error) {
+	s.RLock()
+	defer s.RUnlock()
+	if s.err != nil {
+		return nil, false, s.err
+	}
+
+	return s.session.Aggregate(namespace, q, opts)
+}
+
+// DeleteSeries deletes the series with the given IDs
+func (s *AsyncSession) DeleteSeries(namespace ident.ID, ids ident.Iterator) (int64, error) {
+	s.RLock()
+	defer s.RUnlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	return s.session.DeleteSeries(namespace, ids)
+}
+
+// DeleteTagged deletes the series matching the provided query
+func (s *AsyncSession) DeleteTagged(namespace ident.ID, q index.Query) (int64, error) {
+	s.RLock()
+	defer s.RUnlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	return s.session.DeleteTagged(namespace, q)
+}
+
 // ShardID returns the given shard for an ID for callers
 // to easily discern what shard is failing when operations
 // for given IDs begin failing
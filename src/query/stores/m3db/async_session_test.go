@@ -83,6 +83,14 @@ func TestAsyncSessionError(t *testing.T) {
 	assert.Nil(t, seriesIterators)
 	assert.EqualError(t, err, expectedErrStr)
 
+	seriesIterator, err = asyncSession.FetchWithOptions(nil, nil, time.Now(), time.Now(), client.FetchOptions{})
+	assert.Nil(t, seriesIterator)
+	assert.EqualError(t, err, expectedErrStr)
+
+	seriesIterators, err = asyncSession.FetchIDsWithOptions(nil, nil, time.Now(), time.Now(), client.FetchOptions{})
+	assert.Nil(t, seriesIterators)
+	assert.EqualError(t, err, expectedErrStr)
+
 	pools, err := asyncSession.IteratorPools()
 	assert.Nil(t, pools)
 	assert.EqualError(t, err, expectedErrStr)
@@ -146,6 +154,14 @@ func TestAsyncSessionInitialized(t *testing.T) {
 	_, err = asyncSession.FetchIDs(nil, nil, time.Now(), time.Now())
 	assert.NoError(t, err)
 
+	mockSession.EXPECT().FetchWithOptions(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	_, err = asyncSession.FetchWithOptions(nil, nil, time.Now(), time.Now(), client.FetchOptions{})
+	assert.NoError(t, err)
+
+	mockSession.EXPECT().FetchIDsWithOptions(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	_, err = asyncSession.FetchIDsWithOptions(nil, nil, time.Now(), time.Now(), client.FetchOptions{})
+	assert.NoError(t, err)
+
 	mockSession.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, false, nil)
 	_, _, err = asyncSession.FetchTagged(namespace, index.Query{}, index.QueryOptions{})
 	assert.NoError(t, err)
@@ -64,7 +64,8 @@ func NewStorageAndSession(
 	require.NoError(t, err)
 	writePool.Init()
 	tagOptions := models.NewTagOptions().SetMetricName([]byte("name"))
-	storage := m3.NewStorage(clusters, nil, writePool, tagOptions, defaultLookbackDuration)
+	storage := m3.NewStorage(clusters, nil, writePool, tagOptions, defaultLookbackDuration,
+		m3.StitchingOptions{})
 	return storage, session
 }
 
@@ -91,6 +92,7 @@ func NewStorageAndSessionWithAggregatedNamespaces(
 	require.NoError(t, err)
 	writePool.Init()
 	tagOptions := models.NewTagOptions().SetMetricName([]byte("name"))
-	storage := m3.NewStorage(clusters, nil, writePool, tagOptions, defaultLookbackDuration)
+	storage := m3.NewStorage(clusters, nil, writePool, tagOptions, defaultLookbackDuration,
+		m3.StitchingOptions{})
 	return storage, session
 }
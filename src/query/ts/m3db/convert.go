@@ -22,14 +22,17 @@ package m3db
 
 import (
 	"fmt"
+	"io"
 	"math"
 	"sort"
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3x/ident"
 	"github.com/m3db/m3x/pool"
 )
@@ -99,6 +102,11 @@ func ConvertM3DBSeriesIterators(
 		return convertM3DBSegmentedBlockIterators(iterators, bounds, opts)
 	}
 
+	if annotations := opts.Annotations(); annotations != nil {
+		iterators = storage.NewAnnotatedSeriesIterators(iterators,
+			annotations, opts.TagOptions())
+	}
+
 	return seriesIteratorsToEncodedBlockIterators(iterators, bounds, opts)
 }
 
@@ -110,18 +118,22 @@ func convertM3DBSegmentedBlockIterators(
 ) ([]block.Block, error) {
 	defer iterators.Close()
 	blockBuilder := newEncodedBlockBuilder(opts)
-	var (
-		iterAlloc    = opts.IterAlloc()
-		pools        = opts.IteratorPools()
-		checkedPools = opts.CheckedBytesPool()
-	)
+	checkedPools := opts.CheckedBytesPool()
 
 	for _, seriesIterator := range iterators.Iters() {
+		iterAlloc, multiIterPool, err := readerIteratorsForCodec(
+			seriesIterator.CodecID(),
+			opts,
+		)
+		if err != nil {
+			return nil, err
+		}
+
 		blockReplicas, err := blockReplicasFromSeriesIterator(
 			seriesIterator,
 			iterAlloc,
 			bounds,
-			pools,
+			multiIterPool,
 			checkedPools,
 		)
 
@@ -140,7 +152,7 @@ func convertM3DBSegmentedBlockIterators(
 			blockReplicas,
 			bounds.StepSize,
 			seriesIterator,
-			pools,
+			opts,
 		)
 		if err != nil {
 			return nil, err
@@ -150,18 +162,40 @@ func convertM3DBSegmentedBlockIterators(
 	return blockBuilder.build()
 }
 
+// readerIteratorsForCodec returns the reader iterator allocator and the pool
+// of multi reader iterators used to decode series encoded with a codec.
+func readerIteratorsForCodec(
+	id codec.ID,
+	opts Options,
+) (encoding.ReaderIteratorAllocate, encoding.MultiReaderIteratorPool, error) {
+	if id == codec.DefaultID {
+		var multiIterPool encoding.MultiReaderIteratorPool
+		if pools := opts.IteratorPools(); pools != nil {
+			multiIterPool = pools.MultiReaderIterator()
+		}
+		return opts.IterAlloc(), multiIterPool, nil
+	}
+
+	codecPools, err := opts.CodecRegistry().Pools(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	iterAlloc := func(r io.Reader) encoding.ReaderIterator {
+		iter := codecPools.ReaderIteratorPool.Get()
+		iter.Reset(r)
+		return iter
+	}
+	return iterAlloc, codecPools.MultiReaderIteratorPool, nil
+}
+
 func blockReplicasFromSeriesIterator(
 	seriesIterator encoding.SeriesIterator,
 	iterAlloc encoding.ReaderIteratorAllocate,
 	bounds models.Bounds,
-	pools encoding.IteratorPools,
+	multiIterPool encoding.MultiReaderIteratorPool,
 	checkedPools pool.CheckedBytesPool,
 ) (seriesBlocks, error) {
 	blocks := make(seriesBlocks, 0, bounds.Steps())
-	var pool encoding.MultiReaderIteratorPool
-	if pools != nil {
-		pool = pools.MultiReaderIterator()
-	}
 
 	for _, replica := range seriesIterator.Replicas() {
 		perBlockSliceReaders := replica.Readers()
@@ -180,7 +214,7 @@ func blockReplicasFromSeriesIterator(
 				readers[i] = clonedReader
 			}
 
-			iter := encoding.NewMultiReaderIterator(iterAlloc, pool)
+			iter := encoding.NewMultiReaderIterator(iterAlloc, multiIterPool)
 			iter.Reset(readers, start, bs)
 			inserted := false
 			for _, bl := range blocks {
@@ -253,7 +287,7 @@ func seriesBlocksFromBlockReplicas(
 	blockReplicas seriesBlocks,
 	stepSize time.Duration,
 	seriesIterator encoding.SeriesIterator,
-	pools encoding.IteratorPools,
+	opts Options,
 ) error {
 	// NB(braskin): we need to clone the ID, namespace, and tags since we close the series iterator
 	var (
@@ -290,6 +324,13 @@ func seriesBlocksFromBlockReplicas(
 			Replicas:       block.replicas,
 		}, nil)
 
+		// NB: The series is split into new iterators for each block so the
+		// annotations are read from these rather than the original iterator.
+		if annotations := opts.Annotations(); annotations != nil {
+			iter = storage.NewAnnotatedSeriesIterator(iter, annotations,
+				opts.TagOptions())
+		}
+
 		// NB: if querying a small range, such that blockSize is greater than the
 		// iterator duration, use the smaller range instead.
 		duration := filterValuesEnd.Sub(filterValuesStart)
@@ -27,9 +27,12 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
+	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/test"
+	xtime "github.com/m3db/m3x/time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -199,6 +202,45 @@ func TestUpdateTimeBySteps(t *testing.T) {
 	}
 }
 
+func TestReaderIteratorsForCodec(t *testing.T) {
+	opts := NewOptions()
+	iterAlloc, pool, err := readerIteratorsForCodec(codec.DefaultID, opts)
+	require.NoError(t, err)
+	require.NotNil(t, iterAlloc)
+	assert.Equal(t, opts.IteratorPools().MultiReaderIterator(), pool)
+
+	codecPools, err := opts.CodecRegistry().Pools(codec.DeltaOfDelta)
+	require.NoError(t, err)
+	encoder := codecPools.EncoderPool.Get()
+	encoder.Reset(Start, 0)
+	for i := 0; i < 10; i++ {
+		dp := ts.Datapoint{
+			Timestamp: Start.Add(time.Duration(i) * time.Minute),
+			Value:     float64(i * 3),
+		}
+		require.NoError(t, encoder.Encode(dp, xtime.Second, nil))
+	}
+
+	iterAlloc, pool, err = readerIteratorsForCodec(codec.DeltaOfDelta, opts)
+	require.NoError(t, err)
+	assert.Equal(t, codecPools.MultiReaderIteratorPool, pool)
+
+	iter := iterAlloc(encoder.Stream())
+	defer iter.Close()
+	var count int
+	for iter.Next() {
+		dp, _, _ := iter.Current()
+		assert.Equal(t, Start.Add(time.Duration(count)*time.Minute), dp.Timestamp)
+		assert.Equal(t, float64(count*3), dp.Value)
+		count++
+	}
+	require.NoError(t, iter.Err())
+	assert.Equal(t, 10, count)
+
+	_, _, err = readerIteratorsForCodec(codec.ID(255), opts)
+	require.Error(t, err)
+}
+
 func TestPadSeriesBlocks(t *testing.T) {
 	blockSize := time.Hour
 	start := time.Now().Truncate(blockSize)
@@ -27,9 +27,11 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/dod"
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/pools"
+	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/ts/m3db/consolidators"
 	"github.com/m3db/m3x/pool"
 )
@@ -50,8 +52,10 @@ type encodedBlockOptions struct {
 	consolidationFn  consolidators.ConsolidationFunc
 	tagOptions       models.TagOptions
 	iterAlloc        encoding.ReaderIteratorAllocate
+	codecRegistry    encoding.CodecRegistry
 	pools            encoding.IteratorPools
 	checkedPools     pool.CheckedBytesPool
+	annotations      *storage.Annotations
 }
 
 // NewOptions creates a default encoded block options which dictates how
@@ -65,11 +69,15 @@ func NewOptions() Options {
 	})
 	bytesPool.Init()
 
+	codecRegistry := encoding.NewCodecRegistry(nil, nil,
+		m3tsz.NewCodec(), m3tsz.NewXORCodec(), dod.NewCodec())
+
 	return &encodedBlockOptions{
 		lookbackDuration: defaultLookbackDuration,
 		consolidationFn:  defaultConsolidationFn,
 		tagOptions:       models.NewTagOptions(),
 		iterAlloc:        defaultIterAlloc,
+		codecRegistry:    codecRegistry,
 		pools:            pools.BuildIteratorPools(),
 		checkedPools:     bytesPool,
 	}
@@ -125,6 +133,16 @@ func (o *encodedBlockOptions) IterAlloc() encoding.ReaderIteratorAllocate {
 	return o.iterAlloc
 }
 
+func (o *encodedBlockOptions) SetCodecRegistry(r encoding.CodecRegistry) Options {
+	opts := *o
+	opts.codecRegistry = r
+	return &opts
+}
+
+func (o *encodedBlockOptions) CodecRegistry() encoding.CodecRegistry {
+	return o.codecRegistry
+}
+
 func (o *encodedBlockOptions) SetIteratorPools(p encoding.IteratorPools) Options {
 	opts := *o
 	opts.pools = p
@@ -145,6 +163,16 @@ func (o *encodedBlockOptions) CheckedBytesPool() pool.CheckedBytesPool {
 	return o.checkedPools
 }
 
+func (o *encodedBlockOptions) SetAnnotations(a *storage.Annotations) Options {
+	opts := *o
+	opts.annotations = a
+	return &opts
+}
+
+func (o *encodedBlockOptions) Annotations() *storage.Annotations {
+	return o.annotations
+}
+
 func (o *encodedBlockOptions) Validate() error {
 	if o.lookbackDuration < 0 {
 		return errors.New("unable to validate block options; negative lookback")
@@ -154,5 +182,9 @@ func (o *encodedBlockOptions) Validate() error {
 		return fmt.Errorf("unable to validate tag options, err: %v", err)
 	}
 
+	if o.codecRegistry == nil {
+		return errors.New("unable to validate block options; no codec registry")
+	}
+
 	return nil
 }
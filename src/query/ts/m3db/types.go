@@ -26,6 +26,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/ts/m3db/consolidators"
 	"github.com/m3db/m3x/pool"
 )
@@ -56,6 +57,11 @@ type Options interface {
 	SetIterAlloc(encoding.ReaderIteratorAllocate) Options
 	// IterAlloc returns the reader iterator allocator.
 	IterAlloc() encoding.ReaderIteratorAllocate
+	// SetCodecRegistry sets the registry of codecs used to decode series
+	// from namespaces that are not encoded with the default codec.
+	SetCodecRegistry(encoding.CodecRegistry) Options
+	// CodecRegistry returns the registry of codecs.
+	CodecRegistry() encoding.CodecRegistry
 	// SetIteratorPools sets the iterator pools for the converter.
 	SetIteratorPools(encoding.IteratorPools) Options
 	// IteratorPools returns the iterator pools for the converter.
@@ -64,6 +70,12 @@ type Options interface {
 	SetCheckedBytesPool(pool.CheckedBytesPool) Options
 	// CheckedBytesPool returns the checked bytes pools for the converter.
 	CheckedBytesPool() pool.CheckedBytesPool
+	// SetAnnotations sets the annotations that the annotated datapoints read
+	// from the converted series are added to.
+	SetAnnotations(*storage.Annotations) Options
+	// Annotations returns the annotations, nil if annotations are not
+	// collected.
+	Annotations() *storage.Annotations
 
 	// Validate ensures that the given block options are valid.
 	Validate() error
@@ -27,6 +27,8 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
+	"github.com/m3db/m3/src/dbnode/encoding/dod"
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
@@ -48,12 +50,16 @@ func initializeVars() {
 	iterAlloc = func(r io.Reader) encoding.ReaderIterator {
 		return m3tsz.NewReaderIterator(r, m3tsz.DefaultIntOptimizationEnabled, encoding.NewOptions())
 	}
+
+	codecRegistry = encoding.NewCodecRegistry(nil, nil,
+		m3tsz.NewCodec(), m3tsz.NewXORCodec(), dod.NewCodec())
 }
 
 var (
-	opts       checked.BytesOptions
-	iterAlloc  func(r io.Reader) encoding.ReaderIterator
-	initialize sync.Once
+	opts          checked.BytesOptions
+	iterAlloc     func(r io.Reader) encoding.ReaderIterator
+	codecRegistry encoding.CodecRegistry
+	initialize    sync.Once
 )
 
 func compressedSegmentFromBlockReader(br xio.BlockReader) (*rpc.M3Segment, error) {
@@ -189,6 +195,7 @@ func compressedSeriesFromSeriesIterator(
 			Compressed: &rpc.M3CompressedSeries{
 				CompressedTags: tags,
 				Replicas:       compressedReplicas,
+				CodecID:        uint32(it.CodecID()),
 			},
 		},
 	}, nil
@@ -320,6 +327,27 @@ func seriesIteratorFromCompressedSeries(
 ) (encoding.SeriesIterator, error) {
 	initialize.Do(initializeVars)
 
+	// NB: series that are not encoded with the default codec are decoded
+	// with the reader iterators of their codec rather than those pooled
+	// by the m3db session.
+	var (
+		codecID          = codec.ID(timeSeries.GetCodecID())
+		codecIterAlloc   = iterAlloc
+		codecMultiReader encoding.MultiReaderIteratorPool
+	)
+	if codecID != codec.DefaultID {
+		codecPools, err := codecRegistry.Pools(codecID)
+		if err != nil {
+			return nil, err
+		}
+		codecIterAlloc = func(r io.Reader) encoding.ReaderIterator {
+			iter := codecPools.ReaderIteratorPool.Get()
+			iter.Reset(r)
+			return iter
+		}
+		codecMultiReader = codecPools.MultiReaderIteratorPool
+	}
+
 	// Attempt to decompress compressed tags first as this is the only scenario that is expected to fail
 	tagIter, err := tagIteratorFromSeries(timeSeries, iteratorPools)
 	if err != nil {
@@ -327,7 +355,7 @@ func seriesIteratorFromCompressedSeries(
 	}
 
 	var (
-		multiReaderPool encoding.MultiReaderIteratorPool
+		multiReaderPool = codecMultiReader
 		seriesIterPool  encoding.SeriesIteratorPool
 
 		checkedBytesWrapperPool xpool.CheckedBytesWrapperPool
@@ -339,7 +367,9 @@ func seriesIteratorFromCompressedSeries(
 	replicas := timeSeries.GetReplicas()
 	// Set up iterator pools if available
 	if iteratorPools != nil {
-		multiReaderPool = iteratorPools.MultiReaderIterator()
+		if codecID == codec.DefaultID {
+			multiReaderPool = iteratorPools.MultiReaderIterator()
+		}
 		seriesIterPool = iteratorPools.SeriesIterator()
 		checkedBytesWrapperPool = iteratorPools.CheckedBytesWrapper()
 		idPool = iteratorPools.ID()
@@ -354,7 +384,7 @@ func seriesIteratorFromCompressedSeries(
 
 		// TODO arnikola investigate pooling these?
 		sliceOfSlicesIterator := xio.NewReaderSliceOfSlicesFromBlockReadersIterator(blockReaders)
-		perReplicaIterator := encoding.NewMultiReaderIterator(iterAlloc, multiReaderPool)
+		perReplicaIterator := encoding.NewMultiReaderIterator(codecIterAlloc, multiReaderPool)
 		perReplicaIterator.ResetSliceOfSlices(sliceOfSlicesIterator)
 
 		allReplicaIterators = append(allReplicaIterators, perReplicaIterator)
@@ -386,6 +416,7 @@ func seriesIteratorFromCompressedSeries(
 		StartInclusive: start,
 		EndExclusive:   end,
 		Replicas:       allReplicaIterators,
+		CodecID:        codecID,
 	})
 
 	return seriesIter, nil
@@ -26,9 +26,14 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/codec"
+	"github.com/m3db/m3/src/dbnode/encoding/dod"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/dbnode/x/xio"
 	rpc "github.com/m3db/m3/src/query/generated/proto/rpcpb"
 	"github.com/m3db/m3/src/query/test"
 	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -213,6 +218,63 @@ func TestSeriesConversionFromCompressedDataWithIteratorPool(t *testing.T) {
 	assert.False(t, ip.MsiPoolUsed)
 }
 
+func TestSeriesConversionFromCompressedDataWithCodec(t *testing.T) {
+	registry := encoding.NewCodecRegistry(nil, nil, dod.NewCodec())
+	codecPools, err := registry.Pools(codec.DeltaOfDelta)
+	require.NoError(t, err)
+
+	encoder := codecPools.EncoderPool.Get()
+	encoder.Reset(start, 0)
+	for i := 0; i < 10; i++ {
+		dp := ts.Datapoint{
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Value:     float64(i * 2),
+		}
+		require.NoError(t, encoder.Encode(dp, xtime.Second, nil))
+	}
+
+	segment, err := compressedSegmentFromBlockReader(xio.BlockReader{
+		SegmentReader: encoder.Stream(),
+		Start:         start,
+		BlockSize:     blockSize,
+	})
+	require.NoError(t, err)
+
+	series := &rpc.M3CompressedSeries{
+		Replicas: []*rpc.M3CompressedValuesReplica{
+			{Segments: []*rpc.M3Segments{{Merged: segment}}},
+		},
+		CodecID: uint32(codec.DeltaOfDelta),
+	}
+
+	// Ensure the codec is preserved across the wire.
+	data, err := series.Marshal()
+	require.NoError(t, err)
+	var decoded rpc.M3CompressedSeries
+	require.NoError(t, decoded.Unmarshal(data))
+	require.Equal(t, uint32(codec.DeltaOfDelta), decoded.GetCodecID())
+
+	meta := &rpc.SeriesMetadata{
+		Id:        []byte(seriesID),
+		StartTime: start.UnixNano(),
+		EndTime:   start.Add(blockSize).UnixNano(),
+	}
+	it, err := seriesIteratorFromCompressedSeries(&decoded, meta, nil)
+	require.NoError(t, err)
+	defer it.Close()
+
+	assert.Equal(t, codec.DeltaOfDelta, it.CodecID())
+	count := 0
+	for it.Next() {
+		dp, _, _ := it.Current()
+		assert.Equal(t, start.Add(time.Duration(count)*time.Minute), dp.Timestamp)
+		assert.Equal(t, float64(count*2), dp.Value)
+		count++
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, 10, count)
+}
+
 func TestencodeToCompressedFetchResult(t *testing.T) {
 	iters := encoding.NewSeriesIterators([]encoding.SeriesIterator{buildTestSeriesIterator(t), buildTestSeriesIterator(t)}, nil)
 	ip := test.MakeMockIteratorPool()
@@ -101,6 +101,22 @@ func DurationToNanosMap(input map[string]interface{}) (map[string]interface{}, e
 				}
 
 				dictTranslated[k] = durMap
+			case []interface{}:
+				durSlice := make([]interface{}, 0, len(vv))
+				for _, elem := range vv {
+					elemMap, ok := elem.(map[string]interface{})
+					if !ok {
+						durSlice = append(durSlice, elem)
+						continue
+					}
+					durMap, err := DurationToNanosMap(elemMap)
+					if err != nil {
+						return nil, err
+					}
+					durSlice = append(durSlice, durMap)
+				}
+
+				dictTranslated[k] = durSlice
 			default:
 				dictTranslated[k] = vv
 			}
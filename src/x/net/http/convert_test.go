@@ -39,12 +39,14 @@ func TestDurationToNanosBytes(t *testing.T) {
 		`{"field":"value","fieldDuration":"1s"}`:                                           ret{`{"field":"value","fieldNanos":1000000000}`, false},
 		`{"realDuration":"50ns","nanoDuration":100,"normalNanos":200}`:                     ret{`{"nanoNanos":100,"normalNanos":200,"realNanos":50}`, false},
 		`{"field":"value","moreFields":{"innerDuration":"2ms","innerField":"innerValue"}}`: ret{`{"field":"value","moreFields":{"innerField":"innerValue","innerNanos":2000000}}`, false},
+		`{"rules":[{"periodDuration":"1h"},"value"]}`:                                      ret{`{"rules":[{"periodNanos":3600000000000},"value"]}`, false},
 		`not json`:                                       ret{"", true},
 		`{"fieldDuration":[]}`:                           ret{"", true},
 		`{"fieldDuration":{}}`:                           ret{"", true},
 		`{"fieldDuration":"badDuration"}`:                ret{"", true},
 		`{"fieldDuration":100.5}`:                        ret{"", true},
 		`{"moreFields":{"innerDuration":"badDuration"}}`: ret{"", true},
+		`{"rules":[{"periodDuration":"badDuration"}]}`:   ret{"", true},
 	}
 
 	for k, v := range testCases {